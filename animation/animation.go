@@ -0,0 +1,194 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package animation provides sprite sheet animations.
+//
+// DecodeGIF and DecodeAPNG decode an animated image into a Sheet, which has all the frames in one image.
+// An Animation advances the frames of a Sheet by ticks, and draws the current frame with
+// DrawImageOptions.SourceRect, so that the frames of many animations can be drawn as batches.
+//
+// Note: This package is experimental and API might be changed.
+package animation
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// Frame represents a frame of a sprite sheet animation.
+type Frame struct {
+	// Rect is the region of the frame in the sheet image.
+	Rect image.Rectangle
+
+	// Delay is the duration to show the frame.
+	// A Delay of 0 or less is treated as one tick.
+	Delay time.Duration
+}
+
+// Sheet represents a sprite sheet that has the frames of an animation.
+type Sheet struct {
+	Image  *ebiten.Image
+	Frames []Frame
+
+	// LoopCount is the number of times the animation is played. 0 means that the animation is played forever.
+	LoopCount int
+}
+
+// decoded represents the frames of a decoded animated image.
+type decoded struct {
+	// images are the composited frames of the same size.
+	images []*image.RGBA
+	delays []time.Duration
+
+	// loopCount is the number of times the animation is played. 0 means forever.
+	loopCount int
+}
+
+// newSheet arranges the frames in a grid in row-major order and creates a sheet.
+func newSheet(d *decoded, filter ebiten.Filter) (*Sheet, error) {
+	if len(d.images) == 0 {
+		return nil, errors.New("animation: no frames")
+	}
+	size := d.images[0].Bounds().Size()
+	w, h := size.X, size.Y
+	cols := ebiten.MaxImageSize / w
+	if cols < 1 {
+		cols = 1
+	}
+	if cols > len(d.images) {
+		cols = len(d.images)
+	}
+	rows := (len(d.images) + cols - 1) / cols
+	if cols*w > ebiten.MaxImageSize || rows*h > ebiten.MaxImageSize {
+		return nil, errors.New("animation: the frames don't fit in an image")
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, cols*w, rows*h))
+	frames := make([]Frame, len(d.images))
+	for i, img := range d.images {
+		x, y := (i%cols)*w, (i/cols)*h
+		r := image.Rect(x, y, x+w, y+h)
+		draw.Draw(dst, r, img, image.ZP, draw.Src)
+		frames[i] = Frame{
+			Rect:  r,
+			Delay: d.delays[i],
+		}
+	}
+	img, _ := ebiten.NewImageFromImage(dst, filter)
+	return &Sheet{
+		Image:     img,
+		Frames:    frames,
+		LoopCount: d.loopCount,
+	}, nil
+}
+
+// Animation represents a playing sprite sheet animation.
+type Animation struct {
+	sheet *Sheet
+	frame int
+	plays int
+
+	// elapsed is the time elapsed in the current frame multiplied by FPS, so that ticks are counted without errors.
+	elapsed time.Duration
+}
+
+// NewAnimation returns a new animation of the sheet at the first frame.
+//
+// If the sheet has no frames, NewAnimation panics.
+func NewAnimation(sheet *Sheet) *Animation {
+	if len(sheet.Frames) == 0 {
+		panic("animation: the sheet must have frames")
+	}
+	return &Animation{
+		sheet: sheet,
+	}
+}
+
+// Sheet returns the sheet of the animation.
+func (a *Animation) Sheet() *Sheet {
+	return a.sheet
+}
+
+// Reset rewinds the animation to the first frame.
+func (a *Animation) Reset() {
+	a.frame = 0
+	a.plays = 0
+	a.elapsed = 0
+}
+
+// IsFinished reports whether the animation has been played LoopCount times.
+// A finished animation stays at the last frame.
+func (a *Animation) IsFinished() bool {
+	return a.sheet.LoopCount > 0 && a.plays >= a.sheet.LoopCount
+}
+
+// Update advances the animation by one tick.
+//
+// Update is supposed to be called every frame, i.e. ebiten.FPS times per second.
+func (a *Animation) Update() {
+	if a.IsFinished() {
+		return
+	}
+	a.elapsed += time.Second
+	for {
+		d := a.sheet.Frames[a.frame].Delay * ebiten.FPS
+		if d <= 0 {
+			d = time.Second
+		}
+		if a.elapsed < d {
+			return
+		}
+		a.elapsed -= d
+		a.frame++
+		if a.frame < len(a.sheet.Frames) {
+			continue
+		}
+		a.plays++
+		if a.IsFinished() {
+			a.frame = len(a.sheet.Frames) - 1
+			a.elapsed = 0
+			return
+		}
+		a.frame = 0
+	}
+}
+
+// Frame returns the index of the current frame.
+func (a *Animation) Frame() int {
+	return a.frame
+}
+
+// SourceRect returns the region of the current frame in the sheet image.
+//
+// SourceRect is useful with DrawImageOptions.SourceRect to draw the current frame.
+func (a *Animation) SourceRect() image.Rectangle {
+	return a.sheet.Frames[a.frame].Rect
+}
+
+// Draw draws the current frame on the target with the options op.
+//
+// op can be nil. op is not modified, and op.SourceRect is ignored.
+func (a *Animation) Draw(target *ebiten.Image, op *ebiten.DrawImageOptions) {
+	o := &ebiten.DrawImageOptions{}
+	if op != nil {
+		*o = *op
+	}
+	r := a.SourceRect()
+	o.SourceRect = &r
+	_ = target.DrawImage(a.sheet.Image, o)
+}
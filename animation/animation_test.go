@@ -0,0 +1,93 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package animation
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+func TestAnimationUpdate(t *testing.T) {
+	// At 60 FPS, the frames are shown for 3, 1 and 6 ticks.
+	sheet := &Sheet{
+		Frames: []Frame{
+			{Rect: image.Rect(0, 0, 1, 1), Delay: 50 * time.Millisecond},
+			{Rect: image.Rect(1, 0, 2, 1), Delay: 0},
+			{Rect: image.Rect(2, 0, 3, 1), Delay: 100 * time.Millisecond},
+		},
+		LoopCount: 2,
+	}
+	a := NewAnimation(sheet)
+	var got []int
+	for i := 0; i < 24; i++ {
+		got = append(got, a.Frame())
+		a.Update()
+	}
+	want := []int{
+		0, 0, 0, 1, 2, 2, 2, 2, 2, 2,
+		0, 0, 0, 1, 2, 2, 2, 2, 2, 2,
+		2, 2, 2, 2,
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Frame() at tick %d: got: %d, want: %d", i, got[i], want[i])
+		}
+	}
+	if !a.IsFinished() {
+		t.Errorf("IsFinished(): got: false, want: true")
+	}
+	if got, want := a.SourceRect(), image.Rect(2, 0, 3, 1); got != want {
+		t.Errorf("SourceRect(): got: %v, want: %v", got, want)
+	}
+
+	a.Reset()
+	if a.IsFinished() || a.Frame() != 0 {
+		t.Errorf("Reset must rewind the animation")
+	}
+}
+
+func TestNewSheet(t *testing.T) {
+	d := &decoded{}
+	for i := 0; i < 3; i++ {
+		d.images = append(d.images, image.NewRGBA(image.Rect(0, 0, 2, 2)))
+		d.delays = append(d.delays, time.Duration(i)*time.Second)
+	}
+	// Only two frames fit in a row.
+	max := ebiten.MaxImageSize
+	ebiten.MaxImageSize = 5
+	defer func() {
+		ebiten.MaxImageSize = max
+	}()
+	s, err := newSheet(d, ebiten.FilterDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Frame{
+		{Rect: image.Rect(0, 0, 2, 2), Delay: 0},
+		{Rect: image.Rect(2, 0, 4, 2), Delay: time.Second},
+		{Rect: image.Rect(0, 2, 2, 4), Delay: 2 * time.Second},
+	}
+	if w, h := s.Image.Size(); w != 4 || h != 4 {
+		t.Errorf("Image.Size(): got: (%d, %d), want: (4, 4)", w, h)
+	}
+	for i := range want {
+		if s.Frames[i] != want[i] {
+			t.Errorf("Frames[%d]: got: %v, want: %v", i, s.Frames[i], want[i])
+		}
+	}
+}
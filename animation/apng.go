@@ -0,0 +1,274 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package animation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+// The dispose and blend operations of APNG frames.
+const (
+	apngDisposeNone       = 0
+	apngDisposeBackground = 1
+	apngDisposePrevious   = 2
+
+	apngBlendSource = 0
+	apngBlendOver   = 1
+)
+
+// DecodeAPNG decodes an animated PNG (APNG) image into a sheet.
+//
+// The frames are composited with their dispose and blend operations, so each frame of the sheet is a whole image.
+// A PNG image without animation is decoded into a sheet of one frame.
+func DecodeAPNG(r io.Reader, filter ebiten.Filter) (*Sheet, error) {
+	d, err := decodeAPNG(r)
+	if err != nil {
+		return nil, err
+	}
+	return newSheet(d, filter)
+}
+
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+// apngFrame represents a frame specified by an fcTL chunk.
+type apngFrame struct {
+	width    int
+	height   int
+	x        int
+	y        int
+	delay    time.Duration
+	disposal byte
+	blend    byte
+
+	// data is the compressed image data of the IDAT or fdAT chunks.
+	data [][]byte
+}
+
+// readPNGChunks reads the chunks of a PNG image until IEND.
+func readPNGChunks(b []byte) ([]pngChunk, error) {
+	if !bytes.HasPrefix(b, []byte(pngHeader)) {
+		return nil, errors.New("animation: not a PNG image")
+	}
+	b = b[len(pngHeader):]
+	var chunks []pngChunk
+	for {
+		if len(b) < 12 {
+			return nil, errors.New("animation: unexpected EOF in a PNG image")
+		}
+		n := binary.BigEndian.Uint32(b)
+		if uint64(n) > uint64(len(b)-12) {
+			return nil, errors.New("animation: unexpected EOF in a PNG image")
+		}
+		c := pngChunk{
+			typ:  string(b[4:8]),
+			data: b[8 : 8+n],
+		}
+		if crc32.ChecksumIEEE(b[4:8+n]) != binary.BigEndian.Uint32(b[8+n:]) {
+			return nil, errors.New("animation: invalid checksum in a PNG image")
+		}
+		chunks = append(chunks, c)
+		if c.typ == "IEND" {
+			return chunks, nil
+		}
+		b = b[12+n:]
+	}
+}
+
+func writePNGChunk(w *bytes.Buffer, typ string, data []byte) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(len(data)))
+	w.Write(b[:])
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	crc.Write(data)
+	w.WriteString(typ)
+	w.Write(data)
+	binary.BigEndian.PutUint32(b[:], crc.Sum32())
+	w.Write(b[:])
+}
+
+func decodeAPNG(r io.Reader) (*decoded, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := readPNGChunks(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 || chunks[0].typ != "IHDR" || len(chunks[0].data) != 13 {
+		return nil, errors.New("animation: IHDR must be the first chunk of a PNG image")
+	}
+	ihdr := chunks[0].data
+
+	var (
+		animated bool
+		plays    int
+		frames   []*apngFrame
+		// header is the chunks before the image data like PLTE and tRNS, which are shared by all the frames.
+		header   []pngChunk
+		seenData bool
+	)
+	for _, c := range chunks[1:] {
+		switch c.typ {
+		case "acTL":
+			if len(c.data) != 8 {
+				return nil, errors.New("animation: invalid acTL chunk")
+			}
+			animated = true
+			plays = int(binary.BigEndian.Uint32(c.data[4:]))
+		case "fcTL":
+			f, err := parseFCTL(c.data)
+			if err != nil {
+				return nil, err
+			}
+			frames = append(frames, f)
+		case "IDAT":
+			seenData = true
+			// The default image is a part of the animation only when fcTL precedes IDAT.
+			if len(frames) > 0 {
+				f := frames[len(frames)-1]
+				f.data = append(f.data, c.data)
+			}
+		case "fdAT":
+			if len(frames) == 0 || len(c.data) < 4 {
+				return nil, errors.New("animation: invalid fdAT chunk")
+			}
+			f := frames[len(frames)-1]
+			f.data = append(f.data, c.data[4:])
+		case "IEND":
+		default:
+			if !seenData {
+				header = append(header, c)
+			}
+		}
+	}
+
+	if !animated {
+		img, err := png.Decode(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		rgba := image.NewRGBA(img.Bounds().Sub(img.Bounds().Min))
+		draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+		return &decoded{
+			images:    []*image.RGBA{rgba},
+			delays:    []time.Duration{0},
+			loopCount: 1,
+		}, nil
+	}
+
+	w := int(binary.BigEndian.Uint32(ihdr[0:]))
+	h := int(binary.BigEndian.Uint32(ihdr[4:]))
+	d := &decoded{
+		loopCount: plays,
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i, f := range frames {
+		if len(f.data) == 0 {
+			return nil, errors.New("animation: an APNG frame has no image data")
+		}
+		bounds := image.Rect(f.x, f.y, f.x+f.width, f.y+f.height)
+		if !bounds.In(canvas.Bounds()) {
+			return nil, errors.New("animation: an APNG frame is out of the image")
+		}
+
+		// Decode the frame as an individual PNG image with the frame size.
+		var buf bytes.Buffer
+		buf.WriteString(pngHeader)
+		fihdr := make([]byte, len(ihdr))
+		copy(fihdr, ihdr)
+		binary.BigEndian.PutUint32(fihdr[0:], uint32(f.width))
+		binary.BigEndian.PutUint32(fihdr[4:], uint32(f.height))
+		writePNGChunk(&buf, "IHDR", fihdr)
+		for _, c := range header {
+			writePNGChunk(&buf, c.typ, c.data)
+		}
+		for _, data := range f.data {
+			writePNGChunk(&buf, "IDAT", data)
+		}
+		writePNGChunk(&buf, "IEND", nil)
+		img, err := png.Decode(&buf)
+		if err != nil {
+			return nil, err
+		}
+
+		disposal := f.disposal
+		if i == 0 && disposal == apngDisposePrevious {
+			// There is no previous image for the first frame.
+			disposal = apngDisposeBackground
+		}
+		var prev *image.RGBA
+		if disposal == apngDisposePrevious {
+			prev = cloneRGBA(canvas)
+		}
+		op := draw.Over
+		if f.blend == apngBlendSource {
+			op = draw.Src
+		}
+		draw.Draw(canvas, bounds, img, img.Bounds().Min, op)
+		d.images = append(d.images, cloneRGBA(canvas))
+		d.delays = append(d.delays, f.delay)
+
+		switch disposal {
+		case apngDisposeBackground:
+			draw.Draw(canvas, bounds, image.Transparent, image.ZP, draw.Src)
+		case apngDisposePrevious:
+			canvas = prev
+		}
+	}
+	return d, nil
+}
+
+func parseFCTL(b []byte) (*apngFrame, error) {
+	if len(b) != 26 {
+		return nil, errors.New("animation: invalid fcTL chunk")
+	}
+	num := time.Duration(binary.BigEndian.Uint16(b[20:]))
+	den := time.Duration(binary.BigEndian.Uint16(b[22:]))
+	if den == 0 {
+		// A denominator of 0 means 1/100 seconds.
+		den = 100
+	}
+	f := &apngFrame{
+		width:    int(binary.BigEndian.Uint32(b[4:])),
+		height:   int(binary.BigEndian.Uint32(b[8:])),
+		x:        int(binary.BigEndian.Uint32(b[12:])),
+		y:        int(binary.BigEndian.Uint32(b[16:])),
+		delay:    num * time.Second / den,
+		disposal: b[24],
+		blend:    b[25],
+	}
+	if f.width <= 0 || f.height <= 0 {
+		return nil, errors.New("animation: invalid APNG frame size")
+	}
+	return f, nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package animation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+)
+
+type testAPNGFrame struct {
+	image    *image.Paletted
+	delayNum uint16
+	delayDen uint16
+	disposal byte
+	blend    byte
+}
+
+// encodeAPNG encodes the frames into an APNG image of the given size.
+// The first frame is the default image.
+//
+// The frames must have the same palette so that the frames share the header of the first frame.
+func encodeAPNG(t *testing.T, width, height int, plays int, frames []testAPNGFrame) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(pngHeader)
+
+	seq := uint32(0)
+	for i, f := range frames {
+		var b bytes.Buffer
+		if err := png.Encode(&b, f.image); err != nil {
+			t.Fatal(err)
+		}
+		chunks, err := readPNGChunks(b.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			ihdr := make([]byte, 13)
+			copy(ihdr, chunks[0].data)
+			binary.BigEndian.PutUint32(ihdr[0:], uint32(width))
+			binary.BigEndian.PutUint32(ihdr[4:], uint32(height))
+			writePNGChunk(&buf, "IHDR", ihdr)
+
+			actl := make([]byte, 8)
+			binary.BigEndian.PutUint32(actl[0:], uint32(len(frames)))
+			binary.BigEndian.PutUint32(actl[4:], uint32(plays))
+			writePNGChunk(&buf, "acTL", actl)
+
+			// Write the palette.
+			for _, c := range chunks[1:] {
+				if c.typ != "IDAT" && c.typ != "IEND" {
+					writePNGChunk(&buf, c.typ, c.data)
+				}
+			}
+		}
+
+		r := f.image.Bounds()
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(r.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(r.Dy()))
+		binary.BigEndian.PutUint32(fctl[12:], uint32(r.Min.X))
+		binary.BigEndian.PutUint32(fctl[16:], uint32(r.Min.Y))
+		binary.BigEndian.PutUint16(fctl[20:], f.delayNum)
+		binary.BigEndian.PutUint16(fctl[22:], f.delayDen)
+		fctl[24] = f.disposal
+		fctl[25] = f.blend
+		writePNGChunk(&buf, "fcTL", fctl)
+		seq++
+
+		for _, c := range chunks {
+			if c.typ != "IDAT" {
+				continue
+			}
+			if i == 0 {
+				writePNGChunk(&buf, "IDAT", c.data)
+				continue
+			}
+			fdat := make([]byte, 4+len(c.data))
+			binary.BigEndian.PutUint32(fdat, seq)
+			copy(fdat[4:], c.data)
+			writePNGChunk(&buf, "fdAT", fdat)
+			seq++
+		}
+	}
+	writePNGChunk(&buf, "IEND", nil)
+	return buf.Bytes()
+}
+
+var testPalette = color.Palette{color.RGBA{}, red, green, blue}
+
+// filledPaletted returns an image of testPalette filled with the color at the index.
+func filledPaletted(r image.Rectangle, index uint8) *image.Paletted {
+	img := image.NewPaletted(r, testPalette)
+	for i := range img.Pix {
+		img.Pix[i] = index
+	}
+	return img
+}
+
+func TestDecodeAPNG(t *testing.T) {
+	b := encodeAPNG(t, 2, 2, 0, []testAPNGFrame{
+		{
+			image:    filledPaletted(image.Rect(0, 0, 2, 2), 1),
+			delayNum: 1,
+			delayDen: 10,
+			disposal: apngDisposeNone,
+			blend:    apngBlendSource,
+		},
+		{
+			image:    filledPaletted(image.Rect(1, 1, 2, 2), 3),
+			disposal: apngDisposePrevious,
+			blend:    apngBlendOver,
+		},
+		{
+			// A transparent pixel with the source blending clears the pixel.
+			image:    filledPaletted(image.Rect(0, 0, 1, 1), 0),
+			delayNum: 3,
+			disposal: apngDisposeNone,
+			blend:    apngBlendSource,
+		},
+	})
+
+	d, err := decodeAPNG(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(d.images), 3; got != want {
+		t.Fatalf("len(images): got: %d, want: %d", got, want)
+	}
+	checkFrame(t, "frame 0", d.images[0], [4]color.RGBA{red, red, red, red})
+	checkFrame(t, "frame 1", d.images[1], [4]color.RGBA{red, red, red, blue})
+	// The blue pixel is disposed to the previous image.
+	checkFrame(t, "frame 2", d.images[2], [4]color.RGBA{{}, red, red, red})
+
+	// The denominator 0 means 1/100 seconds.
+	wantDelays := []time.Duration{100 * time.Millisecond, 0, 30 * time.Millisecond}
+	for i, want := range wantDelays {
+		if got := d.delays[i]; got != want {
+			t.Errorf("delays[%d]: got: %v, want: %v", i, got, want)
+		}
+	}
+	if got, want := d.loopCount, 0; got != want {
+		t.Errorf("loopCount: got: %d, want: %d", got, want)
+	}
+}
+
+func TestDecodeAPNGStatic(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, filledPaletted(image.Rect(0, 0, 2, 2), 2)); err != nil {
+		t.Fatal(err)
+	}
+	d, err := decodeAPNG(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(d.images), 1; got != want {
+		t.Fatalf("len(images): got: %d, want: %d", got, want)
+	}
+	checkFrame(t, "frame 0", d.images[0], [4]color.RGBA{green, green, green, green})
+}
+
+func TestDecodeAPNGOutOfBounds(t *testing.T) {
+	b := encodeAPNG(t, 2, 2, 0, []testAPNGFrame{
+		{
+			image: filledPaletted(image.Rect(1, 1, 3, 3), 1),
+		},
+	})
+	if _, err := decodeAPNG(bytes.NewReader(b)); err == nil {
+		t.Errorf("decodeAPNG with an out-of-bounds frame must return an error")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package animation
+
+import (
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// DecodeGIF decodes an animated GIF image into a sheet.
+//
+// The frames are composited with their disposal methods, so each frame of the sheet is a whole image.
+func DecodeGIF(r io.Reader, filter ebiten.Filter) (*Sheet, error) {
+	d, err := decodeGIF(r)
+	if err != nil {
+		return nil, err
+	}
+	return newSheet(d, filter)
+}
+
+func decodeGIF(r io.Reader) (*decoded, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decoded{}
+	switch {
+	case g.LoopCount == 0:
+		d.loopCount = 0
+	case g.LoopCount < 0:
+		d.loopCount = 1
+	default:
+		// The animation is restarted LoopCount times.
+		d.loopCount = g.LoopCount + 1
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	var prev *image.RGBA
+	for i, p := range g.Image {
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			prev = cloneRGBA(canvas)
+		}
+		draw.Draw(canvas, p.Bounds(), p, p.Bounds().Min, draw.Over)
+		d.images = append(d.images, cloneRGBA(canvas))
+
+		// As browsers do, a delay of 10ms or less is treated as 100ms.
+		delay := g.Delay[i]
+		if delay <= 1 {
+			delay = 10
+		}
+		d.delays = append(d.delays, time.Duration(delay)*10*time.Millisecond)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			// The background is treated as transparent as browsers do.
+			draw.Draw(canvas, p.Bounds(), image.Transparent, image.ZP, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = prev
+		}
+	}
+	return d, nil
+}
+
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	c := image.NewRGBA(img.Bounds())
+	copy(c.Pix, img.Pix)
+	return c
+}
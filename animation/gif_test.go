@@ -0,0 +1,87 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package animation
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+	"time"
+)
+
+var (
+	red   = color.RGBA{0xff, 0, 0, 0xff}
+	green = color.RGBA{0, 0xff, 0, 0xff}
+	blue  = color.RGBA{0, 0, 0xff, 0xff}
+)
+
+// checkFrame checks the pixels of a 2x2 frame in row-major order.
+func checkFrame(t *testing.T, name string, img *image.RGBA, want [4]color.RGBA) {
+	for i, w := range want {
+		x, y := i%2, i/2
+		if got := img.RGBAAt(x, y); got != w {
+			t.Errorf("%s: At(%d, %d): got: %v, want: %v", name, x, y, got, w)
+		}
+	}
+}
+
+func TestDecodeGIF(t *testing.T) {
+	p := color.Palette{color.Transparent, red, green, blue}
+	frame := func(r image.Rectangle, index uint8) *image.Paletted {
+		img := image.NewPaletted(r, p)
+		for i := range img.Pix {
+			img.Pix[i] = index
+		}
+		return img
+	}
+	g := &gif.GIF{
+		Image: []*image.Paletted{
+			frame(image.Rect(0, 0, 2, 2), 1),
+			frame(image.Rect(1, 1, 2, 2), 3),
+			frame(image.Rect(0, 0, 1, 1), 2),
+		},
+		Delay:     []int{5, 0, 20},
+		Disposal:  []byte{gif.DisposalNone, gif.DisposalBackground, gif.DisposalNone},
+		LoopCount: 2,
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := decodeGIF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(d.images), 3; got != want {
+		t.Fatalf("len(images): got: %d, want: %d", got, want)
+	}
+	checkFrame(t, "frame 0", d.images[0], [4]color.RGBA{red, red, red, red})
+	checkFrame(t, "frame 1", d.images[1], [4]color.RGBA{red, red, red, blue})
+	// The blue pixel is disposed to the transparent background.
+	checkFrame(t, "frame 2", d.images[2], [4]color.RGBA{green, red, red, {}})
+
+	wantDelays := []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond}
+	for i, want := range wantDelays {
+		if got := d.delays[i]; got != want {
+			t.Errorf("delays[%d]: got: %v, want: %v", i, got, want)
+		}
+	}
+	if got, want := d.loopCount, 3; got != want {
+		t.Errorf("loopCount: got: %d, want: %d", got, want)
+	}
+}
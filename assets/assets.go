@@ -0,0 +1,290 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package assets provides an asynchronous asset loader.
+//
+// A Loader decodes images and audio on worker goroutines, and then creates the images and the audio players
+// in Update on the game's goroutine. As the pixels of the images are uploaded to GPU little by little
+// across frames, loading many assets doesn't freeze a frame, and a loading screen can show the progress.
+//
+// A FileSystem abstracts where the asset files are, e.g. byte slices embedded in the binary, a zip archive
+// appended to the binary, a directory or a web server, so that the same loading code works on all the platforms.
+//
+// Note: This package is experimental and API might be changed.
+package assets
+
+import (
+	"image"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/audio"
+	"github.com/hajimehoshi/ebiten/internal/graphicsutil"
+)
+
+// DefaultBytesPerFrame is the default maximum size in bytes of the pixels uploaded in one Update call.
+const DefaultBytesPerFrame = 4 * 1024 * 1024
+
+// An OpenFunc opens the source of an asset, e.g. a file.
+//
+// OpenFunc is called on a worker goroutine.
+type OpenFunc func() (io.ReadCloser, error)
+
+// An AudioDecoder decodes the audio source src into a stream of the PCM bytes.
+//
+// For example, a function returning the result of wav.Decode or vorbis.Decode can be an AudioDecoder.
+// AudioDecoder is called on a worker goroutine.
+type AudioDecoder func(context *audio.Context, src audio.ReadSeekCloser) (io.Reader, error)
+
+// A Loader loads assets on worker goroutines.
+//
+// The functions of a Loader must be called on the game's goroutine, e.g. in the update function.
+type Loader struct {
+	// BytesPerFrame is the maximum size in bytes of the pixels uploaded in one Update call.
+	// At least one image is created in an Update call even if the image is larger than BytesPerFrame.
+	//
+	// If BytesPerFrame is 0 or less, DefaultBytesPerFrame is used.
+	BytesPerFrame int
+
+	// Workers is the maximum number of the worker goroutines.
+	//
+	// If Workers is 0 or less, runtime.NumCPU() is used.
+	Workers int
+
+	sem    chan struct{}
+	total  int
+	loaded int
+
+	// decoded is the assets decoded on the workers and waiting for Update.
+	decoded []asset
+	m       sync.Mutex
+}
+
+type asset interface {
+	// size returns the size in bytes uploaded to GPU at finalize.
+	size() int
+
+	// finalize creates the asset on the game's goroutine.
+	finalize()
+}
+
+// An Image is an image asset loaded by a Loader.
+type Image struct {
+	pixels []byte
+	width  int
+	height int
+	filter ebiten.Filter
+
+	image  *ebiten.Image
+	err    error
+	loaded bool
+}
+
+// Loaded reports whether the image has been loaded or failed to be loaded.
+func (i *Image) Loaded() bool {
+	return i.loaded
+}
+
+// Image returns the loaded image. Image returns nil until the image is loaded or when loading failed.
+func (i *Image) Image() *ebiten.Image {
+	return i.image
+}
+
+// Err returns the error occurred while loading the image. Err returns nil until the image is loaded.
+func (i *Image) Err() error {
+	if !i.loaded {
+		return nil
+	}
+	return i.err
+}
+
+func (i *Image) size() int {
+	return len(i.pixels)
+}
+
+func (i *Image) finalize() {
+	defer func() {
+		i.pixels = nil
+		i.loaded = true
+	}()
+	if i.err != nil {
+		return
+	}
+	img, _ := ebiten.NewImage(i.width, i.height, i.filter)
+	_ = img.ReplacePixels(i.pixels)
+	i.image = img
+}
+
+// An Audio is an audio asset loaded by a Loader.
+type Audio struct {
+	context *audio.Context
+	pcm     []byte
+
+	player *audio.Player
+	err    error
+	loaded bool
+}
+
+// Loaded reports whether the audio has been loaded or failed to be loaded.
+func (a *Audio) Loaded() bool {
+	return a.loaded
+}
+
+// Player returns a player of the loaded audio. Player returns nil until the audio is loaded or when loading failed.
+func (a *Audio) Player() *audio.Player {
+	return a.player
+}
+
+// Err returns the error occurred while loading the audio. Err returns nil until the audio is loaded.
+func (a *Audio) Err() error {
+	if !a.loaded {
+		return nil
+	}
+	return a.err
+}
+
+func (a *Audio) size() int {
+	return 0
+}
+
+func (a *Audio) finalize() {
+	defer func() {
+		a.pcm = nil
+		a.loaded = true
+	}()
+	if a.err != nil {
+		return
+	}
+	a.player, a.err = audio.NewPlayerFromBytes(a.context, a.pcm)
+}
+
+// LoadImage starts loading the image from the source opened by open.
+//
+// The image is decoded with image.Decode on a worker goroutine, so the decoders of the image formats
+// must be registered, e.g. by importing image/png.
+func (l *Loader) LoadImage(open OpenFunc, filter ebiten.Filter) *Image {
+	i := &Image{
+		filter: filter,
+	}
+	l.start(i, func() {
+		img, err := decodeImage(open)
+		if err != nil {
+			i.err = err
+			return
+		}
+		size := img.Bounds().Size()
+		i.width, i.height = size.X, size.Y
+		i.pixels = graphicsutil.CopyImage(img)
+	})
+	return i
+}
+
+func decodeImage(open OpenFunc) (image.Image, error) {
+	r, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+// LoadAudio starts loading the audio from the source opened by open.
+//
+// The whole audio is decoded with decode on a worker goroutine.
+func (l *Loader) LoadAudio(context *audio.Context, open OpenFunc, decode AudioDecoder) *Audio {
+	a := &Audio{
+		context: context,
+	}
+	l.start(a, func() {
+		a.pcm, a.err = decodeAudio(context, open, decode)
+	})
+	return a
+}
+
+func decodeAudio(context *audio.Context, open OpenFunc, decode AudioDecoder) ([]byte, error) {
+	r, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	s, err := decode(context, audio.BytesReadSeekCloser(src))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(s)
+}
+
+// start runs decode for a on a worker goroutine.
+func (l *Loader) start(a asset, decode func()) {
+	if l.sem == nil {
+		n := l.Workers
+		if n <= 0 {
+			n = runtime.NumCPU()
+		}
+		l.sem = make(chan struct{}, n)
+	}
+	l.total++
+	go func() {
+		l.sem <- struct{}{}
+		decode()
+		<-l.sem
+
+		l.m.Lock()
+		l.decoded = append(l.decoded, a)
+		l.m.Unlock()
+	}()
+}
+
+// Update creates the decoded assets up to BytesPerFrame bytes.
+//
+// Update must be called every frame while loading, e.g. in the update function.
+func (l *Loader) Update() {
+	max := l.BytesPerFrame
+	if max <= 0 {
+		max = DefaultBytesPerFrame
+	}
+
+	l.m.Lock()
+	n := 0
+	bytes := 0
+	for _, a := range l.decoded {
+		if n > 0 && bytes+a.size() > max {
+			break
+		}
+		bytes += a.size()
+		n++
+	}
+	as := l.decoded[:n]
+	l.decoded = l.decoded[n:]
+	l.m.Unlock()
+
+	for _, a := range as {
+		a.finalize()
+	}
+	l.loaded += len(as)
+}
+
+// Progress returns the number of the loaded assets, including the ones failed to be loaded,
+// and the total number of the assets requested to load.
+func (l *Loader) Progress() (loaded, total int) {
+	return l.loaded, l.total
+}
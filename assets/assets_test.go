@@ -0,0 +1,127 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assets
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+func pngOpenFunc(t *testing.T, w, h int, clr color.Color) OpenFunc {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			img.Set(i, j, clr)
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	return func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+}
+
+func waitForLoading(t *testing.T, l *Loader, f func()) {
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		l.Update()
+		if f != nil {
+			f()
+		}
+		if loaded, total := l.Progress(); loaded == total {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("loading timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLoadImage(t *testing.T) {
+	l := &Loader{}
+	clr := color.RGBA{0xff, 0x80, 0, 0xff}
+	img := l.LoadImage(pngOpenFunc(t, 4, 2, clr), ebiten.FilterDefault)
+	errOpen := errors.New("open failed")
+	failed := l.LoadImage(func() (io.ReadCloser, error) {
+		return nil, errOpen
+	}, ebiten.FilterDefault)
+
+	if _, total := l.Progress(); total != 2 {
+		t.Errorf("total: got: %d, want: 2", total)
+	}
+	waitForLoading(t, l, nil)
+
+	if !img.Loaded() {
+		t.Fatal("Loaded(): got: false, want: true")
+	}
+	if err := img.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := img.Image().Size(); w != 4 || h != 2 {
+		t.Errorf("Size(): got: (%d, %d), want: (4, 2)", w, h)
+	}
+	if got := img.Image().At(3, 1); got != clr {
+		t.Errorf("At(3, 1): got: %v, want: %v", got, clr)
+	}
+
+	if !failed.Loaded() {
+		t.Fatal("Loaded(): got: false, want: true")
+	}
+	if got := failed.Err(); got != errOpen {
+		t.Errorf("Err(): got: %v, want: %v", got, errOpen)
+	}
+	if failed.Image() != nil {
+		t.Errorf("Image(): got: non-nil, want: nil")
+	}
+}
+
+func TestLoadImageBudget(t *testing.T) {
+	const num = 4
+	l := &Loader{
+		// Each image is 4 * 8 * 8 = 256 bytes.
+		BytesPerFrame: 300,
+	}
+	imgs := make([]*Image, num)
+	for i := range imgs {
+		imgs[i] = l.LoadImage(pngOpenFunc(t, 8, 8, color.White), ebiten.FilterDefault)
+	}
+
+	last := 0
+	waitForLoading(t, l, func() {
+		loaded, _ := l.Progress()
+		if loaded-last > 1 {
+			t.Errorf("loaded in one Update: got: %d, want: <= 1", loaded-last)
+		}
+		last = loaded
+	})
+	for i, img := range imgs {
+		if img.Image() == nil {
+			t.Errorf("imgs[%d].Image(): got: nil, want: non-nil", i)
+		}
+	}
+}
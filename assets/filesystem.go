@@ -0,0 +1,218 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assets
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+// A File is a file opened by a FileSystem.
+type File interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// A FileSystem provides the asset files.
+//
+// The names of the files are slash-separated paths relative to the root of the file system, e.g. "images/player.png",
+// on all the platforms. If the file doesn't exist, Open returns an error for which os.IsNotExist reports true,
+// except for Dir on browsers.
+//
+// The same loading code works with any FileSystem. For example, a game can use Dir while developing and
+// switch to Bytes or Executable for distribution.
+type FileSystem interface {
+	Open(name string) (File, error)
+}
+
+// Open returns an OpenFunc to open the file name in fsys.
+func Open(fsys FileSystem, name string) OpenFunc {
+	return func() (io.ReadCloser, error) {
+		return fsys.Open(name)
+	}
+}
+
+// ReadFile reads the whole content of the file name in fsys, e.g. to parse a font.
+func ReadFile(fsys FileSystem, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// cleanName returns the canonical form of name without the leading slash.
+//
+// cleanName never returns a path going up beyond the root.
+func cleanName(name string) string {
+	return path.Clean("/" + name)[1:]
+}
+
+func notExist(name string) error {
+	return &os.PathError{
+		Op:   "open",
+		Path: name,
+		Err:  os.ErrNotExist,
+	}
+}
+
+type bytesFile struct {
+	*bytes.Reader
+}
+
+func (b *bytesFile) Close() error {
+	return nil
+}
+
+// Bytes is a FileSystem of the files embedded in the binary as byte slices, e.g. by file2byteslice.
+// The keys are the names of the files.
+type Bytes map[string][]byte
+
+// Open implements FileSystem.
+func (b Bytes) Open(name string) (File, error) {
+	bs, ok := b[cleanName(name)]
+	if !ok {
+		return nil, notExist(name)
+	}
+	return &bytesFile{bytes.NewReader(bs)}, nil
+}
+
+// Dir is a FileSystem of the files in a directory.
+//
+// On browsers, the path of the directory is relative to the URL of the page, and the files are fetched
+// by XMLHttpRequest.
+type Dir string
+
+// Open implements FileSystem.
+func (d Dir) Open(name string) (File, error) {
+	f, err := ebitenutil.OpenFile(path.Join(string(d), cleanName(name)))
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+type zipFileSystem struct {
+	files map[string]*zip.File
+}
+
+// Zip returns a FileSystem of the files in a zip archive r, whose size is size.
+//
+// The archive may have arbitrary data before it, e.g. an executable the archive is appended to.
+func Zip(r io.ReaderAt, size int64) (FileSystem, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	z := &zipFileSystem{
+		files: map[string]*zip.File{},
+	}
+	for _, f := range zr.File {
+		if strings.HasSuffix(f.Name, "/") {
+			// Skip directories.
+			continue
+		}
+		z.files[cleanName(f.Name)] = f
+	}
+	return z, nil
+}
+
+// Open implements FileSystem.
+func (z *zipFileSystem) Open(name string) (File, error) {
+	f, ok := z.files[cleanName(name)]
+	if !ok {
+		return nil, notExist(name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// A file in an archive is not seekable. Read the whole content instead.
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return &bytesFile{bytes.NewReader(bs)}, nil
+}
+
+// Executable returns a FileSystem of the files in a zip archive appended to the running executable.
+//
+// The archive can be appended after building, e.g.:
+//
+//     cat assets.zip >> game
+//
+// The executable file is kept open while the program runs.
+//
+// Executable returns error on browsers and on mobiles, or when no archive is appended.
+func Executable() (FileSystem, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return nil, err
+	}
+	s, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	z, err := Zip(f, s.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return z, nil
+}
+
+// HTTP is a FileSystem of the files under a base URL, e.g. "https://example.com/assets".
+//
+// The files are fetched by net/http, which uses the Fetch API on browsers.
+type HTTP string
+
+// Open implements FileSystem.
+func (h HTTP) Open(name string) (File, error) {
+	url := strings.TrimSuffix(string(h), "/") + "/" + cleanName(name)
+	res, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, notExist(name)
+	}
+	if res.StatusCode < 200 || 400 <= res.StatusCode {
+		return nil, fmt.Errorf("assets: http error: %s: %d", url, res.StatusCode)
+	}
+	bs, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &bytesFile{bytes.NewReader(bs)}, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assets
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+var testFiles = map[string]string{
+	"foo.txt":        "foo",
+	"images/bar.png": "bar",
+}
+
+func testFileSystem(t *testing.T, fsys FileSystem) {
+	for name, want := range testFiles {
+		bs, err := ReadFile(fsys, name)
+		if err != nil {
+			t.Errorf("ReadFile(%q): %v", name, err)
+			continue
+		}
+		if got := string(bs); got != want {
+			t.Errorf("ReadFile(%q): got: %q, want: %q", name, got, want)
+		}
+	}
+
+	// A name is cleaned.
+	bs, err := ReadFile(fsys, "/images/../foo.txt")
+	if err != nil {
+		t.Errorf("ReadFile(%q): %v", "/images/../foo.txt", err)
+	} else if got, want := string(bs), "foo"; got != want {
+		t.Errorf("ReadFile(%q): got: %q, want: %q", "/images/../foo.txt", got, want)
+	}
+
+	if _, err := fsys.Open("missing.txt"); !os.IsNotExist(err) {
+		t.Errorf("Open(%q): got: %v, want: not-exist error", "missing.txt", err)
+	}
+
+	// A File is seekable, e.g. for audio decoders.
+	f, err := fsys.Open("foo.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	bs, err = ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(bs), "oo"; got != want {
+		t.Errorf("content after Seek: got: %q, want: %q", got, want)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	b := Bytes{}
+	for name, content := range testFiles {
+		b[name] = []byte(content)
+	}
+	testFileSystem(t, b)
+}
+
+func TestDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ebiten")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range testFiles {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testFileSystem(t, Dir(filepath.ToSlash(dir)))
+}
+
+func TestZip(t *testing.T) {
+	// Put arbitrary data before the archive as an executable.
+	buf := bytes.NewBufferString("executable")
+	w := zip.NewWriter(buf)
+	if _, err := w.Create("images/"); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range testFiles {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := Zip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testFileSystem(t, z)
+}
+
+func TestHTTP(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := testFiles[r.URL.Path[len("/assets/"):]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	defer s.Close()
+
+	testFileSystem(t, HTTP(s.URL+"/assets/"))
+}
+
+func TestOpen(t *testing.T) {
+	l := &Loader{}
+	img := l.LoadImage(Open(Bytes{}, "missing.png"), ebiten.FilterDefault)
+	waitForLoading(t, l, nil)
+	if !os.IsNotExist(img.Err()) {
+		t.Errorf("Err(): got: %v, want: not-exist error", img.Err())
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package atlas provides sprite atlases made by tools like TexturePacker and Aseprite.
+//
+// ParseJSON parses the metadata of an atlas. The atlas image is loaded separately, e.g. by ebitenutil.NewImageFromFile.
+// The named sprites are drawn with DrawImageOptions.SourceRect, so that the sprites of an atlas can be drawn as batches.
+//
+// Note: This package is experimental and API might be changed.
+package atlas
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/animation"
+)
+
+// The directions of a tag.
+const (
+	DirectionForward  = "forward"
+	DirectionReverse  = "reverse"
+	DirectionPingPong = "pingpong"
+)
+
+// Atlas represents the metadata of a sprite atlas.
+type Atlas struct {
+	// Image is the path of the atlas image.
+	Image string
+
+	// Sprites are the sprites in the order of the metadata.
+	Sprites []*Sprite
+
+	// Tags are the animation tags of Aseprite.
+	Tags []*Tag
+
+	names map[string]*Sprite
+}
+
+// Sprite represents a named region in an atlas.
+type Sprite struct {
+	Name string
+
+	// Rect is the region of the sprite in the atlas image.
+	//
+	// If Rotated is true, the sprite is rotated by 90 degrees clockwise in the atlas image,
+	// i.e. the width and the height of Rect are the height and the width of the sprite.
+	Rect    image.Rectangle
+	Rotated bool
+
+	// Offset is the position of the trimmed sprite in the original sprite.
+	Offset image.Point
+
+	// SourceSize is the size of the original sprite before trimming.
+	SourceSize image.Point
+
+	// Duration is the duration of the frame in an animation. Duration is 0 if not specified.
+	Duration time.Duration
+}
+
+// Tag represents an animation tag of Aseprite, which specifies the frames of an animation.
+type Tag struct {
+	Name string
+
+	// From and To are the indices of the first and the last sprites of the animation.
+	From int
+	To   int
+
+	// Direction is DirectionForward, DirectionReverse or DirectionPingPong.
+	Direction string
+}
+
+// Sprite returns the sprite of the name. If there is no such sprite, Sprite returns nil.
+func (a *Atlas) Sprite(name string) *Sprite {
+	return a.names[name]
+}
+
+// Tag returns the tag of the name. If there is no such tag, Tag returns nil.
+func (a *Atlas) Tag(name string) *Tag {
+	for _, t := range a.Tags {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// GeoM returns the geometry matrix to draw the region Rect of the atlas image as the original sprite.
+// The trimmed offset and the rotation are restored.
+func (s *Sprite) GeoM() ebiten.GeoM {
+	var g ebiten.GeoM
+	if s.Rotated {
+		g.Rotate(-math.Pi / 2)
+		g.Translate(0, float64(s.Rect.Dx()))
+	}
+	g.Translate(float64(s.Offset.X), float64(s.Offset.Y))
+	return g
+}
+
+// Draw draws the sprite in the atlas image img on the target with the options op.
+// The sprite is drawn as the original sprite at the origin, and then transformed with op.GeoM.
+//
+// op can be nil. op is not modified, and op.SourceRect is ignored.
+func (s *Sprite) Draw(target, img *ebiten.Image, op *ebiten.DrawImageOptions) {
+	o := &ebiten.DrawImageOptions{}
+	if op != nil {
+		*o = *op
+	}
+	r := s.Rect
+	o.SourceRect = &r
+	o.GeoM = s.GeoM()
+	if op != nil {
+		o.GeoM.Concat(op.GeoM)
+	}
+	_ = target.DrawImage(img, o)
+}
+
+// Sheet returns an animation sheet of the tag with the atlas image img.
+//
+// The sprites of the animation must be neither rotated nor trimmed.
+func (a *Atlas) Sheet(img *ebiten.Image, tag string) (*animation.Sheet, error) {
+	t := a.Tag(tag)
+	if t == nil {
+		return nil, fmt.Errorf("atlas: tag %q not found", tag)
+	}
+	if t.From < 0 || t.To < t.From || len(a.Sprites) <= t.To {
+		return nil, fmt.Errorf("atlas: tag %q has an invalid range [%d, %d]", tag, t.From, t.To)
+	}
+
+	var indices []int
+	switch t.Direction {
+	case DirectionForward, "":
+		for i := t.From; i <= t.To; i++ {
+			indices = append(indices, i)
+		}
+	case DirectionReverse:
+		for i := t.To; i >= t.From; i-- {
+			indices = append(indices, i)
+		}
+	case DirectionPingPong:
+		for i := t.From; i <= t.To; i++ {
+			indices = append(indices, i)
+		}
+		// The both ends are not repeated.
+		for i := t.To - 1; i > t.From; i-- {
+			indices = append(indices, i)
+		}
+	default:
+		return nil, fmt.Errorf("atlas: tag %q has an unknown direction %q", tag, t.Direction)
+	}
+
+	s := &animation.Sheet{
+		Image: img,
+	}
+	for _, i := range indices {
+		sp := a.Sprites[i]
+		if sp.Rotated || sp.Offset != (image.Point{}) || sp.Rect.Size() != sp.SourceSize {
+			return nil, fmt.Errorf("atlas: sprite %q of an animation must be neither rotated nor trimmed", sp.Name)
+		}
+		s.Frames = append(s.Frames, animation.Frame{
+			Rect:  sp.Rect,
+			Delay: sp.Duration,
+		})
+	}
+	return s, nil
+}
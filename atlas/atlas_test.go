@@ -0,0 +1,104 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atlas
+
+import (
+	"image"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSpriteGeoM(t *testing.T) {
+	// A 10x20 sprite rotated in the 20x10 region at (0, 0).
+	s := &Sprite{
+		Rect:       image.Rect(0, 0, 20, 10),
+		Rotated:    true,
+		Offset:     image.Pt(1, 2),
+		SourceSize: image.Pt(11, 22),
+	}
+	g := s.GeoM()
+	cases := []struct {
+		X, Y         float64
+		WantX, WantY float64
+	}{
+		// The top-right corner of the region is the top-left corner of the sprite.
+		{20, 0, 1, 2},
+		{0, 0, 1, 22},
+		{20, 10, 11, 2},
+		{0, 10, 11, 22},
+	}
+	for _, c := range cases {
+		x, y := g.Apply(c.X, c.Y)
+		if math.Abs(x-c.WantX) > 1e-9 || math.Abs(y-c.WantY) > 1e-9 {
+			t.Errorf("Apply(%v, %v): got: (%v, %v), want: (%v, %v)", c.X, c.Y, x, y, c.WantX, c.WantY)
+		}
+	}
+}
+
+func TestAtlasSheet(t *testing.T) {
+	a := &Atlas{}
+	for i := 0; i < 4; i++ {
+		a.Sprites = append(a.Sprites, &Sprite{
+			Rect:       image.Rect(i*8, 0, (i+1)*8, 8),
+			SourceSize: image.Pt(8, 8),
+			Duration:   time.Duration(i+1) * time.Millisecond,
+		})
+	}
+	a.Tags = []*Tag{
+		{Name: "forward", From: 1, To: 3, Direction: DirectionForward},
+		{Name: "reverse", From: 1, To: 3, Direction: DirectionReverse},
+		{Name: "pingpong", From: 0, To: 3, Direction: DirectionPingPong},
+		{Name: "invalid", From: 2, To: 4},
+	}
+	cases := []struct {
+		Tag  string
+		Want []int
+	}{
+		{"forward", []int{1, 2, 3}},
+		{"reverse", []int{3, 2, 1}},
+		{"pingpong", []int{0, 1, 2, 3, 2, 1}},
+	}
+	for _, c := range cases {
+		s, err := a.Sheet(nil, c.Tag)
+		if err != nil {
+			t.Errorf("Sheet(%q): %v", c.Tag, err)
+			continue
+		}
+		if len(s.Frames) != len(c.Want) {
+			t.Errorf("Sheet(%q): len(Frames): got: %d, want: %d", c.Tag, len(s.Frames), len(c.Want))
+			continue
+		}
+		for i, idx := range c.Want {
+			if got, want := s.Frames[i].Rect, a.Sprites[idx].Rect; got != want {
+				t.Errorf("Sheet(%q): Frames[%d].Rect: got: %v, want: %v", c.Tag, i, got, want)
+			}
+			if got, want := s.Frames[i].Delay, a.Sprites[idx].Duration; got != want {
+				t.Errorf("Sheet(%q): Frames[%d].Delay: got: %v, want: %v", c.Tag, i, got, want)
+			}
+		}
+	}
+
+	if _, err := a.Sheet(nil, "invalid"); err == nil {
+		t.Errorf("Sheet(%q) must return an error", "invalid")
+	}
+	if _, err := a.Sheet(nil, "missing"); err == nil {
+		t.Errorf("Sheet(%q) must return an error", "missing")
+	}
+	a.Sprites[2].Offset = image.Pt(1, 0)
+	if _, err := a.Sheet(nil, "forward"); err == nil {
+		t.Errorf("Sheet with a trimmed sprite must return an error")
+	}
+}
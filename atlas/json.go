@@ -0,0 +1,152 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atlas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"time"
+)
+
+type jsonRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type jsonFrame struct {
+	Filename         string   `json:"filename"`
+	Frame            jsonRect `json:"frame"`
+	Rotated          bool     `json:"rotated"`
+	Trimmed          bool     `json:"trimmed"`
+	SpriteSourceSize jsonRect `json:"spriteSourceSize"`
+	SourceSize       struct {
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"sourceSize"`
+
+	// Duration is the duration of the frame in milliseconds, which is exported by Aseprite.
+	Duration int `json:"duration"`
+}
+
+type jsonAtlas struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image     string `json:"image"`
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"`
+		} `json:"frameTags"`
+	} `json:"meta"`
+}
+
+// ParseJSON parses the metadata of an atlas in the JSON formats of TexturePacker and Aseprite.
+//
+// Both the Hash and the Array formats are supported. In the Hash format, the order of the sprites is kept
+// as the tags of Aseprite refer to the sprites by the indices.
+func ParseJSON(r io.Reader) (*Atlas, error) {
+	var j jsonAtlas
+	if err := json.NewDecoder(r).Decode(&j); err != nil {
+		return nil, err
+	}
+	frames, err := parseJSONFrames(j.Frames)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Atlas{
+		Image: j.Meta.Image,
+		names: map[string]*Sprite{},
+	}
+	for _, f := range frames {
+		s := &Sprite{
+			Name:     f.Filename,
+			Rotated:  f.Rotated,
+			Duration: time.Duration(f.Duration) * time.Millisecond,
+		}
+		w, h := f.Frame.W, f.Frame.H
+		if f.Rotated {
+			// The size of the frame is the size of the sprite before rotated.
+			w, h = h, w
+		}
+		s.Rect = image.Rect(f.Frame.X, f.Frame.Y, f.Frame.X+w, f.Frame.Y+h)
+		if f.Trimmed {
+			s.Offset = image.Pt(f.SpriteSourceSize.X, f.SpriteSourceSize.Y)
+		}
+		s.SourceSize = image.Pt(f.SourceSize.W, f.SourceSize.H)
+		if s.SourceSize == (image.Point{}) {
+			s.SourceSize = image.Pt(f.Frame.W, f.Frame.H)
+		}
+		if _, ok := a.names[s.Name]; ok {
+			return nil, fmt.Errorf("atlas: duplicated sprite name %q", s.Name)
+		}
+		a.Sprites = append(a.Sprites, s)
+		a.names[s.Name] = s
+	}
+	for _, t := range j.Meta.FrameTags {
+		a.Tags = append(a.Tags, &Tag{
+			Name:      t.Name,
+			From:      t.From,
+			To:        t.To,
+			Direction: t.Direction,
+		})
+	}
+	return a, nil
+}
+
+// parseJSONFrames parses the frames in the Hash format (an object) or the Array format (an array).
+func parseJSONFrames(data json.RawMessage) ([]*jsonFrame, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("atlas: frames not found")
+	}
+
+	if data[0] == '[' {
+		var frames []*jsonFrame
+		if err := json.Unmarshal(data, &frames); err != nil {
+			return nil, err
+		}
+		return frames, nil
+	}
+
+	// Decode the object token by token to keep the order of the keys.
+	d := json.NewDecoder(bytes.NewReader(data))
+	if t, err := d.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, errors.New("atlas: frames must be an object or an array")
+	}
+	var frames []*jsonFrame
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		f := &jsonFrame{}
+		if err := d.Decode(f); err != nil {
+			return nil, err
+		}
+		f.Filename = t.(string)
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
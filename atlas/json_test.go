@@ -0,0 +1,131 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atlas
+
+import (
+	"image"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The keys are not sorted to test that the order is kept.
+const texturePackerHash = `{
+	"frames": {
+		"tree.png": {
+			"frame": {"x": 0, "y": 0, "w": 10, "h": 20},
+			"rotated": true,
+			"trimmed": false,
+			"spriteSourceSize": {"x": 0, "y": 0, "w": 10, "h": 20},
+			"sourceSize": {"w": 10, "h": 20}
+		},
+		"bush.png": {
+			"frame": {"x": 20, "y": 0, "w": 8, "h": 6},
+			"rotated": false,
+			"trimmed": true,
+			"spriteSourceSize": {"x": 1, "y": 2, "w": 8, "h": 6},
+			"sourceSize": {"w": 10, "h": 10}
+		}
+	},
+	"meta": {
+		"image": "sprites.png",
+		"size": {"w": 32, "h": 32}
+	}
+}`
+
+const asepriteArray = `{
+	"frames": [
+		{"filename": "run 0", "frame": {"x": 0, "y": 0, "w": 16, "h": 16}, "duration": 100},
+		{"filename": "run 1", "frame": {"x": 16, "y": 0, "w": 16, "h": 16}, "duration": 150},
+		{"filename": "run 2", "frame": {"x": 32, "y": 0, "w": 16, "h": 16}, "duration": 100}
+	],
+	"meta": {
+		"image": "run.png",
+		"frameTags": [
+			{"name": "run", "from": 0, "to": 2, "direction": "pingpong"}
+		]
+	}
+}`
+
+func TestParseJSONHash(t *testing.T) {
+	a, err := ParseJSON(strings.NewReader(texturePackerHash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.Image, "sprites.png"; got != want {
+		t.Errorf("Image: got: %q, want: %q", got, want)
+	}
+	want := []Sprite{
+		{
+			Name:       "tree.png",
+			Rect:       image.Rect(0, 0, 20, 10),
+			Rotated:    true,
+			SourceSize: image.Pt(10, 20),
+		},
+		{
+			Name:       "bush.png",
+			Rect:       image.Rect(20, 0, 28, 6),
+			Offset:     image.Pt(1, 2),
+			SourceSize: image.Pt(10, 10),
+		},
+	}
+	if got := len(a.Sprites); got != len(want) {
+		t.Fatalf("len(Sprites): got: %d, want: %d", got, len(want))
+	}
+	for i := range want {
+		if got := *a.Sprites[i]; got != want[i] {
+			t.Errorf("Sprites[%d]: got: %+v, want: %+v", i, got, want[i])
+		}
+	}
+	if got := a.Sprite("bush.png"); got != a.Sprites[1] {
+		t.Errorf("Sprite(%q): got: %p, want: %p", "bush.png", got, a.Sprites[1])
+	}
+	if got := a.Sprite("rock.png"); got != nil {
+		t.Errorf("Sprite(%q): got: %p, want: nil", "rock.png", got)
+	}
+}
+
+func TestParseJSONArray(t *testing.T) {
+	a, err := ParseJSON(strings.NewReader(asepriteArray))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDurations := []time.Duration{100 * time.Millisecond, 150 * time.Millisecond, 100 * time.Millisecond}
+	if got := len(a.Sprites); got != len(wantDurations) {
+		t.Fatalf("len(Sprites): got: %d, want: %d", got, len(wantDurations))
+	}
+	for i, want := range wantDurations {
+		if got := a.Sprites[i].Duration; got != want {
+			t.Errorf("Sprites[%d].Duration: got: %v, want: %v", i, got, want)
+		}
+		if got, want := a.Sprites[i].SourceSize, image.Pt(16, 16); got != want {
+			t.Errorf("Sprites[%d].SourceSize: got: %v, want: %v", i, got, want)
+		}
+	}
+	tag := a.Tag("run")
+	if tag == nil {
+		t.Fatalf("Tag(%q) must not be nil", "run")
+	}
+	if got, want := *tag, (Tag{Name: "run", From: 0, To: 2, Direction: DirectionPingPong}); got != want {
+		t.Errorf("Tag(%q): got: %+v, want: %+v", "run", got, want)
+	}
+}
+
+func TestParseJSONDuplicatedName(t *testing.T) {
+	const data = `{"frames": [{"filename": "a", "frame": {"w": 1, "h": 1}}, {"filename": "a", "frame": {"w": 1, "h": 1}}]}`
+	if _, err := ParseJSON(strings.NewReader(data)); err == nil {
+		t.Errorf("ParseJSON with duplicated names must return an error")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/audio/internal/fft"
+	"github.com/hajimehoshi/ebiten/internal/sync"
+)
+
+// Analyzer analyzes the mixed output of an audio context, e.g. for music visualizers and beat-synced gameplay.
+//
+// Analyzer keeps the latest samples of the output and reports the level and the spectrum of them.
+type Analyzer struct {
+	players    *players
+	sampleRate int
+
+	// samples is a ring buffer of the latest monaural samples in [-1, 1].
+	samples []float64
+	head    int
+
+	m sync.Mutex
+}
+
+// NewAnalyzer creates a new analyzer for the output of the context.
+//
+// size is the number of the latest samples to analyze, and must be a power of 2 like 1024 or 2048.
+// A larger size results in a finer frequency resolution and a slower response.
+//
+// NewAnalyzer panics when size is not a power of 2.
+func NewAnalyzer(context *Context, size int) *Analyzer {
+	if !fft.IsPowerOf2(size) {
+		panic("audio: size must be a power of 2")
+	}
+	a := &Analyzer{
+		players:    context.players,
+		sampleRate: context.sampleRate,
+		samples:    make([]float64, size),
+	}
+	context.players.addAnalyzer(a)
+	return a
+}
+
+// write appends the mixed output in the stream format.
+func (a *Analyzer) write(b []byte) {
+	a.m.Lock()
+	defer a.m.Unlock()
+	for i := 0; i+3 < len(b); i += channelNum * bytesPerSample {
+		l := int16(b[i]) | int16(b[i+1])<<8
+		r := int16(b[i+2]) | int16(b[i+3])<<8
+		a.samples[a.head] = (float64(l) + float64(r)) / 2 / (1<<15 - 1)
+		a.head = (a.head + 1) % len(a.samples)
+	}
+}
+
+// latestSamples returns the samples in chronological order.
+//
+// latestSamples must be called with the lock.
+func (a *Analyzer) latestSamples() []float64 {
+	s := make([]float64, len(a.samples))
+	n := copy(s, a.samples[a.head:])
+	copy(s[n:], a.samples[:a.head])
+	return s
+}
+
+// Level returns the RMS (root mean square) level of the latest samples in [0, 1].
+func (a *Analyzer) Level() float64 {
+	a.m.Lock()
+	defer a.m.Unlock()
+	sum := 0.0
+	for _, s := range a.samples {
+		sum += s * s
+	}
+	return math.Sqrt(sum / float64(len(a.samples)))
+}
+
+// Spectrum returns the amplitudes of the frequency components of the latest samples.
+//
+// The result has size/2 elements: the i-th element is the amplitude at the frequency BinFrequency(i).
+// A sine wave of amplitude 1 results in about 1 at the bin of its frequency.
+func (a *Analyzer) Spectrum() []float64 {
+	a.m.Lock()
+	s := a.latestSamples()
+	a.m.Unlock()
+	return fft.Magnitudes(s)
+}
+
+// BinFrequency returns the frequency in Hz of the i-th element of Spectrum.
+func (a *Analyzer) BinFrequency(i int) float64 {
+	return float64(i) * float64(a.sampleRate) / float64(len(a.samples))
+}
+
+// Close stops analyzing.
+//
+// Close always returns nil.
+func (a *Analyzer) Close() error {
+	a.players.removeAnalyzer(a)
+	return nil
+}
@@ -51,7 +51,13 @@ import (
 )
 
 type players struct {
-	players map[*Player]struct{}
+	players   map[*Player]struct{}
+	analyzers map[*Analyzer]struct{}
+
+	// suspended indicates whether the app is suspended.
+	// While the app is suspended, the players are not proceeded and silence is output.
+	suspended bool
+
 	sync.RWMutex
 }
 
@@ -67,10 +73,13 @@ func (p *players) Read(b []byte) (int, error) {
 	p.Lock()
 	defer p.Unlock()
 
-	if len(p.players) == 0 {
+	if len(p.players) == 0 || p.suspended {
 		l := len(b)
 		l &= mask
 		copy(b, make([]byte, l))
+		for a := range p.analyzers {
+			a.write(b[:l])
+		}
 		return l, nil
 	}
 
@@ -100,6 +109,10 @@ func (p *players) Read(b []byte) (int, error) {
 		b[2*i+1] = byte(x >> 8)
 	}
 
+	for a := range p.analyzers {
+		a.write(b[:l])
+	}
+
 	closed := []*Player{}
 	for player := range p.players {
 		if player.eof() {
@@ -113,6 +126,22 @@ func (p *players) Read(b []byte) (int, error) {
 	return l, nil
 }
 
+// Suspend implements hooks.LifecycleHandler.
+func (p *players) Suspend() error {
+	p.Lock()
+	p.suspended = true
+	p.Unlock()
+	return nil
+}
+
+// Restore implements hooks.LifecycleHandler.
+func (p *players) Restore() error {
+	p.Lock()
+	p.suspended = false
+	p.Unlock()
+	return nil
+}
+
 func (p *players) addPlayer(player *Player) {
 	p.Lock()
 	p.players[player] = struct{}{}
@@ -125,6 +154,18 @@ func (p *players) removePlayer(player *Player) {
 	p.Unlock()
 }
 
+func (p *players) addAnalyzer(analyzer *Analyzer) {
+	p.Lock()
+	p.analyzers[analyzer] = struct{}{}
+	p.Unlock()
+}
+
+func (p *players) removeAnalyzer(analyzer *Analyzer) {
+	p.Lock()
+	delete(p.analyzers, analyzer)
+	p.Unlock()
+}
+
 func (p *players) hasPlayer(player *Player) bool {
 	p.RLock()
 	_, ok := p.players[player]
@@ -202,8 +243,11 @@ func NewContext(sampleRate int) (*Context, error) {
 	}
 	theContext = c
 	c.players = &players{
-		players: map[*Player]struct{}{},
+		players:   map[*Player]struct{}{},
+		analyzers: map[*Analyzer]struct{}{},
 	}
+	// The players are paused while the app is suspended, e.g. when an Android app goes background.
+	hooks.AddLifecycleHandler(c.players)
 
 	go c.loop()
 
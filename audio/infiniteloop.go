@@ -21,50 +21,134 @@ import (
 
 // InfiniteLoop represents a loop which never ends.
 type InfiniteLoop struct {
-	stream ReadSeekCloser
-	size   int64
+	stream  ReadSeekCloser
+	lstart  int64
+	llength int64
+
+	// pos is the current position of the stream. -1 means that the position is not known yet.
+	pos int64
 }
 
 // NewInfiniteLoop creates a new infinite loop stream with a stream and size in bytes.
 func NewInfiniteLoop(stream ReadSeekCloser, size int64) *InfiniteLoop {
+	return NewInfiniteLoopWithIntro(stream, 0, size)
+}
+
+// NewInfiniteLoopWithIntro creates a new infinite loop stream with an intro part and a loop part.
+//
+// The stream is played from the start, and after the intro part of introLength bytes,
+// the loop part of loopLength bytes is repeated forever.
+// The boundary of the loop is seamless: Read continues reading the start of the loop in the same call.
+//
+// introLength and loopLength are truncated to the multiples of the bytes of a sample
+// (4 bytes for 16bit stereo).
+//
+// NewInfiniteLoopWithIntro panics when introLength is negative or loopLength is not positive.
+func NewInfiniteLoopWithIntro(stream ReadSeekCloser, introLength int64, loopLength int64) *InfiniteLoop {
+	introLength &= mask
+	loopLength &= mask
+	if introLength < 0 {
+		panic("audio: introLength must not be negative")
+	}
+	if loopLength <= 0 {
+		panic("audio: loopLength must be positive")
+	}
 	return &InfiniteLoop{
-		stream: stream,
-		size:   size,
+		stream:  stream,
+		lstart:  introLength,
+		llength: loopLength,
+		pos:     -1,
 	}
 }
 
+func (i *InfiniteLoop) length() int64 {
+	return i.lstart + i.llength
+}
+
+func (i *InfiniteLoop) ensurePos() error {
+	if i.pos >= 0 {
+		return nil
+	}
+	pos, err := i.stream.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	i.pos = pos
+	return nil
+}
+
 // Read is implementation of ReadSeekCloser's Read.
 func (i *InfiniteLoop) Read(b []byte) (int, error) {
-	n, err := i.stream.Read(b)
-	if err == io.EOF {
-		if _, err := i.Seek(0, io.SeekStart); err != nil {
-			return 0, err
+	if err := i.ensurePos(); err != nil {
+		return 0, err
+	}
+
+	read := 0
+	// readInLoop is the bytes read after the last seek to the start of the loop.
+	// readInLoop is -1 before the first seek in this call, where the bytes before the seek are unknown.
+	readInLoop := -1
+	for read < len(b) {
+		buf := b[read:]
+		if rest := i.length() - i.pos; rest < int64(len(buf)) {
+			buf = buf[:rest]
+		}
+		n, err := i.stream.Read(buf)
+		read += n
+		if readInLoop >= 0 {
+			readInLoop += n
+		}
+		i.pos += int64(n)
+		if err != nil && err != io.EOF {
+			return read, err
+		}
+
+		// The end of the stream before the end of the loop is also treated as the end of the loop.
+		if i.pos < i.length() && err != io.EOF {
+			if n == 0 {
+				break
+			}
+			continue
+		}
+		if _, err := i.Seek(i.lstart, io.SeekStart); err != nil {
+			return read, err
+		}
+		if readInLoop == 0 {
+			// Avoid an infinite loop when the loop part is empty.
+			break
 		}
-		err = nil
+		readInLoop = 0
 	}
-	return n, err
+	return read, nil
 }
 
 // Seek is implementation of ReadSeekCloser's Seek.
+//
+// A position after the end of the loop is wrapped into the loop.
 func (i *InfiniteLoop) Seek(offset int64, whence int) (int64, error) {
+	if err := i.ensurePos(); err != nil {
+		return 0, err
+	}
+
 	next := int64(0)
 	switch whence {
 	case io.SeekStart:
 		next = offset
 	case io.SeekCurrent:
-		current, err := i.stream.Seek(0, io.SeekCurrent)
-		if err != nil {
-			return 0, err
-		}
-		next = current + offset
+		next = i.pos + offset
 	case io.SeekEnd:
 		return 0, fmt.Errorf("audio: whence must be 0 or 1 for InfiniteLoop")
 	}
-	next %= i.size
+	if next < 0 {
+		return 0, fmt.Errorf("audio: position must not be negative")
+	}
+	if next >= i.length() {
+		next = i.lstart + (next-i.lstart)%i.llength
+	}
 	pos, err := i.stream.Seek(next, io.SeekStart)
 	if err != nil {
 		return 0, err
 	}
+	i.pos = pos
 	return pos, nil
 }
 
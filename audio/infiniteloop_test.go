@@ -0,0 +1,147 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/audio"
+)
+
+// newTestData returns n bytes whose values are their positions.
+func newTestData(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+// expectedLoop returns the first n bytes of the loop with the intro.
+func expectedLoop(src []byte, introLength, loopLength int, n int) []byte {
+	end := introLength + loopLength
+	if end > len(src) {
+		end = len(src)
+	}
+	b := make([]byte, 0, n)
+	b = append(b, src[:introLength]...)
+	for len(b) < n {
+		b = append(b, src[introLength:end]...)
+	}
+	return b[:n]
+}
+
+func TestInfiniteLoopWithIntroRead(t *testing.T) {
+	cases := []struct {
+		Name        string
+		DataLength  int
+		IntroLength int
+		LoopLength  int
+		ChunkSize   int
+	}{
+		{"no intro", 32, 0, 16, 12},
+		{"small chunks", 32, 8, 12, 4},
+		{"chunk across the wrap", 32, 8, 12, 16},
+		{"chunk across several wraps", 32, 8, 12, 40},
+		{"stream shorter than the loop", 16, 8, 12, 12},
+		{"unaligned lengths", 32, 9, 14, 8},
+	}
+	for _, c := range cases {
+		src := newTestData(c.DataLength)
+		l := NewInfiniteLoopWithIntro(BytesReadSeekCloser(src), int64(c.IntroLength), int64(c.LoopLength))
+
+		// The lengths are truncated to the multiples of 4 bytes.
+		intro := c.IntroLength &^ 3
+		loop := c.LoopLength &^ 3
+
+		const total = 100
+		got := make([]byte, 0, total)
+		buf := make([]byte, c.ChunkSize)
+		for len(got) < total {
+			n, err := l.Read(buf)
+			if err != nil {
+				t.Fatalf("%s: Read: %v", c.Name, err)
+			}
+			if n != len(buf) {
+				t.Errorf("%s: Read must fill the buffer across the wrap: got: %d, want: %d", c.Name, n, len(buf))
+			}
+			got = append(got, buf[:n]...)
+		}
+		got = got[:total]
+		if want := expectedLoop(src, intro, loop, total); !bytes.Equal(got, want) {
+			t.Errorf("%s: got: %v, want: %v", c.Name, got, want)
+		}
+	}
+}
+
+func TestInfiniteLoopWithIntroSeek(t *testing.T) {
+	const (
+		introLength = 8
+		loopLength  = 12
+	)
+	src := newTestData(32)
+
+	cases := []struct {
+		Name    string
+		Offset  int64
+		Whence  int
+		WantPos int64
+	}{
+		{"start", 0, io.SeekStart, 0},
+		{"before the intro end", 4, io.SeekStart, 4},
+		{"at the intro end", introLength, io.SeekStart, introLength},
+		{"after the intro end", 12, io.SeekStart, 12},
+		{"at the loop end", introLength + loopLength, io.SeekStart, introLength},
+		{"after the loop end", introLength + loopLength + 4, io.SeekStart, introLength + 4},
+		{"after several loops", introLength + 3*loopLength + 8, io.SeekStart, introLength + 8},
+		{"current", 4, io.SeekCurrent, 8},
+		{"current after the loop end", 20, io.SeekCurrent, 12},
+	}
+	for _, c := range cases {
+		l := NewInfiniteLoopWithIntro(BytesReadSeekCloser(src), introLength, loopLength)
+		// Move the current position to 4 for the cases with io.SeekCurrent.
+		if _, err := l.Seek(4, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+
+		pos, err := l.Seek(c.Offset, c.Whence)
+		if err != nil {
+			t.Fatalf("%s: Seek: %v", c.Name, err)
+		}
+		if pos != c.WantPos {
+			t.Errorf("%s: position: got: %d, want: %d", c.Name, pos, c.WantPos)
+		}
+
+		// The bytes after the seek continue the loop from the position.
+		got := make([]byte, 16)
+		if _, err := io.ReadFull(l, got); err != nil {
+			t.Fatalf("%s: Read: %v", c.Name, err)
+		}
+		want := expectedLoop(src, introLength, loopLength, int(pos)+len(got))[pos:]
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: bytes after the seek: got: %v, want: %v", c.Name, got, want)
+		}
+	}
+
+	l := NewInfiniteLoopWithIntro(BytesReadSeekCloser(src), introLength, loopLength)
+	if _, err := l.Seek(-1, io.SeekStart); err == nil {
+		t.Errorf("Seek to a negative position must return an error")
+	}
+	if _, err := l.Seek(0, io.SeekEnd); err == nil {
+		t.Errorf("Seek with io.SeekEnd must return an error")
+	}
+}
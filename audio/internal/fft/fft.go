@@ -0,0 +1,86 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fft provides the fast Fourier transform for the audio analysis.
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// IsPowerOf2 reports whether n is a power of 2.
+func IsPowerOf2(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// Transform computes the discrete Fourier transform of x in place.
+//
+// Transform panics when len(x) is not a power of 2.
+func Transform(x []complex128) {
+	n := len(x)
+	if !IsPowerOf2(n) {
+		panic("fft: the length must be a power of 2")
+	}
+
+	// Bit-reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		w := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			wk := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a := x[start+k]
+				b := x[start+k+size/2] * wk
+				x[start+k] = a + b
+				x[start+k+size/2] = a - b
+				wk *= w
+			}
+		}
+	}
+}
+
+// Magnitudes returns the amplitudes of the frequency components of the real samples.
+//
+// The samples are multiplied by the Hann window to reduce the spectral leakage.
+// The result has len(samples)/2 elements: the i-th element is the amplitude at the frequency
+// i × sampleRate / len(samples). A sine wave of amplitude 1 at a bin's frequency results in about 1.
+//
+// Magnitudes panics when len(samples) is not a power of 2.
+func Magnitudes(samples []float64) []float64 {
+	n := len(samples)
+	x := make([]complex128, n)
+	for i, s := range samples {
+		w := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n))
+		x[i] = complex(s*w, 0)
+	}
+	Transform(x)
+
+	// The gain of the Hann window is 0.5, and a real sine wave is split into the positive and negative frequencies.
+	m := make([]float64, n/2)
+	for i := range m {
+		m[i] = cmplx.Abs(x[i]) * 4 / float64(n)
+	}
+	return m
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fft_test
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/audio/internal/fft"
+)
+
+func TestTransform(t *testing.T) {
+	const n = 16
+	x := make([]complex128, n)
+	for i := range x {
+		x[i] = complex(float64(i%5), float64(i%3))
+	}
+
+	// Compare with the naive DFT.
+	want := make([]complex128, n)
+	for k := range want {
+		for i, v := range x {
+			want[k] += v * cmplx.Exp(complex(0, -2*math.Pi*float64(k*i)/n))
+		}
+	}
+	Transform(x)
+	for k := range x {
+		if cmplx.Abs(x[k]-want[k]) > 1e-9 {
+			t.Errorf("x[%d]: got: %v, want: %v", k, x[k], want[k])
+		}
+	}
+}
+
+func TestMagnitudes(t *testing.T) {
+	const n = 256
+	const bin = 16
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = 0.5 * math.Sin(2*math.Pi*bin*float64(i)/n)
+	}
+	m := Magnitudes(samples)
+	if got, want := len(m), n/2; got != want {
+		t.Fatalf("len(m): got: %d, want: %d", got, want)
+	}
+	if got := m[bin]; math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("m[%d]: got: %f, want: %f", bin, got, 0.5)
+	}
+	for i, v := range m {
+		if i < bin-1 || bin+1 < i {
+			if v > 1e-9 {
+				t.Errorf("m[%d]: got: %f, want: 0", i, v)
+			}
+		}
+	}
+}
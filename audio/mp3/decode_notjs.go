@@ -12,12 +12,12 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// +build !js
+// +build !js wasm
 
 // Package mp3 provides MP3 decoder.
 //
-// On desktops and mobiles, a pure Go decoder is used.
-// On browsers, a native decoder on the browser is used.
+// On desktops, mobiles and WebAssembly, a pure Go decoder is used.
+// On browsers with GopherJS, a native decoder on the browser is used.
 package mp3
 
 import (
@@ -0,0 +1,172 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"io"
+
+	"github.com/hajimehoshi/ebiten/internal/sync"
+)
+
+// Recorder is an audio input stream from the default recording device like a microphone.
+//
+// The stream format is the same as a Player's: 16-bit little endian and 2 channels with the context's sample rate.
+// A monaural input is duplicated to both channels.
+type Recorder struct {
+	buf      []byte
+	maxBytes int
+	closed   bool
+	stop     func()
+	notifyCh chan struct{}
+
+	m sync.Mutex
+}
+
+// NewRecorder starts recording from the default recording device.
+//
+// The recorded data is buffered up to one second. When the game doesn't read the data in time,
+// the oldest data is discarded.
+//
+// NewRecorder might wait for the user's permission to use the device.
+// NewRecorder returns error when the device is not available or the permission is denied.
+//
+// Recording is supported only on browsers (GopherJS and WebAssembly) with getUserMedia as of 1.8.0-alpha.
+// On desktops and mobiles, NewRecorder always returns an error that recording is not supported on the platform.
+func NewRecorder(context *Context) (*Recorder, error) {
+	r := &Recorder{
+		maxBytes: context.sampleRate * bytesPerSample * channelNum,
+		notifyCh: make(chan struct{}, 1),
+	}
+	stop, err := startRecording(r, context.sampleRate)
+	if err != nil {
+		return nil, err
+	}
+	r.stop = stop
+	return r, nil
+}
+
+// write appends the recorded data.
+func (r *Recorder) write(b []byte) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.closed {
+		return
+	}
+	r.buf = append(r.buf, b...)
+	if n := len(r.buf) - r.maxBytes; n > 0 {
+		n = (n + channelNum*bytesPerSample - 1) & mask
+		r.buf = r.buf[:copy(r.buf, r.buf[n:])]
+	}
+	select {
+	case r.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// Read reads the recorded data. Read blocks until some data is recorded.
+//
+// Read returns io.EOF after the recorder is closed.
+func (r *Recorder) Read(b []byte) (int, error) {
+	for {
+		r.m.Lock()
+		if r.closed {
+			r.m.Unlock()
+			return 0, io.EOF
+		}
+		if len(r.buf) > 0 {
+			n := copy(b, r.buf)
+			n &= mask
+			r.buf = r.buf[:copy(r.buf, r.buf[n:])]
+			r.m.Unlock()
+			return n, nil
+		}
+		r.m.Unlock()
+		<-r.notifyCh
+	}
+}
+
+// Buffered returns the size of the recorded data in bytes that can be read without blocking.
+func (r *Recorder) Buffered() int {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return len(r.buf)
+}
+
+// Close stops recording.
+//
+// Close always returns nil.
+func (r *Recorder) Close() error {
+	r.m.Lock()
+	if r.closed {
+		r.m.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.buf = nil
+	r.m.Unlock()
+
+	select {
+	case r.notifyCh <- struct{}{}:
+	default:
+	}
+	r.stop()
+	return nil
+}
+
+// captureResampler converts monaural float samples in [-1, 1] into the stream format with the linear interpolation.
+type captureResampler struct {
+	from int
+	to   int
+
+	// pos is the position of the next sample to output, relative to the head of the next input.
+	// -1 < pos < 0 means that the sample is between last and the head of the next input.
+	pos  float64
+	last float32
+}
+
+func (c *captureResampler) resample(src []float32) []byte {
+	if len(src) == 0 {
+		return nil
+	}
+	at := func(i int) float32 {
+		if i < 0 {
+			return c.last
+		}
+		return src[i]
+	}
+
+	step := float64(c.from) / float64(c.to)
+	var dst []byte
+	for ; c.pos <= float64(len(src)-1); c.pos += step {
+		i := int(c.pos+1) - 1 // floor for pos > -1
+		frac := float32(c.pos - float64(i))
+		v := at(i)
+		if frac > 0 {
+			v = v*(1-frac) + at(i+1)*frac
+		}
+		const max = 1<<15 - 1
+		s := int32(v * max)
+		if s > max {
+			s = max
+		}
+		if s < -max {
+			s = -max
+		}
+		dst = append(dst, byte(s), byte(s>>8), byte(s), byte(s>>8))
+	}
+	c.pos -= float64(len(src))
+	c.last = src[len(src)-1]
+	return dst
+}
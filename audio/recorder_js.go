@@ -0,0 +1,107 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build js
+
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/internal/js"
+)
+
+// float32sFromJS copies the float values of the Float32Array v.
+func float32sFromJS(v js.Value) []float32 {
+	// Copy the bytes via Uint8Array since there is no function to copy a Float32Array in the js package.
+	u8 := js.Global().Get("Uint8Array").New(v.Get("buffer"), v.Get("byteOffset"), v.Get("byteLength"))
+	b := make([]byte, u8.Get("byteLength").Int())
+	js.CopyBytesToGo(b, u8)
+
+	// Typed arrays are in the platform's endianness, which is little endian on the supported browsers.
+	fs := make([]float32, len(b)/4)
+	for i := range fs {
+		fs[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return fs
+}
+
+func startRecording(r *Recorder, sampleRate int) (func(), error) {
+	devices := js.Global().Get("navigator").Get("mediaDevices")
+	if !devices.Truthy() || !devices.Get("getUserMedia").Truthy() {
+		return nil, errors.New("audio: getUserMedia is not available")
+	}
+	klass := js.Global().Get("AudioContext")
+	if !klass.Truthy() {
+		klass = js.Global().Get("webkitAudioContext")
+	}
+	if !klass.Truthy() {
+		return nil, errors.New("audio: AudioContext is not available")
+	}
+
+	var stream, ctx, processor js.Value
+	var processf js.Func
+	ch := make(chan error)
+
+	thenf := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		stream = args[0]
+		ctx = klass.New()
+		res := &captureResampler{
+			from: ctx.Get("sampleRate").Int(),
+			to:   sampleRate,
+		}
+		source := ctx.Call("createMediaStreamSource", stream)
+		// ScriptProcessorNode is deprecated but AudioWorklet is not available on many browsers yet.
+		processor = ctx.Call("createScriptProcessor", 4096, 1, 1)
+		processf = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			data := args[0].Get("inputBuffer").Call("getChannelData", 0)
+			r.write(res.resample(float32sFromJS(data)))
+			return nil
+		})
+		processor.Set("onaudioprocess", processf)
+		source.Call("connect", processor)
+		// The processor must be connected to the destination to work on Chrome. Nothing is output.
+		processor.Call("connect", ctx.Get("destination"))
+		close(ch)
+		return nil
+	})
+	defer thenf.Release()
+
+	catchf := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		ch <- fmt.Errorf("audio: getUserMedia failed: %s", args[0].Call("toString").String())
+		return nil
+	})
+	defer catchf.Release()
+
+	devices.Call("getUserMedia", map[string]interface{}{
+		"audio": true,
+	}).Call("then", thenf).Call("catch", catchf)
+	if err := <-ch; err != nil {
+		return nil, err
+	}
+
+	return func() {
+		processor.Call("disconnect")
+		processor.Set("onaudioprocess", nil)
+		processf.Release()
+		tracks := stream.Call("getTracks")
+		for i := 0; i < tracks.Length(); i++ {
+			tracks.Index(i).Call("stop")
+		}
+		ctx.Call("close")
+	}, nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/hajimehoshi/ebiten/internal/sync"
+)
+
+// Sound is a preloaded short sound like a sound effect.
+//
+// A Sound holds the whole decoded stream in memory, so that the sound can be played many times at once
+// without decoding. Use a Player with a stream for long sounds like music.
+type Sound struct {
+	context *Context
+	data    []byte
+}
+
+// NewSound reads the whole src and creates a new Sound.
+//
+// The format of src should be same as noted at NewPlayer. Decoded streams in e.g. audio/wav package can be passed.
+//
+// NewSound returns error when reading src returns error.
+func NewSound(context *Context, src io.Reader) (*Sound, error) {
+	b, err := ioutil.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	return NewSoundFromBytes(context, b), nil
+}
+
+// NewSoundFromBytes creates a new Sound with the given bytes.
+//
+// The format of src should be same as noted at NewPlayer. src must not be modified after calling this.
+func NewSoundFromBytes(context *Context, src []byte) *Sound {
+	return &Sound{
+		context: context,
+		data:    src,
+	}
+}
+
+type voice struct {
+	player *Player
+	volume float64
+}
+
+// Bus is a category of sounds like sound effects, music or UI sounds.
+//
+// A bus plays sounds with a common volume, and limits the number of sounds playing at the same time.
+// When the number of playing voices reaches the limit, the oldest playing voice is stopped to play a new sound.
+// Paused voices don't count toward the limit and are never stopped by a new sound.
+type Bus struct {
+	voices    []*voice
+	volume    float64
+	maxVoices int
+	m         sync.Mutex
+}
+
+// NewBus creates a new bus with the maximum number of voices.
+//
+// If maxVoices is 0, the number of voices is not limited.
+//
+// NewBus panics when maxVoices is negative.
+func NewBus(maxVoices int) *Bus {
+	if maxVoices < 0 {
+		panic("audio: maxVoices must not be negative")
+	}
+	return &Bus{
+		volume:    1,
+		maxVoices: maxVoices,
+	}
+}
+
+// removeFinishedVoices closes and removes the voices that finished playing.
+// The paused voices are kept so that they can be resumed.
+//
+// removeFinishedVoices must be called with the lock.
+func (b *Bus) removeFinishedVoices() error {
+	n := 0
+	var err error
+	for _, v := range b.voices {
+		if v.player.IsPlaying() || !v.player.eof() {
+			b.voices[n] = v
+			n++
+			continue
+		}
+		if e := v.player.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	for i := n; i < len(b.voices); i++ {
+		b.voices[i] = nil
+	}
+	b.voices = b.voices[:n]
+	return err
+}
+
+// Play plays the sound with the volume on the bus.
+//
+// The actual volume is the product of volume and the bus's volume.
+// volume must be in between 0 and 1. Play panics otherwise.
+//
+// The returned player is owned by the bus: the player is closed when it finishes playing,
+// when it is stolen by another sound, or when the bus is stopped. Don't call Close of the player.
+// The player can be paused and resumed. A paused player is not stolen, and is kept until the bus is stopped.
+//
+// Play returns error when closing a finished player returns error.
+func (b *Bus) Play(sound *Sound, volume float64) (*Player, error) {
+	// The condition must be true when volume is NaN.
+	if !(0 <= volume && volume <= 1) {
+		panic("audio: volume must be in between 0 and 1")
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if err := b.removeFinishedVoices(); err != nil {
+		return nil, err
+	}
+	if err := b.stealVoices(); err != nil {
+		return nil, err
+	}
+
+	p, err := NewPlayerFromBytes(sound.context, sound.data)
+	if err != nil {
+		return nil, err
+	}
+	p.SetVolume(volume * b.volume)
+	if err := p.Play(); err != nil {
+		return nil, err
+	}
+	b.voices = append(b.voices, &voice{
+		player: p,
+		volume: volume,
+	})
+	return p, nil
+}
+
+// stealVoices closes and removes the oldest playing voices so that a new voice can be played within the limit.
+//
+// stealVoices must be called with the lock.
+func (b *Bus) stealVoices() error {
+	if b.maxVoices == 0 {
+		return nil
+	}
+	playing := 0
+	for _, v := range b.voices {
+		if v.player.IsPlaying() {
+			playing++
+		}
+	}
+	n := 0
+	for _, v := range b.voices {
+		if playing >= b.maxVoices && v.player.IsPlaying() {
+			if err := v.player.Close(); err != nil {
+				return err
+			}
+			playing--
+			continue
+		}
+		b.voices[n] = v
+		n++
+	}
+	for i := n; i < len(b.voices); i++ {
+		b.voices[i] = nil
+	}
+	b.voices = b.voices[:n]
+	return nil
+}
+
+// Volume returns the volume of the bus [0-1].
+func (b *Bus) Volume() float64 {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.volume
+}
+
+// SetVolume sets the volume of the bus. The volumes of the playing sounds are updated immediately.
+// volume must be in between 0 and 1. This function panics otherwise.
+func (b *Bus) SetVolume(volume float64) {
+	// The condition must be true when volume is NaN.
+	if !(0 <= volume && volume <= 1) {
+		panic("audio: volume must be in between 0 and 1")
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.volume = volume
+	for _, v := range b.voices {
+		v.player.SetVolume(v.volume * volume)
+	}
+}
+
+// VoiceCount returns the number of the sounds playing on the bus.
+func (b *Bus) VoiceCount() int {
+	b.m.Lock()
+	defer b.m.Unlock()
+	n := 0
+	for _, v := range b.voices {
+		if v.player.IsPlaying() {
+			n++
+		}
+	}
+	return n
+}
+
+// Stop stops and closes all the sounds on the bus, including the paused ones.
+//
+// Stop returns error when closing a player returns error.
+func (b *Bus) Stop() error {
+	b.m.Lock()
+	defer b.m.Unlock()
+	var err error
+	for _, v := range b.voices {
+		if e := v.player.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	b.voices = nil
+	return err
+}
@@ -0,0 +1,157 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/audio"
+)
+
+// theContext is the audio context shared by the tests.
+// The players are never proceeded in the tests since the game loop doesn't run,
+// so a played sound keeps playing until it is paused or closed.
+var theContext *Context
+
+func init() {
+	c, err := NewContext(44100)
+	if err != nil {
+		panic(err)
+	}
+	theContext = c
+}
+
+func newTestSound(t *testing.T) *Sound {
+	s, err := NewSound(theContext, bytes.NewReader(make([]byte, 4096)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// isClosed reports whether the player is closed.
+func isClosed(p *Player) bool {
+	// Rewind fails only when the player is already closed.
+	return p.Rewind() != nil
+}
+
+func TestBusVoiceLimit(t *testing.T) {
+	s := newTestSound(t)
+	b := NewBus(2)
+	defer b.Stop()
+
+	var ps []*Player
+	for i := 0; i < 4; i++ {
+		p, err := b.Play(s, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ps = append(ps, p)
+		want := i + 1
+		if want > 2 {
+			want = 2
+		}
+		if got := b.VoiceCount(); got != want {
+			t.Errorf("VoiceCount() after playing %d sounds: got: %d, want: %d", i+1, got, want)
+		}
+	}
+
+	// The oldest voices are stolen first.
+	for i, p := range ps {
+		want := i < 2
+		if got := isClosed(p); got != want {
+			t.Errorf("player #%d is closed: got: %t, want: %t", i, got, want)
+		}
+	}
+}
+
+func TestBusNoVoiceLimit(t *testing.T) {
+	s := newTestSound(t)
+	b := NewBus(0)
+	defer b.Stop()
+
+	for i := 0; i < 8; i++ {
+		if _, err := b.Play(s, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := b.VoiceCount(), 8; got != want {
+		t.Errorf("VoiceCount(): got: %d, want: %d", got, want)
+	}
+}
+
+func TestBusPausedVoice(t *testing.T) {
+	s := newTestSound(t)
+	b := NewBus(2)
+	defer b.Stop()
+
+	paused, err := b.Play(s, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := paused.Pause(); err != nil {
+		t.Fatal(err)
+	}
+
+	var ps []*Player
+	for i := 0; i < 3; i++ {
+		p, err := b.Play(s, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ps = append(ps, p)
+	}
+
+	// The paused voice doesn't count toward the limit and is not stolen.
+	if isClosed(paused) {
+		t.Errorf("the paused player must not be closed")
+	}
+	if got, want := b.VoiceCount(), 2; got != want {
+		t.Errorf("VoiceCount(): got: %d, want: %d", got, want)
+	}
+	for i, p := range ps {
+		want := i < 1
+		if got := isClosed(p); got != want {
+			t.Errorf("player #%d is closed: got: %t, want: %t", i, got, want)
+		}
+	}
+
+	// The resumed voice is stolen as a playing voice.
+	if err := paused.Play(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Play(s, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !isClosed(paused) {
+		t.Errorf("the resumed player must be stolen as the oldest playing voice")
+	}
+	if got, want := b.VoiceCount(), 2; got != want {
+		t.Errorf("VoiceCount(): got: %d, want: %d", got, want)
+	}
+
+	// Stop closes all the voices including paused ones.
+	p := ps[2]
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if !isClosed(p) {
+		t.Errorf("the paused player must be closed by Stop")
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package benchutil provides a harness to benchmark rendering under the real main loop.
+//
+// Benchmarks are registered with Register and run with Run. Each benchmark draws the screen
+// once per frame, and the time per frame is measured including the presentation.
+// The results are written in the Go benchmark format, so that the results of different
+// commits can be compared with tools like benchstat.
+//
+// Note: This package is experimental and API might be changed.
+package benchutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"runtime"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	// ScreenWidth and ScreenHeight are the size of the screen passed to the benchmarks.
+	ScreenWidth  = 640
+	ScreenHeight = 480
+
+	// warmupFrames is the number of frames drawn before measuring each benchmark.
+	warmupFrames = 30
+)
+
+type benchmark struct {
+	name string
+	f    func(screen *ebiten.Image)
+}
+
+var benchmarks []benchmark
+
+// Register registers a benchmark with the name.
+//
+// f is called once per frame to draw the screen. Register panics if the name is already registered.
+//
+// Register is typically called in an init function.
+func Register(name string, f func(screen *ebiten.Image)) {
+	for _, b := range benchmarks {
+		if b.name == name {
+			panic(fmt.Sprintf("benchutil: benchmark %q is already registered", name))
+		}
+	}
+	benchmarks = append(benchmarks, benchmark{name: name, f: f})
+}
+
+// errDone is returned from Update to stop the main loop after all the benchmarks are run.
+var errDone = errors.New("benchutil: done")
+
+// runner is a Game to run the benchmarks in order.
+type runner struct {
+	benchmarks []benchmark
+	frames     int
+	out        io.Writer
+
+	// index is the index of the current benchmark.
+	index int
+
+	// frame is the number of frames drawn for the current benchmark, including the warmup frames.
+	frame int
+
+	start    time.Time
+	memStats runtime.MemStats
+
+	err error
+}
+
+func (r *runner) Update() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.index >= len(r.benchmarks) {
+		return errDone
+	}
+	return nil
+}
+
+func (r *runner) Draw(screen *ebiten.Image) {
+	if r.index >= len(r.benchmarks) || r.err != nil {
+		return
+	}
+
+	// The time is measured between the starts of the first measured frame and the frame after the last one,
+	// so that the presentation of the last frame is also counted.
+	switch r.frame {
+	case warmupFrames:
+		runtime.ReadMemStats(&r.memStats)
+		r.start = time.Now()
+	case warmupFrames + r.frames:
+		d := time.Since(r.start)
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		if _, err := io.WriteString(r.out, r.result(d, &m)); err != nil {
+			r.err = err
+			return
+		}
+		r.index++
+		r.frame = 0
+		if r.index >= len(r.benchmarks) {
+			return
+		}
+	}
+
+	r.benchmarks[r.index].f(screen)
+	r.frame++
+}
+
+func (r *runner) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return ScreenWidth, ScreenHeight
+}
+
+// result returns a line of the current benchmark's result in the Go benchmark format.
+func (r *runner) result(d time.Duration, m *runtime.MemStats) string {
+	name := "Benchmark" + r.benchmarks[r.index].name
+	if n := runtime.GOMAXPROCS(0); n != 1 {
+		name = fmt.Sprintf("%s-%d", name, n)
+	}
+	n := uint64(r.frames)
+	return fmt.Sprintf("%s\t%8d\t%10d ns/op\t%8d B/op\t%8d allocs/op\n",
+		name, r.frames, d.Nanoseconds()/int64(r.frames),
+		(m.TotalAlloc-r.memStats.TotalAlloc)/n, (m.Mallocs-r.memStats.Mallocs)/n)
+}
+
+// header returns the header lines of the Go benchmark format.
+func header() string {
+	return fmt.Sprintf("goos: %s\ngoarch: %s\n", runtime.GOOS, runtime.GOARCH)
+}
+
+// Run runs the registered benchmarks whose names match pattern in the main loop,
+// and writes the results to out.
+//
+// pattern is a regular expression. An empty pattern matches all the benchmarks.
+// Each benchmark is measured for the given number of frames after some warmup frames.
+// The vsync is disabled while the benchmarks are run so that the frames are not limited by
+// the display's refresh rate.
+//
+// As ebiten.RunGame, Run must be called from the OS main thread, and must not be called
+// with ebiten.Run or ebiten.RunGame in one process.
+func Run(out io.Writer, pattern string, frames int) error {
+	if frames <= 0 {
+		panic("benchutil: frames must be positive")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	r := &runner{
+		frames: frames,
+		out:    out,
+	}
+	for _, b := range benchmarks {
+		if re.MatchString(b.name) {
+			r.benchmarks = append(r.benchmarks, b)
+		}
+	}
+	if len(r.benchmarks) == 0 {
+		return nil
+	}
+
+	if _, err := io.WriteString(out, header()); err != nil {
+		return err
+	}
+
+	vsync := ebiten.IsVsyncEnabled()
+	ebiten.SetVsyncEnabled(false)
+	defer ebiten.SetVsyncEnabled(vsync)
+
+	ebiten.SetWindowTitle("Benchmark")
+	if err := ebiten.RunGame(r); err != nil && err != errDone {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package benchutil
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+func TestRunner(t *testing.T) {
+	counts := map[string]int{}
+	r := &runner{
+		frames: 5,
+		out:    &bytes.Buffer{},
+	}
+	for _, name := range []string{"Foo", "Bar"} {
+		name := name
+		r.benchmarks = append(r.benchmarks, benchmark{
+			name: name,
+			f: func(screen *ebiten.Image) {
+				counts[name]++
+			},
+		})
+	}
+
+	for i := 0; ; i++ {
+		if i > 1000 {
+			t.Fatal("the runner didn't finish")
+		}
+		if err := r.Update(); err == errDone {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		r.Draw(nil)
+	}
+
+	for _, name := range []string{"Foo", "Bar"} {
+		if got, want := counts[name], warmupFrames+5; got != want {
+			t.Errorf("the number of frames of %s: got: %d, want: %d", name, got, want)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(r.out.(*bytes.Buffer).String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("the number of result lines: got: %d, want: 2", len(lines))
+	}
+	for i, name := range []string{"Foo", "Bar"} {
+		re := regexp.MustCompile(`^Benchmark` + name + `(-\d+)?\t +5\t +\d+ ns/op\t +\d+ B/op\t +\d+ allocs/op$`)
+		if !re.MatchString(lines[i]) {
+			t.Errorf("result line %d: got: %q", i, lines[i])
+		}
+	}
+}
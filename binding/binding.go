@@ -0,0 +1,360 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package binding provides bindings of named actions like "jump" to inputs of keyboards, mice, gamepads and touches.
+//
+// A game queries the actions instead of the inputs, so that the players can remap the inputs.
+// The bindings can be saved and loaded as JSON.
+//
+// Note: This package is experimental and API might be changed.
+package binding
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// InputType represents a type of inputs.
+type InputType int
+
+const (
+	InputTypeKey InputType = iota
+	InputTypeMouseButton
+	InputTypeGamepadButton
+	InputTypeGamepadAxis
+	InputTypeTouch
+)
+
+// Input is an input bound to an action.
+//
+// Use the functions like KeyInput to create an Input.
+type Input struct {
+	Type InputType
+
+	// Key is the key for InputTypeKey.
+	Key ebiten.Key
+
+	// MouseButton is the mouse button for InputTypeMouseButton.
+	MouseButton ebiten.MouseButton
+
+	// GamepadButton is the gamepad button for InputTypeGamepadButton.
+	GamepadButton ebiten.GamepadButton
+
+	// GamepadAxis is the gamepad axis for InputTypeGamepadAxis.
+	GamepadAxis int
+
+	// AxisPositive indicates whether the positive direction of GamepadAxis is bound.
+	// Otherwise the negative direction is bound.
+	AxisPositive bool
+
+	// Touch is the region on the screen for InputTypeTouch.
+	Touch image.Rectangle
+}
+
+// KeyInput returns an Input of the key.
+func KeyInput(key ebiten.Key) Input {
+	return Input{Type: InputTypeKey, Key: key}
+}
+
+// MouseButtonInput returns an Input of the mouse button.
+func MouseButtonInput(button ebiten.MouseButton) Input {
+	return Input{Type: InputTypeMouseButton, MouseButton: button}
+}
+
+// GamepadButtonInput returns an Input of the gamepad button.
+func GamepadButtonInput(button ebiten.GamepadButton) Input {
+	return Input{Type: InputTypeGamepadButton, GamepadButton: button}
+}
+
+// GamepadAxisInput returns an Input of a direction of the gamepad axis.
+func GamepadAxisInput(axis int, positive bool) Input {
+	return Input{Type: InputTypeGamepadAxis, GamepadAxis: axis, AxisPositive: positive}
+}
+
+// TouchInput returns an Input of touches in the region of the screen.
+func TouchInput(region image.Rectangle) Input {
+	return Input{Type: InputTypeTouch, Touch: region}
+}
+
+var mouseButtonNames = map[ebiten.MouseButton]string{
+	ebiten.MouseButtonLeft:   "left",
+	ebiten.MouseButtonRight:  "right",
+	ebiten.MouseButtonMiddle: "middle",
+}
+
+// String returns a string representing the input, e.g. "key:Space", "mouse:left", "gamepadbutton:0",
+// "gamepadaxis:1+" or "touch:0,0,100,100".
+func (i Input) String() string {
+	switch i.Type {
+	case InputTypeKey:
+		return "key:" + i.Key.String()
+	case InputTypeMouseButton:
+		return "mouse:" + mouseButtonNames[i.MouseButton]
+	case InputTypeGamepadButton:
+		return fmt.Sprintf("gamepadbutton:%d", i.GamepadButton)
+	case InputTypeGamepadAxis:
+		sign := "-"
+		if i.AxisPositive {
+			sign = "+"
+		}
+		return fmt.Sprintf("gamepadaxis:%d%s", i.GamepadAxis, sign)
+	case InputTypeTouch:
+		r := i.Touch
+		return fmt.Sprintf("touch:%d,%d,%d,%d", r.Min.X, r.Min.Y, r.Max.X, r.Max.Y)
+	}
+	return ""
+}
+
+// ParseInput parses a string returned by Input's String.
+func ParseInput(str string) (Input, error) {
+	tokens := strings.SplitN(str, ":", 2)
+	if len(tokens) != 2 {
+		return Input{}, fmt.Errorf("binding: invalid input: %q", str)
+	}
+	kind, value := tokens[0], tokens[1]
+	switch kind {
+	case "key":
+		for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+			if strings.EqualFold(k.String(), value) {
+				return KeyInput(k), nil
+			}
+		}
+	case "mouse":
+		for b, name := range mouseButtonNames {
+			if name == value {
+				return MouseButtonInput(b), nil
+			}
+		}
+	case "gamepadbutton":
+		b, err := strconv.Atoi(value)
+		if err == nil && b >= 0 {
+			return GamepadButtonInput(ebiten.GamepadButton(b)), nil
+		}
+	case "gamepadaxis":
+		if strings.HasSuffix(value, "+") || strings.HasSuffix(value, "-") {
+			a, err := strconv.Atoi(value[:len(value)-1])
+			if err == nil && a >= 0 {
+				return GamepadAxisInput(a, strings.HasSuffix(value, "+")), nil
+			}
+		}
+	case "touch":
+		var r image.Rectangle
+		if _, err := fmt.Sscanf(value, "%d,%d,%d,%d", &r.Min.X, &r.Min.Y, &r.Max.X, &r.Max.Y); err == nil {
+			return TouchInput(r), nil
+		}
+	}
+	return Input{}, fmt.Errorf("binding: invalid input: %q", str)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (i Input) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (i *Input) UnmarshalText(text []byte) error {
+	input, err := ParseInput(string(text))
+	if err != nil {
+		return err
+	}
+	*i = input
+	return nil
+}
+
+// Bindings is a set of the bindings of actions to inputs, usually for one player.
+//
+// The zero value of Bindings is an empty set ready to use.
+type Bindings struct {
+	// GamepadID is the ID of the gamepad for the gamepad inputs.
+	GamepadID int
+
+	// AxisThreshold is the value of a gamepad axis in (0, 1] to treat the axis as pressed. 0 is treated as 0.5.
+	AxisThreshold float64
+
+	actions map[string][]Input
+}
+
+func (b *Bindings) axisThreshold() float64 {
+	if b.AxisThreshold == 0 {
+		return 0.5
+	}
+	return b.AxisThreshold
+}
+
+// Bind adds the inputs to the action.
+func (b *Bindings) Bind(action string, inputs ...Input) {
+	if b.actions == nil {
+		b.actions = map[string][]Input{}
+	}
+	for _, i := range inputs {
+		if !containsInput(b.actions[action], i) {
+			b.actions[action] = append(b.actions[action], i)
+		}
+	}
+}
+
+func containsInput(inputs []Input, input Input) bool {
+	for _, i := range inputs {
+		if i == input {
+			return true
+		}
+	}
+	return false
+}
+
+// Unbind removes all the inputs from the action.
+func (b *Bindings) Unbind(action string) {
+	delete(b.actions, action)
+}
+
+// Actions returns the names of the actions in the sorted order.
+func (b *Bindings) Actions() []string {
+	var as []string
+	for a := range b.actions {
+		as = append(as, a)
+	}
+	sort.Strings(as)
+	return as
+}
+
+// Inputs returns the inputs bound to the action.
+func (b *Bindings) Inputs(action string) []Input {
+	is := make([]Input, len(b.actions[action]))
+	copy(is, b.actions[action])
+	return is
+}
+
+// inputValue returns the current value of the input in [0, 1].
+func (b *Bindings) inputValue(i Input) float64 {
+	pressed := false
+	switch i.Type {
+	case InputTypeKey:
+		pressed = ebiten.IsKeyPressed(i.Key)
+	case InputTypeMouseButton:
+		pressed = ebiten.IsMouseButtonPressed(i.MouseButton)
+	case InputTypeGamepadButton:
+		pressed = ebiten.IsGamepadButtonPressed(b.GamepadID, i.GamepadButton)
+	case InputTypeGamepadAxis:
+		if i.GamepadAxis >= ebiten.GamepadAxisNum(b.GamepadID) {
+			return 0
+		}
+		v := ebiten.GamepadAxis(b.GamepadID, i.GamepadAxis)
+		if !i.AxisPositive {
+			v = -v
+		}
+		return math.Max(0, math.Min(1, v))
+	case InputTypeTouch:
+		for _, t := range ebiten.Touches() {
+			if image.Pt(t.Position()).In(i.Touch) {
+				pressed = true
+				break
+			}
+		}
+	}
+	if pressed {
+		return 1
+	}
+	return 0
+}
+
+// ActionValue returns the value of the action in [0, 1].
+//
+// The value is the maximum of the values of the bound inputs: 1 for a pressed key or button,
+// and the value of the bound direction for a gamepad axis.
+func (b *Bindings) ActionValue(action string) float64 {
+	v := 0.0
+	for _, i := range b.actions[action] {
+		v = math.Max(v, b.inputValue(i))
+	}
+	return v
+}
+
+// IsActionPressed returns a boolean indicating whether any input bound to the action is pressed.
+//
+// A gamepad axis is treated as pressed when its value in the bound direction exceeds AxisThreshold.
+func (b *Bindings) IsActionPressed(action string) bool {
+	for _, i := range b.actions[action] {
+		v := b.inputValue(i)
+		if i.Type == InputTypeGamepadAxis {
+			if v >= b.axisThreshold() {
+				return true
+			}
+			continue
+		}
+		if v > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// PressedInput returns an input that is currently pressed, except for touches.
+//
+// This is useful to let the players remap an action by pressing the new input.
+// ok is false when no input is pressed.
+func (b *Bindings) PressedInput() (input Input, ok bool) {
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if ebiten.IsKeyPressed(k) {
+			return KeyInput(k), true
+		}
+	}
+	for _, m := range []ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonRight, ebiten.MouseButtonMiddle} {
+		if ebiten.IsMouseButtonPressed(m) {
+			return MouseButtonInput(m), true
+		}
+	}
+	for g := ebiten.GamepadButton(0); g < ebiten.GamepadButton(ebiten.GamepadButtonNum(b.GamepadID)); g++ {
+		if ebiten.IsGamepadButtonPressed(b.GamepadID, g) {
+			return GamepadButtonInput(g), true
+		}
+	}
+	for a := 0; a < ebiten.GamepadAxisNum(b.GamepadID); a++ {
+		v := ebiten.GamepadAxis(b.GamepadID, a)
+		if math.Abs(v) >= b.axisThreshold() {
+			return GamepadAxisInput(a, v > 0), true
+		}
+	}
+	return Input{}, false
+}
+
+// MarshalJSON implements json.Marshaler.
+//
+// The bindings are encoded as an object from the action names to the arrays of the inputs' strings.
+// GamepadID and AxisThreshold are not encoded.
+func (b *Bindings) MarshalJSON() ([]byte, error) {
+	actions := b.actions
+	if actions == nil {
+		actions = map[string][]Input{}
+	}
+	return json.Marshal(actions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// The existing bindings are replaced.
+func (b *Bindings) UnmarshalJSON(data []byte) error {
+	var actions map[string][]Input
+	if err := json.Unmarshal(data, &actions); err != nil {
+		return err
+	}
+	b.actions = actions
+	return nil
+}
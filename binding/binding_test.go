@@ -0,0 +1,91 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binding_test
+
+import (
+	"encoding/json"
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	. "github.com/hajimehoshi/ebiten/binding"
+)
+
+func TestInputString(t *testing.T) {
+	for _, i := range []Input{
+		KeyInput(ebiten.KeySpace),
+		MouseButtonInput(ebiten.MouseButtonRight),
+		GamepadButtonInput(3),
+		GamepadAxisInput(1, false),
+		GamepadAxisInput(0, true),
+		TouchInput(image.Rect(10, 20, 30, 40)),
+	} {
+		got, err := ParseInput(i.String())
+		if err != nil {
+			t.Errorf("ParseInput(%q): %v", i.String(), err)
+			continue
+		}
+		if got != i {
+			t.Errorf("ParseInput(%q): got: %v, want: %v", i.String(), got, i)
+		}
+	}
+
+	if got, want := KeyInput(ebiten.KeySpace).String(), "key:Space"; got != want {
+		t.Errorf("String(): got: %q, want: %q", got, want)
+	}
+	for _, str := range []string{"", "key:", "key:Foo", "mouse:center", "gamepadaxis:1", "touch:1,2", "pen:0"} {
+		if _, err := ParseInput(str); err == nil {
+			t.Errorf("ParseInput(%q) must return an error", str)
+		}
+	}
+}
+
+func TestBindingsJSON(t *testing.T) {
+	b := &Bindings{}
+	b.Bind("jump", KeyInput(ebiten.KeySpace), GamepadButtonInput(0))
+	b.Bind("jump", KeyInput(ebiten.KeySpace))
+	b.Bind("left", KeyInput(ebiten.KeyLeft), GamepadAxisInput(0, false))
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"jump":["key:Space","gamepadbutton:0"],"left":["key:Left","gamepadaxis:0-"]}`; got != want {
+		t.Errorf("json.Marshal: got: %s, want: %s", got, want)
+	}
+
+	b2 := &Bindings{}
+	if err := json.Unmarshal(data, b2); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b2.Actions(), []string{"jump", "left"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Actions(): got: %v, want: %v", got, want)
+	}
+	for _, a := range b.Actions() {
+		if got, want := b2.Inputs(a), b.Inputs(a); !reflect.DeepEqual(got, want) {
+			t.Errorf("Inputs(%q): got: %v, want: %v", a, got, want)
+		}
+	}
+
+	b.Unbind("jump")
+	if got := b.Inputs("jump"); len(got) != 0 {
+		t.Errorf("Inputs after Unbind: got: %v, want: none", got)
+	}
+
+	if err := json.Unmarshal([]byte(`{"jump":["key:Nothing"]}`), b2); err == nil {
+		t.Errorf("json.Unmarshal with an invalid input must return an error")
+	}
+}
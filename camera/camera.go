@@ -0,0 +1,170 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package camera provides a camera to see a 2D world through a viewport.
+//
+// Note: This package is experimental and API might be changed.
+package camera
+
+import (
+	"image"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// Camera represents a viewport on a 2D world.
+//
+// The position of the camera is the point in the world that is shown at the center of the viewport.
+type Camera struct {
+	// X and Y are the position of the camera in the world coordinates.
+	X float64
+	Y float64
+
+	// Zoom is the scale of the world on the screen. 0 is treated as 1.
+	Zoom float64
+
+	// Rotation is the rotation of the camera in radian.
+	// The world is rotated in the opposite direction on the screen.
+	Rotation float64
+
+	// ViewportWidth and ViewportHeight are the size of the viewport on the screen.
+	ViewportWidth  int
+	ViewportHeight int
+
+	// Bounds is the region in the world coordinates that the viewport is kept in.
+	// If Bounds is empty, the camera position is not clamped.
+	Bounds image.Rectangle
+
+	// Rand is the source of random numbers for shaking.
+	// If Rand is nil, the functions of the math/rand package are used.
+	Rand *rand.Rand
+
+	shakeIntensity float64
+	shakeDuration  int
+	shakeTicks     int
+	shakeX         float64
+	shakeY         float64
+}
+
+// NewCamera returns a new camera with the given viewport size.
+func NewCamera(viewportWidth, viewportHeight int) *Camera {
+	return &Camera{
+		Zoom:           1,
+		ViewportWidth:  viewportWidth,
+		ViewportHeight: viewportHeight,
+	}
+}
+
+func (c *Camera) zoom() float64 {
+	if c.Zoom == 0 {
+		return 1
+	}
+	return c.Zoom
+}
+
+// Shake starts shaking the camera.
+//
+// intensity is the maximum offset of the shaking in the world coordinates.
+// duration is the duration in ticks. The intensity decreases linearly over the duration.
+func (c *Camera) Shake(intensity float64, duration int) {
+	c.shakeIntensity = intensity
+	c.shakeDuration = duration
+	c.shakeTicks = duration
+}
+
+// IsShaking returns a boolean value indicating whether the camera is shaking.
+func (c *Camera) IsShaking() bool {
+	return c.shakeTicks > 0
+}
+
+func (c *Camera) float64() float64 {
+	if c.Rand != nil {
+		return c.Rand.Float64()
+	}
+	return rand.Float64()
+}
+
+// Update advances the shaking by one tick and clamps the position by Bounds.
+//
+// Update is usually called once in a game's update function.
+func (c *Camera) Update() {
+	if c.shakeTicks > 0 {
+		c.shakeTicks--
+		i := c.shakeIntensity * float64(c.shakeTicks) / float64(c.shakeDuration)
+		c.shakeX = (c.float64()*2 - 1) * i
+		c.shakeY = (c.float64()*2 - 1) * i
+	} else {
+		c.shakeX = 0
+		c.shakeY = 0
+	}
+	c.Clamp()
+}
+
+// Clamp clamps the position so that the viewport is in Bounds.
+// If the viewport is larger than Bounds, the viewport is centered on Bounds.
+//
+// Rotation is not considered on clamping.
+//
+// If Bounds is empty, Clamp does nothing.
+func (c *Camera) Clamp() {
+	if c.Bounds.Empty() {
+		return
+	}
+	z := c.zoom()
+	hw := float64(c.ViewportWidth) / z / 2
+	hh := float64(c.ViewportHeight) / z / 2
+	c.X = clamp(c.X, float64(c.Bounds.Min.X)+hw, float64(c.Bounds.Max.X)-hw)
+	c.Y = clamp(c.Y, float64(c.Bounds.Min.Y)+hh, float64(c.Bounds.Max.Y)-hh)
+}
+
+func clamp(v, min, max float64) float64 {
+	if min > max {
+		return (min + max) / 2
+	}
+	return math.Max(min, math.Min(max, v))
+}
+
+// GeoM returns a matrix to transform the world coordinates into the screen coordinates.
+//
+// Concat the result to a GeoM of DrawImageOptions to draw an object in the world:
+//
+//     op := &ebiten.DrawImageOptions{}
+//     op.GeoM.Translate(objectX, objectY)
+//     op.GeoM.Concat(camera.GeoM())
+//     screen.DrawImage(objectImage, op)
+func (c *Camera) GeoM() ebiten.GeoM {
+	g := ebiten.GeoM{}
+	g.Translate(-c.X-c.shakeX, -c.Y-c.shakeY)
+	g.Rotate(-c.Rotation)
+	z := c.zoom()
+	g.Scale(z, z)
+	g.Translate(float64(c.ViewportWidth)/2, float64(c.ViewportHeight)/2)
+	return g
+}
+
+// WorldToScreen converts the position in the world coordinates into the screen coordinates.
+func (c *Camera) WorldToScreen(x, y float64) (float64, float64) {
+	g := c.GeoM()
+	return g.Apply(x, y)
+}
+
+// ScreenToWorld converts the position in the screen coordinates into the world coordinates.
+// This is useful to know the position in the world that the cursor points to.
+func (c *Camera) ScreenToWorld(x, y float64) (float64, float64) {
+	g := c.GeoM()
+	g.Invert()
+	return g.Apply(x, y)
+}
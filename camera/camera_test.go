@@ -0,0 +1,102 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package camera_test
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/camera"
+)
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestWorldToScreen(t *testing.T) {
+	c := NewCamera(320, 240)
+	c.X = 100
+	c.Y = 50
+	c.Zoom = 2
+
+	x, y := c.WorldToScreen(100, 50)
+	if !closeTo(x, 160) || !closeTo(y, 120) {
+		t.Errorf("WorldToScreen(100, 50): got: (%f, %f), want: (160, 120)", x, y)
+	}
+	x, y = c.WorldToScreen(110, 40)
+	if !closeTo(x, 180) || !closeTo(y, 100) {
+		t.Errorf("WorldToScreen(110, 40): got: (%f, %f), want: (180, 100)", x, y)
+	}
+
+	c.Rotation = math.Pi / 2
+	x, y = c.WorldToScreen(110, 50)
+	if !closeTo(x, 160) || !closeTo(y, 100) {
+		t.Errorf("WorldToScreen(110, 50) with rotation: got: (%f, %f), want: (160, 100)", x, y)
+	}
+}
+
+func TestScreenToWorld(t *testing.T) {
+	c := NewCamera(320, 240)
+	c.X = -30
+	c.Y = 70
+	c.Zoom = 1.5
+	c.Rotation = 0.3
+
+	for _, p := range [][2]float64{{0, 0}, {160, 120}, {320, 240}, {12.5, 200}} {
+		wx, wy := c.ScreenToWorld(p[0], p[1])
+		sx, sy := c.WorldToScreen(wx, wy)
+		if !closeTo(sx, p[0]) || !closeTo(sy, p[1]) {
+			t.Errorf("WorldToScreen(ScreenToWorld(%f, %f)): got: (%f, %f)", p[0], p[1], sx, sy)
+		}
+	}
+}
+
+func TestClamp(t *testing.T) {
+	c := NewCamera(100, 100)
+	c.Bounds = image.Rect(0, 0, 400, 150)
+
+	c.X, c.Y = -100, 1000
+	c.Clamp()
+	if c.X != 50 || c.Y != 100 {
+		t.Errorf("Clamp(): got: (%f, %f), want: (50, 100)", c.X, c.Y)
+	}
+
+	// The viewport is larger than the bounds vertically.
+	c.Zoom = 0.5
+	c.X, c.Y = 1000, 0
+	c.Clamp()
+	if c.X != 300 || c.Y != 75 {
+		t.Errorf("Clamp() with zoom: got: (%f, %f), want: (300, 75)", c.X, c.Y)
+	}
+}
+
+func TestShake(t *testing.T) {
+	c := NewCamera(100, 100)
+	c.Shake(10, 3)
+	for i := 0; i < 3; i++ {
+		if !c.IsShaking() {
+			t.Errorf("IsShaking() at tick %d: got: false, want: true", i)
+		}
+		c.Update()
+	}
+	if c.IsShaking() {
+		t.Error("IsShaking() after the duration: got: true, want: false")
+	}
+	x, y := c.WorldToScreen(0, 0)
+	if x != 50 || y != 50 {
+		t.Errorf("WorldToScreen(0, 0) after shaking: got: (%f, %f), want: (50, 50)", x, y)
+	}
+}
@@ -0,0 +1,572 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ebitenmobile is a wrapper of gomobile for Ebiten games.
+//
+// ebitenmobile binds a game package with gomobile and adds the views to run the game:
+// EbitenView on Android and EbitenViewController on iOS.
+// The views forward the lifecycle events (pause/resume and context lost) and the touch events to the game,
+// so you don't have to write the glue code by hand.
+//
+// The game package must call ebitenmobileview.SetUpdateFunc in its init function,
+// and must have at least one exported function so that gomobile can bind it.
+//
+// Usage:
+//
+//     ebitenmobile bind -target android -javapkg com.example.yourgame -o yourgame.aar ./yourgame/mobile
+//     ebitenmobile bind -target ios -o Yourgame.framework ./yourgame/mobile
+//
+// On Android, EbitenView's Java package is <javapkg>.ebitenmobileview.
+// Call EbitenView's suspendGame and resumeGame at onPause and onResume of your Activity.
+//
+// gomobile, and the Android SDK and JDK for Android, are required.
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const ebitenmobileviewPkg = "github.com/hajimehoshi/ebiten/mobile/ebitenmobileview"
+
+var (
+	flagTarget  = flag.String("target", "", "target platform: android or ios")
+	flagOutput  = flag.String("o", "", "output file name (.aar for android and .framework for ios)")
+	flagJavaPkg = flag.String("javapkg", "", "prefix of the Java package for android (see gomobile bind)")
+	flagPrefix  = flag.String("prefix", "", "prefix of the Objective-C names for ios (see gomobile bind)")
+	flagVerbose = flag.Bool("v", false, "print the commands")
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: ebitenmobile bind -target [android|ios] -o output [-javapkg pkg] [-prefix prefix] [-v] package")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	if len(os.Args) < 2 || os.Args[1] != "bind" {
+		usage()
+	}
+	if err := flag.CommandLine.Parse(os.Args[2:]); err != nil {
+		usage()
+	}
+	if flag.NArg() != 1 || *flagOutput == "" {
+		usage()
+	}
+	pkg := flag.Arg(0)
+
+	switch *flagTarget {
+	case "android":
+		if err := bindAndroid(pkg); err != nil {
+			log.Fatal(err)
+		}
+	case "ios":
+		if err := bindIOS(pkg); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		usage()
+	}
+}
+
+func run(name string, args ...string) error {
+	if *flagVerbose {
+		fmt.Fprintln(os.Stderr, name, strings.Join(args, " "))
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// gomobileBind binds the game package and ebitenmobileview together.
+func gomobileBind(pkg string, args ...string) error {
+	args = append([]string{"bind", "-target", *flagTarget, "-o", *flagOutput}, args...)
+	if *flagVerbose {
+		args = append(args, "-v")
+	}
+	args = append(args, ebitenmobileviewPkg, pkg)
+	return run("gomobile", args...)
+}
+
+func bindAndroid(pkg string) error {
+	var args []string
+	if *flagJavaPkg != "" {
+		args = append(args, "-javapkg", *flagJavaPkg)
+	}
+	if err := gomobileBind(pkg, args...); err != nil {
+		return err
+	}
+
+	javaPkg := "ebitenmobileview"
+	if *flagJavaPkg != "" {
+		javaPkg = *flagJavaPkg + ".ebitenmobileview"
+	}
+
+	dir, err := ioutil.TempDir("", "ebitenmobile")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// Compile EbitenView against the classes generated by gomobile.
+	src := filepath.Join(dir, "src", filepath.FromSlash(strings.Replace(javaPkg, ".", "/", -1)), "EbitenView.java")
+	if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(src)
+	if err != nil {
+		return err
+	}
+	if err := ebitenViewJavaTmpl.Execute(f, map[string]string{
+		"JavaPkg": javaPkg,
+	}); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	aar, err := ioutil.ReadFile(*flagOutput)
+	if err != nil {
+		return err
+	}
+	classesJar, err := readZipEntry(aar, "classes.jar")
+	if err != nil {
+		return err
+	}
+	classesJarPath := filepath.Join(dir, "classes.jar")
+	if err := ioutil.WriteFile(classesJarPath, classesJar, 0644); err != nil {
+		return err
+	}
+
+	androidJar, err := androidJarPath()
+	if err != nil {
+		return err
+	}
+	classesDir := filepath.Join(dir, "classes")
+	if err := os.Mkdir(classesDir, 0755); err != nil {
+		return err
+	}
+	if err := run("javac",
+		"-source", "1.7",
+		"-target", "1.7",
+		"-bootclasspath", androidJar,
+		"-classpath", classesJarPath,
+		"-d", classesDir,
+		src); err != nil {
+		return err
+	}
+
+	// Add the compiled classes to classes.jar, and update the .aar file.
+	files := map[string][]byte{}
+	if err := filepath.Walk(classesDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(classesDir, path)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = b
+		return nil
+	}); err != nil {
+		return err
+	}
+	newClassesJar, err := addZipEntries(classesJar, files)
+	if err != nil {
+		return err
+	}
+	newAAR, err := addZipEntries(aar, map[string][]byte{
+		"classes.jar": newClassesJar,
+	})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(*flagOutput, newAAR, 0644)
+}
+
+// androidJarPath returns the path of android.jar of the latest platform in the Android SDK.
+func androidJarPath() (string, error) {
+	sdk := os.Getenv("ANDROID_HOME")
+	if sdk == "" {
+		return "", errors.New("ebitenmobile: ANDROID_HOME must be set")
+	}
+	ps, err := filepath.Glob(filepath.Join(sdk, "platforms", "android-*", "android.jar"))
+	if err != nil {
+		return "", err
+	}
+	if len(ps) == 0 {
+		return "", fmt.Errorf("ebitenmobile: android.jar is not found in %s", sdk)
+	}
+	sort.Slice(ps, func(i, j int) bool {
+		return apiLevel(ps[i]) < apiLevel(ps[j])
+	})
+	return ps[len(ps)-1], nil
+}
+
+func apiLevel(androidJarPath string) int {
+	var l int
+	fmt.Sscanf(filepath.Base(filepath.Dir(androidJarPath)), "android-%d", &l)
+	return l
+}
+
+func readZipEntry(archive []byte, name string) ([]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("ebitenmobile: %s is not found", name)
+}
+
+// addZipEntries returns a new zip archive with the given entries.
+// An existing entry with the same name is replaced.
+func addZipEntries(archive []byte, entries map[string][]byte) ([]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, f := range r.File {
+		if _, ok := entries[f.Name]; ok {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		fw, err := w.CreateHeader(&f.FileHeader)
+		if err != nil {
+			rc.Close()
+			return nil, err
+		}
+		if _, err := io.Copy(fw, rc); err != nil {
+			rc.Close()
+			return nil, err
+		}
+		rc.Close()
+	}
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fw, err := w.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write(entries[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func bindIOS(pkg string) error {
+	var args []string
+	if *flagPrefix != "" {
+		args = append(args, "-prefix", *flagPrefix)
+	}
+	if err := gomobileBind(pkg, args...); err != nil {
+		return err
+	}
+
+	// EbitenViewController is already compiled into the framework via cgo.
+	// Add its header to the framework and include it from the umbrella header.
+	out, err := exec.Command("go", "list", "-f", "{{.Dir}}", ebitenmobileviewPkg).Output()
+	if err != nil {
+		return err
+	}
+	header, err := ioutil.ReadFile(filepath.Join(strings.TrimSpace(string(out)), "EbitenViewController.h"))
+	if err != nil {
+		return err
+	}
+	// Remove the build constraint for Go.
+	header = bytes.Replace(header, []byte("// +build ios\n"), nil, 1)
+
+	headers := filepath.Join(*flagOutput, "Versions", "A", "Headers")
+	if err := ioutil.WriteFile(filepath.Join(headers, "EbitenViewController.h"), header, 0644); err != nil {
+		return err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(*flagOutput), ".framework")
+	umbrella := filepath.Join(headers, name+".h")
+	u, err := ioutil.ReadFile(umbrella)
+	if err != nil {
+		return err
+	}
+	u = append(u, []byte("\n#include \"EbitenViewController.h\"\n")...)
+	return ioutil.WriteFile(umbrella, u, 0644)
+}
+
+var ebitenViewJavaTmpl = template.Must(template.New("EbitenView.java").Parse(`// Code generated by ebitenmobile. DO NOT EDIT.
+
+package {{.JavaPkg}};
+
+import android.content.Context;
+import android.hardware.Sensor;
+import android.hardware.SensorEvent;
+import android.hardware.SensorEventListener;
+import android.hardware.SensorManager;
+import android.opengl.GLSurfaceView;
+import android.os.Build;
+import android.os.Handler;
+import android.os.Looper;
+import android.util.AttributeSet;
+import android.util.Log;
+import android.view.DisplayCutout;
+import android.view.MotionEvent;
+import android.view.ViewGroup;
+import android.view.WindowInsets;
+
+import javax.microedition.khronos.egl.EGLConfig;
+import javax.microedition.khronos.opengles.GL10;
+
+// EbitenView is a view to run the game.
+//
+// EbitenView starts the game when the view is laid out.
+// Call suspendGame and resumeGame at onPause and onResume of the Activity.
+public class EbitenView extends ViewGroup {
+    private double getDeviceScale() {
+        if (deviceScale_ == 0.0) {
+            deviceScale_ = getResources().getDisplayMetrics().density;
+        }
+        return deviceScale_;
+    }
+
+    private double pxToDp(double x) {
+        return x / getDeviceScale();
+    }
+
+    private double dpToPx(double x) {
+        return x * getDeviceScale();
+    }
+
+    public EbitenView(Context context) {
+        super(context);
+        initialize(context);
+    }
+
+    public EbitenView(Context context, AttributeSet attrs) {
+        super(context, attrs);
+        initialize(context);
+    }
+
+    private void initialize(Context context) {
+        ebitenSurfaceView_ = new EbitenSurfaceView(context);
+        addView(ebitenSurfaceView_);
+        sensorManager_ = (SensorManager)context.getSystemService(Context.SENSOR_SERVICE);
+    }
+
+    private void registerSensors() {
+        if (sensorManager_ == null) {
+            return;
+        }
+        int[] types = {Sensor.TYPE_ACCELEROMETER, Sensor.TYPE_GYROSCOPE, Sensor.TYPE_ROTATION_VECTOR};
+        for (int type : types) {
+            Sensor sensor = sensorManager_.getDefaultSensor(type);
+            if (sensor != null) {
+                sensorManager_.registerListener(sensorListener_, sensor, SensorManager.SENSOR_DELAY_GAME);
+            }
+        }
+    }
+
+    private void unregisterSensors() {
+        if (sensorManager_ == null) {
+            return;
+        }
+        sensorManager_.unregisterListener(sensorListener_);
+    }
+
+    private final SensorEventListener sensorListener_ = new SensorEventListener() {
+        private final float[] rotationMatrix_ = new float[9];
+        private final float[] orientation_ = new float[3];
+
+        @Override
+        public void onSensorChanged(SensorEvent e) {
+            switch (e.sensor.getType()) {
+            case Sensor.TYPE_ACCELEROMETER:
+                Ebitenmobileview.updateAcceleration(e.values[0], e.values[1], e.values[2]);
+                break;
+            case Sensor.TYPE_GYROSCOPE:
+                Ebitenmobileview.updateRotationRate(e.values[0], e.values[1], e.values[2]);
+                break;
+            case Sensor.TYPE_ROTATION_VECTOR:
+                SensorManager.getRotationMatrixFromVector(rotationMatrix_, e.values);
+                SensorManager.getOrientation(rotationMatrix_, orientation_);
+                // Convert the azimuth, the pitch and the roll to alpha, beta and gamma of the W3C DeviceOrientation Event.
+                Ebitenmobileview.updateOrientation(-orientation_[0], -orientation_[1], orientation_[2]);
+                break;
+            }
+        }
+
+        @Override
+        public void onAccuracyChanged(Sensor sensor, int accuracy) {
+        }
+    };
+
+    @Override
+    protected void onLayout(boolean changed, int left, int top, int right, int bottom) {
+        int widthInPx = right - left;
+        int heightInPx = bottom - top;
+        updateSafeAreaInsets();
+        try {
+            Ebitenmobileview.layout(pxToDp(widthInPx), pxToDp(heightInPx));
+        } catch (Exception e) {
+            onErrorOnGameUpdate(e);
+            return;
+        }
+        double scaleInPx = dpToPx(Ebitenmobileview.screenScale());
+        int width = (int)(Ebitenmobileview.screenWidth() * scaleInPx);
+        int height = (int)(Ebitenmobileview.screenHeight() * scaleInPx);
+        int x = (widthInPx - width) / 2;
+        int y = (heightInPx - height) / 2;
+        ebitenSurfaceView_.layout(x, y, x + width, y + height);
+    }
+
+    private void updateSafeAreaInsets() {
+        if (Build.VERSION.SDK_INT < Build.VERSION_CODES.P) {
+            return;
+        }
+        WindowInsets insets = getRootWindowInsets();
+        if (insets == null) {
+            return;
+        }
+        DisplayCutout cutout = insets.getDisplayCutout();
+        if (cutout == null) {
+            Ebitenmobileview.setSafeAreaInsets(0, 0, 0, 0);
+            return;
+        }
+        Ebitenmobileview.setSafeAreaInsets(
+            pxToDp(cutout.getSafeInsetLeft()), pxToDp(cutout.getSafeInsetTop()),
+            pxToDp(cutout.getSafeInsetRight()), pxToDp(cutout.getSafeInsetBottom()));
+    }
+
+    // suspendGame suspends the game.
+    // This must be called at onPause of the Activity.
+    public void suspendGame() {
+        unregisterSensors();
+        ebitenSurfaceView_.onPause();
+        Ebitenmobileview.pause();
+    }
+
+    // resumeGame resumes the game.
+    // This must be called at onResume of the Activity.
+    public void resumeGame() {
+        registerSensors();
+        ebitenSurfaceView_.onResume();
+        Ebitenmobileview.resume();
+    }
+
+    // onErrorOnGameUpdate is called on the main thread when an error happens when updating the game.
+    // The default implementation logs the error. Override this to handle the error.
+    protected void onErrorOnGameUpdate(Exception e) {
+        Log.e("Go", e.toString());
+    }
+
+    private class EbitenSurfaceView extends GLSurfaceView {
+        public EbitenSurfaceView(Context context) {
+            super(context);
+            setEGLContextClientVersion(2);
+            setEGLConfigChooser(8, 8, 8, 8, 0, 0);
+            setPreserveEGLContextOnPause(true);
+            setRenderer(new EbitenRenderer());
+        }
+
+        @Override
+        public boolean onTouchEvent(MotionEvent e) {
+            for (int i = 0; i < e.getPointerCount(); i++) {
+                int id = e.getPointerId(i);
+                int x = (int)e.getX(i);
+                int y = (int)e.getY(i);
+                Ebitenmobileview.updateTouchesOnAndroid(e.getActionMasked(), id, (int)pxToDp(x), (int)pxToDp(y));
+            }
+            return true;
+        }
+    }
+
+    private class EbitenRenderer implements GLSurfaceView.Renderer {
+        private boolean surfaceCreated_ = false;
+
+        @Override
+        public void onDrawFrame(GL10 gl) {
+            try {
+                Ebitenmobileview.update();
+            } catch (final Exception e) {
+                new Handler(Looper.getMainLooper()).post(new Runnable() {
+                    @Override
+                    public void run() {
+                        onErrorOnGameUpdate(e);
+                    }
+                });
+            }
+        }
+
+        @Override
+        public void onSurfaceCreated(GL10 gl, EGLConfig config) {
+            // onSurfaceCreated is called again when the OpenGL context is lost and a new context is created.
+            if (surfaceCreated_) {
+                Ebitenmobileview.onContextLost();
+                return;
+            }
+            surfaceCreated_ = true;
+        }
+
+        @Override
+        public void onSurfaceChanged(GL10 gl, int width, int height) {
+        }
+    }
+
+    private double deviceScale_ = 0.0;
+    private EbitenSurfaceView ebitenSurfaceView_;
+    private SensorManager sensorManager_;
+}
+`))
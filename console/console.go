@@ -0,0 +1,364 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package console provides a drop-down debug console where games can register commands and variables.
+//
+// The console is opened and closed by a key (the grave accent key by default). While the console is open,
+// the typed line is executed by the Enter key:
+//
+//     name               shows the value of the variable name
+//     name value         sets the value of the variable name
+//     command args...    executes the command with the arguments
+//     help               shows the commands and the variables
+//     clear              clears the log
+//
+// The variables (cvars) are registered in the same way as the flag package:
+//
+//     c := console.New()
+//     c.Float64Var(&gravity, "gravity", 9.8, "the gravity in pixels per tick^2")
+//     c.RegisterCommand("spawn", "spawns an enemy", func(args []string) error {
+//         ...
+//     })
+//
+// Call Update in the game's update function and Draw at the end of the drawing.
+//
+// Note: This package is experimental and API might be changed.
+package console
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/hajimehoshi/ebiten/inpututil"
+	"github.com/hajimehoshi/ebiten/internal/assets"
+	"github.com/hajimehoshi/ebiten/widget"
+)
+
+// CommandFunc is a function of a command called with the arguments.
+//
+// The returned error is printed in the console.
+type CommandFunc func(args []string) error
+
+type command struct {
+	usage string
+	f     CommandFunc
+}
+
+// Console is a drop-down debug console.
+type Console struct {
+	// ToggleKey is the key to open and close the console.
+	ToggleKey ebiten.Key
+
+	// Lines is the number of the log lines shown in the console.
+	Lines int
+
+	// MaxLog is the maximum number of the log lines kept. The older lines are discarded.
+	MaxLog int
+
+	// MaxHistory is the maximum number of the executed lines kept for the history.
+	MaxHistory int
+
+	vars     *flag.FlagSet
+	commands map[string]*command
+
+	open  bool
+	ticks int
+
+	line    []rune
+	log     []string
+	history []string
+
+	// historyIndex is the index of the history shown in the line. len(history) means the new line.
+	historyIndex int
+}
+
+// New returns a new closed console.
+func New() *Console {
+	vars := flag.NewFlagSet("console", flag.ContinueOnError)
+	vars.SetOutput(ioutil.Discard)
+	return &Console{
+		ToggleKey:  ebiten.KeyGraveAccent,
+		Lines:      12,
+		MaxLog:     256,
+		MaxHistory: 64,
+		vars:       vars,
+		commands:   map[string]*command{},
+	}
+}
+
+// RegisterCommand registers a command with the name.
+//
+// If a command or a variable with the same name is already registered, RegisterCommand panics.
+func (c *Console) RegisterCommand(name string, usage string, f CommandFunc) {
+	c.checkName(name)
+	c.commands[name] = &command{
+		usage: usage,
+		f:     f,
+	}
+}
+
+func (c *Console) checkName(name string) {
+	if name == "" || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+		panic(fmt.Sprintf("console: invalid name: %q", name))
+	}
+	if _, ok := c.commands[name]; ok || name == "help" || name == "clear" || c.vars.Lookup(name) != nil {
+		panic(fmt.Sprintf("console: %s is already registered", name))
+	}
+}
+
+// Var registers a variable with the name. The variable is set by the Set function of value.
+//
+// If a command or a variable with the same name is already registered, Var panics.
+func (c *Console) Var(value flag.Value, name string, usage string) {
+	c.checkName(name)
+	c.vars.Var(value, name, usage)
+}
+
+// BoolVar registers a bool variable with the name and the default value. p points to the variable.
+func (c *Console) BoolVar(p *bool, name string, value bool, usage string) {
+	c.checkName(name)
+	c.vars.BoolVar(p, name, value, usage)
+}
+
+// IntVar registers an int variable with the name and the default value. p points to the variable.
+func (c *Console) IntVar(p *int, name string, value int, usage string) {
+	c.checkName(name)
+	c.vars.IntVar(p, name, value, usage)
+}
+
+// Float64Var registers a float64 variable with the name and the default value. p points to the variable.
+func (c *Console) Float64Var(p *float64, name string, value float64, usage string) {
+	c.checkName(name)
+	c.vars.Float64Var(p, name, value, usage)
+}
+
+// StringVar registers a string variable with the name and the default value. p points to the variable.
+func (c *Console) StringVar(p *string, name string, value string, usage string) {
+	c.checkName(name)
+	c.vars.StringVar(p, name, value, usage)
+}
+
+// IsOpen reports whether the console is open.
+//
+// While the console is open, the game should ignore the keyboard input.
+func (c *Console) IsOpen() bool {
+	return c.open
+}
+
+// SetOpen opens or closes the console.
+func (c *Console) SetOpen(open bool) {
+	c.open = open
+}
+
+// Println prints the operands to the log of the console in the manner of fmt.Sprintln.
+func (c *Console) Println(a ...interface{}) {
+	c.print(fmt.Sprintln(a...))
+}
+
+// Printf prints the formatted string to the log of the console in the manner of fmt.Sprintf.
+func (c *Console) Printf(format string, a ...interface{}) {
+	c.print(fmt.Sprintf(format, a...))
+}
+
+func (c *Console) print(str string) {
+	str = strings.TrimSuffix(str, "\n")
+	c.log = append(c.log, strings.Split(str, "\n")...)
+	if c.MaxLog > 0 && len(c.log) > c.MaxLog {
+		c.log = append(c.log[:0], c.log[len(c.log)-c.MaxLog:]...)
+	}
+}
+
+// Log returns the lines of the log.
+func (c *Console) Log() []string {
+	return c.log
+}
+
+// Exec executes the line as if it is typed in the console.
+func (c *Console) Exec(line string) {
+	c.Println("> " + line)
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+	name, args := fields[0], fields[1:]
+
+	switch name {
+	case "help":
+		c.help()
+		return
+	case "clear":
+		c.log = c.log[:0]
+		return
+	}
+
+	if cmd, ok := c.commands[name]; ok {
+		if err := cmd.f(args); err != nil {
+			c.Println("error:", err)
+		}
+		return
+	}
+	if f := c.vars.Lookup(name); f != nil {
+		if len(args) > 0 {
+			// A string variable can include spaces.
+			old := f.Value.String()
+			if err := c.vars.Set(name, strings.Join(args, " ")); err != nil {
+				// The flag values might be modified even when parsing fails.
+				f.Value.Set(old)
+				c.Println("error:", err)
+				return
+			}
+		}
+		c.Printf("%s = %s", name, f.Value)
+		return
+	}
+	c.Printf("unknown command: %s (see help)", name)
+}
+
+func (c *Console) help() {
+	var names []string
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c.Printf("%s: %s", name, c.commands[name].usage)
+	}
+	// VisitAll visits the variables in lexicographical order.
+	c.vars.VisitAll(func(f *flag.Flag) {
+		c.Printf("%s = %s: %s", f.Name, f.Value, f.Usage)
+	})
+}
+
+// Update updates the console with the keyboard input.
+//
+// Update should be called once every frame.
+func (c *Console) Update() {
+	if inpututil.IsKeyJustPressed(c.ToggleKey) {
+		// The character of the toggle key is not input.
+		c.open = !c.open
+		return
+	}
+	if !c.open {
+		return
+	}
+	c.UpdateWithInput(widget.CurrentInput())
+}
+
+// UpdateWithInput updates the console with the given input.
+//
+// The typed characters and the editing keys of in are used. The toggle key is not handled by UpdateWithInput.
+func (c *Console) UpdateWithInput(in widget.Input) {
+	c.ticks++
+	if !c.open {
+		return
+	}
+	for _, r := range in.Runes {
+		if !unicode.IsPrint(r) {
+			continue
+		}
+		c.line = append(c.line, r)
+	}
+	for _, k := range in.Keys {
+		switch k {
+		case ebiten.KeyBackspace:
+			if len(c.line) > 0 {
+				c.line = c.line[:len(c.line)-1]
+			}
+		case ebiten.KeyEnter:
+			line := string(c.line)
+			c.line = c.line[:0]
+			if strings.TrimSpace(line) != "" {
+				c.history = append(c.history, line)
+				if c.MaxHistory > 0 && len(c.history) > c.MaxHistory {
+					c.history = append(c.history[:0], c.history[len(c.history)-c.MaxHistory:]...)
+				}
+			}
+			c.historyIndex = len(c.history)
+			c.Exec(line)
+		case ebiten.KeyUp:
+			if c.historyIndex > 0 {
+				c.historyIndex--
+				c.line = []rune(c.history[c.historyIndex])
+			}
+		case ebiten.KeyDown:
+			if c.historyIndex < len(c.history) {
+				c.historyIndex++
+			}
+			if c.historyIndex < len(c.history) {
+				c.line = []rune(c.history[c.historyIndex])
+			} else {
+				c.line = c.line[:0]
+			}
+		}
+	}
+}
+
+// Line returns the line being typed.
+func (c *Console) Line() string {
+	return string(c.line)
+}
+
+// Draw draws the console at the top of the screen if the console is open.
+//
+// The texts are drawn with the debug font (see ebitenutil.DebugPrint).
+func (c *Console) Draw(screen *ebiten.Image) {
+	if !c.open {
+		return
+	}
+	const (
+		cw = assets.CharWidth
+		ch = assets.CharHeight
+	)
+	sw, _ := screen.Size()
+	cols := sw/cw - 1
+	if cols < 1 {
+		cols = 1
+	}
+
+	ebitenutil.DrawRect(screen, 0, 0, float64(sw), float64((c.Lines+1)*ch+4), color.RGBA{0, 0, 0, 0xc0})
+
+	// Wrap the log lines by the screen width and show the last lines.
+	var lines []string
+	for _, l := range c.log {
+		rs := []rune(l)
+		for len(rs) > cols {
+			lines = append(lines, string(rs[:cols]))
+			rs = rs[cols:]
+		}
+		lines = append(lines, string(rs))
+	}
+	if len(lines) > c.Lines {
+		lines = lines[len(lines)-c.Lines:]
+	}
+	for i, l := range lines {
+		ebitenutil.DebugPrintAt(screen, l, 0, 2+i*ch)
+	}
+
+	// Show the tail of the line if the line is too long. The caret blinks every half second.
+	prompt := []rune("> " + string(c.line))
+	if c.ticks/30%2 == 0 {
+		prompt = append(prompt, '_')
+	}
+	if len(prompt) > cols {
+		prompt = prompt[len(prompt)-cols:]
+	}
+	ebitenutil.DebugPrintAt(screen, string(prompt), 0, 2+c.Lines*ch)
+}
@@ -0,0 +1,216 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package console_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	. "github.com/hajimehoshi/ebiten/console"
+	"github.com/hajimehoshi/ebiten/ebitentest"
+	"github.com/hajimehoshi/ebiten/widget"
+)
+
+func TestMain(m *testing.M) {
+	if err := ebitentest.Init(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func lastLog(c *Console) string {
+	l := c.Log()
+	if len(l) == 0 {
+		return ""
+	}
+	return l[len(l)-1]
+}
+
+func TestExecCommand(t *testing.T) {
+	c := New()
+	var got []string
+	c.RegisterCommand("spawn", "spawns an enemy", func(args []string) error {
+		got = args
+		return nil
+	})
+	c.RegisterCommand("fail", "fails", func(args []string) error {
+		return errors.New("failed")
+	})
+
+	c.Exec("  spawn  goblin 3 ")
+	if want := []string{"goblin", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("args: got: %v, want: %v", got, want)
+	}
+	c.Exec("fail")
+	if got, want := lastLog(c), "error: failed"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+	c.Exec("foo")
+	if got, want := lastLog(c), "unknown command: foo (see help)"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+	c.Exec("clear")
+	if got := len(c.Log()); got != 0 {
+		t.Errorf("len(Log()): got: %d, want: 0", got)
+	}
+}
+
+func TestExecVar(t *testing.T) {
+	c := New()
+	var (
+		gravity float64
+		god     bool
+		name    string
+	)
+	c.Float64Var(&gravity, "gravity", 9.8, "the gravity")
+	c.BoolVar(&god, "god", false, "the god mode")
+	c.StringVar(&name, "name", "player", "the player name")
+
+	if gravity != 9.8 {
+		t.Errorf("gravity: got: %v, want: 9.8", gravity)
+	}
+	c.Exec("gravity")
+	if got, want := lastLog(c), "gravity = 9.8"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+	c.Exec("gravity 4.5")
+	if gravity != 4.5 {
+		t.Errorf("gravity: got: %v, want: 4.5", gravity)
+	}
+	c.Exec("god true")
+	if !god {
+		t.Errorf("god: got: false, want: true")
+	}
+	c.Exec("name Ebiten  Gopher")
+	if want := "Ebiten Gopher"; name != want {
+		t.Errorf("name: got: %q, want: %q", name, want)
+	}
+	c.Exec("gravity abc")
+	if got := lastLog(c); !strings.HasPrefix(got, "error: ") {
+		t.Errorf("got: %q, want: an error", got)
+	}
+	if gravity != 4.5 {
+		t.Errorf("gravity: got: %v, want: 4.5", gravity)
+	}
+
+	c.Exec("help")
+	if got, want := lastLog(c), "name = Ebiten Gopher: the player name"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+}
+
+func TestRegisterDuplicated(t *testing.T) {
+	c := New()
+	var v int
+	c.IntVar(&v, "foo", 0, "")
+	for _, name := range []string{"foo", "help", "a b", ""} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterCommand(%q) must panic", name)
+				}
+			}()
+			c.RegisterCommand(name, "", func([]string) error { return nil })
+		}()
+	}
+}
+
+func TestTyping(t *testing.T) {
+	c := New()
+	var n int
+	c.IntVar(&n, "n", 0, "")
+
+	// The input is ignored while the console is closed.
+	c.UpdateWithInput(widget.Input{Runes: []rune("n 1")})
+	if got := c.Line(); got != "" {
+		t.Errorf("Line(): got: %q, want: \"\"", got)
+	}
+
+	c.SetOpen(true)
+	c.UpdateWithInput(widget.Input{Runes: []rune("n 12\t")})
+	c.UpdateWithInput(widget.Input{Keys: []ebiten.Key{ebiten.KeyBackspace}})
+	if got, want := c.Line(), "n 1"; got != want {
+		t.Errorf("Line(): got: %q, want: %q", got, want)
+	}
+	c.UpdateWithInput(widget.Input{Keys: []ebiten.Key{ebiten.KeyEnter}})
+	if n != 1 {
+		t.Errorf("n: got: %d, want: 1", n)
+	}
+	if got := c.Line(); got != "" {
+		t.Errorf("Line(): got: %q, want: \"\"", got)
+	}
+
+	c.UpdateWithInput(widget.Input{Runes: []rune("n 2")})
+	c.UpdateWithInput(widget.Input{Keys: []ebiten.Key{ebiten.KeyEnter}})
+
+	up := widget.Input{Keys: []ebiten.Key{ebiten.KeyUp}}
+	down := widget.Input{Keys: []ebiten.Key{ebiten.KeyDown}}
+	c.UpdateWithInput(up)
+	if got, want := c.Line(), "n 2"; got != want {
+		t.Errorf("Line(): got: %q, want: %q", got, want)
+	}
+	c.UpdateWithInput(up)
+	c.UpdateWithInput(up)
+	if got, want := c.Line(), "n 1"; got != want {
+		t.Errorf("Line(): got: %q, want: %q", got, want)
+	}
+	c.UpdateWithInput(down)
+	if got, want := c.Line(), "n 2"; got != want {
+		t.Errorf("Line(): got: %q, want: %q", got, want)
+	}
+	c.UpdateWithInput(down)
+	if got := c.Line(); got != "" {
+		t.Errorf("Line(): got: %q, want: \"\"", got)
+	}
+}
+
+func TestMaxLog(t *testing.T) {
+	c := New()
+	c.MaxLog = 3
+	for i := 0; i < 5; i++ {
+		c.Printf("%d", i)
+	}
+	c.Println("a\nb")
+	if got, want := c.Log(), []string{"4", "a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Log(): got: %v, want: %v", got, want)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	c := New()
+	c.Println("hello")
+	screen, _ := ebiten.NewImage(160, 240, ebiten.FilterDefault)
+
+	c.Draw(screen)
+	_, _, _, a := screen.At(0, 0).RGBA()
+	if a != 0 {
+		t.Errorf("the closed console must not be drawn")
+	}
+
+	c.SetOpen(true)
+	c.Draw(screen)
+	_, _, _, a = screen.At(0, 0).RGBA()
+	if a == 0 {
+		t.Errorf("the open console must be drawn")
+	}
+	_, _, _, a = screen.At(0, 239).RGBA()
+	if a != 0 {
+		t.Errorf("the console must not cover the bottom of the screen")
+	}
+}
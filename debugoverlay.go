@@ -0,0 +1,122 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+	"image"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/internal/assets"
+	"github.com/hajimehoshi/ebiten/internal/graphics"
+)
+
+// debugOverlay is the statistics shown by SetDebugOverlayEnabled.
+type debugOverlay struct {
+	// textImage is the image of the debug font. textImage is created when the overlay is drawn first.
+	textImage *Image
+
+	drawImages    int
+	drawCalls     int
+	skippedStates int
+
+	memStats        runtime.MemStats
+	memStatsUpdated time.Time
+}
+
+// update updates the statistics for the last frame.
+//
+// update must be called once a frame whether the overlay is enabled or not.
+func (d *debugOverlay) update() {
+	d.drawImages, d.drawCalls, d.skippedStates = graphics.TakeDrawStats()
+	if !IsDebugOverlayEnabled() {
+		return
+	}
+	// ReadMemStats stops the world. Avoid calling this every frame.
+	if now := time.Now(); now.Sub(d.memStatsUpdated) >= time.Second {
+		runtime.ReadMemStats(&d.memStats)
+		d.memStatsUpdated = now
+	}
+}
+
+func (d *debugOverlay) text() string {
+	const mib = 1 << 20
+	return fmt.Sprintf(`FPS: %0.2f (refresh rate: %d Hz)
+TPS: %0.2f
+Draws: %d (calls: %d)
+Skipped states: %d
+Images: %0.1f MiB
+Heap: %0.1f MiB (GC: %d)`,
+		CurrentFPS(), RefreshRate(), CurrentTPS(),
+		d.drawImages, d.drawCalls, d.skippedStates,
+		float64(ImageMemoryUsage())/mib,
+		float64(d.memStats.HeapAlloc)/mib, d.memStats.NumGC)
+}
+
+// draw draws the overlay on dst. The overlay is scaled by scale and then transformed by geom.
+func (d *debugOverlay) draw(dst *Image, geom GeoM, scale float64) {
+	if d.textImage == nil {
+		d.textImage, _ = NewImageFromImage(assets.CreateTextImage(), FilterDefault)
+		theImages.remove(d.textImage)
+	}
+
+	const (
+		cw      = assets.CharWidth
+		ch      = assets.CharHeight
+		padding = 2
+	)
+
+	str := d.text()
+	lines := strings.Split(str, "\n")
+	maxLen := 0
+	for _, l := range lines {
+		if maxLen < len(l) {
+			maxLen = len(l)
+		}
+	}
+
+	// Draw a translucent background so that the text is readable on any screen.
+	ew, eh := emptyImage.Size()
+	op := &DrawImageOptions{}
+	op.GeoM.Scale(float64(maxLen*cw+2*padding)/float64(ew), float64(len(lines)*ch+2*padding)/float64(eh))
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Concat(geom)
+	op.ColorM.Translate(0, 0, 0, 0.5)
+	_ = dst.DrawImage(emptyImage, op)
+
+	w, _ := d.textImage.Size()
+	x, y := 0, 0
+	var r image.Rectangle
+	for _, c := range str {
+		if c == '\n' {
+			x = 0
+			y += ch
+			continue
+		}
+		n := w / cw
+		sx := (int(c) % n) * cw
+		sy := (int(c) / n) * ch
+		r = image.Rect(sx, sy, sx+cw, sy+ch)
+		op := &DrawImageOptions{}
+		op.SourceRect = &r
+		op.GeoM.Translate(float64(x+padding), float64(y+padding))
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Concat(geom)
+		_ = dst.DrawImage(d.textImage, op)
+		x += cw
+	}
+}
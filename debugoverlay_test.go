@@ -0,0 +1,153 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/internal/graphics"
+)
+
+func TestDrawStats(t *testing.T) {
+	src, _ := NewImage(4, 4, FilterDefault)
+	dst, _ := NewImage(16, 16, FilterDefault)
+	src.Fill(color.White)
+	dst.Fill(color.Black)
+
+	// Flush the commands so far.
+	_ = dst.At(0, 0)
+	graphics.TakeDrawStats()
+
+	for i := 0; i < 3; i++ {
+		op := &DrawImageOptions{}
+		op.GeoM.Translate(float64(4*i), 0)
+		_ = dst.DrawImage(src, op)
+	}
+	_ = dst.At(0, 0)
+
+	draws, calls, _ := graphics.TakeDrawStats()
+	if draws != 3 {
+		t.Errorf("draw-image requests: got: %d, want: 3", draws)
+	}
+	if calls != 1 {
+		t.Errorf("draw calls: got: %d, want: 1", calls)
+	}
+}
+
+func TestSkippedStateChanges(t *testing.T) {
+	src0, _ := NewImage(4, 4, FilterDefault)
+	src1, _ := NewImage(4, 4, FilterDefault)
+	dst, _ := NewImage(16, 16, FilterDefault)
+	src0.Fill(color.White)
+	src1.Fill(color.White)
+	dst.Fill(color.Black)
+
+	_ = dst.At(0, 0)
+	graphics.TakeDrawStats()
+
+	// The two draw calls use the same program and the same uniform values.
+	_ = dst.DrawImage(src0, nil)
+	_ = dst.DrawImage(src1, nil)
+	_ = dst.At(0, 0)
+
+	_, calls, skipped := graphics.TakeDrawStats()
+	if calls != 2 {
+		t.Errorf("draw calls: got: %d, want: 2", calls)
+	}
+	if skipped == 0 {
+		t.Errorf("skipped state changes: got: 0, want: > 0")
+	}
+}
+
+func TestTintedDrawsMerged(t *testing.T) {
+	src, _ := NewImage(4, 4, FilterDefault)
+	dst, _ := NewImage(16, 16, FilterDefault)
+	src.Fill(color.White)
+	dst.Fill(color.Black)
+
+	_ = dst.At(0, 0)
+	graphics.TakeDrawStats()
+
+	// Tints consisting of only scaling and translation are applied per vertex.
+	op := &DrawImageOptions{}
+	op.ColorM.Scale(1, 0, 0, 1)
+	_ = dst.DrawImage(src, op)
+	op = &DrawImageOptions{}
+	op.GeoM.Translate(8, 0)
+	op.ColorM.Scale(0, 1, 0, 1)
+	op.ColorM.Translate(0, 0, 0.5, 0)
+	_ = dst.DrawImage(src, op)
+
+	if got, want := dst.At(0, 0), (color.RGBA{0xff, 0, 0, 0xff}); got != want {
+		t.Errorf("dst.At(0, 0): got: %v, want: %v", got, want)
+	}
+	got := dst.At(8, 0).(color.RGBA)
+	if got.R != 0 || got.G != 0xff || got.B < 0x7f || 0x80 < got.B || got.A != 0xff {
+		t.Errorf("dst.At(8, 0): got: %v, want: (0, 255, 128, 255)", got)
+	}
+
+	_, calls, _ := graphics.TakeDrawStats()
+	if calls != 1 {
+		t.Errorf("draw calls: got: %d, want: 1", calls)
+	}
+}
+
+func TestDrawImagesStats(t *testing.T) {
+	src, _ := NewImage(4, 4, FilterDefault)
+	dst, _ := NewImage(16, 16, FilterDefault)
+	src.Fill(color.White)
+	dst.Fill(color.Black)
+
+	_ = dst.At(0, 0)
+	graphics.TakeDrawStats()
+
+	ops := make([]DrawImageOptions, 3)
+	for i := range ops {
+		ops[i].GeoM.Translate(float64(i*4), 0)
+		ops[i].ColorM.Scale(1, float64(i)/2, 0, 1)
+	}
+	_ = dst.DrawImages(src, ops)
+	_ = dst.At(0, 0)
+
+	draws, calls, _ := graphics.TakeDrawStats()
+	if draws != 3 {
+		t.Errorf("draw images: got: %d, want: 3", draws)
+	}
+	if calls != 1 {
+		t.Errorf("draw calls: got: %d, want: 1", calls)
+	}
+}
+
+func TestDebugOverlayDraw(t *testing.T) {
+	dst, _ := NewImage(200, 200, FilterDefault)
+	dst.Fill(color.White)
+
+	d := &debugOverlay{}
+	d.draw(dst, GeoM{}, 1)
+
+	// The background darkens the corner.
+	got := dst.At(1, 1).(color.RGBA)
+	want := color.RGBA{0x80, 0x80, 0x80, 0xff}
+	if got.R < 0x7f || 0x80 < got.R || got.G != got.R || got.B != got.R || got.A != 0xff {
+		t.Errorf("dst.At(1, 1): got: %v, want: %v", got, want)
+	}
+	// The overlay doesn't cover the far side.
+	got = dst.At(199, 199).(color.RGBA)
+	want = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	if got != want {
+		t.Errorf("dst.At(199, 199): got: %v, want: %v", got, want)
+	}
+}
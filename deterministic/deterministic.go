@@ -0,0 +1,192 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deterministic provides a deterministic simulation for lockstep and rollback networking.
+//
+// In lockstep networking, all the peers run the same simulation with the same inputs tick by tick, and only the
+// inputs are exchanged. This requires the simulation to be strictly deterministic: the result of a tick must depend
+// only on the state of the previous tick and the inputs of the tick.
+//
+// This package provides:
+//
+//   - Input, a snapshot of the local input state that can be serialized and sent to the other peers.
+//   - Simulation, which counts the ticks and provides a random number generator seeded deterministically per tick.
+//
+// A typical lockstep game captures the local input by CaptureInput in Update, exchanges it with the other peers,
+// and calls Simulation.Step when the inputs of all the players for the next tick are available.
+// The simulation should read the input only from the given Inputs, not from the ebiten package.
+//
+// To make the ticks follow the system clock strictly, enable ebiten's deterministic mode by
+// ebiten.SetDeterministicModeEnabled.
+//
+// The simulation must also be deterministic by itself: run the simulation on a single goroutine, don't depend on
+// the iteration order of maps, don't use the time or the global random number generator, and be careful with
+// the floating point operations that might differ among the platforms.
+//
+// Note: This package is experimental and API might be changed.
+package deterministic
+
+import (
+	"encoding/json"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/input"
+	"github.com/hajimehoshi/ebiten/internal/inputstate"
+)
+
+// Input is a snapshot of the input state of a player at a tick.
+//
+// The zero value represents no input.
+type Input struct {
+	state input.State
+}
+
+// CaptureInput returns the current input state of the local player.
+//
+// The positions are in the logical screen coordinates.
+func CaptureInput() *Input {
+	return &Input{
+		state: inputstate.Capture(),
+	}
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (i *Input) MarshalBinary() ([]byte, error) {
+	return json.Marshal(&i.state)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (i *Input) UnmarshalBinary(data []byte) error {
+	var s input.State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	i.state = s
+	return nil
+}
+
+// InputChars returns the characters input at the tick.
+func (i *Input) InputChars() []rune {
+	rs := make([]rune, len(i.state.Runes))
+	copy(rs, i.state.Runes)
+	return rs
+}
+
+// IsKeyPressed reports whether the key is pressed.
+func (i *Input) IsKeyPressed(key ebiten.Key) bool {
+	return i.state.IsKeyPressed(input.Key(key))
+}
+
+// IsMouseButtonPressed reports whether the mouse button is pressed.
+func (i *Input) IsMouseButtonPressed(button ebiten.MouseButton) bool {
+	return i.state.IsMouseButtonPressed(input.MouseButton(button))
+}
+
+// CursorPosition returns the cursor position.
+func (i *Input) CursorPosition() (x, y int) {
+	return i.state.CursorX, i.state.CursorY
+}
+
+// GamepadIDs returns the IDs of the connected gamepads.
+func (i *Input) GamepadIDs() []int {
+	return i.state.GamepadIDs()
+}
+
+// GamepadAxis returns the value of the gamepad's axis in [-1, 1].
+func (i *Input) GamepadAxis(id int, axis int) float64 {
+	return i.state.GamepadAxis(id, axis)
+}
+
+// IsGamepadButtonPressed reports whether the gamepad's button is pressed.
+func (i *Input) IsGamepadButtonPressed(id int, button ebiten.GamepadButton) bool {
+	return i.state.IsGamepadButtonPressed(id, input.GamepadButton(button))
+}
+
+// TouchIDs returns the IDs of the touches.
+func (i *Input) TouchIDs() []int {
+	ids := make([]int, len(i.state.Touches))
+	for idx, t := range i.state.Touches {
+		ids[idx] = t.ID
+	}
+	return ids
+}
+
+// TouchPosition returns the position of the touch. If the touch doesn't exist, TouchPosition returns (0, 0).
+func (i *Input) TouchPosition(id int) (x, y int) {
+	for _, t := range i.state.Touches {
+		if t.ID == id {
+			return t.X, t.Y
+		}
+	}
+	return 0, 0
+}
+
+// Simulation counts the ticks of a deterministic simulation.
+type Simulation struct {
+	seed int64
+	tick int64
+	rand *rand.Rand
+}
+
+// NewSimulation returns a new Simulation at the tick 0.
+//
+// seed is the seed of the random number generator, which must be the same among the peers.
+func NewSimulation(seed int64) *Simulation {
+	return &Simulation{
+		seed: seed,
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Tick returns the current tick, that is the number of the steps so far.
+func (s *Simulation) Tick() int64 {
+	return s.tick
+}
+
+// SetTick sets the current tick, e.g. to rewind the simulation for rollback networking.
+//
+// The game state must be restored to the state at the tick by the game.
+func (s *Simulation) SetTick(tick int64) {
+	s.tick = tick
+}
+
+// Rand returns the random number generator for the simulation.
+//
+// Rand is seeded with the seed and the tick at every Step, so the random numbers at a tick are always the same
+// even when the tick is simulated again after rollback. Rand must be used only in the function given to Step.
+func (s *Simulation) Rand() *rand.Rand {
+	return s.rand
+}
+
+// Step simulates the current tick by calling f, and advances the tick.
+//
+// f is called with the tick being simulated. If f returns an error, the tick is not advanced and Step returns
+// the error.
+func (s *Simulation) Step(f func(tick int64) error) error {
+	s.rand.Seed(tickSeed(s.seed, s.tick))
+	if err := f(s.tick); err != nil {
+		return err
+	}
+	s.tick++
+	return nil
+}
+
+// tickSeed returns a seed for the tick, mixed by SplitMix64 so that the seeds for the adjacent ticks are not correlated.
+func tickSeed(seed, tick int64) int64 {
+	z := uint64(seed) + uint64(tick+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
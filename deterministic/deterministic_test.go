@@ -0,0 +1,101 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deterministic_test
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+
+	. "github.com/hajimehoshi/ebiten/deterministic"
+)
+
+func TestSimulationRand(t *testing.T) {
+	run := func(s *Simulation, n int) []int64 {
+		var vals []int64
+		for i := 0; i < n; i++ {
+			if err := s.Step(func(tick int64) error {
+				vals = append(vals, s.Rand().Int63())
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return vals
+	}
+
+	a := run(NewSimulation(1), 10)
+	b := run(NewSimulation(1), 10)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("the random number at the tick %d: got: %d, want: %d", i, b[i], a[i])
+		}
+	}
+	if a[0] == a[1] {
+		t.Errorf("the random numbers at the adjacent ticks must be different")
+	}
+
+	// Rewinding the simulation reproduces the same random numbers.
+	s := NewSimulation(1)
+	run(s, 10)
+	s.SetTick(5)
+	for i, v := range run(s, 5) {
+		if v != a[5+i] {
+			t.Errorf("the random number at the tick %d after rollback: got: %d, want: %d", 5+i, v, a[5+i])
+		}
+	}
+	if got, want := s.Tick(), int64(10); got != want {
+		t.Errorf("Tick(): got: %d, want: %d", got, want)
+	}
+
+	if got, want := run(NewSimulation(2), 1)[0], a[0]; got == want {
+		t.Errorf("the random numbers with the different seeds must be different: %d", got)
+	}
+}
+
+func TestInputMarshal(t *testing.T) {
+	var in Input
+	bs, err := in.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Input
+	if err := out.UnmarshalBinary(bs); err != nil {
+		t.Fatal(err)
+	}
+	if out.IsKeyPressed(ebiten.KeySpace) {
+		t.Errorf("IsKeyPressed(KeySpace): got: true, want: false")
+	}
+
+	if err := out.UnmarshalBinary([]byte(`{"keys":[0],"cursorX":1,"cursorY":2,"touches":[{"id":3,"x":4,"y":5}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if !out.IsKeyPressed(ebiten.Key(0)) {
+		t.Errorf("IsKeyPressed(0): got: false, want: true")
+	}
+	if x, y := out.CursorPosition(); x != 1 || y != 2 {
+		t.Errorf("CursorPosition(): got: (%d, %d), want: (1, 2)", x, y)
+	}
+	if ids := out.TouchIDs(); len(ids) != 1 || ids[0] != 3 {
+		t.Errorf("TouchIDs(): got: %v, want: [3]", ids)
+	}
+	if x, y := out.TouchPosition(3); x != 4 || y != 5 {
+		t.Errorf("TouchPosition(3): got: (%d, %d), want: (4, 5)", x, y)
+	}
+
+	if err := out.UnmarshalBinary([]byte("invalid")); err == nil {
+		t.Errorf("UnmarshalBinary with an invalid data must return an error")
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/internal/shareable"
+)
+
+// imageEntry is an entry of the image registry.
+//
+// imageEntry holds the internal shareable images instead of the image itself, so that the registry doesn't
+// prevent the image from being released by GC.
+type imageEntry struct {
+	shareableImage *shareable.Image
+
+	// palette is the shareable image of the palette of a paletted image, or nil.
+	palette *shareable.Image
+}
+
+// imageRegistry tracks the images created by the user, and the images released by GC that are waiting for
+// disposal.
+//
+// The tracked images are keyed by their IDs, which are assigned in their creation order.
+type imageRegistry struct {
+	images map[uint64]imageEntry
+	nextID uint64
+
+	// finalized is the images whose finalizers have run. They are disposed at the end of a frame.
+	finalized []*Image
+
+	m sync.Mutex
+}
+
+var theImages = &imageRegistry{}
+
+// add starts tracking the image i.
+func (r *imageRegistry) add(i *Image) {
+	e := imageEntry{
+		shareableImage: i.shareableImage,
+	}
+	if i.palette != nil {
+		e.palette = i.palette.shareableImage
+	}
+
+	r.m.Lock()
+	if r.images == nil {
+		r.images = map[uint64]imageEntry{}
+	}
+	r.nextID++
+	i.id = r.nextID
+	r.images[i.id] = e
+	r.m.Unlock()
+}
+
+// remove stops tracking the image i.
+//
+// remove is called when i is disposed, or when i is used only internally.
+func (r *imageRegistry) remove(i *Image) {
+	r.m.Lock()
+	r.removeWithoutLock(i)
+	r.m.Unlock()
+}
+
+// removeWithoutLock stops tracking the image i.
+//
+// removeWithoutLock must be called with the lock.
+func (r *imageRegistry) removeWithoutLock(i *Image) {
+	if i.id == 0 {
+		return
+	}
+	delete(r.images, i.id)
+	i.id = 0
+}
+
+// list returns the information of the tracked images that are not disposed in their creation order.
+func (r *imageRegistry) list() []ImageInfo {
+	type idEntry struct {
+		id    uint64
+		entry imageEntry
+	}
+	r.m.Lock()
+	entries := make([]idEntry, 0, len(r.images))
+	for id, e := range r.images {
+		entries = append(entries, idEntry{id, e})
+	}
+	r.m.Unlock()
+
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].id < entries[b].id
+	})
+	infos := make([]ImageInfo, 0, len(entries))
+	for _, e := range entries {
+		s := e.entry.shareableImage
+		if s.IsDisposed() {
+			continue
+		}
+		w, h := s.Size()
+		infos = append(infos, ImageInfo{
+			Width:  w,
+			Height: h,
+			Format: PixelFormat(s.Format()),
+		})
+	}
+	return infos
+}
+
+// disposeAll disposes the tracked images and stops tracking them.
+func (r *imageRegistry) disposeAll() {
+	r.m.Lock()
+	images := r.images
+	r.images = nil
+	r.m.Unlock()
+
+	// The images themselves are not accessible here. The images see their shareable images disposed.
+	for _, e := range images {
+		e.shareableImage.Dispose()
+		if e.palette != nil {
+			e.palette.Dispose()
+		}
+	}
+}
+
+// enqueue stops tracking the image i released by GC, and queues i for disposal.
+//
+// enqueue can be called from any goroutines.
+func (r *imageRegistry) enqueue(i *Image) {
+	r.m.Lock()
+	r.removeWithoutLock(i)
+	r.finalized = append(r.finalized, i)
+	r.m.Unlock()
+}
+
+// disposeFinalized disposes the images queued by enqueue.
+func (r *imageRegistry) disposeFinalized() {
+	r.m.Lock()
+	imgs := r.finalized
+	r.finalized = nil
+	r.m.Unlock()
+
+	for _, i := range imgs {
+		_ = i.Dispose()
+	}
+}
+
+// setFinalizer sets the finalizer of the image i.
+//
+// Disposing an image issues commands to GPU. The finalizer doesn't dispose the image immediately since finalizers
+// run on another goroutine at arbitrary timings, e.g. in the middle of a frame. Instead, the image is disposed at
+// the end of the frame.
+func setFinalizer(i *Image) {
+	runtime.SetFinalizer(i, theImages.enqueue)
+}
+
+// ImageInfo represents an image created by the user and not disposed yet. See Images.
+type ImageInfo struct {
+	Width  int
+	Height int
+	Format PixelFormat
+}
+
+// Images returns the information of the images created by the user and not disposed yet, in their creation order.
+//
+// The images created by the functions like NewImage are included. The screen images passed to the game's update
+// function and the images used by Ebiten internally are not included.
+//
+// Images doesn't prevent the images from being released by GC: an image that is no longer referred anywhere
+// else is released and disposed at the end of a frame, and is not included after that.
+//
+// Images is useful for debugging leaks of images.
+func Images() []ImageInfo {
+	return theImages.list()
+}
+
+// DisposeAll disposes all the images that Images returns.
+//
+// DisposeAll is useful to release GPU memory at once for explicit teardown e.g. at a scene change.
+// Note that the disposed images can't be used any more. Recreate the images after DisposeAll if needed.
+//
+// DisposeAll always returns nil.
+func DisposeAll() error {
+	theImages.disposeAll()
+	return nil
+}
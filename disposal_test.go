@@ -0,0 +1,129 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/internal/shareable"
+)
+
+func TestImages(t *testing.T) {
+	// Use unusual sizes to identify the images.
+	img0, _ := NewImage(31, 31, FilterDefault)
+	img1, _ := NewImageWithFormat(33, 33, PixelFormatAlpha8, FilterDefault)
+	img2, _ := NewVolatileImage(35, 35, FilterDefault)
+	defer img0.Dispose()
+	defer img2.Dispose()
+
+	indexOf := func(info ImageInfo) int {
+		for i, info2 := range Images() {
+			if info2 == info {
+				return i
+			}
+		}
+		return -1
+	}
+	info0 := ImageInfo{Width: 31, Height: 31, Format: PixelFormatRGBA8}
+	info1 := ImageInfo{Width: 33, Height: 33, Format: PixelFormatAlpha8}
+	info2 := ImageInfo{Width: 35, Height: 35, Format: PixelFormatRGBA8}
+	i0, i1, i2 := indexOf(info0), indexOf(info1), indexOf(info2)
+	if i0 < 0 || i1 < 0 || i2 < 0 {
+		t.Fatalf("Images() must include the created images: indices: %d, %d, %d", i0, i1, i2)
+	}
+	if !(i0 < i1 && i1 < i2) {
+		t.Errorf("Images() must be in the creation order: indices: %d, %d, %d", i0, i1, i2)
+	}
+
+	n := len(Images())
+	img1.Dispose()
+	if got, want := len(Images()), n-1; got != want {
+		t.Errorf("len(Images()) after Dispose: got: %d, want: %d", got, want)
+	}
+	if indexOf(info1) >= 0 {
+		t.Errorf("Images() must not include the disposed images")
+	}
+}
+
+func TestImagesReleasedByGC(t *testing.T) {
+	// Create the image in another function so that no reference remains on the stack.
+	var s *shareable.Image
+	var id uint64
+	func() {
+		img, _ := NewImage(16, 16, FilterDefault)
+		s, id = img.shareableImage, img.id
+	}()
+
+	tracked := func() bool {
+		theImages.m.Lock()
+		defer theImages.m.Unlock()
+		_, ok := theImages.images[id]
+		return ok
+	}
+
+	// The finalizer runs on another goroutine after GC.
+	for i := 0; i < 50 && tracked(); i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if tracked() {
+		t.Fatalf("the image released by GC must not be tracked")
+	}
+	if s.IsDisposed() {
+		t.Errorf("the image released by GC must not be disposed until the end of the frame")
+	}
+
+	// graphicsContext.Update calls disposeFinalized at the end of a frame.
+	theImages.disposeFinalized()
+	if !s.IsDisposed() {
+		t.Errorf("the image released by GC must be disposed at the end of the frame")
+	}
+}
+
+func TestImageRegistryDisposeAll(t *testing.T) {
+	// Use a local registry not to dispose the images of the other tests.
+	r := &imageRegistry{}
+	var imgs []*Image
+	for i := 0; i < 3; i++ {
+		img, _ := NewImage(16, 16, FilterDefault)
+		theImages.remove(img)
+		r.add(img)
+		imgs = append(imgs, img)
+	}
+	pimg, _ := NewPalettedImage(16, 16, nil)
+	theImages.remove(pimg)
+	r.add(pimg)
+	imgs = append(imgs, pimg)
+
+	if got := len(r.list()); got != len(imgs) {
+		t.Fatalf("len(list()): got: %d, want: %d", got, len(imgs))
+	}
+	r.disposeAll()
+	for i, img := range imgs {
+		if !img.isDisposed() {
+			t.Errorf("imgs[%d] must be disposed", i)
+		}
+		// Dispose after disposeAll must be safe.
+		_ = img.Dispose()
+	}
+	if !pimg.palette.isDisposed() {
+		t.Errorf("the palette must be disposed")
+	}
+	if got := len(r.list()); got != 0 {
+		t.Errorf("len(list()): got: %d, want: 0", got)
+	}
+}
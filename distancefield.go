@@ -0,0 +1,117 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/internal/graphics"
+)
+
+// DistanceFieldOptions represents options to render a signed distance field image at DrawImageWithDistanceField.
+type DistanceFieldOptions struct {
+	// Spread is the range of the distances in the source image's pixels.
+	// The alpha value a of a pixel in [0, 1] represents the signed distance (a - 0.5) × Spread from the edge of the shape,
+	// positive inside and negative outside.
+	Spread float64
+
+	// OutlineWidth is the width of the outline around the shape in the source image's pixels.
+	// 0 means no outline.
+	// The outline and the glow must fit in the distances that the image represents, i.e., Spread / 2.
+	OutlineWidth float64
+
+	// OutlineColor is the color of the outline.
+	OutlineColor color.Color
+
+	// GlowWidth is the width of the glow fading out around the outline in the source image's pixels.
+	// 0 means no glow.
+	GlowWidth float64
+
+	// GlowColor is the color of the glow.
+	GlowColor color.Color
+}
+
+func colorToNonPremultipliedFloats(clr color.Color) [4]float32 {
+	if clr == nil {
+		return [4]float32{}
+	}
+	r, g, b, a := clr.RGBA()
+	if a == 0 {
+		return [4]float32{}
+	}
+	return [4]float32{float32(r) / float32(a), float32(g) / float32(a), float32(b) / float32(a), float32(a) / 0xffff}
+}
+
+// DrawImageWithDistanceField draws the given image, a signed distance field, on the image i with options.
+//
+// A distance field image keeps the edges of the shape crisp at any scale, which is useful for large or freely
+// scaled texts and icons. The shape is filled with white, which can be tinted by ColorM, and the outline and the glow
+// are drawn behind the shape. Only the alpha values of img are used.
+//
+// The image is always drawn with the linear filter, and the Filter of options is ignored.
+// The successive drawings with the same distance field parameters and the same scale are merged as DrawImage.
+//
+// When the image i is disposed, DrawImageWithDistanceField does nothing.
+// When the given image img is disposed, DrawImageWithDistanceField panics.
+// When img is a paletted image, DrawImageWithDistanceField panics.
+//
+// DrawImageWithDistanceField always returns nil.
+func (i *Image) DrawImageWithDistanceField(img *Image, options *DrawImageOptions, distanceField *DistanceFieldOptions) error {
+	i.copyCheck()
+	if img.isDisposed() {
+		panic("ebiten: the given image to DrawImageWithDistanceField must not be disposed")
+	}
+	if i.isDisposed() {
+		return nil
+	}
+	if i.Format() == PixelFormatAlpha8 {
+		panic("ebiten: an image of PixelFormatAlpha8 can't be a render target")
+	}
+	if i.Format().isCompressed() {
+		panic("ebiten: an image of a compressed format can't be a render target")
+	}
+	if img.palette != nil {
+		panic("ebiten: a paletted image can't be drawn as a distance field")
+	}
+	if img == i {
+		panic("ebiten: the given image to DrawImageWithDistanceField must be different from the receiver")
+	}
+
+	if options == nil {
+		options = &DrawImageOptions{}
+	}
+	q, st, ok := i.quadAndState(img, options)
+	if !ok {
+		return nil
+	}
+	if _, _, ok := st.colorm.Tint(); ok {
+		q.Tint, st.colorm = st.colorm, nil
+	}
+
+	// The scale is the square root of the area ratio, which is exact for uniform scaling.
+	a, b, c, d := options.GeoM.Element(0, 0), options.GeoM.Element(0, 1), options.GeoM.Element(1, 0), options.GeoM.Element(1, 1)
+	df := &graphics.DistanceField{
+		Spread:       float32(distanceField.Spread),
+		Scale:        float32(math.Sqrt(math.Abs(a*d - b*c))),
+		OutlineWidth: float32(distanceField.OutlineWidth),
+		GlowWidth:    float32(distanceField.GlowWidth),
+		OutlineColor: colorToNonPremultipliedFloats(distanceField.OutlineColor),
+		GlowColor:    colorToNonPremultipliedFloats(distanceField.GlowColor),
+	}
+	i.modified = true
+	i.shareableImage.DrawImagesWithDistanceField(img.shareableImage, df, []graphics.Quad{q}, st.colorm, st.mode, st.clip, st.disabled, st.address)
+	return nil
+}
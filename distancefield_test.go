@@ -0,0 +1,79 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image/color"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten"
+)
+
+func TestDrawImageWithDistanceField(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	cases := []struct {
+		Name    string
+		Alpha   uint8
+		Options *DistanceFieldOptions
+		Want    color.RGBA
+	}{
+		{
+			Name:    "inside",
+			Alpha:   0xff,
+			Options: &DistanceFieldOptions{Spread: 8},
+			Want:    color.RGBA{0xff, 0xff, 0xff, 0xff},
+		},
+		{
+			Name:    "outside",
+			Alpha:   0x60,
+			Options: &DistanceFieldOptions{Spread: 8},
+			Want:    color.RGBA{},
+		},
+		{
+			Name:    "outline",
+			Alpha:   0x60,
+			Options: &DistanceFieldOptions{Spread: 8, OutlineWidth: 2, OutlineColor: red},
+			Want:    red,
+		},
+		{
+			Name:    "out of outline",
+			Alpha:   0x20,
+			Options: &DistanceFieldOptions{Spread: 8, OutlineWidth: 1, OutlineColor: red},
+			Want:    color.RGBA{},
+		},
+	}
+	for _, c := range cases {
+		src, _ := NewImage(4, 4, FilterDefault)
+		src.Fill(color.Alpha{c.Alpha})
+		dst, _ := NewImage(4, 4, FilterDefault)
+		dst.DrawImageWithDistanceField(src, nil, c.Options)
+		got := dst.At(1, 1).(color.RGBA)
+		if !sameColors(got, c.Want, 2) {
+			t.Errorf("%s: dst.At(1, 1): got: %v, want: %v", c.Name, got, c.Want)
+		}
+	}
+}
+
+func TestDrawImageWithDistanceFieldColorM(t *testing.T) {
+	src, _ := NewImage(4, 4, FilterDefault)
+	src.Fill(color.White)
+	dst, _ := NewImage(4, 4, FilterDefault)
+	op := &DrawImageOptions{}
+	op.ColorM.Scale(0, 1, 0, 1)
+	dst.DrawImageWithDistanceField(src, op, &DistanceFieldOptions{Spread: 8})
+	if got, want := dst.At(1, 1).(color.RGBA), (color.RGBA{0, 0xff, 0, 0xff}); !sameColors(got, want, 2) {
+		t.Errorf("dst.At(1, 1): got: %v, want: %v", got, want)
+	}
+}
@@ -37,9 +37,48 @@
 //         ebiten.Run(update, 320, 240, 2, "Your game's title")
 //     }
 //
+// Alternatively, you can implement the Game interface and call RunGame.
+// With RunGame, the logical update and the rendering are separated
+// and Draw is not called when the rendering result would not be adopted.
+//
+//     type Game struct{}
+//
+//     func (g *Game) Update() error {
+//         // Write your game's logical update.
+//         return nil
+//     }
+//
+//     func (g *Game) Draw(screen *ebiten.Image) {
+//         // Write your game's rendering.
+//     }
+//
+//     func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+//         return 320, 240
+//     }
+//
+//     func main() {
+//         ebiten.SetWindowTitle("Your game's title")
+//         ebiten.RunGame(&Game{})
+//     }
+//
+// On Linux, the build tag ebitenheadless enables the headless mode.
+// In the headless mode, no window is created and the game is rendered to an offscreen
+// framebuffer with an EGL surfaceless context. This is useful to run tests
+// or to render images on servers without any displays. For example:
+//
+//     go test -tags ebitenheadless ./...
+//
+// On desktops, the build tag ebitenexternal enables the external mode.
+// In the external mode, no window is created and the host application drives the game from its own main loop
+// with its own OpenGL context. See the github.com/hajimehoshi/ebiten/external package.
+//
 // The EBITEN_SCREENSHOT_KEY environment variable specified the key
 // to take a screenshot. For example, if you run your game with
 // `EBITEN_SCREENSHOT_KEY=q`, you can take a game screen's screenshot
 // by pressing Q key. The image file is saved at the current directory
 // with the name screen*.png.
+//
+// The EBITEN_FORCE_RESTORING environment variable enables restoring images
+// on desktops, where the graphics context is never lost. With this, you can test that
+// your game is rendered correctly after restoring by SimulateContextLoss.
 package ebiten
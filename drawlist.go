@@ -0,0 +1,189 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"image"
+	"sort"
+)
+
+// DrawList is a retained list of drawings that are sorted by their z-indices and drawn at once.
+//
+// DrawList is useful when the order of the drawing calls in the game code doesn't match with the order in which
+// the things should be rendered, e.g. when game objects of different layers are updated in an arbitrary order.
+// The drawings are submitted by Draw and rendered by Flush.
+//
+// The drawings of the same z-index are grouped by the textures of their source images,
+// so that the drawings are batched efficiently.
+// Note that this changes the order of the drawings of the same z-index: the drawings of the same z-index
+// should not overlap, or PreserveOrder should be true.
+//
+// The zero value is an empty DrawList ready to use.
+type DrawList struct {
+	// PreserveOrder indicates whether the drawings of the same z-index are rendered in the submission order.
+	// If PreserveOrder is true, the drawings are not grouped by the textures.
+	PreserveOrder bool
+
+	entries []drawListEntry
+	ops     []DrawImageOptions
+}
+
+type drawListEntry struct {
+	img *Image
+	op  DrawImageOptions
+	z   float64
+
+	// group is the index of the texture group in the same z-index.
+	group int
+
+	// order is the submission order.
+	order int
+
+	// sourceRect and clipRect hold the copies of the regions, as DrawImage copies them.
+	sourceRect image.Rectangle
+	clipRect   image.Rectangle
+}
+
+// Draw submits a drawing of img with options at the z-index z. The drawings are rendered at Flush.
+// The drawings of greater z-indices are rendered in front of the drawings of smaller z-indices.
+//
+// Draw copies options, so modifying options after Draw doesn't affect the drawing.
+//
+// When the given image img is disposed, Draw panics.
+func (d *DrawList) Draw(img *Image, options *DrawImageOptions, z float64) {
+	if img.isDisposed() {
+		panic("ebiten: the given image to Draw must not be disposed")
+	}
+	if options == nil {
+		options = &DrawImageOptions{}
+	}
+	// Parts and ImageParts are deprecated. They are expanded here since ImageParts might be changed until Flush.
+	if options.ImageParts != nil || options.Parts != nil {
+		for _, op := range partOptions(options) {
+			d.add(img, &op, z)
+		}
+		return
+	}
+	d.add(img, options, z)
+}
+
+func (d *DrawList) add(img *Image, options *DrawImageOptions, z float64) {
+	e := drawListEntry{
+		img:   img,
+		op:    *options,
+		z:     z,
+		order: len(d.entries),
+	}
+	if r := options.SourceRect; r != nil {
+		e.sourceRect = *r
+	}
+	if r := options.ClipRect; r != nil {
+		e.clipRect = *r
+	}
+	d.entries = append(d.entries, e)
+}
+
+// Len returns the number of the submitted drawings.
+func (d *DrawList) Len() int {
+	return len(d.entries)
+}
+
+// Reset discards the submitted drawings.
+func (d *DrawList) Reset() {
+	for i := range d.entries {
+		// Don't keep the references to the images.
+		d.entries[i] = drawListEntry{}
+	}
+	d.entries = d.entries[:0]
+}
+
+// Flush renders the submitted drawings on dst in the order of their z-indices, and discards them.
+//
+// When dst is disposed, Flush discards the drawings without rendering.
+// When a submitted image is disposed before Flush, Flush panics.
+//
+// Flush always returns nil.
+func (d *DrawList) Flush(dst *Image) error {
+	defer d.Reset()
+
+	for i := range d.entries {
+		if d.entries[i].img.isDisposed() {
+			panic("ebiten: the image submitted to DrawList must not be disposed until Flush")
+		}
+	}
+
+	if !d.PreserveOrder {
+		d.assignGroups()
+	}
+	sort.Slice(d.entries, func(a, b int) bool {
+		ea, eb := &d.entries[a], &d.entries[b]
+		if ea.z != eb.z {
+			return ea.z < eb.z
+		}
+		if ea.group != eb.group {
+			return ea.group < eb.group
+		}
+		return ea.order < eb.order
+	})
+
+	// Draw the successive drawings of the same image at once by DrawImages.
+	for start := 0; start < len(d.entries); {
+		img := d.entries[start].img
+		end := start + 1
+		for end < len(d.entries) && d.entries[end].img == img {
+			end++
+		}
+		d.ops = d.ops[:0]
+		for i := start; i < end; i++ {
+			e := &d.entries[i]
+			d.ops = append(d.ops, e.op)
+			op := &d.ops[len(d.ops)-1]
+			if op.SourceRect != nil {
+				op.SourceRect = &e.sourceRect
+			}
+			if op.ClipRect != nil {
+				op.ClipRect = &e.clipRect
+			}
+		}
+		_ = dst.DrawImages(img, d.ops)
+		start = end
+	}
+
+	for i := range d.ops {
+		d.ops[i] = DrawImageOptions{}
+	}
+	d.ops = d.ops[:0]
+	return nil
+}
+
+// assignGroups assigns the texture groups to the entries.
+// The groups are numbered in the order of the first appearances of the textures in each z-index.
+func (d *DrawList) assignGroups() {
+	type key struct {
+		z       float64
+		texture interface{}
+	}
+	groups := map[key]int{}
+	for i := range d.entries {
+		e := &d.entries[i]
+		k := key{e.z, e.img.shareableImage.TextureKey()}
+		g, ok := groups[k]
+		if !ok {
+			g = len(groups)
+			groups[k] = g
+		}
+		e.group = g
+	}
+}
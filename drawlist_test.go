@@ -0,0 +1,92 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten"
+)
+
+func TestDrawListZ(t *testing.T) {
+	red, _ := NewImage(4, 4, FilterDefault)
+	red.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	blue, _ := NewImage(4, 4, FilterDefault)
+	blue.Fill(color.RGBA{0, 0, 0xff, 0xff})
+	dst, _ := NewImage(8, 4, FilterDefault)
+
+	var l DrawList
+	op := &DrawImageOptions{}
+	l.Draw(red, op, 1)
+	l.Draw(blue, op, 0)
+	op.GeoM.Translate(4, 0)
+	l.Draw(blue, op, 1)
+	l.Draw(red, op, 0)
+	// Modifying the options after Draw doesn't affect the drawing.
+	op.GeoM.Translate(100, 0)
+
+	if got, want := l.Len(), 4; got != want {
+		t.Errorf("Len(): got: %d, want: %d", got, want)
+	}
+	l.Flush(dst)
+	if got, want := l.Len(), 0; got != want {
+		t.Errorf("Len() after Flush: got: %d, want: %d", got, want)
+	}
+
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 8; i++ {
+			want := color.RGBA{0xff, 0, 0, 0xff}
+			if i >= 4 {
+				want = color.RGBA{0, 0, 0xff, 0xff}
+			}
+			if got := dst.At(i, j); got != want {
+				t.Errorf("At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestDrawListPreserveOrder(t *testing.T) {
+	src, _ := NewImage(4, 4, FilterDefault)
+	src.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	// A volatile image has its own texture.
+	other, _ := NewVolatileImage(4, 4, FilterDefault)
+	other.Fill(color.RGBA{0, 0xff, 0, 0xff})
+	dst, _ := NewImage(4, 4, FilterDefault)
+
+	for _, preserve := range []bool{false, true} {
+		dst.Clear()
+
+		l := DrawList{PreserveOrder: preserve}
+		l.Draw(src, nil, 0)
+		l.Draw(other, nil, 0)
+		r := image.Rect(0, 0, 4, 4)
+		l.Draw(src, &DrawImageOptions{SourceRect: &r}, 0)
+		// Modifying the region after Draw doesn't affect the drawing.
+		r.Max.X = 0
+		l.Flush(dst)
+
+		// Without PreserveOrder, the drawings of src are grouped and other is drawn on them.
+		want := color.RGBA{0xff, 0, 0, 0xff}
+		if !preserve {
+			want = color.RGBA{0, 0xff, 0, 0xff}
+		}
+		if got := dst.At(0, 0); got != want {
+			t.Errorf("PreserveOrder: %v: At(0, 0): got: %v, want: %v", preserve, got, want)
+		}
+	}
+}
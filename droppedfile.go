@@ -0,0 +1,85 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/internal/input"
+)
+
+// DroppedFile represents a file dropped onto the window.
+type DroppedFile struct {
+	file *input.DroppedFile
+}
+
+// Name returns the base name of the file.
+func (f *DroppedFile) Name() string {
+	return f.file.Name
+}
+
+// Path returns the absolute path of the file on desktops.
+//
+// Path returns an empty string on browsers, where the path of the file is not available.
+func (f *DroppedFile) Path() string {
+	return f.file.Path
+}
+
+// Open opens the file to read its content.
+//
+// On desktops, Open opens the file on the file system. As the file might be moved or removed after dropping,
+// Open can return error.
+// On browsers, the content is already read by the File API and Open never returns error.
+func (f *DroppedFile) Open() (io.ReadCloser, error) {
+	if f.file.Data != nil {
+		return ioutil.NopCloser(bytes.NewReader(f.file.Data)), nil
+	}
+	return os.Open(f.file.Path)
+}
+
+// DroppedFiles returns the files dropped onto the window at the time update is called.
+//
+// e.g. the following code loads an image by dropping a PNG file:
+//
+//     for _, f := range ebiten.DroppedFiles() {
+//         r, err := f.Open()
+//         if err != nil {
+//             return err
+//         }
+//         img, _, err := image.Decode(r)
+//         r.Close()
+//         ...
+//     }
+//
+// On browsers, a dropped file is available after its content is read asynchronously,
+// i.e. some frames after the file is dropped.
+//
+// DroppedFiles always returns nil on mobiles, in headless mode and in external mode.
+//
+// This function is concurrent-safe.
+func DroppedFiles() []*DroppedFile {
+	fs := input.Get().DroppedFiles()
+	if len(fs) == 0 {
+		return nil
+	}
+	r := make([]*DroppedFile, 0, len(fs))
+	for _, f := range fs {
+		r = append(r, &DroppedFile{f})
+	}
+	return r
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/input"
+)
+
+func TestDroppedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ebiten")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "foo.png")
+	if err := ioutil.WriteFile(path, []byte("file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := DroppedFiles(); got != nil {
+		t.Errorf("DroppedFiles(): got: %v, want: nil", got)
+	}
+
+	input.Get().AppendDroppedFiles(&input.DroppedFile{
+		Name: "foo.png",
+		Path: path,
+	}, &input.DroppedFile{
+		Name: "bar.png",
+		Data: []byte("data"),
+	})
+	defer input.Get().ClearDroppedFiles()
+
+	fs := DroppedFiles()
+	if got, want := len(fs), 2; got != want {
+		t.Fatalf("len(DroppedFiles()): got: %d, want: %d", got, want)
+	}
+
+	cases := []struct {
+		Name string
+		Path string
+		Data string
+	}{
+		{
+			Name: "foo.png",
+			Path: path,
+			Data: "file",
+		},
+		{
+			Name: "bar.png",
+			Path: "",
+			Data: "data",
+		},
+	}
+	for i, c := range cases {
+		f := fs[i]
+		if got := f.Name(); got != c.Name {
+			t.Errorf("Name(): got: %s, want: %s", got, c.Name)
+		}
+		if got := f.Path(); got != c.Path {
+			t.Errorf("Path(): got: %s, want: %s", got, c.Path)
+		}
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		bs, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(bs); got != c.Data {
+			t.Errorf("content: got: %s, want: %s", got, c.Data)
+		}
+	}
+
+	input.Get().ClearDroppedFiles()
+	if got := DroppedFiles(); got != nil {
+		t.Errorf("DroppedFiles() after clearing: got: %v, want: nil", got)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ebitentest provides utilities to test rendering of Ebiten games without a display.
+//
+// With Init, Ebiten renders images with a software graphics driver on CPU instead of the GPU,
+// and the results can be compared with golden images by CheckGolden:
+//
+//     func TestMain(m *testing.M) {
+//         if err := ebitentest.Init(); err != nil {
+//             panic(err)
+//         }
+//         os.Exit(m.Run())
+//     }
+//
+//     func TestDrawPlayer(t *testing.T) {
+//         img, _ := ebiten.NewImage(64, 64, ebiten.FilterDefault)
+//         drawPlayer(img)
+//         ebitentest.CheckGolden(t, img, "testdata/player.png", 1)
+//     }
+//
+// To create or update the golden images, run the tests with the environment variable EBITEN_UPDATE_GOLDEN=1.
+package ebitentest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/internal/graphics"
+	"github.com/hajimehoshi/ebiten/internal/shareable"
+	"github.com/hajimehoshi/ebiten/internal/software"
+)
+
+// The software driver lives outside the graphics package so that the graphics package doesn't depend on it.
+var _ graphics.Driver = (*software.Driver)(nil)
+
+var (
+	initOnce sync.Once
+	initErr  error
+)
+
+// Init initializes Ebiten to render images with a software graphics driver on CPU.
+//
+// After Init, images can be drawn and their pixels can be read by At without the main loop,
+// so that rendering can be tested without a display and without a GPU. Init is usually called at TestMain.
+//
+// The software driver follows the OpenGL driver, but the results might be slightly different from GPUs'.
+// The screen is not available: ebiten.Run must not be called after Init.
+//
+// Init must be called before any images are drawn. Calling Init more than once does nothing.
+func Init() error {
+	initOnce.Do(func() {
+		graphics.SetDriver(software.NewDriver())
+		initErr = shareable.InitializeGLState()
+	})
+	return initErr
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// CompareImages compares the pixels of got and want, and returns an error describing the differences if any.
+//
+// The pixels are compared as premultiplied 8-bit RGBA colors at the same positions relative to the bounds.
+// Two colors are considered the same when the differences of all the components are tolerance or less.
+func CompareImages(got, want image.Image, tolerance int) error {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Dx() != wb.Dx() || gb.Dy() != wb.Dy() {
+		return fmt.Errorf("ebitentest: the sizes differ: got: %dx%d, want: %dx%d", gb.Dx(), gb.Dy(), wb.Dx(), wb.Dy())
+	}
+
+	n := 0
+	var first string
+	for j := 0; j < gb.Dy(); j++ {
+		for i := 0; i < gb.Dx(); i++ {
+			g := color.RGBAModel.Convert(got.At(gb.Min.X+i, gb.Min.Y+j)).(color.RGBA)
+			w := color.RGBAModel.Convert(want.At(wb.Min.X+i, wb.Min.Y+j)).(color.RGBA)
+			if abs(int(g.R)-int(w.R)) <= tolerance &&
+				abs(int(g.G)-int(w.G)) <= tolerance &&
+				abs(int(g.B)-int(w.B)) <= tolerance &&
+				abs(int(g.A)-int(w.A)) <= tolerance {
+				continue
+			}
+			if n == 0 {
+				first = fmt.Sprintf("(%d, %d): got: %v, want: %v", i, j, g, w)
+			}
+			n++
+		}
+	}
+	if n > 0 {
+		return fmt.Errorf("ebitentest: %d pixels differ with tolerance %d, the first one at %s", n, tolerance, first)
+	}
+	return nil
+}
+
+// goldenUpdated reports whether the golden images should be updated.
+func goldenUpdated() bool {
+	return os.Getenv("EBITEN_UPDATE_GOLDEN") == "1"
+}
+
+// CheckGolden compares got with the golden PNG image at path by CompareImages, and reports an error to t
+// if they differ or the golden image can't be read.
+//
+// On failure, got is saved next to the golden image with the suffix .got.png, e.g. testdata/player.got.png
+// for testdata/player.png, so that the result can be inspected.
+//
+// If the environment variable EBITEN_UPDATE_GOLDEN is 1, CheckGolden writes got to path as the new golden image
+// instead of comparing.
+func CheckGolden(t testing.TB, got image.Image, path string, tolerance int) {
+	t.Helper()
+
+	if goldenUpdated() {
+		if err := savePNG(path, got); err != nil {
+			t.Errorf("ebitentest: updating the golden image failed: %v", err)
+		}
+		return
+	}
+
+	err := compareWithGolden(got, path, tolerance)
+	if err == nil {
+		return
+	}
+	gotPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".got.png"
+	if err := savePNG(gotPath, got); err != nil {
+		t.Errorf("ebitentest: saving the result failed: %v", err)
+	}
+	t.Errorf("%s: %v (the result is saved at %s)", path, err, gotPath)
+}
+
+func compareWithGolden(got image.Image, path string, tolerance int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	want, err := png.Decode(f)
+	if err != nil {
+		return err
+	}
+	return CompareImages(got, want, tolerance)
+}
+
+func savePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
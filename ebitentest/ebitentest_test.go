@@ -0,0 +1,152 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebitentest_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	. "github.com/hajimehoshi/ebiten/ebitentest"
+)
+
+func TestMain(m *testing.M) {
+	if err := Init(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestDrawImage(t *testing.T) {
+	src, _ := ebiten.NewImage(4, 4, ebiten.FilterDefault)
+	src.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	dst, _ := ebiten.NewImage(8, 8, ebiten.FilterDefault)
+	dst.Fill(color.RGBA{0, 0, 0xff, 0xff})
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(1.5, 1)
+	op.GeoM.Translate(1, 2)
+	op.ColorM.Scale(1, 1, 1, 0.5)
+	dst.DrawImage(src, op)
+
+	want := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for j := 0; j < 8; j++ {
+		for i := 0; i < 8; i++ {
+			c := color.RGBA{0, 0, 0xff, 0xff}
+			if 1 <= i && i < 7 && 2 <= j && j < 6 {
+				c = color.RGBA{0x80, 0, 0x7f, 0xff}
+			}
+			want.Set(i, j, c)
+		}
+	}
+	if err := CompareImages(dst, want, 1); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDrawImageCompositeMode(t *testing.T) {
+	src, _ := ebiten.NewImage(2, 2, ebiten.FilterDefault)
+	src.Fill(color.RGBA{0x40, 0x40, 0, 0x80})
+	dst, _ := ebiten.NewImage(2, 2, ebiten.FilterDefault)
+	dst.Fill(color.RGBA{0x40, 0, 0x40, 0x80})
+
+	op := &ebiten.DrawImageOptions{}
+	op.CompositeMode = ebiten.CompositeModeLighter
+	dst.DrawImage(src, op)
+
+	want := image.NewUniform(color.RGBA{0x80, 0x40, 0x40, 0xff})
+	if err := CompareImages(dst, want, 1); err == nil {
+		t.Errorf("CompareImages must return an error for an image of a different size")
+	}
+	if err := CompareImages(dst, &image.RGBA{}, 0); err == nil {
+		t.Errorf("CompareImages must return an error for an image of a different size")
+	}
+	wantImg := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	for j := 0; j < 2; j++ {
+		for i := 0; i < 2; i++ {
+			wantImg.Set(i, j, want.C)
+		}
+	}
+	if err := CompareImages(dst, wantImg, 1); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCompareImages(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	b := image.NewRGBA(image.Rect(10, 10, 12, 12))
+	b.Set(11, 10, color.RGBA{2, 0, 0, 2})
+	if err := CompareImages(a, b, 2); err != nil {
+		t.Error(err)
+	}
+	if err := CompareImages(a, b, 1); err == nil {
+		t.Errorf("CompareImages must return an error when the difference exceeds the tolerance")
+	}
+}
+
+// failureRecorder is a testing.TB that records failures instead of failing the test.
+type failureRecorder struct {
+	testing.TB
+	failures []string
+}
+
+func (f *failureRecorder) Helper() {
+}
+
+func (f *failureRecorder) Errorf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func TestCheckGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ebitentest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "golden.png")
+
+	img, _ := ebiten.NewImage(4, 4, ebiten.FilterDefault)
+	img.Fill(color.RGBA{0x10, 0x20, 0x30, 0xff})
+
+	// A missing golden image is a failure.
+	r := &failureRecorder{TB: t}
+	CheckGolden(r, img, path, 0)
+	if len(r.failures) != 1 {
+		t.Errorf("failures for a missing golden image: got: %d, want: 1", len(r.failures))
+	}
+
+	os.Setenv("EBITEN_UPDATE_GOLDEN", "1")
+	CheckGolden(t, img, path, 0)
+	os.Unsetenv("EBITEN_UPDATE_GOLDEN")
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("the golden image must be written: %v", err)
+	}
+	CheckGolden(t, img, path, 0)
+
+	img.Fill(color.RGBA{0x10, 0x20, 0x40, 0xff})
+	r = &failureRecorder{TB: t}
+	CheckGolden(r, img, path, 0)
+	if len(r.failures) != 1 {
+		t.Errorf("failures for a different image: got: %d, want: 1", len(r.failures))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "golden.got.png")); err != nil {
+		t.Errorf("the result must be saved on failure: %v", err)
+	}
+}
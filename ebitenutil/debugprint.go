@@ -18,7 +18,7 @@ import (
 	"image"
 
 	"github.com/hajimehoshi/ebiten"
-	"github.com/hajimehoshi/ebiten/ebitenutil/internal/assets"
+	"github.com/hajimehoshi/ebiten/internal/assets"
 )
 
 var (
@@ -53,8 +53,17 @@ func init() {
 //
 // DebugPrint always returns nil as of 1.5.0-alpha.
 func DebugPrint(image *ebiten.Image, str string) error {
-	drawDebugText(image, str, 1, 1, debugPrintTextShadowImage)
-	drawDebugText(image, str, 0, 0, debugPrintTextImage)
+	return DebugPrintAt(image, str, 0, 0)
+}
+
+// DebugPrintAt draws the string str on the image at (x, y), the upper-left corner of the text.
+//
+// The available runes are the same as DebugPrint.
+//
+// DebugPrintAt always returns nil.
+func DebugPrintAt(image *ebiten.Image, str string, x, y int) error {
+	drawDebugText(image, str, x+1, y+1, debugPrintTextShadowImage)
+	drawDebugText(image, str, x, y, debugPrintTextImage)
 	return nil
 }
 
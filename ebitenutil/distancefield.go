@@ -0,0 +1,36 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebitenutil
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/distancefield"
+)
+
+// NewDistanceFieldImage generates a signed distance field of the shape of img and returns it as an ebiten.Image
+// to be drawn with (*ebiten.Image).DrawImageWithDistanceField.
+//
+// A pixel of img is inside the shape when its alpha value is half or more.
+// spread is the range of the distances in pixels, which should be passed as DistanceFieldOptions.Spread.
+// The returned image is larger than img by spread / 2 on each side, i.e., the upper-left corner of img is at
+// (spread / 2, spread / 2) in the returned image.
+//
+// The generation is done on CPU and is slow for large images. Generate distance fields at loading time,
+// e.g. from a high-resolution image scaled down afterwards.
+func NewDistanceFieldImage(img image.Image, spread int) (*ebiten.Image, error) {
+	return ebiten.NewImageFromImage(distancefield.Generate(img, spread), ebiten.FilterLinear)
+}
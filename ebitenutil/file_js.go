@@ -21,7 +21,7 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/gopherjs/gopherjs/js"
+	"github.com/hajimehoshi/ebiten/internal/js"
 )
 
 type file struct {
@@ -32,33 +32,47 @@ func (f *file) Close() error {
 	return nil
 }
 
+// OpenFile fetches a file via HTTP and returns a stream for its data.
+//
+// path is resolved relatively to the page's URL. OpenFile blocks until the whole content is fetched.
 func OpenFile(path string) (ReadSeekCloser, error) {
 	var err error
-	var content *js.Object
+	var content js.Value
 	ch := make(chan struct{})
-	req := js.Global.Get("XMLHttpRequest").New()
+	req := js.Global().Get("XMLHttpRequest").New()
 	req.Call("open", "GET", path, true)
 	req.Set("responseType", "arraybuffer")
-	req.Call("addEventListener", "load", func() {
+
+	loadf := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		defer close(ch)
 		status := req.Get("status").Int()
 		if 200 <= status && status < 400 {
 			content = req.Get("response")
-			return
+			return nil
 		}
 		err = errors.New(fmt.Sprintf("http error: %d", status))
+		return nil
 	})
-	req.Call("addEventListener", "error", func() {
+	defer loadf.Release()
+	req.Call("addEventListener", "load", loadf)
+
+	errorf := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		defer close(ch)
 		err = errors.New(fmt.Sprintf("XMLHttpRequest error: %s", req.Get("statusText").String()))
+		return nil
 	})
+	defer errorf.Release()
+	req.Call("addEventListener", "error", errorf)
+
 	req.Call("send")
 	<-ch
 	if err != nil {
 		return nil, err
 	}
 
-	data := js.Global.Get("Uint8Array").New(content).Interface().([]uint8)
+	uint8Array := js.Global().Get("Uint8Array").New(content)
+	data := make([]byte, uint8Array.Get("byteLength").Int())
+	js.CopyBytesToGo(data, uint8Array)
 	f := &file{bytes.NewReader(data)}
 	return f, nil
 }
@@ -0,0 +1,41 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebitenutil
+
+import (
+	"image"
+	"io"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// NewImageFromReader decodes an image from r and returns ebiten.Image and image.Image.
+//
+// Image decoders must be imported when using this function. For example,
+// if you want to load a PNG image, you'd need to add `_ "image/png"` to the import section.
+//
+// Unlike NewImageFromFile, this works on any environments including mobiles,
+// e.g. with a reader of embedded resources.
+func NewImageFromReader(r io.Reader, filter ebiten.Filter) (*ebiten.Image, image.Image, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	img2, err := ebiten.NewImageFromImage(img, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return img2, img, nil
+}
@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// +build darwin freebsd linux windows
+// +build darwin freebsd js linux windows
 // +build !android
 // +build !ios
 
@@ -29,7 +29,8 @@ import (
 // Image decoders must be imported when using this function. For example,
 // if you want to load a PNG image, you'd need to add `_ "image/png"` to the import section.
 //
-// How to solve path depends on your environment. This varies on your desktop or web browser.
+// How to solve path depends on your environment. This varies on your desktop or web browser:
+// on browsers, path is fetched via HTTP relatively to the page. See also OpenFile.
 // Note that this doesn't work on mobiles.
 //
 // For productions, instead of using this function, it is safer to embed your resources, e.g., with github.com/jteeuwen/go-bindata .
@@ -41,13 +42,5 @@ func NewImageFromFile(path string, filter ebiten.Filter) (*ebiten.Image, image.I
 	defer func() {
 		_ = file.Close()
 	}()
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return nil, nil, err
-	}
-	img2, err := ebiten.NewImageFromImage(img, filter)
-	if err != nil {
-		return nil, nil, err
-	}
-	return img2, img, err
+	return NewImageFromReader(file, filter)
 }
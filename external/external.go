@@ -0,0 +1,92 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package external provides functions to drive a game from a main loop owned by the host application,
+// e.g. when embedding a game into an existing Cocoa or Win32 application or into another engine.
+//
+// This package works only with the build tag ebitenexternal on desktops. With the build tag, Ebiten doesn't
+// create any windows nor OpenGL contexts. Instead, the host application creates an OpenGL context
+// (OpenGL 2.1 or later, or a compatibility profile), makes it current and calls Step on every frame.
+//
+//     go build -tags ebitenexternal
+//
+// Ebiten changes the OpenGL state like the current program and the bound framebuffer in Step.
+// The host application must restore its own state after Step as needed.
+// Before Step, the host application must unbind its vertex array object and its pixel unpack buffer if any.
+//
+// In external mode, Ebiten doesn't handle any input devices, and e.g. ebiten.IsKeyPressed always returns false.
+// The host application is responsible to pass the input to the game.
+//
+// Note: This package is experimental and API might be changed.
+package external
+
+import (
+	"github.com/hajimehoshi/ebiten"
+)
+
+// Start starts the game and returns immediately.
+//
+// Different from ebiten.Run, this invokes only the game loop and not the main (UI) loop.
+// The game is updated and rendered only in Step.
+//
+// The screen is rendered to the host's framebuffer in the size of width*scale x height*scale pixels.
+// The screen size can be changed later by ebiten.SetScreenSize and ebiten.SetScreenScale.
+//
+// Start returns error when the build tag ebitenexternal is not specified.
+func Start(f func(*ebiten.Image) error, width, height int, scale float64) error {
+	return start(f, width, height, scale)
+}
+
+// Step updates and renders the game by one frame.
+//
+// Step must be called on every frame on the thread where the host's OpenGL context is current.
+// The game is rendered to the framebuffer specified by SetFramebuffer.
+//
+// Step returns error when 1) OpenGL error happens, or 2) f in Start returns error samely as ebiten.Run.
+func Step() error {
+	return step()
+}
+
+// SetFramebuffer sets the host's framebuffer object (FBO) that the game renders to.
+//
+// The default value is 0, which is the default framebuffer of the host's OpenGL context.
+// The framebuffer must be larger than or equal to the screen size in pixels.
+//
+// SetFramebuffer cancels RenderToTexture.
+func SetFramebuffer(framebuffer uint32) {
+	setFramebuffer(framebuffer)
+}
+
+// RenderToTexture makes the game render to a texture owned by Ebiten instead of the host's framebuffer.
+// This is useful to embed the game into a widget of a GUI toolkit, e.g. the viewport of a level editor.
+//
+// The texture is available by Texture after Step.
+func RenderToTexture() {
+	renderToTexture()
+}
+
+// Texture returns the OpenGL texture name that the game is rendered to by RenderToTexture.
+//
+// The texture is in RGBA8 and in the screen size in pixels. As the OpenGL's convention, the first row of the texture is the bottom of the screen.
+// The texture is recreated when the screen size changes. Call Texture after every Step instead of keeping the name.
+// The texture must not be deleted nor modified by the host application.
+//
+// The texture can be used in another OpenGL context sharing objects with the context used in Step.
+// Step flushes the commands at the end, but the host application might need to synchronize the contexts further,
+// e.g. by binding the texture again in the other context.
+//
+// Texture returns 0 before the first Step after RenderToTexture is called, or when RenderToTexture is not called.
+func Texture() uint32 {
+	return texture()
+}
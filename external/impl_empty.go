@@ -0,0 +1,43 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !ebitenexternal js android ios
+
+package external
+
+import (
+	"errors"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+var errNotAvailable = errors.New("external: the build tag ebitenexternal is required on desktops")
+
+func start(f func(*ebiten.Image) error, width, height int, scale float64) error {
+	return errNotAvailable
+}
+
+func step() error {
+	return errNotAvailable
+}
+
+func setFramebuffer(framebuffer uint32) {
+}
+
+func renderToTexture() {
+}
+
+func texture() uint32 {
+	return 0
+}
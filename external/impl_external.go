@@ -0,0 +1,57 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ebitenexternal
+// +build darwin freebsd linux windows
+// +build !js
+// +build !android
+// +build !ios
+
+package external
+
+import (
+	"errors"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+var chError <-chan error
+
+func start(f func(*ebiten.Image) error, width, height int, scale float64) error {
+	if chError != nil {
+		return errors.New("external: Start must not be called twice")
+	}
+	chError = ebiten.RunWithoutMainLoop(f, width, height, scale, "")
+	return nil
+}
+
+func step() error {
+	if chError == nil {
+		return errors.New("external: Start must be called ahead of Step")
+	}
+	return ui.Step(chError)
+}
+
+func setFramebuffer(framebuffer uint32) {
+	ui.SetFramebuffer(framebuffer)
+}
+
+func renderToTexture() {
+	ui.RenderToTexture()
+}
+
+func texture() uint32 {
+	return ui.Texture()
+}
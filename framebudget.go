@@ -0,0 +1,131 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"context"
+	"fmt"
+	"runtime/trace"
+	"sync/atomic"
+	"time"
+)
+
+// FrameStats is the breakdown of the time for a frame.
+type FrameStats struct {
+	// Total is the time from the start of the frame to the start of the next frame.
+	Total time.Duration
+
+	// Update is the time for the update function (or game's Update and Draw) including the hooks,
+	// the post effects and the rendering of the screen.
+	// As the graphics commands are executed later, this is mostly the CPU time of the game.
+	Update time.Duration
+
+	// Flush is the time to execute the graphics commands.
+	Flush time.Duration
+
+	// Swap is the rest of the frame: swapping the buffers, waiting for vsync and processing events.
+	Swap time.Duration
+}
+
+func (s *FrameStats) String() string {
+	return fmt.Sprintf("total: %s (update: %s, flush: %s, swap: %s)", s.Total, s.Update, s.Flush, s.Swap)
+}
+
+type frameBudget struct {
+	budget time.Duration
+	f      func(stats *FrameStats)
+}
+
+// theFrameBudget is the current frame budget (*frameBudget).
+var theFrameBudget atomic.Value
+
+// SetFrameBudget sets the time budget for a frame and the function called when a frame exceeds it.
+//
+// When a frame takes longer than budget, f is called with the breakdown of the time at the start
+// of the next frame, on the same goroutine as the update function. If budget is 0, frames are not checked.
+//
+// Note that a frame takes at least the display's refresh interval (e.g. 16.7ms at 60 Hz) when
+// vsync is enabled, so budget should be longer than that.
+//
+// The update and the command flush of every frame are recorded as runtime/trace regions.
+// When tracing is enabled, a frame exceeding the budget is also logged to the trace, even if f is nil.
+//
+// This function is concurrent-safe.
+func SetFrameBudget(budget time.Duration, f func(stats *FrameStats)) {
+	theFrameBudget.Store(&frameBudget{
+		budget: budget,
+		f:      f,
+	})
+}
+
+func currentFrameBudget() *frameBudget {
+	b, _ := theFrameBudget.Load().(*frameBudget)
+	return b
+}
+
+// frameTimer measures the time for each part of frames.
+type frameTimer struct {
+	// start is the start time of the current frame.
+	start time.Time
+
+	// flushStart is the start time of the command flush in the current frame.
+	flushStart time.Time
+
+	// stats is the stats of the current frame.
+	stats FrameStats
+
+	// region is the trace region in progress.
+	region *trace.Region
+}
+
+// begin starts a new frame and checks the previous frame with the frame budget.
+func (t *frameTimer) begin() {
+	now := time.Now()
+	if !t.start.IsZero() {
+		t.stats.Total = now.Sub(t.start)
+		t.stats.Swap = t.stats.Total - t.stats.Update - t.stats.Flush
+		if b := currentFrameBudget(); b != nil && b.budget > 0 && t.stats.Total > b.budget {
+			if trace.IsEnabled() {
+				trace.Log(context.Background(), "ebiten", "slow frame: "+t.stats.String())
+			}
+			if b.f != nil {
+				stats := t.stats
+				b.f(&stats)
+			}
+		}
+	}
+	if t.region != nil {
+		// The previous frame ended with an error.
+		t.region.End()
+	}
+	t.start = now
+	t.stats = FrameStats{}
+	t.region = trace.StartRegion(context.Background(), "ebiten: update")
+}
+
+// beginFlush is called before the commands are flushed in the current frame.
+func (t *frameTimer) beginFlush() {
+	t.region.End()
+	t.flushStart = time.Now()
+	t.stats.Update = t.flushStart.Sub(t.start)
+	t.region = trace.StartRegion(context.Background(), "ebiten: flush")
+}
+
+// endFlush is called after the commands are flushed in the current frame.
+func (t *frameTimer) endFlush() {
+	t.region.End()
+	t.region = nil
+	t.stats.Flush = time.Since(t.flushStart)
+}
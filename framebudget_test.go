@@ -0,0 +1,65 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrameBudget(t *testing.T) {
+	defer SetFrameBudget(0, nil)
+
+	var got []FrameStats
+	SetFrameBudget(5*time.Millisecond, func(stats *FrameStats) {
+		got = append(got, *stats)
+	})
+
+	timer := &frameTimer{}
+	frame := func(update, flush, swap time.Duration) {
+		time.Sleep(update)
+		timer.beginFlush()
+		time.Sleep(flush)
+		timer.endFlush()
+		time.Sleep(swap)
+		timer.begin()
+	}
+
+	timer.begin()
+	// A fast frame.
+	frame(0, 0, 0)
+	if len(got) != 0 {
+		t.Fatalf("len(got): got: %d, want: 0", len(got))
+	}
+
+	// A slow frame.
+	frame(10*time.Millisecond, 0, 0)
+	if len(got) != 1 {
+		t.Fatalf("len(got): got: %d, want: 1", len(got))
+	}
+	s := got[0]
+	if s.Update < 10*time.Millisecond {
+		t.Errorf("Update: got: %s, want: >= 10ms", s.Update)
+	}
+	if s.Total != s.Update+s.Flush+s.Swap {
+		t.Errorf("Total must be the sum of the parts: %s", s.String())
+	}
+
+	// Frames are not checked without the budget.
+	SetFrameBudget(0, func(stats *FrameStats) {
+		t.Errorf("the function must not be called without the budget")
+	})
+	frame(10*time.Millisecond, 0, 0)
+}
@@ -408,7 +408,9 @@ func main() {
 			buildTag = "// +build darwin freebsd linux windows" +
 				"\n// +build !js" +
 				"\n// +build !android" +
-				"\n// +build !ios"
+				"\n// +build !ios" +
+				"\n// +build !ebitenheadless !linux" +
+				"\n// +build !ebitenexternal"
 		case "internal/input/keys_js.go":
 			buildTag = "// +build js"
 		}
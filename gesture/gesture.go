@@ -0,0 +1,331 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gesture provides a recognizer of touch gestures like tap, double-tap, long-press, pan and pinch.
+//
+// Note: This package is experimental and API might be changed.
+package gesture
+
+import (
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// Kind represents a kind of gestures.
+type Kind int
+
+const (
+	// Tap is a short touch without moving.
+	Tap Kind = iota
+
+	// DoubleTap is the second tap soon after a tap at the same position.
+	// The first tap is reported as Tap.
+	DoubleTap
+
+	// LongPress is a touch held for a while without moving. LongPress is reported once per touch.
+	LongPress
+
+	// Pan is a move of one touch.
+	Pan
+
+	// Pinch is a move of two touches.
+	Pinch
+)
+
+// Phase represents a phase of a continuous gesture, i.e., Pan or Pinch.
+type Phase int
+
+const (
+	PhaseBegan Phase = iota
+	PhaseChanged
+	PhaseEnded
+)
+
+// Gesture represents a recognized gesture.
+type Gesture struct {
+	Kind Kind
+
+	// Phase is the phase of the gesture. Phase is used only for Pan and Pinch.
+	Phase Phase
+
+	// X and Y are the position of the gesture. For Pinch, the position is the center of the two touches.
+	X float64
+	Y float64
+
+	// DX and DY are the move of the position in the current frame for Pan and Pinch.
+	// At PhaseBegan of Pan, the move from the start of the touch is reported.
+	DX float64
+	DY float64
+
+	// Scale is the ratio of the distance between the two touches to the distance at the previous frame for Pinch.
+	// Multiply the scales to get the total scale of a pinch.
+	Scale float64
+}
+
+type touch struct {
+	startX float64
+	startY float64
+	x      float64
+	y      float64
+	prevX  float64
+	prevY  float64
+	ticks  int
+
+	// moved indicates whether the touch moved farther than the slop.
+	moved bool
+
+	// multi indicates whether the touch was a part of a multi-touch.
+	multi bool
+
+	longPressed bool
+}
+
+// Recognizer recognizes gestures from the touches every frame.
+//
+// The zero value of Recognizer is ready to use with the default thresholds.
+type Recognizer struct {
+	// TapDuration is the maximum duration of a tap in ticks. 0 is treated as 15.
+	TapDuration int
+
+	// DoubleTapInterval is the maximum interval between the taps of a double-tap in ticks. 0 is treated as 20.
+	DoubleTapInterval int
+
+	// LongPressDuration is the duration of a long-press in ticks. 0 is treated as 30.
+	LongPressDuration int
+
+	// Slop is the distance in pixels that a touch can move and still be a tap or a long-press.
+	// A touch farther than Slop from the previous tap is not a double-tap either. 0 is treated as 10.
+	Slop float64
+
+	touches  map[int]*touch
+	gestures []Gesture
+	ticks    int
+
+	hasLastTap  bool
+	lastTapTick int
+	lastTapX    float64
+	lastTapY    float64
+
+	panning  bool
+	panID    int
+	pinching bool
+	pinchX   float64
+	pinchY   float64
+}
+
+func (r *Recognizer) tapDuration() int {
+	if r.TapDuration == 0 {
+		return 15
+	}
+	return r.TapDuration
+}
+
+func (r *Recognizer) doubleTapInterval() int {
+	if r.DoubleTapInterval == 0 {
+		return 20
+	}
+	return r.DoubleTapInterval
+}
+
+func (r *Recognizer) longPressDuration() int {
+	if r.LongPressDuration == 0 {
+		return 30
+	}
+	return r.LongPressDuration
+}
+
+func (r *Recognizer) slop() float64 {
+	if r.Slop == 0 {
+		return 10
+	}
+	return r.Slop
+}
+
+// Update updates the recognizer with the current touches of ebiten.Touches.
+//
+// Update should be called once every frame.
+func (r *Recognizer) Update() {
+	r.UpdateWithTouches(ebiten.Touches())
+}
+
+// UpdateWithTouches updates the recognizer with the given touches.
+//
+// This is useful to recognize gestures from touches in other coordinates or from emulated touches.
+func (r *Recognizer) UpdateWithTouches(touches []ebiten.Touch) {
+	r.gestures = r.gestures[:0]
+	r.ticks++
+	if r.touches == nil {
+		r.touches = map[int]*touch{}
+	}
+
+	current := map[int]struct{}{}
+	for _, t := range touches {
+		id := t.ID()
+		ix, iy := t.Position()
+		x, y := float64(ix), float64(iy)
+		current[id] = struct{}{}
+
+		s, ok := r.touches[id]
+		if !ok {
+			s = &touch{
+				startX: x,
+				startY: y,
+				x:      x,
+				y:      y,
+			}
+			r.touches[id] = s
+		} else {
+			s.ticks++
+		}
+		s.prevX, s.prevY = s.x, s.y
+		s.x, s.y = x, y
+		if math.Hypot(x-s.startX, y-s.startY) > r.slop() {
+			s.moved = true
+		}
+		if len(touches) >= 2 {
+			s.multi = true
+		}
+	}
+
+	// Taps are recognized when the touches end.
+	var ended []int
+	for id := range r.touches {
+		if _, ok := current[id]; !ok {
+			ended = append(ended, id)
+		}
+	}
+	sort.Ints(ended)
+	var panEnd *touch
+	for _, id := range ended {
+		s := r.touches[id]
+		delete(r.touches, id)
+		if r.panning && r.panID == id {
+			panEnd = s
+		}
+		if s.moved || s.multi || s.longPressed || s.ticks >= r.tapDuration() {
+			continue
+		}
+		if r.hasLastTap && r.ticks-r.lastTapTick <= r.doubleTapInterval() && math.Hypot(s.x-r.lastTapX, s.y-r.lastTapY) <= r.slop() {
+			r.gestures = append(r.gestures, Gesture{Kind: DoubleTap, X: s.x, Y: s.y})
+			r.hasLastTap = false
+			continue
+		}
+		r.gestures = append(r.gestures, Gesture{Kind: Tap, X: s.x, Y: s.y})
+		r.hasLastTap = true
+		r.lastTapTick = r.ticks
+		r.lastTapX, r.lastTapY = s.x, s.y
+	}
+
+	r.updatePan(panEnd)
+	r.updatePinch()
+
+	if len(r.touches) == 1 {
+		for _, s := range r.touches {
+			if !s.moved && !s.multi && !s.longPressed && s.ticks >= r.longPressDuration() {
+				r.gestures = append(r.gestures, Gesture{Kind: LongPress, X: s.x, Y: s.y})
+				s.longPressed = true
+			}
+		}
+	}
+}
+
+func (r *Recognizer) updatePan(ended *touch) {
+	if r.panning {
+		if ended != nil {
+			r.gestures = append(r.gestures, Gesture{Kind: Pan, Phase: PhaseEnded, X: ended.x, Y: ended.y})
+			r.panning = false
+		} else if s, ok := r.touches[r.panID]; ok && len(r.touches) != 1 {
+			r.gestures = append(r.gestures, Gesture{Kind: Pan, Phase: PhaseEnded, X: s.x, Y: s.y})
+			r.panning = false
+		}
+	}
+
+	if len(r.touches) != 1 {
+		return
+	}
+	for id, s := range r.touches {
+		if !s.moved || s.multi || s.longPressed {
+			return
+		}
+		if !r.panning {
+			r.gestures = append(r.gestures, Gesture{
+				Kind:  Pan,
+				Phase: PhaseBegan,
+				X:     s.x,
+				Y:     s.y,
+				DX:    s.x - s.startX,
+				DY:    s.y - s.startY,
+			})
+			r.panning = true
+			r.panID = id
+			return
+		}
+		r.gestures = append(r.gestures, Gesture{
+			Kind:  Pan,
+			Phase: PhaseChanged,
+			X:     s.x,
+			Y:     s.y,
+			DX:    s.x - s.prevX,
+			DY:    s.y - s.prevY,
+		})
+	}
+}
+
+func (r *Recognizer) updatePinch() {
+	if len(r.touches) != 2 {
+		if r.pinching {
+			r.gestures = append(r.gestures, Gesture{Kind: Pinch, Phase: PhaseEnded, X: r.pinchX, Y: r.pinchY, Scale: 1})
+			r.pinching = false
+		}
+		return
+	}
+
+	var ts []*touch
+	for _, s := range r.touches {
+		ts = append(ts, s)
+	}
+	a, b := ts[0], ts[1]
+	x, y := (a.x+b.x)/2, (a.y+b.y)/2
+	prevX, prevY := (a.prevX+b.prevX)/2, (a.prevY+b.prevY)/2
+	d := math.Hypot(a.x-b.x, a.y-b.y)
+	prevD := math.Hypot(a.prevX-b.prevX, a.prevY-b.prevY)
+
+	g := Gesture{
+		Kind:  Pinch,
+		X:     x,
+		Y:     y,
+		Scale: 1,
+	}
+	if !r.pinching {
+		g.Phase = PhaseBegan
+		r.pinching = true
+	} else {
+		g.Phase = PhaseChanged
+		g.DX, g.DY = x-prevX, y-prevY
+		if prevD > 0 && d > 0 {
+			g.Scale = d / prevD
+		}
+	}
+	r.pinchX, r.pinchY = x, y
+	r.gestures = append(r.gestures, g)
+}
+
+// Gestures returns the gestures recognized at the last update.
+func (r *Recognizer) Gestures() []Gesture {
+	gs := make([]Gesture, len(r.gestures))
+	copy(gs, r.gestures)
+	return gs
+}
@@ -0,0 +1,157 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gesture_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	. "github.com/hajimehoshi/ebiten/gesture"
+)
+
+type testTouch struct {
+	id int
+	x  int
+	y  int
+}
+
+func (t *testTouch) ID() int {
+	return t.id
+}
+
+func (t *testTouch) Position() (int, int) {
+	return t.x, t.y
+}
+
+func touches(ts ...*testTouch) []ebiten.Touch {
+	r := make([]ebiten.Touch, len(ts))
+	for i, t := range ts {
+		r[i] = t
+	}
+	return r
+}
+
+func kinds(gs []Gesture) []Kind {
+	var ks []Kind
+	for _, g := range gs {
+		ks = append(ks, g.Kind)
+	}
+	return ks
+}
+
+func TestTap(t *testing.T) {
+	r := &Recognizer{}
+	for i := 0; i < 3; i++ {
+		r.UpdateWithTouches(touches(&testTouch{0, 10, 10}))
+		if gs := r.Gestures(); len(gs) != 0 {
+			t.Fatalf("Gestures() while touching: got: %v, want: none", kinds(gs))
+		}
+	}
+	r.UpdateWithTouches(nil)
+	if gs := r.Gestures(); len(gs) != 1 || gs[0].Kind != Tap || gs[0].X != 10 || gs[0].Y != 10 {
+		t.Fatalf("Gestures() after a tap: got: %v", gs)
+	}
+
+	r.UpdateWithTouches(touches(&testTouch{1, 12, 11}))
+	r.UpdateWithTouches(nil)
+	if gs := r.Gestures(); len(gs) != 1 || gs[0].Kind != DoubleTap {
+		t.Errorf("Gestures() after the second tap: got: %v, want: [DoubleTap]", kinds(gs))
+	}
+
+	// A touch too long is not a tap.
+	for i := 0; i < 20; i++ {
+		r.UpdateWithTouches(touches(&testTouch{2, 10, 10}))
+	}
+	r.UpdateWithTouches(nil)
+	if gs := r.Gestures(); len(gs) != 0 {
+		t.Errorf("Gestures() after a long touch: got: %v, want: none", kinds(gs))
+	}
+}
+
+func TestLongPress(t *testing.T) {
+	r := &Recognizer{LongPressDuration: 5}
+	n := 0
+	for i := 0; i < 10; i++ {
+		r.UpdateWithTouches(touches(&testTouch{0, 10, 10}))
+		for _, g := range r.Gestures() {
+			if g.Kind != LongPress {
+				t.Errorf("unexpected gesture: %v", g.Kind)
+			}
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("the number of LongPress: got: %d, want: 1", n)
+	}
+}
+
+func TestPan(t *testing.T) {
+	r := &Recognizer{}
+	var dx float64
+	var phases []Phase
+	for x := 0; x <= 50; x += 10 {
+		r.UpdateWithTouches(touches(&testTouch{0, x, 0}))
+		for _, g := range r.Gestures() {
+			if g.Kind != Pan {
+				t.Fatalf("unexpected gesture: %v", g.Kind)
+			}
+			dx += g.DX
+			phases = append(phases, g.Phase)
+		}
+	}
+	r.UpdateWithTouches(nil)
+	for _, g := range r.Gestures() {
+		phases = append(phases, g.Phase)
+	}
+	if dx != 50 {
+		t.Errorf("total DX: got: %f, want: 50", dx)
+	}
+	// The pan begins when the touch moves farther than the slop.
+	want := []Phase{PhaseBegan, PhaseChanged, PhaseChanged, PhaseChanged, PhaseEnded}
+	if len(phases) != len(want) {
+		t.Fatalf("phases: got: %v, want: %v", phases, want)
+	}
+	for i := range want {
+		if phases[i] != want[i] {
+			t.Errorf("phases[%d]: got: %v, want: %v", i, phases[i], want[i])
+		}
+	}
+}
+
+func TestPinch(t *testing.T) {
+	r := &Recognizer{}
+	scale := 1.0
+	for d := 10; d <= 40; d += 10 {
+		r.UpdateWithTouches(touches(&testTouch{0, 100 - d, 100}, &testTouch{1, 100 + d, 100}))
+		gs := r.Gestures()
+		if len(gs) != 1 || gs[0].Kind != Pinch {
+			t.Fatalf("Gestures(): got: %v, want: [Pinch]", kinds(gs))
+		}
+		if gs[0].X != 100 || gs[0].Y != 100 {
+			t.Errorf("the center: got: (%f, %f), want: (100, 100)", gs[0].X, gs[0].Y)
+		}
+		scale *= gs[0].Scale
+	}
+	if math.Abs(scale-4) > 1e-9 {
+		t.Errorf("total scale: got: %f, want: 4", scale)
+	}
+
+	r.UpdateWithTouches(nil)
+	gs := r.Gestures()
+	if len(gs) != 1 || gs[0].Kind != Pinch || gs[0].Phase != PhaseEnded {
+		t.Errorf("Gestures() after releasing: got: %v, want: [Pinch]", gs)
+	}
+}
@@ -15,8 +15,8 @@
 package ebiten
 
 import (
+	"github.com/hajimehoshi/ebiten/internal/driver"
 	"github.com/hajimehoshi/ebiten/internal/graphics"
-	"github.com/hajimehoshi/ebiten/internal/opengl"
 )
 
 // Filter represents the type of texture filter to be used when an image is maginified or minified.
@@ -34,8 +34,154 @@ const (
 
 	// filterScreen represents a special filter for screen. Inner usage only.
 	filterScreen Filter = Filter(graphics.FilterScreen)
+
+	// FilterAnisotropic2, FilterAnisotropic4, FilterAnisotropic8 and FilterAnisotropic16 represent anisotropic
+	// filters with the maximum levels 2, 4, 8 and 16.
+	//
+	// An anisotropic filter is a linear filter that takes up to the level samples along the longer axis of the
+	// area of the source image covered by a destination pixel. This reduces flickering of images drawn with strong
+	// non-uniform scaling like a pseudo-3D road or a mode-7 style floor, at the cost of more texture fetches.
+	//
+	// Where anisotropic filters are not available (e.g., OpenGL ES 2.0 without GL_OES_standard_derivatives),
+	// FilterLinear is used instead.
+	FilterAnisotropic2  Filter = Filter(graphics.FilterAnisotropic2)
+	FilterAnisotropic4  Filter = Filter(graphics.FilterAnisotropic4)
+	FilterAnisotropic8  Filter = Filter(graphics.FilterAnisotropic8)
+	FilterAnisotropic16 Filter = Filter(graphics.FilterAnisotropic16)
+)
+
+// ScreenFilter represents the filter to render the screen on the window or the display.
+type ScreenFilter int
+
+const (
+	// ScreenFilterSharpBilinear scales the pixels like nearest filter and interpolates only the boundaries of
+	// the pixels, so that all the pixels look crisp and have the same size even at a fractional scale.
+	// This is the default screen filter.
+	ScreenFilterSharpBilinear ScreenFilter = iota
+
+	// ScreenFilterNearest scales the screen with nearest filter.
+	// At a fractional scale, the pixels might have different sizes.
+	ScreenFilterNearest
+
+	// ScreenFilterLinear scales the screen with linear filter.
+	ScreenFilterLinear
+
+	// ScreenFilterInteger scales the screen with nearest filter by the largest integer that fits the screen,
+	// and letterboxes the rest with black.
+	// If the screen doesn't fit even at the scale 1, the screen is scaled down with nearest filter.
+	//
+	// ScreenFilterInteger is the same as ScreenFilterNearest with LayoutPolicyInteger regardless of
+	// the current layout policy.
+	ScreenFilterInteger
+)
+
+// filter returns the filter to draw the screen with.
+func (s ScreenFilter) filter() Filter {
+	switch s {
+	case ScreenFilterNearest, ScreenFilterInteger:
+		return FilterNearest
+	case ScreenFilterLinear:
+		return FilterLinear
+	default:
+		return filterScreen
+	}
+}
+
+// ColorChannels represents a set of color channels as bit flags.
+type ColorChannels int
+
+const (
+	ColorChannelRed   ColorChannels = ColorChannels(driver.ColorChannelRed)
+	ColorChannelGreen ColorChannels = ColorChannels(driver.ColorChannelGreen)
+	ColorChannelBlue  ColorChannels = ColorChannels(driver.ColorChannelBlue)
+	ColorChannelAlpha ColorChannels = ColorChannels(driver.ColorChannelAlpha)
+)
+
+// Address represents an address mode, which specifies how the pixels out of the source region are sampled.
+type Address int
+
+const (
+	// AddressClampToZero makes the pixels out of the source region transparent. This is the default address mode.
+	AddressClampToZero Address = Address(driver.AddressClampToZero)
+
+	// AddressClampToEdge makes the pixels out of the source region the nearest pixels on the edges.
+	AddressClampToEdge Address = Address(driver.AddressClampToEdge)
+
+	// AddressRepeat repeats the whole source image.
+	AddressRepeat Address = Address(driver.AddressRepeat)
+
+	// AddressMirrorRepeat repeats the whole source image with mirroring it at every other repetition.
+	AddressMirrorRepeat Address = Address(driver.AddressMirrorRepeat)
 )
 
+// PixelFormat represents a pixel format of an image.
+type PixelFormat int
+
+const (
+	// PixelFormatRGBA8 represents 8-bit alpha-premultiplied RGBA. This is the default format.
+	PixelFormatRGBA8 PixelFormat = PixelFormat(driver.PixelFormatRGBA8)
+
+	// PixelFormatAlpha8 represents 8-bit alpha, which is suitable e.g. for masks and glyphs.
+	//
+	// An image of this format is drawn as white with the alpha, so the color can be specified with ColorM.
+	// An image of this format can't be a render target: the pixels can be changed only by ReplacePixels,
+	// Fill and Clear.
+	PixelFormatAlpha8 PixelFormat = PixelFormat(driver.PixelFormatAlpha8)
+
+	// PixelFormatRGBA16F represents 16-bit floating point alpha-premultiplied RGBA, which is suitable e.g. for HDR accumulation.
+	// Each component is represented as IEEE 754 binary16 in little endian.
+	//
+	// If the environment doesn't support 16-bit floating point textures, 8-bit textures are used instead.
+	PixelFormatRGBA16F PixelFormat = PixelFormat(driver.PixelFormatRGBA16F)
+
+	// PixelFormatSRGBA8 represents 8-bit alpha-premultiplied sRGB-encoded RGBA.
+	// The pixels are same as PixelFormatRGBA8's, but alpha blending and filtering on an image of this format
+	// are done in the linear color space. This fixes e.g. dark fringes on antialiased edges.
+	// ColorM is still applied in the sRGB color space.
+	//
+	// If the environment doesn't support sRGB textures, 8-bit textures are used instead and
+	// an image of this format behaves as PixelFormatRGBA8.
+	PixelFormatSRGBA8 PixelFormat = PixelFormat(driver.PixelFormatSRGBA8)
+
+	// PixelFormatETC2RGBA8 represents ETC2 compressed RGBA with EAC alpha, which is available
+	// e.g. on OpenGL ES 3 devices and on desktops with GL_ARB_ES3_compatibility.
+	//
+	// An image of a compressed format can be created only by NewImageFromCompressedData.
+	PixelFormatETC2RGBA8 PixelFormat = PixelFormat(driver.PixelFormatETC2RGBA8)
+
+	// PixelFormatASTC4x4 represents ASTC compressed RGBA with 4x4 blocks, which is available e.g. on recent mobile GPUs.
+	//
+	// An image of a compressed format can be created only by NewImageFromCompressedData.
+	PixelFormatASTC4x4 PixelFormat = PixelFormat(driver.PixelFormatASTC4x4)
+
+	// PixelFormatBC3 represents BC3 (DXT5) compressed RGBA, which is available e.g. on most desktops.
+	//
+	// An image of a compressed format can be created only by NewImageFromCompressedData.
+	PixelFormatBC3 PixelFormat = PixelFormat(driver.PixelFormatBC3)
+)
+
+// BytesPerPixel returns the size of a pixel of the format in bytes.
+//
+// BytesPerPixel panics for a compressed format, of which a pixel is not represented in bytes.
+func (f PixelFormat) BytesPerPixel() int {
+	return driver.PixelFormat(f).BytesPerPixel()
+}
+
+func (f PixelFormat) isCompressed() bool {
+	return driver.PixelFormat(f).IsCompressed()
+}
+
+// IsPixelFormatAvailable reports whether the pixel format is natively available on the device.
+//
+// Images of an unavailable uncompressed format still work with 8-bit RGBA textures,
+// while images of an unavailable compressed format can't be created.
+//
+// As the available formats are determined by the device, IsPixelFormatAvailable always returns false
+// before the main loop (ebiten.Run) starts.
+func IsPixelFormatAvailable(format PixelFormat) bool {
+	return graphics.IsPixelFormatAvailable(driver.PixelFormat(format))
+}
+
 // CompositeMode represents Porter-Duff composition mode.
 type CompositeMode int
 
@@ -46,42 +192,64 @@ type CompositeMode int
 const (
 	// Regular alpha blending
 	// c_out = c_src + c_dst × (1 - α_src)
-	CompositeModeSourceOver CompositeMode = CompositeMode(opengl.CompositeModeSourceOver)
+	CompositeModeSourceOver CompositeMode = CompositeMode(driver.CompositeModeSourceOver)
 
 	// c_out = 0
-	CompositeModeClear CompositeMode = CompositeMode(opengl.CompositeModeClear)
+	CompositeModeClear CompositeMode = CompositeMode(driver.CompositeModeClear)
 
 	// c_out = c_src
-	CompositeModeCopy CompositeMode = CompositeMode(opengl.CompositeModeCopy)
+	CompositeModeCopy CompositeMode = CompositeMode(driver.CompositeModeCopy)
 
 	// c_out = c_dst
-	CompositeModeDestination CompositeMode = CompositeMode(opengl.CompositeModeDestination)
+	CompositeModeDestination CompositeMode = CompositeMode(driver.CompositeModeDestination)
 
 	// c_out = c_src × (1 - α_dst) + c_dst
-	CompositeModeDestinationOver CompositeMode = CompositeMode(opengl.CompositeModeDestinationOver)
+	CompositeModeDestinationOver CompositeMode = CompositeMode(driver.CompositeModeDestinationOver)
 
 	// c_out = c_src × α_dst
-	CompositeModeSourceIn CompositeMode = CompositeMode(opengl.CompositeModeSourceIn)
+	CompositeModeSourceIn CompositeMode = CompositeMode(driver.CompositeModeSourceIn)
 
 	// c_out = c_dst × α_src
-	CompositeModeDestinationIn CompositeMode = CompositeMode(opengl.CompositeModeDestinationIn)
+	CompositeModeDestinationIn CompositeMode = CompositeMode(driver.CompositeModeDestinationIn)
 
 	// c_out = c_src × (1 - α_dst)
-	CompositeModeSourceOut CompositeMode = CompositeMode(opengl.CompositeModeSourceOut)
+	CompositeModeSourceOut CompositeMode = CompositeMode(driver.CompositeModeSourceOut)
 
 	// c_out = c_dst × (1 - α_src)
-	CompositeModeDestinationOut CompositeMode = CompositeMode(opengl.CompositeModeDestinationOut)
+	CompositeModeDestinationOut CompositeMode = CompositeMode(driver.CompositeModeDestinationOut)
 
 	// c_out = c_src × α_dst + c_dst × (1 - α_src)
-	CompositeModeSourceAtop CompositeMode = CompositeMode(opengl.CompositeModeSourceAtop)
+	CompositeModeSourceAtop CompositeMode = CompositeMode(driver.CompositeModeSourceAtop)
 
 	// c_out = c_src × (1 - α_dst) + c_dst × α_src
-	CompositeModeDestinationAtop CompositeMode = CompositeMode(opengl.CompositeModeDestinationAtop)
+	CompositeModeDestinationAtop CompositeMode = CompositeMode(driver.CompositeModeDestinationAtop)
 
 	// c_out = c_src × (1 - α_dst) + c_dst × (1 - α_src)
-	CompositeModeXor CompositeMode = CompositeMode(opengl.CompositeModeXor)
+	CompositeModeXor CompositeMode = CompositeMode(driver.CompositeModeXor)
 
 	// Sum of source and destination (a.k.a. 'plus' or 'additive')
 	// c_out = c_src + c_dst
-	CompositeModeLighter CompositeMode = CompositeMode(opengl.CompositeModeLighter)
+	CompositeModeLighter CompositeMode = CompositeMode(driver.CompositeModeLighter)
+
+	// The product of source and destination (a.k.a 'multiply blend mode')
+	// This is useful e.g. for shadows.
+	// c_out = c_src × c_dst + c_dst × (1 - α_src)
+	CompositeModeMultiply CompositeMode = CompositeMode(driver.CompositeModeMultiply)
+
+	// The inverted product of the inverted source and destination (a.k.a 'screen blend mode')
+	// c_out = c_src + c_dst × (1 - c_src)
+	CompositeModeScreen CompositeMode = CompositeMode(driver.CompositeModeScreen)
+
+	// The minimum of source and destination for each component including alpha
+	// c_out = min(c_src, c_dst)
+	CompositeModeMin CompositeMode = CompositeMode(driver.CompositeModeMin)
+
+	// The maximum of source and destination for each component including alpha
+	// c_out = max(c_src, c_dst)
+	CompositeModeMax CompositeMode = CompositeMode(driver.CompositeModeMax)
+
+	// Difference of destination and source
+	// c_out = c_dst - c_src
+	// α_out = α_src + α_dst
+	CompositeModeSubtract CompositeMode = CompositeMode(driver.CompositeModeSubtract)
 )
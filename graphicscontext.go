@@ -15,14 +15,23 @@
 package ebiten
 
 import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/hajimehoshi/ebiten/internal/clock"
 	"github.com/hajimehoshi/ebiten/internal/graphics"
 	"github.com/hajimehoshi/ebiten/internal/hooks"
+	"github.com/hajimehoshi/ebiten/internal/logger"
 	"github.com/hajimehoshi/ebiten/internal/shareable"
 	"github.com/hajimehoshi/ebiten/internal/ui"
 	"github.com/hajimehoshi/ebiten/internal/web"
 )
 
+// contextLossRequested is 1 when SimulateContextLoss is called and the images are not restored yet.
+var contextLossRequested int32
+
 func newGraphicsContext(f func(*Image) error) *graphicsContext {
 	return &graphicsContext{
 		f: f,
@@ -34,35 +43,89 @@ type graphicsContext struct {
 	offscreen   *Image
 	screen      *Image
 	initialized bool
-	invalidated bool // browser only
+	invalidated bool // browser and mobile only
 	offsetX     float64
 	offsetY     float64
+
+	// paddingRight and paddingBottom are the padding of the screen framebuffer around the screen, e.g. on
+	// fullscreen mode. offsetX and offsetY are the left and the top padding.
+	paddingRight  float64
+	paddingBottom float64
+
+	// screenInvalidated indicates whether the screen framebuffer must be rendered regardless of the power saving mode.
+	screenInvalidated bool
+
+	// presented indicates whether the screen framebuffer is rendered at the last Update.
+	presented bool
+
+	// overMemoryBudget indicates whether the image memory usage exceeded the budget at the last Update.
+	overMemoryBudget bool
+
+	// draw is called to draw the screen at a frame without updates.
+	// If draw is nil, the last screen is presented as it is at such a frame.
+	draw func(*Image)
+
+	// postEffectImages are the images to apply the post effects to in rotation.
+	postEffectImages [2]*Image
+
+	debugOverlay debugOverlay
+	frameTimer   frameTimer
+
+	// windows are the additional windows created by NewWindow.
+	windows  []*Window
+	windowsM sync.Mutex
 }
 
 func (c *graphicsContext) Invalidate() {
-	// Note that this is called only on browsers so far.
-	// TODO: On mobiles, this function is not called and instead IsTexture is called
-	// to detect if the context is lost. This is simple but might not work on some platforms.
-	// Should Invalidate be called explicitly?
+	// Note that this is called on browsers, or on mobiles only when the context lost is notified
+	// explicitly (e.g. by the view generated by ebitenmobile).
+	// Otherwise, IsTexture is called to detect if the context is lost.
+	// This is simple but might not work on some platforms.
 	c.invalidated = true
 }
 
+func (c *graphicsContext) ResetGLStateCache() {
+	shareable.ResetGLStateCache()
+}
+
 func (c *graphicsContext) SetSize(screenWidth, screenHeight int, screenScale float64) {
 	if c.screen != nil {
 		_ = c.screen.Dispose()
 	}
-	if c.offscreen != nil {
-		_ = c.offscreen.Dispose()
-	}
-	c.offscreen = newVolatileImage(screenWidth, screenHeight)
+	c.resetOffscreen(screenWidth, screenHeight)
 
 	w := int(float64(screenWidth) * screenScale)
 	h := int(float64(screenHeight) * screenScale)
-	px0, py0, _, _ := ui.ScreenPadding()
+	px0, py0, px1, py1 := ui.ScreenPadding()
 	c.screen = newImageWithScreenFramebuffer(w, h)
 
 	c.offsetX = px0
 	c.offsetY = py0
+	c.paddingRight = px1
+	c.paddingBottom = py1
+	c.screenInvalidated = true
+}
+
+// resetOffscreen recreates the offscreen with the given size in the current format.
+func (c *graphicsContext) resetOffscreen(width, height int) {
+	if c.offscreen != nil {
+		_ = c.offscreen.Dispose()
+	}
+	c.offscreen = newVolatileImage(width, height, offscreenFormat())
+	for i, img := range c.postEffectImages {
+		if img != nil {
+			_ = img.Dispose()
+		}
+		c.postEffectImages[i] = nil
+	}
+}
+
+// offscreenFormat returns the pixel format of the offscreen.
+func offscreenFormat() PixelFormat {
+	if IsSRGBRenderingEnabled() {
+		return PixelFormatSRGBA8
+	}
+	return PixelFormatRGBA8
 }
 
 func (c *graphicsContext) initializeIfNeeded() error {
@@ -70,6 +133,7 @@ func (c *graphicsContext) initializeIfNeeded() error {
 		if err := shareable.InitializeGLState(); err != nil {
 			return err
 		}
+		MaxImageSize = graphics.MaxImageSize()
 		c.initialized = true
 	}
 	if err := c.restoreIfNeeded(); err != nil {
@@ -79,13 +143,32 @@ func (c *graphicsContext) initializeIfNeeded() error {
 }
 
 func (c *graphicsContext) Update(afterFrameUpdate func()) error {
-	updateCount := clock.Update()
+	c.frameTimer.begin()
+	refreshRate := 0
+	if ui.IsVsyncEnabled() {
+		// The frames are paced with the display only when vsync is enabled.
+		refreshRate = ui.RefreshRate()
+	}
+	updateCount := clock.Update(refreshRate)
+	c.debugOverlay.update()
 
 	if err := c.initializeIfNeeded(); err != nil {
 		return err
 	}
+	if err := hooks.RunRestoreHooksIfNeeded(); err != nil {
+		return err
+	}
+	if c.offscreen.Format() != offscreenFormat() {
+		// The offscreen is volatile and doesn't have to be preserved.
+		c.resetOffscreen(c.offscreen.Size())
+	}
+	// In the power saving mode, the offscreen is not cleared and keeps the last contents.
+	powerSaving := IsPowerSavingEnabled()
+	c.offscreen.modified = false
 	for i := 0; i < updateCount; i++ {
-		c.offscreen.fill(0, 0, 0, 0)
+		if !powerSaving {
+			c.offscreen.fill(0, 0, 0, 0)
+		}
 
 		setRunningSlowly(i < updateCount-1)
 		if err := hooks.RunBeforeUpdateHooks(); err != nil {
@@ -94,45 +177,171 @@ func (c *graphicsContext) Update(afterFrameUpdate func()) error {
 		if err := c.f(c.offscreen); err != nil {
 			return err
 		}
+		if err := takeDelayedError(); err != nil {
+			return err
+		}
 		afterFrameUpdate()
 	}
+	if updateCount == 0 && c.draw != nil {
+		if !powerSaving {
+			c.offscreen.fill(0, 0, 0, 0)
+		}
+		setRunningSlowly(false)
+		c.draw(c.offscreen)
+		if err := takeDelayedError(); err != nil {
+			return err
+		}
+	}
 
-	// Clear the screen framebuffer by DrawImage instad of Fill
-	// to clear the whole region including fullscreen's padding.
-	// TODO: This clear is needed only when the screen size is changed.
-	if c.offsetX > 0 || c.offsetY > 0 {
-		op := &DrawImageOptions{}
-		w, h := emptyImage.Size()
-		s := float64(graphics.MaxImageSize())
-		op.GeoM.Scale(s/float64(w), s/float64(h))
-		op.CompositeMode = CompositeModeCopy
-		c.screen.DrawImage(emptyImage, op)
+	// In the power saving mode, the screen framebuffer is not rendered when nothing is drawn on the offscreen,
+	// if the UI can keep presenting the last rendered framebuffer.
+	c.presented = !powerSaving || c.offscreen.modified || c.screenInvalidated || !ui.CanSkipPresenting() ||
+		IsDebugOverlayEnabled() || currentFrameRecorder() != nil
+	if c.presented {
+		c.screenInvalidated = false
+		if err := c.renderScreen(); err != nil {
+			return err
+		}
 	}
 
-	dw, dh := c.screen.Size()
-	sw, _ := c.offscreen.Size()
-	scale := float64(dw) / float64(sw)
+	// Dispose the images released by GC at the frame boundary, not in the middle of the frame.
+	theImages.disposeFinalized()
 
-	op := &DrawImageOptions{}
-	// c.screen is special: its Y axis is down to up,
-	// and the origin point is lower left.
-	op.GeoM.Scale(scale, -scale)
-	op.GeoM.Translate(0, float64(dh))
-	op.GeoM.Translate(c.offsetX, c.offsetY)
+	c.frameTimer.beginFlush()
+	if err := shareable.ResolveStaleImages(); err != nil {
+		return err
+	}
+	c.frameTimer.endFlush()
+	c.checkImageMemoryBudget()
+	theStreamer.endFrame()
 
-	op.CompositeMode = CompositeModeCopy
-	op.Filter = filterScreen
-	_ = c.screen.DrawImage(c.offscreen, op)
+	if err := c.updateWindows(); err != nil {
+		return err
+	}
+	return nil
+}
 
-	if err := shareable.ResolveStaleImages(); err != nil {
+// NeedsPresenting reports whether the screen framebuffer is rendered at the last Update and needs to be presented.
+func (c *graphicsContext) NeedsPresenting() bool {
+	return c.presented
+}
+
+// renderScreen renders the offscreen to the screen framebuffer with the post effects and the debug overlay.
+func (c *graphicsContext) renderScreen() error {
+	src, err := c.applyPostEffects()
+	if err != nil {
 		return err
 	}
+	if r := currentFrameRecorder(); r != nil {
+		if err := recordFrame(r, src); err != nil {
+			return err
+		}
+	}
+
+	sw, sh := src.Size()
+	dw, dh := c.screen.Size()
+	m := newScreenMapping(CurrentLayoutPolicy(), CurrentScreenFilter(), sw, sh, dw, dh, c.offsetX, c.offsetY, c.paddingRight, c.paddingBottom)
+	renderOnScreen(c.screen, src, m, CurrentScreenFilter())
+	theScreenMapping.Store(m)
+
+	if IsDebugOverlayEnabled() {
+		// c.screen is special: its Y axis is down to up,
+		// and the origin point is lower left.
+		var geom GeoM
+		geom.Scale(1, -1)
+		geom.Translate(0, float64(dh))
+		geom.Translate(c.offsetX, c.offsetY)
+		c.debugOverlay.draw(c.screen, geom, math.Max(1, math.Floor(math.Min(m.scaleX, m.scaleY))))
+	}
+	return nil
+}
+
+func (c *graphicsContext) addWindow(w *Window) {
+	c.windowsM.Lock()
+	c.windows = append(c.windows, w)
+	c.windowsM.Unlock()
+}
+
+// updateWindows draws the additional windows, and removes the closed windows.
+func (c *graphicsContext) updateWindows() error {
+	// Copy the windows as a window's function might create another window.
+	c.windowsM.Lock()
+	windows := make([]*Window, len(c.windows))
+	copy(windows, c.windows)
+	c.windowsM.Unlock()
+
+	for _, w := range windows {
+		if w.IsClosed() {
+			w.dispose()
+			c.removeWindow(w)
+			continue
+		}
+		if err := w.update(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (c *graphicsContext) removeWindow(w *Window) {
+	c.windowsM.Lock()
+	defer c.windowsM.Unlock()
+	for i, ww := range c.windows {
+		if ww == w {
+			c.windows = append(c.windows[:i], c.windows[i+1:]...)
+			return
+		}
+	}
+}
+
+// applyPostEffects applies the post effects to the offscreen and returns the result.
+// If there are no post effects, applyPostEffects returns the offscreen as it is.
+func (c *graphicsContext) applyPostEffects() (*Image, error) {
+	effects := currentPostEffects()
+	if len(effects) == 0 {
+		return c.offscreen, nil
+	}
+
+	w, h := c.offscreen.Size()
+	for i := range c.postEffectImages {
+		if c.postEffectImages[i] == nil {
+			c.postEffectImages[i] = newVolatileImage(w, h, c.offscreen.Format())
+		}
+	}
+
+	src := c.offscreen
+	for i, e := range effects {
+		dst := c.postEffectImages[i%len(c.postEffectImages)]
+		dst.fill(0, 0, 0, 0)
+		if err := e.Apply(dst, src); err != nil {
+			return nil, err
+		}
+		src = dst
+	}
+	return src, nil
+}
+
+// checkImageMemoryBudget logs when the image memory usage exceeds the budget.
+// The event is logged once until the usage goes under the budget again.
+func (c *graphicsContext) checkImageMemoryBudget() {
+	budget := ImageMemoryBudget()
+	usage := ImageMemoryUsage()
+	over := budget > 0 && usage > budget
+	if over && !c.overMemoryBudget {
+		logger.Warn("ebiten: the image memory usage exceeds the budget", "usage", usage, "budget", budget)
+	}
+	c.overMemoryBudget = over
+}
+
 func (c *graphicsContext) needsRestoring() (bool, error) {
+	if atomic.CompareAndSwapInt32(&contextLossRequested, 1, 0) {
+		return true, nil
+	}
+	if c.invalidated {
+		return true, nil
+	}
 	if web.IsBrowser() {
-		return c.invalidated, nil
+		return false, nil
 	}
 	return c.offscreen.shareableImage.IsInvalidated()
 }
@@ -148,9 +357,14 @@ func (c *graphicsContext) restoreIfNeeded() error {
 	if !r {
 		return nil
 	}
+	logger.Warn("ebiten: the graphics context is lost and the images are being restored")
+	start := time.Now()
 	if err := shareable.Restore(); err != nil {
 		return err
 	}
+	logger.Info("ebiten: the images are restored", "duration", time.Since(start))
+	hooks.RequestRestore()
 	c.invalidated = false
+	c.screenInvalidated = true
 	return nil
 }
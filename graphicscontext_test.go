@@ -0,0 +1,123 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"image/color"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/internal/shareable"
+)
+
+func TestSimulateContextLoss(t *testing.T) {
+	if !shareable.IsRestoringEnabled() {
+		t.Skip("restoring is disabled: run the test with EBITEN_FORCE_RESTORING=1")
+	}
+
+	src, _ := NewImage(4, 4, FilterDefault)
+	src.Fill(color.RGBA{0x80, 0x40, 0x20, 0xff})
+	dst, _ := NewImage(8, 8, FilterDefault)
+	op := &DrawImageOptions{}
+	op.GeoM.Translate(2, 2)
+	dst.DrawImage(src, op)
+
+	// Resolve the stale images as the end of a frame does.
+	if err := shareable.ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+
+	SimulateContextLoss()
+	c := newGraphicsContext(nil)
+	r, err := c.needsRestoring()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r {
+		t.Fatal("needsRestoring(): got: false, want: true")
+	}
+	if err := shareable.Restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	for j := 0; j < 8; j++ {
+		for i := 0; i < 8; i++ {
+			got := dst.At(i, j)
+			want := color.RGBA{}
+			if 2 <= i && i < 6 && 2 <= j && j < 6 {
+				want = color.RGBA{0x80, 0x40, 0x20, 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	// The request is consumed by the first check.
+	if atomic.LoadInt32(&contextLossRequested) != 0 {
+		t.Error("contextLossRequested must be reset after the check")
+	}
+}
+
+type translateEffect struct {
+	dx float64
+}
+
+func (t *translateEffect) Apply(dst, src *Image) error {
+	op := &DrawImageOptions{}
+	op.GeoM.Translate(t.dx, 0)
+	return dst.DrawImage(src, op)
+}
+
+func TestApplyPostEffects(t *testing.T) {
+	defer SetPostEffects()
+
+	c := newGraphicsContext(nil)
+	c.offscreen = newVolatileImage(8, 8, PixelFormatRGBA8)
+	c.offscreen.fill(0, 0, 0, 0)
+	pix := make([]byte, 4*8*8)
+	for j := 0; j < 8; j++ {
+		pix[4*(j*8)] = 0xff
+		pix[4*(j*8)+3] = 0xff
+	}
+	c.offscreen.ReplacePixels(pix)
+
+	// Without post effects, the offscreen is used as it is.
+	SetPostEffects()
+	img, err := c.applyPostEffects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img != c.offscreen {
+		t.Errorf("applyPostEffects() must return the offscreen without post effects")
+	}
+
+	// Three effects use the images in rotation.
+	SetPostEffects(&translateEffect{1}, &translateEffect{2}, &translateEffect{3})
+	img, err = c.applyPostEffects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 8; i++ {
+		got := img.At(i, 4)
+		want := color.RGBA{}
+		if i == 6 {
+			want = color.RGBA{0xff, 0, 0, 0xff}
+		}
+		if got != want {
+			t.Errorf("img.At(%d, 4): got: %v, want: %v", i, got, want)
+		}
+	}
+}
@@ -15,14 +15,17 @@
 package ebiten
 
 import (
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"runtime"
+	"sync/atomic"
 
 	"github.com/hajimehoshi/ebiten/internal/affine"
+	"github.com/hajimehoshi/ebiten/internal/driver"
 	"github.com/hajimehoshi/ebiten/internal/graphics"
 	"github.com/hajimehoshi/ebiten/internal/graphicsutil"
-	"github.com/hajimehoshi/ebiten/internal/opengl"
 	"github.com/hajimehoshi/ebiten/internal/shareable"
 )
 
@@ -33,6 +36,7 @@ var emptyImage *Image
 
 func init() {
 	emptyImage, _ = NewImage(16, 16, FilterDefault)
+	theImages.remove(emptyImage)
 }
 
 // Image represents a rectangle set of pixels.
@@ -48,6 +52,20 @@ type Image struct {
 	shareableImage *shareable.Image
 
 	filter Filter
+
+	// clip is the clipping region for DrawImage. nil means no clipping.
+	clip *image.Rectangle
+
+	// palette is the palette of a paletted image. nil means the image is not paletted.
+	// See NewPalettedImage.
+	palette *Image
+
+	// modified indicates whether the image is modified since modified is reset.
+	// This is used to detect whether the screen is changed at a frame in the power saving mode.
+	modified bool
+
+	// id is the key in the image registry. id is 0 when the image is not tracked. See Images.
+	id uint64
 }
 
 func (i *Image) copyCheck() {
@@ -61,11 +79,18 @@ func (i *Image) Size() (width, height int) {
 	return i.shareableImage.Size()
 }
 
+// Format returns the pixel format of the image.
+func (i *Image) Format() PixelFormat {
+	return PixelFormat(i.shareableImage.Format())
+}
+
 func (i *Image) isDisposed() bool {
-	return i.shareableImage == nil
+	// The shareable image might be disposed by DisposeAll without the image.
+	return i.shareableImage == nil || i.shareableImage.IsDisposed()
 }
 
 // Clear resets the pixels of the image into 0.
+// If the image has a depth buffer, the depth buffer is also cleared.
 //
 // When the image is disposed, Clear does nothing.
 //
@@ -80,6 +105,7 @@ func (i *Image) Clear() error {
 }
 
 // Fill fills the image with a solid color.
+// If the image has a depth buffer, the depth buffer is also cleared.
 //
 // When the image is disposed, Fill does nothing.
 //
@@ -95,7 +121,21 @@ func (i *Image) Fill(clr color.Color) error {
 }
 
 func (i *Image) fill(r, g, b, a uint8) {
+	if i.Format().isCompressed() {
+		panic("ebiten: an image of a compressed format can't be filled")
+	}
 	wd, hd := i.Size()
+	if i.Format() == PixelFormatAlpha8 {
+		// An alpha-only image can't be a render target.
+		pix := make([]byte, wd*hd)
+		for idx := range pix {
+			pix[idx] = a
+		}
+		i.modified = true
+		i.shareableImage.ReplacePixels(pix)
+		return
+	}
+
 	ws, hs := emptyImage.Size()
 	sw := float64(wd) / float64(ws)
 	sh := float64(hd) / float64(hs)
@@ -108,9 +148,70 @@ func (i *Image) fill(r, g, b, a uint8) {
 		af := float64(a) / 0xff
 		op.ColorM.Translate(rf, gf, bf, af)
 	}
-	op.CompositeMode = CompositeModeCopy
-	op.Filter = FilterNearest
-	_ = i.DrawImage(emptyImage, op)
+
+	// Call the shareable image's DrawImage directly, since Fill and Clear are not affected by the clipping region.
+	i.modified = true
+	i.shareableImage.ClearDepth()
+	i.shareableImage.DrawImage(emptyImage.shareableImage, 0, 0, ws, hs, op.GeoM.impl, op.ColorM.impl, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+}
+
+// SetClip sets the clipping region of the image.
+//
+// DrawImage on the image changes only the pixels in the clipping region.
+// If DrawImageOptions.ClipRect is also specified, the intersection of them is used.
+// Fill and Clear are not affected by the clipping region.
+//
+// If r is nil, the clipping region is reset and DrawImage can change the whole image.
+//
+// SetClip copies the content of r pointer.
+func (i *Image) SetClip(r *image.Rectangle) {
+	i.copyCheck()
+	if r == nil {
+		i.clip = nil
+		return
+	}
+	c := *r
+	i.clip = &c
+}
+
+// Clip returns the clipping region of the image set by SetClip.
+//
+// If the image is not clipped, Clip returns nil.
+func (i *Image) Clip() *image.Rectangle {
+	i.copyCheck()
+	if i.clip == nil {
+		return nil
+	}
+	c := *i.clip
+	return &c
+}
+
+// clipRect returns the clipping region for DrawImage, which is the intersection of
+// the image's clipping region and r.
+//
+// clipRect returns nil when the drawing doesn't have to be clipped.
+// clipRect returns false when the clipping region is empty and nothing is drawn.
+func (i *Image) clipRect(r *image.Rectangle) (*image.Rectangle, bool) {
+	if i.clip == nil && r == nil {
+		return nil, true
+	}
+	b := i.Bounds()
+	c := b
+	if i.clip != nil {
+		c = c.Intersect(*i.clip)
+	}
+	if r != nil {
+		c = c.Intersect(*r)
+	}
+	if c.Empty() {
+		return nil, false
+	}
+	// When the region covers the whole image, don't clip so that the draw commands can be merged
+	// with the other commands.
+	if c == b {
+		return nil, true
+	}
+	return &c, true
 }
 
 // DrawImage draws the given image on the image i.
@@ -126,7 +227,11 @@ func (i *Image) fill(r, g, b, a uint8) {
 // When the image i is disposed, DrawImage does nothing.
 // When the given image img is disposed, DrawImage panics.
 //
-// When the given image is as same as i, DrawImage panics.
+// The given image img can be the same as i, e.g. for feedback effects like motion trails and scrolling.
+// As described above, the pixels of img before the drawing are adopted even if the source and the destination
+// regions overlap. Drawing an image on itself is slower than drawing another image since the source region
+// is copied internally.
+// When the format of i is PixelFormatAlpha8 or a compressed format, DrawImage panics.
 //
 // DrawImage works more efficiently as batches
 // when the successive calls of DrawImages satisfies the below conditions:
@@ -136,9 +241,15 @@ func (i *Image) fill(r, g, b, a uint8) {
 //     * This is not a strong request since different images might share a same inner
 //       OpenGL texture in high possibility. This is not 100%, so using the same render
 //       source is safer.
-//   * All ColorM values are same
+//   * All ColorM values are same, or all consist of only scaling and translation
+//     (e.g. made by ColorM.Scale and ColorM.Translate)
 //   * All CompositeMode values are same
 //   * All Filter values are same
+//   * All clipping regions are same (see ClipRect and SetClip)
+//   * All DisabledChannels values are same
+//   * All Address values are same
+//
+// To draw many parts of the same image, e.g. sprites, DrawImages is cheaper.
 //
 // For more performance tips, see https://github.com/hajimehoshi/ebiten/wiki/Performance-Tips.
 //
@@ -151,6 +262,12 @@ func (i *Image) DrawImage(img *Image, options *DrawImageOptions) error {
 	if i.isDisposed() {
 		return nil
 	}
+	if i.Format() == PixelFormatAlpha8 {
+		panic("ebiten: an image of PixelFormatAlpha8 can't be a render target")
+	}
+	if i.Format().isCompressed() {
+		panic("ebiten: an image of a compressed format can't be a render target")
+	}
 
 	// Calculate vertices before locking because the user can do anything in
 	// options.ImageParts interface without deadlock (e.g. Call Image functions).
@@ -158,48 +275,147 @@ func (i *Image) DrawImage(img *Image, options *DrawImageOptions) error {
 		options = &DrawImageOptions{}
 	}
 
-	parts := options.ImageParts
-	// Parts is deprecated. This implementations is for backward compatibility.
-	if parts == nil && options.Parts != nil {
-		parts = imageParts(options.Parts)
-	}
-
-	// ImageParts is deprecated. This implementations is for backward compatibility.
-	if parts != nil {
-		l := parts.Len()
-		for idx := 0; idx < l; idx++ {
-			sx0, sy0, sx1, sy1 := parts.Src(idx)
-			dx0, dy0, dx1, dy1 := parts.Dst(idx)
-			op := &DrawImageOptions{
-				ColorM:        options.ColorM,
-				CompositeMode: options.CompositeMode,
-			}
-			r := image.Rect(sx0, sy0, sx1, sy1)
-			op.SourceRect = &r
-			op.GeoM.Scale(
-				float64(dx1-dx0)/float64(sx1-sx0),
-				float64(dy1-dy0)/float64(sy1-sy0))
-			op.GeoM.Translate(float64(dx0), float64(dy0))
-			op.GeoM.Concat(options.GeoM)
-			i.DrawImage(img, op)
+	// Parts and ImageParts are deprecated. This implementation is for backward compatibility.
+	if options.ImageParts != nil || options.Parts != nil {
+		i.DrawImages(img, partOptions(options))
+		return nil
+	}
+
+	q, st, ok := i.quadAndState(img, options)
+	if !ok {
+		return nil
+	}
+	if img.palette != nil {
+		if _, _, ok := st.colorm.Tint(); ok {
+			q.Tint, st.colorm = st.colorm, nil
+		}
+		i.drawQuads(img, []graphics.Quad{q}, &st)
+		return nil
+	}
+	i.modified = true
+	i.shareableImage.DrawImage(img.shareableImage, q.SX0, q.SY0, q.SX1, q.SY1, q.GeoM, st.colorm, st.mode, st.filter, st.clip, st.disabled, q.Z, st.address)
+	return nil
+}
+
+// DrawImages draws the given image on the image i with each of the options ops in order.
+//
+// The result is the same as calling DrawImage for each of ops, but DrawImages is cheaper
+// especially when drawing many small parts of the same image like sprites.
+// The successive options that satisfy the batch conditions described at DrawImage are
+// processed at once without the per-call overhead.
+//
+// When the image i is disposed, DrawImages does nothing.
+// When the given image img is disposed, DrawImages panics.
+// When the format of i is PixelFormatAlpha8 or a compressed format, DrawImages panics.
+//
+// DrawImages always returns nil.
+func (i *Image) DrawImages(img *Image, ops []DrawImageOptions) error {
+	i.copyCheck()
+	if img.isDisposed() {
+		panic("ebiten: the given image to DrawImages must not be disposed")
+	}
+	if i.isDisposed() {
+		return nil
+	}
+	if i.Format() == PixelFormatAlpha8 {
+		panic("ebiten: an image of PixelFormatAlpha8 can't be a render target")
+	}
+	if i.Format().isCompressed() {
+		panic("ebiten: an image of a compressed format can't be a render target")
+	}
+
+	// Each drawing on the image itself must adopt the result of the previous drawings.
+	if img == i {
+		for idx := range ops {
+			i.DrawImage(img, &ops[idx])
 		}
 		return nil
 	}
 
+	quads := make([]graphics.Quad, 0, len(ops))
+	var st drawImageState
+	for idx := range ops {
+		op := &ops[idx]
+		// Parts and ImageParts are deprecated. They are drawn separately for backward compatibility.
+		if op.ImageParts != nil || op.Parts != nil {
+			i.drawQuads(img, quads, &st)
+			quads = quads[:0]
+			i.DrawImage(img, op)
+			continue
+		}
+		q, s, ok := i.quadAndState(img, op)
+		if !ok {
+			continue
+		}
+		// As well as DrawImage, a color matrix consisting of only scaling and translation is applied
+		// per quadrangle so that the quadrangles with different tints can be drawn at once.
+		if _, _, ok := s.colorm.Tint(); ok && (img.Format() != PixelFormatAlpha8 || img.palette != nil) {
+			q.Tint, s.colorm = s.colorm, nil
+		}
+		if len(quads) > 0 && !st.equals(&s) {
+			i.drawQuads(img, quads, &st)
+			quads = quads[:0]
+		}
+		quads = append(quads, q)
+		st = s
+	}
+	i.drawQuads(img, quads, &st)
+	return nil
+}
+
+func (i *Image) drawQuads(img *Image, quads []graphics.Quad, st *drawImageState) {
+	if len(quads) == 0 {
+		return
+	}
+	i.modified = true
+	if img.palette != nil {
+		i.shareableImage.DrawImagesWithPalette(img.shareableImage, img.palette.shareableImage, quads, st.colorm, st.mode, st.clip, st.disabled, st.address)
+		return
+	}
+	i.shareableImage.DrawImages(img.shareableImage, quads, st.colorm, st.mode, st.filter, st.clip, st.disabled, st.address)
+}
+
+// drawImageState represents the states of a drawing other than the quadrangle.
+type drawImageState struct {
+	colorm   *affine.ColorM
+	mode     driver.CompositeMode
+	filter   graphics.Filter
+	clip     *image.Rectangle
+	disabled driver.ColorChannels
+	address  driver.Address
+}
+
+func (s *drawImageState) equals(other *drawImageState) bool {
+	if s.colorm != other.colorm || s.mode != other.mode || s.filter != other.filter || s.disabled != other.disabled || s.address != other.address {
+		return false
+	}
+	if s.clip == nil || other.clip == nil {
+		return s.clip == other.clip
+	}
+	return *s.clip == *other.clip
+}
+
+// quadAndState returns the quadrangle and the state to draw img on the image i with options.
+// The deprecated parts of options are ignored.
+//
+// quadAndState returns false when nothing is drawn.
+func (i *Image) quadAndState(img *Image, options *DrawImageOptions) (graphics.Quad, drawImageState, bool) {
+	address := driver.Address(options.Address)
+
 	w, h := img.Size()
 	sx0, sy0, sx1, sy1 := 0, 0, w, h
 	if r := options.SourceRect; r != nil {
 		sx0 = r.Min.X
 		sy0 = r.Min.Y
-		if sx1 > r.Max.X {
+		if sx1 > r.Max.X || address.Wraps() {
 			sx1 = r.Max.X
 		}
-		if sy1 > r.Max.Y {
+		if sy1 > r.Max.Y || address.Wraps() {
 			sy1 = r.Max.Y
 		}
 	}
 	geom := options.GeoM.impl
-	if sx0 < 0 || sy0 < 0 {
+	if (sx0 < 0 || sy0 < 0) && !address.Wraps() {
 		dx := 0.0
 		dy := 0.0
 		if sx0 < 0 {
@@ -216,7 +432,7 @@ func (i *Image) DrawImage(img *Image, options *DrawImageOptions) error {
 		geom = g
 	}
 
-	mode := opengl.CompositeMode(options.CompositeMode)
+	mode := driver.CompositeMode(options.CompositeMode)
 
 	filter := graphics.FilterNearest
 	if options.Filter != FilterDefault {
@@ -225,7 +441,112 @@ func (i *Image) DrawImage(img *Image, options *DrawImageOptions) error {
 		filter = graphics.Filter(img.filter)
 	}
 
-	i.shareableImage.DrawImage(img.shareableImage, sx0, sy0, sx1, sy1, geom, options.ColorM.impl, mode, filter)
+	clip, ok := i.clipRect(options.ClipRect)
+	if !ok {
+		return graphics.Quad{}, drawImageState{}, false
+	}
+
+	z := options.Z
+	if z < 0 {
+		z = 0
+	}
+	if z > 1 {
+		z = 1
+	}
+
+	q := graphics.Quad{
+		SX0:  sx0,
+		SY0:  sy0,
+		SX1:  sx1,
+		SY1:  sy1,
+		GeoM: geom,
+		Z:    float32(z),
+	}
+	st := drawImageState{
+		colorm:   options.ColorM.impl,
+		mode:     mode,
+		filter:   filter,
+		clip:     clip,
+		disabled: driver.ColorChannels(options.DisabledChannels),
+		address:  address,
+	}
+	return q, st, true
+}
+
+// drawImageWithLUT draws img on the image i, converting the colors with the color look-up table lut.
+//
+// See ColorGradingEffect for the LUT image layout.
+func (i *Image) drawImageWithLUT(img, lut *Image) {
+	i.copyCheck()
+	if img.isDisposed() || lut.isDisposed() {
+		panic("ebiten: the given images to drawImageWithLUT must not be disposed")
+	}
+	if i.isDisposed() {
+		return
+	}
+	w, h := img.Size()
+	i.modified = true
+	i.shareableImage.DrawImageWithLUT(img.shareableImage, lut.shareableImage, 0, 0, w, h, nil, nil, driver.CompositeModeCopy)
+}
+
+// CopyFrom copies the pixels of src to the image i.
+//
+// The sizes of i and src must be the same, or CopyFrom panics.
+//
+// See CopyRegion for details.
+//
+// CopyFrom always returns nil.
+func (i *Image) CopyFrom(src *Image) error {
+	if src.isDisposed() {
+		panic("ebiten: the given image to CopyFrom must not be disposed")
+	}
+	if i.isDisposed() {
+		return nil
+	}
+	w, h := src.Size()
+	if dw, dh := i.Size(); dw != w || dh != h {
+		panic("ebiten: the sizes of the image and the given image to CopyFrom must be the same")
+	}
+	return i.CopyRegion(image.ZP, src, image.Rect(0, 0, w, h))
+}
+
+// CopyRegion copies the region srcRect of src to the position dst of the image i.
+// The parts out of the bounds of the images are ignored.
+//
+// Unlike DrawImage with CompositeModeCopy, CopyRegion copies the pixels as they are on GPU
+// without the drawing pipeline. The clipping region of i is not applied.
+// Copying is cheaper than drawing also in terms of restoring the image on context lost,
+// especially when the whole image is overwritten.
+// This is useful e.g. for snapshots and double buffering.
+//
+// When the image i is disposed, CopyRegion does nothing.
+// When the given image src is disposed, CopyRegion panics.
+//
+// When the given image is as same as i, CopyRegion panics.
+// When the formats of the images are different, PixelFormatAlpha8 or a compressed format, CopyRegion panics.
+//
+// CopyRegion always returns nil.
+func (i *Image) CopyRegion(dst image.Point, src *Image, srcRect image.Rectangle) error {
+	i.copyCheck()
+	if src.isDisposed() {
+		panic("ebiten: the given image to CopyRegion must not be disposed")
+	}
+	if i.isDisposed() {
+		return nil
+	}
+	if f := i.Format(); f != src.Format() || f == PixelFormatAlpha8 || f.isCompressed() {
+		panic("ebiten: CopyRegion is not available between the images of the pixel formats")
+	}
+
+	sr := srcRect.Intersect(src.Bounds())
+	dst = dst.Add(sr.Min.Sub(srcRect.Min))
+	dr := image.Rectangle{dst, dst.Add(sr.Size())}.Intersect(i.Bounds())
+	if dr.Empty() {
+		return nil
+	}
+	sr.Min = sr.Min.Add(dr.Min.Sub(dst))
+	i.modified = true
+	i.shareableImage.CopyPixels(src.shareableImage, sr.Min.X, sr.Min.Y, dr.Dx(), dr.Dy(), dr.Min.X, dr.Min.Y)
 	return nil
 }
 
@@ -246,21 +567,102 @@ func (i *Image) ColorModel() color.Model {
 //
 // At always returns a transparent color if the image is disposed.
 //
-// At can't be called before the main loop (ebiten.Run) starts (as of version 1.4.0-alpha).
+// Before the main loop (ebiten.Run) starts, At can read only the pixels given on CPU,
+// i.e. the pixels of images created by NewImage or NewImageFromImage and modified only by ReplacePixels.
+// As the pixels drawn by DrawImage or Fill are not determined until the main loop starts, At panics for them.
+// In tests, the ebitentest package makes the drawn pixels available without the main loop.
+//
+// In the main loop, At doesn't panic even when reading the pixels from GPU fails, e.g. due to a driver failure.
+// Instead, At returns a transparent color and the error is returned from Run at the end of the current
+// logical update, so that the game can handle the error instead of crashing.
+//
+// At panics for an image of a compressed format.
 func (i *Image) At(x, y int) color.Color {
 	if i.isDisposed() {
 		return color.RGBA{}
 	}
+	if i.Format().isCompressed() {
+		panic("ebiten: the pixels of an image of a compressed format can't be read")
+	}
 	clr, err := i.shareableImage.At(x, y)
 	if err != nil {
-		panic(err)
+		setDelayedError(err)
+		return color.RGBA{}
+	}
+	if i.palette != nil {
+		if !image.Pt(x, y).In(i.Bounds()) {
+			return color.RGBA{}
+		}
+		// The alpha value of an alpha-only pixel is the index of the palette.
+		return i.paletteColorAt(clr.(color.RGBA).A)
 	}
 	return clr
 }
 
+// ResolvePixels reads the pixels of the given images from GPU at once if needed, so that the following At calls for
+// the images are fast.
+//
+// At reads the pixels of an image from GPU when the image has been drawn since the pixels were read last time,
+// and every reading waits for the queued drawing commands to be done. Reading pixels of many images drawn in a frame
+// by At, e.g. for collision detection with masks, results in many such waits. ResolvePixels waits for the drawing
+// commands only once, and reads the pixels of all the given images. The pixels of images sharing the same texture
+// are read only once.
+//
+// The read pixels are cached and At uses them until the image is drawn again or InvalidatePixelCache is called. Typically, calling ResolvePixels once
+// a frame after drawing the images and before reading the pixels is enough.
+//
+// ResolvePixels ignores disposed images and images of compressed formats.
+//
+// This function is concurrent-safe.
+func ResolvePixels(images []*Image) error {
+	var imgs []*shareable.Image
+	for _, img := range images {
+		if img.isDisposed() || img.Format().isCompressed() {
+			continue
+		}
+		imgs = append(imgs, img.shareableImage)
+	}
+	return shareable.ResolvePixels(imgs)
+}
+
+// IsPixelCacheValid reports whether the pixels of the image are cached on CPU,
+// i.e. whether At returns the pixels without reading them from GPU.
+//
+// The cache is taken when At or ResolvePixels reads the pixels, and is invalidated when the image is drawn
+// or InvalidatePixelCache is called. The pixels given by ReplacePixels are also cached.
+//
+// IsPixelCacheValid returns false for a disposed image and an image of a compressed format.
+//
+// This function is concurrent-safe.
+func (i *Image) IsPixelCacheValid() bool {
+	if i.isDisposed() || i.Format().isCompressed() {
+		return false
+	}
+	return i.shareableImage.IsPixelCacheValid()
+}
+
+// InvalidatePixelCache discards the pixels of the image cached on CPU, so that the next At or ResolvePixels
+// reads the pixels from GPU again. The pixels of the image are not changed.
+//
+// InvalidatePixelCache is useful to control when the pixels are read, e.g. to release the memory of the cache of
+// a large image whose pixels are not read any more. Note that the cache of other images might be invalidated too,
+// as images can share a texture internally. On platforms where the images need to be restored after
+// the graphics context is lost, e.g. mobiles, the pixels might be read at the end of the frame to restore the image.
+//
+// InvalidatePixelCache does nothing for a disposed image and an image of a compressed format.
+//
+// This function is concurrent-safe.
+func (i *Image) InvalidatePixelCache() {
+	if i.isDisposed() || i.Format().isCompressed() {
+		return
+	}
+	i.shareableImage.InvalidatePixelCache()
+}
+
 // Dispose disposes the image data. After disposing, most of image functions do nothing and returns meaningless values.
 //
 // Dispose is useful to save memory.
+// An image released by GC without Dispose is disposed automatically at the end of a frame.
 //
 // When the image is disposed, Dipose does nothing.
 //
@@ -272,17 +674,24 @@ func (i *Image) Dispose() error {
 	}
 	i.shareableImage.Dispose()
 	i.shareableImage = nil
+	if i.palette != nil {
+		_ = i.palette.Dispose()
+	}
+	theImages.remove(i)
 	runtime.SetFinalizer(i, nil)
 	return nil
 }
 
 // ReplacePixels replaces the pixels of the image with p.
 //
-// The given p must represent RGBA pre-multiplied alpha values. len(p) must equal to 4 * (image width) * (image height).
+// The given p must represent pixels in the image's format (see PixelFormat).
+// For the default format, p must represent RGBA pre-multiplied alpha values.
+// len(p) must equal to (bytes per pixel) * (image width) * (image height).
 //
 // ReplacePixels may be slow (as for implementation, this calls glTexSubImage2D).
 //
 // When len(p) is not appropriate, ReplacePixels panics.
+// When the format of the image is a compressed format, ReplacePixels panics.
 //
 // When the image is disposed, ReplacePixels does nothing.
 //
@@ -292,6 +701,10 @@ func (i *Image) ReplacePixels(p []byte) error {
 	if i.isDisposed() {
 		return nil
 	}
+	if i.Format().isCompressed() {
+		panic("ebiten: the pixels of an image of a compressed format can't be replaced")
+	}
+	i.modified = true
 	i.shareableImage.ReplacePixels(p)
 	return nil
 }
@@ -301,7 +714,11 @@ type DrawImageOptions struct {
 	// SourceRect is the region of the source image to draw.
 	// If SourceRect is nil, whole image is used.
 	//
-	// It is assured that texels out of the SourceRect are never used.
+	// It is assured that texels out of the SourceRect are never used
+	// unless Address is AddressRepeat or AddressMirrorRepeat.
+	//
+	// With AddressRepeat or AddressMirrorRepeat, SourceRect can exceed the bounds of the source image,
+	// and the region out of the bounds is filled by repeating the source image.
 	//
 	// Calling DrawImage copies the content of SourceRect pointer. This means that
 	// even if the SourceRect value is modified after passed to DrawImage,
@@ -324,6 +741,9 @@ type DrawImageOptions struct {
 
 	// CompositeMode is a composite mode to draw.
 	// The default (zero) value is regular alpha blending.
+	//
+	// With DrawImages, each of the options can have a different composite mode, e.g. to mix additive and
+	// alpha-blended sprites. The drawings are batched per run of the successive options with the same composite mode.
 	CompositeMode CompositeMode
 
 	// Filter is a type of texture filter.
@@ -338,10 +758,49 @@ type DrawImageOptions struct {
 	// Otherwise, Filter specified at DrawImageOptions is used.
 	Filter Filter
 
-	// Deprecated (as of 1.5.0-alpha): Use SourceRect instead.
+	// ClipRect is the region of the destination image to draw in.
+	// The pixels out of ClipRect are never changed.
+	// If ClipRect is nil, the drawing is clipped only by the clipping region of the destination image (see SetClip).
+	//
+	// Clipping is done without allocating any intermediate images,
+	// so this is useful e.g. for UI widgets and split-screen views.
+	//
+	// Calling DrawImage copies the content of ClipRect pointer as well as SourceRect.
+	ClipRect *image.Rectangle
+
+	// DisabledChannels is the color channels of the destination image that are not changed by drawing.
+	// The default (zero) value changes all the channels.
+	//
+	// For example, specifying ColorChannelRed | ColorChannelGreen | ColorChannelBlue changes only
+	// the alpha channel, which is useful e.g. to prepare an alpha mask in place.
+	DisabledChannels ColorChannels
+
+	// Address is an address mode to sample the source image.
+	// The default (zero) value is AddressClampToZero.
+	//
+	// For example, an infinite scrolling background can be drawn by one DrawImage call with AddressRepeat
+	// and a SourceRect that is larger than the image.
+	//
+	// An image drawn with AddressRepeat or AddressMirrorRepeat is moved to its own texture,
+	// which might make batching the drawings less efficient.
+	Address Address
+
+	// Z is the depth of the drawing, which is used only when the destination image has a depth buffer
+	// (see NewImageWithDepth). Z is clamped to [0, 1].
+	// The default (zero) value is the farthest.
+	//
+	// On an image with a depth buffer, a drawing with a greater Z is rendered in front of the drawings with smaller Z
+	// regardless of the order of the calls, and drawings with the same Z are rendered in the order of the calls.
+	// This is useful e.g. to sort sprites in 2.5D games without sorting the calls.
+	// The fully transparent pixels of the source image don't hide the pixels behind them,
+	// while the semi-transparent pixels do.
+	// The depth is not tested nor updated with CompositeModeCopy and CompositeModeClear.
+	Z float64
+
+	// Deprecated (as of 1.5.0-alpha): Use SourceRect instead. To draw multiple parts, use DrawImages.
 	ImageParts ImageParts
 
-	// Deprecated (as of 1.1.0-alpha): Use SourceRect instead.
+	// Deprecated (as of 1.1.0-alpha): Use SourceRect instead. To draw multiple parts, use DrawImages.
 	Parts []ImagePart
 }
 
@@ -360,29 +819,90 @@ func NewImage(width, height int, filter Filter) (*Image, error) {
 		filter:         filter,
 	}
 	i.addr = i
-	runtime.SetFinalizer(i, (*Image).Dispose)
+	setFinalizer(i)
+	theImages.add(i)
+	return i, nil
+}
+
+// NewImageWithFormat returns an empty image with the given pixel format.
+//
+// Images of the default format PixelFormatRGBA8 might share a texture with other images,
+// while images of the other formats have their own textures.
+//
+// If width or height is less than 1 or more than device-dependent maximum size, NewImageWithFormat panics.
+//
+// Error returned by NewImageWithFormat is always nil.
+func NewImageWithFormat(width, height int, format PixelFormat, filter Filter) (*Image, error) {
+	s := shareable.NewImageWithFormat(width, height, driver.PixelFormat(format))
+	i := &Image{
+		shareableImage: s,
+		filter:         filter,
+	}
+	i.addr = i
+	setFinalizer(i)
+	theImages.add(i)
 	return i, nil
 }
 
-// newVolatileImage returns an empty 'volatile' image.
-// A volatile image is always cleared at the start of a frame.
+// NewImageWithDepth returns an empty image with a depth buffer.
+//
+// On an image with a depth buffer, drawings are sorted by DrawImageOptions.Z on GPU.
+// The depth buffer is cleared by Clear and Fill. Typically, an image with a depth buffer should be cleared every frame.
 //
-// This is suitable for offscreen images that pixels are changed often.
+// Images with depth buffers have their own textures and are not shared with other images.
+//
+// If width or height is less than 1 or more than device-dependent maximum size, NewImageWithDepth panics.
+//
+// Error returned by NewImageWithDepth is always nil.
+func NewImageWithDepth(width, height int, filter Filter) (*Image, error) {
+	s := shareable.NewImageWithDepth(width, height)
+	i := &Image{
+		shareableImage: s,
+		filter:         filter,
+	}
+	i.addr = i
+	setFinalizer(i)
+	theImages.add(i)
+	return i, nil
+}
+
+// NewVolatileImage returns an empty 'volatile' image.
+//
+// A volatile image is suitable for an offscreen buffer whose pixels are redrawn every frame,
+// e.g. a scratch buffer for effects.
 //
 // Pixels in regular non-volatile images are saved at each end of a frame if the image
 // is changed, and restored automatically from the saved pixels on GL context lost.
-// On the other hand, pixels in volatile images are not saved.
+// On the other hand, pixels in volatile images are neither saved nor restored: the pixels are
+// undefined after the context is lost, and must be redrawn.
 // Saving pixels is an expensive operation, and it is desirable to avoid it if possible.
 //
-// Note that volatile images are internal only and will never be source of drawing.
+// Note that when a volatile image is drawn on a regular image, the regular image can't be restored
+// from its drawing history and its pixels are saved at the end of the frame instead.
+// Volatile images should be drawn only on the screen or other volatile images.
+//
+// Volatile images have their own textures and are not shared with other images.
+//
+// If width or height is less than 1 or more than device-dependent maximum size, NewVolatileImage panics.
+//
+// Error returned by NewVolatileImage is always nil.
+func NewVolatileImage(width, height int, filter Filter) (*Image, error) {
+	i := newVolatileImage(width, height, PixelFormatRGBA8)
+	i.filter = filter
+	theImages.add(i)
+	return i, nil
+}
+
+// newVolatileImage returns an empty 'volatile' image with the given pixel format.
+// See NewVolatileImage.
 //
 // If width or height is less than 1 or more than device-dependent maximum size, newVolatileImage panics.
-func newVolatileImage(width, height int) *Image {
+func newVolatileImage(width, height int, format PixelFormat) *Image {
 	i := &Image{
-		shareableImage: shareable.NewVolatileImage(width, height),
+		shareableImage: shareable.NewVolatileImage(width, height, driver.PixelFormat(format)),
 	}
 	i.addr = i
-	runtime.SetFinalizer(i, (*Image).Dispose)
+	setFinalizer(i)
 	return i
 }
 
@@ -393,6 +913,9 @@ func newVolatileImage(width, height int) *Image {
 // filter argument is just for backward compatibility.
 // If you are not sure, specify FilterDefault.
 //
+// NewImageFromImage can be called before the main loop (ebiten.Run) starts, e.g. to load assets.
+// Uploading the pixels to GPU is deferred until the main loop starts.
+//
 // Error returned by NewImageFromImage is always nil as of 1.5.0-alpha.
 func NewImageFromImage(source image.Image, filter Filter) (*Image, error) {
 	size := source.Bounds().Size()
@@ -405,23 +928,96 @@ func NewImageFromImage(source image.Image, filter Filter) (*Image, error) {
 		filter:         filter,
 	}
 	i.addr = i
-	runtime.SetFinalizer(i, (*Image).Dispose)
+	setFinalizer(i)
+	theImages.add(i)
 
 	_ = i.ReplacePixels(graphicsutil.CopyImage(source))
 	return i, nil
 }
 
+// NewImageFromCompressedData creates a new image of the compressed pixel format with the compressed texels data.
+//
+// data must consist of 4x4 texel blocks of 16 bytes in the format, e.g. the payload of a KTX or DDS file without the header.
+// The colors must be alpha-premultiplied.
+// Compressed texels are uploaded to GPU as they are, which saves GPU memory and the upload time.
+//
+// An image of a compressed format can't be a render target, and its pixels can't be replaced or read:
+// DrawImage, Fill, Clear, ReplacePixels and At on it panic. The image can be drawn on other images.
+//
+// The format must be available on the device (see IsPixelFormatAvailable).
+// As the available formats are determined by the device, NewImageFromCompressedData can be called only after
+// the main loop (ebiten.Run) starts.
+//
+// NewImageFromCompressedData returns an error when the format is not an available compressed format,
+// or when the size of data doesn't match with the image size.
+func NewImageFromCompressedData(width, height int, format PixelFormat, data []byte, filter Filter) (*Image, error) {
+	if !format.isCompressed() {
+		return nil, errors.New("ebiten: the pixel format must be compressed")
+	}
+	if !IsPixelFormatAvailable(format) {
+		return nil, errors.New("ebiten: the compressed pixel format is not available")
+	}
+	if l := driver.PixelFormat(format).DataSize(width, height); len(data) != l {
+		return nil, fmt.Errorf("ebiten: len(data) was %d but must be %d", len(data), l)
+	}
+	i := &Image{
+		shareableImage: shareable.NewImageFromCompressedData(width, height, driver.PixelFormat(format), data),
+		filter:         filter,
+	}
+	i.addr = i
+	setFinalizer(i)
+	theImages.add(i)
+	return i, nil
+}
+
 func newImageWithScreenFramebuffer(width, height int) *Image {
 	i := &Image{
 		shareableImage: shareable.NewScreenFramebufferImage(width, height),
 		filter:         FilterDefault,
 	}
 	i.addr = i
-	runtime.SetFinalizer(i, (*Image).Dispose)
+	setFinalizer(i)
 	return i
 }
 
-// MaxImageSize is deprecated as of 1.7.0-alpha. No replacement so far.
+// ImageMemoryUsage returns the total size in bytes of the GPU memory for images.
+//
+// The size includes the padding of textures: small images share a large texture, and
+// a texture's width and height are expanded to powers of 2 on environments without
+// non-power-of-two texture support. Thus, the returned value is usually larger than
+// the sum of 4 * (image width) * (image height).
+// The memory for the screen is not counted.
+//
+// The usage is updated when the graphics commands are executed, which happens at least once per frame.
+func ImageMemoryUsage() int64 {
+	return graphics.MemoryUsage()
+}
+
+var imageMemoryBudget int64
+
+// ImageMemoryBudget returns the budget in bytes of the GPU memory for images.
+// 0 means no budget.
+//
+// This function is concurrent-safe.
+func ImageMemoryBudget() int64 {
+	return atomic.LoadInt64(&imageMemoryBudget)
+}
+
+// SetImageMemoryBudget sets the budget in bytes of the GPU memory for images.
+//
+// When ImageMemoryUsage exceeds the budget at the end of a frame, a warning is reported to the logger
+// (see SetLogger). Exceeding the budget doesn't affect anything else.
+// If budget is 0, the usage is not checked. The default value is 0.
+//
+// This function is concurrent-safe.
+func SetImageMemoryBudget(budget int64) {
+	atomic.StoreInt64(&imageMemoryBudget, budget)
+}
+
+// MaxImageSize is the maximum width and height of an image.
+//
+// MaxImageSize is updated with the actual maximum texture size of the device when the main loop starts.
+// Before that, MaxImageSize is 4096, which is available on most devices.
 //
-// TODO: Make this replacement (#541)
+// To use an image larger than MaxImageSize, use LargeImage.
 var MaxImageSize = 4096
@@ -201,18 +201,45 @@ func TestImageComposition(t *testing.T) {
 }
 
 func TestImageSelf(t *testing.T) {
-	// Note that mutex usages: without defer, unlocking is not called when panicing.
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("DrawImage must panic but not")
-		}
-	}()
-	img, _, err := openEbitenImage()
+	const w = 4
+	img, err := NewImage(w, 1, FilterNearest)
 	if err != nil {
 		t.Fatal(err)
 		return
 	}
-	img.DrawImage(img, nil)
+	defer img.Dispose()
+
+	cs := []color.RGBA{
+		{0xff, 0, 0, 0xff},
+		{0, 0xff, 0, 0xff},
+		{0, 0, 0xff, 0xff},
+		{0xff, 0xff, 0, 0xff},
+	}
+	pix := make([]byte, 4*w)
+	for i, c := range cs {
+		pix[4*i] = c.R
+		pix[4*i+1] = c.G
+		pix[4*i+2] = c.B
+		pix[4*i+3] = c.A
+	}
+	if err := img.ReplacePixels(pix); err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	// Scroll the image by 1 pixel. The source and the destination regions overlap.
+	op := &DrawImageOptions{}
+	op.GeoM.Translate(1, 0)
+	op.CompositeMode = CompositeModeCopy
+	img.DrawImage(img, op)
+
+	want := []color.RGBA{cs[0], cs[0], cs[1], cs[2]}
+	for i := 0; i < w; i++ {
+		got := img.At(i, 0)
+		if got != want[i] {
+			t.Errorf("img.At(%d, 0): got: %v, want: %v", i, got, want[i])
+		}
+	}
 }
 
 func TestImageScale(t *testing.T) {
@@ -368,6 +395,83 @@ func TestImageReplacePixels(t *testing.T) {
 	}
 }
 
+func TestImageReplacePixelsLarge(t *testing.T) {
+	// The pixels of a large region are uploaded on the upload worker if available.
+	const w, h = 512, 512
+	src, _ := NewImage(w, h, FilterNearest)
+	dst, _ := NewImage(w, h, FilterNearest)
+
+	// The order of the operations must be kept: the upload must be after the fill.
+	src.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			idx := 4 * (i + j*w)
+			pix[idx] = byte(i)
+			pix[idx+1] = byte(j)
+			pix[idx+2] = 0x80
+			pix[idx+3] = 0xff
+		}
+	}
+	src.ReplacePixels(pix)
+
+	// Drawing the image right after the upload must wait for the upload.
+	dst.DrawImage(src, nil)
+	for j := 0; j < h; j += 31 {
+		for i := 0; i < w; i += 31 {
+			got := dst.At(i, j)
+			want := color.RGBA{byte(i), byte(j), 0x80, 0xff}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageReplacePixelsMultipleImages(t *testing.T) {
+	// The small images share one texture, and replacing their pixels in a frame is uploaded at once.
+	const (
+		w = 7
+		h = 5
+		n = 8
+	)
+	imgs := make([]*Image, n)
+	for k := range imgs {
+		img, err := NewImage(w, h, FilterNearest)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		defer img.Dispose()
+		imgs[k] = img
+	}
+	for k, img := range imgs {
+		p := make([]byte, 4*w*h)
+		for i := 0; i < w*h; i++ {
+			p[4*i] = byte(k)
+			p[4*i+1] = byte(i)
+			p[4*i+2] = byte(k + i)
+			p[4*i+3] = 0xff
+		}
+		if err := img.ReplacePixels(p); err != nil {
+			t.Fatal(err)
+			return
+		}
+	}
+	for k, img := range imgs {
+		for j := 0; j < h; j++ {
+			for i := 0; i < w; i++ {
+				got := img.At(i, j)
+				idx := i + j*w
+				want := color.RGBA{byte(k), byte(idx), byte(k + idx), 0xff}
+				if got != want {
+					t.Errorf("imgs[%d] At(%d, %d): got %#v; want %#v", k, i, j, got, want)
+				}
+			}
+		}
+	}
+}
+
 func TestImageDispose(t *testing.T) {
 	img, err := NewImage(16, 16, FilterNearest)
 	if err != nil {
@@ -433,6 +537,131 @@ func TestImageCompositeModeLighter(t *testing.T) {
 	}
 }
 
+func TestImageDisabledChannels(t *testing.T) {
+	src, _ := NewImage(1, 1, FilterNearest)
+	src.Fill(color.RGBA{0x10, 0x20, 0x30, 0x40})
+	dst, _ := NewImage(1, 1, FilterNearest)
+	dst.Fill(color.RGBA{0x80, 0x80, 0x80, 0xff})
+
+	op := &DrawImageOptions{}
+	op.CompositeMode = CompositeModeCopy
+	op.DisabledChannels = ColorChannelRed | ColorChannelGreen | ColorChannelBlue
+	dst.DrawImage(src, op)
+
+	got := dst.At(0, 0).(color.RGBA)
+	want := color.RGBA{0x80, 0x80, 0x80, 0x40}
+	if got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+
+	// The color mask must not affect later draws.
+	dst.DrawImage(src, &DrawImageOptions{CompositeMode: CompositeModeCopy})
+	got = dst.At(0, 0).(color.RGBA)
+	want = color.RGBA{0x10, 0x20, 0x30, 0x40}
+	if got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestImageCopyRegion(t *testing.T) {
+	const w, h = 16, 16
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			idx := 4 * (j*w + i)
+			pix[idx] = byte(i * 8)
+			pix[idx+1] = byte(j * 8)
+			pix[idx+3] = 0x80
+		}
+	}
+	src, _ := NewImage(w, h, FilterDefault)
+	src.ReplacePixels(pix)
+	srcAt := func(i, j int) color.RGBA {
+		return color.RGBA{byte(i * 8), byte(j * 8), 0, 0x80}
+	}
+
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	dst, _ := NewImage(w, h, FilterDefault)
+	dst.Fill(blue)
+	// The clipping region doesn't affect copying.
+	dst.SetClip(&image.Rectangle{})
+	dst.CopyRegion(image.Pt(-2, 4), src, image.Rect(2, 2, 10, 10))
+
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			want := blue
+			if i < 6 && 4 <= j && j < 12 {
+				want = srcAt(i+4, j-2)
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	dst.CopyFrom(src)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			want := srcAt(i, j)
+			if got != want {
+				t.Errorf("dst.At(%d, %d) after CopyFrom: got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageCompositeModes(t *testing.T) {
+	dstClr := color.RGBA{0x80, 0x40, 0x20, 0xff}
+	srcClr := color.RGBA{0x40, 0x80, 0x10, 0xff}
+	testCases := []struct {
+		name string
+		mode CompositeMode
+		want color.RGBA
+	}{
+		{
+			name: "multiply",
+			mode: CompositeModeMultiply,
+			want: color.RGBA{0x20, 0x20, 0x02, 0xff},
+		},
+		{
+			name: "screen",
+			mode: CompositeModeScreen,
+			want: color.RGBA{0xa0, 0xa0, 0x2e, 0xff},
+		},
+		{
+			name: "min",
+			mode: CompositeModeMin,
+			want: color.RGBA{0x40, 0x40, 0x10, 0xff},
+		},
+		{
+			name: "max",
+			mode: CompositeModeMax,
+			want: color.RGBA{0x80, 0x80, 0x20, 0xff},
+		},
+		{
+			name: "subtract",
+			mode: CompositeModeSubtract,
+			want: color.RGBA{0x40, 0x00, 0x10, 0xff},
+		},
+	}
+
+	src, _ := NewImage(1, 1, FilterNearest)
+	src.Fill(srcClr)
+	for _, c := range testCases {
+		dst, _ := NewImage(1, 1, FilterNearest)
+		dst.Fill(dstClr)
+		op := &DrawImageOptions{}
+		op.CompositeMode = c.mode
+		dst.DrawImage(src, op)
+		got := dst.At(0, 0).(color.RGBA)
+		if !sameColors(got, c.want, 1) {
+			t.Errorf("%s: got %v, want: %v", c.name, got, c.want)
+		}
+	}
+}
+
 func TestNewImageFromEbitenImage(t *testing.T) {
 	img, _, err := openEbitenImage()
 	if err != nil {
@@ -612,6 +841,83 @@ func TestImageTooManyFill(t *testing.T) {
 	}
 }
 
+func TestImageTooManyQuads(t *testing.T) {
+	// The number of the quads is more than the limit of one draw call with 16-bit indices.
+	const (
+		width  = 256
+		height = 128
+	)
+
+	src, _ := NewImage(1, 1, FilterNearest)
+	src.Fill(color.White)
+	dst, _ := NewImage(width, height, FilterNearest)
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			if (i+j)%2 == 0 {
+				continue
+			}
+			op := &DrawImageOptions{}
+			op.GeoM.Translate(float64(i), float64(j))
+			dst.DrawImage(src, op)
+		}
+	}
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			got := color.RGBAModel.Convert(dst.At(i, j)).(color.RGBA)
+			want := color.RGBA{}
+			if (i+j)%2 != 0 {
+				want = color.RGBA{0xff, 0xff, 0xff, 0xff}
+			}
+			if got != want {
+				t.Fatalf("dst.At(%d, %d): got %#v, want: %#v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageClip(t *testing.T) {
+	src, _ := NewImage(16, 16, FilterNearest)
+	src.Fill(color.White)
+	dst, _ := NewImage(16, 16, FilterNearest)
+
+	// Clip by both the image's clipping region and ClipRect.
+	c := image.Rect(2, 2, 12, 12)
+	dst.SetClip(&c)
+	op := &DrawImageOptions{}
+	r := image.Rect(8, 4, 20, 20)
+	op.ClipRect = &r
+	dst.DrawImage(src, op)
+
+	// Fill is not affected by the clipping region.
+	dst2, _ := NewImage(16, 16, FilterNearest)
+	dst2.SetClip(&c)
+	dst2.Fill(color.White)
+
+	// An empty clipping region draws nothing.
+	dst.SetClip(nil)
+	empty := image.Rect(0, 0, 0, 0)
+	op.ClipRect = &empty
+	dst.DrawImage(src, op)
+
+	for j := 0; j < 16; j++ {
+		for i := 0; i < 16; i++ {
+			got := color.RGBAModel.Convert(dst.At(i, j)).(color.RGBA)
+			want := color.RGBA{}
+			if 8 <= i && i < 12 && 4 <= j && j < 12 {
+				want = color.RGBA{0xff, 0xff, 0xff, 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got %v, want: %v", i, j, got, want)
+			}
+			got = color.RGBAModel.Convert(dst2.At(i, j)).(color.RGBA)
+			want = color.RGBA{0xff, 0xff, 0xff, 0xff}
+			if got != want {
+				t.Errorf("dst2.At(%d, %d): got %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
 func BenchmarkDrawImage(b *testing.B) {
 	img0, _ := NewImage(16, 16, FilterNearest)
 	img1, _ := NewImage(16, 16, FilterNearest)
@@ -647,6 +953,52 @@ func TestImageLinear(t *testing.T) {
 	}
 }
 
+func TestImageAnisotropic(t *testing.T) {
+	// The source image has horizontal stripes: 2 white rows and 6 black rows in every 8 rows.
+	src, _ := NewImage(4, 64, FilterDefault)
+	pix := make([]byte, 4*4*64)
+	for j := 0; j < 64; j++ {
+		for i := 0; i < 4; i++ {
+			idx := 4 * (j*4 + i)
+			if j%8 < 2 {
+				pix[idx] = 0xff
+				pix[idx+1] = 0xff
+				pix[idx+2] = 0xff
+			}
+			pix[idx+3] = 0xff
+		}
+	}
+	src.ReplacePixels(pix)
+
+	cases := []struct {
+		Filter Filter
+		Want   uint8
+	}{
+		// Linear filter samples only the black rows at the centers of the destination pixels.
+		{FilterLinear, 0},
+		// Anisotropic filter averages all the 8 rows covered by a destination pixel.
+		{FilterAnisotropic8, 0x40},
+		{FilterAnisotropic16, 0x40},
+	}
+	for _, c := range cases {
+		dst, _ := NewImage(4, 8, FilterDefault)
+		op := &DrawImageOptions{}
+		op.GeoM.Scale(1, 1.0/8.0)
+		op.Filter = c.Filter
+		dst.DrawImage(src, op)
+
+		for j := 0; j < 8; j++ {
+			for i := 0; i < 4; i++ {
+				got := color.RGBAModel.Convert(dst.At(i, j)).(color.RGBA)
+				want := color.RGBA{c.Want, c.Want, c.Want, 0xff}
+				if !sameColors(got, want, 1) {
+					t.Errorf("filter %d: dst.At(%d, %d): got %v, want: %v", c.Filter, i, j, got, want)
+				}
+			}
+		}
+	}
+}
+
 func TestImageOutside(t *testing.T) {
 	src, _ := NewImage(5, 10, FilterNearest) // internal texture size is 8x16.
 	dst, _ := NewImage(4, 4, FilterNearest)
@@ -796,3 +1148,589 @@ func TestImageCopy(t *testing.T) {
 	img1 := *img0
 	img1.Fill(color.Transparent)
 }
+
+func TestImageAlpha8(t *testing.T) {
+	const w, h = 16, 16
+	mask, _ := NewImageWithFormat(w, h, PixelFormatAlpha8, FilterDefault)
+	if got, want := mask.Format(), PixelFormatAlpha8; got != want {
+		t.Errorf("Format(): got: %v, want: %v", got, want)
+	}
+	pix := make([]byte, w*h)
+	for i := range pix {
+		pix[i] = byte(i)
+	}
+	mask.ReplacePixels(pix)
+
+	dst, _ := NewImage(w, h, FilterDefault)
+	op := &DrawImageOptions{}
+	op.ColorM.Scale(1, 0, 0, 1)
+	dst.DrawImage(mask, op)
+
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			a := byte(i + j*w)
+			got := dst.At(i, j).(color.RGBA)
+			want := color.RGBA{a, 0, 0, a}
+			if !sameColors(got, want, 1) {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+			got = mask.At(i, j).(color.RGBA)
+			want = color.RGBA{a, a, a, a}
+			if got != want {
+				t.Errorf("mask.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	mask.Fill(color.RGBA{0x80, 0x80, 0x80, 0x80})
+	if got, want := mask.At(0, 0).(color.RGBA), (color.RGBA{0x80, 0x80, 0x80, 0x80}); got != want {
+		t.Errorf("mask.At(0, 0): got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageAlpha8DrawImage(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("DrawImage on an alpha-only image should panic")
+		}
+	}()
+
+	src, _ := NewImage(16, 16, FilterDefault)
+	dst, _ := NewImageWithFormat(16, 16, PixelFormatAlpha8, FilterDefault)
+	dst.DrawImage(src, nil)
+}
+
+func TestImageRGBA16F(t *testing.T) {
+	const w, h = 16, 16
+	src, _ := NewImageWithFormat(w, h, PixelFormatRGBA16F, FilterDefault)
+	if got, want := src.Format(), PixelFormatRGBA16F; got != want {
+		t.Errorf("Format(): got: %v, want: %v", got, want)
+	}
+
+	pix := make([]byte, PixelFormatRGBA16F.BytesPerPixel()*w*h)
+	for i := 0; i < w*h; i++ {
+		for c, v := range []float32{0.5, 0.25, 0, 1} {
+			b := emath.Float16bits(v)
+			pix[8*i+2*c] = byte(b)
+			pix[8*i+2*c+1] = byte(b >> 8)
+		}
+	}
+	src.ReplacePixels(pix)
+
+	dst, _ := NewImage(w, h, FilterDefault)
+	dst.DrawImage(src, nil)
+
+	want := color.RGBA{0x80, 0x40, 0, 0xff}
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			if !sameColors(got, want, 1) {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+			got = src.At(i, j).(color.RGBA)
+			if !sameColors(got, want, 1) {
+				t.Errorf("src.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageSRGB(t *testing.T) {
+	const w, h = 16, 16
+	dst, _ := NewImageWithFormat(w, h, PixelFormatSRGBA8, FilterDefault)
+	if got, want := dst.Format(), PixelFormatSRGBA8; got != want {
+		t.Errorf("Format(): got: %v, want: %v", got, want)
+	}
+
+	// The pixels are same as PixelFormatRGBA8's, including semi-transparent pixels.
+	pix := make([]byte, 4*w*h)
+	for i := 0; i < w*h; i++ {
+		copy(pix[4*i:], []byte{0x40, 0x20, 0x10, 0x80})
+	}
+	dst.ReplacePixels(pix)
+	if got, want := dst.At(0, 0).(color.RGBA), (color.RGBA{0x40, 0x20, 0x10, 0x80}); !sameColors(got, want, 1) {
+		t.Errorf("At(0, 0): got: %v, want: %v", got, want)
+	}
+
+	dst.Fill(color.RGBA{0x80, 0x40, 0x20, 0xff})
+	if got, want := dst.At(0, 0).(color.RGBA), (color.RGBA{0x80, 0x40, 0x20, 0xff}); !sameColors(got, want, 1) {
+		t.Errorf("At(0, 0) after Fill: got: %v, want: %v", got, want)
+	}
+
+	// Drawing an sRGB image on a regular image doesn't change the colors.
+	rgba, _ := NewImage(w, h, FilterDefault)
+	rgba.DrawImage(dst, nil)
+	if got, want := rgba.At(0, 0).(color.RGBA), (color.RGBA{0x80, 0x40, 0x20, 0xff}); !sameColors(got, want, 1) {
+		t.Errorf("rgba.At(0, 0): got: %v, want: %v", got, want)
+	}
+
+	// Blending is done in the linear color space.
+	dst.Fill(color.Black)
+	src, _ := NewImage(w, h, FilterDefault)
+	src.Fill(color.RGBA{0x80, 0x80, 0x80, 0x80})
+	dst.DrawImage(src, nil)
+	got := dst.At(0, 0).(color.RGBA)
+	if got.R == 0x80 {
+		t.Skip("sRGB textures are not available")
+	}
+	// The linear value 0.5 is 0xbc in sRGB.
+	if want := (color.RGBA{0xbc, 0xbc, 0xbc, 0xff}); !sameColors(got, want, 1) {
+		t.Errorf("At(0, 0) after blending: got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageDepth(t *testing.T) {
+	const w, h = 16, 16
+	dst, _ := NewImageWithDepth(w, h, FilterDefault)
+
+	red, _ := NewImage(w, h, FilterDefault)
+	red.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	green, _ := NewImage(w, h, FilterDefault)
+	green.Fill(color.RGBA{0, 0xff, 0, 0xff})
+
+	// The left half of blue is transparent.
+	blue, _ := NewImage(w, h, FilterDefault)
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := w / 2; i < w; i++ {
+			pix[4*(i+j*w)+2] = 0xff
+			pix[4*(i+j*w)+3] = 0xff
+		}
+	}
+	blue.ReplacePixels(pix)
+
+	op := &DrawImageOptions{}
+	op.Z = 0.5
+	dst.DrawImage(red, op)
+	op.Z = 0.75
+	dst.DrawImage(blue, op)
+	// green is behind red and blue.
+	op.Z = 0.25
+	dst.DrawImage(green, op)
+
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			want := color.RGBA{0xff, 0, 0, 0xff}
+			if i >= w/2 {
+				want = color.RGBA{0, 0, 0xff, 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	// Clear resets the depth buffer.
+	dst.Clear()
+	op.Z = 0
+	dst.DrawImage(green, op)
+	if got, want := dst.At(0, 0).(color.RGBA), (color.RGBA{0, 0xff, 0, 0xff}); got != want {
+		t.Errorf("dst.At(0, 0): got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageAddress(t *testing.T) {
+	const w, h = 4, 4
+	src, _ := NewImage(w, h, FilterDefault)
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			idx := 4 * (i + j*w)
+			pix[idx] = byte(0x40 * i)
+			pix[idx+1] = byte(0x40 * j)
+			pix[idx+3] = 0xff
+		}
+	}
+	src.ReplacePixels(pix)
+
+	mirror := func(v, size int) int {
+		v %= 2 * size
+		if v >= size {
+			return 2*size - 1 - v
+		}
+		return v
+	}
+	testCases := []struct {
+		address Address
+		index   func(i, j int) (int, int)
+	}{
+		{
+			address: AddressRepeat,
+			index: func(i, j int) (int, int) {
+				return i % w, j % h
+			},
+		},
+		{
+			address: AddressMirrorRepeat,
+			index: func(i, j int) (int, int) {
+				return mirror(i, w), mirror(j, h)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		dst, _ := NewImage(w*3, h*2, FilterDefault)
+		op := &DrawImageOptions{}
+		r := image.Rect(0, 0, w*3, h*2)
+		op.SourceRect = &r
+		op.Address = tc.address
+		dst.DrawImage(src, op)
+
+		for j := 0; j < h*2; j++ {
+			for i := 0; i < w*3; i++ {
+				got := dst.At(i, j).(color.RGBA)
+				si, sj := tc.index(i, j)
+				want := color.RGBA{byte(0x40 * si), byte(0x40 * sj), 0, 0xff}
+				if got != want {
+					t.Errorf("address: %d, At(%d, %d): got: %v, want: %v", tc.address, i, j, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestImageAddressClampToEdge(t *testing.T) {
+	src, _ := NewImage(1, 1, FilterDefault)
+	src.Fill(color.RGBA{0xff, 0, 0, 0xff})
+
+	for _, a := range []Address{AddressClampToZero, AddressClampToEdge} {
+		dst, _ := NewImage(4, 4, FilterDefault)
+		op := &DrawImageOptions{}
+		op.GeoM.Scale(4, 4)
+		op.Filter = FilterLinear
+		op.Address = a
+		dst.DrawImage(src, op)
+
+		got := dst.At(0, 0).(color.RGBA)
+		if a == AddressClampToEdge {
+			if want := (color.RGBA{0xff, 0, 0, 0xff}); got != want {
+				t.Errorf("address: %d, At(0, 0): got: %v, want: %v", a, got, want)
+			}
+			continue
+		}
+		// With AddressClampToZero, the edges are blended with transparent pixels.
+		if got.A == 0xff {
+			t.Errorf("address: %d, At(0, 0): got: %v, want: translucent", a, got)
+		}
+	}
+}
+
+func TestNewVolatileImage(t *testing.T) {
+	const w, h = 16, 16
+	img, _ := NewVolatileImage(w, h, FilterDefault)
+	if got := img.At(0, 0).(color.RGBA); got != (color.RGBA{}) {
+		t.Errorf("At(0, 0): got: %v, want: %v", got, color.RGBA{})
+	}
+
+	src, _ := NewImage(w, h, FilterDefault)
+	src.Fill(color.RGBA{0x80, 0x40, 0x20, 0xff})
+	img.DrawImage(src, nil)
+	if got, want := img.At(0, 0).(color.RGBA), (color.RGBA{0x80, 0x40, 0x20, 0xff}); got != want {
+		t.Errorf("At(0, 0): got: %v, want: %v", got, want)
+	}
+
+	// A volatile image can be a source.
+	dst, _ := NewImage(w, h, FilterDefault)
+	dst.DrawImage(img, nil)
+	if got, want := dst.At(0, 0).(color.RGBA), (color.RGBA{0x80, 0x40, 0x20, 0xff}); got != want {
+		t.Errorf("dst.At(0, 0): got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageDrawImages(t *testing.T) {
+	const w, h = 16, 16
+	pix := make([]byte, 4*4*4)
+	for i := 0; i < 4*4; i++ {
+		pix[4*i] = byte(i * 0x10)
+		pix[4*i+1] = byte(0xff - i*0x10)
+		pix[4*i+2] = 0x80
+		pix[4*i+3] = 0xff
+	}
+	src, _ := NewImage(4, 4, FilterDefault)
+	src.ReplacePixels(pix)
+
+	ops := make([]DrawImageOptions, 6)
+	for i := range ops {
+		ops[i].GeoM.Translate(float64(i*2), float64(i))
+	}
+	ops[1].ColorM.Scale(0.5, 1, 1, 1)
+	ops[2].ColorM.Translate(0, 0, 0.25, 0)
+	// A full color matrix splits the batch.
+	ops[3].ColorM.ChangeHSV(1, 0.5, 1)
+	ops[4].SourceRect = &image.Rectangle{image.Pt(1, 1), image.Pt(3, 3)}
+	ops[5].CompositeMode = CompositeModeLighter
+
+	dst0, _ := NewImage(w, h, FilterDefault)
+	dst0.DrawImages(src, ops)
+
+	dst1, _ := NewImage(w, h, FilterDefault)
+	for i := range ops {
+		dst1.DrawImage(src, &ops[i])
+	}
+
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst0.At(i, j)
+			want := dst1.At(i, j)
+			if got != want {
+				t.Errorf("dst0.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageParts(t *testing.T) {
+	pix := make([]byte, 4*4*4)
+	for i := 0; i < 4*4; i++ {
+		if i%4 < 2 {
+			pix[4*i] = 0xff
+		} else {
+			pix[4*i+1] = 0xff
+		}
+		pix[4*i+3] = 0xff
+	}
+	src, _ := NewImage(4, 4, FilterDefault)
+	src.ReplacePixels(pix)
+
+	// Draw the red half at (0, 0) - (4, 4) and the green half at (8, 0) - (10, 4).
+	parts := []ImagePart{
+		{Dst: image.Rect(0, 0, 4, 4), Src: image.Rect(0, 0, 2, 4)},
+		{Dst: image.Rect(8, 0, 10, 4), Src: image.Rect(2, 0, 4, 4)},
+	}
+	dst0, _ := NewImage(16, 16, FilterDefault)
+	op := &DrawImageOptions{}
+	op.GeoM.Translate(0, 2)
+	op.Parts = parts
+	dst0.DrawImage(src, op)
+
+	dst1, _ := NewImage(16, 16, FilterDefault)
+	op = &DrawImageOptions{}
+	op.GeoM.Translate(0, 2)
+	op.ImageParts = testImageParts(parts)
+	dst1.DrawImage(src, op)
+
+	for j := 0; j < 16; j++ {
+		for i := 0; i < 16; i++ {
+			var want color.RGBA
+			switch {
+			case j < 2 || 6 <= j:
+			case i < 4:
+				want = color.RGBA{0xff, 0, 0, 0xff}
+			case 8 <= i && i < 10:
+				want = color.RGBA{0, 0xff, 0, 0xff}
+			}
+			if got := dst0.At(i, j); got != want {
+				t.Errorf("Parts: At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+			if got := dst1.At(i, j); got != want {
+				t.Errorf("ImageParts: At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+type testImageParts []ImagePart
+
+func (p testImageParts) Len() int {
+	return len(p)
+}
+
+func (p testImageParts) Dst(i int) (x0, y0, x1, y1 int) {
+	r := p[i].Dst
+	return r.Min.X, r.Min.Y, r.Max.X, r.Max.Y
+}
+
+func (p testImageParts) Src(i int) (x0, y0, x1, y1 int) {
+	r := p[i].Src
+	return r.Min.X, r.Min.Y, r.Max.X, r.Max.Y
+}
+
+type testImagePartsWithCompositeMode struct {
+	testImageParts
+	defaultMode CompositeMode
+}
+
+func (p testImagePartsWithCompositeMode) CompositeMode(i int) CompositeMode {
+	if m := p.testImageParts[i].CompositeMode; m != nil {
+		return *m
+	}
+	return p.defaultMode
+}
+
+func TestImagePartsCompositeMode(t *testing.T) {
+	src, _ := NewImage(4, 4, FilterDefault)
+	src.Fill(color.RGBA{0x80, 0, 0, 0x80})
+
+	lighter := CompositeModeLighter
+	parts := []ImagePart{
+		{Dst: image.Rect(0, 0, 4, 4), Src: image.Rect(0, 0, 4, 4), CompositeMode: &lighter},
+		{Dst: image.Rect(8, 0, 12, 4), Src: image.Rect(0, 0, 4, 4)},
+	}
+	bg := color.RGBA{0, 0, 0x80, 0xff}
+	dst0, _ := NewImage(16, 4, FilterDefault)
+	dst0.Fill(bg)
+	op := &DrawImageOptions{}
+	op.CompositeMode = CompositeModeCopy
+	op.Parts = parts
+	dst0.DrawImage(src, op)
+
+	dst1, _ := NewImage(16, 4, FilterDefault)
+	dst1.Fill(bg)
+	op = &DrawImageOptions{}
+	op.ImageParts = testImagePartsWithCompositeMode{testImageParts(parts), CompositeModeCopy}
+	dst1.DrawImage(src, op)
+
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 16; i++ {
+			want := bg
+			switch {
+			case i < 4:
+				want = color.RGBA{0x80, 0, 0x80, 0xff}
+			case 8 <= i && i < 12:
+				want = color.RGBA{0x80, 0, 0, 0x80}
+			}
+			if got := dst0.At(i, j).(color.RGBA); !sameColors(got, want, 1) {
+				t.Errorf("Parts: At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+			if got := dst1.At(i, j).(color.RGBA); !sameColors(got, want, 1) {
+				t.Errorf("ImageParts: At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestNewImageFromCompressedData(t *testing.T) {
+	// A BC3 block of a solid color: the alpha endpoints, the alpha indices,
+	// the RGB565 color endpoints and the color indices.
+	block := func(c uint16) []byte {
+		return []byte{0xff, 0xff, 0, 0, 0, 0, 0, 0, byte(c), byte(c >> 8), byte(c), byte(c >> 8), 0, 0, 0, 0}
+	}
+	// The width is not a multiple of the block size.
+	const w, h = 6, 4
+	data := append(block(0xf800), block(0x001f)...)
+
+	if _, err := NewImageFromCompressedData(w, h, PixelFormatRGBA8, make([]byte, 4*w*h), FilterDefault); err == nil {
+		t.Errorf("NewImageFromCompressedData with an uncompressed format must return an error")
+	}
+	if !IsPixelFormatAvailable(PixelFormatBC3) {
+		t.Skip("PixelFormatBC3 is not available")
+	}
+	if _, err := NewImageFromCompressedData(w, h, PixelFormatBC3, data[:16], FilterDefault); err == nil {
+		t.Errorf("NewImageFromCompressedData with too short data must return an error")
+	}
+	src, err := NewImageFromCompressedData(w, h, PixelFormatBC3, data, FilterDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := src.Format(), PixelFormatBC3; got != want {
+		t.Errorf("Format(): got: %v, want: %v", got, want)
+	}
+
+	dst, _ := NewImage(w, h, FilterDefault)
+	dst.DrawImage(src, nil)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			want := color.RGBA{0xff, 0, 0, 0xff}
+			if i >= 4 {
+				want = color.RGBA{0, 0, 0xff, 0xff}
+			}
+			if got := dst.At(i, j); got != want {
+				t.Errorf("At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageSnapshot(t *testing.T) {
+	img, _ := NewImage(4, 4, FilterDefault)
+	img.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	s, err := img.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img.Fill(color.RGBA{0, 0xff, 0, 0xff})
+	img.RestoreSnapshot(s)
+	if got, want := img.At(1, 1), (color.RGBA{0xff, 0, 0, 0xff}); got != want {
+		t.Errorf("At(1, 1) after RestoreSnapshot: got: %v, want: %v", got, want)
+	}
+
+	// A snapshot can be restored many times.
+	img.Fill(color.RGBA{0, 0, 0xff, 0xff})
+	img.RestoreSnapshot(s)
+	if got, want := img.At(2, 2), (color.RGBA{0xff, 0, 0, 0xff}); got != want {
+		t.Errorf("At(2, 2) after the second RestoreSnapshot: got: %v, want: %v", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RestoreSnapshot with a snapshot of a different size must panic")
+		}
+	}()
+	img2, _ := NewImage(2, 2, FilterDefault)
+	img2.RestoreSnapshot(s)
+}
+
+func TestImageResolvePixels(t *testing.T) {
+	src, _ := NewImage(4, 4, FilterDefault)
+	src.Fill(color.RGBA{0x80, 0, 0, 0x80})
+
+	var imgs []*Image
+	for i := 0; i < 4; i++ {
+		img, _ := NewImage(4, 4, FilterDefault)
+		op := &DrawImageOptions{}
+		op.GeoM.Translate(float64(i), 0)
+		img.DrawImage(src, op)
+		imgs = append(imgs, img)
+	}
+	disposed, _ := NewImage(1, 1, FilterDefault)
+	disposed.Dispose()
+
+	if err := ResolvePixels(append(imgs, disposed)); err != nil {
+		t.Fatal(err)
+	}
+	for k, img := range imgs {
+		for i := 0; i < 4; i++ {
+			want := color.RGBA{}
+			if i >= k {
+				want = color.RGBA{0x80, 0, 0, 0x80}
+			}
+			if got := img.At(i, 0).(color.RGBA); !sameColors(got, want, 1) {
+				t.Errorf("imgs[%d].At(%d, 0): got: %v, want: %v", k, i, got, want)
+			}
+		}
+	}
+}
+
+func TestImagePixelCache(t *testing.T) {
+	img, _ := NewImage(2, 2, FilterDefault)
+	img.ReplacePixels(make([]byte, 4*2*2))
+	if !img.IsPixelCacheValid() {
+		t.Errorf("the pixel cache must be valid after ReplacePixels")
+	}
+	img.Fill(color.RGBA{0, 0xff, 0, 0xff})
+	if img.IsPixelCacheValid() {
+		t.Errorf("the pixel cache must be invalid after Fill")
+	}
+	want := color.RGBA{0, 0xff, 0, 0xff}
+	if got := img.At(0, 0).(color.RGBA); !sameColors(got, want, 1) {
+		t.Errorf("At(0, 0): got: %v, want: %v", got, want)
+	}
+	if !img.IsPixelCacheValid() {
+		t.Errorf("the pixel cache must be valid after At")
+	}
+
+	img.InvalidatePixelCache()
+	if img.IsPixelCacheValid() {
+		t.Errorf("the pixel cache must be invalid after InvalidatePixelCache")
+	}
+	if got := img.At(1, 1).(color.RGBA); !sameColors(got, want, 1) {
+		t.Errorf("At(1, 1): got: %v, want: %v", got, want)
+	}
+
+	img.Dispose()
+	if img.IsPixelCacheValid() {
+		t.Errorf("the pixel cache of a disposed image must be invalid")
+	}
+	img.InvalidatePixelCache()
+}
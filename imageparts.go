@@ -18,32 +18,78 @@ import (
 	"image"
 )
 
-// An ImagePart is deprecated (as of 1.1.0-alpha): Use SourceRect instead.
+// An ImagePart is deprecated (as of 1.1.0-alpha): Use SourceRect and DrawImages instead.
 type ImagePart struct {
 	Dst image.Rectangle
 	Src image.Rectangle
+
+	// CompositeMode is the composite mode to draw the part.
+	// If CompositeMode is nil, the CompositeMode of DrawImageOptions is used.
+	//
+	// The successive parts of the same composite mode are drawn at once, so parts of different composite modes,
+	// e.g. additive and alpha-blended particles, can be drawn by one DrawImage call.
+	CompositeMode *CompositeMode
 }
 
-// An ImageParts is deprecated (as of 1.5.0-alpha): Use SourceRect instead.
+// An ImageParts is deprecated (as of 1.5.0-alpha): Use SourceRect and DrawImages instead.
 type ImageParts interface {
 	Len() int
 	Dst(i int) (x0, y0, x1, y1 int)
 	Src(i int) (x0, y0, x1, y1 int)
 }
 
-// NOTE: Remove this in the future.
-type imageParts []ImagePart
-
-func (p imageParts) Len() int {
-	return len(p)
+// ImagePartsWithCompositeMode is an ImageParts that specifies the composite mode of each part.
+//
+// When ImageParts of DrawImageOptions implements ImagePartsWithCompositeMode, CompositeMode of each part is used
+// instead of CompositeMode of DrawImageOptions. See also ImagePart's CompositeMode.
+type ImagePartsWithCompositeMode interface {
+	ImageParts
+	CompositeMode(i int) CompositeMode
 }
 
-func (p imageParts) Dst(i int) (x0, y0, x1, y1 int) {
-	dst := &p[i].Dst
-	return dst.Min.X, dst.Min.Y, dst.Max.X, dst.Max.Y
+// partOptions returns the options to draw each of the deprecated parts of options.
+// The parts of a Parts slice are read directly without the ImageParts interface.
+func partOptions(options *DrawImageOptions) []DrawImageOptions {
+	if parts := options.ImageParts; parts != nil {
+		modes, _ := parts.(ImagePartsWithCompositeMode)
+		ops := make([]DrawImageOptions, parts.Len())
+		for idx := range ops {
+			sx0, sy0, sx1, sy1 := parts.Src(idx)
+			dx0, dy0, dx1, dy1 := parts.Dst(idx)
+			mode := options.CompositeMode
+			if modes != nil {
+				mode = modes.CompositeMode(idx)
+			}
+			setPartOptions(&ops[idx], options, mode, sx0, sy0, sx1, sy1, dx0, dy0, dx1, dy1)
+		}
+		return ops
+	}
+	ops := make([]DrawImageOptions, len(options.Parts))
+	for idx := range options.Parts {
+		src := &options.Parts[idx].Src
+		dst := &options.Parts[idx].Dst
+		mode := options.CompositeMode
+		if m := options.Parts[idx].CompositeMode; m != nil {
+			mode = *m
+		}
+		setPartOptions(&ops[idx], options, mode, src.Min.X, src.Min.Y, src.Max.X, src.Max.Y, dst.Min.X, dst.Min.Y, dst.Max.X, dst.Max.Y)
+	}
+	return ops
 }
 
-func (p imageParts) Src(i int) (x0, y0, x1, y1 int) {
-	src := &p[i].Src
-	return src.Min.X, src.Min.Y, src.Max.X, src.Max.Y
+func setPartOptions(op *DrawImageOptions, options *DrawImageOptions, mode CompositeMode, sx0, sy0, sx1, sy1, dx0, dy0, dx1, dy1 int) {
+	op.ColorM = options.ColorM
+	op.CompositeMode = mode
+	op.Filter = options.Filter
+	op.ClipRect = options.ClipRect
+	op.DisabledChannels = options.DisabledChannels
+	op.Address = options.Address
+	op.Z = options.Z
+	r := image.Rect(sx0, sy0, sx1, sy1)
+	op.SourceRect = &r
+	op.GeoM.Scale(
+		float64(dx1-dx0)/float64(sx1-sx0),
+		float64(dy1-dy0)/float64(sy1-sy0))
+	op.GeoM.Translate(float64(dx0), float64(dy0))
+	op.GeoM.Concat(options.GeoM)
 }
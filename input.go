@@ -29,6 +29,9 @@ import (
 //
 // This function is concurrent-safe.
 func InputChars() []rune {
+	if s := input.OverridingState(); s != nil {
+		return append(make([]rune, 0, len(s.Runes)), s.Runes...)
+	}
 	rb := input.Get().RuneBuffer()
 	return append(make([]rune, 0, len(rb)), rb...)
 }
@@ -42,14 +45,22 @@ func InputChars() []rune {
 //
 // This function is concurrent-safe.
 func IsKeyPressed(key Key) bool {
+	if s := input.OverridingState(); s != nil {
+		return s.IsKeyPressed(input.Key(key))
+	}
 	return input.Get().IsKeyPressed(input.Key(key))
 }
 
 // CursorPosition returns a position of a mouse cursor.
 //
+// The position is on the screen laid out by the layout policy and ScreenFilterInteger. See SetLayoutPolicy.
+//
 // This function is concurrent-safe.
 func CursorPosition() (x, y int) {
-	return ui.AdjustedCursorPosition()
+	if s := input.OverridingState(); s != nil {
+		return s.CursorX, s.CursorY
+	}
+	return adjustScreenPosition(ui.AdjustedCursorPosition())
 }
 
 // IsMouseButtonPressed returns a boolean indicating whether mouseButton is pressed.
@@ -59,6 +70,9 @@ func CursorPosition() (x, y int) {
 // Note that touch events not longer affect this function's result as of 1.4.0-alpha.
 // Use Touches instead.
 func IsMouseButtonPressed(mouseButton MouseButton) bool {
+	if s := input.OverridingState(); s != nil {
+		return s.IsMouseButtonPressed(input.MouseButton(mouseButton))
+	}
 	return input.Get().IsMouseButtonPressed(input.MouseButton(mouseButton))
 }
 
@@ -68,6 +82,9 @@ func IsMouseButtonPressed(mouseButton MouseButton) bool {
 //
 // This function always returns an empty slice on mobiles.
 func GamepadIDs() []int {
+	if s := input.OverridingState(); s != nil {
+		return s.GamepadIDs()
+	}
 	return input.Get().GamepadIDs()
 }
 
@@ -77,6 +94,9 @@ func GamepadIDs() []int {
 //
 // This function always returns 0 on mobiles.
 func GamepadAxisNum(id int) int {
+	if s := input.OverridingState(); s != nil {
+		return s.GamepadAxisNum(id)
+	}
 	return input.Get().GamepadAxisNum(id)
 }
 
@@ -86,6 +106,9 @@ func GamepadAxisNum(id int) int {
 //
 // This function always returns 0 on mobiles.
 func GamepadAxis(id int, axis int) float64 {
+	if s := input.OverridingState(); s != nil {
+		return s.GamepadAxis(id, axis)
+	}
 	return input.Get().GamepadAxis(id, axis)
 }
 
@@ -95,9 +118,67 @@ func GamepadAxis(id int, axis int) float64 {
 //
 // This function always returns 0 on mobiles.
 func GamepadButtonNum(id int) int {
+	if s := input.OverridingState(); s != nil {
+		return s.GamepadButtonNum(id)
+	}
 	return input.Get().GamepadButtonNum(id)
 }
 
+// GamepadName returns the name of the gamepad (id).
+//
+// GamepadName returns an empty string when the gamepad is not available.
+//
+// This function is concurrent-safe.
+//
+// This function always returns an empty string on mobiles.
+func GamepadName(id int) string {
+	if s := input.OverridingState(); s != nil {
+		return s.GamepadName(id)
+	}
+	return input.Get().GamepadName(id)
+}
+
+// GamepadVendorID returns the USB vendor ID of the gamepad (id).
+//
+// GamepadVendorID returns 0 when the gamepad is not available or the vendor ID is unknown.
+//
+// This function is concurrent-safe.
+//
+// This function is available only on browsers that report the IDs, e.g. Chrome and Firefox.
+// This function always returns 0 on the other platforms.
+func GamepadVendorID(id int) int {
+	if s := input.OverridingState(); s != nil {
+		return s.GamepadVendorID(id)
+	}
+	return input.Get().GamepadVendorID(id)
+}
+
+// GamepadProductID returns the USB product ID of the gamepad (id).
+//
+// GamepadProductID returns 0 when the gamepad is not available or the product ID is unknown.
+//
+// This function is concurrent-safe.
+//
+// This function is available only on browsers that report the IDs, e.g. Chrome and Firefox.
+// This function always returns 0 on the other platforms.
+func GamepadProductID(id int) int {
+	if s := input.OverridingState(); s != nil {
+		return s.GamepadProductID(id)
+	}
+	return input.Get().GamepadProductID(id)
+}
+
+// GamepadGUID returns the GUID of the gamepad (id) in the format of SDL's game controller database,
+// e.g. "030000004c050000c405000000000000".
+//
+// The GUID is made from the vendor and the product IDs, and can be used as a key to remap the buttons per device.
+// GamepadGUID returns an empty string when the IDs are unknown.
+//
+// This function is concurrent-safe.
+func GamepadGUID(id int) string {
+	return input.GamepadGUID(GamepadVendorID(id), GamepadProductID(id))
+}
+
 // IsGamepadButtonPressed returns the boolean indicating the given button of the gamepad (id) is pressed or not.
 //
 // This function is concurrent-safe.
@@ -108,6 +189,9 @@ func GamepadButtonNum(id int) int {
 //
 // This function always returns false on mobiles.
 func IsGamepadButtonPressed(id int, button GamepadButton) bool {
+	if s := input.OverridingState(); s != nil {
+		return s.IsGamepadButtonPressed(id, input.GamepadButton(button))
+	}
 	return input.Get().IsGamepadButtonPressed(id, input.GamepadButton(button))
 }
 
@@ -124,11 +208,21 @@ type Touch interface {
 //
 // Touches returns nil when there are no touches.
 // Touches always returns nil on desktops.
+//
+// The positions are on the screen laid out by the layout policy and ScreenFilterInteger. See SetLayoutPolicy.
 func Touches() []Touch {
+	if s := input.OverridingState(); s != nil {
+		var ts []Touch
+		for _, t := range s.Touches {
+			ts = append(ts, input.NewTouch(t.ID, t.X, t.Y))
+		}
+		return ts
+	}
 	touches := ui.AdjustedTouches()
 	var copies []Touch
 	for _, touch := range touches {
-		copies = append(copies, touch)
+		x, y := adjustScreenPosition(touch.Position())
+		copies = append(copies, input.NewTouch(touch.ID(), x, y))
 	}
 	return copies
 }
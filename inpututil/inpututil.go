@@ -18,6 +18,8 @@
 package inpututil
 
 import (
+	"sort"
+
 	"github.com/hajimehoshi/ebiten"
 	"github.com/hajimehoshi/ebiten/internal/hooks"
 	"github.com/hajimehoshi/ebiten/internal/sync"
@@ -33,6 +35,9 @@ type inputState struct {
 	gamepadButtonStates     map[int]map[ebiten.GamepadButton]int
 	prevGamepadButtonStates map[int]map[ebiten.GamepadButton]int
 
+	gamepadIDs     map[int]struct{}
+	prevGamepadIDs map[int]struct{}
+
 	touchStates     map[int]int
 	prevTouchStates map[int]int
 
@@ -49,6 +54,9 @@ var theInputState = &inputState{
 	gamepadButtonStates:     map[int]map[ebiten.GamepadButton]int{},
 	prevGamepadButtonStates: map[int]map[ebiten.GamepadButton]int{},
 
+	gamepadIDs:     map[int]struct{}{},
+	prevGamepadIDs: map[int]struct{}{},
+
 	touchStates:     map[int]int{},
 	prevTouchStates: map[int]int{},
 }
@@ -126,6 +134,8 @@ func (i *inputState) update() {
 	for _, id := range idsToDelete {
 		delete(i.gamepadButtonStates, id)
 	}
+	i.prevGamepadIDs = i.gamepadIDs
+	i.gamepadIDs = ids
 
 	// Touches
 	ids = map[int]struct{}{}
@@ -249,6 +259,34 @@ func GamepadButtonPressDuration(id int, button ebiten.GamepadButton) int {
 	return s
 }
 
+// JustConnectedGamepadIDs returns gamepad IDs that are connected just in the current frame.
+//
+// JustConnectedGamepadIDs is concurrent safe.
+func JustConnectedGamepadIDs() []int {
+	ids := []int{}
+	theInputState.m.RLock()
+	for id := range theInputState.gamepadIDs {
+		if _, ok := theInputState.prevGamepadIDs[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	theInputState.m.RUnlock()
+	sort.Ints(ids)
+	return ids
+}
+
+// IsGamepadJustDisconnected returns a boolean value indicating
+// whether the gamepad of the given id is disconnected just in the current frame.
+//
+// IsGamepadJustDisconnected is concurrent safe.
+func IsGamepadJustDisconnected(id int) bool {
+	theInputState.m.RLock()
+	_, prev := theInputState.prevGamepadIDs[id]
+	_, current := theInputState.gamepadIDs[id]
+	theInputState.m.RUnlock()
+	return prev && !current
+}
+
 // JustPressedTouches returns touch IDs that are created just in the current frame.
 //
 // JustPressedTouches is concurrent safe.
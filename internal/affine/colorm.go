@@ -101,6 +101,25 @@ func (c *ColorM) UnsafeElements() ([]float32, []float32) {
 	return c.body, c.translate
 }
 
+// Tint returns the scale and the translation of each color component when the matrix consists of
+// only scaling and translation, i.e. all the non-diagonal elements of the body are 0.
+// Otherwise, Tint returns false.
+func (c *ColorM) Tint() (scale, translate [4]float32, ok bool) {
+	if !c.isInited() {
+		return [4]float32{1, 1, 1, 1}, [4]float32{}, true
+	}
+	for i, e := range c.body {
+		if i%(ColorMDim-1) != i/(ColorMDim-1) && e != 0 {
+			return [4]float32{}, [4]float32{}, false
+		}
+	}
+	for i := 0; i < ColorMDim-1; i++ {
+		scale[i] = c.body[i*ColorMDim]
+		translate[i] = c.translate[i]
+	}
+	return scale, translate, true
+}
+
 // SetElement sets an element at (i, j).
 func (c *ColorM) SetElement(i, j int, element float32) *ColorM {
 	newC := &ColorM{
@@ -25,6 +25,7 @@
 package clock
 
 import (
+	"math"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/internal/sync"
@@ -40,9 +41,20 @@ var (
 	// lastSystemTime is the last system time in the previous Update.
 	lastSystemTime int64
 
+	// pacedTime is the system time at the last Update snapped to the display's frame interval.
+	pacedTime int64
+
 	currentFPS     float64
+	currentTPS     float64
 	lastFPSUpdated int64
 	framesForFPS   int64
+	ticksForTPS    int64
+
+	// frameProgress is the elapsed time since the last game frame in frames at the last Update.
+	frameProgress float64
+
+	// deterministic indicates whether the game frames strictly follow the system clock.
+	deterministic bool
 
 	ping func()
 
@@ -56,6 +68,42 @@ func CurrentFPS() float64 {
 	return v
 }
 
+// CurrentTPS returns the current number of game updates (ticks) per second.
+func CurrentTPS() float64 {
+	m.Lock()
+	v := currentTPS
+	m.Unlock()
+	return v
+}
+
+// FrameProgress returns the elapsed time since the last game frame in frames, in [0, 1).
+//
+// FrameProgress is updated at Update.
+func FrameProgress() float64 {
+	m.Lock()
+	v := frameProgress
+	m.Unlock()
+	return v
+}
+
+// SetDeterministic sets whether the game frames strictly follow the system clock.
+//
+// In the deterministic mode, the audio clock is not used, and the game frames are never dropped even when the
+// game is behind the system clock: the delayed frames are caught up gradually at the next Updates.
+func SetDeterministic(enabled bool) {
+	m.Lock()
+	deterministic = enabled
+	m.Unlock()
+}
+
+// IsDeterministic reports whether the deterministic mode is enabled.
+func IsDeterministic() bool {
+	m.Lock()
+	v := deterministic
+	m.Unlock()
+	return v
+}
+
 func RegisterPing(pingFunc func()) {
 	m.Lock()
 	ping = pingFunc
@@ -69,26 +117,77 @@ func ProceedAudioTimer(num int64) {
 	m.Unlock()
 }
 
-func updateFPS(now int64) {
+func updateFPSAndTPS(now int64, count int) {
 	if lastFPSUpdated == 0 {
 		lastFPSUpdated = now
 	}
 	framesForFPS++
+	ticksForTPS += int64(count)
 	if time.Second > time.Duration(now-lastFPSUpdated) {
 		return
 	}
 	currentFPS = float64(framesForFPS) * float64(time.Second) / float64(now-lastFPSUpdated)
+	currentTPS = float64(ticksForTPS) * float64(time.Second) / float64(now-lastFPSUpdated)
 	lastFPSUpdated = now
 	framesForFPS = 0
+	ticksForTPS = 0
+}
+
+func updateFrameProgress(now int64) {
+	// lastSystemTime is the time of the last game frame, which might be slightly ahead of now
+	// due to the FPS stabilization.
+	p := float64(now-lastSystemTime) * FPS / float64(time.Second)
+	if p < 0 {
+		p = 0
+	}
+	if p >= 1 {
+		// The game frames are behind the system clock. This happens e.g. when the game is too slow.
+		// Don't extrapolate the states beyond the next frame.
+		p = math.Nextafter(1, 0)
+	}
+	frameProgress = p
+}
+
+// pace returns the time now snapped to the frame interval of the display with the given refresh rate.
+//
+// The rendering frames are presented at the display's frame interval, but the system time measured at each frame
+// has jitter. Without pacing, the number of game frames and the frame progress can be unstable like 0, 2, 0, 2, ...
+// pace absorbs the jitter by advancing the time by multiples of the frame interval, while slowly converging to the
+// system time so that the error doesn't accumulate when the actual refresh rate is slightly different, e.g. 59.94 Hz.
+//
+// If refreshRate is 0, e.g. when the refresh rate is unknown or vsync is disabled, pace returns now as it is.
+func pace(now int64, refreshRate int) int64 {
+	if refreshRate <= 0 || pacedTime == 0 {
+		pacedTime = now
+		return now
+	}
+	interval := int64(time.Second) / int64(refreshRate)
+	diff := now - pacedTime
+	if diff < -interval || diff > 5*interval {
+		// The time is too far from the paced time, e.g. after the game is paused.
+		pacedTime = now
+		return now
+	}
+	k := (diff + interval/2) / interval
+	if k < 0 {
+		k = 0
+	}
+	t := pacedTime + k*interval
+	pacedTime = t + (now-t)/8
+	return pacedTime
 }
 
 // Update updates the inner clock state and returns an integer value
 // indicating how many game frames the game should update.
-func Update() int {
+//
+// refreshRate is the refresh rate of the display in Hz, which is used for the frame pacing.
+// refreshRate should be 0 when the refresh rate is unknown or the frames are not synced with the display.
+func Update(refreshRate int) int {
 	m.Lock()
 	defer m.Unlock()
 
-	n := now()
+	sysNow := now()
+	n := pace(sysNow, refreshRate)
 
 	if ping != nil {
 		ping()
@@ -107,7 +206,10 @@ func Update() int {
 	count := 0
 	syncWithSystemClock := false
 
-	if audioTimeInFrames > 0 && lastAudioTimeInFrames != audioTimeInFrames {
+	// maxCount is the maximum number of the game frames at one Update.
+	const maxCount = 5
+
+	if !deterministic && audioTimeInFrames > 0 && lastAudioTimeInFrames != audioTimeInFrames {
 		// If the audio clock is updated, use this.
 		if frames < audioTimeInFrames {
 			count = int(audioTimeInFrames - frames)
@@ -122,12 +224,16 @@ func Update() int {
 		// As the audio clock can be updated discountinuously,
 		// the system clock is still needed.
 
-		if diff > 5*int64(time.Second)/FPS {
+		if !deterministic && diff > maxCount*int64(time.Second)/FPS {
 			// The previous time is too old.
 			// Let's force to sync the game time with the system clock.
 			syncWithSystemClock = true
 		} else {
 			count = int(diff * FPS / int64(time.Second))
+			if count > maxCount {
+				// This happens only in the deterministic mode. The rest is caught up at the next Updates.
+				count = maxCount
+			}
 		}
 	}
 
@@ -147,7 +253,8 @@ func Update() int {
 		lastSystemTime += int64(count) * int64(time.Second) / FPS
 	}
 
-	updateFPS(n)
+	updateFPSAndTPS(sysNow, count)
+	updateFrameProgress(n)
 
 	return count
 }
@@ -17,11 +17,11 @@
 package devicescale
 
 import (
-	"github.com/gopherjs/gopherjs/js"
+	"github.com/hajimehoshi/ebiten/internal/js"
 )
 
 func impl() float64 {
-	ratio := js.Global.Get("window").Get("devicePixelRatio").Float()
+	ratio := js.Global().Get("window").Get("devicePixelRatio").Float()
 	if ratio == 0 {
 		ratio = 1
 	}
@@ -0,0 +1,101 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package distancefield offers a generator of signed distance fields.
+package distancefield
+
+import (
+	"image"
+	"math"
+)
+
+// Generate returns a signed distance field of the shape of src.
+//
+// A pixel of src is inside the shape when its alpha value is half or more.
+// The alpha value a of a pixel of the result represents the signed distance (a - 0.5) * spread from the edge
+// in pixels, positive inside.
+//
+// The bounds of the result are the bounds of src extended by spread / 2 on each side so that
+// the distances outside the shape fit.
+func Generate(src image.Image, spread int) *image.Alpha {
+	pad := spread / 2
+	sb := src.Bounds()
+	b := sb.Inset(-pad)
+	w, h := b.Dx(), b.Dy()
+
+	inside := make([]bool, w*h)
+	for j := sb.Min.Y; j < sb.Max.Y; j++ {
+		for i := sb.Min.X; i < sb.Max.X; i++ {
+			_, _, _, a := src.At(i, j).RGBA()
+			inside[(j-b.Min.Y)*w+(i-b.Min.X)] = a >= 0x8000
+		}
+	}
+
+	dst := image.NewAlpha(b)
+	r := pad + 1
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			in := inside[j*w+i]
+
+			// Search the nearest pixel of the opposite state in the window.
+			min2 := r * r
+			for y := j - r; y <= j+r; y++ {
+				if y < 0 || y >= h {
+					// Out of the bounds is outside.
+					if in {
+						if dy := y - j; dy*dy < min2 {
+							min2 = dy * dy
+						}
+					}
+					continue
+				}
+				dy := y - j
+				if dy*dy >= min2 {
+					continue
+				}
+				for x := i - r; x <= i+r; x++ {
+					dx := x - i
+					d2 := dx*dx + dy*dy
+					if d2 >= min2 {
+						continue
+					}
+					if x < 0 || x >= w {
+						if in {
+							min2 = d2
+						}
+						continue
+					}
+					if inside[y*w+x] != in {
+						min2 = d2
+					}
+				}
+			}
+
+			// The edge is at the middle of the pixels.
+			d := math.Sqrt(float64(min2)) - 0.5
+			if !in {
+				d = -d
+			}
+			v := 0.5 + d/float64(spread)
+			if v < 0 {
+				v = 0
+			}
+			if v > 1 {
+				v = 1
+			}
+			dst.Pix[j*dst.Stride+i] = uint8(math.Floor(v*0xff + 0.5))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distancefield_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/internal/distancefield"
+)
+
+func TestGenerate(t *testing.T) {
+	// A 4x4 square at (4, 4) in an 12x12 image.
+	src := image.NewAlpha(image.Rect(0, 0, 12, 12))
+	for j := 4; j < 8; j++ {
+		for i := 4; i < 8; i++ {
+			src.SetAlpha(i, j, color.Alpha{0xff})
+		}
+	}
+
+	const spread = 8
+	dst := Generate(src, spread)
+	if got, want := dst.Bounds(), image.Rect(-4, -4, 16, 16); got != want {
+		t.Errorf("Bounds(): got: %v, want: %v", got, want)
+	}
+
+	cases := []struct {
+		X, Y int
+		Want uint8
+	}{
+		// Inside, next to the edge: 0.5 + 0.5/8
+		{4, 5, 0x8f},
+		// Outside, next to the edge: 0.5 - 0.5/8
+		{3, 5, 0x70},
+		// The center: 0.5 + 1.5/8
+		{5, 5, 0xaf},
+		// Far outside
+		{-4, -4, 0},
+	}
+	for _, c := range cases {
+		if got := dst.AlphaAt(c.X, c.Y).A; got != c.Want {
+			t.Errorf("AlphaAt(%d, %d): got: %#x, want: %#x", c.X, c.Y, got, c.Want)
+		}
+	}
+
+	// The distance field is symmetric.
+	for j := 0; j < 12; j++ {
+		for i := 0; i < 12; i++ {
+			if dst.AlphaAt(i, j) != dst.AlphaAt(11-i, j) || dst.AlphaAt(i, j) != dst.AlphaAt(i, 11-j) {
+				t.Errorf("the distance field is not symmetric at (%d, %d)", i, j)
+			}
+		}
+	}
+}
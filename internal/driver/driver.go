@@ -0,0 +1,222 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package driver defines the handle and state types that are shared by the graphics package
+// and the graphics drivers.
+//
+// The types don't depend on any specific graphics library, so that a driver other than OpenGL
+// can implement graphics.Driver.
+package driver
+
+// Texture represents a texture.
+//
+// The concrete value is defined by each driver. A nil value represents an invalid texture.
+type Texture interface{}
+
+// Framebuffer represents a render target.
+//
+// The concrete value is defined by each driver. A nil value represents an invalid framebuffer.
+type Framebuffer interface{}
+
+// Renderbuffer represents a buffer attached to a framebuffer other than textures, e.g. a depth buffer.
+//
+// The concrete value is defined by each driver. A nil value represents an invalid renderbuffer.
+type Renderbuffer interface{}
+
+// Shader represents a compiled shader.
+//
+// The concrete value is defined by each driver.
+type Shader interface{}
+
+// Program represents a linked program of shaders.
+//
+// The concrete value is defined by each driver. A nil value represents an invalid program.
+type Program interface{}
+
+// Buffer represents a vertex or index buffer.
+//
+// The concrete value is defined by each driver. A nil value represents an invalid buffer.
+type Buffer interface{}
+
+type ShaderType int
+
+const (
+	VertexShader ShaderType = iota
+	FragmentShader
+)
+
+type BufferType int
+
+const (
+	ArrayBuffer BufferType = iota
+	ElementArrayBuffer
+)
+
+type Mode int
+
+const (
+	Triangles Mode = iota
+	Lines
+)
+
+type DataType int
+
+const (
+	Short DataType = iota
+	Float
+)
+
+func (d DataType) SizeInBytes() int {
+	switch d {
+	case Short:
+		return 2
+	case Float:
+		return 4
+	default:
+		panic("not reached")
+	}
+}
+
+type CompositeMode int
+
+const (
+	CompositeModeSourceOver CompositeMode = iota // This value must be 0 (= initial value)
+	CompositeModeClear
+	CompositeModeCopy
+	CompositeModeDestination
+	CompositeModeDestinationOver
+	CompositeModeSourceIn
+	CompositeModeDestinationIn
+	CompositeModeSourceOut
+	CompositeModeDestinationOut
+	CompositeModeSourceAtop
+	CompositeModeDestinationAtop
+	CompositeModeXor
+	CompositeModeLighter
+	CompositeModeMultiply
+	CompositeModeScreen
+	CompositeModeMin
+	CompositeModeMax
+	CompositeModeSubtract
+	CompositeModeUnknown
+)
+
+// ColorChannels represents a set of color channels as bit flags.
+type ColorChannels int
+
+const (
+	ColorChannelRed ColorChannels = 1 << iota
+	ColorChannelGreen
+	ColorChannelBlue
+	ColorChannelAlpha
+)
+
+// Address represents an address mode, which specifies how the texels out of the source region are sampled.
+type Address int
+
+const (
+	// AddressClampToZero makes the texels out of the source region transparent. This value must be 0.
+	AddressClampToZero Address = iota
+
+	// AddressClampToEdge makes the texels out of the source region the nearest texels on the edges.
+	AddressClampToEdge
+
+	// AddressRepeat repeats the whole image.
+	AddressRepeat
+
+	// AddressMirrorRepeat repeats the whole image with mirroring it at every other repetition.
+	AddressMirrorRepeat
+)
+
+// Wraps reports whether the address mode wraps the whole image.
+func (a Address) Wraps() bool {
+	return a == AddressRepeat || a == AddressMirrorRepeat
+}
+
+// PixelFormat represents a pixel format of a texture.
+type PixelFormat int
+
+const (
+	// PixelFormatRGBA8 is 8-bit RGBA. This is the default format.
+	PixelFormatRGBA8 PixelFormat = iota
+
+	// PixelFormatAlpha8 is 8-bit alpha only.
+	PixelFormatAlpha8
+
+	// PixelFormatRGBA16F is 16-bit floating point RGBA.
+	// Each component is represented as IEEE 754 binary16 in little endian.
+	PixelFormatRGBA16F
+
+	// PixelFormatSRGBA8 is 8-bit sRGB-encoded RGBA.
+	// The texels are decoded into the linear space when sampled, and encoded when rendered.
+	PixelFormatSRGBA8
+
+	// PixelFormatETC2RGBA8 is ETC2 compressed RGBA with EAC alpha (GL_COMPRESSED_RGBA8_ETC2_EAC).
+	PixelFormatETC2RGBA8
+
+	// PixelFormatASTC4x4 is ASTC compressed RGBA with 4x4 blocks (GL_COMPRESSED_RGBA_ASTC_4x4_KHR).
+	PixelFormatASTC4x4
+
+	// PixelFormatBC3 is BC3 (DXT5) compressed RGBA (GL_COMPRESSED_RGBA_S3TC_DXT5_EXT).
+	PixelFormatBC3
+)
+
+// IsCompressed reports whether the pixel format is a block-compressed format.
+//
+// All the compressed formats consist of 4x4 texel blocks of 16 bytes.
+func (f PixelFormat) IsCompressed() bool {
+	switch f {
+	case PixelFormatETC2RGBA8, PixelFormatASTC4x4, PixelFormatBC3:
+		return true
+	}
+	return false
+}
+
+// DataSize returns the size in bytes of the texels of the given size.
+func (f PixelFormat) DataSize(width, height int) int {
+	if f.IsCompressed() {
+		return 16 * ((width + 3) / 4) * ((height + 3) / 4)
+	}
+	return f.BytesPerPixel() * width * height
+}
+
+// BytesPerPixel returns the size of a pixel in bytes.
+//
+// BytesPerPixel panics for a compressed format. Use DataSize instead.
+func (f PixelFormat) BytesPerPixel() int {
+	switch f {
+	case PixelFormatRGBA8, PixelFormatSRGBA8:
+		return 4
+	case PixelFormatAlpha8:
+		return 1
+	case PixelFormatRGBA16F:
+		return 8
+	default:
+		panic("not reached")
+	}
+}
+
+// TextureUpload represents an upload of pixels to a region of a texture from a pixel buffer.
+type TextureUpload struct {
+	Texture Texture
+	Format  PixelFormat
+
+	// Offset is the offset of the pixels in the pixel buffer in bytes.
+	Offset int
+
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
@@ -15,11 +15,16 @@
 package graphics
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"image"
+	"runtime/pprof"
+	"runtime/trace"
+	"sync/atomic"
 
 	"github.com/hajimehoshi/ebiten/internal/affine"
-	emath "github.com/hajimehoshi/ebiten/internal/math"
-	"github.com/hajimehoshi/ebiten/internal/opengl"
+	"github.com/hajimehoshi/ebiten/internal/driver"
 )
 
 // command represents a drawing command.
@@ -32,7 +37,7 @@ type command interface {
 	Exec(indexOffsetInBytes int) error
 	NumVertices() int
 	AddNumVertices(n int)
-	CanMerge(dst, src *Image, color *affine.ColorM, mode opengl.CompositeMode, filter Filter) bool
+	CanMerge(dst, src *Image, color *affine.ColorM, mode driver.CompositeMode, filter Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) bool
 }
 
 // commandQueue is a command queue for drawing commands.
@@ -47,11 +52,46 @@ type commandQueue struct {
 	// nvertices must <= len(vertices).
 	// vertices is never shrunk since re-extending a vertices buffer is heavy.
 	nvertices int
+
+	// instances represents the data for instanced drawing converted from vertices.
+	// instances is used only when instanced drawing is available.
+	instances []float32
+
+	// disposeCommands is dispose commands that are not added to commands yet.
+	//
+	// Dispose commands are batched so that they don't prevent draw-image commands from being merged.
+	// See EnqueueDisposeCommand.
+	disposeCommands []command
+
+	// pixels is the staging buffer to upload the pixels of replacing-pixels commands at once.
+	// pixels is never shrunk as well as vertices.
+	pixels []byte
+
+	// reorderedVertices is the buffer to rearrange vertices at reorder.
+	reorderedVertices []float32
 }
 
 // theCommandQueue is the command queue for the current process.
 var theCommandQueue = &commandQueue{}
 
+var (
+	// drawImageCount is the number of quadrangles of enqueued draw-image requests since the last TakeDrawStats.
+	drawImageCount int64
+
+	// drawCallCount is the number of executed draw calls since the last TakeDrawStats.
+	drawCallCount int64
+
+	// skippedStateChangeCount is the number of the state changes skipped as redundant since the last TakeDrawStats.
+	skippedStateChangeCount int64
+)
+
+// TakeDrawStats returns the number of quadrangles of the draw-image requests enqueued, the number of draw calls
+// executed after merging the requests and the number of the state changes (e.g. uniform variables)
+// skipped since the values were not changed, since the last TakeDrawStats call.
+func TakeDrawStats() (drawImages, drawCalls, skippedStateChanges int) {
+	return int(atomic.SwapInt64(&drawImageCount, 0)), int(atomic.SwapInt64(&drawCallCount, 0)), int(atomic.SwapInt64(&skippedStateChangeCount, 0))
+}
+
 // appendVertices appends vertices to the queue.
 func (q *commandQueue) appendVertices(vertices []float32) {
 	if len(q.vertices) < q.nvertices+len(vertices) {
@@ -66,13 +106,55 @@ func (q *commandQueue) appendVertices(vertices []float32) {
 	q.nvertices += len(vertices)
 }
 
+// instanceData converts the vertices of quadrangles into the data for instanced drawing
+// and returns the result.
+//
+// As a quadrangle is always an affine transformation of a rectangle, a quadrangle can be
+// represented by its origin vertex with the depth and two edge vectors. The source region is represented
+// by the texture coordinates of the origin vertex and its diagonally opposite vertex.
+// The tint is same among the vertices.
+// Thus, one instance needs 19 values while four vertices need 60 values.
+func (q *commandQueue) instanceData(vertices []float32) []float32 {
+	vn := QuadVertexSizeInBytes() / driver.Float.SizeInBytes()
+	in := theInstanceArrayBufferLayout.totalBytes() / driver.Float.SizeInBytes()
+	n := len(vertices) / vn * in
+	if len(q.instances) < n {
+		q.instances = make([]float32, n)
+	}
+	is := q.instances[:n]
+	for i := 0; i < len(vertices)/vn; i++ {
+		v := vertices[i*vn : (i+1)*vn]
+		d := is[i*in : (i+1)*in]
+		// The layout of a vertex is (x, y, z, u, v, u', v', scale, translate) where (u', v') is the diagonally
+		// opposite texture coordinate. The order of vertices is top-left, top-right, bottom-left, and bottom-right.
+		// The depth z and the tint are same among the vertices. See QuadVertices.
+		v0 := v[:vertexFloat32Num]
+		v1 := v[vertexFloat32Num : 2*vertexFloat32Num]
+		v2 := v[2*vertexFloat32Num : 3*vertexFloat32Num]
+		d[0] = v0[0]
+		d[1] = v0[1]
+		d[2] = v0[2]
+		d[3] = v1[0] - v0[0]
+		d[4] = v1[1] - v0[1]
+		d[5] = v2[0] - v0[0]
+		d[6] = v2[1] - v0[1]
+		d[7] = v0[3]
+		d[8] = v0[4]
+		d[9] = v0[5]
+		d[10] = v0[6]
+		copy(d[11:19], v0[7:15])
+	}
+	return is
+}
+
 // EnqueueDrawImageCommand enqueues a drawing-image command.
-func (q *commandQueue) EnqueueDrawImageCommand(dst, src *Image, vertices []float32, color *affine.ColorM, mode opengl.CompositeMode, filter Filter) {
+func (q *commandQueue) EnqueueDrawImageCommand(dst, src *Image, vertices []float32, color *affine.ColorM, mode driver.CompositeMode, filter Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
 	// Avoid defer for performance
+	atomic.AddInt64(&drawImageCount, int64(len(vertices)/quadFloat32Num))
 	q.appendVertices(vertices)
 	if 0 < len(q.commands) {
 		last := q.commands[len(q.commands)-1]
-		if last.CanMerge(dst, src, color, mode, filter) {
+		if last.CanMerge(dst, src, color, mode, filter, clip, disabled, address) {
 			last.AddNumVertices(len(vertices))
 			return
 		}
@@ -84,6 +166,113 @@ func (q *commandQueue) EnqueueDrawImageCommand(dst, src *Image, vertices []float
 		color:     color,
 		mode:      mode,
 		filter:    filter,
+		clip:      clip,
+		disabled:  disabled,
+		address:   address,
+	}
+	q.commands = append(q.commands, c)
+}
+
+// EnqueueDrawImageWithLUTCommand enqueues a drawing-image command converting the colors with
+// the color look-up table lut.
+//
+// The command is never merged with other commands.
+func (q *commandQueue) EnqueueDrawImageWithLUTCommand(dst, src, lut *Image, vertices []float32, color *affine.ColorM, mode driver.CompositeMode) {
+	atomic.AddInt64(&drawImageCount, 1)
+	q.appendVertices(vertices)
+	c := &drawImageCommand{
+		dst:       dst,
+		src:       src,
+		nvertices: len(vertices),
+		color:     color,
+		mode:      mode,
+		filter:    FilterNearest,
+		lut:       lut,
+	}
+	q.commands = append(q.commands, c)
+}
+
+// EnqueueDrawImageWithPaletteCommand enqueues a drawing-image command resolving the colors of src,
+// an alpha-only image of palette indices, with palette.
+//
+// The command is merged only with the last command with the same palette.
+func (q *commandQueue) EnqueueDrawImageWithPaletteCommand(dst, src, palette *Image, vertices []float32, color *affine.ColorM, mode driver.CompositeMode, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	atomic.AddInt64(&drawImageCount, int64(len(vertices)/quadFloat32Num))
+	q.appendVertices(vertices)
+	if 0 < len(q.commands) {
+		if last, ok := q.commands[len(q.commands)-1].(*drawImageCommand); ok && last.palette == palette && last.canMerge(dst, src, color, mode, FilterNearest, clip, disabled, address) {
+			last.AddNumVertices(len(vertices))
+			return
+		}
+	}
+	c := &drawImageCommand{
+		dst:       dst,
+		src:       src,
+		nvertices: len(vertices),
+		color:     color,
+		mode:      mode,
+		filter:    FilterNearest,
+		clip:      clip,
+		disabled:  disabled,
+		address:   address,
+		palette:   palette,
+	}
+	q.commands = append(q.commands, c)
+}
+
+// EnqueueDrawImageWithNormalMapCommand enqueues a drawing-image command lighting the colors of src with
+// normalMap and lighting.
+//
+// The command is merged only with the last command with the same normal map and the same lighting.
+func (q *commandQueue) EnqueueDrawImageWithNormalMapCommand(dst, src, normalMap *Image, lighting *NormalMapLighting, vertices []float32, color *affine.ColorM, mode driver.CompositeMode, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	atomic.AddInt64(&drawImageCount, int64(len(vertices)/quadFloat32Num))
+	q.appendVertices(vertices)
+	if 0 < len(q.commands) {
+		if last, ok := q.commands[len(q.commands)-1].(*drawImageCommand); ok && last.normalMap == normalMap && *last.lighting == *lighting && last.canMerge(dst, src, color, mode, FilterNearest, clip, disabled, address) {
+			last.AddNumVertices(len(vertices))
+			return
+		}
+	}
+	c := &drawImageCommand{
+		dst:       dst,
+		src:       src,
+		nvertices: len(vertices),
+		color:     color,
+		mode:      mode,
+		filter:    FilterNearest,
+		clip:      clip,
+		disabled:  disabled,
+		address:   address,
+		normalMap: normalMap,
+		lighting:  lighting,
+	}
+	q.commands = append(q.commands, c)
+}
+
+// EnqueueDrawImageWithDistanceFieldCommand enqueues a drawing-image command rendering src, a distance field,
+// with distanceField.
+//
+// The command is merged only with the last command with the same distance field parameters.
+func (q *commandQueue) EnqueueDrawImageWithDistanceFieldCommand(dst, src *Image, distanceField *DistanceField, vertices []float32, color *affine.ColorM, mode driver.CompositeMode, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	atomic.AddInt64(&drawImageCount, int64(len(vertices)/quadFloat32Num))
+	q.appendVertices(vertices)
+	if 0 < len(q.commands) {
+		if last, ok := q.commands[len(q.commands)-1].(*drawImageCommand); ok && last.distanceField != nil && *last.distanceField == *distanceField && last.canMerge(dst, src, color, mode, FilterLinear, clip, disabled, address) {
+			last.AddNumVertices(len(vertices))
+			return
+		}
+	}
+	c := &drawImageCommand{
+		dst:           dst,
+		src:           src,
+		nvertices:     len(vertices),
+		color:         color,
+		mode:          mode,
+		filter:        FilterLinear,
+		clip:          clip,
+		disabled:      disabled,
+		address:       address,
+		distanceField: distanceField,
 	}
 	q.commands = append(q.commands, c)
 }
@@ -91,13 +280,55 @@ func (q *commandQueue) EnqueueDrawImageCommand(dst, src *Image, vertices []float
 // Enqueue enqueues a drawing command other than a draw-image command.
 //
 // For a draw-image command, use EnqueueDrawImageCommand.
+// For a dispose command, use EnqueueDisposeCommand.
 func (q *commandQueue) Enqueue(command command) {
+	q.flushDisposeCommands()
 	q.commands = append(q.commands, command)
 }
 
+// EnqueueReplacePixelsCommand enqueues a replacing-pixels command.
+//
+// If the last command is also a replacing-pixels command, the region is added to it instead so that
+// the pixels of the consecutive commands are uploaded together.
+func (q *commandQueue) EnqueueReplacePixelsCommand(region *replacePixelsRegion) {
+	q.flushDisposeCommands()
+	if len(q.commands) > 0 {
+		if c, ok := q.commands[len(q.commands)-1].(*replacePixelsCommand); ok {
+			c.regions = append(c.regions, region)
+			return
+		}
+	}
+	q.commands = append(q.commands, &replacePixelsCommand{
+		regions: []*replacePixelsRegion{region},
+	})
+}
+
+// EnqueueDisposeCommand enqueues a dispose command.
+//
+// The dispose command is not added to the queue immediately. The dispose commands are added together
+// just before the next command other than a draw-image command, or at Flush.
+// Delaying disposing is safe because a disposed image is never used by the following draw-image commands.
+// On the other hand, disposing must not be delayed after creating a new image, since the new texture might
+// have the same handle as the disposed one e.g. after the context is lost.
+func (q *commandQueue) EnqueueDisposeCommand(command *disposeCommand) {
+	q.disposeCommands = append(q.disposeCommands, command)
+}
+
+// flushDisposeCommands adds the batched dispose commands to the queue.
+func (q *commandQueue) flushDisposeCommands() {
+	if len(q.disposeCommands) == 0 {
+		return
+	}
+	q.commands = append(q.commands, q.disposeCommands...)
+	q.disposeCommands = q.disposeCommands[:0]
+}
+
 // commandGroups separates q.commands into some groups.
 // The number of quads of drawImageCommand in one groups must be equal to or less than
 // its limit (maxQuads).
+//
+// As the indices are 16-bit, a drawImageCommand with more quads than the limit is split
+// into multiple groups transparently.
 func (q *commandQueue) commandGroups() [][]command {
 	cs := q.commands
 	var gs [][]command
@@ -114,7 +345,10 @@ func (q *commandQueue) commandGroups() [][]command {
 				break
 			}
 			cc := c.split(maxQuads - quads)
-			gs[len(gs)-1] = append(gs[len(gs)-1], cc[0])
+			// When the current group is already full, the first part is empty and can be skipped.
+			if cc[0].quadsNum() > 0 {
+				gs[len(gs)-1] = append(gs[len(gs)-1], cc[0])
+			}
 			cs[0] = cc[1]
 			quads = 0
 			gs = append(gs, []command{})
@@ -126,10 +360,105 @@ func (q *commandQueue) commandGroups() [][]command {
 	return gs
 }
 
+// maxReorderDistance is the maximum number of commands that a draw-image command is moved over
+// to be merged with an earlier draw-image command.
+const maxReorderDistance = 32
+
+// reorderGroup is a command with the ranges of the vertices of the commands merged into it.
+type reorderGroup struct {
+	command  command
+	segments [][2]int
+}
+
+// reorder merges draw-image commands into earlier mergeable draw-image commands that are not adjacent,
+// so that the alternating drawings to multiple destinations (e.g. a lighting buffer and the screen)
+// are batched.
+//
+// A draw-image command is moved before the commands in between only when it doesn't depend on them.
+// See (*drawImageCommand).dependsOn.
+func (q *commandQueue) reorder() {
+	gs := make([]*reorderGroup, 0, len(q.commands))
+	merged := false
+	offset := 0
+	for _, c := range q.commands {
+		n := c.NumVertices()
+		seg := [2]int{offset, offset + n}
+		offset += n
+		if d, ok := c.(*drawImageCommand); ok && d.lut == nil && d.palette == nil && d.normalMap == nil && d.distanceField == nil {
+			if g := mergeableGroup(gs, d); g != nil {
+				g.command.AddNumVertices(n)
+				g.segments = append(g.segments, seg)
+				merged = true
+				continue
+			}
+		}
+		gs = append(gs, &reorderGroup{
+			command:  c,
+			segments: [][2]int{seg},
+		})
+	}
+	if !merged {
+		return
+	}
+
+	q.commands = q.commands[:0]
+	vs := q.reorderedVertices[:0]
+	for _, g := range gs {
+		q.commands = append(q.commands, g.command)
+		for _, s := range g.segments {
+			vs = append(vs, q.vertices[s[0]:s[1]]...)
+		}
+	}
+	copy(q.vertices, vs)
+	q.reorderedVertices = vs
+}
+
+// mergeableGroup returns the group in gs that d can be merged into, or nil if there is no such group.
+func mergeableGroup(gs []*reorderGroup, d *drawImageCommand) *reorderGroup {
+	for i := len(gs) - 1; i >= 0 && i >= len(gs)-1-maxReorderDistance; i-- {
+		c := gs[i].command
+		if c.CanMerge(d.dst, d.src, d.color, d.mode, d.filter, d.clip, d.disabled, d.address) {
+			return gs[i]
+		}
+		if d.dependsOn(c) {
+			return nil
+		}
+	}
+	return nil
+}
+
 // Flush flushes the command queue.
+//
+// Before the OpenGL state is initialized by ResetGLState, e.g. before the main loop starts,
+// Flush does nothing and the commands are kept so that images can be created and drawn in advance.
+//
+// The flush is annotated as a runtime/trace task and with the pprof label ebiten=flush,
+// so that the time for rendering is distinguished from the game's in the profiles.
 func (q *commandQueue) Flush() error {
+	if !theOpenGLState.initialized {
+		return nil
+	}
+	if len(q.commands) == 0 && len(q.disposeCommands) == 0 {
+		// Don't annotate an empty flush, e.g. at reading pixels of images one by one.
+		// pprof.Do would reset the caller's labels.
+		return q.flush(context.Background())
+	}
+
+	ctx, task := trace.NewTask(context.Background(), "ebiten: flush")
+	defer task.End()
+
+	var err error
+	pprof.Do(ctx, pprof.Labels("ebiten", "flush"), func(ctx context.Context) {
+		err = q.flush(ctx)
+	})
+	return err
+}
+
+func (q *commandQueue) flush(ctx context.Context) error {
 	// glViewport must be called at least at every frame on iOS.
-	opengl.GetContext().ResetViewportSize()
+	currentDriver().ResetViewportSize()
+	q.flushDisposeCommands()
+	q.reorder()
 	n := 0
 	lastN := 0
 	for _, g := range q.commandGroups() {
@@ -137,28 +466,33 @@ func (q *commandQueue) Flush() error {
 			n += c.NumVertices()
 		}
 		if 0 < n-lastN {
+			theOpenGLState.bindNextArrayBuffer()
 			// Note that the vertices passed to BufferSubData is not under GC management
 			// in opengl package due to unsafe-way.
 			// See BufferSubData in context_mobile.go.
-			opengl.GetContext().BufferSubData(opengl.ArrayBuffer, q.vertices[lastN:n])
+			if theOpenGLState.instancing {
+				currentDriver().BufferSubData(driver.ArrayBuffer, q.instanceData(q.vertices[lastN:n]))
+			} else {
+				currentDriver().BufferSubData(driver.ArrayBuffer, q.vertices[lastN:n])
+			}
 		}
 		// NOTE: WebGL doesn't seem to have Check gl.MAX_ELEMENTS_VERTICES or gl.MAX_ELEMENTS_INDICES so far.
 		// Let's use them to compare to len(quads) in the future.
-		if maxQuads < (n-lastN)*opengl.Float.SizeInBytes()/QuadVertexSizeInBytes() {
+		if maxQuads < (n-lastN)*driver.Float.SizeInBytes()/QuadVertexSizeInBytes() {
 			return fmt.Errorf("len(quads) must be equal to or less than %d", maxQuads)
 		}
 		numc := len(g)
 		indexOffsetInBytes := 0
 		for _, c := range g {
-			if err := c.Exec(indexOffsetInBytes); err != nil {
+			if err := execCommand(ctx, c, indexOffsetInBytes); err != nil {
 				return err
 			}
-			n := c.NumVertices() * opengl.Float.SizeInBytes() / QuadVertexSizeInBytes()
+			n := c.NumVertices() * driver.Float.SizeInBytes() / QuadVertexSizeInBytes()
 			indexOffsetInBytes += 6 * n * 2
 		}
 		if 0 < numc {
 			// Call glFlush to prevent black flicking (especially on Android (#226) and iOS).
-			opengl.GetContext().Flush()
+			currentDriver().Flush()
 		}
 		lastN = n
 	}
@@ -167,6 +501,19 @@ func (q *commandQueue) Flush() error {
 	return nil
 }
 
+// execCommand executes c. A texture upload is annotated with the pprof label ebiten=upload.
+func execCommand(ctx context.Context, c command, indexOffsetInBytes int) error {
+	if _, ok := c.(*replacePixelsCommand); !ok {
+		return c.Exec(indexOffsetInBytes)
+	}
+	var err error
+	pprof.Do(ctx, pprof.Labels("ebiten", "upload"), func(ctx context.Context) {
+		defer trace.StartRegion(ctx, "ebiten: upload texture").End()
+		err = c.Exec(indexOffsetInBytes)
+	})
+	return err
+}
+
 // FlushCommands flushes the command queue.
 func FlushCommands() error {
 	return theCommandQueue.Flush()
@@ -178,8 +525,31 @@ type drawImageCommand struct {
 	src       *Image
 	nvertices int
 	color     *affine.ColorM
-	mode      opengl.CompositeMode
+	mode      driver.CompositeMode
 	filter    Filter
+
+	// clip is the clipping region on dst. nil means no clipping.
+	clip *image.Rectangle
+
+	// disabled is the color channels of dst that are not changed.
+	disabled driver.ColorChannels
+
+	// address is the address mode to sample src.
+	address driver.Address
+
+	// lut is the color look-up table to convert the colors. nil means no conversion.
+	lut *Image
+
+	// palette is the palette to resolve the colors of src, which is an alpha-only image of palette indices.
+	// nil means src is not paletted.
+	palette *Image
+
+	// normalMap is the normal map to light src with lighting. nil means src is not lit.
+	normalMap *Image
+	lighting  *NormalMapLighting
+
+	// distanceField is the parameters to render src as a distance field. nil means src is not a distance field.
+	distanceField *DistanceField
 }
 
 // QuadVertexSizeInBytes returns the size in bytes of vertices for a quadrangle.
@@ -189,23 +559,45 @@ func QuadVertexSizeInBytes() int {
 
 // Exec executes the drawImageCommand.
 func (c *drawImageCommand) Exec(indexOffsetInBytes int) error {
+	for _, img := range []*Image{c.dst, c.src, c.lut, c.palette, c.normalMap} {
+		if img != nil {
+			img.texture.waitUpload()
+		}
+	}
+
 	f, err := c.dst.createFramebufferIfNeeded()
 	if err != nil {
 		return err
 	}
 	f.setAsViewport()
 
-	opengl.GetContext().BlendFunc(c.mode)
+	currentDriver().BlendFunc(c.mode)
+	if c.clip != nil {
+		// The Y axis of an offscreen framebuffer is same as the image's since the projection matrix
+		// doesn't flip the Y axis. Then the clipping region can be used as it is.
+		r := c.clip
+		currentDriver().SetScissor(r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+	} else {
+		currentDriver().DisableScissor()
+	}
+	currentDriver().ColorMask(
+		c.disabled&driver.ColorChannelRed == 0,
+		c.disabled&driver.ColorChannelGreen == 0,
+		c.disabled&driver.ColorChannelBlue == 0,
+		c.disabled&driver.ColorChannelAlpha == 0)
+	depthTest := c.depthTest()
+	currentDriver().SetDepthTest(depthTest)
 
 	n := c.quadsNum()
 	if n == 0 {
 		return nil
 	}
 	proj := f.projectionMatrix()
-	theOpenGLState.useProgram(proj, c.src.texture.native, c.dst, c.src, c.color, c.filter)
+	theOpenGLState.useProgram(proj, c.src.texture.native, c.dst, c.src, c.color, c.filter, c.address, depthTest, c.lut, c.palette, c.normalMap, c.lighting, c.distanceField)
 	// TODO: We should call glBindBuffer here?
 	// The buffer is already bound at begin() but it is counterintuitive.
-	opengl.GetContext().DrawElements(opengl.Triangles, 6*n, indexOffsetInBytes)
+	theOpenGLState.drawQuads(indexOffsetInBytes/(6*2), n)
+	atomic.AddInt64(&drawCallCount, 1)
 
 	// glFlush() might be necessary at least on MacBook Pro (a smilar problem at #419),
 	// but basically this pass the tests (esp. TestImageTooManyFill).
@@ -214,6 +606,16 @@ func (c *drawImageCommand) Exec(indexOffsetInBytes int) error {
 	return nil
 }
 
+// depthTest returns a boolean value indicating whether the depth test is applied to the drawImageCommand.
+//
+// The copy and clear modes overwrite the destination regardless of the depth, e.g. for filling.
+func (c *drawImageCommand) depthTest() bool {
+	if !c.dst.depth {
+		return false
+	}
+	return c.mode != driver.CompositeModeCopy && c.mode != driver.CompositeModeClear
+}
+
 func (c *drawImageCommand) NumVertices() int {
 	return c.nvertices
 }
@@ -229,7 +631,7 @@ func (c *drawImageCommand) AddNumVertices(n int) {
 func (c *drawImageCommand) split(quadsNum int) [2]*drawImageCommand {
 	c1 := *c
 	c2 := *c
-	s := opengl.Float.SizeInBytes()
+	s := driver.Float.SizeInBytes()
 	n := quadsNum * QuadVertexSizeInBytes() / s
 	c1.nvertices = n
 	c2.nvertices -= n
@@ -238,7 +640,15 @@ func (c *drawImageCommand) split(quadsNum int) [2]*drawImageCommand {
 
 // CanMerge returns a boolean value indicating whether the other drawImageCommand can be merged
 // with the drawImageCommand c.
-func (c *drawImageCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode opengl.CompositeMode, filter Filter) bool {
+func (c *drawImageCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode driver.CompositeMode, filter Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) bool {
+	if c.lut != nil || c.palette != nil || c.normalMap != nil || c.distanceField != nil {
+		return false
+	}
+	return c.canMerge(dst, src, color, mode, filter, clip, disabled, address)
+}
+
+// canMerge is same as CanMerge but ignores the color look-up table, the palette, the normal map and the distance field.
+func (c *drawImageCommand) canMerge(dst, src *Image, color *affine.ColorM, mode driver.CompositeMode, filter Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) bool {
 	if c.dst != dst {
 		return false
 	}
@@ -254,16 +664,52 @@ func (c *drawImageCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode
 	if c.filter != filter {
 		return false
 	}
+	if !EqualClips(c.clip, clip) {
+		return false
+	}
+	if c.disabled != disabled {
+		return false
+	}
+	if c.address != address {
+		return false
+	}
 	return true
 }
 
+// EqualClips returns a boolean value indicating whether the clipping regions a and b are same.
+// nil represents no clipping.
+func EqualClips(a, b *image.Rectangle) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 // quadsNum returns the number of quadrangles.
 func (c *drawImageCommand) quadsNum() int {
-	return c.nvertices * opengl.Float.SizeInBytes() / QuadVertexSizeInBytes()
+	return c.nvertices * driver.Float.SizeInBytes() / QuadVertexSizeInBytes()
 }
 
-// replacePixelsCommand represents a command to replace pixels of an image.
-type replacePixelsCommand struct {
+// dependsOn reports whether command must be executed before c.
+//
+// This is true when command is not a draw-image command, or when command writes c's source (read after write),
+// reads c's destination (write after read) or writes c's destination (write after write).
+func (c *drawImageCommand) dependsOn(command command) bool {
+	d, ok := command.(*drawImageCommand)
+	if !ok {
+		return true
+	}
+	if d.dst == c.src || d.dst == c.dst {
+		return true
+	}
+	if d.src == c.dst || d.lut == c.dst || d.palette == c.dst || d.normalMap == c.dst {
+		return true
+	}
+	return false
+}
+
+// replacePixelsRegion represents a region of an image to be replaced with pixels.
+type replacePixelsRegion struct {
 	dst    *Image
 	pixels []byte
 	x      int
@@ -272,19 +718,105 @@ type replacePixelsCommand struct {
 	height int
 }
 
+// texels returns the pixels converted into the texture's format.
+func (r *replacePixelsRegion) texels() []byte {
+	t := r.dst.texture
+	p := convertPixels(r.pixels, r.dst.format, t.format)
+	if t.format == driver.PixelFormatSRGBA8 {
+		// r.pixels is owned by the command and can be modified.
+		p = srgbPixelsToTexels(p)
+	}
+	return p
+}
+
+// minUploadWorkerTexels is the minimum number of the texels of a region to be uploaded on the upload worker.
+//
+// Small regions are uploaded on the render thread, as sending them to the upload worker costs more than uploading.
+const minUploadWorkerTexels = 256 * 256
+
+// replacePixelsCommand represents a command to replace pixels of images.
+//
+// Consecutive replacing-pixels commands are merged into one (see EnqueueReplacePixelsCommand).
+// When pixel buffers are available, the pixels of all the regions are uploaded via one pixel buffer
+// so that streaming many regions every frame doesn't stall the pipeline for each region.
+// When the upload worker is available, large regions are uploaded on the worker instead.
+type replacePixelsCommand struct {
+	regions []*replacePixelsRegion
+}
+
 // Exec executes the replacePixelsCommand.
 func (c *replacePixelsCommand) Exec(indexOffsetInBytes int) error {
-	f, err := c.dst.createFramebufferIfNeeded()
-	if err != nil {
-		return err
+	for _, r := range c.regions {
+		// An alpha-only texture can't be attached to a framebuffer.
+		if r.dst.texture.format == driver.PixelFormatAlpha8 {
+			continue
+		}
+		f, err := r.dst.createFramebufferIfNeeded()
+		if err != nil {
+			return err
+		}
+		f.setAsViewport()
 	}
-	f.setAsViewport()
 
 	// glFlush is necessary on Android.
 	// glTexSubImage2D didn't work without this hack at least on Nexus 5x and NuAns NEO [Reloaded] (#211).
-	opengl.GetContext().Flush()
-	opengl.GetContext().BindTexture(c.dst.texture.native)
-	opengl.GetContext().TexSubImage2D(c.pixels, c.x, c.y, c.width, c.height)
+	currentDriver().Flush()
+
+	regions := c.regions
+	if currentDriver().IsUploadWorkerAvailable() {
+		regions = nil
+		// synced is the textures updated on the render thread. The later regions of them must be uploaded
+		// on the render thread too to keep the order.
+		synced := map[*texture]struct{}{}
+		for _, r := range c.regions {
+			t := r.dst.texture
+			if _, ok := synced[t]; ok || r.width*r.height < minUploadWorkerTexels {
+				t.waitUpload()
+				regions = append(regions, r)
+				synced[t] = struct{}{}
+				continue
+			}
+			// Converting and uploading large texels takes long. Do them on the upload worker
+			// so that the rendering is not blocked.
+			currentDriver().TexSubImage2DOnWorker(t.native, r.texels, t.format, r.x, r.y, r.width, r.height)
+			t.uploading = true
+		}
+		if len(regions) == 0 {
+			return nil
+		}
+	}
+
+	// Copying the pixels to a pixel buffer is not worth for only one region.
+	if len(regions) == 1 || !currentDriver().IsPixelBufferAvailable() {
+		for _, r := range regions {
+			t := r.dst.texture
+			currentDriver().BindTexture(t.native)
+			currentDriver().TexSubImage2D(r.texels(), t.format, r.x, r.y, r.width, r.height)
+		}
+		return nil
+	}
+
+	q := theCommandQueue
+	q.pixels = q.pixels[:0]
+	uploads := make([]driver.TextureUpload, 0, len(regions))
+	for _, r := range regions {
+		// Align the offsets to 4 bytes so that the offsets are valid for any pixel formats.
+		for len(q.pixels)%4 != 0 {
+			q.pixels = append(q.pixels, 0)
+		}
+		t := r.dst.texture
+		uploads = append(uploads, driver.TextureUpload{
+			Texture: t.native,
+			Format:  t.format,
+			Offset:  len(q.pixels),
+			X:       r.x,
+			Y:       r.y,
+			Width:   r.width,
+			Height:  r.height,
+		})
+		q.pixels = append(q.pixels, r.texels()...)
+	}
+	currentDriver().TexSubImages2DFromPixelBuffer(q.pixels, uploads)
 	return nil
 }
 
@@ -295,7 +827,73 @@ func (c *replacePixelsCommand) NumVertices() int {
 func (c *replacePixelsCommand) AddNumVertices(n int) {
 }
 
-func (c *replacePixelsCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode opengl.CompositeMode, filter Filter) bool {
+func (c *replacePixelsCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode driver.CompositeMode, filter Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) bool {
+	return false
+}
+
+// copyPixelsCommand represents a command to copy pixels from an image to another image.
+type copyPixelsCommand struct {
+	dst    *Image
+	src    *Image
+	sx     int
+	sy     int
+	width  int
+	height int
+	dx     int
+	dy     int
+}
+
+// Exec executes the copyPixelsCommand.
+func (c *copyPixelsCommand) Exec(indexOffsetInBytes int) error {
+	c.src.texture.waitUpload()
+	c.dst.texture.waitUpload()
+
+	// The source pixels are read from the framebuffer of the source image.
+	f, err := c.src.createFramebufferIfNeeded()
+	if err != nil {
+		return err
+	}
+	f.setAsViewport()
+
+	currentDriver().BindTexture(c.dst.texture.native)
+	currentDriver().CopyTexSubImage2D(c.dx, c.dy, c.sx, c.sy, c.width, c.height)
+	return nil
+}
+
+func (c *copyPixelsCommand) NumVertices() int {
+	return 0
+}
+
+func (c *copyPixelsCommand) AddNumVertices(n int) {
+}
+
+func (c *copyPixelsCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode driver.CompositeMode, filter Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) bool {
+	return false
+}
+
+// clearDepthCommand represents a command to clear the depth buffer of an image.
+type clearDepthCommand struct {
+	dst *Image
+}
+
+// Exec executes the clearDepthCommand.
+func (c *clearDepthCommand) Exec(indexOffsetInBytes int) error {
+	f, err := c.dst.createFramebufferIfNeeded()
+	if err != nil {
+		return err
+	}
+	f.clearDepth()
+	return nil
+}
+
+func (c *clearDepthCommand) NumVertices() int {
+	return 0
+}
+
+func (c *clearDepthCommand) AddNumVertices(n int) {
+}
+
+func (c *clearDepthCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode driver.CompositeMode, filter Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) bool {
 	return false
 }
 
@@ -306,12 +904,17 @@ type disposeCommand struct {
 
 // Exec executes the disposeCommand.
 func (c *disposeCommand) Exec(indexOffsetInBytes int) error {
-	if c.target.framebuffer != nil &&
-		c.target.framebuffer.native != opengl.GetContext().ScreenFramebuffer() {
-		opengl.GetContext().DeleteFramebuffer(c.target.framebuffer.native)
+	// The screen framebuffer might be changed after the image is created. Check the flag instead of the native value.
+	if c.target.framebuffer != nil && !c.target.framebuffer.screen {
+		currentDriver().DeleteFramebuffer(c.target.framebuffer.native)
+		if c.target.framebuffer.depth != nil {
+			currentDriver().DeleteRenderbuffer(c.target.framebuffer.depth)
+		}
 	}
 	if c.target.texture != nil {
-		opengl.GetContext().DeleteTexture(c.target.texture.native)
+		c.target.texture.waitUpload()
+		currentDriver().DeleteTexture(c.target.texture.native)
+		atomic.AddInt64(&memoryUsage, -c.target.texture.sizeInBytes())
 	}
 	return nil
 }
@@ -323,7 +926,7 @@ func (c *disposeCommand) NumVertices() int {
 func (c *disposeCommand) AddNumVertices(n int) {
 }
 
-func (c *disposeCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode opengl.CompositeMode, filter Filter) bool {
+func (c *disposeCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode driver.CompositeMode, filter Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) bool {
 	return false
 }
 
@@ -332,6 +935,10 @@ type newImageCommand struct {
 	result *Image
 	width  int
 	height int
+	format driver.PixelFormat
+
+	// data is the compressed texels data when the format is compressed.
+	data []byte
 }
 
 func checkSize(width, height int) {
@@ -352,16 +959,52 @@ func checkSize(width, height int) {
 
 // Exec executes a newImageCommand.
 func (c *newImageCommand) Exec(indexOffsetInBytes int) error {
-	w := emath.NextPowerOf2Int(c.width)
-	h := emath.NextPowerOf2Int(c.height)
+	if c.format.IsCompressed() {
+		return c.execCompressed()
+	}
+	w, h := textureSize(c.width, c.height, currentDriver().IsNPOTTextureAvailable())
 	checkSize(w, h)
-	native, err := opengl.GetContext().NewTexture(w, h)
+	format := c.format
+	if !currentDriver().IsPixelFormatAvailable(format) {
+		// Fall back to the default format. The pixels are converted when they are transferred.
+		format = driver.PixelFormatRGBA8
+	}
+	native, err := currentDriver().NewTexture(w, h, format)
+	if err != nil {
+		return err
+	}
+	t := &texture{
+		native: native,
+		width:  w,
+		height: h,
+		format: format,
+	}
+	c.result.texture = t
+	atomic.AddInt64(&memoryUsage, t.sizeInBytes())
+	return nil
+}
+
+// execCompressed creates a texture of the compressed format.
+//
+// Compressed texels can't be converted on the fly, so the texture size is the image size
+// and there is no fallback format.
+func (c *newImageCommand) execCompressed() error {
+	checkSize(c.width, c.height)
+	if !currentDriver().IsPixelFormatAvailable(c.format) {
+		return errors.New("graphics: the compressed pixel format is not available")
+	}
+	native, err := currentDriver().NewCompressedTexture(c.width, c.height, c.format, c.data)
 	if err != nil {
 		return err
 	}
-	c.result.texture = &texture{
+	t := &texture{
 		native: native,
+		width:  c.width,
+		height: c.height,
+		format: c.format,
 	}
+	c.result.texture = t
+	atomic.AddInt64(&memoryUsage, t.sizeInBytes())
 	return nil
 }
 
@@ -372,7 +1015,7 @@ func (c *newImageCommand) NumVertices() int {
 func (c *newImageCommand) AddNumVertices(n int) {
 }
 
-func (c *newImageCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode opengl.CompositeMode, filter Filter) bool {
+func (c *newImageCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode driver.CompositeMode, filter Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) bool {
 	return false
 }
 
@@ -400,6 +1043,6 @@ func (c *newScreenFramebufferImageCommand) NumVertices() int {
 func (c *newScreenFramebufferImageCommand) AddNumVertices(n int) {
 }
 
-func (c *newScreenFramebufferImageCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode opengl.CompositeMode, filter Filter) bool {
+func (c *newScreenFramebufferImageCommand) CanMerge(dst, src *Image, color *affine.ColorM, mode driver.CompositeMode, filter Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) bool {
 	return false
 }
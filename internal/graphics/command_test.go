@@ -0,0 +1,380 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphics
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/internal/affine"
+	"github.com/hajimehoshi/ebiten/internal/driver"
+)
+
+// verticesFromInstance calculates the vertices of a quadrangle from one instance
+// in the same way as the vertex shader for instanced drawing.
+func verticesFromInstance(d []float32) []float32 {
+	mix := func(a, b, t float32) float32 {
+		return a*(1-t) + b*t
+	}
+	vs := []float32{}
+	for i := 0; i < len(cornerVertices)/2; i++ {
+		cx, cy := cornerVertices[2*i], cornerVertices[2*i+1]
+		vs = append(vs,
+			d[0]+cx*d[3]+cy*d[5],
+			d[1]+cx*d[4]+cy*d[6],
+			d[2],
+			mix(d[7], d[9], cx),
+			mix(d[8], d[10], cy),
+			mix(d[9], d[7], cx),
+			mix(d[10], d[8], cy))
+		vs = append(vs, d[11:19]...)
+	}
+	return vs
+}
+
+func TestInstanceData(t *testing.T) {
+	testCases := []struct {
+		name string
+		geo  *affine.GeoM
+	}{
+		{
+			name: "identity",
+			geo:  nil,
+		},
+		{
+			name: "translated",
+			geo:  (*affine.GeoM)(nil).Translate(10, 20),
+		},
+		{
+			name: "scaled",
+			geo:  (*affine.GeoM)(nil).Scale(2, 3),
+		},
+		{
+			name: "rotated",
+			geo:  (*affine.GeoM)(nil).Rotate(math.Pi/6).Translate(5, 7),
+		},
+		{
+			name: "flipped horizontally",
+			geo:  (*affine.GeoM)(nil).Scale(-1, 1).Translate(32, 0),
+		},
+		{
+			name: "flipped vertically",
+			geo:  (*affine.GeoM)(nil).Scale(1, -1).Translate(0, 16),
+		},
+		{
+			name: "flipped and rotated",
+			geo:  (*affine.GeoM)(nil).Scale(-1, -1).Rotate(3*math.Pi/4).Translate(8, 9),
+		},
+	}
+
+	const eps = 1.0 / 1024
+	for _, tc := range testCases {
+		tint := (*affine.ColorM)(nil).Scale(0.5, 0.25, 1, 0.75).Translate(0.125, 0, 0, 0)
+		vs := QuadVertices(4, 8, 36, 24, tc.geo, tint, 0.5)
+		q := &commandQueue{}
+		got := verticesFromInstance(q.instanceData(vs))
+		if len(got) != len(vs) {
+			t.Fatalf("%s: len(vertices): got: %d, want: %d", tc.name, len(got), len(vs))
+		}
+		for i := range vs {
+			if math.Abs(float64(got[i]-vs[i])) > eps {
+				t.Errorf("%s: vertices[%d]: got: %f, want: %f", tc.name, i, got[i], vs[i])
+			}
+		}
+	}
+}
+
+func TestCommandGroups(t *testing.T) {
+	vs := QuadVertexSizeInBytes() / 4
+	testCases := []struct {
+		quads []int
+		want  [][]int
+	}{
+		{
+			quads: []int{1, 2, 3},
+			want:  [][]int{{1, 2, 3}},
+		},
+		{
+			quads: []int{maxQuads},
+			want:  [][]int{{maxQuads}},
+		},
+		{
+			quads: []int{maxQuads + 1},
+			want:  [][]int{{maxQuads}, {1}},
+		},
+		{
+			quads: []int{maxQuads, 1},
+			want:  [][]int{{maxQuads}, {1}},
+		},
+		{
+			quads: []int{1, 3*maxQuads - 1},
+			want:  [][]int{{1, maxQuads - 1}, {maxQuads}, {maxQuads}},
+		},
+	}
+	for _, tc := range testCases {
+		q := &commandQueue{}
+		for _, n := range tc.quads {
+			q.commands = append(q.commands, &drawImageCommand{nvertices: n * vs})
+		}
+		gs := q.commandGroups()
+		got := [][]int{}
+		for _, g := range gs {
+			ns := []int{}
+			for _, c := range g {
+				ns = append(ns, c.(*drawImageCommand).quadsNum())
+			}
+			got = append(got, ns)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("commandGroups() with quads %v: got: %v, want: %v", tc.quads, got, tc.want)
+		}
+	}
+}
+
+func TestDrawImageCommandMerge(t *testing.T) {
+	dst := &Image{}
+	src := &Image{}
+	vs := make([]float32, 24)
+
+	modes := []driver.CompositeMode{
+		driver.CompositeModeSourceOver,
+		driver.CompositeModeSourceOver,
+		driver.CompositeModeMultiply,
+		driver.CompositeModeMultiply,
+		driver.CompositeModeMin,
+		driver.CompositeModeMax,
+		driver.CompositeModeSubtract,
+		driver.CompositeModeLighter,
+	}
+	q := &commandQueue{}
+	for _, m := range modes {
+		q.EnqueueDrawImageCommand(dst, src, vs, nil, m, FilterNearest, nil, 0, 0)
+	}
+	if got, want := len(q.commands), 6; got != want {
+		t.Errorf("len(commands): got: %d, want: %d", got, want)
+	}
+}
+
+func TestDrawImageCommandMergeAddress(t *testing.T) {
+	dst := &Image{}
+	src := &Image{}
+	vs := make([]float32, QuadVertexSizeInBytes()/4)
+
+	addresses := []driver.Address{
+		driver.AddressClampToZero,
+		driver.AddressClampToZero,
+		driver.AddressRepeat,
+		driver.AddressRepeat,
+		driver.AddressMirrorRepeat,
+		driver.AddressClampToEdge,
+	}
+	q := &commandQueue{}
+	for _, a := range addresses {
+		q.EnqueueDrawImageCommand(dst, src, vs, nil, driver.CompositeModeSourceOver, FilterNearest, nil, 0, a)
+	}
+	if got, want := len(q.commands), 4; got != want {
+		t.Errorf("len(commands): got: %d, want: %d", got, want)
+	}
+}
+
+func TestDisposeCommandBatching(t *testing.T) {
+	dst := &Image{}
+	src := &Image{}
+	vs := make([]float32, 24)
+
+	q := &commandQueue{}
+	q.EnqueueDrawImageCommand(dst, src, vs, nil, driver.CompositeModeSourceOver, FilterNearest, nil, 0, 0)
+	q.EnqueueDisposeCommand(&disposeCommand{target: &Image{}})
+	q.EnqueueDrawImageCommand(dst, src, vs, nil, driver.CompositeModeSourceOver, FilterNearest, nil, 0, 0)
+	if got, want := len(q.commands), 1; got != want {
+		t.Fatalf("len(commands): got: %d, want: %d", got, want)
+	}
+
+	// A dispose command must be executed before a new image is created.
+	q.Enqueue(&newImageCommand{result: &Image{}, width: 1, height: 1})
+	if got, want := len(q.commands), 3; got != want {
+		t.Fatalf("len(commands): got: %d, want: %d", got, want)
+	}
+	if _, ok := q.commands[1].(*disposeCommand); !ok {
+		t.Errorf("commands[1]: got: %T, want: *disposeCommand", q.commands[1])
+	}
+	if _, ok := q.commands[2].(*newImageCommand); !ok {
+		t.Errorf("commands[2]: got: %T, want: *newImageCommand", q.commands[2])
+	}
+}
+
+func TestFlushBeforeInitialized(t *testing.T) {
+	if theOpenGLState.initialized {
+		t.Skip("the OpenGL state is already initialized")
+	}
+	dst := &Image{}
+	src := &Image{}
+	vs := make([]float32, QuadVertexSizeInBytes()/4)
+
+	q := &commandQueue{}
+	q.Enqueue(&newImageCommand{result: dst, width: 1, height: 1})
+	q.EnqueueDrawImageCommand(dst, src, vs, nil, driver.CompositeModeSourceOver, FilterNearest, nil, 0, 0)
+	if err := q.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	// The commands are kept until the OpenGL state is initialized.
+	if got, want := len(q.commands), 2; got != want {
+		t.Errorf("len(commands): got: %d, want: %d", got, want)
+	}
+}
+
+func TestReplacePixelsCommandMerge(t *testing.T) {
+	dst := &Image{}
+	src := &Image{}
+	vs := make([]float32, 24)
+
+	q := &commandQueue{}
+	q.EnqueueReplacePixelsCommand(&replacePixelsRegion{dst: dst})
+	q.EnqueueReplacePixelsCommand(&replacePixelsRegion{dst: src})
+	q.EnqueueReplacePixelsCommand(&replacePixelsRegion{dst: dst})
+	q.EnqueueDrawImageCommand(dst, src, vs, nil, driver.CompositeModeSourceOver, FilterNearest, nil, 0, 0)
+	q.EnqueueReplacePixelsCommand(&replacePixelsRegion{dst: src})
+	q.EnqueueDisposeCommand(&disposeCommand{target: &Image{}})
+	q.EnqueueReplacePixelsCommand(&replacePixelsRegion{dst: src})
+
+	want := []int{3, 0, 1, 0, 1}
+	if got := len(q.commands); got != len(want) {
+		t.Fatalf("len(commands): got: %d, want: %d", got, len(want))
+	}
+	for i, n := range want {
+		c, ok := q.commands[i].(*replacePixelsCommand)
+		if n == 0 {
+			if ok {
+				t.Errorf("commands[%d]: got: %T, want: not *replacePixelsCommand", i, q.commands[i])
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("commands[%d]: got: %T, want: *replacePixelsCommand", i, q.commands[i])
+			continue
+		}
+		if got := len(c.regions); got != n {
+			t.Errorf("len(commands[%d].regions): got: %d, want: %d", i, got, n)
+		}
+	}
+}
+
+func TestReorder(t *testing.T) {
+	a := &Image{}
+	b := &Image{}
+	x := &Image{}
+	y := &Image{}
+	vn := QuadVertexSizeInBytes() / 4
+
+	type draw struct {
+		dst *Image
+		src *Image
+	}
+	testCases := []struct {
+		name  string
+		draws []draw
+		want  [][]float32
+	}{
+		{
+			name:  "interleaved destinations",
+			draws: []draw{{x, a}, {y, b}, {x, a}, {y, b}, {x, a}},
+			want:  [][]float32{{0, 2, 4}, {1, 3}},
+		},
+		{
+			name:  "read after write",
+			draws: []draw{{y, x}, {x, a}, {y, x}},
+			want:  [][]float32{{0}, {1}, {2}},
+		},
+		{
+			name:  "write after read",
+			draws: []draw{{x, a}, {y, x}, {x, a}},
+			want:  [][]float32{{0}, {1}, {2}},
+		},
+		{
+			name:  "write after write",
+			draws: []draw{{x, a}, {x, b}, {x, a}},
+			want:  [][]float32{{0}, {1}, {2}},
+		},
+		{
+			name:  "independent command in between",
+			draws: []draw{{x, a}, {y, b}, {y, a}, {x, a}},
+			want:  [][]float32{{0, 3}, {1}, {2}},
+		},
+	}
+	for _, tc := range testCases {
+		q := &commandQueue{}
+		for i, d := range tc.draws {
+			vs := make([]float32, vn)
+			vs[0] = float32(i)
+			q.EnqueueDrawImageCommand(d.dst, d.src, vs, nil, driver.CompositeModeSourceOver, FilterNearest, nil, 0, 0)
+		}
+		q.reorder()
+
+		got := [][]float32{}
+		n := 0
+		for _, c := range q.commands {
+			ids := []float32{}
+			for i := 0; i < c.NumVertices()/vn; i++ {
+				ids = append(ids, q.vertices[n+i*vn])
+			}
+			n += c.NumVertices()
+			got = append(got, ids)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got: %v, want: %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestReorderBarrier(t *testing.T) {
+	x := &Image{}
+	y := &Image{}
+	src := &Image{}
+	vs := make([]float32, QuadVertexSizeInBytes()/4)
+
+	q := &commandQueue{}
+	q.EnqueueDrawImageCommand(x, src, vs, nil, driver.CompositeModeSourceOver, FilterNearest, nil, 0, 0)
+	q.EnqueueDrawImageCommand(y, src, vs, nil, driver.CompositeModeSourceOver, FilterNearest, nil, 0, 0)
+	q.EnqueueReplacePixelsCommand(&replacePixelsRegion{dst: src})
+	q.EnqueueDrawImageCommand(x, src, vs, nil, driver.CompositeModeSourceOver, FilterNearest, nil, 0, 0)
+	q.reorder()
+	if got, want := len(q.commands), 4; got != want {
+		t.Errorf("len(commands): got: %d, want: %d", got, want)
+	}
+}
+
+func TestTextureSize(t *testing.T) {
+	testCases := []struct {
+		width  int
+		height int
+		npot   bool
+		wantW  int
+		wantH  int
+	}{
+		{1, 1, false, 1, 1},
+		{16, 16, false, 16, 16},
+		{17, 3, false, 32, 4},
+		{17, 3, true, 17, 3},
+		{1000, 600, true, 1000, 600},
+		{4096, 4096, false, 4096, 4096},
+	}
+	for _, c := range testCases {
+		w, h := textureSize(c.width, c.height, c.npot)
+		if w != c.wantW || h != c.wantH {
+			t.Errorf("textureSize(%d, %d, %t): got: (%d, %d), want: (%d, %d)", c.width, c.height, c.npot, w, h, c.wantW, c.wantH)
+		}
+	}
+}
@@ -12,5 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package graphics represents a low layer for graphics using OpenGL.
+// Package graphics represents a low layer for graphics.
+//
+// The drawing commands are queued and executed against a Driver. By default, the OpenGL context is used as the Driver.
 package graphics
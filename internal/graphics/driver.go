@@ -0,0 +1,152 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphics
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/driver"
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+)
+
+// Driver represents a graphics driver that executes the drawing commands.
+//
+// The commands in the command queue are executed against a Driver instead of a specific
+// graphics library. The handles and the states are the types defined in the driver package,
+// and don't depend on any specific graphics library. *opengl.Context implements Driver,
+// and *software.Driver implements Driver to render on CPU e.g. for testing.
+//
+// The vertex attributes of a program are specified by the names at NewProgram,
+// and then are referred by their indices.
+type Driver interface {
+	Reset() error
+
+	// ResetStateCache discards the cached state like the bound texture, and sets the state that the driver assumes again.
+	ResetStateCache()
+	Flush()
+	MaxTextureSize() int
+
+	// IsNPOTTextureAvailable reports whether textures of which sizes are not powers of 2 are available.
+	IsNPOTTextureAvailable() bool
+
+	// IsPixelFormatAvailable reports whether textures of the given pixel format are available.
+	IsPixelFormatAvailable(format driver.PixelFormat) bool
+	NewTexture(width, height int, format driver.PixelFormat) (driver.Texture, error)
+
+	// NewCompressedTexture creates a texture of the compressed format with the compressed texels data.
+	// The bound texture is changed.
+	NewCompressedTexture(width, height int, format driver.PixelFormat, data []byte) (driver.Texture, error)
+	BindTexture(t driver.Texture)
+
+	// BindTextureAt binds the texture to the given texture unit. The active texture unit is not changed.
+	BindTextureAt(unit int, t driver.Texture)
+	DeleteTexture(t driver.Texture)
+	IsTexture(t driver.Texture) bool
+	TexSubImage2D(p []byte, format driver.PixelFormat, x, y, width, height int)
+
+	// IsPixelBufferAvailable reports whether TexSubImages2DFromPixelBuffer is available.
+	IsPixelBufferAvailable() bool
+
+	// TexSubImages2DFromPixelBuffer uploads p to a pixel buffer at once, and then updates the regions
+	// of the textures with the pixels at the offsets in the buffer.
+	// The bound texture is changed.
+	TexSubImages2DFromPixelBuffer(p []byte, uploads []driver.TextureUpload)
+
+	// IsUploadWorkerAvailable reports whether TexSubImage2DOnWorker is available.
+	IsUploadWorkerAvailable() bool
+
+	// TexSubImage2DOnWorker updates the region of the texture with the texels on the upload worker, which runs in
+	// parallel with the rendering with a context sharing the textures. texels is called on the upload worker.
+	// The texture must not be used until WaitUpload is called for the texture.
+	TexSubImage2DOnWorker(t driver.Texture, texels func() []byte, format driver.PixelFormat, x, y, width, height int)
+
+	// WaitUpload waits for the uploads to the texture by TexSubImage2DOnWorker to finish.
+	WaitUpload(t driver.Texture)
+
+	// CopyTexSubImage2D copies the region (sx, sy) - (sx+width, sy+height) of the current framebuffer
+	// to (x, y) of the bound texture.
+	CopyTexSubImage2D(x, y, sx, sy, width, height int)
+
+	NewFramebuffer(texture driver.Texture) (driver.Framebuffer, error)
+	DeleteFramebuffer(f driver.Framebuffer)
+	ScreenFramebuffer() driver.Framebuffer
+	FramebufferPixels(f driver.Framebuffer, format driver.PixelFormat, width, height int) ([]byte, error)
+	SetViewport(f driver.Framebuffer, width, height int)
+	ResetViewportSize()
+
+	NewDepthRenderbuffer(width, height int) (driver.Renderbuffer, error)
+	DeleteRenderbuffer(r driver.Renderbuffer)
+	AttachDepthRenderbuffer(f driver.Framebuffer, r driver.Renderbuffer) error
+
+	NewShader(shaderType driver.ShaderType, source string) (driver.Shader, error)
+	DeleteShader(s driver.Shader)
+	NewProgram(shaders []driver.Shader, attributes []string) (driver.Program, error)
+	UseProgram(p driver.Program)
+	DeleteProgram(p driver.Program)
+	UniformInt(p driver.Program, location string, v int)
+	UniformFloat(p driver.Program, location string, v float32)
+	UniformFloats(p driver.Program, location string, v []float32)
+
+	NewArrayBuffer(size int) driver.Buffer
+	NewElementArrayBuffer(indices []uint16) driver.Buffer
+	BindArrayBuffer(b driver.Buffer)
+	BindElementArrayBuffer(b driver.Buffer)
+	BufferSubData(bufferType driver.BufferType, data []float32)
+	DeleteBuffer(b driver.Buffer)
+	VertexAttribPointer(p driver.Program, index int, size int, dataType driver.DataType, stride int, offset int)
+	EnableVertexAttribArray(p driver.Program, index int)
+	DisableVertexAttribArray(p driver.Program, index int)
+	VertexAttribDivisor(p driver.Program, index int, divisor int)
+
+	BlendFunc(mode driver.CompositeMode)
+	SetScissor(x, y, width, height int)
+	DisableScissor()
+	ColorMask(r, g, b, a bool)
+
+	// SetDepthTest specifies whether the depth test is enabled. The depth buffer is updated only when the test is enabled.
+	SetDepthTest(enabled bool)
+
+	// ClearDepth clears the depth buffer of the current framebuffer. The scissor test is applied to clearing.
+	ClearDepth()
+
+	DrawElements(mode driver.Mode, len int, offsetInBytes int)
+
+	// IsInstancingAvailable reports whether VertexAttribDivisor and DrawElementsInstanced are available.
+	IsInstancingAvailable() bool
+	DrawElementsInstanced(mode driver.Mode, len int, offsetInBytes int, instanceCount int)
+
+	// IsStandardDerivativesAvailable reports whether dFdx and dFdy are available in fragment shaders.
+	IsStandardDerivativesAvailable() bool
+}
+
+var _ Driver = (*opengl.Context)(nil)
+
+// theDriver is the graphics driver specified by SetDriver.
+var theDriver Driver
+
+// SetDriver sets the graphics driver.
+//
+// If SetDriver is not called, the current OpenGL context is used.
+// SetDriver is useful to inject a mock driver e.g. for testing.
+//
+// SetDriver must be called before any commands are executed.
+func SetDriver(driver Driver) {
+	theDriver = driver
+}
+
+func currentDriver() Driver {
+	if theDriver != nil {
+		return theDriver
+	}
+	return opengl.GetContext()
+}
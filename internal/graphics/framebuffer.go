@@ -15,7 +15,7 @@
 package graphics
 
 import (
-	"github.com/hajimehoshi/ebiten/internal/opengl"
+	"github.com/hajimehoshi/ebiten/internal/driver"
 	"github.com/hajimehoshi/ebiten/internal/web"
 )
 
@@ -38,31 +38,55 @@ func orthoProjectionMatrix(left, right, bottom, top int) []float32 {
 
 // framebuffer is a wrapper of OpenGL's framebuffer.
 type framebuffer struct {
-	native    opengl.Framebuffer
+	native    driver.Framebuffer
 	proMatrix []float32
 	width     int
 	height    int
+
+	// depth is the depth buffer attached to the framebuffer. depth is nil if the framebuffer doesn't have a depth buffer.
+	depth driver.Renderbuffer
+
+	// screen indicates whether the framebuffer is the screen framebuffer, which is not owned by Ebiten.
+	screen bool
 }
 
 // newFramebufferFromTexture creates a framebuffer from the given texture.
-func newFramebufferFromTexture(texture *texture, width, height int) (*framebuffer, error) {
-	native, err := opengl.GetContext().NewFramebuffer(opengl.Texture(texture.native))
+//
+// If depth is true, a depth buffer is attached to the framebuffer and is cleared.
+func newFramebufferFromTexture(texture *texture, width, height int, depth bool) (*framebuffer, error) {
+	native, err := currentDriver().NewFramebuffer(texture.native)
 	if err != nil {
 		return nil, err
 	}
-	return &framebuffer{
+	f := &framebuffer{
 		native: native,
 		width:  width,
 		height: height,
-	}, nil
+	}
+	if !depth {
+		return f, nil
+	}
+
+	r, err := currentDriver().NewDepthRenderbuffer(width, height)
+	if err != nil {
+		return nil, err
+	}
+	if err := currentDriver().AttachDepthRenderbuffer(native, r); err != nil {
+		return nil, err
+	}
+	f.depth = r
+	// The initial content of a renderbuffer is undefined.
+	f.clearDepth()
+	return f, nil
 }
 
 // newScreenFramebuffer creates a framebuffer for the screen.
 func newScreenFramebuffer(width, height int) *framebuffer {
 	return &framebuffer{
-		native: opengl.GetContext().ScreenFramebuffer(),
+		native: currentDriver().ScreenFramebuffer(),
 		width:  width,
 		height: height,
+		screen: true,
 	}
 }
 
@@ -82,7 +106,14 @@ func (f *framebuffer) viewportSize() (int, int) {
 // setAsViewport sets the framebuffer as the current viewport.
 func (f *framebuffer) setAsViewport() {
 	w, h := f.viewportSize()
-	opengl.GetContext().SetViewport(f.native, w, h)
+	currentDriver().SetViewport(f.native, w, h)
+}
+
+// clearDepth clears the depth buffer of the framebuffer.
+func (f *framebuffer) clearDepth() {
+	f.setAsViewport()
+	currentDriver().DisableScissor()
+	currentDriver().ClearDepth()
 }
 
 // projectionMatrix returns a projection matrix of the framebuffer.
@@ -15,9 +15,13 @@
 package graphics
 
 import (
+	"errors"
+	"fmt"
+	"image"
+	"sync/atomic"
+
 	"github.com/hajimehoshi/ebiten/internal/affine"
-	"github.com/hajimehoshi/ebiten/internal/math"
-	"github.com/hajimehoshi/ebiten/internal/opengl"
+	"github.com/hajimehoshi/ebiten/internal/driver"
 )
 
 var (
@@ -30,7 +34,7 @@ var (
 // MaxImageSize returns the maximum of width/height of an image.
 func MaxImageSize() int {
 	if maxTextureSize == 0 {
-		maxTextureSize = opengl.GetContext().MaxTextureSize()
+		maxTextureSize = currentDriver().MaxTextureSize()
 		if maxTextureSize == 0 {
 			panic("graphics: failed to get the max texture size")
 		}
@@ -39,28 +43,89 @@ func MaxImageSize() int {
 	return s
 }
 
+var memoryUsage int64
+
+// MemoryUsage returns the total size in bytes of the textures for images.
+//
+// When non-power-of-two textures are not available, the textures are counted with the sizes expanded to powers of 2.
+// The screen framebuffer is not counted.
+// The usage is updated when the commands to create or dispose textures are executed.
+func MemoryUsage() int64 {
+	return atomic.LoadInt64(&memoryUsage)
+}
+
 // Image represents an image that is implemented with OpenGL.
 type Image struct {
 	texture     *texture
 	framebuffer *framebuffer
 	width       int
 	height      int
+	format      driver.PixelFormat
+
+	// depth indicates whether the image has a depth buffer.
+	depth bool
 }
 
-func NewImage(width, height int) *Image {
+// alphaColorM is the color matrix applied before the given color matrix when the source is an alpha-only image.
+//
+// An alpha-only texture is sampled as black with the alpha. alphaColorM converts it to white
+// so that an alpha-only image works as a mask that can be colored with a color matrix.
+var alphaColorM = (*affine.ColorM)(nil).Translate(1, 1, 1, 0)
+
+// NewImage creates an image.
+//
+// If depth is true, the image has a depth buffer. The depth buffer is used when the image is a render target.
+func NewImage(width, height int, format driver.PixelFormat, depth bool) *Image {
 	i := &Image{
 		width:  width,
 		height: height,
+		format: format,
+		depth:  depth,
 	}
 	c := &newImageCommand{
 		result: i,
 		width:  width,
 		height: height,
+		format: format,
 	}
 	theCommandQueue.Enqueue(c)
 	return i
 }
 
+// NewCompressedImage creates an image of the compressed pixel format with the compressed texels data.
+//
+// The format must be available (see IsPixelFormatAvailable). A compressed image can't be a render target,
+// and its pixels can't be replaced or read.
+func NewCompressedImage(width, height int, format driver.PixelFormat, data []byte) *Image {
+	if !format.IsCompressed() {
+		panic("graphics: the pixel format must be compressed")
+	}
+	i := &Image{
+		width:  width,
+		height: height,
+		format: format,
+	}
+	c := &newImageCommand{
+		result: i,
+		width:  width,
+		height: height,
+		format: format,
+		data:   data,
+	}
+	theCommandQueue.Enqueue(c)
+	return i
+}
+
+// IsPixelFormatAvailable reports whether textures of the pixel format are natively available.
+//
+// IsPixelFormatAvailable returns false before the OpenGL state is initialized by ResetGLState.
+func IsPixelFormatAvailable(format driver.PixelFormat) bool {
+	if !theOpenGLState.initialized {
+		return false
+	}
+	return currentDriver().IsPixelFormatAvailable(format)
+}
+
 func NewScreenFramebufferImage(width, height int) *Image {
 	i := &Image{
 		width:  width,
@@ -79,52 +144,247 @@ func (i *Image) Dispose() {
 	c := &disposeCommand{
 		target: i,
 	}
-	theCommandQueue.Enqueue(c)
+	theCommandQueue.EnqueueDisposeCommand(c)
 }
 
 func (i *Image) Size() (int, int) {
 	return i.width, i.height
 }
 
-func (i *Image) DrawImage(src *Image, vertices []float32, clr *affine.ColorM, mode opengl.CompositeMode, filter Filter) {
-	theCommandQueue.EnqueueDrawImageCommand(i, src, vertices, clr, mode, filter)
+// Format returns the pixel format of the image.
+func (i *Image) Format() driver.PixelFormat {
+	return i.format
+}
+
+// HasDepth returns a boolean value indicating whether the image has a depth buffer.
+func (i *Image) HasDepth() bool {
+	return i.depth
+}
+
+// DrawImage draws the src image on the image i.
+//
+// clip is the clipping region on i. If clip is nil, the drawing is not clipped.
+// disabled is the color channels of i that are not changed.
+//
+// If i has a depth buffer, the depth test is applied with the depth values of the vertices
+// unless mode is CompositeModeCopy or CompositeModeClear. See also QuadVertices.
+//
+// address is the address mode to sample src. If address wraps, the whole src is wrapped.
+func (i *Image) DrawImage(src *Image, vertices []float32, clr *affine.ColorM, mode driver.CompositeMode, filter Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	if i.format == driver.PixelFormatAlpha8 {
+		panic("graphics: an alpha-only image can't be a render target")
+	}
+	if i.format.IsCompressed() {
+		panic("graphics: a compressed image can't be a render target")
+	}
+	if src.format == driver.PixelFormatAlpha8 {
+		clr = alphaColorM.Concat(clr)
+	}
+	theCommandQueue.EnqueueDrawImageCommand(i, src, vertices, clr, mode, filter, clip, disabled, address)
+}
+
+// DrawImageWithLUT draws the image src on the image i with nearest filter, converting the colors
+// with the color look-up table lut after applying clr.
+//
+// lut consists of N tiles of NxN texels arranged horizontally where N is the height of lut.
+// The n-th tile is for the n-th blue entry, and the X and Y axes in a tile are for the red and green entries.
+// lut must be at the origin of its texture.
+func (i *Image) DrawImageWithLUT(src, lut *Image, vertices []float32, clr *affine.ColorM, mode driver.CompositeMode) {
+	if i.format == driver.PixelFormatAlpha8 {
+		panic("graphics: an alpha-only image can't be a render target")
+	}
+	if i.format.IsCompressed() {
+		panic("graphics: a compressed image can't be a render target")
+	}
+	if lut.width != lut.height*lut.height {
+		panic(fmt.Sprintf("graphics: the LUT size must be (N*N, N) but was (%d, %d)", lut.width, lut.height))
+	}
+	if src.format == driver.PixelFormatAlpha8 {
+		clr = alphaColorM.Concat(clr)
+	}
+	theCommandQueue.EnqueueDrawImageWithLUTCommand(i, src, lut, vertices, clr, mode)
 }
 
+// DrawImageWithPalette draws the image src on the image i with nearest filter, resolving the colors of src
+// with palette before applying clr.
+//
+// src must be an alpha-only image whose alpha values are the indices of palette. palette is a Nx1 image of
+// the colors, and must be at the origin of its texture.
+func (i *Image) DrawImageWithPalette(src, palette *Image, vertices []float32, clr *affine.ColorM, mode driver.CompositeMode, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	if i.format == driver.PixelFormatAlpha8 {
+		panic("graphics: an alpha-only image can't be a render target")
+	}
+	if i.format.IsCompressed() {
+		panic("graphics: a compressed image can't be a render target")
+	}
+	if src.format != driver.PixelFormatAlpha8 {
+		panic("graphics: the source image of DrawImageWithPalette must be alpha-only")
+	}
+	theCommandQueue.EnqueueDrawImageWithPaletteCommand(i, src, palette, vertices, clr, mode, clip, disabled, address)
+}
+
+// MaxNormalMapLights is the maximum number of the lights of NormalMapLighting.
+const MaxNormalMapLights = 4
+
+// NormalMapLighting represents the lights for DrawImageWithNormalMap.
+//
+// The values are laid out as the uniform variables of the shader.
+type NormalMapLighting struct {
+	// Ambient is the color of the ambient light. The last element is not used.
+	Ambient [4]float32
+
+	// Positions is a 4x4 column-major matrix. Each column is a light: (x, y, z, 1) is the position of a point light
+	// in the destination, and (x, y, z, 0) is the direction toward a directional light.
+	Positions [4 * MaxNormalMapLights]float32
+
+	// Colors is a 4x4 column-major matrix. Each column is the color (r, g, b) and the radius of a light.
+	// The radius 0 means no attenuation. The color of an unused light must be black.
+	Colors [4 * MaxNormalMapLights]float32
+}
+
+// DrawImageWithNormalMap draws the image src on the image i with nearest filter, lighting the colors with
+// normalMap and lighting after applying clr.
+//
+// normalMap must have the same size as src, and both must be at the origin of their textures.
+func (i *Image) DrawImageWithNormalMap(src, normalMap *Image, lighting *NormalMapLighting, vertices []float32, clr *affine.ColorM, mode driver.CompositeMode, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	if i.format == driver.PixelFormatAlpha8 {
+		panic("graphics: an alpha-only image can't be a render target")
+	}
+	if i.format.IsCompressed() {
+		panic("graphics: a compressed image can't be a render target")
+	}
+	if src.width != normalMap.width || src.height != normalMap.height {
+		panic("graphics: the normal map must have the same size as the source image")
+	}
+	theCommandQueue.EnqueueDrawImageWithNormalMapCommand(i, src, normalMap, lighting, vertices, clr, mode, clip, disabled, address)
+}
+
+// DistanceField represents the parameters to render a distance field at DrawImageWithDistanceField.
+type DistanceField struct {
+	// Spread is the range of the distances in source pixels. The alpha value a of the source represents
+	// the signed distance (a - 0.5) * Spread from the edge, positive inside.
+	Spread float32
+
+	// Scale is the scale from source pixels to destination pixels, used to antialias the edges.
+	Scale float32
+
+	// OutlineWidth and GlowWidth are the widths in source pixels. 0 means no outline or no glow.
+	OutlineWidth float32
+	GlowWidth    float32
+
+	// OutlineColor and GlowColor are non-premultiplied colors.
+	OutlineColor [4]float32
+	GlowColor    [4]float32
+}
+
+// DrawImageWithDistanceField draws the image src, a distance field, on the image i with linear filter.
+//
+// The shape is filled with white, which is tinted by clr, and the outline and the glow are drawn behind it.
+func (i *Image) DrawImageWithDistanceField(src *Image, distanceField *DistanceField, vertices []float32, clr *affine.ColorM, mode driver.CompositeMode, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	if i.format == driver.PixelFormatAlpha8 {
+		panic("graphics: an alpha-only image can't be a render target")
+	}
+	if i.format.IsCompressed() {
+		panic("graphics: a compressed image can't be a render target")
+	}
+	theCommandQueue.EnqueueDrawImageWithDistanceFieldCommand(i, src, distanceField, vertices, clr, mode, clip, disabled, address)
+}
+
+// Pixels returns the pixels of the image in the image's pixel format.
+//
+// The pixels of an alpha-only image can't be read since an alpha-only texture can't be attached to a framebuffer.
+// The pixels can't be read before the OpenGL state is initialized by ResetGLState.
 func (i *Image) Pixels() ([]byte, error) {
+	if i.format == driver.PixelFormatAlpha8 {
+		panic("graphics: the pixels of an alpha-only image can't be read")
+	}
+	if i.format.IsCompressed() {
+		panic("graphics: the pixels of a compressed image can't be read")
+	}
+	if !theOpenGLState.initialized {
+		return nil, errors.New("graphics: the pixels can't be read before the OpenGL state is initialized")
+	}
 	// Flush the enqueued commands so that pixels are certainly read.
 	if err := theCommandQueue.Flush(); err != nil {
 		return nil, err
 	}
+	i.texture.waitUpload()
 	f, err := i.createFramebufferIfNeeded()
 	if err != nil {
 		return nil, err
 	}
-	return opengl.GetContext().FramebufferPixels(f.native, i.width, i.height)
+	p, err := currentDriver().FramebufferPixels(f.native, i.texture.format, i.width, i.height)
+	if err != nil {
+		return nil, err
+	}
+	if i.texture.format == driver.PixelFormatSRGBA8 {
+		p = srgbTexelsToPixels(p)
+	}
+	return convertPixels(p, i.texture.format, i.format), nil
 }
 
+// ReplacePixels replaces the pixels of the region (x, y) - (x+width, y+height) with p in the image's pixel format.
 func (i *Image) ReplacePixels(p []byte, x, y, width, height int) {
+	if i.format.IsCompressed() {
+		panic("graphics: the pixels of a compressed image can't be replaced")
+	}
 	pixels := make([]byte, len(p))
 	copy(pixels, p)
-	c := &replacePixelsCommand{
+	theCommandQueue.EnqueueReplacePixelsCommand(&replacePixelsRegion{
 		dst:    i,
 		pixels: pixels,
 		x:      x,
 		y:      y,
 		width:  width,
 		height: height,
+	})
+}
+
+// CopyPixels copies the region (sx, sy) - (sx+width, sy+height) of src to (dx, dy) of the image i.
+//
+// CopyPixels doesn't use the drawing pipeline: the color matrix, the composite mode, the clipping region
+// and the color mask are not applied.
+//
+// The pixel formats of i and src must be the same, and must be neither alpha-only nor compressed.
+func (i *Image) CopyPixels(src *Image, sx, sy, width, height, dx, dy int) {
+	if i.format != src.format || i.format == driver.PixelFormatAlpha8 || i.format.IsCompressed() {
+		panic("graphics: the pixels can't be copied between the images of the pixel formats")
+	}
+	c := &copyPixelsCommand{
+		dst:    i,
+		src:    src,
+		sx:     sx,
+		sy:     sy,
+		width:  width,
+		height: height,
+		dx:     dx,
+		dy:     dy,
+	}
+	theCommandQueue.Enqueue(c)
+}
+
+// ClearDepth clears the depth buffer of the image.
+//
+// If the image doesn't have a depth buffer, ClearDepth does nothing.
+func (i *Image) ClearDepth() {
+	if !i.depth {
+		return
+	}
+	c := &clearDepthCommand{
+		dst: i,
 	}
 	theCommandQueue.Enqueue(c)
 }
 
 func (i *Image) IsInvalidated() bool {
-	return !opengl.GetContext().IsTexture(i.texture.native)
+	return !currentDriver().IsTexture(i.texture.native)
 }
 
 func (i *Image) createFramebufferIfNeeded() (*framebuffer, error) {
 	if i.framebuffer != nil {
 		return i.framebuffer, nil
 	}
-	f, err := newFramebufferFromTexture(i.texture, math.NextPowerOf2Int(i.width), math.NextPowerOf2Int(i.height))
+	f, err := newFramebufferFromTexture(i.texture, i.texture.width, i.texture.height, i.depth)
 	if err != nil {
 		return nil, err
 	}
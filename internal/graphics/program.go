@@ -16,10 +16,10 @@ package graphics
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/hajimehoshi/ebiten/internal/affine"
-	emath "github.com/hajimehoshi/ebiten/internal/math"
-	"github.com/hajimehoshi/ebiten/internal/opengl"
+	"github.com/hajimehoshi/ebiten/internal/driver"
 	"github.com/hajimehoshi/ebiten/internal/web"
 )
 
@@ -27,7 +27,7 @@ import (
 type arrayBufferLayoutPart struct {
 	// TODO: This struct should belong to a program and know it.
 	name     string
-	dataType opengl.DataType
+	dataType driver.DataType
 	num      int
 }
 
@@ -38,6 +38,19 @@ type arrayBufferLayoutPart struct {
 type arrayBufferLayout struct {
 	parts []arrayBufferLayoutPart
 	total int
+
+	// firstAttrib is the index of the first part's attribute in a program.
+	// The attribute of the i-th part is referred by the index firstAttrib + i.
+	firstAttrib int
+}
+
+// attribNames returns the names of the attributes in the order of the parts.
+func (a *arrayBufferLayout) attribNames() []string {
+	names := make([]string, len(a.parts))
+	for i, p := range a.parts {
+		names[i] = p.name
+	}
+	return names
 }
 
 // totalBytes returns the size in bytes for one element of the array buffer.
@@ -54,28 +67,39 @@ func (a *arrayBufferLayout) totalBytes() int {
 }
 
 // newArrayBuffer creates OpenGL's buffer object for the array buffer.
-func (a *arrayBufferLayout) newArrayBuffer() opengl.Buffer {
-	return opengl.GetContext().NewArrayBuffer(a.totalBytes() * 4 * maxQuads)
+func (a *arrayBufferLayout) newArrayBuffer() driver.Buffer {
+	return currentDriver().NewArrayBuffer(a.totalBytes() * 4 * maxQuads)
 }
 
 // enable binds the array buffer the given program to use the array buffer.
-func (a *arrayBufferLayout) enable(program opengl.Program) {
-	for _, p := range a.parts {
-		opengl.GetContext().EnableVertexAttribArray(program, p.name)
+func (a *arrayBufferLayout) enable(program driver.Program) {
+	for i := range a.parts {
+		currentDriver().EnableVertexAttribArray(program, a.firstAttrib+i)
 	}
+	a.setPointers(program, 0)
+}
+
+// setPointers specifies the data locations of the currently bound array buffer.
+// offset is the offset in bytes where the data starts.
+func (a *arrayBufferLayout) setPointers(program driver.Program, offset int) {
 	total := a.totalBytes()
-	offset := 0
-	for _, p := range a.parts {
-		opengl.GetContext().VertexAttribPointer(program, p.name, p.num, p.dataType, total, offset)
+	for i, p := range a.parts {
+		currentDriver().VertexAttribPointer(program, a.firstAttrib+i, p.num, p.dataType, total, offset)
 		offset += p.dataType.SizeInBytes() * p.num
 	}
 }
 
+func (a *arrayBufferLayout) setDivisor(program driver.Program, divisor int) {
+	for i := range a.parts {
+		currentDriver().VertexAttribDivisor(program, a.firstAttrib+i, divisor)
+	}
+}
+
 // disable stops using the array buffer.
-func (a *arrayBufferLayout) disable(program opengl.Program) {
+func (a *arrayBufferLayout) disable(program driver.Program) {
 	// TODO: Disabling should be done in reversed order?
-	for _, p := range a.parts {
-		opengl.GetContext().DisableVertexAttribArray(program, p.name)
+	for i := range a.parts {
+		currentDriver().DisableVertexAttribArray(program, a.firstAttrib+i)
 	}
 }
 
@@ -86,55 +110,185 @@ var (
 		parts: []arrayBufferLayoutPart{
 			{
 				name:     "vertex",
-				dataType: opengl.Float,
-				num:      2,
+				dataType: driver.Float,
+				num:      3,
 			},
 			{
 				name:     "tex_coord",
-				dataType: opengl.Float,
+				dataType: driver.Float,
+				num:      4,
+			},
+			{
+				name:     "color_scale",
+				dataType: driver.Float,
+				num:      4,
+			},
+			{
+				name:     "color_translate",
+				dataType: driver.Float,
 				num:      4,
 			},
 		},
 	}
+
+	// theCornerArrayBufferLayout is the per-vertex array buffer layout for instanced drawing.
+	// The data is the four corners of the unit square and is shared by all the instances.
+	theCornerArrayBufferLayout = arrayBufferLayout{
+		parts: []arrayBufferLayoutPart{
+			{
+				name:     "corner",
+				dataType: driver.Float,
+				num:      2,
+			},
+		},
+	}
+
+	// theInstanceArrayBufferLayout is the per-instance array buffer layout for instanced drawing.
+	// One instance represents one quadrangle. See also (*commandQueue).instanceData.
+	//
+	// The attributes follow theCornerArrayBufferLayout's in a program.
+	theInstanceArrayBufferLayout = arrayBufferLayout{
+		firstAttrib: 1,
+		parts: []arrayBufferLayoutPart{
+			{
+				name:     "origin",
+				dataType: driver.Float,
+				num:      3,
+			},
+			{
+				name:     "edges",
+				dataType: driver.Float,
+				num:      4,
+			},
+			{
+				name:     "tex_region",
+				dataType: driver.Float,
+				num:      4,
+			},
+			{
+				name:     "color_scale",
+				dataType: driver.Float,
+				num:      4,
+			},
+			{
+				name:     "color_translate",
+				dataType: driver.Float,
+				num:      4,
+			},
+		},
+	}
+
+	// cornerVertices is the vertices of the unit square in the same order as quadrangles' vertices.
+	cornerVertices = []float32{
+		0, 0,
+		1, 0,
+		0, 1,
+		1, 1,
+	}
 )
 
 // openGLState is a state for OpenGL.
 type openGLState struct {
-	// arrayBuffer is OpenGL's array buffer (vertices data).
-	arrayBuffer opengl.Buffer
+	// arrayBuffers is OpenGL's array buffers (vertices data).
+	//
+	// The array buffers are used in rotation so that uploading vertices doesn't wait for
+	// the GPU to finish the draw calls using the previously uploaded vertices.
+	arrayBuffers []driver.Buffer
+
+	// arrayBufferIndex is the index of the currently bound array buffer in arrayBuffers.
+	arrayBufferIndex int
 
 	// elementArrayBuffer is OpenGL's element array buffer (indices data).
-	elementArrayBuffer opengl.Buffer
+	elementArrayBuffer driver.Buffer
+
+	// cornerBuffer is OpenGL's array buffer for the unit square's corners.
+	// cornerBuffer is used only when instancing is true.
+	cornerBuffer driver.Buffer
+
+	// instancing indicates whether instanced drawing is used.
+	//
+	// With instanced drawing, an array buffer holds one instance data per quadrangle
+	// instead of four vertices.
+	instancing bool
 
 	// programNearest is OpenGL's program for rendering a texture with nearest filter.
-	programNearest opengl.Program
+	programNearest driver.Program
 
 	// programLinear is OpenGL's program for rendering a texture with linear filter.
-	programLinear opengl.Program
+	programLinear driver.Program
+
+	programScreen driver.Program
+
+	// programColorLUT is OpenGL's program for rendering a texture with nearest filter and converting
+	// the colors with a color look-up table.
+	programColorLUT driver.Program
 
-	programScreen opengl.Program
+	// programPalette is OpenGL's program for rendering an alpha-only texture of palette indices with nearest filter
+	// and resolving the colors with a palette.
+	programPalette driver.Program
 
-	lastProgram                opengl.Program
-	lastProjectionMatrix       []float32
-	lastColorMatrix            []float32
-	lastColorMatrixTranslation []float32
-	lastSourceWidth            int
-	lastSourceHeight           int
+	// programNormalMap is OpenGL's program for rendering a texture with nearest filter and lighting it
+	// with a normal map.
+	programNormalMap driver.Program
+
+	// programDistanceField is OpenGL's program for rendering a distance field texture with linear filter.
+	programDistanceField driver.Program
+
+	// programAnisotropic is OpenGL's program for rendering a texture with anisotropic filter.
+	// programAnisotropic is zero when the standard derivatives are not available, and then linear filter is used
+	// instead.
+	programAnisotropic driver.Program
+
+	lastProgram driver.Program
+
+	// programStates is the states of the programs that have been used. See programState.
+	programStates map[driver.Program]*programState
 
 	indices []uint16
+
+	// initialized indicates whether the state has been initialized by reset.
+	// Until then, the commands are not executed but kept in the queue.
+	initialized bool
+}
+
+// programState is the last values of the uniform variables of a program.
+//
+// The values of uniform variables belong to each program and are kept while other programs are used,
+// so switching programs doesn't require sending the values again.
+type programState struct {
+	projectionMatrix       []float32
+	colorMatrix            []float32
+	colorMatrixTranslation []float32
+	sourceWidth            int
+	sourceHeight           int
+	depthTest              bool
+	address                driver.Address
+	sourceImageWidth       int
+	sourceImageHeight      int
+	sourceSRGB             bool
+	destinationSRGB        bool
+	scale                  float32
+	paletteTextureWidth    int
+	paletteTextureHeight   int
+	lighting               NormalMapLighting
+	distanceField          DistanceField
+	anisotropy             int
 }
 
 var (
 	// theOpenGLState is the OpenGL state in the current process.
 	theOpenGLState openGLState
 
-	zeroBuffer  opengl.Buffer
-	zeroProgram opengl.Program
+	zeroBuffer  driver.Buffer
+	zeroProgram driver.Program
 )
 
 const (
 	indicesNum = 1 << 16
 	maxQuads   = indicesNum / 6
+
+	// arrayBuffersNum is the number of the array buffers used in rotation.
+	arrayBuffersNum = 3
 )
 
 // ResetGLState resets or initializes the current OpenGL state.
@@ -142,92 +296,217 @@ func ResetGLState() error {
 	return theOpenGLState.reset()
 }
 
+// ResetGLStateCache discards the cached OpenGL state like the current program and framebuffer.
+//
+// ResetGLStateCache must be called when the OpenGL state might be changed outside of Ebiten,
+// e.g. by the host application that owns the OpenGL context.
+// ResetGLStateCache does nothing before the OpenGL state is initialized.
+func ResetGLStateCache() {
+	if !theOpenGLState.initialized {
+		return
+	}
+	currentDriver().ResetStateCache()
+	theOpenGLState.lastProgram = zeroProgram
+}
+
 // reset resets or initializes the OpenGL state.
 func (s *openGLState) reset() error {
-	if err := opengl.GetContext().Reset(); err != nil {
+	if err := currentDriver().Reset(); err != nil {
 		return err
 	}
+	s.instancing = currentDriver().IsInstancingAvailable()
 
 	s.lastProgram = zeroProgram
-	s.lastProjectionMatrix = nil
-	s.lastColorMatrix = nil
-	s.lastColorMatrixTranslation = nil
-	s.lastSourceWidth = 0
-	s.lastSourceHeight = 0
+	s.programStates = map[driver.Program]*programState{}
 
 	// When context lost happens, deleting programs or buffers is not necessary.
 	// However, it is not assumed that reset is called only when context lost happens.
 	// Let's delete them explicitly.
 	if s.programNearest != zeroProgram {
-		opengl.GetContext().DeleteProgram(s.programNearest)
+		currentDriver().DeleteProgram(s.programNearest)
 	}
 	if s.programLinear != zeroProgram {
-		opengl.GetContext().DeleteProgram(s.programLinear)
+		currentDriver().DeleteProgram(s.programLinear)
 	}
 	if s.programScreen != zeroProgram {
-		opengl.GetContext().DeleteProgram(s.programScreen)
+		currentDriver().DeleteProgram(s.programScreen)
+	}
+	if s.programColorLUT != zeroProgram {
+		currentDriver().DeleteProgram(s.programColorLUT)
+	}
+	if s.programPalette != zeroProgram {
+		currentDriver().DeleteProgram(s.programPalette)
+	}
+	if s.programNormalMap != zeroProgram {
+		currentDriver().DeleteProgram(s.programNormalMap)
+	}
+	if s.programDistanceField != zeroProgram {
+		currentDriver().DeleteProgram(s.programDistanceField)
+	}
+	if s.programAnisotropic != zeroProgram {
+		currentDriver().DeleteProgram(s.programAnisotropic)
 	}
 
 	// On browsers (at least Chrome), buffers are already detached from the context
 	// and must not be deleted by DeleteBuffer.
 	if !web.IsBrowser() {
-		if s.arrayBuffer != zeroBuffer {
-			opengl.GetContext().DeleteBuffer(s.arrayBuffer)
+		for _, b := range s.arrayBuffers {
+			currentDriver().DeleteBuffer(b)
 		}
 		if s.elementArrayBuffer != zeroBuffer {
-			opengl.GetContext().DeleteBuffer(s.elementArrayBuffer)
+			currentDriver().DeleteBuffer(s.elementArrayBuffer)
+		}
+		if s.cornerBuffer != zeroBuffer {
+			currentDriver().DeleteBuffer(s.cornerBuffer)
 		}
 	}
+	s.cornerBuffer = zeroBuffer
+
+	// The shaders are only the built-in ones: they are compiled once here, and a compiling error is fatal.
+	// TODO: When a custom shader API exists, recompile user shaders from their source files at runtime and show
+	// the compiling errors on an overlay instead of panicking, for fast iteration of effects (hot-reloading).
+	vertexShader := shaderVertexModelview
+	if s.instancing {
+		vertexShader = shaderVertexModelviewInstanced
+	}
+	shaderVertexModelviewNative, err := currentDriver().NewShader(driver.VertexShader, shader(vertexShader))
+	if err != nil {
+		panic(fmt.Sprintf("graphics: shader compiling error:\n%s", err))
+	}
+	defer currentDriver().DeleteShader(shaderVertexModelviewNative)
+
+	shaderFragmentNearestNative, err := currentDriver().NewShader(driver.FragmentShader, shader(shaderFragmentNearest))
+	if err != nil {
+		panic(fmt.Sprintf("graphics: shader compiling error:\n%s", err))
+	}
+	defer currentDriver().DeleteShader(shaderFragmentNearestNative)
+
+	shaderFragmentLinearNative, err := currentDriver().NewShader(driver.FragmentShader, shader(shaderFragmentLinear))
+	if err != nil {
+		panic(fmt.Sprintf("graphics: shader compiling error:\n%s", err))
+	}
+	defer currentDriver().DeleteShader(shaderFragmentLinearNative)
 
-	shaderVertexModelviewNative, err := opengl.GetContext().NewShader(opengl.VertexShader, shader(shaderVertexModelview))
+	shaderFragmentScreenNative, err := currentDriver().NewShader(driver.FragmentShader, shader(shaderFragmentScreen))
 	if err != nil {
 		panic(fmt.Sprintf("graphics: shader compiling error:\n%s", err))
 	}
-	defer opengl.GetContext().DeleteShader(shaderVertexModelviewNative)
+	defer currentDriver().DeleteShader(shaderFragmentScreenNative)
 
-	shaderFragmentNearestNative, err := opengl.GetContext().NewShader(opengl.FragmentShader, shader(shaderFragmentNearest))
+	shaderFragmentColorLUTNative, err := currentDriver().NewShader(driver.FragmentShader, shader(shaderFragmentColorLUT))
 	if err != nil {
 		panic(fmt.Sprintf("graphics: shader compiling error:\n%s", err))
 	}
-	defer opengl.GetContext().DeleteShader(shaderFragmentNearestNative)
+	defer currentDriver().DeleteShader(shaderFragmentColorLUTNative)
 
-	shaderFragmentLinearNative, err := opengl.GetContext().NewShader(opengl.FragmentShader, shader(shaderFragmentLinear))
+	shaderFragmentPaletteNative, err := currentDriver().NewShader(driver.FragmentShader, shader(shaderFragmentPalette))
 	if err != nil {
 		panic(fmt.Sprintf("graphics: shader compiling error:\n%s", err))
 	}
-	defer opengl.GetContext().DeleteShader(shaderFragmentLinearNative)
+	defer currentDriver().DeleteShader(shaderFragmentPaletteNative)
 
-	shaderFragmentScreenNative, err := opengl.GetContext().NewShader(opengl.FragmentShader, shader(shaderFragmentScreen))
+	shaderFragmentNormalMapNative, err := currentDriver().NewShader(driver.FragmentShader, shader(shaderFragmentNormalMap))
 	if err != nil {
 		panic(fmt.Sprintf("graphics: shader compiling error:\n%s", err))
 	}
-	defer opengl.GetContext().DeleteShader(shaderFragmentScreenNative)
+	defer currentDriver().DeleteShader(shaderFragmentNormalMapNative)
 
-	s.programNearest, err = opengl.GetContext().NewProgram([]opengl.Shader{
+	shaderFragmentDistanceFieldNative, err := currentDriver().NewShader(driver.FragmentShader, shader(shaderFragmentDistanceField))
+	if err != nil {
+		panic(fmt.Sprintf("graphics: shader compiling error:\n%s", err))
+	}
+	defer currentDriver().DeleteShader(shaderFragmentDistanceFieldNative)
+
+	attribs := theArrayBufferLayout.attribNames()
+	if s.instancing {
+		attribs = append(theCornerArrayBufferLayout.attribNames(), theInstanceArrayBufferLayout.attribNames()...)
+	}
+
+	s.programNearest, err = currentDriver().NewProgram([]driver.Shader{
 		shaderVertexModelviewNative,
 		shaderFragmentNearestNative,
-	})
+	}, attribs)
 	if err != nil {
 		return err
 	}
 
-	s.programLinear, err = opengl.GetContext().NewProgram([]opengl.Shader{
+	s.programLinear, err = currentDriver().NewProgram([]driver.Shader{
 		shaderVertexModelviewNative,
 		shaderFragmentLinearNative,
-	})
+	}, attribs)
 	if err != nil {
 		return err
 	}
 
-	s.programScreen, err = opengl.GetContext().NewProgram([]opengl.Shader{
+	s.programScreen, err = currentDriver().NewProgram([]driver.Shader{
 		shaderVertexModelviewNative,
 		shaderFragmentScreenNative,
-	})
+	}, attribs)
 	if err != nil {
 		return err
 	}
 
-	s.arrayBuffer = theArrayBufferLayout.newArrayBuffer()
+	s.programColorLUT, err = currentDriver().NewProgram([]driver.Shader{
+		shaderVertexModelviewNative,
+		shaderFragmentColorLUTNative,
+	}, attribs)
+	if err != nil {
+		return err
+	}
+
+	s.programPalette, err = currentDriver().NewProgram([]driver.Shader{
+		shaderVertexModelviewNative,
+		shaderFragmentPaletteNative,
+	}, attribs)
+	if err != nil {
+		return err
+	}
+
+	s.programNormalMap, err = currentDriver().NewProgram([]driver.Shader{
+		shaderVertexModelviewNative,
+		shaderFragmentNormalMapNative,
+	}, attribs)
+	if err != nil {
+		return err
+	}
+
+	s.programDistanceField, err = currentDriver().NewProgram([]driver.Shader{
+		shaderVertexModelviewNative,
+		shaderFragmentDistanceFieldNative,
+	}, attribs)
+	if err != nil {
+		return err
+	}
+
+	// The derivatives might not be available e.g. on OpenGL ES 2.0. Then linear filter is used instead.
+	s.programAnisotropic = zeroProgram
+	if currentDriver().IsStandardDerivativesAvailable() {
+		shaderFragmentAnisotropicNative, err := currentDriver().NewShader(driver.FragmentShader, shader(shaderFragmentAnisotropic))
+		if err != nil {
+			panic(fmt.Sprintf("graphics: shader compiling error:\n%s", err))
+		}
+		defer currentDriver().DeleteShader(shaderFragmentAnisotropicNative)
+
+		s.programAnisotropic, err = currentDriver().NewProgram([]driver.Shader{
+			shaderVertexModelviewNative,
+			shaderFragmentAnisotropicNative,
+		}, attribs)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.instancing {
+		s.cornerBuffer = currentDriver().NewArrayBuffer(len(cornerVertices) * driver.Float.SizeInBytes())
+		currentDriver().BufferSubData(driver.ArrayBuffer, cornerVertices)
+	}
+	// Even with instanced drawing, an array buffer is big enough to hold the instance data.
+	s.arrayBuffers = make([]driver.Buffer, arrayBuffersNum)
+	for i := range s.arrayBuffers {
+		s.arrayBuffers[i] = theArrayBufferLayout.newArrayBuffer()
+	}
+	s.arrayBufferIndex = 0
+	currentDriver().BindArrayBuffer(s.arrayBuffers[0])
 
 	s.indices = make([]uint16, 6*maxQuads)
 	for i := uint16(0); i < maxQuads; i++ {
@@ -241,11 +520,75 @@ func (s *openGLState) reset() error {
 	// Note that the indices passed to NewElementArrayBuffer is not under GC management
 	// in opengl package due to unsafe-way.
 	// See NewElementArrayBuffer in context_mobile.go.
-	s.elementArrayBuffer = opengl.GetContext().NewElementArrayBuffer(s.indices)
+	s.elementArrayBuffer = currentDriver().NewElementArrayBuffer(s.indices)
 
+	s.initialized = true
 	return nil
 }
 
+// enableArrayBuffers enables the vertex attributes of the given program.
+//
+// When instancing is available, the per-vertex attributes are taken from the corner buffer
+// and the per-instance attributes are taken from the array buffer.
+func (s *openGLState) enableArrayBuffers(program driver.Program) {
+	if !s.instancing {
+		theArrayBufferLayout.enable(program)
+		return
+	}
+	currentDriver().BindArrayBuffer(s.cornerBuffer)
+	theCornerArrayBufferLayout.enable(program)
+	theCornerArrayBufferLayout.setDivisor(program, 0)
+
+	// Bind the array buffer at last so that the instance data is sent to the array buffer.
+	currentDriver().BindArrayBuffer(s.arrayBuffers[s.arrayBufferIndex])
+	theInstanceArrayBufferLayout.enable(program)
+	theInstanceArrayBufferLayout.setDivisor(program, 1)
+}
+
+// disableArrayBuffers disables the vertex attributes of the given program enabled by enableArrayBuffers.
+func (s *openGLState) disableArrayBuffers(program driver.Program) {
+	if !s.instancing {
+		theArrayBufferLayout.disable(program)
+		return
+	}
+	theInstanceArrayBufferLayout.setDivisor(program, 0)
+	theInstanceArrayBufferLayout.disable(program)
+	theCornerArrayBufferLayout.disable(program)
+}
+
+// bindNextArrayBuffer binds the next array buffer in the rotation.
+//
+// bindNextArrayBuffer must be called before uploading vertices: glBufferSubData might stall
+// until the GPU finishes the draw calls using the buffer, and the buffer used least recently
+// is the least likely to be in use.
+func (s *openGLState) bindNextArrayBuffer() {
+	s.arrayBufferIndex = (s.arrayBufferIndex + 1) % len(s.arrayBuffers)
+	currentDriver().BindArrayBuffer(s.arrayBuffers[s.arrayBufferIndex])
+
+	if s.lastProgram == zeroProgram {
+		// The pointers are specified when a program is used.
+		return
+	}
+	if s.instancing {
+		// The pointers of the instance data are specified at every drawQuads.
+		return
+	}
+	// The vertex attribute pointers refer to the buffer that was bound when they were specified.
+	theArrayBufferLayout.setPointers(s.lastProgram, 0)
+}
+
+// drawQuads draws n quadrangles starting from the quadrangle at the given index.
+func (s *openGLState) drawQuads(index int, n int) {
+	if !s.instancing {
+		currentDriver().DrawElements(driver.Triangles, 6*n, 6*index*2)
+		return
+	}
+	// Instanced drawing doesn't have the notion of the first instance without glDrawElementsInstancedBaseInstance.
+	// Specify the starting position of the instance data instead.
+	theInstanceArrayBufferLayout.setPointers(s.lastProgram, index*theInstanceArrayBufferLayout.totalBytes())
+	currentDriver().DrawElementsInstanced(driver.Triangles, 6, 0, n)
+}
+
 // areSameFloat32Array returns a boolean indicating if a and b are deeply equal.
 func areSameFloat32Array(a, b []float32) bool {
 	if len(a) != len(b) {
@@ -259,11 +602,32 @@ func areSameFloat32Array(a, b []float32) bool {
 	return true
 }
 
+func boolToInt(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
+
 // useProgram uses the program (programTexture).
-func (s *openGLState) useProgram(proj []float32, texture opengl.Texture, dst, src *Image, colorM *affine.ColorM, filter Filter) {
-	c := opengl.GetContext()
+//
+// depthTest indicates whether the depth test is enabled. With the depth test, transparent fragments are discarded
+// so that they don't hide the fragments behind them.
+//
+// If lut is not nil, the colors are converted with lut as a color look-up table. lut is sampled with nearest filter.
+//
+// If palette is not nil, src is an alpha-only image of palette indices and the colors are resolved with palette.
+// src is sampled with nearest filter.
+//
+// If normalMap is not nil, the colors are lit by lighting with normalMap, which is at the same position of its texture
+// as src. src is sampled with nearest filter.
+//
+// If distanceField is not nil, src is a distance field and is rendered with distanceField. src is sampled with
+// linear filter.
+func (s *openGLState) useProgram(proj []float32, texture driver.Texture, dst, src *Image, colorM *affine.ColorM, filter Filter, address driver.Address, depthTest bool, lut, palette, normalMap *Image, lighting *NormalMapLighting, distanceField *DistanceField) {
+	c := currentDriver()
 
-	var program opengl.Program
+	var program driver.Program
 	switch filter {
 	case FilterNearest:
 		program = s.programNearest
@@ -271,74 +635,210 @@ func (s *openGLState) useProgram(proj []float32, texture opengl.Texture, dst, sr
 		program = s.programLinear
 	case FilterScreen:
 		program = s.programScreen
+	case FilterAnisotropic2, FilterAnisotropic4, FilterAnisotropic8, FilterAnisotropic16:
+		program = s.programAnisotropic
+		if program == zeroProgram {
+			program = s.programLinear
+		}
 	default:
 		panic("not reached")
 	}
+	if lut != nil {
+		program = s.programColorLUT
+	}
+	if palette != nil {
+		program = s.programPalette
+	}
+	if normalMap != nil {
+		program = s.programNormalMap
+	}
+	if distanceField != nil {
+		program = s.programDistanceField
+	}
+
+	// skipped is the number of the state changes skipped as the values are not changed.
+	skipped := 0
 
 	if s.lastProgram != program {
 		c.UseProgram(program)
 		if s.lastProgram != zeroProgram {
-			theArrayBufferLayout.disable(s.lastProgram)
+			s.disableArrayBuffers(s.lastProgram)
 		}
-		theArrayBufferLayout.enable(program)
-
+		s.enableArrayBuffers(program)
 		s.lastProgram = program
-		s.lastProjectionMatrix = nil
-		s.lastColorMatrix = nil
-		s.lastColorMatrixTranslation = nil
-		s.lastSourceWidth = 0
-		s.lastSourceHeight = 0
 		c.BindElementArrayBuffer(s.elementArrayBuffer)
+	} else {
+		skipped++
+	}
+
+	st, ok := s.programStates[program]
+	if !ok {
+		// Initialize the uniform variables at the first use of the program.
+		st = &programState{}
 		c.UniformInt(program, "texture", 0)
+		c.UniformInt(program, "depth_test", 0)
+		if program != s.programScreen {
+			c.UniformInt(program, "address", int(driver.AddressClampToZero))
+		}
+		st.address = driver.AddressClampToZero
+		c.UniformInt(program, "source_srgb", 0)
+		c.UniformInt(program, "destination_srgb", 0)
+		if program == s.programColorLUT {
+			c.UniformInt(program, "lut", 1)
+		}
+		if program == s.programPalette {
+			c.UniformInt(program, "palette", 1)
+		}
+		if program == s.programNormalMap {
+			c.UniformInt(program, "normal_map", 1)
+		}
+		s.programStates[program] = st
 	}
 
-	if !areSameFloat32Array(s.lastProjectionMatrix, proj) {
+	if !areSameFloat32Array(st.projectionMatrix, proj) {
 		c.UniformFloats(program, "projection_matrix", proj)
-		if s.lastProjectionMatrix == nil {
-			s.lastProjectionMatrix = make([]float32, 16)
-		}
 		// (*framebuffer).projectionMatrix is always same for the same framebuffer.
 		// It's OK to hold the reference without copying.
-		s.lastProjectionMatrix = proj
+		st.projectionMatrix = proj
+	} else {
+		skipped++
 	}
 
 	esBody, esTranslate := colorM.UnsafeElements()
 
-	if !areSameFloat32Array(s.lastColorMatrix, esBody) {
+	if !areSameFloat32Array(st.colorMatrix, esBody) {
 		c.UniformFloats(program, "color_matrix", esBody)
-		if s.lastColorMatrix == nil {
-			s.lastColorMatrix = make([]float32, 16)
-		}
 		// ColorM's elements are immutable. It's OK to hold the reference without copying.
-		s.lastColorMatrix = esBody
+		st.colorMatrix = esBody
+	} else {
+		skipped++
 	}
-	if !areSameFloat32Array(s.lastColorMatrixTranslation, esTranslate) {
+	if !areSameFloat32Array(st.colorMatrixTranslation, esTranslate) {
 		c.UniformFloats(program, "color_matrix_translation", esTranslate)
-		if s.lastColorMatrixTranslation == nil {
-			s.lastColorMatrixTranslation = make([]float32, 4)
-		}
 		// ColorM's elements are immutable. It's OK to hold the reference without copying.
-		s.lastColorMatrixTranslation = esTranslate
+		st.colorMatrixTranslation = esTranslate
+	} else {
+		skipped++
 	}
 
-	sw, sh := src.Size()
-	sw = emath.NextPowerOf2Int(sw)
-	sh = emath.NextPowerOf2Int(sh)
+	// The texture might be larger than the image when non-power-of-two textures are not available.
+	sw, sh := src.texture.width, src.texture.height
 
-	if s.lastSourceWidth != sw || s.lastSourceHeight != sh {
+	if st.sourceWidth != sw || st.sourceHeight != sh {
 		c.UniformFloats(program, "source_size", []float32{float32(sw), float32(sh)})
-		s.lastSourceWidth = sw
-		s.lastSourceHeight = sh
+		st.sourceWidth = sw
+		st.sourceHeight = sh
+	} else {
+		skipped++
+	}
+
+	// The screen program doesn't use the address mode.
+	if program == s.programScreen {
+		address = driver.AddressClampToZero
+	}
+	if st.address != address {
+		c.UniformInt(program, "address", int(address))
+		st.address = address
+	} else {
+		skipped++
+	}
+	// The source image size is used only to wrap the whole source image.
+	if address.Wraps() {
+		if st.sourceImageWidth != src.width || st.sourceImageHeight != src.height {
+			c.UniformFloats(program, "source_image_size", []float32{float32(src.width), float32(src.height)})
+			st.sourceImageWidth = src.width
+			st.sourceImageHeight = src.height
+		} else {
+			skipped++
+		}
+	}
+
+	if st.depthTest != depthTest {
+		c.UniformInt(program, "depth_test", boolToInt(depthTest))
+		st.depthTest = depthTest
+	} else {
+		skipped++
+	}
+
+	// The screen framebuffer is never an sRGB framebuffer.
+	srcSRGB := src.texture.format == driver.PixelFormatSRGBA8
+	dstSRGB := dst.texture != nil && dst.texture.format == driver.PixelFormatSRGBA8
+	if st.sourceSRGB != srcSRGB {
+		c.UniformInt(program, "source_srgb", boolToInt(srcSRGB))
+		st.sourceSRGB = srcSRGB
+	} else {
+		skipped++
+	}
+	if st.destinationSRGB != dstSRGB {
+		c.UniformInt(program, "destination_srgb", boolToInt(dstSRGB))
+		st.destinationSRGB = dstSRGB
+	} else {
+		skipped++
 	}
 
 	if program == s.programScreen {
 		sw, _ := src.Size()
 		dw, _ := dst.Size()
 		scale := float32(dw) / float32(sw)
-		c.UniformFloat(program, "scale", scale)
+		if st.scale != scale {
+			c.UniformFloat(program, "scale", scale)
+			st.scale = scale
+		} else {
+			skipped++
+		}
+	}
+
+	if program == s.programAnisotropic {
+		if a := filter.anisotropy(); st.anisotropy != a {
+			c.UniformFloat(program, "max_anisotropy", float32(a))
+			st.anisotropy = a
+		} else {
+			skipped++
+		}
+	}
+
+	if lut != nil {
+		// The LUT is used rarely. Let's not cache the uniform values.
+		c.UniformFloat(program, "lut_size", float32(lut.height))
+		c.UniformFloats(program, "lut_texture_size", []float32{float32(lut.texture.width), float32(lut.texture.height)})
+		c.BindTextureAt(1, lut.texture.native)
+	}
+	if palette != nil {
+		if st.paletteTextureWidth != palette.texture.width || st.paletteTextureHeight != palette.texture.height {
+			c.UniformFloats(program, "palette_texture_size", []float32{float32(palette.texture.width), float32(palette.texture.height)})
+			st.paletteTextureWidth = palette.texture.width
+			st.paletteTextureHeight = palette.texture.height
+		} else {
+			skipped++
+		}
+		c.BindTextureAt(1, palette.texture.native)
 	}
+	if normalMap != nil {
+		if st.lighting != *lighting {
+			c.UniformFloats(program, "ambient_color", lighting.Ambient[:])
+			c.UniformFloats(program, "light_positions", lighting.Positions[:])
+			c.UniformFloats(program, "light_colors", lighting.Colors[:])
+			st.lighting = *lighting
+		} else {
+			skipped++
+		}
+		c.BindTextureAt(1, normalMap.texture.native)
+	}
+	if distanceField != nil {
+		if st.distanceField != *distanceField {
+			c.UniformFloats(program, "distance_field", []float32{distanceField.Spread, distanceField.Scale, distanceField.OutlineWidth, distanceField.GlowWidth})
+			c.UniformFloats(program, "outline_color", distanceField.OutlineColor[:])
+			c.UniformFloats(program, "glow_color", distanceField.GlowColor[:])
+			st.distanceField = *distanceField
+		} else {
+			skipped++
+		}
+	}
+
+	atomic.AddInt64(&skippedStateChangeCount, int64(skipped))
 
 	// We don't have to call gl.ActiveTexture here: GL_TEXTURE0 is the default active texture
 	// See also: https://www.opengl.org/sdk/docs/man2/xhtml/glActiveTexture.xml
+	// The driver skips binding the texture if the texture is already bound.
 	c.BindTexture(texture)
 }
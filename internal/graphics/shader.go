@@ -22,15 +22,24 @@ type shaderID int
 
 const (
 	shaderVertexModelview shaderID = iota
+	shaderVertexModelviewInstanced
 	shaderFragmentNearest
 	shaderFragmentLinear
 	shaderFragmentScreen
+	shaderFragmentColorLUT
+	shaderFragmentPalette
+	shaderFragmentNormalMap
+	shaderFragmentDistanceField
+	shaderFragmentAnisotropic
 )
 
 func shader(id shaderID) string {
 	if id == shaderVertexModelview {
 		return shaderStrVertex
 	}
+	if id == shaderVertexModelviewInstanced {
+		return shaderStrVertexInstanced
+	}
 	defs := []string{}
 	switch id {
 	case shaderFragmentNearest:
@@ -39,6 +48,20 @@ func shader(id shaderID) string {
 		defs = append(defs, "#define FILTER_LINEAR")
 	case shaderFragmentScreen:
 		defs = append(defs, "#define FILTER_SCREEN")
+	case shaderFragmentColorLUT:
+		defs = append(defs, "#define FILTER_NEAREST")
+		defs = append(defs, "#define COLOR_LUT")
+	case shaderFragmentPalette:
+		defs = append(defs, "#define FILTER_NEAREST")
+		defs = append(defs, "#define COLOR_PALETTE")
+	case shaderFragmentNormalMap:
+		defs = append(defs, "#define FILTER_NEAREST")
+		defs = append(defs, "#define NORMAL_MAP")
+	case shaderFragmentDistanceField:
+		defs = append(defs, "#define FILTER_LINEAR")
+		defs = append(defs, "#define DISTANCE_FIELD")
+	case shaderFragmentAnisotropic:
+		defs = append(defs, "#define FILTER_ANISOTROPIC")
 	default:
 		panic("not reached")
 	}
@@ -48,20 +71,80 @@ func shader(id shaderID) string {
 const (
 	shaderStrVertex = `
 uniform mat4 projection_matrix;
-attribute vec2 vertex;
+uniform vec2 source_size;
+attribute vec3 vertex;
+// tex_coord is the texture coordinate of the vertex (xy) and the one of the diagonally opposite vertex (zw) in texels.
 attribute vec4 tex_coord;
+attribute vec4 color_scale;
+attribute vec4 color_translate;
+// varying_tex_coord is the normalized texture coordinate to sample.
 varying vec2 varying_tex_coord;
+// varying_tex_coord_min and varying_tex_coord_max are the upper-left and the lower-right corners of the source region
+// in the normalized texture coordinates. As they are calculated from the diagonally opposite coordinates, they are
+// the same for all the vertices of a quadrangle.
+// The source region origin is varying_tex_coord_min, and the source region size is
+// varying_tex_coord_max - varying_tex_coord_min. Sampling positions are clamped or wrapped in the region so that
+// the texels of the other images in the same texture (atlas) are never sampled.
 varying vec2 varying_tex_coord_min;
 varying vec2 varying_tex_coord_max;
+varying vec4 varying_color_scale;
+varying vec4 varying_color_translate;
+// varying_dst_pos is the position in the destination in pixels.
+varying vec2 varying_dst_pos;
 
 void main(void) {
-  varying_tex_coord = vec2(tex_coord[0], tex_coord[1]);
-  varying_tex_coord_min = vec2(min(tex_coord[0], tex_coord[2]), min(tex_coord[1], tex_coord[3]));
-  varying_tex_coord_max = vec2(max(tex_coord[0], tex_coord[2]), max(tex_coord[1], tex_coord[3]));
-  gl_Position = projection_matrix * vec4(vertex, 0, 1);
+  // tex_coord is in texels. Normalize it by the texture size.
+  vec4 uv = tex_coord / vec4(source_size, source_size);
+  varying_tex_coord = vec2(uv[0], uv[1]);
+  varying_tex_coord_min = vec2(min(uv[0], uv[2]), min(uv[1], uv[3]));
+  varying_tex_coord_max = vec2(max(uv[0], uv[2]), max(uv[1], uv[3]));
+  varying_color_scale = color_scale;
+  varying_color_translate = color_translate;
+  varying_dst_pos = vertex.xy;
+  // The depth value 1 is the nearest. Convert it to the normalized device coordinate -1.
+  gl_Position = projection_matrix * vec4(vertex.xy, 1.0 - 2.0 * vertex.z, 1);
+}
+`
+	// shaderStrVertexInstanced is the vertex shader for instanced drawing.
+	// Each instance is a quadrangle and corner represents a corner of the unit square.
+	// The vertex and the texture coordinates are calculated in the same way as shaderStrVertex.
+	shaderStrVertexInstanced = `
+uniform mat4 projection_matrix;
+uniform vec2 source_size;
+attribute vec2 corner;
+attribute vec3 origin;
+attribute vec4 edges;
+attribute vec4 tex_region;
+attribute vec4 color_scale;
+attribute vec4 color_translate;
+varying vec2 varying_tex_coord;
+varying vec2 varying_tex_coord_min;
+varying vec2 varying_tex_coord_max;
+varying vec4 varying_color_scale;
+varying vec4 varying_color_translate;
+varying vec2 varying_dst_pos;
+
+void main(void) {
+  vec2 vertex = origin.xy + corner.x * edges.xy + corner.y * edges.zw;
+  vec2 tex_coord = mix(tex_region.xy, tex_region.zw, corner) / source_size;
+  vec2 tex_coord_opposite = mix(tex_region.zw, tex_region.xy, corner) / source_size;
+  varying_tex_coord = tex_coord;
+  varying_tex_coord_min = min(tex_coord, tex_coord_opposite);
+  varying_tex_coord_max = max(tex_coord, tex_coord_opposite);
+  varying_color_scale = color_scale;
+  varying_color_translate = color_translate;
+  varying_dst_pos = vertex;
+  gl_Position = projection_matrix * vec4(vertex, 1.0 - 2.0 * origin.z, 1);
 }
 `
 	shaderStrFragment = `
+{{Definitions}}
+
+#if defined(GL_ES) && defined(FILTER_ANISOTROPIC)
+// dFdx and dFdy require the extension on OpenGL ES 2.0 and WebGL 1.
+#extension GL_OES_standard_derivatives : enable
+#endif
+
 #if defined(GL_ES)
 precision mediump float;
 #else
@@ -70,21 +153,160 @@ precision mediump float;
 #define highp
 #endif
 
-{{Definitions}}
+// The values must be same as driver.Address.
+#define ADDRESS_CLAMP_TO_ZERO 0
+#define ADDRESS_CLAMP_TO_EDGE 1
+#define ADDRESS_REPEAT 2
+#define ADDRESS_MIRROR_REPEAT 3
 
 uniform sampler2D texture;
 uniform mat4 color_matrix;
 uniform vec4 color_matrix_translation;
+uniform bool depth_test;
+// source_srgb and destination_srgb indicate whether the source and the destination textures are sRGB textures.
+uniform bool source_srgb;
+uniform bool destination_srgb;
 
+// source_size is the size of the source texture in texels. The texel size in the normalized texture coordinates
+// is 1.0 / source_size.
 uniform highp vec2 source_size;
 
+uniform int address;
+// source_image_size is the size of the source image in texels, which might be smaller than the texture.
+uniform highp vec2 source_image_size;
+
 #if defined(FILTER_SCREEN)
 uniform highp float scale;
 #endif
 
+#if defined(FILTER_ANISOTROPIC)
+// max_anisotropy is the maximum number of the samples along the longer axis of the footprint.
+uniform highp float max_anisotropy;
+#endif
+
+#if defined(COLOR_LUT)
+uniform sampler2D lut;
+// lut_size is the number of the entries for each color component.
+uniform highp float lut_size;
+// lut_texture_size is the size of the LUT texture, which might be larger than the LUT image.
+uniform highp vec2 lut_texture_size;
+
+// lookUpLUTTile returns the bilinearly interpolated color at (r, g) in the tile for the blue entry b.
+vec3 lookUpLUTTile(highp float b, highp vec2 rg) {
+  highp vec2 p0 = floor(rg);
+  highp vec2 p1 = min(p0 + 1.0, lut_size - 1.0);
+  highp vec2 rate = rg - p0;
+  // Sample at the centers of the texels.
+  highp vec2 offset = vec2(b * lut_size, 0) + 0.5;
+  vec3 c0 = texture2D(lut, (offset + p0) / lut_texture_size).rgb;
+  vec3 c1 = texture2D(lut, (offset + vec2(p1.x, p0.y)) / lut_texture_size).rgb;
+  vec3 c2 = texture2D(lut, (offset + vec2(p0.x, p1.y)) / lut_texture_size).rgb;
+  vec3 c3 = texture2D(lut, (offset + p1) / lut_texture_size).rgb;
+  return mix(mix(c0, c1, rate.x), mix(c2, c3, rate.x), rate.y);
+}
+
+// lookUpLUT returns the color converted by the LUT.
+//
+// The LUT image consists of lut_size tiles arranged horizontally. The n-th tile is for the n-th blue entry,
+// and the X and Y axes in a tile are for the red and green entries.
+vec3 lookUpLUT(vec3 c) {
+  highp vec3 p = c * (lut_size - 1.0);
+  highp float b0 = floor(p.b);
+  highp float b1 = min(b0 + 1.0, lut_size - 1.0);
+  return mix(lookUpLUTTile(b0, p.rg), lookUpLUTTile(b1, p.rg), p.b - b0);
+}
+#endif
+
+#if defined(COLOR_PALETTE)
+uniform sampler2D palette;
+// palette_texture_size is the size of the palette texture, which might be larger than the palette image.
+uniform highp vec2 palette_texture_size;
+
+// lookUpPalette returns the color of the palette entry at the index, which is the alpha value of an alpha-only texel.
+vec4 lookUpPalette(highp float index) {
+  highp float i = floor(index * 255.0 + 0.5);
+  // Sample at the center of the texel.
+  return texture2D(palette, (vec2(i, 0) + 0.5) / palette_texture_size);
+}
+#endif
+
+#if defined(NORMAL_MAP)
+uniform sampler2D normal_map;
+uniform vec4 ambient_color;
+// Each column of light_positions is a light: (x, y, z, 1) is the position of a point light in the destination,
+// and (x, y, z, 0) is the direction toward a directional light.
+uniform highp mat4 light_positions;
+// Each column of light_colors is the color (r, g, b) and the radius of a light. The radius 0 means no attenuation.
+uniform highp mat4 light_colors;
+
+varying highp vec2 varying_dst_pos;
+
+// lightNormal returns the sum of the lights on the surface with the normal n at the destination position dst.
+//
+// The normal's Y axis points up as in the normal maps exported from usual tools, while the destination's Y axis
+// points down.
+vec3 lightNormal(highp vec3 n, highp vec2 dst) {
+  n = normalize(vec3(n.x, -n.y, n.z));
+  vec3 c = ambient_color.rgb;
+  for (int i = 0; i < 4; i++) {
+    highp vec4 p = light_positions[i];
+    highp vec3 l = p.xyz - vec3(dst, 0) * p.w;
+    highp float d = length(l);
+    if (d == 0.0) {
+      continue;
+    }
+    highp float attenuation = 1.0;
+    if (0.0 < p.w && 0.0 < light_colors[i].w) {
+      attenuation = clamp(1.0 - d / light_colors[i].w, 0.0, 1.0);
+    }
+    c += light_colors[i].rgb * max(dot(n, l / d), 0.0) * attenuation;
+  }
+  return c;
+}
+#endif
+
+#if defined(DISTANCE_FIELD)
+// distance_field is (spread, scale, outline width, glow width).
+// The alpha value a of the source represents the signed distance (a - 0.5) * spread from the edge in source pixels,
+// positive inside. scale is the scale from source pixels to destination pixels.
+uniform highp vec4 distance_field;
+uniform vec4 outline_color;
+uniform vec4 glow_color;
+
+// over composites the non-premultiplied color top over bottom.
+vec4 over(vec4 top, vec4 bottom) {
+  float a = top.a + bottom.a * (1.0 - top.a);
+  if (a == 0.0) {
+    return vec4(0, 0, 0, 0);
+  }
+  return vec4((top.rgb * top.a + bottom.rgb * bottom.a * (1.0 - top.a)) / a, a);
+}
+
+// distanceFieldColor returns the non-premultiplied color of the shape filled with fill, the outline and the glow
+// at the distance value a.
+vec4 distanceFieldColor(vec4 fill, highp float a) {
+  highp float d = (a - 0.5) * distance_field.x;
+  highp float scale = distance_field.y;
+  highp float outline = distance_field.z;
+  highp float glow = distance_field.w;
+  // Antialias the edges by the coverage of a destination pixel.
+  vec4 color = vec4(fill.rgb, fill.a * clamp(d * scale + 0.5, 0.0, 1.0));
+  if (0.0 < outline) {
+    color = over(color, vec4(outline_color.rgb, outline_color.a * clamp((d + outline) * scale + 0.5, 0.0, 1.0)));
+  }
+  if (0.0 < glow) {
+    color = over(color, vec4(glow_color.rgb, glow_color.a * clamp(1.0 + (d + outline) / glow, 0.0, 1.0)));
+  }
+  return color;
+}
+#endif
+
 varying highp vec2 varying_tex_coord;
 varying highp vec2 varying_tex_coord_min;
 varying highp vec2 varying_tex_coord_max;
+// varying_color_scale and varying_color_translate are the tint applied before the color matrix.
+varying vec4 varying_color_scale;
+varying vec4 varying_color_translate;
 
 highp vec2 roundTexel(highp vec2 p) {
   // highp (relative) precision is 2^(-16) in the spec.
@@ -95,6 +317,67 @@ highp vec2 roundTexel(highp vec2 p) {
   return p;
 }
 
+highp vec3 decodeSRGB(highp vec3 c) {
+  return mix(c / 12.92, pow((c + 0.055) / 1.055, vec3(2.4)), step(0.04045, c));
+}
+
+highp vec3 encodeSRGB(highp vec3 c) {
+  return mix(c * 12.92, 1.055 * pow(c, vec3(1.0 / 2.4)) - 0.055, step(0.0031308, c));
+}
+
+// adjustTexelByAddress adjusts the texel position p out of the region (tmin, tmax) by the address mode.
+highp vec2 adjustTexelByAddress(highp vec2 p, highp vec2 tmin, highp vec2 tmax, highp vec2 texel_size) {
+  if (address == ADDRESS_CLAMP_TO_EDGE) {
+    return clamp(p, tmin, tmax - texel_size / 256.0);
+  }
+  if (address == ADDRESS_REPEAT) {
+    return tmin + mod(p - tmin, tmax - tmin);
+  }
+  if (address == ADDRESS_MIRROR_REPEAT) {
+    highp vec2 size = tmax - tmin;
+    highp vec2 q = mod(p - tmin, 2.0 * size);
+    // Mirror every other repetition.
+    q = mix(q, 2.0 * size - q - texel_size / 256.0, step(size, q));
+    return tmin + q;
+  }
+  return p;
+}
+
+#if defined(FILTER_LINEAR) || defined(FILTER_ANISOTROPIC)
+// sampleLinear returns the bilinearly interpolated color at the texel position pos in the region (tmin, tmax).
+vec4 sampleLinear(highp vec2 pos, highp vec2 tmin, highp vec2 tmax, highp vec2 texel_size) {
+  highp vec2 p0 = pos - texel_size / 2.0;
+  highp vec2 p1 = pos + texel_size / 2.0;
+  highp vec2 q0 = adjustTexelByAddress(p0, tmin, tmax, texel_size);
+  highp vec2 q1 = adjustTexelByAddress(p1, tmin, tmax, texel_size);
+  vec4 c0 = texture2D(texture, q0);
+  vec4 c1 = texture2D(texture, vec2(q1.x, q0.y));
+  vec4 c2 = texture2D(texture, vec2(q0.x, q1.y));
+  vec4 c3 = texture2D(texture, q1);
+  if (address == ADDRESS_CLAMP_TO_ZERO) {
+    if (p0.x < tmin.x) {
+      c0 = vec4(0, 0, 0, 0);
+      c2 = vec4(0, 0, 0, 0);
+    }
+    if (p0.y < tmin.y) {
+      c0 = vec4(0, 0, 0, 0);
+      c1 = vec4(0, 0, 0, 0);
+    }
+    if ((tmax.x - texel_size.x / 256.0) <= p1.x) {
+      c1 = vec4(0, 0, 0, 0);
+      c3 = vec4(0, 0, 0, 0);
+    }
+    if ((tmax.y - texel_size.y / 256.0) <= p1.y) {
+      c2 = vec4(0, 0, 0, 0);
+      c3 = vec4(0, 0, 0, 0);
+    }
+  }
+
+  vec2 rate = fract(p0 * source_size);
+  return mix(mix(c0, c1, rate.x), mix(c2, c3, rate.x), rate.y);
+}
+#endif
+
 void main(void) {
   highp vec2 pos = varying_tex_coord;
 
@@ -104,42 +387,58 @@ void main(void) {
 
   highp vec2 texel_size = 1.0 / source_size;
 
+  highp vec2 tex_min = varying_tex_coord_min;
+  highp vec2 tex_max = varying_tex_coord_max;
+  if (address == ADDRESS_REPEAT || address == ADDRESS_MIRROR_REPEAT) {
+    // The whole source image is wrapped. Such an image is always at the origin of the texture.
+    tex_min = vec2(0, 0);
+    tex_max = source_image_size / source_size;
+  }
+
 #if defined(FILTER_NEAREST)
-  vec4 color = texture2D(texture, pos);
-  if (pos.x < varying_tex_coord_min.x ||
-    pos.y < varying_tex_coord_min.y ||
-    (varying_tex_coord_max.x - texel_size.x / 256.0) <= pos.x ||
-    (varying_tex_coord_max.y - texel_size.y / 256.0) <= pos.y) {
+  vec4 color = texture2D(texture, adjustTexelByAddress(pos, tex_min, tex_max, texel_size));
+#if defined(COLOR_PALETTE)
+  color = lookUpPalette(color.a);
+#endif
+  if (address == ADDRESS_CLAMP_TO_ZERO &&
+    (pos.x < tex_min.x ||
+    pos.y < tex_min.y ||
+    (tex_max.x - texel_size.x / 256.0) <= pos.x ||
+    (tex_max.y - texel_size.y / 256.0) <= pos.y)) {
     color = vec4(0, 0, 0, 0);
   }
 #endif
 
 #if defined(FILTER_LINEAR)
-  highp vec2 p0 = pos - texel_size / 2.0;
-  highp vec2 p1 = pos + texel_size / 2.0;
-  vec4 c0 = texture2D(texture, p0);
-  vec4 c1 = texture2D(texture, vec2(p1.x, p0.y));
-  vec4 c2 = texture2D(texture, vec2(p0.x, p1.y));
-  vec4 c3 = texture2D(texture, p1);
-  if (p0.x < varying_tex_coord_min.x) {
-    c0 = vec4(0, 0, 0, 0);
-    c2 = vec4(0, 0, 0, 0);
-  }
-  if (p0.y < varying_tex_coord_min.y) {
-    c0 = vec4(0, 0, 0, 0);
-    c1 = vec4(0, 0, 0, 0);
-  }
-  if ((varying_tex_coord_max.x - texel_size.x / 256.0) <= p1.x) {
-    c1 = vec4(0, 0, 0, 0);
-    c3 = vec4(0, 0, 0, 0);
+  vec4 color = sampleLinear(pos, tex_min, tex_max, texel_size);
+#if defined(DISTANCE_FIELD)
+  // The alpha value is the distance. The shape is filled with white, which is then tinted by the color matrix.
+  highp float distance = color.a;
+  color = vec4(1, 1, 1, 1);
+#endif
+#endif
+
+#if defined(FILTER_ANISOTROPIC)
+  // axis is the longer axis of the footprint of the destination pixel in the normalized texture coordinates.
+  // As the source texture doesn't have mipmaps, the footprint is covered by linear samples at even intervals
+  // along the axis instead of sampling a smaller mipmap.
+  highp vec2 dx = dFdx(varying_tex_coord);
+  highp vec2 dy = dFdy(varying_tex_coord);
+  highp vec2 axis = dx;
+  if (length(dx * source_size) < length(dy * source_size)) {
+    axis = dy;
   }
-  if ((varying_tex_coord_max.y - texel_size.y / 256.0) <= p1.y) {
-    c2 = vec4(0, 0, 0, 0);
-    c3 = vec4(0, 0, 0, 0);
+  highp float n = clamp(ceil(length(axis * source_size)), 1.0, max_anisotropy);
+  vec4 color = vec4(0, 0, 0, 0);
+  // The loop bound must be a constant in GLSL ES 1.0.
+  for (int i = 0; i < 16; i++) {
+    if (n <= float(i)) {
+      break;
+    }
+    highp vec2 p = pos + ((float(i) + 0.5) / n - 0.5) * axis;
+    color += sampleLinear(p, tex_min, tex_max, texel_size);
   }
-
-  vec2 rate = fract(p0 * source_size);
-  vec4 color = mix(mix(c0, c1, rate.x), mix(c2, c3, rate.x), rate.y);
+  color /= n;
 #endif
 
 #if defined(FILTER_SCREEN)
@@ -161,12 +460,37 @@ void main(void) {
   if (0.0 < color.a) {
     color.rgb /= color.a;
   }
-  // Apply the color matrix
+  // The texels of an sRGB texture are decoded into the linear color space when sampled.
+  // Encode them so that the color matrix is always applied in the sRGB color space.
+  if (source_srgb) {
+    color.rgb = encodeSRGB(color.rgb);
+  }
+  // Apply the tint and the color matrix
+  color = color * varying_color_scale + varying_color_translate;
   color = (color_matrix * color) + color_matrix_translation;
   color = clamp(color, 0.0, 1.0);
+#if defined(DISTANCE_FIELD)
+  color = distanceFieldColor(color, distance);
+#endif
+#if defined(NORMAL_MAP)
+  // The normal map is at the same position of its texture as the source image.
+  color.rgb = clamp(color.rgb * lightNormal(texture2D(normal_map, pos).rgb * 2.0 - 1.0, varying_dst_pos), 0.0, 1.0);
+#endif
+#if defined(COLOR_LUT)
+  color.rgb = lookUpLUT(color.rgb);
+#endif
+  // The colors are encoded when they are written to an sRGB texture, and blending is done in the linear color space.
+  if (destination_srgb) {
+    color.rgb = decodeSRGB(color.rgb);
+  }
   // Premultiply alpha
   color.rgb *= color.a;
 
+  if (depth_test && color.a == 0.0) {
+    // Transparent fragments must not update the depth buffer.
+    discard;
+  }
+
   gl_FragColor = color;
 }
 `
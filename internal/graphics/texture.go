@@ -15,7 +15,10 @@
 package graphics
 
 import (
-	"github.com/hajimehoshi/ebiten/internal/opengl"
+	"encoding/binary"
+
+	"github.com/hajimehoshi/ebiten/internal/driver"
+	"github.com/hajimehoshi/ebiten/internal/math"
 )
 
 type Filter int
@@ -25,9 +28,169 @@ const (
 	FilterNearest
 	FilterLinear
 	FilterScreen
+
+	// FilterAnisotropic2 to FilterAnisotropic16 are linear filters that take up to the given number of samples
+	// along the longer axis of the footprint of a destination pixel.
+	FilterAnisotropic2
+	FilterAnisotropic4
+	FilterAnisotropic8
+	FilterAnisotropic16
 )
 
+// anisotropy returns the maximum number of the samples of the anisotropic filter, or 0 if f is not anisotropic.
+func (f Filter) anisotropy() int {
+	switch f {
+	case FilterAnisotropic2:
+		return 2
+	case FilterAnisotropic4:
+		return 4
+	case FilterAnisotropic8:
+		return 8
+	case FilterAnisotropic16:
+		return 16
+	}
+	return 0
+}
+
 // texture represents OpenGL's texture.
 type texture struct {
-	native opengl.Texture
+	native driver.Texture
+
+	// width and height are the actual size of the texture.
+	// These might be larger than the image size.
+	width  int
+	height int
+
+	// format is the actual pixel format of the texture.
+	// This might be different from the image's format when the format is not available.
+	format driver.PixelFormat
+
+	// uploading indicates whether the texels might be being uploaded on the upload worker.
+	uploading bool
+}
+
+// waitUpload waits for the uploads on the upload worker to finish if needed.
+// waitUpload must be called before the texture is used on the render thread.
+func (t *texture) waitUpload() {
+	if t == nil || !t.uploading {
+		return
+	}
+	currentDriver().WaitUpload(t.native)
+	t.uploading = false
+}
+
+// textureSize returns the size of a texture for an image with the given size.
+//
+// If npot is false, non-power-of-two textures are not available and the size is expanded to powers of 2.
+func textureSize(width, height int, npot bool) (int, int) {
+	if npot {
+		return width, height
+	}
+	return math.NextPowerOf2Int(width), math.NextPowerOf2Int(height)
+}
+
+func (t *texture) sizeInBytes() int64 {
+	return int64(t.format.DataSize(t.width, t.height))
+}
+
+// convertPixels converts the pixels p in the format from into the format to.
+//
+// An alpha-only pixel is converted to black with the alpha, which is the same color
+// as sampling an alpha-only texture.
+//
+// The pixels of PixelFormatSRGBA8 are same as PixelFormatRGBA8's.
+// See srgbPixelsToTexels for the texels of an sRGB texture.
+func convertPixels(p []byte, from, to driver.PixelFormat) []byte {
+	if from == to {
+		return p
+	}
+	n := len(p) / from.BytesPerPixel()
+
+	// Convert the pixels via 8-bit RGBA.
+	rgba := p
+	switch from {
+	case driver.PixelFormatAlpha8:
+		rgba = make([]byte, 4*n)
+		for i := 0; i < n; i++ {
+			rgba[4*i+3] = p[i]
+		}
+	case driver.PixelFormatRGBA16F:
+		rgba = make([]byte, 4*n)
+		for i := range rgba {
+			f := math.Float16frombits(binary.LittleEndian.Uint16(p[2*i:]))
+			if f < 0 {
+				f = 0
+			}
+			if f > 1 {
+				f = 1
+			}
+			rgba[i] = byte(f*0xff + 0.5)
+		}
+	}
+
+	switch to {
+	case driver.PixelFormatRGBA8, driver.PixelFormatSRGBA8:
+		return rgba
+	case driver.PixelFormatAlpha8:
+		r := make([]byte, n)
+		for i := range r {
+			r[i] = rgba[4*i+3]
+		}
+		return r
+	case driver.PixelFormatRGBA16F:
+		r := make([]byte, 8*n)
+		for i, v := range rgba {
+			binary.LittleEndian.PutUint16(r[2*i:], math.Float16bits(float32(v)/0xff))
+		}
+		return r
+	default:
+		panic("not reached")
+	}
+}
+
+// srgbPixelsToTexels converts the alpha-premultiplied sRGB pixels p into the texels of an sRGB texture.
+//
+// As blending on an sRGB texture is done in the linear color space, the texels are the sRGB-encoded values
+// of the colors premultiplied in the linear color space.
+// p is modified and returned.
+func srgbPixelsToTexels(p []byte) []byte {
+	for i := 0; i < len(p)/4; i++ {
+		a := p[4*i+3]
+		// An opaque or transparent pixel doesn't have to be converted.
+		if a == 0 || a == 0xff {
+			continue
+		}
+		af := float64(a) / 0xff
+		for j := 0; j < 3; j++ {
+			v := float64(p[4*i+j]) / float64(a)
+			if v > 1 {
+				v = 1
+			}
+			v = math.LinearToSRGB(math.SRGBToLinear(v) * af)
+			p[4*i+j] = byte(v*0xff + 0.5)
+		}
+	}
+	return p
+}
+
+// srgbTexelsToPixels converts the texels p of an sRGB texture into alpha-premultiplied sRGB pixels.
+// This is the inverse of srgbPixelsToTexels.
+// p is modified and returned.
+func srgbTexelsToPixels(p []byte) []byte {
+	for i := 0; i < len(p)/4; i++ {
+		a := p[4*i+3]
+		if a == 0 || a == 0xff {
+			continue
+		}
+		af := float64(a) / 0xff
+		for j := 0; j < 3; j++ {
+			v := math.SRGBToLinear(float64(p[4*i+j])/0xff) / af
+			if v > 1 {
+				v = 1
+			}
+			v = math.LinearToSRGB(v) * af
+			p[4*i+j] = byte(v*0xff + 0.5)
+		}
+	}
+	return p
 }
@@ -0,0 +1,140 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphics
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/affine"
+)
+
+var (
+	quadFloat32Num     = QuadVertexSizeInBytes() / 4
+	vertexFloat32Num   = quadFloat32Num / 4
+	theVerticesBackend = &verticesBackend{}
+)
+
+type verticesBackend struct {
+	backend []float32
+	head    int
+}
+
+func (v *verticesBackend) get() []float32 {
+	const num = 256
+	if v.backend == nil {
+		v.backend = make([]float32, quadFloat32Num*num)
+	}
+	s := v.backend[v.head : v.head+quadFloat32Num]
+	v.head += quadFloat32Num
+	if v.head+quadFloat32Num > len(v.backend) {
+		v.backend = nil
+		v.head = 0
+	}
+	return s
+}
+
+// QuadVertices returns the vertices of the quadrangle that the source region (sx0, sy0)-(sx1, sy1)
+// of an image is transformed into by geo.
+//
+// The texture coordinates are in texels. They are normalized by the texture size in the vertex shader,
+// as the actual texture size is not determined until the texture is created.
+//
+// tint is the color matrix applied per vertex before the color matrix of the drawing.
+// tint must consist of only scaling and translation (see (*affine.ColorM).Tint). nil means identity.
+// As tints are a part of the vertices, drawings with different tints can be merged into one draw call.
+//
+// z is the depth value of the quadrangle, which is used only when the destination has a depth buffer.
+// z must be in [0, 1], and a quadrangle with a greater z is nearer.
+//
+// The returned slice is reused in later calls.
+// QuadVertices returns nil when the source region is empty.
+func QuadVertices(sx0, sy0, sx1, sy1 int, geo *affine.GeoM, tint *affine.ColorM, z float32) []float32 {
+	if sx0 >= sx1 || sy0 >= sy1 {
+		return nil
+	}
+	scale, translate, ok := tint.Tint()
+	if !ok {
+		panic("graphics: tint must consist of only scaling and translation")
+	}
+
+	vs := theVerticesBackend.get()
+	putQuadVertices(vs, sx0, sy0, sx1, sy1, geo, scale, translate, z)
+	return vs
+}
+
+// Quad represents a quadrangle that the source region (SX0, SY0)-(SX1, SY1) of an image is transformed into by GeoM.
+//
+// See QuadVertices for Tint and Z.
+type Quad struct {
+	SX0, SY0, SX1, SY1 int
+	GeoM               *affine.GeoM
+	Tint               *affine.ColorM
+	Z                  float32
+}
+
+// AppendQuadVertices appends the vertices of the quadrangles quads to vs and returns the extended slice.
+// The quadrangles of which source regions are empty are skipped.
+//
+// Unlike QuadVertices, the returned slice is not reused.
+func AppendQuadVertices(vs []float32, quads []Quad) []float32 {
+	for _, q := range quads {
+		if q.SX0 >= q.SX1 || q.SY0 >= q.SY1 {
+			continue
+		}
+		scale, translate, ok := q.Tint.Tint()
+		if !ok {
+			panic("graphics: tint must consist of only scaling and translation")
+		}
+		n := len(vs)
+		if cap(vs) < n+quadFloat32Num {
+			vs = append(vs, make([]float32, quadFloat32Num)...)
+		} else {
+			vs = vs[:n+quadFloat32Num]
+		}
+		putQuadVertices(vs[n:], q.SX0, q.SY0, q.SX1, q.SY1, q.GeoM, scale, translate, q.Z)
+	}
+	return vs
+}
+
+func putQuadVertices(vs []float32, sx0, sy0, sx1, sy1 int, geo *affine.GeoM, scale, translate [4]float32, z float32) {
+	x0, y0 := 0.0, 0.0
+	x1, y1 := float64(sx1-sx0), float64(sy1-sy0)
+	u0, v0, u1, v1 := float32(sx0), float32(sy0), float32(sx1), float32(sy1)
+
+	// The layout of a vertex is (x, y, z, u, v, u', v', scale (4 values), translate (4 values)).
+	// The texture coordinates: first 2 values indicates the actual coodinate, and
+	// the second indicates diagonally opposite coodinates.
+	// The second is needed to calculate source rectangle size in shader programs.
+	corners := [4][6]float32{}
+	x, y := geo.Apply32(x0, y0)
+	corners[0] = [6]float32{x, y, u0, v0, u1, v1}
+	x, y = geo.Apply32(x1, y0)
+	corners[1] = [6]float32{x, y, u1, v0, u0, v1}
+	x, y = geo.Apply32(x0, y1)
+	corners[2] = [6]float32{x, y, u0, v1, u1, v0}
+	x, y = geo.Apply32(x1, y1)
+	corners[3] = [6]float32{x, y, u1, v1, u0, v0}
+
+	for i, c := range corners {
+		v := vs[i*vertexFloat32Num : (i+1)*vertexFloat32Num]
+		v[0] = c[0]
+		v[1] = c[1]
+		v[2] = z
+		v[3] = c[2]
+		v[4] = c[3]
+		v[5] = c[4]
+		v[6] = c[5]
+		copy(v[7:11], scale[:])
+		copy(v[11:15], translate[:])
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hooks
+
+import (
+	"sync"
+)
+
+// LifecycleHandler is a resource that needs to be handled at the lifecycle events of the app.
+type LifecycleHandler interface {
+	// Suspend is called when the app is suspended.
+	Suspend() error
+
+	// Restore is called when the app is resumed, or when the graphics context is lost and the images are restored.
+	Restore() error
+}
+
+var (
+	lifecycleHandlers []LifecycleHandler
+	suspended         bool
+	restoreRequested  bool
+
+	// suspendErr is the error returned by a Suspend function. This is returned at RunRestoreHooksIfNeeded.
+	suspendErr error
+
+	lifecycleM sync.Mutex
+)
+
+// AddLifecycleHandler adds a lifecycle handler.
+//
+// The handlers are called in the order of addition at suspending, and in the reverse order at restoring.
+//
+// If h is already added, AddLifecycleHandler does nothing.
+func AddLifecycleHandler(h LifecycleHandler) {
+	lifecycleM.Lock()
+	defer lifecycleM.Unlock()
+	for _, h2 := range lifecycleHandlers {
+		if h2 == h {
+			return
+		}
+	}
+	lifecycleHandlers = append(lifecycleHandlers, h)
+}
+
+// RemoveLifecycleHandler removes a lifecycle handler.
+//
+// If h is not added, RemoveLifecycleHandler does nothing.
+func RemoveLifecycleHandler(h LifecycleHandler) {
+	lifecycleM.Lock()
+	defer lifecycleM.Unlock()
+	for i, h2 := range lifecycleHandlers {
+		if h2 == h {
+			lifecycleHandlers = append(lifecycleHandlers[:i], lifecycleHandlers[i+1:]...)
+			return
+		}
+	}
+}
+
+func currentLifecycleHandlers() []LifecycleHandler {
+	lifecycleM.Lock()
+	defer lifecycleM.Unlock()
+	return append([]LifecycleHandler{}, lifecycleHandlers...)
+}
+
+// Suspend calls the Suspend functions of the lifecycle handlers.
+//
+// Suspend must be called while the game is not updated, e.g. after the rendering thread is paused.
+// If the app is already suspended, Suspend does nothing.
+//
+// The first error returned by the handlers is returned at RunRestoreHooksIfNeeded.
+func Suspend() {
+	lifecycleM.Lock()
+	if suspended {
+		lifecycleM.Unlock()
+		return
+	}
+	suspended = true
+	lifecycleM.Unlock()
+
+	var err error
+	for _, h := range currentLifecycleHandlers() {
+		if err2 := h.Suspend(); err2 != nil && err == nil {
+			err = err2
+		}
+	}
+
+	lifecycleM.Lock()
+	if suspendErr == nil {
+		suspendErr = err
+	}
+	lifecycleM.Unlock()
+}
+
+// Resume requests to call the Restore functions of the lifecycle handlers at the next RunRestoreHooksIfNeeded.
+//
+// If the app is not suspended, Resume does nothing.
+func Resume() {
+	lifecycleM.Lock()
+	defer lifecycleM.Unlock()
+	if !suspended {
+		return
+	}
+	suspended = false
+	restoreRequested = true
+}
+
+// IsSuspended reports whether the app is suspended.
+func IsSuspended() bool {
+	lifecycleM.Lock()
+	defer lifecycleM.Unlock()
+	return suspended
+}
+
+// RequestRestore requests to call the Restore functions of the lifecycle handlers at the next
+// RunRestoreHooksIfNeeded, e.g. when the images are restored from the context loss.
+func RequestRestore() {
+	lifecycleM.Lock()
+	restoreRequested = true
+	lifecycleM.Unlock()
+}
+
+// RunRestoreHooksIfNeeded calls the Restore functions of the lifecycle handlers if requested.
+//
+// RunRestoreHooksIfNeeded should be called at the beginning of a frame after the images are restored.
+func RunRestoreHooksIfNeeded() error {
+	lifecycleM.Lock()
+	err := suspendErr
+	suspendErr = nil
+	r := restoreRequested
+	restoreRequested = false
+	lifecycleM.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if !r {
+		return nil
+	}
+
+	hs := currentLifecycleHandlers()
+	for i := len(hs) - 1; i >= 0; i-- {
+		if err := hs[i].Restore(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hooks_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/internal/hooks"
+)
+
+type testHandler struct {
+	name   string
+	log    *[]string
+	errSus error
+}
+
+func (h *testHandler) Suspend() error {
+	*h.log = append(*h.log, "suspend "+h.name)
+	return h.errSus
+}
+
+func (h *testHandler) Restore() error {
+	*h.log = append(*h.log, "restore "+h.name)
+	return nil
+}
+
+func TestLifecycleHandlers(t *testing.T) {
+	var log []string
+	a := &testHandler{name: "a", log: &log}
+	b := &testHandler{name: "b", log: &log}
+	AddLifecycleHandler(a)
+	AddLifecycleHandler(b)
+	AddLifecycleHandler(a)
+	defer RemoveLifecycleHandler(a)
+	defer RemoveLifecycleHandler(b)
+
+	// Restore is not called without a request.
+	if err := RunRestoreHooksIfNeeded(); err != nil {
+		t.Fatal(err)
+	}
+
+	Suspend()
+	Suspend()
+	if !IsSuspended() {
+		t.Errorf("IsSuspended(): got: false, want: true")
+	}
+	// Restore is not called until the app is resumed.
+	if err := RunRestoreHooksIfNeeded(); err != nil {
+		t.Fatal(err)
+	}
+	Resume()
+	if IsSuspended() {
+		t.Errorf("IsSuspended(): got: true, want: false")
+	}
+	if err := RunRestoreHooksIfNeeded(); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunRestoreHooksIfNeeded(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restore is called after the context loss without suspending.
+	RequestRestore()
+	if err := RunRestoreHooksIfNeeded(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"suspend a",
+		"suspend b",
+		"restore b",
+		"restore a",
+		"restore b",
+		"restore a",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("got: %v, want: %v", log, want)
+	}
+}
+
+func TestLifecycleHandlerSuspendError(t *testing.T) {
+	var log []string
+	errSus := errors.New("suspend error")
+	a := &testHandler{name: "a", log: &log, errSus: errSus}
+	AddLifecycleHandler(a)
+	defer RemoveLifecycleHandler(a)
+
+	Suspend()
+	Resume()
+	if err := RunRestoreHooksIfNeeded(); err != errSus {
+		t.Errorf("RunRestoreHooksIfNeeded(): got: %v, want: %v", err, errSus)
+	}
+	if err := RunRestoreHooksIfNeeded(); err != nil {
+		t.Errorf("RunRestoreHooksIfNeeded(): got: %v, want: nil", err)
+	}
+}
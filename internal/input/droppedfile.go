@@ -0,0 +1,58 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"sync"
+)
+
+// DroppedFile is a file dropped onto the window.
+type DroppedFile struct {
+	// Name is the base name of the file.
+	Name string
+
+	// Path is the path of the file on the file system. Path is empty on browsers.
+	Path string
+
+	// Data is the content of the file. Data is nil unless the file is read on dropping, e.g. on browsers.
+	Data []byte
+}
+
+// droppedFiles is kept apart from Input as this is common to all the platforms.
+var (
+	droppedFiles  []*DroppedFile
+	droppedFilesM sync.Mutex
+)
+
+// DroppedFiles returns the files dropped since the last ClearDroppedFiles.
+func (i *Input) DroppedFiles() []*DroppedFile {
+	droppedFilesM.Lock()
+	defer droppedFilesM.Unlock()
+	return append([]*DroppedFile{}, droppedFiles...)
+}
+
+// AppendDroppedFiles appends the given files to the dropped files.
+func (i *Input) AppendDroppedFiles(files ...*DroppedFile) {
+	droppedFilesM.Lock()
+	defer droppedFilesM.Unlock()
+	droppedFiles = append(droppedFiles, files...)
+}
+
+// ClearDroppedFiles clears the dropped files.
+func (i *Input) ClearDroppedFiles() {
+	droppedFilesM.Lock()
+	defer droppedFilesM.Unlock()
+	droppedFiles = nil
+}
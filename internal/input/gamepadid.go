@@ -0,0 +1,73 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseGamepadID parses the id of the Gamepad API and returns the name, the vendor ID and the product ID.
+//
+// The format of the id depends on browsers:
+//
+//   Chrome:  "USB Gamepad (STANDARD GAMEPAD Vendor: 0079 Product: 0011)"
+//   Firefox: "0079-0011-USB Gamepad"
+//
+// The IDs are 0 when the id doesn't include them.
+func parseGamepadID(id string) (name string, vendor, product int) {
+	if i := strings.Index(id, "Vendor: "); i >= 0 {
+		if j := strings.Index(id, "Product: "); j >= 0 {
+			v, err1 := strconv.ParseUint(firstField(id[i+len("Vendor: "):]), 16, 16)
+			p, err2 := strconv.ParseUint(firstField(id[j+len("Product: "):]), 16, 16)
+			if err1 == nil && err2 == nil {
+				vendor, product = int(v), int(p)
+			}
+		}
+		if k := strings.LastIndex(id, " ("); k >= 0 {
+			id = id[:k]
+		}
+		return id, vendor, product
+	}
+
+	if tokens := strings.SplitN(id, "-", 3); len(tokens) == 3 && len(tokens[0]) == 4 && len(tokens[1]) == 4 {
+		v, err1 := strconv.ParseUint(tokens[0], 16, 16)
+		p, err2 := strconv.ParseUint(tokens[1], 16, 16)
+		if err1 == nil && err2 == nil {
+			return tokens[2], int(v), int(p)
+		}
+	}
+	return id, 0, 0
+}
+
+// firstField returns the string before the first space or ')'.
+func firstField(str string) string {
+	if i := strings.IndexAny(str, " )"); i >= 0 {
+		return str[:i]
+	}
+	return str
+}
+
+// GamepadGUID returns the GUID for SDL's game controller database of a USB gamepad.
+//
+// GamepadGUID returns an empty string when either of the IDs is 0.
+func GamepadGUID(vendor, product int) string {
+	if vendor == 0 || product == 0 {
+		return ""
+	}
+	// The bus type (USB), the vendor ID and the product ID in little endian, each followed by 2 zero bytes.
+	return fmt.Sprintf("03000000%02x%02x0000%02x%02x000000000000", vendor&0xff, vendor>>8, product&0xff, product>>8)
+}
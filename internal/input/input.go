@@ -72,6 +72,33 @@ func (i *Input) GamepadButtonNum(id int) int {
 	return i.gamepads[id].buttonNum
 }
 
+func (i *Input) GamepadName(id int) string {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	if len(i.gamepads) <= id {
+		return ""
+	}
+	return i.gamepads[id].name
+}
+
+func (i *Input) GamepadVendorID(id int) int {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	if len(i.gamepads) <= id {
+		return 0
+	}
+	return i.gamepads[id].vendor
+}
+
+func (i *Input) GamepadProductID(id int) int {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	if len(i.gamepads) <= id {
+		return 0
+	}
+	return i.gamepads[id].product
+}
+
 func (i *Input) IsGamepadButtonPressed(id int, button GamepadButton) bool {
 	i.m.RLock()
 	defer i.m.RUnlock()
@@ -102,6 +129,9 @@ func (in *Input) Touches() []*Touch {
 
 type gamePad struct {
 	valid         bool
+	name          string
+	vendor        int
+	product       int
 	axisNum       int
 	axes          [16]float64
 	buttonNum     int
@@ -16,10 +16,13 @@
 // +build !js
 // +build !android
 // +build !ios
+// +build !ebitenheadless !linux
+// +build !ebitenexternal
 
 package input
 
 import (
+	"path/filepath"
 	"sync"
 	"unicode"
 
@@ -101,6 +104,16 @@ func (i *Input) Update(window *glfw.Window, scale float64) {
 				i.m.Unlock()
 			}
 		})
+		window.SetDropCallback(func(w *glfw.Window, names []string) {
+			files := make([]*DroppedFile, 0, len(names))
+			for _, n := range names {
+				files = append(files, &DroppedFile{
+					Name: filepath.Base(n),
+					Path: n,
+				})
+			}
+			i.AppendDroppedFiles(files...)
+		})
 	}
 	if i.keyPressed == nil {
 		i.keyPressed = map[glfw.Key]bool{}
@@ -119,10 +132,13 @@ func (i *Input) Update(window *glfw.Window, scale float64) {
 	i.cursorY = int(y / scale)
 	for id := glfw.Joystick(0); id < glfw.Joystick(len(i.gamepads)); id++ {
 		i.gamepads[id].valid = false
+		i.gamepads[id].name = ""
 		if !glfw.JoystickPresent(id) {
 			continue
 		}
 		i.gamepads[id].valid = true
+		// GLFW 3.2 doesn't provide the vendor and the product IDs.
+		i.gamepads[id].name = glfw.GetJoystickName(id)
 
 		axes32 := glfw.GetJoystickAxes(id)
 		i.gamepads[id].axisNum = len(axes32)
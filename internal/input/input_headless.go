@@ -0,0 +1,50 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ebitenheadless,linux ebitenexternal
+// +build !js
+// +build !android
+// +build !ios
+
+package input
+
+import (
+	"sync"
+)
+
+// Input is the input state in headless mode and external mode.
+// As there is no input device in headless mode, nothing is ever pressed.
+// In external mode, the host application handles the input devices.
+type Input struct {
+	cursorX  int
+	cursorY  int
+	gamepads [16]gamePad
+	touches  []*Touch
+	m        sync.RWMutex
+}
+
+func (i *Input) RuneBuffer() []rune {
+	return nil
+}
+
+func (i *Input) ClearRuneBuffer() {
+}
+
+func (i *Input) IsKeyPressed(key Key) bool {
+	return false
+}
+
+func (i *Input) IsMouseButtonPressed(key MouseButton) bool {
+	return false
+}
@@ -19,7 +19,7 @@ package input
 import (
 	"unicode"
 
-	"github.com/gopherjs/gopherjs/js"
+	"github.com/hajimehoshi/ebiten/internal/js"
 )
 
 type mockRWLock struct{}
@@ -138,8 +138,8 @@ func (i *Input) setMouseCursor(x, y int) {
 }
 
 func (i *Input) UpdateGamepads() {
-	nav := js.Global.Get("navigator")
-	if nav.Get("getGamepads") == js.Undefined {
+	nav := js.Global().Get("navigator")
+	if nav.Get("getGamepads").Type() == js.TypeUndefined {
 		return
 	}
 	gamepads := nav.Call("getGamepads")
@@ -147,10 +147,11 @@ func (i *Input) UpdateGamepads() {
 	for id := 0; id < l; id++ {
 		i.gamepads[id].valid = false
 		gamepad := gamepads.Index(id)
-		if gamepad == js.Undefined || gamepad == nil {
+		if gamepad.Type() == js.TypeUndefined || gamepad.Type() == js.TypeNull {
 			continue
 		}
 		i.gamepads[id].valid = true
+		i.gamepads[id].name, i.gamepads[id].vendor, i.gamepads[id].product = parseGamepadID(gamepad.Get("id").String())
 
 		axes := gamepad.Get("axes")
 		axesNum := axes.Get("length").Int()
@@ -176,9 +177,9 @@ func (i *Input) UpdateGamepads() {
 	}
 }
 
-func OnKeyDown(e *js.Object) {
+func OnKeyDown(e js.Value) {
 	c := e.Get("code")
-	if c == js.Undefined {
+	if c.Type() == js.TypeUndefined {
 		code := e.Get("keyCode").Int()
 		if keyCodeToKeyEdge[code] == KeyUp ||
 			keyCodeToKeyEdge[code] == KeyDown ||
@@ -203,16 +204,49 @@ func OnKeyDown(e *js.Object) {
 	theInput.keyDown(cs)
 }
 
-func OnKeyPress(e *js.Object) {
+func OnKeyPress(e js.Value) {
 	e.Call("preventDefault")
 	if r := rune(e.Get("charCode").Int()); unicode.IsPrint(r) {
 		theInput.runeBuffer = append(theInput.runeBuffer, r)
 	}
 }
 
-func OnKeyUp(e *js.Object) {
+func OnDragOver(e js.Value) {
+	// preventDefault is required to accept dropping.
 	e.Call("preventDefault")
-	if e.Get("code") == js.Undefined {
+}
+
+func OnDrop(e js.Value) {
+	e.Call("preventDefault")
+	files := e.Get("dataTransfer").Get("files")
+	for j := 0; j < files.Length(); j++ {
+		readDroppedFile(files.Index(j))
+	}
+}
+
+// readDroppedFile reads the content of the file by the File API.
+// The file is appended to the dropped files asynchronously when the reading finishes.
+func readDroppedFile(file js.Value) {
+	reader := js.Global().Get("FileReader").New()
+	var f js.Func
+	f = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		defer f.Release()
+		a := js.Global().Get("Uint8Array").New(reader.Get("result"))
+		data := make([]byte, a.Length())
+		js.CopyBytesToGo(data, a)
+		theInput.AppendDroppedFiles(&DroppedFile{
+			Name: file.Get("name").String(),
+			Data: data,
+		})
+		return nil
+	})
+	reader.Call("addEventListener", "load", f)
+	reader.Call("readAsArrayBuffer", file)
+}
+
+func OnKeyUp(e js.Value) {
+	e.Call("preventDefault")
+	if e.Get("code").Type() == js.TypeUndefined {
 		// Assume that UA is Edge.
 		code := e.Get("keyCode").Int()
 		theInput.keyUpEdge(code)
@@ -222,46 +256,46 @@ func OnKeyUp(e *js.Object) {
 	theInput.keyUp(code)
 }
 
-func OnMouseDown(e *js.Object) {
+func OnMouseDown(e js.Value) {
 	e.Call("preventDefault")
 	button := e.Get("button").Int()
 	theInput.mouseDown(button)
 	setMouseCursorFromEvent(e)
 }
 
-func OnMouseUp(e *js.Object) {
+func OnMouseUp(e js.Value) {
 	e.Call("preventDefault")
 	button := e.Get("button").Int()
 	theInput.mouseUp(button)
 	setMouseCursorFromEvent(e)
 }
 
-func OnMouseMove(e *js.Object) {
+func OnMouseMove(e js.Value) {
 	e.Call("preventDefault")
 	setMouseCursorFromEvent(e)
 }
 
-func OnTouchStart(e *js.Object) {
+func OnTouchStart(e js.Value) {
 	e.Call("preventDefault")
 	theInput.updateTouches(e)
 }
 
-func OnTouchEnd(e *js.Object) {
+func OnTouchEnd(e js.Value) {
 	e.Call("preventDefault")
 	theInput.updateTouches(e)
 }
 
-func OnTouchMove(e *js.Object) {
+func OnTouchMove(e js.Value) {
 	e.Call("preventDefault")
 	theInput.updateTouches(e)
 }
 
-func setMouseCursorFromEvent(e *js.Object) {
+func setMouseCursorFromEvent(e js.Value) {
 	x, y := e.Get("clientX").Int(), e.Get("clientY").Int()
 	theInput.setMouseCursor(x, y)
 }
 
-func (i *Input) updateTouches(e *js.Object) {
+func (i *Input) updateTouches(e js.Value) {
 	j := e.Get("targetTouches")
 	ts := make([]*Touch, j.Get("length").Int())
 	for i := 0; i < len(ts); i++ {
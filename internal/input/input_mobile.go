@@ -18,6 +18,8 @@ package input
 
 import (
 	"sync"
+
+	"github.com/hajimehoshi/ebiten/internal/hooks"
 )
 
 type Input struct {
@@ -28,6 +30,27 @@ type Input struct {
 	m        sync.RWMutex
 }
 
+func init() {
+	hooks.AddLifecycleHandler(theInput)
+}
+
+// Suspend implements hooks.LifecycleHandler.
+//
+// Suspend releases the touches and the gamepads since their release events are not notified while the app is
+// suspended.
+func (i *Input) Suspend() error {
+	i.m.Lock()
+	i.touches = nil
+	i.gamepads = [16]gamePad{}
+	i.m.Unlock()
+	return nil
+}
+
+// Restore implements hooks.LifecycleHandler.
+func (i *Input) Restore() error {
+	return nil
+}
+
 func (i *Input) RuneBuffer() []rune {
 	return nil
 }
@@ -18,6 +18,8 @@
 // +build !js
 // +build !android
 // +build !ios
+// +build !ebitenheadless !linux
+// +build !ebitenexternal
 
 package input
 
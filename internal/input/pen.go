@@ -0,0 +1,67 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"sync"
+)
+
+// PenState is a snapshot of a pen's state.
+//
+// The position is in the logical screen coordinates.
+type PenState struct {
+	X        float64
+	Y        float64
+	Pressure float64
+	TiltX    float64
+	TiltY    float64
+	Eraser   bool
+	Pressed  bool
+}
+
+// Pen is the state of the pen on or over the screen.
+type Pen struct {
+	state PenState
+	valid bool
+	m     sync.RWMutex
+}
+
+var thePen = &Pen{}
+
+func GetPen() *Pen {
+	return thePen
+}
+
+// Set sets the pen state.
+func (p *Pen) Set(state PenState) {
+	p.m.Lock()
+	p.state = state
+	p.valid = true
+	p.m.Unlock()
+}
+
+// Reset resets the pen state when the pen leaves the screen.
+func (p *Pen) Reset() {
+	p.m.Lock()
+	p.state = PenState{}
+	p.valid = false
+	p.m.Unlock()
+}
+
+func (p *Pen) State() (PenState, bool) {
+	p.m.RLock()
+	defer p.m.RUnlock()
+	return p.state, p.valid
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"sync"
+)
+
+// Sensors is the state of the motion sensors.
+//
+// The values are in the conventions of the W3C DeviceOrientation Event: the device coordinates are
+// x toward the right of the screen, y toward the top and z out of the screen.
+type Sensors struct {
+	acceleration    [3]float64
+	hasAcceleration bool
+	rotationRate    [3]float64
+	hasRotationRate bool
+	orientation     [3]float64
+	hasOrientation  bool
+	m               sync.RWMutex
+}
+
+var theSensors = &Sensors{}
+
+func GetSensors() *Sensors {
+	return theSensors
+}
+
+// SetAcceleration sets the acceleration including the gravity in m/s².
+func (s *Sensors) SetAcceleration(x, y, z float64) {
+	s.m.Lock()
+	s.acceleration = [3]float64{x, y, z}
+	s.hasAcceleration = true
+	s.m.Unlock()
+}
+
+// SetRotationRate sets the rotation rate around the axes in radians per second.
+func (s *Sensors) SetRotationRate(x, y, z float64) {
+	s.m.Lock()
+	s.rotationRate = [3]float64{x, y, z}
+	s.hasRotationRate = true
+	s.m.Unlock()
+}
+
+// SetOrientation sets the orientation angles in radians.
+func (s *Sensors) SetOrientation(alpha, beta, gamma float64) {
+	s.m.Lock()
+	s.orientation = [3]float64{alpha, beta, gamma}
+	s.hasOrientation = true
+	s.m.Unlock()
+}
+
+func (s *Sensors) Acceleration() (x, y, z float64, ok bool) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.acceleration[0], s.acceleration[1], s.acceleration[2], s.hasAcceleration
+}
+
+func (s *Sensors) RotationRate() (x, y, z float64, ok bool) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.rotationRate[0], s.rotationRate[1], s.rotationRate[2], s.hasRotationRate
+}
+
+func (s *Sensors) Orientation() (alpha, beta, gamma float64, ok bool) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.orientation[0], s.orientation[1], s.orientation[2], s.hasOrientation
+}
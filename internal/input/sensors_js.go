@@ -0,0 +1,65 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build js
+
+package input
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/internal/js"
+)
+
+const degToRad = math.Pi / 180
+
+func OnDeviceMotion(e js.Value) {
+	if a := e.Get("accelerationIncludingGravity"); a.Truthy() && a.Get("x").Type() == js.TypeNumber {
+		theSensors.SetAcceleration(a.Get("x").Float(), a.Get("y").Float(), a.Get("z").Float())
+	}
+	// alpha, beta and gamma of rotationRate are the rates around z, x and y axes.
+	if r := e.Get("rotationRate"); r.Truthy() && r.Get("alpha").Type() == js.TypeNumber {
+		theSensors.SetRotationRate(r.Get("beta").Float()*degToRad, r.Get("gamma").Float()*degToRad, r.Get("alpha").Float()*degToRad)
+	}
+}
+
+func OnDeviceOrientation(e js.Value) {
+	if e.Get("beta").Type() != js.TypeNumber {
+		// The orientation sensor is not available.
+		return
+	}
+	theSensors.SetOrientation(e.Get("alpha").Float()*degToRad, e.Get("beta").Float()*degToRad, e.Get("gamma").Float()*degToRad)
+}
+
+var sensorPermissionRequested bool
+
+// RequestSensorPermission requests the permission to use the motion sensors if the browser requires it,
+// e.g. Safari on iOS. The permission can be requested only in a handler of a user gesture like a click.
+//
+// RequestSensorPermission does nothing after the first call.
+func RequestSensorPermission() {
+	if sensorPermissionRequested {
+		return
+	}
+	sensorPermissionRequested = true
+
+	for _, name := range []string{"DeviceMotionEvent", "DeviceOrientationEvent"} {
+		klass := js.Global().Get(name)
+		if !klass.Truthy() || klass.Get("requestPermission").Type() != js.TypeFunction {
+			continue
+		}
+		// The events are dispatched after the permission is granted. The result doesn't have to be handled.
+		klass.Call("requestPermission")
+	}
+}
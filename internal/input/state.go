@@ -0,0 +1,158 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"sync/atomic"
+)
+
+// State is a snapshot of the input state.
+//
+// The positions are in the logical screen coordinates, that is, already adjusted by the screen scale.
+type State struct {
+	Runes        []rune         `json:"runes,omitempty"`
+	Keys         []Key          `json:"keys,omitempty"`
+	MouseButtons []MouseButton  `json:"mouseButtons,omitempty"`
+	CursorX      int            `json:"cursorX"`
+	CursorY      int            `json:"cursorY"`
+	Gamepads     []GamepadState `json:"gamepads,omitempty"`
+	Touches      []TouchState   `json:"touches,omitempty"`
+}
+
+// GamepadState is a snapshot of a gamepad's state.
+type GamepadState struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	VendorID  int       `json:"vendorId,omitempty"`
+	ProductID int       `json:"productId,omitempty"`
+	Axes      []float64 `json:"axes,omitempty"`
+	Buttons   []bool    `json:"buttons,omitempty"`
+}
+
+// TouchState is a snapshot of a touch's state.
+type TouchState struct {
+	ID int `json:"id"`
+	X  int `json:"x"`
+	Y  int `json:"y"`
+}
+
+// theOverridingState is the state overriding the actual input state (*State).
+var theOverridingState atomic.Value
+
+// SetOverridingState sets the state that overrides the actual input state.
+//
+// If s is nil, the actual input state is used again.
+func SetOverridingState(s *State) {
+	theOverridingState.Store(s)
+}
+
+// OverridingState returns the state overriding the actual input state.
+//
+// If the actual input state is used, OverridingState returns nil.
+func OverridingState() *State {
+	s, _ := theOverridingState.Load().(*State)
+	return s
+}
+
+func (s *State) IsKeyPressed(key Key) bool {
+	for _, k := range s.Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *State) IsMouseButtonPressed(button MouseButton) bool {
+	for _, b := range s.MouseButtons {
+		if b == button {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *State) GamepadIDs() []int {
+	ids := make([]int, len(s.Gamepads))
+	for i, g := range s.Gamepads {
+		ids[i] = g.ID
+	}
+	return ids
+}
+
+func (s *State) gamepad(id int) *GamepadState {
+	for i := range s.Gamepads {
+		if s.Gamepads[i].ID == id {
+			return &s.Gamepads[i]
+		}
+	}
+	return nil
+}
+
+func (s *State) GamepadAxisNum(id int) int {
+	g := s.gamepad(id)
+	if g == nil {
+		return 0
+	}
+	return len(g.Axes)
+}
+
+func (s *State) GamepadAxis(id int, axis int) float64 {
+	g := s.gamepad(id)
+	if g == nil || axis < 0 || len(g.Axes) <= axis {
+		return 0
+	}
+	return g.Axes[axis]
+}
+
+func (s *State) GamepadButtonNum(id int) int {
+	g := s.gamepad(id)
+	if g == nil {
+		return 0
+	}
+	return len(g.Buttons)
+}
+
+func (s *State) GamepadName(id int) string {
+	g := s.gamepad(id)
+	if g == nil {
+		return ""
+	}
+	return g.Name
+}
+
+func (s *State) GamepadVendorID(id int) int {
+	g := s.gamepad(id)
+	if g == nil {
+		return 0
+	}
+	return g.VendorID
+}
+
+func (s *State) GamepadProductID(id int) int {
+	g := s.gamepad(id)
+	if g == nil {
+		return 0
+	}
+	return g.ProductID
+}
+
+func (s *State) IsGamepadButtonPressed(id int, button GamepadButton) bool {
+	g := s.gamepad(id)
+	if g == nil || button < 0 || GamepadButton(len(g.Buttons)) <= button {
+		return false
+	}
+	return g.Buttons[button]
+}
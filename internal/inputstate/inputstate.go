@@ -0,0 +1,66 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inputstate captures the input state via the ebiten package.
+package inputstate
+
+import (
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/input"
+)
+
+// Capture returns the current input state obtained via the ebiten package.
+//
+// The state reflects the overriding state if any, as the ebiten package does.
+func Capture() input.State {
+	var s input.State
+	s.Runes = ebiten.InputChars()
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if ebiten.IsKeyPressed(k) {
+			s.Keys = append(s.Keys, input.Key(k))
+		}
+	}
+	for _, b := range []ebiten.MouseButton{
+		ebiten.MouseButtonLeft,
+		ebiten.MouseButtonRight,
+		ebiten.MouseButtonMiddle,
+	} {
+		if ebiten.IsMouseButtonPressed(b) {
+			s.MouseButtons = append(s.MouseButtons, input.MouseButton(b))
+		}
+	}
+	s.CursorX, s.CursorY = ebiten.CursorPosition()
+	for _, id := range ebiten.GamepadIDs() {
+		g := input.GamepadState{
+			ID:        id,
+			Name:      ebiten.GamepadName(id),
+			VendorID:  ebiten.GamepadVendorID(id),
+			ProductID: ebiten.GamepadProductID(id),
+			Axes:      make([]float64, ebiten.GamepadAxisNum(id)),
+			Buttons:   make([]bool, ebiten.GamepadButtonNum(id)),
+		}
+		for a := range g.Axes {
+			g.Axes[a] = ebiten.GamepadAxis(id, a)
+		}
+		for b := range g.Buttons {
+			g.Buttons[b] = ebiten.IsGamepadButtonPressed(id, ebiten.GamepadButton(b))
+		}
+		s.Gamepads = append(s.Gamepads, g)
+	}
+	for _, t := range ebiten.Touches() {
+		x, y := t.Position()
+		s.Touches = append(s.Touches, input.TouchState{ID: t.ID(), X: x, Y: y})
+	}
+	return s
+}
@@ -0,0 +1,23 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build js
+
+// Package js is a thin wrapper of JavaScript values for GopherJS and WebAssembly.
+//
+// The API is a subset of syscall/js's. On WebAssembly, the types and the functions are
+// just the ones of syscall/js. On GopherJS, they are implemented with *js.Object.
+//
+// Note that Value is not comparable. Use pointers to Value to compare objects.
+package js
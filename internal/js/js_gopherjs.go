@@ -0,0 +1,199 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build js,!wasm
+
+package js
+
+import (
+	"github.com/gopherjs/gopherjs/js"
+)
+
+type Type int
+
+const (
+	TypeUndefined Type = iota
+	TypeNull
+	TypeBoolean
+	TypeNumber
+	TypeString
+	TypeSymbol
+	TypeObject
+	TypeFunction
+)
+
+// Value represents a JavaScript value.
+//
+// Note that a JavaScript null is represented as a nil *js.Object in GopherJS.
+type Value struct {
+	v *js.Object
+
+	// The array makes Value not comparable as syscall/js's Value is not.
+	_ [0]func()
+}
+
+// Func is a wrapped Go function to be called by JavaScript.
+type Func struct {
+	Value
+}
+
+var typeOf = js.Global.Get("Function").New("x", "return typeof x;")
+
+func Global() Value {
+	return Value{v: js.Global}
+}
+
+func Undefined() Value {
+	return Value{v: js.Undefined}
+}
+
+func Null() Value {
+	return Value{}
+}
+
+func FuncOf(fn func(this Value, args []Value) interface{}) Func {
+	f := js.MakeFunc(func(this *js.Object, args []*js.Object) interface{} {
+		vs := make([]Value, len(args))
+		for i, a := range args {
+			vs[i] = Value{v: a}
+		}
+		return unwrap(fn(Value{v: this}, vs))
+	})
+	return Func{Value{v: f}}
+}
+
+// Release does nothing on GopherJS. A function is released by the garbage collector.
+func (f Func) Release() {
+}
+
+// unwrap converts x into a value that GopherJS can pass to JavaScript.
+func unwrap(x interface{}) interface{} {
+	switch x := x.(type) {
+	case Value:
+		return x.v
+	case Func:
+		return x.v
+	}
+	return x
+}
+
+func unwrapArgs(args []interface{}) []interface{} {
+	r := make([]interface{}, len(args))
+	for i, a := range args {
+		r[i] = unwrap(a)
+	}
+	return r
+}
+
+func (v Value) Get(p string) Value {
+	return Value{v: v.v.Get(p)}
+}
+
+func (v Value) Set(p string, x interface{}) {
+	v.v.Set(p, unwrap(x))
+}
+
+func (v Value) Index(i int) Value {
+	return Value{v: v.v.Index(i)}
+}
+
+func (v Value) Length() int {
+	return v.v.Length()
+}
+
+func (v Value) Call(m string, args ...interface{}) Value {
+	return Value{v: v.v.Call(m, unwrapArgs(args)...)}
+}
+
+func (v Value) Invoke(args ...interface{}) Value {
+	return Value{v: v.v.Invoke(unwrapArgs(args)...)}
+}
+
+func (v Value) New(args ...interface{}) Value {
+	return Value{v: v.v.New(unwrapArgs(args)...)}
+}
+
+func (v Value) Bool() bool {
+	return v.v.Bool()
+}
+
+func (v Value) Int() int {
+	return v.v.Int()
+}
+
+func (v Value) Float() float64 {
+	return v.v.Float()
+}
+
+func (v Value) String() string {
+	return v.v.String()
+}
+
+func (v Value) Truthy() bool {
+	if v.v == nil {
+		return false
+	}
+	return v.v.Bool()
+}
+
+func (v Value) Type() Type {
+	if v.v == nil {
+		return TypeNull
+	}
+	switch typeOf.Invoke(v.v).String() {
+	case "undefined":
+		return TypeUndefined
+	case "boolean":
+		return TypeBoolean
+	case "number":
+		return TypeNumber
+	case "string":
+		return TypeString
+	case "symbol":
+		return TypeSymbol
+	case "function":
+		return TypeFunction
+	default:
+		return TypeObject
+	}
+}
+
+func CopyBytesToGo(dst []byte, src Value) int {
+	return copy(dst, src.v.Interface().([]byte))
+}
+
+func CopyBytesToJS(dst Value, src []byte) int {
+	n := dst.v.Length()
+	if n > len(src) {
+		n = len(src)
+	}
+	// GopherJS converts a byte slice into a Uint8Array.
+	dst.v.Call("set", src[:n])
+	return n
+}
+
+// Uint8ArrayOf returns a new Uint8Array that has a copy of v.
+func Uint8ArrayOf(v []byte) Value {
+	return Value{v: js.Global.Get("Uint8Array").New(v)}
+}
+
+// Uint16ArrayOf returns a new Uint16Array that has a copy of v.
+func Uint16ArrayOf(v []uint16) Value {
+	return Value{v: js.Global.Get("Uint16Array").New(v)}
+}
+
+// Float32ArrayOf returns a new Float32Array that has a copy of v.
+func Float32ArrayOf(v []float32) Value {
+	return Value{v: js.Global.Get("Float32Array").New(v)}
+}
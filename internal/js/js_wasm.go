@@ -0,0 +1,95 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build js,wasm
+
+package js
+
+import (
+	"reflect"
+	"syscall/js"
+	"unsafe"
+)
+
+type (
+	Value = js.Value
+	Func  = js.Func
+	Type  = js.Type
+)
+
+const (
+	TypeUndefined = js.TypeUndefined
+	TypeNull      = js.TypeNull
+	TypeBoolean   = js.TypeBoolean
+	TypeNumber    = js.TypeNumber
+	TypeString    = js.TypeString
+	TypeSymbol    = js.TypeSymbol
+	TypeObject    = js.TypeObject
+	TypeFunction  = js.TypeFunction
+)
+
+func Global() Value {
+	return js.Global()
+}
+
+func Undefined() Value {
+	return js.Undefined()
+}
+
+func Null() Value {
+	return js.Null()
+}
+
+func FuncOf(fn func(this Value, args []Value) interface{}) Func {
+	return js.FuncOf(fn)
+}
+
+func CopyBytesToGo(dst []byte, src Value) int {
+	return js.CopyBytesToGo(dst, src)
+}
+
+func CopyBytesToJS(dst Value, src []byte) int {
+	return js.CopyBytesToJS(dst, src)
+}
+
+// Uint8ArrayOf returns a new Uint8Array that has a copy of v.
+func Uint8ArrayOf(v []byte) Value {
+	a := js.Global().Get("Uint8Array").New(len(v))
+	js.CopyBytesToJS(a, v)
+	return a
+}
+
+// Uint16ArrayOf returns a new Uint16Array that has a copy of v.
+func Uint16ArrayOf(v []uint16) Value {
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&v))
+
+	var b []byte
+	bh := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	bh.Data = h.Data
+	bh.Len = len(v) * 2
+	bh.Cap = len(v) * 2
+	return js.Global().Get("Uint16Array").New(Uint8ArrayOf(b).Get("buffer"))
+}
+
+// Float32ArrayOf returns a new Float32Array that has a copy of v.
+func Float32ArrayOf(v []float32) Value {
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&v))
+
+	var b []byte
+	bh := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	bh.Data = h.Data
+	bh.Len = len(v) * 4
+	bh.Cap = len(v) * 4
+	return js.Global().Get("Float32Array").New(Uint8ArrayOf(b).Get("buffer"))
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logger reports noteworthy events in the internal packages to the logger set by the user.
+package logger
+
+import (
+	"sync/atomic"
+)
+
+// Level represents the severity of an event.
+type Level int
+
+const (
+	LevelInfo Level = iota
+	LevelWarning
+)
+
+// Field is a key-value pair to describe an event.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Entry is a logged event.
+type Entry struct {
+	Level   Level
+	Message string
+	Fields  []Field
+}
+
+type loggerFunc struct {
+	f func(entry *Entry)
+}
+
+// theLogger is the current logger (*loggerFunc).
+var theLogger atomic.Value
+
+// SetLogger sets the function to receive the events. If f is nil, the events are discarded.
+func SetLogger(f func(entry *Entry)) {
+	theLogger.Store(&loggerFunc{f: f})
+}
+
+// IsEnabled reports whether a logger is set.
+//
+// IsEnabled is useful to avoid calculating the fields when no one receives the events.
+func IsEnabled() bool {
+	l, _ := theLogger.Load().(*loggerFunc)
+	return l != nil && l.f != nil
+}
+
+// Info logs an informational event with the key-value pairs keysAndValues.
+func Info(message string, keysAndValues ...interface{}) {
+	log(LevelInfo, message, keysAndValues)
+}
+
+// Warn logs a warning event with the key-value pairs keysAndValues.
+func Warn(message string, keysAndValues ...interface{}) {
+	log(LevelWarning, message, keysAndValues)
+}
+
+func log(level Level, message string, keysAndValues []interface{}) {
+	l, _ := theLogger.Load().(*loggerFunc)
+	if l == nil || l.f == nil {
+		return
+	}
+	if len(keysAndValues)%2 != 0 {
+		panic("logger: keysAndValues must be key-value pairs")
+	}
+	e := &Entry{
+		Level:   level,
+		Message: message,
+	}
+	for i := 0; i < len(keysAndValues); i += 2 {
+		e.Fields = append(e.Fields, Field{
+			Key:   keysAndValues[i].(string),
+			Value: keysAndValues[i+1],
+		})
+	}
+	l.f(e)
+}
@@ -14,6 +14,10 @@
 
 package math
 
+import (
+	"math"
+)
+
 // NextPowerOf2Int returns a nearest power of 2 to x.
 func NextPowerOf2Int(x int) int {
 	if x <= 0 {
@@ -25,3 +29,81 @@ func NextPowerOf2Int(x int) int {
 	}
 	return r
 }
+
+// Float16bits returns the IEEE 754 binary16 (half precision) representation of f.
+//
+// f is rounded to the nearest representable value. A value too large to be represented
+// is converted to the infinity.
+func Float16bits(f float32) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int((b>>23)&0xff) - 127 + 15
+	mant := b & 0x7fffff
+
+	if (b>>23)&0xff == 0xff {
+		// Infinity or NaN
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	}
+	if exp >= 0x1f {
+		return sign | 0x7c00
+	}
+	if exp <= 0 {
+		// The value is a subnormal number or zero in binary16.
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint(14 - exp)
+		h := mant >> shift
+		if (mant>>(shift-1))&1 != 0 {
+			h++
+		}
+		return sign | uint16(h)
+	}
+	h := uint32(exp)<<10 | mant>>13
+	// Rounding might carry into the exponent, which is still the correct result.
+	if mant&0x1000 != 0 {
+		h++
+	}
+	return sign | uint16(h)
+}
+
+// Float16frombits returns the floating point number of the IEEE 754 binary16 (half precision) representation h.
+func Float16frombits(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		// Zero or a subnormal number
+		f := float32(mant) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	case 0x1f:
+		// Infinity or NaN
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
+
+// SRGBToLinear converts the sRGB-encoded value v in [0, 1] into the linear value.
+func SRGBToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// LinearToSRGB converts the linear value v in [0, 1] into the sRGB-encoded value.
+func LinearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
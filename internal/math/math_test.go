@@ -15,6 +15,7 @@
 package math_test
 
 import (
+	"math"
 	"testing"
 
 	. "github.com/hajimehoshi/ebiten/internal/math"
@@ -39,3 +40,56 @@ func TestNextPowerOf2(t *testing.T) {
 
 	}
 }
+
+func TestFloat16(t *testing.T) {
+	testCases := []struct {
+		f    float32
+		bits uint16
+	}{
+		{0, 0x0000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{65504, 0x7bff},
+		{1.0 / (1 << 24), 0x0001},
+		{1.0 / (1 << 14), 0x0400},
+		{float32(math.Inf(1)), 0x7c00},
+	}
+	for _, c := range testCases {
+		if got := Float16bits(c.f); got != c.bits {
+			t.Errorf("Float16bits(%v): got: 0x%04x, want: 0x%04x", c.f, got, c.bits)
+		}
+		if got := Float16frombits(c.bits); got != c.f {
+			t.Errorf("Float16frombits(0x%04x): got: %v, want: %v", c.bits, got, c.f)
+		}
+	}
+
+	// Too large values are converted to the infinity.
+	if got := Float16bits(1e6); got != 0x7c00 {
+		t.Errorf("Float16bits(1e6): got: 0x%04x, want: 0x7c00", got)
+	}
+	// 1 + 2^-11 is rounded up to 1 + 2^-10.
+	if got := Float16bits(1 + 1.0/(1<<11)); got != 0x3c01 {
+		t.Errorf("Float16bits(1 + 2^-11): got: 0x%04x, want: 0x3c01", got)
+	}
+}
+
+func TestSRGB(t *testing.T) {
+	testCases := []struct {
+		srgb   float64
+		linear float64
+	}{
+		{0, 0},
+		{1, 1},
+		{0.5, 0.21404114048223255},
+		{0.04045, 0.0031308049535603713},
+	}
+	for _, c := range testCases {
+		if got := SRGBToLinear(c.srgb); math.Abs(got-c.linear) > 1e-9 {
+			t.Errorf("SRGBToLinear(%v): got: %v, want: %v", c.srgb, got, c.linear)
+		}
+		if got := LinearToSRGB(c.linear); math.Abs(got-c.srgb) > 1e-6 {
+			t.Errorf("LinearToSRGB(%v): got: %v, want: %v", c.linear, got, c.srgb)
+		}
+	}
+}
@@ -15,7 +15,12 @@
 package opengl
 
 import (
+	"encoding/binary"
+	"image"
 	"math"
+
+	"github.com/hajimehoshi/ebiten/internal/driver"
+	emath "github.com/hajimehoshi/ebiten/internal/math"
 )
 
 var (
@@ -41,6 +46,13 @@ var (
 	dstAlpha         operation
 	oneMinusSrcAlpha operation
 	oneMinusDstAlpha operation
+	dstColor         operation
+	oneMinusSrcColor operation
+
+	funcAdd             equation
+	funcReverseSubtract equation
+	blendMin            equation
+	blendMax            equation
 )
 
 type Context struct {
@@ -50,7 +62,11 @@ type Context struct {
 	lastTexture        Texture
 	lastViewportWidth  int
 	lastViewportHeight int
-	lastCompositeMode  CompositeMode
+	lastCompositeMode  driver.CompositeMode
+	scissorEnabled     bool
+	lastScissor        image.Rectangle
+	lastColorMask      [4]bool
+	depthTestEnabled   bool
 	maxTextureSize     int
 	context
 }
@@ -61,7 +77,8 @@ func GetContext() *Context {
 	return theContext
 }
 
-func (c *Context) BindTexture(t Texture) {
+func (c *Context) BindTexture(texture driver.Texture) {
+	t := toTexture(texture)
 	if c.lastTexture == t {
 		return
 	}
@@ -69,6 +86,20 @@ func (c *Context) BindTexture(t Texture) {
 	c.lastTexture = t
 }
 
+// BindTextureAt binds the texture to the given texture unit.
+//
+// The active texture unit is restored to the unit 0 after binding. As the texture bound to the unit 0
+// is not changed, BindTexture's cache is still valid.
+func (c *Context) BindTextureAt(unit int, texture driver.Texture) {
+	if unit == 0 {
+		c.BindTexture(texture)
+		return
+	}
+	c.activeTextureImpl(unit)
+	c.bindTextureImpl(toTexture(texture))
+	c.activeTextureImpl(0)
+}
+
 func (c *Context) bindFramebuffer(f Framebuffer) {
 	if c.lastFramebuffer == f {
 		return
@@ -77,8 +108,8 @@ func (c *Context) bindFramebuffer(f Framebuffer) {
 	c.lastFramebuffer = f
 }
 
-func (c *Context) SetViewport(f Framebuffer, width, height int) {
-	c.bindFramebuffer(f)
+func (c *Context) SetViewport(f driver.Framebuffer, width, height int) {
+	c.bindFramebuffer(toFramebuffer(f))
 	if c.lastViewportWidth != width || c.lastViewportHeight != height {
 		c.setViewportImpl(width, height)
 		c.lastViewportWidth = width
@@ -86,7 +117,55 @@ func (c *Context) SetViewport(f Framebuffer, width, height int) {
 	}
 }
 
-func (c *Context) ScreenFramebuffer() Framebuffer {
+// SetScissor enables the scissor test with the region (x, y) - (x+width, y+height) in the window coordinates.
+func (c *Context) SetScissor(x, y, width, height int) {
+	r := image.Rect(x, y, x+width, y+height)
+	if c.scissorEnabled && c.lastScissor == r {
+		return
+	}
+	c.setScissorImpl(x, y, width, height)
+	c.scissorEnabled = true
+	c.lastScissor = r
+}
+
+// ColorMask specifies whether each color channel is written or not.
+func (c *Context) ColorMask(r, g, b, a bool) {
+	m := [4]bool{r, g, b, a}
+	if c.lastColorMask == m {
+		return
+	}
+	c.colorMaskImpl(r, g, b, a)
+	c.lastColorMask = m
+}
+
+// DisableScissor disables the scissor test.
+func (c *Context) DisableScissor() {
+	if !c.scissorEnabled {
+		return
+	}
+	c.disableScissorImpl()
+	c.scissorEnabled = false
+}
+
+// SetDepthTest specifies whether the depth test is enabled or not.
+//
+// When the depth test is enabled, the depth buffer is also updated.
+func (c *Context) SetDepthTest(enabled bool) {
+	if c.depthTestEnabled == enabled {
+		return
+	}
+	c.depthTestImpl(enabled)
+	c.depthTestEnabled = enabled
+}
+
+// ClearDepth clears the depth buffer of the current framebuffer.
+//
+// Note that the scissor test is also applied to clearing.
+func (c *Context) ClearDepth() {
+	c.clearDepthImpl()
+}
+
+func (c *Context) ScreenFramebuffer() driver.Framebuffer {
 	return c.screenFramebuffer
 }
 
@@ -101,3 +180,15 @@ func (c *Context) MaxTextureSize() int {
 	}
 	return c.maxTextureSize
 }
+
+// float16Bytes converts the floating point values into IEEE 754 binary16 values in little endian.
+//
+// float16Bytes is used to read the pixels of 16-bit floating point textures, since reading them
+// as 32-bit floating point values is the most portable way.
+func float16Bytes(fs []float32) []byte {
+	p := make([]byte, 2*len(fs))
+	for i, f := range fs {
+		binary.LittleEndian.PutUint16(p[2*i:], emath.Float16bits(f))
+	}
+	return p
+}
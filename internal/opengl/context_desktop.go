@@ -22,16 +22,20 @@ package opengl
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/go-gl/gl/v2.1/gl"
+
+	"github.com/hajimehoshi/ebiten/internal/driver"
 )
 
 type (
-	Texture     uint32
-	Framebuffer uint32
-	Shader      uint32
-	Program     uint32
-	Buffer      uint32
+	Texture      uint32
+	Framebuffer  uint32
+	Renderbuffer uint32
+	Shader       uint32
+	Program      uint32
+	Buffer       uint32
 )
 
 var InvalidTexture Texture
@@ -70,11 +74,37 @@ func init() {
 	dstAlpha = gl.DST_ALPHA
 	oneMinusSrcAlpha = gl.ONE_MINUS_SRC_ALPHA
 	oneMinusDstAlpha = gl.ONE_MINUS_DST_ALPHA
+	dstColor = gl.DST_COLOR
+	oneMinusSrcColor = gl.ONE_MINUS_SRC_COLOR
+
+	funcAdd = gl.FUNC_ADD
+	funcReverseSubtract = gl.FUNC_REVERSE_SUBTRACT
+	blendMin = gl.MIN
+	blendMax = gl.MAX
 }
 
 type context struct {
 	init            bool
+	instancing      bool
+	floatTexture    bool
+	srgb            bool
+	etc2            bool
+	astc            bool
+	bc3             bool
+	sync            bool
 	runOnMainThread func(func() error) error
+
+	// screenFramebufferFixed indicates whether the screen framebuffer is specified by SetScreenFramebuffer.
+	screenFramebufferFixed bool
+
+	// pixelBuffer is the pixel unpack buffer for TexSubImages2DFromPixelBuffer. 0 means not created yet.
+	pixelBuffer uint32
+
+	// uploadJobs is the channel to send jobs to the upload worker. nil means the upload worker is not available.
+	uploadJobs chan *uploadJob
+
+	// uploads is the last upload job of each texture that might not be finished yet.
+	uploads map[Texture]*uploadJob
 }
 
 func Init(runOnMainThread func(func() error) error) {
@@ -88,7 +118,27 @@ func (c *Context) runOnContextThread(f func() error) error {
 }
 
 func (c *Context) Reset() error {
-	if err := c.runOnContextThread(func() error {
+	c.waitAllUploads()
+	if err := c.initialize(); err != nil {
+		return err
+	}
+	c.locationCache = newLocationCache()
+	c.ResetStateCache()
+	if c.screenFramebufferFixed {
+		return nil
+	}
+	_ = c.runOnContextThread(func() error {
+		f := int32(0)
+		gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &f)
+		c.screenFramebuffer = Framebuffer(f)
+		return nil
+	})
+	return nil
+}
+
+// initialize loads the OpenGL functions and checks the extensions only once.
+func (c *Context) initialize() error {
+	return c.runOnContextThread(func() error {
 		if c.init {
 			return nil
 		}
@@ -96,32 +146,114 @@ func (c *Context) Reset() error {
 		if err := gl.Init(); err != nil {
 			return fmt.Errorf("opengl: initializing error %v", err)
 		}
+		// OpenGL 2.1 doesn't have instanced drawing as core functions.
+		// Use them only when the extensions are available.
+		exts := strings.Split(gl.GoStr(gl.GetString(gl.EXTENSIONS)), " ")
+		arrays, draw := false, false
+		float, halfFloat := false, false
+		srgb := false
+		etc2, astc, bc3 := false, false, false
+		sync := false
+		for _, e := range exts {
+			switch e {
+			case "GL_ARB_instanced_arrays":
+				arrays = true
+			case "GL_ARB_draw_instanced":
+				draw = true
+			case "GL_ARB_texture_float":
+				float = true
+			case "GL_ARB_half_float_pixel":
+				halfFloat = true
+			case "GL_ARB_framebuffer_sRGB", "GL_EXT_framebuffer_sRGB":
+				srgb = true
+			case "GL_ARB_ES3_compatibility":
+				etc2 = true
+			case "GL_KHR_texture_compression_astc_ldr":
+				astc = true
+			case "GL_EXT_texture_compression_s3tc":
+				bc3 = true
+			case "GL_ARB_sync":
+				sync = true
+			}
+		}
+		c.instancing = arrays && draw
+		c.floatTexture = float && halfFloat
+		// sRGB textures are a core feature as of OpenGL 2.1, but rendering to them requires the extension.
+		c.srgb = srgb
+		// ETC2 is a core feature of OpenGL ES 3.0, which is available with GL_ARB_ES3_compatibility.
+		c.etc2 = etc2
+		c.astc = astc
+		c.bc3 = bc3
+		// Fence sync objects are required to keep the order of the commands with the upload worker.
+		c.sync = sync
 		c.init = true
 		return nil
-	}); err != nil {
-		return err
-	}
-	c.locationCache = newLocationCache()
+	})
+}
+
+// ResetStateCache discards the cached state and sets the state that Ebiten assumes again.
+//
+// This is necessary when the state might be changed outside of Ebiten, e.g. by the host application.
+func (c *Context) ResetStateCache() {
 	c.lastTexture = invalidTexture
 	c.lastFramebuffer = invalidFramebuffer
 	c.lastViewportWidth = 0
 	c.lastViewportHeight = 0
-	c.lastCompositeMode = CompositeModeUnknown
+	c.lastCompositeMode = driver.CompositeModeUnknown
 	_ = c.runOnContextThread(func() error {
 		gl.Enable(gl.BLEND)
+		if c.srgb {
+			// The colors are encoded only when rendering to sRGB textures.
+			// The screen framebuffer is not sRGB-capable.
+			gl.Enable(gl.FRAMEBUFFER_SRGB)
+		}
 		return nil
 	})
-	c.BlendFunc(CompositeModeSourceOver)
+	c.blendFunc(driver.CompositeModeSourceOver)
+	c.disableScissorImpl()
+	c.scissorEnabled = false
+	c.colorMaskImpl(true, true, true, true)
+	c.lastColorMask = [4]bool{true, true, true, true}
 	_ = c.runOnContextThread(func() error {
-		f := int32(0)
-		gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &f)
-		c.screenFramebuffer = Framebuffer(f)
+		// With LEQUAL, a later drawing with the same depth is drawn over an earlier one.
+		gl.DepthFunc(gl.LEQUAL)
 		return nil
 	})
-	return nil
+	c.depthTestImpl(false)
+	c.depthTestEnabled = false
+	c.activeTextureImpl(0)
+}
+
+// NewScreenTexture creates an RGBA texture and a framebuffer to render to the texture.
+//
+// NewScreenTexture is used to render the screen to a texture that the host application uses.
+// NewScreenTexture can be called before Reset.
+func (c *Context) NewScreenTexture(width, height int) (Texture, Framebuffer, error) {
+	if err := c.initialize(); err != nil {
+		return 0, 0, err
+	}
+	t, err := c.newTexture(width, height, driver.PixelFormatRGBA8)
+	if err != nil {
+		return 0, 0, err
+	}
+	f, err := c.newFramebuffer(t)
+	if err != nil {
+		c.deleteTexture(t)
+		return 0, 0, err
+	}
+	return t, f, nil
 }
 
-func (c *Context) BlendFunc(mode CompositeMode) {
+// SetScreenFramebuffer sets the framebuffer regarded as the screen.
+//
+// By default, the framebuffer bound when Reset is called is regarded as the screen.
+// After SetScreenFramebuffer is called, Reset no longer changes the screen framebuffer.
+func (c *Context) SetScreenFramebuffer(f uint32) {
+	c.screenFramebuffer = Framebuffer(f)
+	c.screenFramebufferFixed = true
+}
+
+func (c *Context) blendFunc(mode driver.CompositeMode) {
 	_ = c.runOnContextThread(func() error {
 		if c.lastCompositeMode == mode {
 			return nil
@@ -129,11 +261,75 @@ func (c *Context) BlendFunc(mode CompositeMode) {
 		c.lastCompositeMode = mode
 		s, d := operations(mode)
 		gl.BlendFunc(uint32(s), uint32(d))
+		rgb, a := equations(mode)
+		gl.BlendEquationSeparate(uint32(rgb), uint32(a))
 		return nil
 	})
 }
 
-func (c *Context) NewTexture(width, height int) (Texture, error) {
+// textureFormat returns the internal format, the format and the type of the pixel format.
+func textureFormat(format driver.PixelFormat) (int32, uint32, uint32) {
+	switch format {
+	case driver.PixelFormatRGBA8:
+		return gl.RGBA, gl.RGBA, gl.UNSIGNED_BYTE
+	case driver.PixelFormatAlpha8:
+		return gl.ALPHA, gl.ALPHA, gl.UNSIGNED_BYTE
+	case driver.PixelFormatRGBA16F:
+		return gl.RGBA16F_ARB, gl.RGBA, gl.HALF_FLOAT_ARB
+	case driver.PixelFormatSRGBA8:
+		return gl.SRGB8_ALPHA8, gl.RGBA, gl.UNSIGNED_BYTE
+	default:
+		panic("not reached")
+	}
+}
+
+// compressedTextureFormat returns the internal format of the compressed pixel format.
+func compressedTextureFormat(format driver.PixelFormat) uint32 {
+	switch format {
+	case driver.PixelFormatETC2RGBA8:
+		return gl.COMPRESSED_RGBA8_ETC2_EAC
+	case driver.PixelFormatASTC4x4:
+		return gl.COMPRESSED_RGBA_ASTC_4x4_KHR
+	case driver.PixelFormatBC3:
+		return gl.COMPRESSED_RGBA_S3TC_DXT5_EXT
+	default:
+		panic("not reached")
+	}
+}
+
+func (c *Context) newTexture(width, height int, format driver.PixelFormat) (Texture, error) {
+	texture, err := c.genTexture()
+	if err != nil {
+		return 0, err
+	}
+	_ = c.runOnContextThread(func() error {
+		internal, f, t := textureFormat(format)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, internal, int32(width), int32(height), 0, f, t, nil)
+		return nil
+	})
+	return texture, nil
+}
+
+func (c *Context) newCompressedTexture(width, height int, format driver.PixelFormat, data []byte) (Texture, error) {
+	texture, err := c.genTexture()
+	if err != nil {
+		return 0, err
+	}
+	if err := c.runOnContextThread(func() error {
+		gl.CompressedTexImage2D(gl.TEXTURE_2D, 0, compressedTextureFormat(format), int32(width), int32(height), 0, int32(len(data)), gl.Ptr(data))
+		if e := gl.GetError(); e != gl.NO_ERROR {
+			return fmt.Errorf("opengl: glCompressedTexImage2D: %d", e)
+		}
+		return nil
+	}); err != nil {
+		c.deleteTexture(texture)
+		return 0, err
+	}
+	return texture, nil
+}
+
+// genTexture generates a texture and binds it with the default parameters.
+func (c *Context) genTexture() (Texture, error) {
 	var texture Texture
 	if err := c.runOnContextThread(func() error {
 		var t uint32
@@ -142,7 +338,8 @@ func (c *Context) NewTexture(width, height int) (Texture, error) {
 		if t <= 0 {
 			return errors.New("opengl: creating texture failed")
 		}
-		gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+		// The rows of alpha-only pixels are not aligned to 4 bytes.
+		gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
 		texture = Texture(t)
 		return nil
 	}); err != nil {
@@ -154,7 +351,6 @@ func (c *Context) NewTexture(width, height int) (Texture, error) {
 		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
 		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
 		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
 		return nil
 	})
 	return texture, nil
@@ -167,7 +363,7 @@ func (c *Context) bindFramebufferImpl(f Framebuffer) {
 	})
 }
 
-func (c *Context) FramebufferPixels(f Framebuffer, width, height int) ([]byte, error) {
+func (c *Context) framebufferPixels(f Framebuffer, format driver.PixelFormat, width, height int) ([]byte, error) {
 	var pixels []byte
 	if err := c.runOnContextThread(func() error {
 		gl.Flush()
@@ -177,8 +373,18 @@ func (c *Context) FramebufferPixels(f Framebuffer, width, height int) ([]byte, e
 	}
 	c.bindFramebuffer(f)
 	if err := c.runOnContextThread(func() error {
-		pixels = make([]byte, 4*width*height)
-		gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+		switch format {
+		case driver.PixelFormatRGBA8, driver.PixelFormatSRGBA8:
+			// The texels of an sRGB texture are read as they are without decoding.
+			pixels = make([]byte, 4*width*height)
+			gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+		case driver.PixelFormatRGBA16F:
+			fs := make([]float32, 4*width*height)
+			gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.FLOAT, gl.Ptr(fs))
+			pixels = float16Bytes(fs)
+		default:
+			panic("not reached")
+		}
 		if e := gl.GetError(); e != gl.NO_ERROR {
 			pixels = nil
 			return fmt.Errorf("opengl: glReadPixels: %d", e)
@@ -197,7 +403,14 @@ func (c *Context) bindTextureImpl(t Texture) {
 	})
 }
 
-func (c *Context) DeleteTexture(t Texture) {
+func (c *Context) activeTextureImpl(unit int) {
+	_ = c.runOnContextThread(func() error {
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(unit))
+		return nil
+	})
+}
+
+func (c *Context) deleteTexture(t Texture) {
 	_ = c.runOnContextThread(func() error {
 		tt := uint32(t)
 		if !gl.IsTexture(tt) {
@@ -211,7 +424,7 @@ func (c *Context) DeleteTexture(t Texture) {
 	})
 }
 
-func (c *Context) IsTexture(t Texture) bool {
+func (c *Context) isTexture(t Texture) bool {
 	r := false
 	_ = c.runOnContextThread(func() error {
 		r = gl.IsTexture(uint32(t))
@@ -220,9 +433,48 @@ func (c *Context) IsTexture(t Texture) bool {
 	return r
 }
 
-func (c *Context) TexSubImage2D(p []byte, x, y, width, height int) {
+func (c *Context) TexSubImage2D(p []byte, format driver.PixelFormat, x, y, width, height int) {
 	_ = c.runOnContextThread(func() error {
-		gl.TexSubImage2D(gl.TEXTURE_2D, 0, int32(x), int32(y), int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(p))
+		_, f, t := textureFormat(format)
+		gl.TexSubImage2D(gl.TEXTURE_2D, 0, int32(x), int32(y), int32(width), int32(height), f, t, gl.Ptr(p))
+		return nil
+	})
+}
+
+func (c *Context) IsPixelBufferAvailable() bool {
+	// Pixel buffer objects are a core feature as of OpenGL 2.1.
+	return true
+}
+
+func (c *Context) TexSubImages2DFromPixelBuffer(p []byte, uploads []driver.TextureUpload) {
+	_ = c.runOnContextThread(func() error {
+		if c.pixelBuffer == 0 {
+			gl.GenBuffers(1, &c.pixelBuffer)
+		}
+		gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, c.pixelBuffer)
+		// Specifying the data with a new store orphans the previous one, so that uploading doesn't wait for
+		// the previous uploads to finish.
+		gl.BufferData(gl.PIXEL_UNPACK_BUFFER, len(p), gl.Ptr(p), gl.STREAM_DRAW)
+		return nil
+	})
+	for _, u := range uploads {
+		c.BindTexture(u.Texture)
+		u := u
+		_ = c.runOnContextThread(func() error {
+			_, f, t := textureFormat(u.Format)
+			gl.TexSubImage2D(gl.TEXTURE_2D, 0, int32(u.X), int32(u.Y), int32(u.Width), int32(u.Height), f, t, gl.PtrOffset(u.Offset))
+			return nil
+		})
+	}
+	_ = c.runOnContextThread(func() error {
+		gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, 0)
+		return nil
+	})
+}
+
+func (c *Context) CopyTexSubImage2D(x, y, sx, sy, width, height int) {
+	_ = c.runOnContextThread(func() error {
+		gl.CopyTexSubImage2D(gl.TEXTURE_2D, 0, int32(x), int32(y), int32(sx), int32(sy), int32(width), int32(height))
 		return nil
 	})
 }
@@ -231,7 +483,7 @@ func (c *Context) BindScreenFramebuffer() {
 	c.bindFramebuffer(c.screenFramebuffer)
 }
 
-func (c *Context) NewFramebuffer(texture Texture) (Framebuffer, error) {
+func (c *Context) newFramebuffer(texture Texture) (Framebuffer, error) {
 	var framebuffer Framebuffer
 	var f uint32
 	if err := c.runOnContextThread(func() error {
@@ -265,6 +517,65 @@ func (c *Context) NewFramebuffer(texture Texture) (Framebuffer, error) {
 	return framebuffer, nil
 }
 
+func (c *Context) newDepthRenderbuffer(width, height int) (Renderbuffer, error) {
+	var renderbuffer Renderbuffer
+	if err := c.runOnContextThread(func() error {
+		var r uint32
+		gl.GenRenderbuffers(1, &r)
+		if r <= 0 {
+			return errors.New("opengl: creating renderbuffer failed: gl.IsRenderbuffer returns false")
+		}
+		gl.BindRenderbuffer(gl.RENDERBUFFER, r)
+		gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT16, int32(width), int32(height))
+		gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+		renderbuffer = Renderbuffer(r)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	return renderbuffer, nil
+}
+
+func (c *Context) deleteRenderbuffer(r Renderbuffer) {
+	_ = c.runOnContextThread(func() error {
+		rr := uint32(r)
+		if !gl.IsRenderbuffer(rr) {
+			return nil
+		}
+		gl.DeleteRenderbuffers(1, &rr)
+		return nil
+	})
+}
+
+func (c *Context) attachDepthRenderbuffer(f Framebuffer, r Renderbuffer) error {
+	c.bindFramebuffer(f)
+	return c.runOnContextThread(func() error {
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, uint32(r))
+		if s := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); s != gl.FRAMEBUFFER_COMPLETE {
+			return fmt.Errorf("opengl: attaching depth renderbuffer failed: %v", s)
+		}
+		return nil
+	})
+}
+
+func (c *Context) depthTestImpl(enabled bool) {
+	_ = c.runOnContextThread(func() error {
+		if enabled {
+			gl.Enable(gl.DEPTH_TEST)
+		} else {
+			gl.Disable(gl.DEPTH_TEST)
+		}
+		return nil
+	})
+}
+
+func (c *Context) clearDepthImpl() {
+	_ = c.runOnContextThread(func() error {
+		gl.Clear(gl.DEPTH_BUFFER_BIT)
+		return nil
+	})
+}
+
 func (c *Context) setViewportImpl(width, height int) {
 	_ = c.runOnContextThread(func() error {
 		gl.Viewport(0, 0, int32(width), int32(height))
@@ -272,7 +583,29 @@ func (c *Context) setViewportImpl(width, height int) {
 	})
 }
 
-func (c *Context) DeleteFramebuffer(f Framebuffer) {
+func (c *Context) setScissorImpl(x, y, width, height int) {
+	_ = c.runOnContextThread(func() error {
+		gl.Enable(gl.SCISSOR_TEST)
+		gl.Scissor(int32(x), int32(y), int32(width), int32(height))
+		return nil
+	})
+}
+
+func (c *Context) disableScissorImpl() {
+	_ = c.runOnContextThread(func() error {
+		gl.Disable(gl.SCISSOR_TEST)
+		return nil
+	})
+}
+
+func (c *Context) colorMaskImpl(r, g, b, a bool) {
+	_ = c.runOnContextThread(func() error {
+		gl.ColorMask(r, g, b, a)
+		return nil
+	})
+}
+
+func (c *Context) deleteFramebuffer(f Framebuffer) {
 	_ = c.runOnContextThread(func() error {
 		ff := uint32(f)
 		if !gl.IsFramebuffer(ff) {
@@ -288,7 +621,7 @@ func (c *Context) DeleteFramebuffer(f Framebuffer) {
 	})
 }
 
-func (c *Context) NewShader(shaderType ShaderType, source string) (Shader, error) {
+func (c *Context) newShader(shaderType ShaderType, source string) (Shader, error) {
 	var shader Shader
 	if err := c.runOnContextThread(func() error {
 		s := gl.CreateShader(uint32(shaderType))
@@ -319,14 +652,14 @@ func (c *Context) NewShader(shaderType ShaderType, source string) (Shader, error
 	return shader, nil
 }
 
-func (c *Context) DeleteShader(s Shader) {
+func (c *Context) deleteShader(s Shader) {
 	_ = c.runOnContextThread(func() error {
 		gl.DeleteShader(uint32(s))
 		return nil
 	})
 }
 
-func (c *Context) NewProgram(shaders []Shader) (Program, error) {
+func (c *Context) newProgram(shaders []Shader) (Program, error) {
 	var program Program
 	if err := c.runOnContextThread(func() error {
 		p := gl.CreateProgram()
@@ -351,14 +684,14 @@ func (c *Context) NewProgram(shaders []Shader) (Program, error) {
 	return program, nil
 }
 
-func (c *Context) UseProgram(p Program) {
+func (c *Context) useProgram(p Program) {
 	_ = c.runOnContextThread(func() error {
 		gl.UseProgram(uint32(p))
 		return nil
 	})
 }
 
-func (c *Context) DeleteProgram(p Program) {
+func (c *Context) deleteProgram(p Program) {
 	_ = c.runOnContextThread(func() error {
 		if !gl.IsProgram(uint32(p)) {
 			return nil
@@ -376,7 +709,7 @@ func (c *Context) getUniformLocationImpl(p Program, location string) uniformLoca
 	return uniform
 }
 
-func (c *Context) UniformInt(p Program, location string, v int) {
+func (c *Context) uniformInt(p Program, location string, v int) {
 	_ = c.runOnContextThread(func() error {
 		l := int32(c.locationCache.GetUniformLocation(c, p, location))
 		gl.Uniform1i(l, int32(v))
@@ -384,7 +717,7 @@ func (c *Context) UniformInt(p Program, location string, v int) {
 	})
 }
 
-func (c *Context) UniformFloat(p Program, location string, v float32) {
+func (c *Context) uniformFloat(p Program, location string, v float32) {
 	_ = c.runOnContextThread(func() error {
 		l := int32(c.locationCache.GetUniformLocation(c, p, location))
 		gl.Uniform1f(l, v)
@@ -392,7 +725,7 @@ func (c *Context) UniformFloat(p Program, location string, v float32) {
 	})
 }
 
-func (c *Context) UniformFloats(p Program, location string, v []float32) {
+func (c *Context) uniformFloats(p Program, location string, v []float32) {
 	_ = c.runOnContextThread(func() error {
 		l := int32(c.locationCache.GetUniformLocation(c, p, location))
 		switch len(v) {
@@ -417,7 +750,7 @@ func (c *Context) getAttribLocationImpl(p Program, location string) attribLocati
 	return attrib
 }
 
-func (c *Context) VertexAttribPointer(p Program, location string, size int, dataType DataType, stride int, offset int) {
+func (c *Context) vertexAttribPointer(p Program, location string, size int, dataType DataType, stride int, offset int) {
 	_ = c.runOnContextThread(func() error {
 		l := c.locationCache.GetAttribLocation(c, p, location)
 		gl.VertexAttribPointer(uint32(l), int32(size), uint32(dataType), false, int32(stride), gl.PtrOffset(offset))
@@ -425,7 +758,7 @@ func (c *Context) VertexAttribPointer(p Program, location string, size int, data
 	})
 }
 
-func (c *Context) EnableVertexAttribArray(p Program, location string) {
+func (c *Context) enableVertexAttribArray(p Program, location string) {
 	_ = c.runOnContextThread(func() error {
 		l := c.locationCache.GetAttribLocation(c, p, location)
 		gl.EnableVertexAttribArray(uint32(l))
@@ -433,7 +766,7 @@ func (c *Context) EnableVertexAttribArray(p Program, location string) {
 	})
 }
 
-func (c *Context) DisableVertexAttribArray(p Program, location string) {
+func (c *Context) disableVertexAttribArray(p Program, location string) {
 	_ = c.runOnContextThread(func() error {
 		l := c.locationCache.GetAttribLocation(c, p, location)
 		gl.DisableVertexAttribArray(uint32(l))
@@ -441,7 +774,7 @@ func (c *Context) DisableVertexAttribArray(p Program, location string) {
 	})
 }
 
-func (c *Context) NewArrayBuffer(size int) Buffer {
+func (c *Context) newArrayBuffer(size int) Buffer {
 	var buffer Buffer
 	_ = c.runOnContextThread(func() error {
 		var b uint32
@@ -454,7 +787,7 @@ func (c *Context) NewArrayBuffer(size int) Buffer {
 	return buffer
 }
 
-func (c *Context) NewElementArrayBuffer(indices []uint16) Buffer {
+func (c *Context) newElementArrayBuffer(indices []uint16) Buffer {
 	var buffer Buffer
 	_ = c.runOnContextThread(func() error {
 		var b uint32
@@ -467,21 +800,28 @@ func (c *Context) NewElementArrayBuffer(indices []uint16) Buffer {
 	return buffer
 }
 
-func (c *Context) BindElementArrayBuffer(b Buffer) {
+func (c *Context) bindArrayBuffer(b Buffer) {
+	_ = c.runOnContextThread(func() error {
+		gl.BindBuffer(gl.ARRAY_BUFFER, uint32(b))
+		return nil
+	})
+}
+
+func (c *Context) bindElementArrayBuffer(b Buffer) {
 	_ = c.runOnContextThread(func() error {
 		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, uint32(b))
 		return nil
 	})
 }
 
-func (c *Context) BufferSubData(bufferType BufferType, data []float32) {
+func (c *Context) bufferSubData(bufferType BufferType, data []float32) {
 	_ = c.runOnContextThread(func() error {
 		gl.BufferSubData(uint32(bufferType), 0, len(data)*4, gl.Ptr(data))
 		return nil
 	})
 }
 
-func (c *Context) DeleteBuffer(b Buffer) {
+func (c *Context) deleteBuffer(b Buffer) {
 	_ = c.runOnContextThread(func() error {
 		bb := uint32(b)
 		gl.DeleteBuffers(1, &bb)
@@ -489,13 +829,58 @@ func (c *Context) DeleteBuffer(b Buffer) {
 	})
 }
 
-func (c *Context) DrawElements(mode Mode, len int, offsetInBytes int) {
+func (c *Context) drawElements(mode Mode, len int, offsetInBytes int) {
 	_ = c.runOnContextThread(func() error {
 		gl.DrawElements(uint32(mode), int32(len), gl.UNSIGNED_SHORT, gl.PtrOffset(offsetInBytes))
 		return nil
 	})
 }
 
+func (c *Context) IsNPOTTextureAvailable() bool {
+	// Non-power-of-two textures are a core feature as of OpenGL 2.0.
+	return true
+}
+
+func (c *Context) IsPixelFormatAvailable(format driver.PixelFormat) bool {
+	switch format {
+	case driver.PixelFormatRGBA16F:
+		return c.floatTexture
+	case driver.PixelFormatSRGBA8:
+		return c.srgb
+	case driver.PixelFormatETC2RGBA8:
+		return c.etc2
+	case driver.PixelFormatASTC4x4:
+		return c.astc
+	case driver.PixelFormatBC3:
+		return c.bc3
+	}
+	return true
+}
+
+func (c *Context) IsInstancingAvailable() bool {
+	return c.instancing
+}
+
+func (c *Context) IsStandardDerivativesAvailable() bool {
+	// dFdx and dFdy are core features as of GLSL 1.10.
+	return true
+}
+
+func (c *Context) vertexAttribDivisor(p Program, location string, divisor int) {
+	_ = c.runOnContextThread(func() error {
+		l := c.locationCache.GetAttribLocation(c, p, location)
+		gl.VertexAttribDivisorARB(uint32(l), uint32(divisor))
+		return nil
+	})
+}
+
+func (c *Context) drawElementsInstanced(mode Mode, len int, offsetInBytes int, instanceCount int) {
+	_ = c.runOnContextThread(func() error {
+		gl.DrawElementsInstancedARB(uint32(mode), int32(len), gl.UNSIGNED_SHORT, gl.PtrOffset(offsetInBytes), int32(instanceCount))
+		return nil
+	})
+}
+
 func (c *Context) maxTextureSizeImpl() int {
 	size := 0
 	_ = c.runOnContextThread(func() error {
@@ -17,40 +17,83 @@
 package opengl
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 
-	"github.com/gopherjs/gopherjs/js"
-	"github.com/gopherjs/webgl"
-
+	"github.com/hajimehoshi/ebiten/internal/driver"
+	"github.com/hajimehoshi/ebiten/internal/js"
 	"github.com/hajimehoshi/ebiten/internal/web"
 )
 
-// Note that `type Texture *js.Object` doesn't work.
-// There is no way to get the internal object in that case.
+// Note that js.Value is not comparable.
+// Use pointers to js.Value so that the objects can be compared.
 
 type (
-	Texture         interface{}
-	Framebuffer     interface{}
-	Shader          interface{}
-	Program         interface{}
-	Buffer          interface{}
-	uniformLocation interface{}
+	Texture         *js.Value
+	Framebuffer     *js.Value
+	Renderbuffer    *js.Value
+	Shader          *js.Value
+	Program         *js.Value
+	Buffer          *js.Value
+	uniformLocation js.Value
 )
 
 type attribLocation int
 
 type programID int
 
-var InvalidTexture = Texture((*js.Object)(nil))
+var InvalidTexture Texture
 
 func getProgramID(p Program) programID {
-	return programID(p.(*js.Object).Get("__ebiten_programId").Int())
-}
+	return programID((*p).Get("__ebiten_programId").Int())
+}
+
+var (
+	glAlpha               int
+	glBlend               int
+	glClampToEdge         int
+	glColorAttachment0    int
+	glCompileStatus       int
+	glCompressedASTC4x4   int
+	glCompressedBC3       int
+	glCompressedETC2RGBA8 int
+	glDepthAttachment     int
+	glDepthBufferBit      int
+	glDepthComponent16    int
+	glDepthTest           int
+	glFramebuffer         int
+	glFramebufferBinding  int
+	glFramebufferComplete int
+	glFloat               int
+	glHalfFloat           int
+	glLEqual              int
+	glLinkStatus          int
+	glMaxTextureSize      int
+	glNearest             int
+	glNoError             int
+	glPixelUnpackBuffer   int
+	glRenderbuffer        int
+	glRGBA                int
+	glRGBA16F             int
+	glSRGB8Alpha8         int
+	glScissorTest         int
+	glStreamDraw          int
+	glTexture0            int
+	glTexture2D           int
+	glTextureMagFilter    int
+	glTextureMinFilter    int
+	glTextureWrapS        int
+	glTextureWrapT        int
+	glUnpackAlignment     int
+	glUnsignedByte        int
+	glUnsignedShort       int
+)
 
 func init() {
 	// Accessing the prototype is rquired on Safari.
-	c := js.Global.Get("WebGLRenderingContext").Get("prototype")
+	c := js.Global().Get("WebGLRenderingContext").Get("prototype")
 	VertexShader = ShaderType(c.Get("VERTEX_SHADER").Int())
 	FragmentShader = ShaderType(c.Get("FRAGMENT_SHADER").Int())
 	ArrayBuffer = BufferType(c.Get("ARRAY_BUFFER").Int())
@@ -68,12 +111,73 @@ func init() {
 	dstAlpha = operation(c.Get("DST_ALPHA").Int())
 	oneMinusSrcAlpha = operation(c.Get("ONE_MINUS_SRC_ALPHA").Int())
 	oneMinusDstAlpha = operation(c.Get("ONE_MINUS_DST_ALPHA").Int())
+	dstColor = operation(c.Get("DST_COLOR").Int())
+	oneMinusSrcColor = operation(c.Get("ONE_MINUS_SRC_COLOR").Int())
+
+	funcAdd = equation(c.Get("FUNC_ADD").Int())
+	funcReverseSubtract = equation(c.Get("FUNC_REVERSE_SUBTRACT").Int())
+	// MIN and MAX are defined only in WebGL 2, and the extension EXT_blend_minmax is required in WebGL 1.
+	// The values of MIN_EXT and MAX_EXT are same as MIN and MAX.
+	blendMin = 0x8007
+	blendMax = 0x8008
+
+	glAlpha = c.Get("ALPHA").Int()
+	glBlend = c.Get("BLEND").Int()
+	glClampToEdge = c.Get("CLAMP_TO_EDGE").Int()
+	glColorAttachment0 = c.Get("COLOR_ATTACHMENT0").Int()
+	glCompileStatus = c.Get("COMPILE_STATUS").Int()
+	// The compressed formats are defined only in the extensions.
+	glCompressedASTC4x4 = 0x93b0
+	glCompressedBC3 = 0x83f3
+	glCompressedETC2RGBA8 = 0x9278
+	glDepthAttachment = c.Get("DEPTH_ATTACHMENT").Int()
+	glDepthBufferBit = c.Get("DEPTH_BUFFER_BIT").Int()
+	glDepthComponent16 = c.Get("DEPTH_COMPONENT16").Int()
+	glDepthTest = c.Get("DEPTH_TEST").Int()
+	glFramebuffer = c.Get("FRAMEBUFFER").Int()
+	glFramebufferBinding = c.Get("FRAMEBUFFER_BINDING").Int()
+	glFramebufferComplete = c.Get("FRAMEBUFFER_COMPLETE").Int()
+	glFloat = c.Get("FLOAT").Int()
+	// HALF_FLOAT and RGBA16F are defined only in WebGL 2.
+	glHalfFloat = 0x140b
+	glLEqual = c.Get("LEQUAL").Int()
+	glLinkStatus = c.Get("LINK_STATUS").Int()
+	glMaxTextureSize = c.Get("MAX_TEXTURE_SIZE").Int()
+	glNearest = c.Get("NEAREST").Int()
+	glNoError = c.Get("NO_ERROR").Int()
+	glRenderbuffer = c.Get("RENDERBUFFER").Int()
+	glRGBA = c.Get("RGBA").Int()
+	glRGBA16F = 0x881a
+	// SRGB8_ALPHA8 is defined only in WebGL 2.
+	glSRGB8Alpha8 = 0x8c43
+	glScissorTest = c.Get("SCISSOR_TEST").Int()
+	glTexture0 = c.Get("TEXTURE0").Int()
+	glTexture2D = c.Get("TEXTURE_2D").Int()
+	glTextureMagFilter = c.Get("TEXTURE_MAG_FILTER").Int()
+	glTextureMinFilter = c.Get("TEXTURE_MIN_FILTER").Int()
+	glTextureWrapS = c.Get("TEXTURE_WRAP_S").Int()
+	glTextureWrapT = c.Get("TEXTURE_WRAP_T").Int()
+	glUnpackAlignment = c.Get("UNPACK_ALIGNMENT").Int()
+	glStreamDraw = c.Get("STREAM_DRAW").Int()
+	// PIXEL_UNPACK_BUFFER is defined only in WebGL 2.
+	glPixelUnpackBuffer = 0x88EC
+	glUnsignedByte = c.Get("UNSIGNED_BYTE").Int()
+	glUnsignedShort = c.Get("UNSIGNED_SHORT").Int()
 }
 
 type context struct {
-	gl            *webgl.Context
-	loseContext   *js.Object
+	gl            js.Value
+	loseContext   js.Value
 	lastProgramID programID
+	webgl2        bool
+	floatTexture  bool
+	etc2          bool
+	astc          bool
+	bc3           bool
+	derivatives   bool
+
+	// pixelBuffer is the pixel unpack buffer for TexSubImages2DFromPixelBuffer. nil means not created yet.
+	pixelBuffer Buffer
 }
 
 func Init() error {
@@ -82,25 +186,38 @@ func Init() error {
 	}
 
 	// TODO: Define id?
-	canvas := js.Global.Get("document").Call("querySelector", "canvas")
-	gl, err := webgl.NewContext(canvas, &webgl.ContextAttributes{
-		Alpha:              true,
-		PremultipliedAlpha: true,
-	})
-	if err != nil {
-		return err
+	canvas := js.Global().Get("document").Call("querySelector", "canvas")
+	attr := map[string]interface{}{
+		"alpha":              true,
+		"premultipliedAlpha": true,
 	}
+
 	c := &Context{}
+
+	// Try WebGL 2 first to use instanced drawing. WebGL 2 can still use the shaders for WebGL 1.
+	gl := canvas.Call("getContext", "webgl2", attr)
+	if gl.Truthy() {
+		c.webgl2 = true
+	} else {
+		gl = canvas.Call("getContext", "webgl", attr)
+		if !gl.Truthy() {
+			gl = canvas.Call("getContext", "experimental-webgl", attr)
+			if !gl.Truthy() {
+				return errors.New("opengl: getContext failed")
+			}
+		}
+	}
 	c.gl = gl
 
 	// Getting an extension might fail after the context is lost, so
 	// it is required to get the extension here.
-	c.loseContext = gl.GetExtension("WEBGL_lose_context")
-	if c.loseContext != nil {
+	c.loseContext = gl.Call("getExtension", "WEBGL_lose_context")
+	if c.loseContext.Truthy() {
 		// This testing function name is temporary.
-		js.Global.Set("_ebiten_loseContextForTesting", func() {
+		js.Global().Set("_ebiten_loseContextForTesting", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 			c.loseContext.Call("loseContext")
-		})
+			return nil
+		}))
 	}
 	theContext = c
 	return nil
@@ -108,121 +225,338 @@ func Init() error {
 
 func (c *Context) Reset() error {
 	c.locationCache = newLocationCache()
+	c.pixelBuffer = nil
+	gl := c.gl
+	if !c.webgl2 {
+		// Enable the extension for CompositeModeMin and CompositeModeMax.
+		// This must be done again after the context is restored.
+		gl.Call("getExtension", "EXT_blend_minmax")
+		// Getting the extension enables dFdx and dFdy, which are a core feature of WebGL 2.
+		c.derivatives = gl.Call("getExtension", "OES_standard_derivatives").Truthy()
+	} else {
+		// The extension is required to render to 16-bit floating point textures.
+		c.floatTexture = gl.Call("getExtension", "EXT_color_buffer_float").Truthy()
+		c.derivatives = true
+	}
+	// Getting the extensions enables the compressed formats.
+	c.etc2 = gl.Call("getExtension", "WEBGL_compressed_texture_etc").Truthy()
+	c.astc = gl.Call("getExtension", "WEBGL_compressed_texture_astc").Truthy()
+	c.bc3 = gl.Call("getExtension", "WEBGL_compressed_texture_s3tc").Truthy()
+	c.ResetStateCache()
+	f := gl.Call("getParameter", glFramebufferBinding)
+	c.screenFramebuffer = &f
+	return nil
+}
+
+// ResetStateCache discards the cached state and sets the state that Ebiten assumes again.
+func (c *Context) ResetStateCache() {
 	c.lastTexture = nil
 	c.lastFramebuffer = nil
 	c.lastViewportWidth = 0
 	c.lastViewportHeight = 0
-	c.lastCompositeMode = CompositeModeUnknown
-	gl := c.gl
-	gl.Enable(gl.BLEND)
-	c.BlendFunc(CompositeModeSourceOver)
-	f := gl.GetParameter(gl.FRAMEBUFFER_BINDING)
-	c.screenFramebuffer = f
-	return nil
-}
-
-func (c *Context) BlendFunc(mode CompositeMode) {
+	c.lastCompositeMode = driver.CompositeModeUnknown
+	gl := c.gl
+	gl.Call("enable", glBlend)
+	c.blendFunc(driver.CompositeModeSourceOver)
+	c.disableScissorImpl()
+	c.scissorEnabled = false
+	c.colorMaskImpl(true, true, true, true)
+	c.lastColorMask = [4]bool{true, true, true, true}
+	// With LEQUAL, a later drawing with the same depth is drawn over an earlier one.
+	gl.Call("depthFunc", glLEqual)
+	c.depthTestImpl(false)
+	c.depthTestEnabled = false
+	c.activeTextureImpl(0)
+}
+
+func (c *Context) blendFunc(mode driver.CompositeMode) {
 	if c.lastCompositeMode == mode {
 		return
 	}
 	c.lastCompositeMode = mode
 	s, d := operations(mode)
 	gl := c.gl
-	gl.BlendFunc(int(s), int(d))
+	gl.Call("blendFunc", int(s), int(d))
+	rgb, a := equations(mode)
+	gl.Call("blendEquationSeparate", int(rgb), int(a))
+}
+
+// textureFormat returns the internal format, the format and the type of the pixel format.
+func textureFormat(format driver.PixelFormat) (int, int, int) {
+	switch format {
+	case driver.PixelFormatRGBA8:
+		return glRGBA, glRGBA, glUnsignedByte
+	case driver.PixelFormatAlpha8:
+		return glAlpha, glAlpha, glUnsignedByte
+	case driver.PixelFormatRGBA16F:
+		return glRGBA16F, glRGBA, glHalfFloat
+	case driver.PixelFormatSRGBA8:
+		return glSRGB8Alpha8, glRGBA, glUnsignedByte
+	default:
+		panic("not reached")
+	}
 }
 
-func (c *Context) NewTexture(width, height int) (Texture, error) {
-	gl := c.gl
-	t := gl.CreateTexture()
-	if t == nil {
-		return nil, errors.New("opengl: glGenTexture failed")
+// compressedTextureFormat returns the internal format of the compressed pixel format.
+func compressedTextureFormat(format driver.PixelFormat) int {
+	switch format {
+	case driver.PixelFormatETC2RGBA8:
+		return glCompressedETC2RGBA8
+	case driver.PixelFormatASTC4x4:
+		return glCompressedASTC4x4
+	case driver.PixelFormatBC3:
+		return glCompressedBC3
+	default:
+		panic("not reached")
 	}
-	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
-	c.BindTexture(t)
+}
 
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+func (c *Context) newTexture(width, height int, format driver.PixelFormat) (Texture, error) {
+	t, err := c.genTexture()
+	if err != nil {
+		return nil, err
+	}
 
 	// void texImage2D(GLenum target, GLint level, GLenum internalformat,
 	//     GLsizei width, GLsizei height, GLint border, GLenum format,
 	//     GLenum type, ArrayBufferView? pixels);
-	gl.Call("texImage2D", gl.TEXTURE_2D, 0, gl.RGBA, width, height, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	internal, f, typ := textureFormat(format)
+	c.gl.Call("texImage2D", glTexture2D, 0, internal, width, height, 0, f, typ, nil)
 
 	return t, nil
 }
 
+func (c *Context) newCompressedTexture(width, height int, format driver.PixelFormat, data []byte) (Texture, error) {
+	t, err := c.genTexture()
+	if err != nil {
+		return nil, err
+	}
+
+	// void compressedTexImage2D(GLenum target, GLint level, GLenum internalformat,
+	//     GLsizei width, GLsizei height, GLint border, ArrayBufferView data);
+	gl := c.gl
+	gl.Call("compressedTexImage2D", glTexture2D, 0, compressedTextureFormat(format), width, height, 0, js.Uint8ArrayOf(data))
+	if e := gl.Call("getError").Int(); e != glNoError {
+		c.deleteTexture(t)
+		return nil, fmt.Errorf("opengl: compressedTexImage2D: %d", e)
+	}
+	return t, nil
+}
+
+// genTexture generates a texture and binds it with the default parameters.
+func (c *Context) genTexture() (Texture, error) {
+	gl := c.gl
+	t := gl.Call("createTexture")
+	if !t.Truthy() {
+		return nil, errors.New("opengl: glGenTexture failed")
+	}
+	// The rows of alpha-only pixels are not aligned to 4 bytes.
+	gl.Call("pixelStorei", glUnpackAlignment, 1)
+	c.BindTexture(&t)
+
+	gl.Call("texParameteri", glTexture2D, glTextureMagFilter, glNearest)
+	gl.Call("texParameteri", glTexture2D, glTextureMinFilter, glNearest)
+	gl.Call("texParameteri", glTexture2D, glTextureWrapS, glClampToEdge)
+	gl.Call("texParameteri", glTexture2D, glTextureWrapT, glClampToEdge)
+	return &t, nil
+}
+
 func (c *Context) bindFramebufferImpl(f Framebuffer) {
 	gl := c.gl
-	gl.BindFramebuffer(gl.FRAMEBUFFER, f.(*js.Object))
+	gl.Call("bindFramebuffer", glFramebuffer, *f)
 }
 
-func (c *Context) FramebufferPixels(f Framebuffer, width, height int) ([]byte, error) {
+func (c *Context) framebufferPixels(f Framebuffer, format driver.PixelFormat, width, height int) ([]byte, error) {
 	gl := c.gl
 
 	c.bindFramebuffer(f)
 
-	pixels := js.Global.Get("Uint8Array").New(4 * width * height)
-	gl.ReadPixels(0, 0, width, height, gl.RGBA, gl.UNSIGNED_BYTE, pixels)
-	if e := gl.GetError(); e != gl.NO_ERROR {
-		return nil, errors.New(fmt.Sprintf("opengl: error: %d", e))
+	switch format {
+	case driver.PixelFormatRGBA8, driver.PixelFormatSRGBA8:
+		// The texels of an sRGB texture are read as they are without decoding.
+		pixels := js.Global().Get("Uint8Array").New(4 * width * height)
+		gl.Call("readPixels", 0, 0, width, height, glRGBA, glUnsignedByte, pixels)
+		if e := gl.Call("getError").Int(); e != glNoError {
+			return nil, errors.New(fmt.Sprintf("opengl: error: %d", e))
+		}
+		p := make([]byte, 4*width*height)
+		js.CopyBytesToGo(p, pixels)
+		return p, nil
+	case driver.PixelFormatRGBA16F:
+		pixels := js.Global().Get("Float32Array").New(4 * width * height)
+		gl.Call("readPixels", 0, 0, width, height, glRGBA, glFloat, pixels)
+		if e := gl.Call("getError").Int(); e != glNoError {
+			return nil, errors.New(fmt.Sprintf("opengl: error: %d", e))
+		}
+		bs := make([]byte, 16*width*height)
+		js.CopyBytesToGo(bs, js.Global().Get("Uint8Array").New(pixels.Get("buffer")))
+		fs := make([]float32, 4*width*height)
+		for i := range fs {
+			fs[i] = math.Float32frombits(binary.LittleEndian.Uint32(bs[4*i:]))
+		}
+		return float16Bytes(fs), nil
+	default:
+		panic("not reached")
 	}
-	return pixels.Interface().([]byte), nil
 }
 
 func (c *Context) bindTextureImpl(t Texture) {
 	gl := c.gl
-	gl.BindTexture(gl.TEXTURE_2D, t.(*js.Object))
+	gl.Call("bindTexture", glTexture2D, *t)
+}
+
+func (c *Context) activeTextureImpl(unit int) {
+	gl := c.gl
+	gl.Call("activeTexture", glTexture0+unit)
 }
 
-func (c *Context) DeleteTexture(t Texture) {
+func (c *Context) deleteTexture(t Texture) {
 	gl := c.gl
-	if !gl.IsTexture(t.(*js.Object)) {
+	if !gl.Call("isTexture", *t).Bool() {
 		return
 	}
 	if c.lastTexture == t {
 		c.lastTexture = nil
 	}
-	gl.DeleteTexture(t.(*js.Object))
+	gl.Call("deleteTexture", *t)
 }
 
-func (c *Context) IsTexture(t Texture) bool {
+func (c *Context) isTexture(t Texture) bool {
 	gl := c.gl
-	b := gl.IsTexture(t.(*js.Object))
-	return b
+	return gl.Call("isTexture", *t).Bool()
 }
 
-func (c *Context) TexSubImage2D(p []byte, x, y, width, height int) {
+func (c *Context) TexSubImage2D(p []byte, format driver.PixelFormat, x, y, width, height int) {
 	gl := c.gl
+	_, f, t := textureFormat(format)
+	var arr js.Value
+	if format == driver.PixelFormatRGBA16F {
+		// The type HALF_FLOAT requires Uint16Array.
+		vs := make([]uint16, len(p)/2)
+		for i := range vs {
+			vs[i] = binary.LittleEndian.Uint16(p[2*i:])
+		}
+		arr = js.Uint16ArrayOf(vs)
+	} else {
+		arr = js.Uint8ArrayOf(p)
+	}
 	// void texSubImage2D(GLenum target, GLint level, GLint xoffset, GLint yoffset,
 	//                    GLsizei width, GLsizei height,
 	//                    GLenum format, GLenum type, ArrayBufferView? pixels);
-	gl.Call("texSubImage2D", gl.TEXTURE_2D, 0, x, y, width, height, gl.RGBA, gl.UNSIGNED_BYTE, p)
+	gl.Call("texSubImage2D", glTexture2D, 0, x, y, width, height, f, t, arr)
 }
 
-func (c *Context) NewFramebuffer(t Texture) (Framebuffer, error) {
+func (c *Context) IsPixelBufferAvailable() bool {
+	return c.webgl2
+}
+
+func (c *Context) TexSubImages2DFromPixelBuffer(p []byte, uploads []driver.TextureUpload) {
 	gl := c.gl
-	f := gl.CreateFramebuffer()
-	c.bindFramebuffer(f)
+	if c.pixelBuffer == nil {
+		b := gl.Call("createBuffer")
+		c.pixelBuffer = &b
+	}
+	gl.Call("bindBuffer", glPixelUnpackBuffer, *c.pixelBuffer)
+	gl.Call("bufferData", glPixelUnpackBuffer, js.Uint8ArrayOf(p), glStreamDraw)
+	for _, u := range uploads {
+		c.BindTexture(u.Texture)
+		_, f, t := textureFormat(u.Format)
+		// void texSubImage2D(GLenum target, GLint level, GLint xoffset, GLint yoffset,
+		//                    GLsizei width, GLsizei height,
+		//                    GLenum format, GLenum type, GLintptr offset);
+		gl.Call("texSubImage2D", glTexture2D, 0, u.X, u.Y, u.Width, u.Height, f, t, u.Offset)
+	}
+	gl.Call("bindBuffer", glPixelUnpackBuffer, nil)
+}
+
+func (c *Context) CopyTexSubImage2D(x, y, sx, sy, width, height int) {
+	gl := c.gl
+	// void copyTexSubImage2D(GLenum target, GLint level, GLint xoffset, GLint yoffset,
+	//                        GLint x, GLint y, GLsizei width, GLsizei height);
+	gl.Call("copyTexSubImage2D", glTexture2D, 0, x, y, sx, sy, width, height)
+}
+
+func (c *Context) newFramebuffer(t Texture) (Framebuffer, error) {
+	gl := c.gl
+	f := gl.Call("createFramebuffer")
+	c.bindFramebuffer(&f)
 
-	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, t.(*js.Object), 0)
-	s := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
-	if s != gl.FRAMEBUFFER_COMPLETE {
+	gl.Call("framebufferTexture2D", glFramebuffer, glColorAttachment0, glTexture2D, *t, 0)
+	if s := gl.Call("checkFramebufferStatus", glFramebuffer).Int(); s != glFramebufferComplete {
 		return nil, errors.New(fmt.Sprintf("opengl: creating framebuffer failed: %d", s))
 	}
 
-	return f, nil
+	return &f, nil
+}
+
+func (c *Context) newDepthRenderbuffer(width, height int) (Renderbuffer, error) {
+	gl := c.gl
+	r := gl.Call("createRenderbuffer")
+	if !r.Truthy() {
+		return nil, errors.New("opengl: createRenderbuffer failed")
+	}
+	gl.Call("bindRenderbuffer", glRenderbuffer, r)
+	gl.Call("renderbufferStorage", glRenderbuffer, glDepthComponent16, width, height)
+	gl.Call("bindRenderbuffer", glRenderbuffer, nil)
+	return &r, nil
+}
+
+func (c *Context) deleteRenderbuffer(r Renderbuffer) {
+	gl := c.gl
+	if !gl.Call("isRenderbuffer", *r).Bool() {
+		return
+	}
+	gl.Call("deleteRenderbuffer", *r)
+}
+
+func (c *Context) attachDepthRenderbuffer(f Framebuffer, r Renderbuffer) error {
+	gl := c.gl
+	c.bindFramebuffer(f)
+	gl.Call("framebufferRenderbuffer", glFramebuffer, glDepthAttachment, glRenderbuffer, *r)
+	if s := gl.Call("checkFramebufferStatus", glFramebuffer).Int(); s != glFramebufferComplete {
+		return errors.New(fmt.Sprintf("opengl: attaching depth renderbuffer failed: %d", s))
+	}
+	return nil
+}
+
+func (c *Context) depthTestImpl(enabled bool) {
+	gl := c.gl
+	if enabled {
+		gl.Call("enable", glDepthTest)
+	} else {
+		gl.Call("disable", glDepthTest)
+	}
+}
+
+func (c *Context) clearDepthImpl() {
+	gl := c.gl
+	gl.Call("clear", glDepthBufferBit)
 }
 
 func (c *Context) setViewportImpl(width, height int) {
 	gl := c.gl
-	gl.Viewport(0, 0, width, height)
+	gl.Call("viewport", 0, 0, width, height)
+}
+
+func (c *Context) setScissorImpl(x, y, width, height int) {
+	gl := c.gl
+	gl.Call("enable", glScissorTest)
+	gl.Call("scissor", x, y, width, height)
+}
+
+func (c *Context) disableScissorImpl() {
+	gl := c.gl
+	gl.Call("disable", glScissorTest)
 }
 
-func (c *Context) DeleteFramebuffer(f Framebuffer) {
+func (c *Context) colorMaskImpl(r, g, b, a bool) {
 	gl := c.gl
-	if !gl.IsFramebuffer(f.(*js.Object)) {
+	gl.Call("colorMask", r, g, b, a)
+}
+
+func (c *Context) deleteFramebuffer(f Framebuffer) {
+	gl := c.gl
+	if !gl.Call("isFramebuffer", *f).Bool() {
 		return
 	}
 	// If a framebuffer to be deleted is bound, a newly bound framebuffer
@@ -233,90 +567,90 @@ func (c *Context) DeleteFramebuffer(f Framebuffer) {
 		c.lastViewportWidth = 0
 		c.lastViewportHeight = 0
 	}
-	gl.DeleteFramebuffer(f.(*js.Object))
+	gl.Call("deleteFramebuffer", *f)
 }
 
-func (c *Context) NewShader(shaderType ShaderType, source string) (Shader, error) {
+func (c *Context) newShader(shaderType ShaderType, source string) (Shader, error) {
 	gl := c.gl
-	s := gl.CreateShader(int(shaderType))
-	if s == nil {
+	s := gl.Call("createShader", int(shaderType))
+	if !s.Truthy() {
 		return nil, fmt.Errorf("opengl: glCreateShader failed: shader type: %d", shaderType)
 	}
 
-	gl.ShaderSource(s, source)
-	gl.CompileShader(s)
+	gl.Call("shaderSource", s, source)
+	gl.Call("compileShader", s)
 
-	if !gl.GetShaderParameterb(s, gl.COMPILE_STATUS) {
-		log := gl.GetShaderInfoLog(s)
+	if !gl.Call("getShaderParameter", s, glCompileStatus).Bool() {
+		log := gl.Call("getShaderInfoLog", s).String()
 		return nil, fmt.Errorf("opengl: shader compile failed: %s", log)
 	}
-	return s, nil
+	return &s, nil
 }
 
-func (c *Context) DeleteShader(s Shader) {
+func (c *Context) deleteShader(s Shader) {
 	gl := c.gl
-	gl.DeleteShader(s.(*js.Object))
+	gl.Call("deleteShader", *s)
 }
 
-func (c *Context) NewProgram(shaders []Shader) (Program, error) {
+func (c *Context) newProgram(shaders []Shader) (Program, error) {
 	gl := c.gl
-	p := gl.CreateProgram()
-	if p == nil {
+	p := gl.Call("createProgram")
+	if !p.Truthy() {
 		return nil, errors.New("opengl: glCreateProgram failed")
 	}
-	p.Set("__ebiten_programId", c.lastProgramID)
+	p.Set("__ebiten_programId", int(c.lastProgramID))
 	c.lastProgramID++
 
 	for _, shader := range shaders {
-		gl.AttachShader(p, shader.(*js.Object))
+		gl.Call("attachShader", p, *shader)
 	}
-	gl.LinkProgram(p)
-	if !gl.GetProgramParameterb(p, gl.LINK_STATUS) {
+	gl.Call("linkProgram", p)
+	if !gl.Call("getProgramParameter", p, glLinkStatus).Bool() {
 		return nil, errors.New("opengl: program error")
 	}
-	return p, nil
+	return &p, nil
 }
 
-func (c *Context) UseProgram(p Program) {
+func (c *Context) useProgram(p Program) {
 	gl := c.gl
-	gl.UseProgram(p.(*js.Object))
+	gl.Call("useProgram", *p)
 }
 
-func (c *Context) DeleteProgram(p Program) {
+func (c *Context) deleteProgram(p Program) {
 	gl := c.gl
-	if !gl.IsProgram(p.(*js.Object)) {
+	if !gl.Call("isProgram", *p).Bool() {
 		return
 	}
-	gl.DeleteProgram(p.(*js.Object))
+	gl.Call("deleteProgram", *p)
 }
 
 func (c *Context) getUniformLocationImpl(p Program, location string) uniformLocation {
 	gl := c.gl
-	return gl.GetUniformLocation(p.(*js.Object), location)
+	return uniformLocation(gl.Call("getUniformLocation", *p, location))
 }
 
-func (c *Context) UniformInt(p Program, location string, v int) {
+func (c *Context) uniformInt(p Program, location string, v int) {
 	gl := c.gl
 	l := c.locationCache.GetUniformLocation(c, p, location)
-	gl.Uniform1i(l.(*js.Object), v)
+	gl.Call("uniform1i", js.Value(l), v)
 }
 
-func (c *Context) UniformFloat(p Program, location string, v float32) {
+func (c *Context) uniformFloat(p Program, location string, v float32) {
 	gl := c.gl
 	l := c.locationCache.GetUniformLocation(c, p, location)
-	gl.Uniform1f(l.(*js.Object), v)
+	gl.Call("uniform1f", js.Value(l), v)
 }
 
-func (c *Context) UniformFloats(p Program, location string, v []float32) {
+func (c *Context) uniformFloats(p Program, location string, v []float32) {
 	gl := c.gl
 	l := c.locationCache.GetUniformLocation(c, p, location)
 	switch len(v) {
 	case 2:
-		gl.Call("uniform2fv", l.(*js.Object), v)
+		gl.Call("uniform2fv", js.Value(l), js.Float32ArrayOf(v))
 	case 4:
-		gl.Call("uniform4fv", l.(*js.Object), v)
+		gl.Call("uniform4fv", js.Value(l), js.Float32ArrayOf(v))
 	case 16:
-		gl.UniformMatrix4fv(l.(*js.Object), false, v)
+		gl.Call("uniformMatrix4fv", js.Value(l), false, js.Float32ArrayOf(v))
 	default:
 		panic("not reached")
 	}
@@ -324,80 +658,138 @@ func (c *Context) UniformFloats(p Program, location string, v []float32) {
 
 func (c *Context) getAttribLocationImpl(p Program, location string) attribLocation {
 	gl := c.gl
-	return attribLocation(gl.GetAttribLocation(p.(*js.Object), location))
+	return attribLocation(gl.Call("getAttribLocation", *p, location).Int())
 }
 
-func (c *Context) VertexAttribPointer(p Program, location string, size int, dataType DataType, stride int, offset int) {
+func (c *Context) vertexAttribPointer(p Program, location string, size int, dataType DataType, stride int, offset int) {
 	gl := c.gl
 	l := c.locationCache.GetAttribLocation(c, p, location)
-	gl.VertexAttribPointer(int(l), size, int(dataType), false, stride, offset)
+	gl.Call("vertexAttribPointer", int(l), size, int(dataType), false, stride, offset)
 }
 
-func (c *Context) EnableVertexAttribArray(p Program, location string) {
+func (c *Context) enableVertexAttribArray(p Program, location string) {
 	gl := c.gl
 	l := c.locationCache.GetAttribLocation(c, p, location)
-	gl.EnableVertexAttribArray(int(l))
+	gl.Call("enableVertexAttribArray", int(l))
 }
 
-func (c *Context) DisableVertexAttribArray(p Program, location string) {
+func (c *Context) disableVertexAttribArray(p Program, location string) {
 	gl := c.gl
 	l := c.locationCache.GetAttribLocation(c, p, location)
-	gl.DisableVertexAttribArray(int(l))
+	gl.Call("disableVertexAttribArray", int(l))
+}
+
+func (c *Context) newArrayBuffer(size int) Buffer {
+	gl := c.gl
+	b := gl.Call("createBuffer")
+	gl.Call("bindBuffer", int(ArrayBuffer), b)
+	gl.Call("bufferData", int(ArrayBuffer), size, int(DynamicDraw))
+	return &b
+}
+
+func (c *Context) newElementArrayBuffer(indices []uint16) Buffer {
+	gl := c.gl
+	b := gl.Call("createBuffer")
+	gl.Call("bindBuffer", int(ElementArrayBuffer), b)
+	gl.Call("bufferData", int(ElementArrayBuffer), js.Uint16ArrayOf(indices), int(StaticDraw))
+	return &b
 }
 
-func (c *Context) NewArrayBuffer(size int) Buffer {
+func (c *Context) bindArrayBuffer(b Buffer) {
 	gl := c.gl
-	b := gl.CreateBuffer()
-	gl.BindBuffer(int(ArrayBuffer), b)
-	gl.BufferData(int(ArrayBuffer), size, int(DynamicDraw))
-	return b
+	gl.Call("bindBuffer", int(ArrayBuffer), *b)
 }
 
-func (c *Context) NewElementArrayBuffer(indices []uint16) Buffer {
+func (c *Context) bindElementArrayBuffer(b Buffer) {
 	gl := c.gl
-	b := gl.CreateBuffer()
-	gl.BindBuffer(int(ElementArrayBuffer), b)
-	gl.BufferData(int(ElementArrayBuffer), indices, int(StaticDraw))
-	return b
+	gl.Call("bindBuffer", int(ElementArrayBuffer), *b)
 }
 
-func (c *Context) BindElementArrayBuffer(b Buffer) {
+func (c *Context) bufferSubData(bufferType BufferType, data []float32) {
 	gl := c.gl
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, b.(*js.Object))
+	gl.Call("bufferSubData", int(bufferType), 0, js.Float32ArrayOf(data))
 }
 
-func (c *Context) BufferSubData(bufferType BufferType, data []float32) {
+func (c *Context) deleteBuffer(b Buffer) {
 	gl := c.gl
-	gl.BufferSubData(int(bufferType), 0, data)
+	gl.Call("deleteBuffer", *b)
+}
+
+func (c *Context) drawElements(mode Mode, len int, offsetInBytes int) {
+	gl := c.gl
+	gl.Call("drawElements", int(mode), len, glUnsignedShort, offsetInBytes)
+}
+
+func (c *Context) IsNPOTTextureAvailable() bool {
+	// WebGL 1 has only limited support of non-power-of-two textures.
+	return c.webgl2
 }
 
-func (c *Context) DeleteBuffer(b Buffer) {
+func (c *Context) IsPixelFormatAvailable(format driver.PixelFormat) bool {
+	switch format {
+	case driver.PixelFormatRGBA16F:
+		return c.floatTexture
+	case driver.PixelFormatSRGBA8:
+		// WebGL 1 requires the extension EXT_sRGB, which has a different internal format. Not supported.
+		return c.webgl2
+	case driver.PixelFormatETC2RGBA8:
+		return c.etc2
+	case driver.PixelFormatASTC4x4:
+		return c.astc
+	case driver.PixelFormatBC3:
+		return c.bc3
+	}
+	return true
+}
+
+func (c *Context) IsInstancingAvailable() bool {
+	return c.webgl2
+}
+
+func (c *Context) IsStandardDerivativesAvailable() bool {
+	return c.derivatives
+}
+
+func (c *Context) IsUploadWorkerAvailable() bool {
+	// WebGL can't share textures between contexts.
+	return false
+}
+
+func (c *Context) TexSubImage2DOnWorker(texture driver.Texture, texels func() []byte, format driver.PixelFormat, x, y, width, height int) {
+	panic("opengl: TexSubImage2DOnWorker is not available")
+}
+
+func (c *Context) WaitUpload(texture driver.Texture) {
+}
+
+func (c *Context) vertexAttribDivisor(p Program, location string, divisor int) {
 	gl := c.gl
-	gl.DeleteBuffer(b.(*js.Object))
+	l := c.locationCache.GetAttribLocation(c, p, location)
+	gl.Call("vertexAttribDivisor", int(l), divisor)
 }
 
-func (c *Context) DrawElements(mode Mode, len int, offsetInBytes int) {
+func (c *Context) drawElementsInstanced(mode Mode, len int, offsetInBytes int, instanceCount int) {
 	gl := c.gl
-	gl.DrawElements(int(mode), len, gl.UNSIGNED_SHORT, offsetInBytes)
+	gl.Call("drawElementsInstanced", int(mode), len, glUnsignedShort, offsetInBytes, instanceCount)
 }
 
 func (c *Context) maxTextureSizeImpl() int {
 	gl := c.gl
-	return gl.GetParameter(gl.MAX_TEXTURE_SIZE).Int()
+	return gl.Call("getParameter", glMaxTextureSize).Int()
 }
 
 func (c *Context) Flush() {
 	gl := c.gl
-	gl.Flush()
+	gl.Call("flush")
 }
 
 func (c *Context) IsContextLost() bool {
 	gl := c.gl
-	return gl.IsContextLost()
+	return gl.Call("isContextLost").Bool()
 }
 
 func (c *Context) RestoreContext() {
-	if c.loseContext != nil {
+	if c.loseContext.Truthy() {
 		c.loseContext.Call("restoreContext")
 	}
 }
@@ -20,17 +20,21 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"unsafe"
 
 	mgl "golang.org/x/mobile/gl"
+
+	"github.com/hajimehoshi/ebiten/internal/driver"
 )
 
 type (
-	Texture     mgl.Texture
-	Framebuffer mgl.Framebuffer
-	Shader      mgl.Shader
-	Program     mgl.Program
-	Buffer      mgl.Buffer
+	Texture      mgl.Texture
+	Framebuffer  mgl.Framebuffer
+	Renderbuffer mgl.Renderbuffer
+	Shader       mgl.Shader
+	Program      mgl.Program
+	Buffer       mgl.Buffer
 )
 
 var InvalidTexture Texture
@@ -69,11 +73,24 @@ func init() {
 	dstAlpha = mgl.DST_ALPHA
 	oneMinusSrcAlpha = mgl.ONE_MINUS_SRC_ALPHA
 	oneMinusDstAlpha = mgl.ONE_MINUS_DST_ALPHA
+	dstColor = mgl.DST_COLOR
+	oneMinusSrcColor = mgl.ONE_MINUS_SRC_COLOR
+
+	funcAdd = mgl.FUNC_ADD
+	funcReverseSubtract = mgl.FUNC_REVERSE_SUBTRACT
+	// GL_MIN and GL_MAX are available with the extension EXT_blend_minmax on OpenGL ES 2.0.
+	// The values of GL_MIN_EXT and GL_MAX_EXT are same as GL_MIN and GL_MAX.
+	blendMin = 0x8007
+	blendMax = 0x8008
 }
 
 type context struct {
 	gl     mgl.Context
 	worker mgl.Worker
+	gles3  bool
+
+	// derivatives indicates whether GL_OES_standard_derivatives is available.
+	derivatives bool
 }
 
 func Init() {
@@ -110,20 +127,43 @@ loop:
 
 func (c *Context) Reset() error {
 	c.locationCache = newLocationCache()
+	c.ResetStateCache()
+	// The version string is like "OpenGL ES 3.0 ...".
+	c.gles3 = strings.HasPrefix(c.gl.GetString(mgl.VERSION), "OpenGL ES 3")
+	c.derivatives = false
+	for _, e := range strings.Split(c.gl.GetString(mgl.EXTENSIONS), " ") {
+		if e == "GL_OES_standard_derivatives" {
+			c.derivatives = true
+			break
+		}
+	}
+	f := c.gl.GetInteger(mgl.FRAMEBUFFER_BINDING)
+	c.screenFramebuffer = Framebuffer(mgl.Framebuffer{uint32(f)})
+	// TODO: Need to update screenFramebufferWidth/Height?
+	return nil
+}
+
+// ResetStateCache discards the cached state and sets the state that Ebiten assumes again.
+func (c *Context) ResetStateCache() {
 	c.lastTexture = invalidTexture
 	c.lastFramebuffer = invalidFramebuffer
 	c.lastViewportWidth = 0
 	c.lastViewportHeight = 0
-	c.lastCompositeMode = CompositeModeUnknown
+	c.lastCompositeMode = driver.CompositeModeUnknown
 	c.gl.Enable(mgl.BLEND)
-	c.BlendFunc(CompositeModeSourceOver)
-	f := c.gl.GetInteger(mgl.FRAMEBUFFER_BINDING)
-	c.screenFramebuffer = Framebuffer(mgl.Framebuffer{uint32(f)})
-	// TODO: Need to update screenFramebufferWidth/Height?
-	return nil
+	c.blendFunc(driver.CompositeModeSourceOver)
+	c.disableScissorImpl()
+	c.scissorEnabled = false
+	c.colorMaskImpl(true, true, true, true)
+	c.lastColorMask = [4]bool{true, true, true, true}
+	// With LEQUAL, a later drawing with the same depth is drawn over an earlier one.
+	c.gl.DepthFunc(mgl.LEQUAL)
+	c.depthTestImpl(false)
+	c.depthTestEnabled = false
+	c.activeTextureImpl(0)
 }
 
-func (c *Context) BlendFunc(mode CompositeMode) {
+func (c *Context) blendFunc(mode driver.CompositeMode) {
 	gl := c.gl
 	if c.lastCompositeMode == mode {
 		return
@@ -131,32 +171,54 @@ func (c *Context) BlendFunc(mode CompositeMode) {
 	c.lastCompositeMode = mode
 	s, d := operations(mode)
 	gl.BlendFunc(mgl.Enum(s), mgl.Enum(d))
+	rgb, a := equations(mode)
+	gl.BlendEquationSeparate(mgl.Enum(rgb), mgl.Enum(a))
 }
 
-func (c *Context) NewTexture(width, height int) (Texture, error) {
+// textureFormat returns the format of the pixel format.
+func textureFormat(format driver.PixelFormat) mgl.Enum {
+	switch format {
+	case driver.PixelFormatRGBA8:
+		return mgl.RGBA
+	case driver.PixelFormatAlpha8:
+		return mgl.ALPHA
+	default:
+		panic("not reached")
+	}
+}
+
+func (c *Context) newTexture(width, height int, format driver.PixelFormat) (Texture, error) {
 	gl := c.gl
 	t := gl.CreateTexture()
 	if t.Value <= 0 {
 		return Texture{}, errors.New("opengl: creating texture failed")
 	}
-	gl.PixelStorei(mgl.UNPACK_ALIGNMENT, 4)
+	// The rows of alpha-only pixels are not aligned to 4 bytes.
+	gl.PixelStorei(mgl.UNPACK_ALIGNMENT, 1)
 	c.BindTexture(Texture(t))
 
 	gl.TexParameteri(mgl.TEXTURE_2D, mgl.TEXTURE_MAG_FILTER, mgl.NEAREST)
 	gl.TexParameteri(mgl.TEXTURE_2D, mgl.TEXTURE_MIN_FILTER, mgl.NEAREST)
 	gl.TexParameteri(mgl.TEXTURE_2D, mgl.TEXTURE_WRAP_S, mgl.CLAMP_TO_EDGE)
 	gl.TexParameteri(mgl.TEXTURE_2D, mgl.TEXTURE_WRAP_T, mgl.CLAMP_TO_EDGE)
-	gl.TexImage2D(mgl.TEXTURE_2D, 0, width, height, mgl.RGBA, mgl.UNSIGNED_BYTE, nil)
+	gl.TexImage2D(mgl.TEXTURE_2D, 0, width, height, textureFormat(format), mgl.UNSIGNED_BYTE, nil)
 
 	return Texture(t), nil
 }
 
+func (c *Context) newCompressedTexture(width, height int, format driver.PixelFormat, data []byte) (Texture, error) {
+	panic("opengl: compressed textures are not available")
+}
+
 func (c *Context) bindFramebufferImpl(f Framebuffer) {
 	gl := c.gl
 	gl.BindFramebuffer(mgl.FRAMEBUFFER, mgl.Framebuffer(f))
 }
 
-func (c *Context) FramebufferPixels(f Framebuffer, width, height int) ([]byte, error) {
+func (c *Context) framebufferPixels(f Framebuffer, format driver.PixelFormat, width, height int) ([]byte, error) {
+	if format != driver.PixelFormatRGBA8 {
+		panic("not reached")
+	}
 	gl := c.gl
 	gl.Flush()
 
@@ -175,7 +237,12 @@ func (c *Context) bindTextureImpl(t Texture) {
 	gl.BindTexture(mgl.TEXTURE_2D, mgl.Texture(t))
 }
 
-func (c *Context) DeleteTexture(t Texture) {
+func (c *Context) activeTextureImpl(unit int) {
+	gl := c.gl
+	gl.ActiveTexture(mgl.TEXTURE0 + mgl.Enum(unit))
+}
+
+func (c *Context) deleteTexture(t Texture) {
 	gl := c.gl
 	if !gl.IsTexture(mgl.Texture(t)) {
 		return
@@ -186,17 +253,32 @@ func (c *Context) DeleteTexture(t Texture) {
 	gl.DeleteTexture(mgl.Texture(t))
 }
 
-func (c *Context) IsTexture(t Texture) bool {
+func (c *Context) isTexture(t Texture) bool {
 	gl := c.gl
 	return gl.IsTexture(mgl.Texture(t))
 }
 
-func (c *Context) TexSubImage2D(p []byte, x, y, width, height int) {
+func (c *Context) TexSubImage2D(p []byte, format driver.PixelFormat, x, y, width, height int) {
 	gl := c.gl
-	gl.TexSubImage2D(mgl.TEXTURE_2D, 0, x, y, width, height, mgl.RGBA, mgl.UNSIGNED_BYTE, p)
+	gl.TexSubImage2D(mgl.TEXTURE_2D, 0, x, y, width, height, textureFormat(format), mgl.UNSIGNED_BYTE, p)
 }
 
-func (c *Context) NewFramebuffer(texture Texture) (Framebuffer, error) {
+func (c *Context) IsPixelBufferAvailable() bool {
+	// golang.org/x/mobile/gl's TexSubImage2D always takes a byte slice and can't specify an offset
+	// in the bound pixel unpack buffer.
+	return false
+}
+
+func (c *Context) TexSubImages2DFromPixelBuffer(p []byte, uploads []driver.TextureUpload) {
+	panic("opengl: TexSubImages2DFromPixelBuffer is not available")
+}
+
+func (c *Context) CopyTexSubImage2D(x, y, sx, sy, width, height int) {
+	gl := c.gl
+	gl.CopyTexSubImage2D(mgl.TEXTURE_2D, 0, x, y, sx, sy, width, height)
+}
+
+func (c *Context) newFramebuffer(texture Texture) (Framebuffer, error) {
 	gl := c.gl
 	f := gl.CreateFramebuffer()
 	if f.Value <= 0 {
@@ -218,12 +300,72 @@ func (c *Context) NewFramebuffer(texture Texture) (Framebuffer, error) {
 	return Framebuffer(f), nil
 }
 
+func (c *Context) newDepthRenderbuffer(width, height int) (Renderbuffer, error) {
+	gl := c.gl
+	r := gl.CreateRenderbuffer()
+	if r.Value <= 0 {
+		return Renderbuffer{}, errors.New("opengl: creating renderbuffer failed: gl.IsRenderbuffer returns false")
+	}
+	gl.BindRenderbuffer(mgl.RENDERBUFFER, r)
+	gl.RenderbufferStorage(mgl.RENDERBUFFER, mgl.DEPTH_COMPONENT16, width, height)
+	gl.BindRenderbuffer(mgl.RENDERBUFFER, mgl.Renderbuffer{})
+	return Renderbuffer(r), nil
+}
+
+func (c *Context) deleteRenderbuffer(r Renderbuffer) {
+	gl := c.gl
+	if !gl.IsRenderbuffer(mgl.Renderbuffer(r)) {
+		return
+	}
+	gl.DeleteRenderbuffer(mgl.Renderbuffer(r))
+}
+
+func (c *Context) attachDepthRenderbuffer(f Framebuffer, r Renderbuffer) error {
+	gl := c.gl
+	c.bindFramebuffer(f)
+	gl.FramebufferRenderbuffer(mgl.FRAMEBUFFER, mgl.DEPTH_ATTACHMENT, mgl.RENDERBUFFER, mgl.Renderbuffer(r))
+	if s := gl.CheckFramebufferStatus(mgl.FRAMEBUFFER); s != mgl.FRAMEBUFFER_COMPLETE {
+		return fmt.Errorf("opengl: attaching depth renderbuffer failed: %v", s)
+	}
+	return nil
+}
+
+func (c *Context) depthTestImpl(enabled bool) {
+	gl := c.gl
+	if enabled {
+		gl.Enable(mgl.DEPTH_TEST)
+	} else {
+		gl.Disable(mgl.DEPTH_TEST)
+	}
+}
+
+func (c *Context) clearDepthImpl() {
+	gl := c.gl
+	gl.Clear(mgl.DEPTH_BUFFER_BIT)
+}
+
 func (c *Context) setViewportImpl(width, height int) {
 	gl := c.gl
 	gl.Viewport(0, 0, width, height)
 }
 
-func (c *Context) DeleteFramebuffer(f Framebuffer) {
+func (c *Context) setScissorImpl(x, y, width, height int) {
+	gl := c.gl
+	gl.Enable(mgl.SCISSOR_TEST)
+	gl.Scissor(int32(x), int32(y), int32(width), int32(height))
+}
+
+func (c *Context) disableScissorImpl() {
+	gl := c.gl
+	gl.Disable(mgl.SCISSOR_TEST)
+}
+
+func (c *Context) colorMaskImpl(r, g, b, a bool) {
+	gl := c.gl
+	gl.ColorMask(r, g, b, a)
+}
+
+func (c *Context) deleteFramebuffer(f Framebuffer) {
 	gl := c.gl
 	if !gl.IsFramebuffer(mgl.Framebuffer(f)) {
 		return
@@ -239,7 +381,7 @@ func (c *Context) DeleteFramebuffer(f Framebuffer) {
 	gl.DeleteFramebuffer(mgl.Framebuffer(f))
 }
 
-func (c *Context) NewShader(shaderType ShaderType, source string) (Shader, error) {
+func (c *Context) newShader(shaderType ShaderType, source string) (Shader, error) {
 	gl := c.gl
 	s := gl.CreateShader(mgl.Enum(shaderType))
 	if s.Value == 0 {
@@ -256,12 +398,12 @@ func (c *Context) NewShader(shaderType ShaderType, source string) (Shader, error
 	return Shader(s), nil
 }
 
-func (c *Context) DeleteShader(s Shader) {
+func (c *Context) deleteShader(s Shader) {
 	gl := c.gl
 	gl.DeleteShader(mgl.Shader(s))
 }
 
-func (c *Context) NewProgram(shaders []Shader) (Program, error) {
+func (c *Context) newProgram(shaders []Shader) (Program, error) {
 	gl := c.gl
 	p := gl.CreateProgram()
 	if p.Value == 0 {
@@ -279,12 +421,12 @@ func (c *Context) NewProgram(shaders []Shader) (Program, error) {
 	return Program(p), nil
 }
 
-func (c *Context) UseProgram(p Program) {
+func (c *Context) useProgram(p Program) {
 	gl := c.gl
 	gl.UseProgram(mgl.Program(p))
 }
 
-func (c *Context) DeleteProgram(p Program) {
+func (c *Context) deleteProgram(p Program) {
 	gl := c.gl
 	if !gl.IsProgram(mgl.Program(p)) {
 		return
@@ -301,17 +443,17 @@ func (c *Context) getUniformLocationImpl(p Program, location string) uniformLoca
 	return u
 }
 
-func (c *Context) UniformInt(p Program, location string, v int) {
+func (c *Context) uniformInt(p Program, location string, v int) {
 	gl := c.gl
 	gl.Uniform1i(mgl.Uniform(c.locationCache.GetUniformLocation(c, p, location)), v)
 }
 
-func (c *Context) UniformFloat(p Program, location string, v float32) {
+func (c *Context) uniformFloat(p Program, location string, v float32) {
 	gl := c.gl
 	gl.Uniform1f(mgl.Uniform(c.locationCache.GetUniformLocation(c, p, location)), v)
 }
 
-func (c *Context) UniformFloats(p Program, location string, v []float32) {
+func (c *Context) uniformFloats(p Program, location string, v []float32) {
 	gl := c.gl
 	l := mgl.Uniform(c.locationCache.GetUniformLocation(c, p, location))
 	switch len(v) {
@@ -335,19 +477,19 @@ func (c *Context) getAttribLocationImpl(p Program, location string) attribLocati
 	return a
 }
 
-func (c *Context) VertexAttribPointer(p Program, location string, size int, dataType DataType, stride int, offset int) {
+func (c *Context) vertexAttribPointer(p Program, location string, size int, dataType DataType, stride int, offset int) {
 	gl := c.gl
 	l := c.locationCache.GetAttribLocation(c, p, location)
 	gl.VertexAttribPointer(mgl.Attrib(l), size, mgl.Enum(dataType), false, stride, offset)
 }
 
-func (c *Context) EnableVertexAttribArray(p Program, location string) {
+func (c *Context) enableVertexAttribArray(p Program, location string) {
 	gl := c.gl
 	l := c.locationCache.GetAttribLocation(c, p, location)
 	gl.EnableVertexAttribArray(mgl.Attrib(l))
 }
 
-func (c *Context) DisableVertexAttribArray(p Program, location string) {
+func (c *Context) disableVertexAttribArray(p Program, location string) {
 	gl := c.gl
 	l := c.locationCache.GetAttribLocation(c, p, location)
 	gl.DisableVertexAttribArray(mgl.Attrib(l))
@@ -364,7 +506,7 @@ func uint16ToBytes(v []uint16) []byte {
 	return b
 }
 
-func (c *Context) NewArrayBuffer(size int) Buffer {
+func (c *Context) newArrayBuffer(size int) Buffer {
 	gl := c.gl
 	b := gl.CreateBuffer()
 	gl.BindBuffer(mgl.Enum(ArrayBuffer), b)
@@ -372,7 +514,7 @@ func (c *Context) NewArrayBuffer(size int) Buffer {
 	return Buffer(b)
 }
 
-func (c *Context) NewElementArrayBuffer(indices []uint16) Buffer {
+func (c *Context) newElementArrayBuffer(indices []uint16) Buffer {
 	gl := c.gl
 	b := gl.CreateBuffer()
 	gl.BindBuffer(mgl.Enum(ElementArrayBuffer), b)
@@ -380,7 +522,12 @@ func (c *Context) NewElementArrayBuffer(indices []uint16) Buffer {
 	return Buffer(b)
 }
 
-func (c *Context) BindElementArrayBuffer(b Buffer) {
+func (c *Context) bindArrayBuffer(b Buffer) {
+	gl := c.gl
+	gl.BindBuffer(mgl.ARRAY_BUFFER, mgl.Buffer(b))
+}
+
+func (c *Context) bindElementArrayBuffer(b Buffer) {
 	gl := c.gl
 	gl.BindBuffer(mgl.ELEMENT_ARRAY_BUFFER, mgl.Buffer(b))
 }
@@ -396,21 +543,63 @@ func float32ToBytes(v []float32) []byte {
 	return b
 }
 
-func (c *Context) BufferSubData(bufferType BufferType, data []float32) {
+func (c *Context) bufferSubData(bufferType BufferType, data []float32) {
 	gl := c.gl
 	gl.BufferSubData(mgl.Enum(bufferType), 0, float32ToBytes(data))
 }
 
-func (c *Context) DeleteBuffer(b Buffer) {
+func (c *Context) deleteBuffer(b Buffer) {
 	gl := c.gl
 	gl.DeleteBuffer(mgl.Buffer(b))
 }
 
-func (c *Context) DrawElements(mode Mode, len int, offsetInBytes int) {
+func (c *Context) drawElements(mode Mode, len int, offsetInBytes int) {
 	gl := c.gl
 	gl.DrawElements(mgl.Enum(mode), len, mgl.UNSIGNED_SHORT, offsetInBytes)
 }
 
+func (c *Context) IsNPOTTextureAvailable() bool {
+	// OpenGL ES 2.0 has only limited support of non-power-of-two textures.
+	return c.gles3
+}
+
+func (c *Context) IsPixelFormatAvailable(format driver.PixelFormat) bool {
+	// golang.org/x/mobile/gl's TexImage2D can't specify a sized internal format like GL_RGBA16F or GL_SRGB8_ALPHA8.
+	// golang.org/x/mobile/gl doesn't have glCompressedTexImage2D either.
+	return format == driver.PixelFormatRGBA8 || format == driver.PixelFormatAlpha8
+}
+
+func (c *Context) IsInstancingAvailable() bool {
+	// golang.org/x/mobile/gl doesn't expose the instanced drawing functions of OpenGL ES 3
+	// (glVertexAttribDivisor and glDrawElementsInstanced) even via Context3.
+	return false
+}
+
+func (c *Context) IsStandardDerivativesAvailable() bool {
+	// The shaders are written in GLSL ES 1.0 even on OpenGL ES 3, so the extension is required.
+	return c.derivatives
+}
+
+func (c *Context) IsUploadWorkerAvailable() bool {
+	// gomobile doesn't provide a way to create a context sharing the textures.
+	return false
+}
+
+func (c *Context) TexSubImage2DOnWorker(texture driver.Texture, texels func() []byte, format driver.PixelFormat, x, y, width, height int) {
+	panic("opengl: TexSubImage2DOnWorker is not available")
+}
+
+func (c *Context) WaitUpload(texture driver.Texture) {
+}
+
+func (c *Context) vertexAttribDivisor(p Program, location string, divisor int) {
+	panic("opengl: VertexAttribDivisor is not available")
+}
+
+func (c *Context) drawElementsInstanced(mode Mode, len int, offsetInBytes int, instanceCount int) {
+	panic("opengl: DrawElementsInstanced is not available")
+}
+
 func (c *Context) maxTextureSizeImpl() int {
 	gl := c.gl
 	return gl.GetInteger(mgl.MAX_TEXTURE_SIZE)
@@ -0,0 +1,263 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opengl
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/driver"
+)
+
+// The functions in this file implement graphics.Driver.
+// The driver-independent handles and states are converted into OpenGL's ones here,
+// and then the platform-specific implementations are called.
+
+func toTexture(t driver.Texture) Texture {
+	if t == nil {
+		return InvalidTexture
+	}
+	return t.(Texture)
+}
+
+func toFramebuffer(f driver.Framebuffer) Framebuffer {
+	if f == nil {
+		var zero Framebuffer
+		return zero
+	}
+	return f.(Framebuffer)
+}
+
+func toRenderbuffer(r driver.Renderbuffer) Renderbuffer {
+	if r == nil {
+		var zero Renderbuffer
+		return zero
+	}
+	return r.(Renderbuffer)
+}
+
+func toProgram(p driver.Program) Program {
+	if p == nil {
+		var zero Program
+		return zero
+	}
+	return p.(Program)
+}
+
+func toBuffer(b driver.Buffer) Buffer {
+	if b == nil {
+		var zero Buffer
+		return zero
+	}
+	return b.(Buffer)
+}
+
+func toShaderType(t driver.ShaderType) ShaderType {
+	switch t {
+	case driver.VertexShader:
+		return VertexShader
+	case driver.FragmentShader:
+		return FragmentShader
+	default:
+		panic("not reached")
+	}
+}
+
+func toBufferType(t driver.BufferType) BufferType {
+	switch t {
+	case driver.ArrayBuffer:
+		return ArrayBuffer
+	case driver.ElementArrayBuffer:
+		return ElementArrayBuffer
+	default:
+		panic("not reached")
+	}
+}
+
+func toMode(m driver.Mode) Mode {
+	switch m {
+	case driver.Triangles:
+		return Triangles
+	case driver.Lines:
+		return Lines
+	default:
+		panic("not reached")
+	}
+}
+
+func toDataType(t driver.DataType) DataType {
+	switch t {
+	case driver.Short:
+		return Short
+	case driver.Float:
+		return Float
+	default:
+		panic("not reached")
+	}
+}
+
+func (c *Context) BlendFunc(mode driver.CompositeMode) {
+	c.blendFunc(mode)
+}
+
+func (c *Context) NewTexture(width, height int, format driver.PixelFormat) (driver.Texture, error) {
+	t, err := c.newTexture(width, height, format)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (c *Context) NewCompressedTexture(width, height int, format driver.PixelFormat, data []byte) (driver.Texture, error) {
+	t, err := c.newCompressedTexture(width, height, format, data)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (c *Context) DeleteTexture(t driver.Texture) {
+	c.deleteTexture(toTexture(t))
+}
+
+func (c *Context) IsTexture(t driver.Texture) bool {
+	return c.isTexture(toTexture(t))
+}
+
+func (c *Context) NewFramebuffer(texture driver.Texture) (driver.Framebuffer, error) {
+	f, err := c.newFramebuffer(toTexture(texture))
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (c *Context) DeleteFramebuffer(f driver.Framebuffer) {
+	c.deleteFramebuffer(toFramebuffer(f))
+}
+
+func (c *Context) NewDepthRenderbuffer(width, height int) (driver.Renderbuffer, error) {
+	r, err := c.newDepthRenderbuffer(width, height)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (c *Context) DeleteRenderbuffer(r driver.Renderbuffer) {
+	c.deleteRenderbuffer(toRenderbuffer(r))
+}
+
+func (c *Context) AttachDepthRenderbuffer(f driver.Framebuffer, r driver.Renderbuffer) error {
+	return c.attachDepthRenderbuffer(toFramebuffer(f), toRenderbuffer(r))
+}
+
+func (c *Context) FramebufferPixels(f driver.Framebuffer, format driver.PixelFormat, width, height int) ([]byte, error) {
+	return c.framebufferPixels(toFramebuffer(f), format, width, height)
+}
+
+func (c *Context) NewShader(shaderType driver.ShaderType, source string) (driver.Shader, error) {
+	s, err := c.newShader(toShaderType(shaderType), source)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *Context) DeleteShader(s driver.Shader) {
+	c.deleteShader(s.(Shader))
+}
+
+func (c *Context) NewProgram(shaders []driver.Shader, attributes []string) (driver.Program, error) {
+	ss := make([]Shader, len(shaders))
+	for i, s := range shaders {
+		ss[i] = s.(Shader)
+	}
+	p, err := c.newProgram(ss)
+	if err != nil {
+		return nil, err
+	}
+	c.locationCache.SetAttribNames(p, attributes)
+	return p, nil
+}
+
+func (c *Context) UseProgram(p driver.Program) {
+	c.useProgram(toProgram(p))
+}
+
+func (c *Context) DeleteProgram(p driver.Program) {
+	c.deleteProgram(toProgram(p))
+}
+
+func (c *Context) UniformInt(p driver.Program, location string, v int) {
+	c.uniformInt(toProgram(p), location, v)
+}
+
+func (c *Context) UniformFloat(p driver.Program, location string, v float32) {
+	c.uniformFloat(toProgram(p), location, v)
+}
+
+func (c *Context) UniformFloats(p driver.Program, location string, v []float32) {
+	c.uniformFloats(toProgram(p), location, v)
+}
+
+func (c *Context) NewArrayBuffer(size int) driver.Buffer {
+	return c.newArrayBuffer(size)
+}
+
+func (c *Context) NewElementArrayBuffer(indices []uint16) driver.Buffer {
+	return c.newElementArrayBuffer(indices)
+}
+
+func (c *Context) BindArrayBuffer(b driver.Buffer) {
+	c.bindArrayBuffer(toBuffer(b))
+}
+
+func (c *Context) BindElementArrayBuffer(b driver.Buffer) {
+	c.bindElementArrayBuffer(toBuffer(b))
+}
+
+func (c *Context) BufferSubData(bufferType driver.BufferType, data []float32) {
+	c.bufferSubData(toBufferType(bufferType), data)
+}
+
+func (c *Context) DeleteBuffer(b driver.Buffer) {
+	c.deleteBuffer(toBuffer(b))
+}
+
+func (c *Context) VertexAttribPointer(p driver.Program, index int, size int, dataType driver.DataType, stride int, offset int) {
+	pp := toProgram(p)
+	c.vertexAttribPointer(pp, c.locationCache.GetAttribName(pp, index), size, toDataType(dataType), stride, offset)
+}
+
+func (c *Context) EnableVertexAttribArray(p driver.Program, index int) {
+	pp := toProgram(p)
+	c.enableVertexAttribArray(pp, c.locationCache.GetAttribName(pp, index))
+}
+
+func (c *Context) DisableVertexAttribArray(p driver.Program, index int) {
+	pp := toProgram(p)
+	c.disableVertexAttribArray(pp, c.locationCache.GetAttribName(pp, index))
+}
+
+func (c *Context) VertexAttribDivisor(p driver.Program, index int, divisor int) {
+	pp := toProgram(p)
+	c.vertexAttribDivisor(pp, c.locationCache.GetAttribName(pp, index), divisor)
+}
+
+func (c *Context) DrawElements(mode driver.Mode, len int, offsetInBytes int) {
+	c.drawElements(toMode(mode), len, offsetInBytes)
+}
+
+func (c *Context) DrawElementsInstanced(mode driver.Mode, len int, offsetInBytes int, instanceCount int) {
+	c.drawElementsInstanced(toMode(mode), len, offsetInBytes, instanceCount)
+}
@@ -19,12 +19,17 @@ package opengl
 type locationCache struct {
 	uniformLocationCache map[programID]map[string]uniformLocation
 	attribLocationCache  map[programID]map[string]attribLocation
+
+	// attribNames is the names of the vertex attributes of each program.
+	// The attributes are referred by their indices from the graphics package.
+	attribNames map[programID][]string
 }
 
 func newLocationCache() *locationCache {
 	return &locationCache{
 		uniformLocationCache: map[programID]map[string]uniformLocation{},
 		attribLocationCache:  map[programID]map[string]attribLocation{},
+		attribNames:          map[programID][]string{},
 	}
 }
 
@@ -53,3 +58,11 @@ func (c *locationCache) GetAttribLocation(context *Context, p Program, location
 	}
 	return l
 }
+
+func (c *locationCache) SetAttribNames(p Program, names []string) {
+	c.attribNames[getProgramID(p)] = names
+}
+
+func (c *locationCache) GetAttribName(p Program, index int) string {
+	return c.attribNames[getProgramID(p)][index]
+}
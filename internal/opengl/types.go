@@ -14,66 +14,75 @@
 
 package opengl
 
+import (
+	"github.com/hajimehoshi/ebiten/internal/driver"
+)
+
 type (
 	ShaderType  int
 	BufferType  int
 	BufferUsage int
 	Mode        int
 	operation   int
+	equation    int
 )
 
-type CompositeMode int
-
-const (
-	CompositeModeSourceOver CompositeMode = iota // This value must be 0 (= initial value)
-	CompositeModeClear
-	CompositeModeCopy
-	CompositeModeDestination
-	CompositeModeDestinationOver
-	CompositeModeSourceIn
-	CompositeModeDestinationIn
-	CompositeModeSourceOut
-	CompositeModeDestinationOut
-	CompositeModeSourceAtop
-	CompositeModeDestinationAtop
-	CompositeModeXor
-	CompositeModeLighter
-	CompositeModeUnknown
-)
-
-func operations(mode CompositeMode) (src operation, dst operation) {
+func operations(mode driver.CompositeMode) (src operation, dst operation) {
 	switch mode {
-	case CompositeModeSourceOver:
+	case driver.CompositeModeSourceOver:
 		return one, oneMinusSrcAlpha
-	case CompositeModeClear:
+	case driver.CompositeModeClear:
 		return zero, zero
-	case CompositeModeCopy:
+	case driver.CompositeModeCopy:
 		return one, zero
-	case CompositeModeDestination:
+	case driver.CompositeModeDestination:
 		return zero, one
-	case CompositeModeDestinationOver:
+	case driver.CompositeModeDestinationOver:
 		return oneMinusDstAlpha, one
-	case CompositeModeSourceIn:
+	case driver.CompositeModeSourceIn:
 		return dstAlpha, zero
-	case CompositeModeDestinationIn:
+	case driver.CompositeModeDestinationIn:
 		return zero, srcAlpha
-	case CompositeModeSourceOut:
+	case driver.CompositeModeSourceOut:
 		return oneMinusDstAlpha, zero
-	case CompositeModeDestinationOut:
+	case driver.CompositeModeDestinationOut:
 		return zero, oneMinusSrcAlpha
-	case CompositeModeSourceAtop:
+	case driver.CompositeModeSourceAtop:
 		return dstAlpha, oneMinusSrcAlpha
-	case CompositeModeDestinationAtop:
+	case driver.CompositeModeDestinationAtop:
 		return oneMinusDstAlpha, srcAlpha
-	case CompositeModeXor:
+	case driver.CompositeModeXor:
 		return oneMinusDstAlpha, oneMinusSrcAlpha
-	case CompositeModeLighter:
+	case driver.CompositeModeLighter:
+		return one, one
+	case driver.CompositeModeMultiply:
+		return dstColor, oneMinusSrcAlpha
+	case driver.CompositeModeScreen:
+		return one, oneMinusSrcColor
+	case driver.CompositeModeMin, driver.CompositeModeMax:
+		// The factors are ignored for the min and max equations.
+		return one, one
+	case driver.CompositeModeSubtract:
 		return one, one
 	default:
 		panic("not reached")
 	}
 }
 
+// equations returns the blend equations for RGB and alpha of the given composite mode.
+func equations(mode driver.CompositeMode) (rgb equation, alpha equation) {
+	switch mode {
+	case driver.CompositeModeMin:
+		return blendMin, blendMin
+	case driver.CompositeModeMax:
+		return blendMax, blendMax
+	case driver.CompositeModeSubtract:
+		return funcReverseSubtract, funcAdd
+	default:
+		return funcAdd, funcAdd
+	}
+}
+
 type DataType int
 
 func (d DataType) SizeInBytes() int {
@@ -0,0 +1,150 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// +build darwin freebsd linux windows
+// +build !js
+// +build !android
+// +build !ios
+
+package opengl
+
+import (
+	"runtime"
+
+	"github.com/go-gl/gl/v2.1/gl"
+
+	"github.com/hajimehoshi/ebiten/internal/driver"
+)
+
+// uploadJob is a job to update a region of a texture on the upload worker.
+type uploadJob struct {
+	texture Texture
+	texels  func() []byte
+	format  driver.PixelFormat
+	x       int
+	y       int
+	width   int
+	height  int
+
+	// ready is the fence to wait for the commands on the main context before the job.
+	ready uintptr
+
+	// finished is the fence to wait for the upload on the main context. finished is set before done is closed.
+	finished uintptr
+
+	// done is closed when the upload commands are sent.
+	done chan struct{}
+}
+
+// SetUploadContext sets the context for the upload worker, which uploads textures on a dedicated thread in
+// parallel with the main thread. makeCurrent makes the context current on the calling thread.
+// The context must share the textures with the main context.
+//
+// The order of the commands across the contexts is kept with fence sync objects. If SetUploadContext is not called,
+// makeCurrent fails, or GL_ARB_sync is not available, the upload worker is not available.
+// SetUploadContext must be called after Init.
+func (c *Context) SetUploadContext(makeCurrent func() error) {
+	jobs := make(chan *uploadJob, 16)
+	ch := make(chan error)
+	go func() {
+		// A context is current on an OS thread.
+		runtime.LockOSThread()
+		if err := makeCurrent(); err != nil {
+			ch <- err
+			return
+		}
+		ch <- nil
+		for j := range jobs {
+			j.exec()
+		}
+	}()
+	if err := <-ch; err != nil {
+		return
+	}
+	c.uploadJobs = jobs
+	c.uploads = map[Texture]*uploadJob{}
+}
+
+// exec uploads the texels. exec must be called on the upload worker.
+func (j *uploadJob) exec() {
+	p := j.texels()
+	// Waiting for the fence blocks only the GPU command stream of this context.
+	gl.WaitSync(j.ready, 0, gl.TIMEOUT_IGNORED)
+	gl.DeleteSync(j.ready)
+	gl.BindTexture(gl.TEXTURE_2D, uint32(j.texture))
+	_, f, t := textureFormat(j.format)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, int32(j.x), int32(j.y), int32(j.width), int32(j.height), f, t, gl.Ptr(p))
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	j.finished = gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+	// A fence must be flushed before another context waits for it.
+	gl.Flush()
+	close(j.done)
+}
+
+func (c *Context) IsUploadWorkerAvailable() bool {
+	return c.sync && c.uploadJobs != nil
+}
+
+func (c *Context) TexSubImage2DOnWorker(texture driver.Texture, texels func() []byte, format driver.PixelFormat, x, y, width, height int) {
+	if c.uploadJobs == nil {
+		panic("opengl: TexSubImage2DOnWorker is not available")
+	}
+	t := toTexture(texture)
+	var ready uintptr
+	_ = c.runOnContextThread(func() error {
+		ready = gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+		gl.Flush()
+		return nil
+	})
+	j := &uploadJob{
+		texture: t,
+		texels:  texels,
+		format:  format,
+		x:       x,
+		y:       y,
+		width:   width,
+		height:  height,
+		ready:   ready,
+		done:    make(chan struct{}),
+	}
+	// The jobs are executed in order, then waiting for the last job of the texture is enough.
+	c.uploads[t] = j
+	c.uploadJobs <- j
+}
+
+func (c *Context) WaitUpload(texture driver.Texture) {
+	t := toTexture(texture)
+	j, ok := c.uploads[t]
+	if !ok {
+		return
+	}
+	<-j.done
+	delete(c.uploads, t)
+	_ = c.runOnContextThread(func() error {
+		gl.WaitSync(j.finished, 0, gl.TIMEOUT_IGNORED)
+		gl.DeleteSync(j.finished)
+		return nil
+	})
+
+	// The changes of the texture by another context are visible after the texture is bound again.
+	if c.lastTexture == t {
+		c.lastTexture = invalidTexture
+	}
+}
+
+// waitAllUploads waits for all the uploads on the upload worker to finish.
+func (c *Context) waitAllUploads() {
+	for t := range c.uploads {
+		c.WaitUpload(t)
+	}
+}
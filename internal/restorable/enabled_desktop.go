@@ -19,8 +19,15 @@
 
 package restorable
 
+import (
+	"os"
+)
+
 func init() {
 	// OpenGL (not ES) never causes context lost,
 	// so restorable feature is not needed.
-	restoringEnabled = false
+	//
+	// EBITEN_FORCE_RESTORING enables the feature to test restoring on desktops.
+	// See also ebiten.SimulateContextLoss.
+	restoringEnabled = os.Getenv("EBITEN_FORCE_RESTORING") != ""
 }
@@ -0,0 +1,164 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restorable
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// graphImage is a node of the image graph.
+type graphImage struct {
+	ID       int  `json:"id"`
+	Width    int  `json:"width"`
+	Height   int  `json:"height"`
+	Format   int  `json:"format"`
+	Stale    bool `json:"stale"`
+	Volatile bool `json:"volatile"`
+	Screen   bool `json:"screen"`
+
+	// BasePixels is the size of the base pixels in bytes.
+	BasePixels int `json:"basePixels"`
+
+	// Compressed is the size of the compressed texels data in bytes.
+	Compressed int `json:"compressed"`
+
+	// History is the number of the draw-image history items.
+	History int `json:"history"`
+}
+
+// graphEdge is an edge of the image graph: Target has draw-image history items with Source.
+type graphEdge struct {
+	Source int `json:"source"`
+	Target int `json:"target"`
+
+	// Count is the number of the history items.
+	Count int `json:"count"`
+}
+
+// graph is the dependency graph of the images for restoring.
+type graph struct {
+	Images []graphImage `json:"images"`
+	Edges  []graphEdge  `json:"edges"`
+}
+
+// graph returns the current graph of the images sorted by the IDs.
+func (i *images) graph() *graph {
+	g := &graph{}
+	for img := range i.images {
+		w, h := img.Size()
+		g.Images = append(g.Images, graphImage{
+			ID:         img.id,
+			Width:      w,
+			Height:     h,
+			Format:     int(img.Format()),
+			Stale:      img.stale,
+			Volatile:   img.volatile,
+			Screen:     img.screen,
+			BasePixels: len(img.basePixels),
+			Compressed: len(img.compressed),
+			History:    len(img.drawImageHistory),
+		})
+		counts := map[*Image]int{}
+		for _, c := range img.drawImageHistory {
+			if c.clearDepth {
+				continue
+			}
+			counts[c.image]++
+		}
+		for src, n := range counts {
+			g.Edges = append(g.Edges, graphEdge{
+				Source: src.id,
+				Target: img.id,
+				Count:  n,
+			})
+		}
+	}
+	sort.Slice(g.Images, func(a, b int) bool {
+		return g.Images[a].ID < g.Images[b].ID
+	})
+	sort.Slice(g.Edges, func(a, b int) bool {
+		if g.Edges[a].Target != g.Edges[b].Target {
+			return g.Edges[a].Target < g.Edges[b].Target
+		}
+		return g.Edges[a].Source < g.Edges[b].Source
+	})
+	return g
+}
+
+// DumpGraphJSON writes the dependency graph of the images to w in JSON.
+//
+// The graph consists of the images with their states and the edges from the source images to
+// the target images of the draw-image history.
+func DumpGraphJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(theImages.graph())
+}
+
+// DumpGraphDOT writes the dependency graph of the images to w in the DOT language of Graphviz.
+//
+// A stale image is drawn in red, a volatile image is dashed, and the screen image is a double circle.
+// An edge from A to B with label n means that B has n draw-image history items with A.
+func DumpGraphDOT(w io.Writer) error {
+	g := theImages.graph()
+	lines := []string{"digraph images {"}
+	for _, img := range g.Images {
+		label := fmt.Sprintf(`#%d %dx%d\nbase: %d bytes\nhistory: %d`, img.ID, img.Width, img.Height, img.BasePixels, img.History)
+		attrs := []string{fmt.Sprintf(`label="%s"`, label)}
+		if img.Stale {
+			attrs = append(attrs, "color=red")
+		}
+		if img.Volatile {
+			attrs = append(attrs, "style=dashed")
+		}
+		if img.Screen {
+			attrs = append(attrs, "shape=doublecircle")
+		}
+		lines = append(lines, fmt.Sprintf("  i%d [%s];", img.ID, strings.Join(attrs, ", ")))
+	}
+	for _, e := range g.Edges {
+		lines = append(lines, fmt.Sprintf("  i%d -> i%d [label=%d];", e.Source, e.Target, e.Count))
+	}
+	lines = append(lines, "}")
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// dumpGraphIfRequested dumps the graph to the file specified by the environment variable
+// EBITEN_DUMP_IMAGE_GRAPH. The graph is dumped in JSON if the file name ends with .json,
+// and in DOT otherwise.
+func dumpGraphIfRequested() error {
+	path := os.Getenv("EBITEN_DUMP_IMAGE_GRAPH")
+	if path == "" {
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	if filepath.Ext(path) == ".json" {
+		return DumpGraphJSON(f)
+	}
+	return DumpGraphDOT(f)
+}
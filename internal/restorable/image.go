@@ -15,13 +15,18 @@
 package restorable
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"image"
 	"image/color"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/internal/affine"
+	"github.com/hajimehoshi/ebiten/internal/driver"
 	"github.com/hajimehoshi/ebiten/internal/graphics"
-	"github.com/hajimehoshi/ebiten/internal/opengl"
+	"github.com/hajimehoshi/ebiten/internal/logger"
+	emath "github.com/hajimehoshi/ebiten/internal/math"
 )
 
 // drawImageHistoryItem is an item for history of draw-image commands.
@@ -29,13 +34,30 @@ type drawImageHistoryItem struct {
 	image    *Image
 	vertices [][]float32
 	colorm   *affine.ColorM
-	mode     opengl.CompositeMode
+	mode     driver.CompositeMode
 	filter   graphics.Filter
+	clip     *image.Rectangle
+	disabled driver.ColorChannels
+	address  driver.Address
+
+	// srcRegion is the region of image sampled by the item.
+	srcRegion image.Rectangle
+
+	// clearDepth indicates that the item represents clearing the depth buffer instead of drawing.
+	// If clearDepth is true, the other fields are not used.
+	clearDepth bool
 }
 
+// maxDrawImageHistoryNum is the maximum number of the history items of an image.
+// If the number exceeds this, the image becomes stale.
+const maxDrawImageHistoryNum = 100
+
 // canMerge returns a boolean value indicating whether the drawImageHistoryItem d
 // can be merged with the given conditions.
-func (d *drawImageHistoryItem) canMerge(image *Image, colorm *affine.ColorM, mode opengl.CompositeMode, filter graphics.Filter) bool {
+func (d *drawImageHistoryItem) canMerge(image *Image, colorm *affine.ColorM, mode driver.CompositeMode, filter graphics.Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) bool {
+	if d.clearDepth {
+		return false
+	}
 	if d.image != image {
 		return false
 	}
@@ -48,15 +70,53 @@ func (d *drawImageHistoryItem) canMerge(image *Image, colorm *affine.ColorM, mod
 	if d.filter != filter {
 		return false
 	}
+	if !graphics.EqualClips(d.clip, clip) {
+		return false
+	}
+	if d.disabled != disabled {
+		return false
+	}
+	if d.address != address {
+		return false
+	}
 	return true
 }
 
+// sourceRegion returns the region of img sampled by drawing the quadrangles vertices.
+func sourceRegion(img *Image, vertices []float32, filter graphics.Filter, address driver.Address) image.Rectangle {
+	w, h := img.image.Size()
+	bounds := image.Rect(0, 0, w, h)
+	if address.Wraps() {
+		return bounds
+	}
+	var r image.Rectangle
+	n := graphics.QuadVertexSizeInBytes() / 4
+	for i := 0; i+n <= len(vertices); i += n {
+		// The first vertex has the texture coordinates of both the corners. See graphics.QuadVertices.
+		u0, v0, u1, v1 := vertices[i+3], vertices[i+4], vertices[i+5], vertices[i+6]
+		r = r.Union(image.Rect(int(math.Floor(float64(u0))), int(math.Floor(float64(v0))), int(math.Ceil(float64(u1))), int(math.Ceil(float64(v1)))))
+	}
+	if filter != graphics.FilterNearest {
+		// The adjacent texels can be sampled with filters other than the nearest filter.
+		r = r.Inset(-1)
+	}
+	return r.Intersect(bounds)
+}
+
 // Image represents an image that can be restored when GL context is lost.
 type Image struct {
 	image *graphics.Image
 
 	basePixels []byte
 
+	// basePixelsShared indicates whether basePixels is shared with snapshots.
+	// Shared base pixels must be copied before being modified in place.
+	basePixelsShared bool
+
+	// compressed is the compressed texels data of an image of a compressed pixel format.
+	// A compressed image is never changed, and is restored from the data instead of the base pixels.
+	compressed []byte
+
 	// drawImageHistory is a set of draw-image commands.
 	// TODO: This should be merged with the similar command queue in package graphics (#433).
 	drawImageHistory []*drawImageHistoryItem
@@ -69,16 +129,19 @@ type Image struct {
 
 	// screen indicates whether the image is used as an actual screen.
 	screen bool
+
+	// id is the serial number of the image to identify it in the dumped graph.
+	id int
 }
 
-var dummyImage = newImageWithoutInit(16, 16, false)
+var dummyImage = newImageWithoutInit(16, 16, driver.PixelFormatRGBA8, false, false)
 
 // newImageWithoutInit creates an image without initialization.
 //
 // Note that Dispose is not called automatically.
-func newImageWithoutInit(width, height int, volatile bool) *Image {
+func newImageWithoutInit(width, height int, format driver.PixelFormat, volatile bool, depth bool) *Image {
 	i := &Image{
-		image:    graphics.NewImage(width, height),
+		image:    graphics.NewImage(width, height, format, depth),
 		volatile: volatile,
 	}
 	theImages.add(i)
@@ -91,17 +154,153 @@ func newImageWithoutInit(width, height int, volatile bool) *Image {
 //
 // Note that Dispose is not called automatically.
 func NewImage(width, height int, volatile bool) *Image {
-	i := newImageWithoutInit(width, height, volatile)
-	i.Clear(0, 0, width, height)
+	i := newImageWithoutInit(width, height, driver.PixelFormatRGBA8, volatile, false)
+	i.clear()
+	return i
+}
+
+// NewImageWithFormat creates an empty non-volatile image with the given size and pixel format.
+//
+// The returned image is cleared.
+//
+// Note that Dispose is not called automatically.
+func NewImageWithFormat(width, height int, format driver.PixelFormat) *Image {
+	i := newImageWithoutInit(width, height, format, false, false)
+	if format == driver.PixelFormatAlpha8 {
+		// An alpha-only image can't be a render target. Clear the image by replacing the pixels instead.
+		// As an alpha-only image is never drawn, the base pixels are always kept.
+		i.ReplacePixels(make([]byte, width*height), 0, 0, width, height)
+		return i
+	}
+	i.clear()
 	return i
 }
 
+// NewVolatileImageWithFormat creates an empty volatile image with the given size and pixel format.
+//
+// format must be a format of a render target, i.e., not PixelFormatAlpha8.
+//
+// The returned image is cleared.
+//
+// Note that Dispose is not called automatically.
+func NewVolatileImageWithFormat(width, height int, format driver.PixelFormat) *Image {
+	if format == driver.PixelFormatAlpha8 {
+		panic("restorable: an alpha-only image can't be volatile")
+	}
+	i := newImageWithoutInit(width, height, format, true, false)
+	i.clear()
+	return i
+}
+
+// NewImageFromCompressedData creates an image of the compressed pixel format with the compressed texels data.
+//
+// The returned image can't be a render target, and its pixels can't be replaced or read.
+//
+// Note that Dispose is not called automatically.
+func NewImageFromCompressedData(width, height int, format driver.PixelFormat, data []byte) *Image {
+	compressed := make([]byte, len(data))
+	copy(compressed, data)
+	i := &Image{
+		image:      graphics.NewCompressedImage(width, height, format, compressed),
+		compressed: compressed,
+	}
+	theImages.add(i)
+	return i
+}
+
+// NewImageWithDepth creates an empty non-volatile image with the given size and a depth buffer.
+//
+// The returned image and its depth buffer are cleared.
+//
+// The depth buffer is not a part of the base pixels. When restoring, the depth buffer is recreated
+// from the draw-image history after the base pixels were taken.
+//
+// Note that Dispose is not called automatically.
+func NewImageWithDepth(width, height int) *Image {
+	i := newImageWithoutInit(width, height, driver.PixelFormatRGBA8, false, true)
+	i.clear()
+	return i
+}
+
+// clear clears the whole image.
+//
+// A cleared image needs neither the base pixels nor the history to be restored.
+// See also isCleared.
+func (i *Image) clear() {
+	w, h := i.image.Size()
+	i.Clear(0, 0, w, h)
+	i.basePixels = nil
+	i.drawImageHistory = nil
+	i.stale = false
+}
+
+// isCleared reports whether the image is known to be cleared without reading the pixels from GPU.
+func (i *Image) isCleared() bool {
+	return i.basePixels == nil && i.compressed == nil && i.isPixelsOnCPU()
+}
+
+// isPixelsOnCPU reports whether the pixels of the image are known without reading them from GPU.
+// If so, the pixels are the base pixels, or the image is cleared when the base pixels are nil.
+//
+// The pixels of a volatile image or the screen are never known.
+func (i *Image) isPixelsOnCPU() bool {
+	return !i.volatile && !i.screen && !i.stale && len(i.drawImageHistory) == 0
+}
+
+// Clear clears the region (x, y) - (x+width, y+height) of the image.
+//
+// If the pixels of the image are known on CPU, the base pixels are cleared as well so that
+// the pixels are still known, e.g. when a part of a shared texture is freed.
 func (i *Image) Clear(x, y, width, height int) {
 	w, h := dummyImage.Size()
 	geom := (*affine.GeoM)(nil).Scale(float64(width)/float64(w), float64(height)/float64(h))
 	geom = geom.Translate(float64(x), float64(y))
 	colorm := (*affine.ColorM)(nil).Scale(0, 0, 0, 0)
-	i.DrawImage(dummyImage, 0, 0, w, h, geom, colorm, opengl.CompositeModeCopy, graphics.FilterNearest)
+	if !i.isPixelsOnCPU() {
+		i.DrawImage(dummyImage, 0, 0, w, h, geom, colorm, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+		return
+	}
+
+	theImages.makeStaleIfDependingOnRegion(i, image.Rect(x, y, x+width, y+height))
+	vs := graphics.QuadVertices(0, 0, w, h, geom, nil, 0)
+	i.image.DrawImage(dummyImage.image, vs, colorm, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, driver.AddressClampToZero)
+	if i.basePixels == nil {
+		return
+	}
+	i.unshareBasePixels()
+	bpp := i.Format().BytesPerPixel()
+	iw, _ := i.image.Size()
+	for j := y; j < y+height; j++ {
+		p := i.basePixels[bpp*(j*iw+x) : bpp*(j*iw+x+width)]
+		for k := range p {
+			p[k] = 0
+		}
+	}
+}
+
+// ClearDepth clears the depth buffer of the image.
+//
+// If the image doesn't have a depth buffer, ClearDepth does nothing.
+func (i *Image) ClearDepth() {
+	if !i.image.HasDepth() {
+		return
+	}
+	i.image.ClearDepth()
+
+	if i.stale || i.volatile || i.screen {
+		return
+	}
+	// The depth buffer is cleared when restoring anyway.
+	if len(i.drawImageHistory) == 0 {
+		return
+	}
+	if len(i.drawImageHistory)+1 > maxDrawImageHistoryNum {
+		i.makeStaleByTooLongHistory()
+		return
+	}
+	i.drawImageHistory = append(i.drawImageHistory, &drawImageHistoryItem{
+		clearDepth: true,
+	})
 }
 
 // NewScreenFramebufferImage creates a special image that framebuffer is one for the screen.
@@ -130,6 +329,11 @@ func (i *Image) Size() (int, int) {
 	return i.image.Size()
 }
 
+// Format returns the image's pixel format.
+func (i *Image) Format() driver.PixelFormat {
+	return i.image.Format()
+}
+
 // makeStale makes the image stale.
 func (i *Image) makeStale() {
 	i.basePixels = nil
@@ -142,6 +346,16 @@ func (i *Image) makeStale() {
 	// the former image can be restored from the latest state of the latter image.
 }
 
+// makeStaleByTooLongHistory makes the image stale as the history exceeds maxDrawImageHistoryNum.
+//
+// The pixels of the image will be read from GPU at the end of the frame, which is slow.
+func (i *Image) makeStaleByTooLongHistory() {
+	w, h := i.image.Size()
+	logger.Warn("restorable: the draw history is too long and the image's pixels will be read from GPU",
+		"image", i.id, "width", w, "height", h, "maxHistory", maxDrawImageHistoryNum)
+	i.makeStale()
+}
+
 // ReplacePixels replaces the image pixels with the given pixels slice.
 func (i *Image) ReplacePixels(pixels []byte, x, y, width, height int) {
 	w, h := i.image.Size()
@@ -152,69 +366,299 @@ func (i *Image) ReplacePixels(pixels []byte, x, y, width, height int) {
 		panic(fmt.Sprintf("restorable: out of range x: %d, y: %d, width: %d, height: %d", x, y, width, height))
 	}
 
-	// TODO: Avoid making other images stale if possible. (#514)
-	// For this purpuse, images should remember which part of that is used for DrawImage.
-	theImages.makeStaleIfDependingOn(i)
+	// Only the images sampling the replaced region become stale (#514).
+	theImages.makeStaleIfDependingOnRegion(i, image.Rect(x, y, x+width, y+height))
 
 	i.image.ReplacePixels(pixels, x, y, width, height)
 
+	bpp := i.Format().BytesPerPixel()
 	if x == 0 && y == 0 && width == w && height == h {
-		if i.basePixels == nil {
-			i.basePixels = make([]byte, 4*w*h)
+		if i.basePixels == nil || i.basePixelsShared {
+			i.basePixels = make([]byte, bpp*w*h)
+			i.basePixelsShared = false
 		}
 		copy(i.basePixels, pixels)
 		i.drawImageHistory = nil
 		i.stale = false
 		return
 	}
-	if i.basePixels == nil {
+	if !i.isPixelsOnCPU() {
 		i.makeStale()
 		return
 	}
-	if len(i.drawImageHistory) > 0 {
-		i.makeStale()
-		return
+	i.unshareBasePixels()
+	if i.basePixels == nil {
+		// The image is cleared.
+		i.basePixels = make([]byte, bpp*w*h)
 	}
-	idx := 4 * (y*w + x)
+	idx := bpp * (y*w + x)
 	for j := 0; j < height; j++ {
-		copy(i.basePixels[idx:idx+4*width], pixels[4*j*width:4*(j+1)*width])
-		idx += 4 * w
+		copy(i.basePixels[idx:idx+bpp*width], pixels[bpp*j*width:bpp*(j+1)*width])
+		idx += bpp * w
 	}
 	i.stale = false
 }
 
+// unshareBasePixels copies the base pixels if they are shared with snapshots so that they can be modified in place.
+func (i *Image) unshareBasePixels() {
+	if !i.basePixelsShared {
+		return
+	}
+	i.basePixelsShared = false
+	if i.basePixels == nil {
+		return
+	}
+	p := make([]byte, len(i.basePixels))
+	copy(p, i.basePixels)
+	i.basePixels = p
+}
+
+// Snapshot returns the pixels of the region (x, y) - (x+width, y+height) in the image's format
+// so that they can be restored later by RestoreSnapshot.
+//
+// The returned slice must not be modified. When the whole image is taken, the snapshot shares the base pixels
+// with the image without copying them, and reading the pixels from GPU is skipped as long as the base pixels
+// are up to date. The base pixels are copied only when the image is modified in place later.
+func (i *Image) Snapshot(x, y, width, height int) ([]byte, error) {
+	w, h := i.image.Size()
+	if x != 0 || y != 0 || width != w || height != h || i.isCleared() {
+		return i.Pixels(x, y, width, height)
+	}
+	if i.compressed != nil {
+		panic("restorable: the pixels of a compressed image can't be read")
+	}
+	if err := i.readPixelsIfNeeded(); err != nil {
+		return nil, err
+	}
+	i.basePixelsShared = true
+	return i.basePixels, nil
+}
+
+// RestoreSnapshot replaces the pixels of the region (x, y) - (x+width, y+height) with the pixels
+// taken by Snapshot.
+//
+// When the whole image is restored, the snapshot pixels become the base pixels without copying them.
+func (i *Image) RestoreSnapshot(pixels []byte, x, y, width, height int) {
+	w, h := i.image.Size()
+	if x != 0 || y != 0 || width != w || height != h {
+		i.ReplacePixels(pixels, x, y, width, height)
+		return
+	}
+	if l := i.Format().BytesPerPixel() * w * h; len(pixels) != l {
+		panic(fmt.Sprintf("restorable: len(pixels) was %d but must be %d", len(pixels), l))
+	}
+
+	theImages.makeStaleIfDependingOnRegion(i, image.Rect(0, 0, w, h))
+	i.image.ReplacePixels(pixels, 0, 0, w, h)
+	i.basePixels = pixels
+	i.basePixelsShared = true
+	i.drawImageHistory = nil
+	i.stale = false
+}
+
 // DrawImage draws a given image img to the image.
-func (i *Image) DrawImage(img *Image, sx0, sy0, sx1, sy1 int, geom *affine.GeoM, colorm *affine.ColorM, mode opengl.CompositeMode, filter graphics.Filter) {
-	w, h := img.Size()
-	vs := vertices(w, h, sx0, sy0, sx1, sy1, geom)
+//
+// clip is the clipping region on the image. If clip is nil, the drawing is not clipped.
+// disabled is the color channels of the image that are not changed.
+// z is the depth value of the drawing, which is used only when the image has a depth buffer.
+// address is the address mode to sample img. If address wraps, the whole img is wrapped.
+func (i *Image) DrawImage(img *Image, sx0, sy0, sx1, sy1 int, geom *affine.GeoM, colorm *affine.ColorM, mode driver.CompositeMode, filter graphics.Filter, clip *image.Rectangle, disabled driver.ColorChannels, z float32, address driver.Address) {
+	// A color matrix consisting of only scaling and translation is applied per vertex as a tint,
+	// so that drawings with different tints can be merged.
+	// An alpha-only image is converted to white before the color matrix, so its tint must stay in the matrix.
+	var tint *affine.ColorM
+	if _, _, ok := colorm.Tint(); ok && img.Format() != driver.PixelFormatAlpha8 {
+		tint, colorm = colorm, nil
+	}
+	vs := graphics.QuadVertices(sx0, sy0, sx1, sy1, geom, tint, z)
+	if vs == nil {
+		return
+	}
+	i.drawImage(img, vs, colorm, mode, filter, clip, disabled, address)
+}
+
+// DrawImages draws the quadrangles quads of the given image img on the image i at once.
+//
+// The tints of quads are applied before colorm. See graphics.QuadVertices.
+// DrawImages is equivalent to calling DrawImage for each quadrangle, but cheaper.
+func (i *Image) DrawImages(img *Image, quads []graphics.Quad, colorm *affine.ColorM, mode driver.CompositeMode, filter graphics.Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	vs := graphics.AppendQuadVertices(nil, quads)
+	if len(vs) == 0 {
+		return
+	}
+	i.drawImage(img, vs, colorm, mode, filter, clip, disabled, address)
+}
+
+func (i *Image) drawImage(img *Image, vertices []float32, colorm *affine.ColorM, mode driver.CompositeMode, filter graphics.Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	theImages.makeStaleIfDependingOn(i)
+
+	if img == i {
+		i.drawSelf(vertices, colorm, mode, filter, clip, disabled, address)
+		return
+	}
+
+	// The drawings on a volatile image are not recorded. Making it stale discards the pixels read before.
+	if img.stale || img.volatile || i.screen || i.volatile || !IsRestoringEnabled() {
+		i.makeStale()
+	} else {
+		i.appendDrawImageHistory(img, vertices, colorm, mode, filter, clip, disabled, address)
+	}
+	i.image.DrawImage(img.image, vertices, colorm, mode, filter, clip, disabled, address)
+}
+
+// drawSelf draws the image on itself.
+//
+// Sampling the render target while rendering is undefined. The sampled region is copied to
+// a temporary image at the same position, and the temporary image is drawn instead.
+// As the image would depend on itself, the drawing can't be recorded and the image becomes stale.
+func (i *Image) drawSelf(vertices []float32, colorm *affine.ColorM, mode driver.CompositeMode, filter graphics.Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	i.makeStale()
+
+	w, h := i.image.Size()
+	src := graphics.NewImage(w, h, i.Format(), false)
+	if r := sourceRegion(i, vertices, filter, address); !r.Empty() {
+		src.CopyPixels(i.image, r.Min.X, r.Min.Y, r.Dx(), r.Dy(), r.Min.X, r.Min.Y)
+	}
+	i.image.DrawImage(src, vertices, colorm, mode, filter, clip, disabled, address)
+	// Disposing is delayed after the drawing. See graphics.commandQueue.EnqueueDisposeCommand.
+	src.Dispose()
+}
+
+// DrawImageWithLUT draws the given image img on the image i, converting the colors with the color look-up table lut.
+//
+// The drawing is not recorded in the history, and i becomes stale.
+func (i *Image) DrawImageWithLUT(img, lut *Image, sx0, sy0, sx1, sy1 int, geom *affine.GeoM, colorm *affine.ColorM, mode driver.CompositeMode) {
+	vs := graphics.QuadVertices(sx0, sy0, sx1, sy1, geom, nil, 0)
 	if vs == nil {
 		return
 	}
 	theImages.makeStaleIfDependingOn(i)
+	i.makeStale()
+	i.image.DrawImageWithLUT(img.image, lut.image, vs, colorm, mode)
+}
+
+// DrawImagesWithPalette draws the quadrangles quads of the given image img on the image i,
+// resolving the colors of img, an alpha-only image of palette indices, with palette.
+//
+// The drawing is not recorded in the history, and i becomes stale.
+func (i *Image) DrawImagesWithPalette(img, palette *Image, quads []graphics.Quad, colorm *affine.ColorM, mode driver.CompositeMode, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	vs := graphics.AppendQuadVertices(nil, quads)
+	if len(vs) == 0 {
+		return
+	}
+	theImages.makeStaleIfDependingOn(i)
+	i.makeStale()
+	i.image.DrawImageWithPalette(img.image, palette.image, vs, colorm, mode, clip, disabled, address)
+}
 
-	if img.stale || img.volatile || i.screen || !IsRestoringEnabled() {
+// DrawImagesWithNormalMap draws the quadrangles quads of the given image img on the image i,
+// lighting the colors of img with normalMap and lighting.
+//
+// The drawing is not recorded in the history, and i becomes stale.
+func (i *Image) DrawImagesWithNormalMap(img, normalMap *Image, lighting *graphics.NormalMapLighting, quads []graphics.Quad, colorm *affine.ColorM, mode driver.CompositeMode, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	vs := graphics.AppendQuadVertices(nil, quads)
+	if len(vs) == 0 {
+		return
+	}
+	theImages.makeStaleIfDependingOn(i)
+	i.makeStale()
+	i.image.DrawImageWithNormalMap(img.image, normalMap.image, lighting, vs, colorm, mode, clip, disabled, address)
+}
+
+// DrawImagesWithDistanceField draws the quadrangles quads of the given image img, a distance field, on the image i
+// with distanceField.
+//
+// The drawing is not recorded in the history, and i becomes stale.
+func (i *Image) DrawImagesWithDistanceField(img *Image, distanceField *graphics.DistanceField, quads []graphics.Quad, colorm *affine.ColorM, mode driver.CompositeMode, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	vs := graphics.AppendQuadVertices(nil, quads)
+	if len(vs) == 0 {
+		return
+	}
+	theImages.makeStaleIfDependingOn(i)
+	i.makeStale()
+	i.image.DrawImageWithDistanceField(img.image, distanceField, vs, colorm, mode, clip, disabled, address)
+}
+
+// CopyPixels copies the region (sx, sy) - (sx+width, sy+height) of img to (dx, dy) of the image.
+//
+// For restoring, the copy is recorded as drawing img in the copy composite mode.
+// If the whole image is overwritten, the previous history is discarded.
+func (i *Image) CopyPixels(img *Image, sx, sy, width, height, dx, dy int) {
+	w, h := i.image.Size()
+	if width <= 0 || height <= 0 {
+		panic("restorable: width/height must be positive")
+	}
+	if dx < 0 || dy < 0 || w < dx+width || h < dy+height {
+		panic(fmt.Sprintf("restorable: out of range dx: %d, dy: %d, width: %d, height: %d", dx, dy, width, height))
+	}
+	sw, sh := img.image.Size()
+	if sx < 0 || sy < 0 || sw < sx+width || sh < sy+height {
+		panic(fmt.Sprintf("restorable: out of range sx: %d, sy: %d, width: %d, height: %d", sx, sy, width, height))
+	}
+
+	theImages.makeStaleIfDependingOn(i)
+
+	if i.isPixelsOnCPU() && img.isPixelsOnCPU() {
+		// Both the pixels are known, e.g. when a shared texture is extended before the main loop starts.
+		// Copy the base pixels so that the pixels are still known without GPU.
+		i.copyBasePixels(img, sx, sy, width, height, dx, dy)
+	} else if img.stale || img.volatile || i.screen || i.volatile || !IsRestoringEnabled() {
 		i.makeStale()
 	} else {
-		i.appendDrawImageHistory(img, vs, colorm, mode, filter)
+		if dx == 0 && dy == 0 && width == w && height == h {
+			// The previous pixels don't matter any more.
+			i.basePixels = nil
+			i.drawImageHistory = nil
+			i.stale = false
+		}
+		geom := (*affine.GeoM)(nil).Translate(float64(dx), float64(dy))
+		vs := graphics.QuadVertices(sx, sy, sx+width, sy+height, geom, nil, 0)
+		i.appendDrawImageHistory(img, vs, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, driver.AddressClampToZero)
+	}
+	i.image.CopyPixels(img.image, sx, sy, width, height, dx, dy)
+}
+
+// copyBasePixels copies the base pixels of the region (sx, sy) - (sx+width, sy+height) of img to (dx, dy) of the image.
+// Both the pixels of the images must be on CPU. See isPixelsOnCPU.
+func (i *Image) copyBasePixels(img *Image, sx, sy, width, height, dx, dy int) {
+	if i.basePixels == nil && img.basePixels == nil {
+		// Copying a cleared region to a cleared image doesn't change anything.
+		return
+	}
+	bpp := i.Format().BytesPerPixel()
+	w, h := i.image.Size()
+	sw, _ := img.image.Size()
+	i.unshareBasePixels()
+	if i.basePixels == nil {
+		i.basePixels = make([]byte, bpp*w*h)
+	}
+	for j := 0; j < height; j++ {
+		d := i.basePixels[bpp*((dy+j)*w+dx) : bpp*((dy+j)*w+dx+width)]
+		if img.basePixels == nil {
+			for k := range d {
+				d[k] = 0
+			}
+			continue
+		}
+		copy(d, img.basePixels[bpp*((sy+j)*sw+sx):])
 	}
-	i.image.DrawImage(img.image, vs, colorm, mode, filter)
 }
 
 // appendDrawImageHistory appends a draw-image history item to the image.
-func (i *Image) appendDrawImageHistory(image *Image, vertices []float32, colorm *affine.ColorM, mode opengl.CompositeMode, filter graphics.Filter) {
+func (i *Image) appendDrawImageHistory(image *Image, vertices []float32, colorm *affine.ColorM, mode driver.CompositeMode, filter graphics.Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
 	if i.stale || i.volatile || i.screen {
 		return
 	}
 	if len(i.drawImageHistory) > 0 {
 		last := i.drawImageHistory[len(i.drawImageHistory)-1]
-		if last.canMerge(image, colorm, mode, filter) {
+		if last.canMerge(image, colorm, mode, filter, clip, disabled, address) {
 			last.vertices = append(last.vertices, vertices)
+			last.srcRegion = last.srcRegion.Union(sourceRegion(image, vertices, filter, address))
 			return
 		}
 	}
-	const maxDrawImageHistoryNum = 100
 	if len(i.drawImageHistory)+1 > maxDrawImageHistoryNum {
-		i.makeStale()
+		i.makeStaleByTooLongHistory()
 		return
 	}
 	// All images must be resolved and not stale each after frame.
@@ -225,30 +669,129 @@ func (i *Image) appendDrawImageHistory(image *Image, vertices []float32, colorm
 		colorm:   colorm,
 		mode:     mode,
 		filter:   filter,
+		clip:     clip,
+		disabled: disabled,
+		address:  address,
+
+		srcRegion: sourceRegion(image, vertices, filter, address),
 	}
 	i.drawImageHistory = append(i.drawImageHistory, item)
 }
 
 // At returns a color value at (x, y).
 //
-// Note that this must not be called until context is available.
+// Before the context is available, At can read only the pixels known on CPU (see isPixelsOnCPU).
+// Otherwise, At returns an error.
 func (i *Image) At(x, y int) (color.RGBA, error) {
+	if i.compressed != nil {
+		panic("restorable: the pixels of a compressed image can't be read")
+	}
 	w, h := i.image.Size()
 	if x < 0 || y < 0 || w <= x || h <= y {
 		return color.RGBA{}, nil
 	}
 
-	if err := graphics.FlushCommands(); err != nil {
+	if i.isCleared() {
+		return color.RGBA{}, nil
+	}
+
+	if err := i.readPixelsIfNeeded(); err != nil {
 		return color.RGBA{}, err
 	}
-	if i.basePixels == nil || i.drawImageHistory != nil || i.stale {
-		if err := i.readPixelsFromGPU(); err != nil {
-			return color.RGBA{}, err
+	return pixelAt(i.basePixels, i.Format(), x+y*w), nil
+}
+
+// Pixels returns a copy of the pixels of the region (x, y) - (x+width, y+height) in the image's format.
+func (i *Image) Pixels(x, y, width, height int) ([]byte, error) {
+	if i.compressed != nil {
+		panic("restorable: the pixels of a compressed image can't be read")
+	}
+	bpp := i.Format().BytesPerPixel()
+	p := make([]byte, bpp*width*height)
+	if i.isCleared() {
+		return p, nil
+	}
+
+	if err := i.readPixelsIfNeeded(); err != nil {
+		return nil, err
+	}
+	w, _ := i.image.Size()
+	for j := 0; j < height; j++ {
+		src := bpp * ((y+j)*w + x)
+		copy(p[bpp*j*width:bpp*(j+1)*width], i.basePixels[src:src+bpp*width])
+	}
+	return p, nil
+}
+
+// needsReadingPixels reports whether the pixels must be read from GPU to know them.
+//
+// The base pixels are up to date unless the image is drawn after they are taken.
+// A cleared image doesn't need the pixels either. See isCleared.
+func (i *Image) needsReadingPixels() bool {
+	if i.isCleared() {
+		return false
+	}
+	return i.basePixels == nil || i.drawImageHistory != nil || i.stale
+}
+
+// IsPixelCacheValid reports whether the pixels of the image are known on CPU without reading them from GPU.
+func (i *Image) IsPixelCacheValid() bool {
+	if i.compressed != nil {
+		return false
+	}
+	return !i.needsReadingPixels()
+}
+
+// InvalidatePixelCache discards the base pixels so that the pixels are read from GPU next time.
+//
+// The pixels on GPU are not changed. As the image becomes stale, the pixels might be read at the end of the frame
+// to restore the image. InvalidatePixelCache does nothing for a compressed image.
+func (i *Image) InvalidatePixelCache() {
+	if i.compressed != nil {
+		return
+	}
+	i.makeStale()
+}
+
+// readPixelsIfNeeded reads the pixels from GPU unless the base pixels are up to date.
+//
+// The command queue is flushed only when the pixels are read, so that reading the known pixels is cheap.
+func (i *Image) readPixelsIfNeeded() error {
+	if !i.needsReadingPixels() {
+		return nil
+	}
+	if err := graphics.FlushCommands(); err != nil {
+		return err
+	}
+	return i.readPixelsFromGPU()
+}
+
+// pixelAt returns the color of the idx-th pixel of p in the given pixel format.
+//
+// An alpha-only pixel is white with the alpha, which is how an alpha-only image is drawn.
+func pixelAt(p []byte, format driver.PixelFormat, idx int) color.RGBA {
+	switch format {
+	case driver.PixelFormatRGBA8, driver.PixelFormatSRGBA8:
+		return color.RGBA{p[4*idx], p[4*idx+1], p[4*idx+2], p[4*idx+3]}
+	case driver.PixelFormatAlpha8:
+		a := p[idx]
+		return color.RGBA{a, a, a, a}
+	case driver.PixelFormatRGBA16F:
+		var c [4]uint8
+		for j := range c {
+			f := emath.Float16frombits(binary.LittleEndian.Uint16(p[8*idx+2*j:]))
+			if f < 0 {
+				f = 0
+			}
+			if f > 1 {
+				f = 1
+			}
+			c[j] = uint8(f*0xff + 0.5)
 		}
+		return color.RGBA{c[0], c[1], c[2], c[3]}
+	default:
+		panic("not reached")
 	}
-	idx := 4*x + 4*y*w
-	r, g, b, a := i.basePixels[idx], i.basePixels[idx+1], i.basePixels[idx+2], i.basePixels[idx+3]
-	return color.RGBA{r, g, b, a}, nil
 }
 
 // makeStaleIfDependingOn makes the image stale if the image depends on target.
@@ -261,6 +804,16 @@ func (i *Image) makeStaleIfDependingOn(target *Image) {
 	}
 }
 
+// makeStaleIfDependingOnRegion makes the image stale if the image depends on the region r of target.
+func (i *Image) makeStaleIfDependingOnRegion(target *Image, r image.Rectangle) {
+	if i.stale {
+		return
+	}
+	if i.dependsOnRegion(target, r) {
+		i.makeStale()
+	}
+}
+
 // readPixelsFromGPU reads the pixels from GPU and resolves the image's 'stale' state.
 func (i *Image) readPixelsFromGPU() error {
 	var err error
@@ -301,10 +854,26 @@ func (i *Image) dependsOn(target *Image) bool {
 	return false
 }
 
+// dependsOnRegion reports whether the image samples the region r of target.
+func (i *Image) dependsOnRegion(target *Image, r image.Rectangle) bool {
+	for _, c := range i.drawImageHistory {
+		if c.clearDepth || c.image != target {
+			continue
+		}
+		if c.srcRegion.Overlaps(r) {
+			return true
+		}
+	}
+	return false
+}
+
 // dependingImages returns all images that is depended by the image.
 func (i *Image) dependingImages() map[*Image]struct{} {
 	r := map[*Image]struct{}{}
 	for _, c := range i.drawImageHistory {
+		if c.clearDepth {
+			continue
+		}
 		r[c.image] = struct{}{}
 	}
 	return r
@@ -331,7 +900,7 @@ func (i *Image) restore() error {
 		return nil
 	}
 	if i.volatile {
-		i.image = graphics.NewImage(w, h)
+		i.image = graphics.NewImage(w, h, i.Format(), i.image.HasDepth())
 		i.basePixels = nil
 		i.drawImageHistory = nil
 		i.stale = false
@@ -341,15 +910,24 @@ func (i *Image) restore() error {
 		// TODO: panic here?
 		return errors.New("restorable: pixels must not be stale when restoring")
 	}
-	gimg := graphics.NewImage(w, h)
+	if i.compressed != nil {
+		i.image = graphics.NewCompressedImage(w, h, i.Format(), i.compressed)
+		return nil
+	}
+	format := i.Format()
+	gimg := graphics.NewImage(w, h, format, i.image.HasDepth())
 	if i.basePixels != nil {
 		gimg.ReplacePixels(i.basePixels, 0, 0, w, h)
 	} else {
 		// Clear the image explicitly.
-		pix := make([]uint8, w*h*4)
+		pix := make([]uint8, w*h*format.BytesPerPixel())
 		gimg.ReplacePixels(pix, 0, 0, w, h)
 	}
 	for _, c := range i.drawImageHistory {
+		if c.clearDepth {
+			gimg.ClearDepth()
+			continue
+		}
 		// All dependencies must be already resolved.
 		if c.image.hasDependency() {
 			panic("not reached")
@@ -358,14 +936,17 @@ func (i *Image) restore() error {
 		for _, v := range c.vertices {
 			vs = append(vs, v...)
 		}
-		gimg.DrawImage(c.image.image, vs, c.colorm, c.mode, c.filter)
+		gimg.DrawImage(c.image.image, vs, c.colorm, c.mode, c.filter, c.clip, c.disabled, c.address)
 	}
 	i.image = gimg
 
-	var err error
-	i.basePixels, err = gimg.Pixels()
-	if err != nil {
-		return err
+	// The pixels of an alpha-only image can't be read, but the base pixels are always up to date.
+	if format != driver.PixelFormatAlpha8 {
+		var err error
+		i.basePixels, err = gimg.Pixels()
+		if err != nil {
+			return err
+		}
 	}
 	i.drawImageHistory = nil
 	i.stale = false
@@ -381,6 +962,7 @@ func (i *Image) Dispose() {
 	i.image.Dispose()
 	i.image = nil
 	i.basePixels = nil
+	i.compressed = nil
 	i.drawImageHistory = nil
 	i.stale = false
 }
@@ -15,6 +15,11 @@
 package restorable
 
 import (
+	"context"
+	"image"
+	"runtime/pprof"
+	"runtime/trace"
+
 	"github.com/hajimehoshi/ebiten/internal/graphics"
 )
 
@@ -40,6 +45,7 @@ func EnableRestoringForTesting() {
 type images struct {
 	images     map[*Image]struct{}
 	lastTarget *Image
+	nextID     int
 }
 
 // theImages represents the images for the current process.
@@ -58,21 +64,62 @@ func ResolveStaleImages() error {
 	if !restoringEnabled {
 		return nil
 	}
-	return theImages.resolveStaleImages()
+	var err error
+	pprof.Do(context.Background(), pprof.Labels("ebiten", "resolve-stale-images"), func(ctx context.Context) {
+		defer trace.StartRegion(ctx, "ebiten: resolve stale images").End()
+		err = theImages.resolveStaleImages()
+	})
+	return err
+}
+
+// ResolvePixels reads the pixels of the given images from GPU unless the base pixels are up to date.
+//
+// The queued draw commands are flushed at most once, while reading the pixels of the images one by one by At
+// might flush them for each image.
+func ResolvePixels(images []*Image) error {
+	var targets []*Image
+	for _, img := range images {
+		if img.compressed != nil {
+			continue
+		}
+		if img.needsReadingPixels() {
+			targets = append(targets, img)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	if err := graphics.FlushCommands(); err != nil {
+		return err
+	}
+	for _, img := range targets {
+		if err := img.readPixelsFromGPU(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Restore restores the images.
 //
 // Restoring means to make all *graphics.Image objects have their textures and framebuffers.
+//
+// If the environment variable EBITEN_DUMP_IMAGE_GRAPH is set to a file path, the dependency graph of
+// the images is dumped to the file before restoring. See DumpGraphDOT and DumpGraphJSON.
 func Restore() error {
 	if err := graphics.ResetGLState(); err != nil {
 		return err
 	}
+	if err := dumpGraphIfRequested(); err != nil {
+		return err
+	}
 	return theImages.restore()
 }
 
 // add adds img to the images.
 func (i *images) add(img *Image) {
+	img.id = i.nextID
+	i.nextID++
 	i.images[img] = struct{}{}
 }
 
@@ -115,6 +162,20 @@ func (i *images) makeStaleIfDependingOnImpl(target *Image) {
 	}
 }
 
+// makeStaleIfDependingOnRegion makes all the images stale that depend on the region r of target.
+//
+// makeStaleIfDependingOnRegion is called when only the region of target is changed.
+// The images depending on only the other regions of target can still be restored with target.
+func (i *images) makeStaleIfDependingOnRegion(target *Image, r image.Rectangle) {
+	if i.lastTarget == target {
+		// All the images depending on target are already stale.
+		return
+	}
+	for img := range i.images {
+		img.makeStaleIfDependingOnRegion(target, r)
+	}
+}
+
 // restore restores the images.
 //
 // Restoring means to make all *graphics.Image objects have their textures and framebuffers.
@@ -183,3 +244,8 @@ func (i *images) restore() error {
 func InitializeGLState() error {
 	return graphics.ResetGLState()
 }
+
+// ResetGLStateCache discards the cached OpenGL state.
+func ResetGLStateCache() {
+	graphics.ResetGLStateCache()
+}
@@ -15,16 +15,20 @@
 package restorable_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/hajimehoshi/ebiten"
 	"github.com/hajimehoshi/ebiten/internal/affine"
+	"github.com/hajimehoshi/ebiten/internal/driver"
 	"github.com/hajimehoshi/ebiten/internal/graphics"
-	"github.com/hajimehoshi/ebiten/internal/opengl"
 	. "github.com/hajimehoshi/ebiten/internal/restorable"
 	"github.com/hajimehoshi/ebiten/internal/testflock"
 )
@@ -117,7 +121,7 @@ func TestRestoreChain(t *testing.T) {
 	clr := color.RGBA{0x00, 0x00, 0x00, 0xff}
 	fill(imgs[0], clr.R, clr.G, clr.B, clr.A)
 	for i := 0; i < num-1; i++ {
-		imgs[i+1].DrawImage(imgs[i], 0, 0, 1, 1, nil, nil, opengl.CompositeModeCopy, graphics.FilterNearest)
+		imgs[i+1].DrawImage(imgs[i], 0, 0, 1, 1, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
 	}
 	if err := ResolveStaleImages(); err != nil {
 		t.Fatal(err)
@@ -155,10 +159,10 @@ func TestRestoreChain2(t *testing.T) {
 	clr8 := color.RGBA{0x00, 0x00, 0xff, 0xff}
 	fill(imgs[8], clr8.R, clr8.G, clr8.B, clr8.A)
 
-	imgs[8].DrawImage(imgs[7], 0, 0, 1, 1, nil, nil, opengl.CompositeModeCopy, graphics.FilterNearest)
-	imgs[9].DrawImage(imgs[8], 0, 0, 1, 1, nil, nil, opengl.CompositeModeCopy, graphics.FilterNearest)
+	imgs[8].DrawImage(imgs[7], 0, 0, 1, 1, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	imgs[9].DrawImage(imgs[8], 0, 0, 1, 1, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
 	for i := 0; i < 7; i++ {
-		imgs[i+1].DrawImage(imgs[i], 0, 0, 1, 1, nil, nil, opengl.CompositeModeCopy, graphics.FilterNearest)
+		imgs[i+1].DrawImage(imgs[i], 0, 0, 1, 1, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
 	}
 
 	if err := ResolveStaleImages(); err != nil {
@@ -197,10 +201,10 @@ func TestRestoreOverrideSource(t *testing.T) {
 	clr0 := color.RGBA{0x00, 0x00, 0x00, 0xff}
 	clr1 := color.RGBA{0x00, 0x00, 0x01, 0xff}
 	fill(img1, clr0.R, clr0.G, clr0.B, clr0.A)
-	img2.DrawImage(img1, 0, 0, 1, 1, nil, nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
-	img3.DrawImage(img2, 0, 0, 1, 1, nil, nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
+	img2.DrawImage(img1, 0, 0, 1, 1, nil, nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	img3.DrawImage(img2, 0, 0, 1, 1, nil, nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
 	fill(img0, clr1.R, clr1.G, clr1.B, clr1.A)
-	img1.DrawImage(img0, 0, 0, 1, 1, nil, nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
+	img1.DrawImage(img0, 0, 0, 1, 1, nil, nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
 	if err := ResolveStaleImages(); err != nil {
 		t.Fatal(err)
 	}
@@ -278,15 +282,15 @@ func TestRestoreComplexGraph(t *testing.T) {
 		img1.Dispose()
 		img0.Dispose()
 	}()
-	img3.DrawImage(img0, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(0, 0), nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
-	img3.DrawImage(img1, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(1, 0), nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
-	img4.DrawImage(img1, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(1, 0), nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
-	img4.DrawImage(img2, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(2, 0), nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
-	img5.DrawImage(img3, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(0, 0), nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
-	img6.DrawImage(img3, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(0, 0), nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
-	img6.DrawImage(img4, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(1, 0), nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
-	img7.DrawImage(img2, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(0, 0), nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
-	img7.DrawImage(img3, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(2, 0), nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
+	img3.DrawImage(img0, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(0, 0), nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	img3.DrawImage(img1, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(1, 0), nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	img4.DrawImage(img1, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(1, 0), nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	img4.DrawImage(img2, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(2, 0), nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	img5.DrawImage(img3, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(0, 0), nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	img6.DrawImage(img3, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(0, 0), nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	img6.DrawImage(img4, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(1, 0), nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	img7.DrawImage(img2, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(0, 0), nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	img7.DrawImage(img3, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(2, 0), nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
 	if err := ResolveStaleImages(); err != nil {
 		t.Fatal(err)
 	}
@@ -374,8 +378,8 @@ func TestRestoreRecursive(t *testing.T) {
 		img1.Dispose()
 		img0.Dispose()
 	}()
-	img1.DrawImage(img0, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(1, 0), nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
-	img0.DrawImage(img1, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(1, 0), nil, opengl.CompositeModeSourceOver, graphics.FilterNearest)
+	img1.DrawImage(img0, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(1, 0), nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	img0.DrawImage(img1, 0, 0, 4, 1, (*affine.GeoM)(nil).Translate(1, 0), nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
 	if err := ResolveStaleImages(); err != nil {
 		t.Fatal(err)
 	}
@@ -469,7 +473,7 @@ func TestDrawImageAndReplacePixels(t *testing.T) {
 	defer img0.Dispose()
 	img1 := NewImage(2, 1, false)
 	defer img1.Dispose()
-	img1.DrawImage(img0, 0, 0, 1, 1, nil, nil, opengl.CompositeModeCopy, graphics.FilterNearest)
+	img1.DrawImage(img0, 0, 0, 1, 1, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
 	img1.ReplacePixels([]byte{0xff, 0xff, 0xff, 0xff}, 1, 0, 1, 1)
 
 	if err := ResolveStaleImages(); err != nil {
@@ -504,8 +508,8 @@ func TestDispose(t *testing.T) {
 	img2 := newImageFromImage(base2)
 	defer img2.Dispose()
 
-	img1.DrawImage(img2, 0, 0, 1, 1, nil, nil, opengl.CompositeModeCopy, graphics.FilterNearest)
-	img0.DrawImage(img1, 0, 0, 1, 1, nil, nil, opengl.CompositeModeCopy, graphics.FilterNearest)
+	img1.DrawImage(img2, 0, 0, 1, 1, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	img0.DrawImage(img1, 0, 0, 1, 1, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
 	img1.Dispose()
 
 	if err := ResolveStaleImages(); err != nil {
@@ -535,7 +539,7 @@ func TestDoubleResolve(t *testing.T) {
 	base.Pix[3] = 0xff
 	img1 := newImageFromImage(base)
 
-	img0.DrawImage(img1, 0, 0, 1, 1, nil, nil, opengl.CompositeModeCopy, graphics.FilterNearest)
+	img0.DrawImage(img1, 0, 0, 1, 1, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
 	img0.ReplacePixels([]uint8{0x00, 0xff, 0x00, 0xff}, 1, 1, 1, 1)
 	// Now img0 is stale.
 	if err := ResolveStaleImages(); err != nil {
@@ -569,4 +573,511 @@ func TestDoubleResolve(t *testing.T) {
 	}
 }
 
+func TestRestoreDrawImageWithClip(t *testing.T) {
+	base0 := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(base0.Pix); i += 4 {
+		base0.Pix[i] = 0xff
+		base0.Pix[i+3] = 0xff
+	}
+	img0 := newImageFromImage(base0)
+	img1 := newImageFromImage(image.NewRGBA(image.Rect(0, 0, 4, 4)))
+
+	clip := image.Rect(1, 1, 3, 3)
+	img1.DrawImage(img0, 0, 0, 4, 4, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, &clip, 0, 0, driver.AddressClampToZero)
+	if err := ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			got, err := img1.At(i, j)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := color.RGBA{}
+			if image.Pt(i, j).In(clip) {
+				want = color.RGBA{0xff, 0x00, 0x00, 0xff}
+			}
+			if !sameColors(got, want, 1) {
+				t.Errorf("img1.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestRestoreCopyPixels(t *testing.T) {
+	base0 := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(base0.Pix); i += 4 {
+		base0.Pix[i] = 0xff
+		base0.Pix[i+3] = 0xff
+	}
+	img0 := newImageFromImage(base0)
+	img1 := newImageFromImage(image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	base2 := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(base2.Pix); i += 4 {
+		base2.Pix[i+1] = 0xff
+		base2.Pix[i+3] = 0xff
+	}
+	img2 := newImageFromImage(base2)
+
+	// Copy a part of img0, and then copy the whole img1 onto img2.
+	img1.CopyPixels(img0, 1, 1, 2, 2, 0, 1)
+	img2.CopyPixels(img1, 0, 0, 4, 4, 0, 0)
+	if err := ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := image.Rect(0, 1, 2, 3)
+	for _, img := range []*Image{img1, img2} {
+		for j := 0; j < 4; j++ {
+			for i := 0; i < 4; i++ {
+				got, err := img.At(i, j)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := color.RGBA{}
+				if image.Pt(i, j).In(r) {
+					want = color.RGBA{0xff, 0x00, 0x00, 0xff}
+				}
+				if !sameColors(got, want, 1) {
+					t.Errorf("At(%d, %d): got: %v, want: %v", i, j, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestRestoreDepth(t *testing.T) {
+	colors := []color.RGBA{
+		{0xff, 0, 0, 0xff},
+		{0, 0xff, 0, 0xff},
+		{0, 0, 0xff, 0xff},
+	}
+	srcs := []*Image{}
+	for _, c := range colors {
+		base := image.NewRGBA(image.Rect(0, 0, 4, 4))
+		for i := 0; i < len(base.Pix); i += 4 {
+			base.Pix[i] = c.R
+			base.Pix[i+1] = c.G
+			base.Pix[i+2] = c.B
+			base.Pix[i+3] = c.A
+		}
+		srcs = append(srcs, newImageFromImage(base))
+	}
+	img := NewImageWithDepth(4, 4)
+
+	// Clearing the depth buffer is recorded so that green is drawn over red at restoring.
+	img.DrawImage(srcs[0], 0, 0, 4, 4, nil, nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0.5, driver.AddressClampToZero)
+	img.ClearDepth()
+	img.DrawImage(srcs[1], 0, 0, 4, 4, nil, nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0.25, driver.AddressClampToZero)
+	img.DrawImage(srcs[2], 0, 0, 4, 4, nil, nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	if err := ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			got, err := img.At(i, j)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := colors[1]
+			if !sameColors(got, want, 1) {
+				t.Errorf("At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
 // TODO: How about volatile/screen images?
+
+func TestDumpGraph(t *testing.T) {
+	src := NewImage(17, 3, false)
+	defer src.Dispose()
+	dst := NewImage(19, 5, false)
+	defer dst.Dispose()
+	fill(src, 0xff, 0, 0, 0xff)
+	dst.DrawImage(src, 0, 0, 17, 3, nil, nil, driver.CompositeModeSourceOver, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+
+	buf := &bytes.Buffer{}
+	if err := DumpGraphJSON(buf); err != nil {
+		t.Fatal(err)
+	}
+	var g struct {
+		Images []struct {
+			ID         int  `json:"id"`
+			Width      int  `json:"width"`
+			Height     int  `json:"height"`
+			Stale      bool `json:"stale"`
+			BasePixels int  `json:"basePixels"`
+			History    int  `json:"history"`
+		} `json:"images"`
+		Edges []struct {
+			Source int `json:"source"`
+			Target int `json:"target"`
+			Count  int `json:"count"`
+		} `json:"edges"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &g); err != nil {
+		t.Fatal(err)
+	}
+
+	srcID, dstID := -1, -1
+	for _, img := range g.Images {
+		switch {
+		case img.Width == 17 && img.Height == 3:
+			srcID = img.ID
+			if img.BasePixels != 4*17*3 {
+				t.Errorf("src base pixels: got: %d, want: %d", img.BasePixels, 4*17*3)
+			}
+		case img.Width == 19 && img.Height == 5:
+			dstID = img.ID
+			if img.History == 0 {
+				t.Errorf("dst history: got: 0, want: > 0")
+			}
+		}
+	}
+	if srcID < 0 || dstID < 0 {
+		t.Fatalf("the images are not found in the graph")
+	}
+	found := false
+	for _, e := range g.Edges {
+		if e.Source == srcID && e.Target == dstID {
+			found = true
+			if e.Count != 1 {
+				t.Errorf("edge count: got: %d, want: 1", e.Count)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("the edge from src (%d) to dst (%d) is not found", srcID, dstID)
+	}
+
+	buf.Reset()
+	if err := DumpGraphDOT(buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("i%d -> i%d [label=1];", srcID, dstID); !strings.Contains(buf.String(), want) {
+		t.Errorf("DOT must contain %q: %s", want, buf.String())
+	}
+}
+
+// isStaleInGraph reports whether the image of the given size is stale in the dumped graph.
+func isStaleInGraph(t *testing.T, width, height int) bool {
+	buf := &bytes.Buffer{}
+	if err := DumpGraphJSON(buf); err != nil {
+		t.Fatal(err)
+	}
+	var g struct {
+		Images []struct {
+			Width  int  `json:"width"`
+			Height int  `json:"height"`
+			Stale  bool `json:"stale"`
+		} `json:"images"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &g); err != nil {
+		t.Fatal(err)
+	}
+	for _, img := range g.Images {
+		if img.Width == width && img.Height == height {
+			return img.Stale
+		}
+	}
+	t.Fatalf("the image (%d, %d) is not found in the graph", width, height)
+	return false
+}
+
+func TestReplacePixelsOutsideSampledRegion(t *testing.T) {
+	base := image.NewRGBA(image.Rect(0, 0, 13, 1))
+	for i := 0; i < len(base.Pix); i += 4 {
+		base.Pix[i] = 0xff
+		base.Pix[i+3] = 0xff
+	}
+	src := newImageFromImage(base)
+	defer src.Dispose()
+	dst := NewImage(4, 11, false)
+	defer dst.Dispose()
+	dst.DrawImage(src, 0, 0, 4, 1, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+
+	// Replacing the region that dst doesn't sample keeps dst restorable.
+	src.ReplacePixels([]byte{0, 0xff, 0, 0xff}, 8, 0, 1, 1)
+	if isStaleInGraph(t, 4, 11) {
+		t.Errorf("dst must not be stale after replacing the pixels outside the sampled region")
+	}
+
+	// Replacing the sampled region makes dst stale.
+	src.ReplacePixels([]byte{0, 0, 0xff, 0xff}, 3, 0, 1, 1)
+	if !isStaleInGraph(t, 4, 11) {
+		t.Errorf("dst must be stale after replacing the pixels in the sampled region")
+	}
+
+	if err := ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		got, err := dst.At(i, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := color.RGBA{0xff, 0, 0, 0xff}
+		if !sameColors(got, want, 1) {
+			t.Errorf("dst.At(%d, 0): got: %v, want: %v", i, got, want)
+		}
+	}
+}
+
+func TestRestoreDrawImageSelf(t *testing.T) {
+	base := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	base.Pix[0] = 0xff
+	base.Pix[3] = 0xff
+	img := newImageFromImage(base)
+	defer img.Dispose()
+
+	// Copy the red pixel at (0, 0) to (1, 0) - (3, 0).
+	geom := (*affine.GeoM)(nil).Scale(3, 1).Translate(1, 0)
+	img.DrawImage(img, 0, 0, 1, 1, geom, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+
+	if err := ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		got, err := img.At(i, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := color.RGBA{0xff, 0, 0, 0xff}
+		if !sameColors(got, want, 1) {
+			t.Errorf("img.At(%d, 0): got: %v, want: %v", i, got, want)
+		}
+	}
+}
+
+func TestRestoreDrawImages(t *testing.T) {
+	base := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	base.Pix[0] = 0xff
+	base.Pix[3] = 0xff
+	base.Pix[5] = 0xff
+	base.Pix[7] = 0xff
+	src := newImageFromImage(base)
+	defer src.Dispose()
+	dst := NewImage(3, 1, false)
+	defer dst.Dispose()
+
+	// Draw the red pixel at (0, 0) and (2, 0), and the green pixel at (1, 0) tinted with blue.
+	quads := []graphics.Quad{
+		{SX0: 0, SY0: 0, SX1: 1, SY1: 1},
+		{SX0: 1, SY0: 0, SX1: 2, SY1: 1, GeoM: (*affine.GeoM)(nil).Translate(1, 0), Tint: (*affine.ColorM)(nil).Translate(0, 0, 1, 0)},
+		{SX0: 0, SY0: 0, SX1: 1, SY1: 1, GeoM: (*affine.GeoM)(nil).Translate(2, 0)},
+	}
+	dst.DrawImages(src, quads, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, driver.AddressClampToZero)
+
+	if err := ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(); err != nil {
+		t.Fatal(err)
+	}
+	wants := []color.RGBA{
+		{0xff, 0, 0, 0xff},
+		{0, 0xff, 0xff, 0xff},
+		{0xff, 0, 0, 0xff},
+	}
+	for i, want := range wants {
+		got, err := dst.At(i, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameColors(got, want, 1) {
+			t.Errorf("dst.At(%d, 0): got: %v, want: %v", i, got, want)
+		}
+	}
+}
+
+func TestCopyPixelsOnCPU(t *testing.T) {
+	src := NewImage(2, 2, false)
+	defer src.Dispose()
+	src.ReplacePixels([]byte{0xff, 0, 0, 0xff}, 1, 1, 1, 1)
+	dst := NewImage(4, 4, false)
+	defer dst.Dispose()
+
+	// The pixels of both the images are known on CPU, so the copy doesn't need GPU.
+	dst.CopyPixels(src, 0, 0, 2, 2, 2, 2)
+	pix := dst.BasePixelsForTesting()
+	if pix == nil {
+		t.Fatal("BasePixelsForTesting: got: nil, want: non-nil")
+	}
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			got := byteSliceToColor(pix, i+j*4)
+			want := color.RGBA{}
+			if i == 3 && j == 3 {
+				want = color.RGBA{0xff, 0, 0, 0xff}
+			}
+			if got != want {
+				t.Errorf("base pixel at (%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestRestoreCompressed(t *testing.T) {
+	if !graphics.IsPixelFormatAvailable(driver.PixelFormatBC3) {
+		t.Skip("PixelFormatBC3 is not available")
+	}
+	// A BC3 block of opaque green.
+	data := []byte{0xff, 0xff, 0, 0, 0, 0, 0, 0, 0xe0, 0x07, 0xe0, 0x07, 0, 0, 0, 0}
+	src := NewImageFromCompressedData(4, 4, driver.PixelFormatBC3, data)
+	defer src.Dispose()
+	dst := NewImage(4, 4, false)
+	defer dst.Dispose()
+	dst.DrawImage(src, 0, 0, 4, 4, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+
+	if err := ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(); err != nil {
+		t.Fatal(err)
+	}
+	want := color.RGBA{0, 0xff, 0, 0xff}
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			got, err := dst.At(i, j)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameColors(got, want, 1) {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	img := NewImage(2, 2, false)
+	defer img.Dispose()
+	img.ReplacePixels([]byte{0xff, 0, 0, 0xff}, 0, 0, 1, 1)
+
+	// The base pixels are up to date, so the snapshot shares them.
+	s, err := img.Snapshot(0, 0, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &s[0] != &img.BasePixelsForTesting()[0] {
+		t.Errorf("the snapshot must share the base pixels")
+	}
+
+	// Modifying the image must not change the snapshot.
+	img.ReplacePixels([]byte{0, 0xff, 0, 0xff}, 1, 1, 1, 1)
+	if got, want := byteSliceToColor(s, 3), (color.RGBA{}); got != want {
+		t.Errorf("the snapshot pixel at (1, 1): got: %v, want: %v", got, want)
+	}
+
+	img.RestoreSnapshot(s, 0, 0, 2, 2)
+	if err := ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(); err != nil {
+		t.Fatal(err)
+	}
+	want := []color.RGBA{{0xff, 0, 0, 0xff}, {}, {}, {}}
+	for j := 0; j < 2; j++ {
+		for i := 0; i < 2; i++ {
+			got, err := img.At(i, j)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameColors(got, want[i+j*2], 1) {
+				t.Errorf("img.At(%d, %d): got: %v, want: %v", i, j, got, want[i+j*2])
+			}
+		}
+	}
+}
+
+func TestResolvePixels(t *testing.T) {
+	src := NewImage(1, 1, false)
+	defer src.Dispose()
+	src.ReplacePixels([]byte{0xff, 0, 0, 0xff}, 0, 0, 1, 1)
+
+	dsts := []*Image{NewImage(1, 1, false), NewImage(1, 1, false)}
+	for _, dst := range dsts {
+		defer dst.Dispose()
+		dst.DrawImage(src, 0, 0, 1, 1, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+		if dst.BasePixelsForTesting() != nil {
+			t.Fatalf("the base pixels must be nil before resolving")
+		}
+	}
+
+	if err := ResolvePixels(append(dsts, src)); err != nil {
+		t.Fatal(err)
+	}
+	want := color.RGBA{0xff, 0, 0, 0xff}
+	for i, dst := range dsts {
+		p := dst.BasePixelsForTesting()
+		if p == nil {
+			t.Fatalf("dsts[%d]: the base pixels must be read", i)
+		}
+		if got := byteSliceToColor(p, 0); !sameColors(got, want, 1) {
+			t.Errorf("dsts[%d]: got: %v, want: %v", i, got, want)
+		}
+	}
+}
+
+func TestInvalidatePixelCache(t *testing.T) {
+	src := NewImage(1, 1, false)
+	defer src.Dispose()
+	src.ReplacePixels([]byte{0xff, 0, 0, 0xff}, 0, 0, 1, 1)
+	if !src.IsPixelCacheValid() {
+		t.Errorf("the pixel cache must be valid after ReplacePixels")
+	}
+
+	dst := NewImage(1, 1, false)
+	defer dst.Dispose()
+	if !dst.IsPixelCacheValid() {
+		t.Errorf("the pixel cache of a cleared image must be valid")
+	}
+	dst.DrawImage(src, 0, 0, 1, 1, nil, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
+	if dst.IsPixelCacheValid() {
+		t.Errorf("the pixel cache must be invalid after DrawImage")
+	}
+
+	src.InvalidatePixelCache()
+	if src.IsPixelCacheValid() {
+		t.Errorf("the pixel cache must be invalid after InvalidatePixelCache")
+	}
+
+	// The invalidated images can still be restored.
+	if err := ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(); err != nil {
+		t.Fatal(err)
+	}
+	want := color.RGBA{0xff, 0, 0, 0xff}
+	for _, img := range []*Image{src, dst} {
+		got, err := img.At(0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameColors(got, want, 1) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+		if !img.IsPixelCacheValid() {
+			t.Errorf("the pixel cache must be valid after At")
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shaping
+
+import (
+	"unicode"
+)
+
+// arabicForm is the presentation forms of an Arabic letter.
+type arabicForm struct {
+	// isolated is the isolated form. The final, initial and medial forms follow it in this order.
+	isolated rune
+
+	// dual indicates whether the letter joins on both sides. Otherwise the letter joins only to the right
+	// and has only the isolated and final forms.
+	dual bool
+}
+
+var arabicForms = map[rune]arabicForm{
+	0x0621: {0xfe80, false}, // The hamza doesn't join at all. See joinsToRight.
+	0x0622: {0xfe81, false},
+	0x0623: {0xfe83, false},
+	0x0624: {0xfe85, false},
+	0x0625: {0xfe87, false},
+	0x0626: {0xfe89, true},
+	0x0627: {0xfe8d, false},
+	0x0628: {0xfe8f, true},
+	0x0629: {0xfe93, false},
+	0x062a: {0xfe95, true},
+	0x062b: {0xfe99, true},
+	0x062c: {0xfe9d, true},
+	0x062d: {0xfea1, true},
+	0x062e: {0xfea5, true},
+	0x062f: {0xfea9, false},
+	0x0630: {0xfeab, false},
+	0x0631: {0xfead, false},
+	0x0632: {0xfeaf, false},
+	0x0633: {0xfeb1, true},
+	0x0634: {0xfeb5, true},
+	0x0635: {0xfeb9, true},
+	0x0636: {0xfebd, true},
+	0x0637: {0xfec1, true},
+	0x0638: {0xfec5, true},
+	0x0639: {0xfec9, true},
+	0x063a: {0xfecd, true},
+	0x0641: {0xfed1, true},
+	0x0642: {0xfed5, true},
+	0x0643: {0xfed9, true},
+	0x0644: {0xfedd, true},
+	0x0645: {0xfee1, true},
+	0x0646: {0xfee5, true},
+	0x0647: {0xfee9, true},
+	0x0648: {0xfeed, false},
+	0x0649: {0xfeef, false},
+	0x064a: {0xfef1, true},
+
+	// Persian letters
+	0x067e: {0xfb56, true},
+	0x0686: {0xfb7a, true},
+	0x0698: {0xfb8a, false},
+	0x06a9: {0xfb8e, true},
+	0x06af: {0xfb92, true},
+	0x06cc: {0xfbfc, true},
+}
+
+const (
+	tatweel = 0x0640
+	lam     = 0x0644
+)
+
+// lamAlefLigatures is the isolated forms of the ligatures of lam and alefs. The final form follows it.
+var lamAlefLigatures = map[rune]rune{
+	0x0622: 0xfef5,
+	0x0623: 0xfef7,
+	0x0625: 0xfef9,
+	0x0627: 0xfefb,
+}
+
+// isTransparent reports whether r is transparent in joining, e.g. a harakat.
+func isTransparent(r rune) bool {
+	return unicode.Is(unicode.Mn, r)
+}
+
+// joinsToLeft reports whether r connects to the following letter.
+func joinsToLeft(r rune) bool {
+	if r == tatweel {
+		return true
+	}
+	f, ok := arabicForms[r]
+	return ok && f.dual
+}
+
+// joinsToRight reports whether r connects to the preceding letter.
+func joinsToRight(r rune) bool {
+	if r == tatweel {
+		return true
+	}
+	_, ok := arabicForms[r]
+	return ok && r != 0x0621
+}
+
+// ShapeArabic replaces the Arabic letters in runes with their contextual presentation forms, and returns the result.
+// indices[i] is the index in runes of the rune shaped[i] comes from.
+//
+// The ligatures of lam and alef are formed: the lam is replaced with the ligature and the alef is removed.
+// The other runes are not changed.
+func ShapeArabic(runes []rune) (shaped []rune, indices []int) {
+	// prev and next return the adjacent non-transparent runes.
+	prev := func(i int) rune {
+		for i--; i >= 0; i-- {
+			if !isTransparent(runes[i]) {
+				return runes[i]
+			}
+		}
+		return 0
+	}
+	next := func(i int) (rune, int) {
+		for i++; i < len(runes); i++ {
+			if !isTransparent(runes[i]) {
+				return runes[i], i
+			}
+		}
+		return 0, -1
+	}
+
+	shaped = make([]rune, 0, len(runes))
+	indices = make([]int, 0, len(runes))
+	removed := map[int]struct{}{}
+	for i, r := range runes {
+		if _, ok := removed[i]; ok {
+			continue
+		}
+		indices = append(indices, i)
+		f, ok := arabicForms[r]
+		if !ok {
+			shaped = append(shaped, r)
+			continue
+		}
+		right := joinsToLeft(prev(i)) && joinsToRight(r)
+		n, ni := next(i)
+
+		if r == lam {
+			if lig, ok := lamAlefLigatures[n]; ok {
+				// The ligature joins only to the right.
+				if right {
+					lig++
+				}
+				shaped = append(shaped, lig)
+				removed[ni] = struct{}{}
+				continue
+			}
+		}
+
+		left := f.dual && joinsToRight(n)
+		switch {
+		case right && left:
+			shaped = append(shaped, f.isolated+3)
+		case left:
+			shaped = append(shaped, f.isolated+2)
+		case right:
+			shaped = append(shaped, f.isolated+1)
+		default:
+			shaped = append(shaped, f.isolated)
+		}
+	}
+	return shaped, indices
+}
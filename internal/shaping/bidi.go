@@ -0,0 +1,254 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shaping
+
+import (
+	"unicode"
+)
+
+// bidiClass is a simplified bidirectional character type.
+type bidiClass int
+
+const (
+	classL bidiClass = iota
+	classR
+	classEN
+	classWS
+	classON
+	classNSM
+)
+
+func classOf(r rune) bidiClass {
+	switch {
+	case unicode.In(r, unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko):
+		if unicode.Is(unicode.Mn, r) {
+			return classNSM
+		}
+		if unicode.IsDigit(r) {
+			// Arabic-Indic digits behave as European numbers in this simplified algorithm.
+			return classEN
+		}
+		if unicode.IsLetter(r) {
+			return classR
+		}
+		return classON
+	case unicode.Is(unicode.Mn, r):
+		return classNSM
+	case '0' <= r && r <= '9':
+		return classEN
+	case unicode.IsSpace(r):
+		return classWS
+	case unicode.IsLetter(r) || unicode.IsDigit(r):
+		return classL
+	}
+	return classON
+}
+
+// IsRTL reports whether the text includes a right-to-left character.
+func IsRTL(runes []rune) bool {
+	for _, r := range runes {
+		if classOf(r) == classR {
+			return true
+		}
+	}
+	return false
+}
+
+// ParagraphRTL reports whether the paragraph direction of runes is right-to-left,
+// i.e., the first strong character is a right-to-left character.
+func ParagraphRTL(runes []rune) bool {
+	for _, r := range runes {
+		switch classOf(r) {
+		case classL:
+			return false
+		case classR:
+			return true
+		}
+	}
+	return false
+}
+
+// Levels returns the embedding levels of runes in a paragraph of the given direction.
+//
+// This is a simplified Unicode bidirectional algorithm without explicit embeddings, isolates or bracket pairs.
+func Levels(runes []rune, rtl bool) []int {
+	base := 0
+	if rtl {
+		base = 1
+	}
+	classes := make([]bidiClass, len(runes))
+	for i, r := range runes {
+		classes[i] = classOf(r)
+	}
+
+	// W1: A non-spacing mark takes the class of the preceding character.
+	for i, c := range classes {
+		if c != classNSM {
+			continue
+		}
+		if i == 0 {
+			classes[i] = classON
+			if rtl {
+				classes[i] = classR
+			}
+			continue
+		}
+		classes[i] = classes[i-1]
+	}
+
+	// W7: A European number after a left-to-right character is treated as left-to-right.
+	strong := classL
+	if rtl {
+		strong = classR
+	}
+	for i, c := range classes {
+		switch c {
+		case classL, classR:
+			strong = c
+		case classEN:
+			if strong == classL {
+				classes[i] = classL
+			}
+		}
+	}
+
+	// N1, N2: Neutrals between the same directions take the direction. Otherwise, the neutrals take the
+	// paragraph direction. Numbers are treated as right-to-left here.
+	dirOf := func(c bidiClass) (bidiClass, bool) {
+		switch c {
+		case classL:
+			return classL, true
+		case classR, classEN:
+			return classR, true
+		}
+		return 0, false
+	}
+	for i := 0; i < len(classes); {
+		if _, ok := dirOf(classes[i]); ok {
+			i++
+			continue
+		}
+		j := i
+		for j < len(classes) {
+			if _, ok := dirOf(classes[j]); ok {
+				break
+			}
+			j++
+		}
+		before, after := classL, classL
+		if rtl {
+			before, after = classR, classR
+		}
+		if i > 0 {
+			before, _ = dirOf(classes[i-1])
+		}
+		if j < len(classes) {
+			after, _ = dirOf(classes[j])
+		}
+		d := classL
+		if rtl {
+			d = classR
+		}
+		if before == after {
+			d = before
+		}
+		for k := i; k < j; k++ {
+			if classes[k] == classWS && j == len(classes) {
+				// L1: Trailing whitespaces are at the paragraph level.
+				classes[k] = classL
+				if rtl {
+					classes[k] = classR
+				}
+				continue
+			}
+			classes[k] = d
+		}
+		i = j
+	}
+
+	// I1, I2
+	levels := make([]int, len(runes))
+	for i, c := range classes {
+		l := base
+		switch {
+		case base == 0 && c == classR:
+			l = 1
+		case base == 0 && c == classEN:
+			l = 2
+		case base == 1 && (c == classL || c == classEN):
+			l = 2
+		}
+		levels[i] = l
+	}
+	return levels
+}
+
+// VisualOrder returns the indices of runes in the visual order from left to right with the given levels (L2).
+func VisualOrder(levels []int) []int {
+	order := make([]int, len(levels))
+	max := 0
+	min := -1
+	for i, l := range levels {
+		order[i] = i
+		if max < l {
+			max = l
+		}
+		if l%2 == 1 && (min < 0 || l < min) {
+			min = l
+		}
+	}
+	if min < 0 {
+		return order
+	}
+	for level := max; level >= min; level-- {
+		for i := 0; i < len(order); {
+			if levels[order[i]] < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(order) && levels[order[j]] >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			i = j
+		}
+	}
+	return order
+}
+
+var mirrors = map[rune]rune{
+	'(': ')',
+	')': '(',
+	'<': '>',
+	'>': '<',
+	'[': ']',
+	']': '[',
+	'{': '}',
+	'}': '{',
+	'«': '»',
+	'»': '«',
+}
+
+// Mirror returns the mirrored glyph of r, e.g. ')' for '(', that is used in a right-to-left run.
+// If r doesn't have its mirrored glyph, Mirror returns r.
+func Mirror(r rune) rune {
+	if m, ok := mirrors[r]; ok {
+		return m
+	}
+	return r
+}
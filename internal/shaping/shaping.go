@@ -0,0 +1,39 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shaping provides a minimal text shaping for right-to-left scripts:
+// the bidirectional reordering, the mirroring of brackets and the contextual forms of Arabic letters.
+//
+// The shaping relies on the Unicode presentation forms instead of the font's substitution tables,
+// which font.Face doesn't expose. Scripts that require the substitution tables, e.g. the conjuncts of Devanagari,
+// and the emoji sequences with zero width joiners are not supported.
+package shaping
+
+// Visual returns the runes of a paragraph, shaped and reordered from left to right to be rendered.
+func Visual(runes []rune) []rune {
+	if !IsRTL(runes) {
+		return runes
+	}
+	shaped, _ := ShapeArabic(runes)
+	levels := Levels(shaped, ParagraphRTL(shaped))
+	visual := make([]rune, len(shaped))
+	for i, idx := range VisualOrder(levels) {
+		r := shaped[idx]
+		if levels[idx]%2 == 1 {
+			r = Mirror(r)
+		}
+		visual[i] = r
+	}
+	return visual
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shaping_test
+
+import (
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/internal/shaping"
+)
+
+func TestShapeArabic(t *testing.T) {
+	cases := []struct {
+		In  string
+		Out string
+	}{
+		// beh beh beh: initial, medial, final
+		{"ببب", "ﺑﺒﺐ"},
+		// beh: isolated
+		{"ب", "ﺏ"},
+		// beh alef beh: alef doesn't join to the left.
+		{"باب", "ﺑﺎﺏ"},
+		// beh fatha beh: the harakat is transparent.
+		{"بَب", "ﺑَﺐ"},
+		// lam alef, beh lam alef
+		{"لا", "ﻻ"},
+		{"بلا", "ﺑﻼ"},
+		// Latin letters are not changed.
+		{"aبb", "aﺏb"},
+	}
+	for _, c := range cases {
+		got, _ := ShapeArabic([]rune(c.In))
+		if string(got) != c.Out {
+			t.Errorf("ShapeArabic(%+q): got: %+q, want: %+q", c.In, string(got), c.Out)
+		}
+	}
+}
+
+func TestVisual(t *testing.T) {
+	const (
+		alef  = "א"
+		bet   = "ב"
+		gimel = "ג"
+	)
+	cases := []struct {
+		In  string
+		Out string
+	}{
+		{"abc", "abc"},
+		{alef + bet + gimel, gimel + bet + alef},
+		{"abc " + alef + bet + " def", "abc " + bet + alef + " def"},
+		{alef + bet + " abc " + gimel, gimel + " abc " + bet + alef},
+		{alef + " 123 " + bet, bet + " 123 " + alef},
+		{alef + "(" + bet + ")", "(" + bet + ")" + alef},
+		{alef + bet + "!", "!" + bet + alef},
+	}
+	for _, c := range cases {
+		if got := string(Visual([]rune(c.In))); got != c.Out {
+			t.Errorf("Visual(%+q): got: %+q, want: %+q", c.In, got, c.Out)
+		}
+	}
+}
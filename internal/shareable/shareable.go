@@ -16,12 +16,13 @@ package shareable
 
 import (
 	"fmt"
+	"image"
 	"image/color"
 	"runtime"
 
 	"github.com/hajimehoshi/ebiten/internal/affine"
+	"github.com/hajimehoshi/ebiten/internal/driver"
 	"github.com/hajimehoshi/ebiten/internal/graphics"
-	"github.com/hajimehoshi/ebiten/internal/opengl"
 	"github.com/hajimehoshi/ebiten/internal/packing"
 	"github.com/hajimehoshi/ebiten/internal/restorable"
 	"github.com/hajimehoshi/ebiten/internal/sync"
@@ -61,7 +62,8 @@ func (b *backend) TryAlloc(width, height int) (*packing.Node, bool) {
 	newImg := restorable.NewImage(s, s, false)
 	oldImg := b.restorable
 	w, h := oldImg.Size()
-	newImg.DrawImage(oldImg, 0, 0, w, h, nil, nil, opengl.CompositeModeCopy, graphics.FilterNearest)
+	// Copying keeps the pixels known on CPU, e.g. before the main loop starts.
+	newImg.CopyPixels(oldImg, 0, 0, w, h, 0, 0)
 	oldImg.Dispose()
 	b.restorable = newImg
 
@@ -94,7 +96,7 @@ func (i *Image) ensureNotShared() {
 
 	x, y, w, h := i.region()
 	newImg := restorable.NewImage(w, h, false)
-	newImg.DrawImage(i.backend.restorable, x, y, x+w, y+h, nil, nil, opengl.CompositeModeCopy, graphics.FilterNearest)
+	newImg.CopyPixels(i.backend.restorable, x, y, w, h, 0, 0)
 
 	i.dispose()
 	i.backend = &backend{
@@ -117,15 +119,81 @@ func (i *Image) Size() (width, height int) {
 	return w, h
 }
 
-func (i *Image) DrawImage(img *Image, sx0, sy0, sx1, sy1 int, geom *affine.GeoM, colorm *affine.ColorM, mode opengl.CompositeMode, filter graphics.Filter) {
+// TextureKey returns a value to identify the texture where the image is placed.
+//
+// The images of the same key share a texture, and the successive drawings of them can be merged.
+func (i *Image) TextureKey() interface{} {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	return i.backend
+}
+
+// Format returns the pixel format of the image.
+func (i *Image) Format() driver.PixelFormat {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	return i.backend.restorable.Format()
+}
+
+// DrawImage draws the given image img on the image i.
+//
+// clip is the clipping region on i. If clip is nil, the drawing is not clipped.
+// disabled is the color channels of i that are not changed.
+// z is the depth value of the drawing, which is used only when i has a depth buffer.
+// address is the address mode to sample img. If address wraps, the whole img is wrapped.
+func (i *Image) DrawImage(img *Image, sx0, sy0, sx1, sy1 int, geom *affine.GeoM, colorm *affine.ColorM, mode driver.CompositeMode, filter graphics.Filter, clip *image.Rectangle, disabled driver.ColorChannels, z float32, address driver.Address) {
 	backendsM.Lock()
 	defer backendsM.Unlock()
 	i.ensureNotShared()
+	if address.Wraps() {
+		// A texture can be wrapped only when the image occupies the texture from the origin.
+		img.ensureNotShared()
+	}
 
-	// Compare i and img after ensuring i is not shared, or
-	// i and img might share the same texture even though i != img.
-	if i.backend.restorable == img.backend.restorable {
-		panic("shareable: Image.DrawImage: img must be different from the receiver")
+	// As i is not shared here, i and img share the same texture only when i == img.
+	// Then, the restorable image draws the image on itself via a temporary copy.
+
+	dx, dy, _, _ := img.region()
+	sx0 += dx
+	sy0 += dy
+	sx1 += dx
+	sy1 += dy
+	// i is not shared here, so clip doesn't have to be translated.
+	i.backend.restorable.DrawImage(img.backend.restorable, sx0, sy0, sx1, sy1, geom, colorm, mode, filter, clip, disabled, z, address)
+}
+
+// DrawImages draws the quadrangles quads of the given image img on the image i at once.
+//
+// The source regions of quads are translated in place.
+func (i *Image) DrawImages(img *Image, quads []graphics.Quad, colorm *affine.ColorM, mode driver.CompositeMode, filter graphics.Filter, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	i.ensureNotShared()
+	if address.Wraps() {
+		img.ensureNotShared()
+	}
+
+	dx, dy, _, _ := img.region()
+	for k := range quads {
+		q := &quads[k]
+		q.SX0 += dx
+		q.SY0 += dy
+		q.SX1 += dx
+		q.SY1 += dy
+	}
+	i.backend.restorable.DrawImages(img.backend.restorable, quads, colorm, mode, filter, clip, disabled, address)
+}
+
+// DrawImageWithLUT draws the given image img on the image i, converting the colors with the color look-up table lut.
+func (i *Image) DrawImageWithLUT(img, lut *Image, sx0, sy0, sx1, sy1 int, geom *affine.GeoM, colorm *affine.ColorM, mode driver.CompositeMode) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	i.ensureNotShared()
+	// The LUT is sampled assuming that the LUT image is at the origin of the texture.
+	lut.ensureNotShared()
+
+	if i.backend.restorable == img.backend.restorable || i.backend.restorable == lut.backend.restorable {
+		panic("shareable: Image.DrawImageWithLUT: img and lut must be different from the receiver")
 	}
 
 	dx, dy, _, _ := img.region()
@@ -133,7 +201,99 @@ func (i *Image) DrawImage(img *Image, sx0, sy0, sx1, sy1 int, geom *affine.GeoM,
 	sy0 += dy
 	sx1 += dx
 	sy1 += dy
-	i.backend.restorable.DrawImage(img.backend.restorable, sx0, sy0, sx1, sy1, geom, colorm, mode, filter)
+	i.backend.restorable.DrawImageWithLUT(img.backend.restorable, lut.backend.restorable, sx0, sy0, sx1, sy1, geom, colorm, mode)
+}
+
+// DrawImagesWithPalette draws the quadrangles quads of the given image img on the image i,
+// resolving the colors of img with palette.
+//
+// img is an alpha-only image of palette indices. img is sampled with nearest filter.
+func (i *Image) DrawImagesWithPalette(img, palette *Image, quads []graphics.Quad, colorm *affine.ColorM, mode driver.CompositeMode, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	i.ensureNotShared()
+	if address.Wraps() {
+		img.ensureNotShared()
+	}
+	// The palette is sampled assuming that the palette image is at the origin of the texture.
+	palette.ensureNotShared()
+
+	dx, dy, _, _ := img.region()
+	for k := range quads {
+		q := &quads[k]
+		q.SX0 += dx
+		q.SY0 += dy
+		q.SX1 += dx
+		q.SY1 += dy
+	}
+	i.backend.restorable.DrawImagesWithPalette(img.backend.restorable, palette.backend.restorable, quads, colorm, mode, clip, disabled, address)
+}
+
+// DrawImagesWithNormalMap draws the quadrangles quads of the given image img on the image i,
+// lighting the colors of img with normalMap and lighting.
+//
+// normalMap must have the same size as img. img is sampled with nearest filter.
+func (i *Image) DrawImagesWithNormalMap(img, normalMap *Image, lighting *graphics.NormalMapLighting, quads []graphics.Quad, colorm *affine.ColorM, mode driver.CompositeMode, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	i.ensureNotShared()
+	// The normal map is sampled at the same texture coordinates as img, so both must be at the origin of their textures.
+	img.ensureNotShared()
+	normalMap.ensureNotShared()
+
+	if i.backend.restorable == img.backend.restorable || i.backend.restorable == normalMap.backend.restorable {
+		panic("shareable: Image.DrawImagesWithNormalMap: img and normalMap must be different from the receiver")
+	}
+
+	i.backend.restorable.DrawImagesWithNormalMap(img.backend.restorable, normalMap.backend.restorable, lighting, quads, colorm, mode, clip, disabled, address)
+}
+
+// DrawImagesWithDistanceField draws the quadrangles quads of the given image img, a distance field, on the image i
+// with distanceField.
+//
+// The source regions of quads are translated in place.
+func (i *Image) DrawImagesWithDistanceField(img *Image, distanceField *graphics.DistanceField, quads []graphics.Quad, colorm *affine.ColorM, mode driver.CompositeMode, clip *image.Rectangle, disabled driver.ColorChannels, address driver.Address) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	i.ensureNotShared()
+	if address.Wraps() {
+		img.ensureNotShared()
+	}
+
+	dx, dy, _, _ := img.region()
+	for k := range quads {
+		q := &quads[k]
+		q.SX0 += dx
+		q.SY0 += dy
+		q.SX1 += dx
+		q.SY1 += dy
+	}
+	i.backend.restorable.DrawImagesWithDistanceField(img.backend.restorable, distanceField, quads, colorm, mode, clip, disabled, address)
+}
+
+// ClearDepth clears the depth buffer of i. If i doesn't have a depth buffer, ClearDepth does nothing.
+func (i *Image) ClearDepth() {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	i.backend.restorable.ClearDepth()
+}
+
+// CopyPixels copies the region (sx, sy) - (sx+width, sy+height) of img to (dx, dy) of the image i.
+func (i *Image) CopyPixels(img *Image, sx, sy, width, height, dx, dy int) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	// Copying only touches the given region, so i can remain shared unless i and img share the same texture.
+	if i.backend.restorable == img.backend.restorable {
+		i.ensureNotShared()
+	}
+	if i.backend.restorable == img.backend.restorable {
+		panic("shareable: Image.CopyPixels: img must be different from the receiver")
+	}
+
+	ox, oy, _, _ := img.region()
+	x, y, _, _ := i.region()
+	i.backend.restorable.CopyPixels(img.backend.restorable, sx+ox, sy+oy, width, height, dx+x, dy+y)
 }
 
 func (i *Image) ReplacePixels(p []byte) {
@@ -141,7 +301,7 @@ func (i *Image) ReplacePixels(p []byte) {
 	defer backendsM.Unlock()
 
 	x, y, w, h := i.region()
-	if l := 4 * w * h; len(p) != l {
+	if l := i.backend.restorable.Format().BytesPerPixel() * w * h; len(p) != l {
 		panic(fmt.Sprintf("shareable: len(p) was %d but must be %d", len(p), l))
 	}
 	i.backend.restorable.ReplacePixels(p, x, y, w, h)
@@ -160,6 +320,64 @@ func (i *Image) At(x, y int) (color.Color, error) {
 	return clr, err
 }
 
+// Pixels returns a copy of the pixels of the image in its format.
+func (i *Image) Pixels() ([]byte, error) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	x, y, w, h := i.region()
+	return i.backend.restorable.Pixels(x, y, w, h)
+}
+
+// IsPixelCacheValid reports whether the pixels of the image are known on CPU without reading them from GPU.
+func (i *Image) IsPixelCacheValid() bool {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	return i.backend.restorable.IsPixelCacheValid()
+}
+
+// InvalidatePixelCache discards the pixels of the image known on CPU.
+// The pixels of the other images sharing the same texture are also discarded.
+func (i *Image) InvalidatePixelCache() {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	i.backend.restorable.InvalidatePixelCache()
+}
+
+// Snapshot returns a snapshot of the pixels of the image in its format, which can be restored by RestoreSnapshot.
+// The returned slice must not be modified.
+//
+// The image stops being shared so that the snapshot can share the pixels with the image without copying them.
+func (i *Image) Snapshot() ([]byte, error) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	i.ensureNotShared()
+	x, y, w, h := i.region()
+	return i.backend.restorable.Snapshot(x, y, w, h)
+}
+
+// RestoreSnapshot replaces the pixels of the image with the snapshot p taken by Snapshot.
+func (i *Image) RestoreSnapshot(p []byte) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	x, y, w, h := i.region()
+	if l := i.backend.restorable.Format().BytesPerPixel() * w * h; len(p) != l {
+		panic(fmt.Sprintf("shareable: len(p) was %d but must be %d", len(p), l))
+	}
+	i.backend.restorable.RestoreSnapshot(p, x, y, w, h)
+}
+
+// IsDisposed reports whether the image is disposed.
+func (i *Image) IsDisposed() bool {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	return i.isDisposed()
+}
+
 func (i *Image) isDisposed() bool {
 	return i.backend == nil
 }
@@ -227,17 +445,22 @@ func NewImage(width, height int) *Image {
 		b := &backend{
 			restorable: restorable.NewImage(width, height, false),
 		}
-		return &Image{
+		i := &Image{
 			backend: b,
 		}
+		runtime.SetFinalizer(i, (*Image).Dispose)
+		return i
 	}
 
 	for _, b := range theBackends {
 		if n, ok := b.TryAlloc(width, height); ok {
-			return &Image{
+			// Set the finalizer so that the region in the shared texture is freed even when Dispose is not called.
+			i := &Image{
 				backend: b,
 				node:    n,
 			}
+			runtime.SetFinalizer(i, (*Image).Dispose)
+			return i
 		}
 	}
 	size := initSize
@@ -266,11 +489,63 @@ func NewImage(width, height int) *Image {
 	return i
 }
 
-func NewVolatileImage(width, height int) *Image {
+// NewImageWithFormat returns an image with the given pixel format.
+//
+// Only images of the default format PixelFormatRGBA8 are shared.
+func NewImageWithFormat(width, height int, format driver.PixelFormat) *Image {
+	if format == driver.PixelFormatRGBA8 {
+		return NewImage(width, height)
+	}
+
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	i := &Image{
+		backend: &backend{
+			restorable: restorable.NewImageWithFormat(width, height, format),
+		},
+	}
+	runtime.SetFinalizer(i, (*Image).Dispose)
+	return i
+}
+
+// NewImageFromCompressedData returns an image of the compressed pixel format with the compressed texels data.
+//
+// An image of a compressed format is not shared.
+func NewImageFromCompressedData(width, height int, format driver.PixelFormat, data []byte) *Image {
 	backendsM.Lock()
 	defer backendsM.Unlock()
 
-	r := restorable.NewImage(width, height, true)
+	i := &Image{
+		backend: &backend{
+			restorable: restorable.NewImageFromCompressedData(width, height, format, data),
+		},
+	}
+	runtime.SetFinalizer(i, (*Image).Dispose)
+	return i
+}
+
+// NewImageWithDepth returns an image with a depth buffer.
+//
+// An image with a depth buffer is not shared, since clearing the depth buffer affects the whole texture.
+func NewImageWithDepth(width, height int) *Image {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	i := &Image{
+		backend: &backend{
+			restorable: restorable.NewImageWithDepth(width, height),
+		},
+	}
+	runtime.SetFinalizer(i, (*Image).Dispose)
+	return i
+}
+
+func NewVolatileImage(width, height int, format driver.PixelFormat) *Image {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	r := restorable.NewVolatileImageWithFormat(width, height, format)
 	i := &Image{
 		backend: &backend{
 			restorable: r,
@@ -300,12 +575,41 @@ func InitializeGLState() error {
 	return restorable.InitializeGLState()
 }
 
+func ResetGLStateCache() {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	restorable.ResetGLStateCache()
+}
+
 func ResolveStaleImages() error {
 	backendsM.Lock()
 	defer backendsM.Unlock()
 	return restorable.ResolveStaleImages()
 }
 
+// ResolvePixels reads the pixels of the given images from GPU at once if needed, so that At for the images doesn't
+// read them one by one.
+//
+// The pixels of the images sharing the same texture are read only once.
+func ResolvePixels(images []*Image) error {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	var rs []*restorable.Image
+	m := map[*backend]struct{}{}
+	for _, img := range images {
+		if img.isDisposed() {
+			continue
+		}
+		if _, ok := m[img.backend]; ok {
+			continue
+		}
+		m[img.backend] = struct{}{}
+		rs = append(rs, img.backend.restorable)
+	}
+	return restorable.ResolvePixels(rs)
+}
+
 func IsRestoringEnabled() bool {
 	// As IsRestoringEnabled is an immutable state, no need to lock here.
 	return restorable.IsRestoringEnabled()
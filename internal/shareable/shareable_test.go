@@ -22,8 +22,8 @@ import (
 
 	"github.com/hajimehoshi/ebiten"
 	"github.com/hajimehoshi/ebiten/internal/affine"
+	"github.com/hajimehoshi/ebiten/internal/driver"
 	"github.com/hajimehoshi/ebiten/internal/graphics"
-	"github.com/hajimehoshi/ebiten/internal/opengl"
 	. "github.com/hajimehoshi/ebiten/internal/shareable"
 	"github.com/hajimehoshi/ebiten/internal/testflock"
 )
@@ -84,7 +84,7 @@ func TestEnsureNotShared(t *testing.T) {
 	)
 	// img4.ensureNotShared() should be called.
 	geom := (*affine.GeoM)(nil).Translate(size/4, size/4)
-	img4.DrawImage(img3, 0, 0, size/2, size/2, geom, nil, opengl.CompositeModeCopy, graphics.FilterNearest)
+	img4.DrawImage(img3, 0, 0, size/2, size/2, geom, nil, driver.CompositeModeCopy, graphics.FilterNearest, nil, 0, 0, driver.AddressClampToZero)
 
 	for j := 0; j < size; j++ {
 		for i := 0; i < size; i++ {
@@ -126,3 +126,39 @@ func TestDispose(t *testing.T) {
 		t.Errorf("BackendNumForTesting(): got: %d, want: %d", BackendNumForTesting(), 1)
 	}
 }
+
+func TestPixels(t *testing.T) {
+	// img1 makes img2 allocated at a non-upper-left location in the shared backend.
+	img1 := NewImage(bigSize, 100)
+	defer img1.Dispose()
+
+	const (
+		w = 8
+		h = 4
+	)
+	img2 := NewImage(w, h)
+	defer img2.Dispose()
+
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			pix[4*(i+j*w)] = byte(i)
+			pix[4*(i+j*w)+1] = byte(j)
+			pix[4*(i+j*w)+3] = 0xff
+		}
+	}
+	img2.ReplacePixels(pix)
+
+	got, err := img2.Pixels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(pix) {
+		t.Fatalf("len(Pixels()): got: %d, want: %d", len(got), len(pix))
+	}
+	for i := range pix {
+		if got[i] != pix[i] {
+			t.Errorf("Pixels()[%d]: got: %d, want: %d", i, got[i], pix[i])
+		}
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package software
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/internal/driver"
+)
+
+// varyings is the output of the vertex shader, which is interpolated for each fragment.
+type varyings struct {
+	texCoord       vec2
+	texCoordMin    vec2
+	texCoordMax    vec2
+	colorScale     vec4
+	colorTranslate vec4
+	dstPos         vec2
+
+	// texCoordDx and texCoordDy are the derivatives of texCoord in the window coordinates like dFdx and dFdy.
+	// They are not interpolated but constant in a triangle.
+	texCoordDx vec2
+	texCoordDy vec2
+}
+
+// vertex is a vertex processed by the vertex shader.
+type vertex struct {
+	// x and y are in the window coordinates, and depth is in [0, 1].
+	x     float64
+	y     float64
+	depth float64
+
+	varyings varyings
+}
+
+// attribute returns the value of the vertex attribute of the given name for the index-th vertex.
+//
+// The missing components are filled with (0, 0, 0, 1) as OpenGL does.
+func (d *Driver) attribute(p *program, name string, index int) vec4 {
+	v := vec4{0, 0, 0, 1}
+	for i, n := range p.attributes {
+		if n != name {
+			continue
+		}
+		ptr, ok := p.pointers[i]
+		if !ok || !ptr.enabled {
+			return v
+		}
+		offset := (ptr.offset + ptr.stride*index) / 4
+		for j := 0; j < ptr.size; j++ {
+			v[j] = float64(ptr.buffer.floats[offset+j])
+		}
+		return v
+	}
+	return v
+}
+
+// vertex processes the index-th vertex in the same way as the vertex shader of the graphics package.
+func (d *Driver) vertex(p *program, index int) vertex {
+	pos := d.attribute(p, "vertex", index)
+	texCoord := d.attribute(p, "tex_coord", index)
+	sourceSize := p.uniformVec2("source_size")
+
+	// tex_coord is in texels. Normalize it by the texture size.
+	uv := vec4{
+		texCoord[0] / sourceSize[0],
+		texCoord[1] / sourceSize[1],
+		texCoord[2] / sourceSize[0],
+		texCoord[3] / sourceSize[1],
+	}
+
+	// The depth value 1 is the nearest. Convert it to the normalized device coordinate -1.
+	clip := p.uniformMat4("projection_matrix").mul(vec4{pos[0], pos[1], 1 - 2*pos[2], 1})
+	return vertex{
+		x:     (clip[0]/clip[3] + 1) / 2 * float64(d.viewportWidth),
+		y:     (clip[1]/clip[3] + 1) / 2 * float64(d.viewportHeight),
+		depth: clamp((clip[2]/clip[3]+1)/2, 0, 1),
+		varyings: varyings{
+			texCoord:       vec2{uv[0], uv[1]},
+			texCoordMin:    vec2{math.Min(uv[0], uv[2]), math.Min(uv[1], uv[3])},
+			texCoordMax:    vec2{math.Max(uv[0], uv[2]), math.Max(uv[1], uv[3])},
+			colorScale:     d.attribute(p, "color_scale", index),
+			colorTranslate: d.attribute(p, "color_translate", index),
+			dstPos:         vec2{pos[0], pos[1]},
+		},
+	}
+}
+
+func (d *Driver) DrawElements(mode driver.Mode, len int, offsetInBytes int) {
+	if mode != driver.Triangles {
+		panic("software: only triangles are supported")
+	}
+	if d.framebuffer.texture == nil {
+		// Drawing on the screen is discarded.
+		return
+	}
+	indices := d.elementArrayBuffer.indices[offsetInBytes/2 : offsetInBytes/2+len]
+	for i := 0; i+2 < len; i += 3 {
+		d.drawTriangle([3]vertex{
+			d.vertex(d.program, int(indices[i])),
+			d.vertex(d.program, int(indices[i+1])),
+			d.vertex(d.program, int(indices[i+2])),
+		})
+	}
+}
+
+// edge returns the edge function of the edge (x0, y0) - (x1, y1) at (x, y).
+// The value is positive when (x, y) is on the left side of the edge in the coordinates where the Y axis points up.
+func edge(x0, y0, x1, y1, x, y float64) float64 {
+	return (x1-x0)*(y-y0) - (y1-y0)*(x-x0)
+}
+
+// includesEdge reports whether the pixels exactly on the edge (x0, y0) - (x1, y1) are drawn.
+//
+// A pixel on an edge shared by two triangles must be drawn exactly once. As the shared edge has opposite
+// directions in the two triangles, only the edges of one of the directions are included.
+func includesEdge(x0, y0, x1, y1 float64) bool {
+	dx, dy := x1-x0, y1-y0
+	return dy > 0 || (dy == 0 && dx < 0)
+}
+
+// drawTriangle rasterizes the triangle and draws its fragments on the current framebuffer.
+//
+// The fragments are sampled at the centers of the pixels as OpenGL does.
+func (d *Driver) drawTriangle(vs [3]vertex) {
+	area := edge(vs[0].x, vs[0].y, vs[1].x, vs[1].y, vs[2].x, vs[2].y)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		vs[1], vs[2] = vs[2], vs[1]
+		area = -area
+	}
+
+	t := d.framebuffer.texture
+	b := image.Rect(
+		int(math.Floor(math.Min(vs[0].x, math.Min(vs[1].x, vs[2].x)))),
+		int(math.Floor(math.Min(vs[0].y, math.Min(vs[1].y, vs[2].y)))),
+		int(math.Ceil(math.Max(vs[0].x, math.Max(vs[1].x, vs[2].x)))),
+		int(math.Ceil(math.Max(vs[0].y, math.Max(vs[1].y, vs[2].y)))))
+	b = b.Intersect(image.Rect(0, 0, t.width, t.height))
+	b = b.Intersect(image.Rect(0, 0, d.viewportWidth, d.viewportHeight))
+	if d.scissor != nil {
+		b = b.Intersect(*d.scissor)
+	}
+
+	// The barycentric coordinate of the k-th vertex is linear in the window coordinates. See edge.
+	var dx, dy vec2
+	for k := 0; k < 3; k++ {
+		v0, v1 := vs[(k+1)%3], vs[(k+2)%3]
+		uv := vs[k].varyings.texCoord
+		dx = dx.add(uv.scale(-(v1.y - v0.y) / area))
+		dy = dy.add(uv.scale((v1.x - v0.x) / area))
+	}
+
+	for j := b.Min.Y; j < b.Max.Y; j++ {
+		y := float64(j) + 0.5
+		for i := b.Min.X; i < b.Max.X; i++ {
+			x := float64(i) + 0.5
+			var ws [3]float64
+			inside := true
+			for k := 0; k < 3; k++ {
+				v0, v1 := vs[(k+1)%3], vs[(k+2)%3]
+				w := edge(v0.x, v0.y, v1.x, v1.y, x, y)
+				if w < 0 || (w == 0 && !includesEdge(v0.x, v0.y, v1.x, v1.y)) {
+					inside = false
+					break
+				}
+				ws[k] = w / area
+			}
+			if !inside {
+				continue
+			}
+			v := interpolate(vs, ws)
+			v.varyings.texCoordDx = dx
+			v.varyings.texCoordDy = dy
+			d.drawFragment(i, j, v)
+		}
+	}
+}
+
+// interpolate returns the vertex at the barycentric coordinates ws in the triangle vs.
+func interpolate(vs [3]vertex, ws [3]float64) vertex {
+	var r vertex
+	for k, v := range vs {
+		w := ws[k]
+		r.depth += v.depth * w
+		r.varyings.texCoord = r.varyings.texCoord.add(v.varyings.texCoord.scale(w))
+		r.varyings.texCoordMin = r.varyings.texCoordMin.add(v.varyings.texCoordMin.scale(w))
+		r.varyings.texCoordMax = r.varyings.texCoordMax.add(v.varyings.texCoordMax.scale(w))
+		r.varyings.colorScale = r.varyings.colorScale.add(v.varyings.colorScale.scale(w))
+		r.varyings.colorTranslate = r.varyings.colorTranslate.add(v.varyings.colorTranslate.scale(w))
+		r.varyings.dstPos = r.varyings.dstPos.add(v.varyings.dstPos.scale(w))
+	}
+	return r
+}
+
+// drawFragment processes the fragment at the pixel (x, y) and blends it with the current framebuffer.
+func (d *Driver) drawFragment(x, y int, v vertex) {
+	c, ok := d.fragment(d.program, &v.varyings)
+	if !ok {
+		return
+	}
+
+	f := d.framebuffer
+	if d.depthTest && f.depth != nil {
+		// With LEQUAL, a later drawing with the same depth is drawn over an earlier one.
+		i := y*f.depth.width + x
+		z := float32(v.depth)
+		if z > f.depth.depths[i] {
+			return
+		}
+		f.depth.depths[i] = z
+	}
+
+	t := f.texture
+	if t.format != driver.PixelFormatRGBA16F {
+		// The colors are clamped for unsigned normalized textures.
+		c = c.clamp(0, 1)
+	}
+	c = blend(d.mode, c, t.at(x, y))
+	if t.format == driver.PixelFormatSRGBA8 {
+		// The colors are encoded when they are written to an sRGB texture.
+		c = encodeSRGB(c)
+	}
+	i := 4 * (y*t.width + x)
+	for k := 0; k < 4; k++ {
+		if d.colorMask[k] {
+			t.texels[i+k] = quantize(c[k], t.format)
+		}
+	}
+}
+
+type operation int
+
+const (
+	zero operation = iota
+	one
+	srcAlpha
+	dstAlpha
+	oneMinusSrcAlpha
+	oneMinusDstAlpha
+	dstColor
+	oneMinusSrcColor
+)
+
+type equation int
+
+const (
+	funcAdd equation = iota
+	funcReverseSubtract
+	blendMin
+	blendMax
+)
+
+// operations returns the blend factors of the given composite mode in the same way as the OpenGL driver.
+func operations(mode driver.CompositeMode) (src operation, dst operation) {
+	switch mode {
+	case driver.CompositeModeSourceOver:
+		return one, oneMinusSrcAlpha
+	case driver.CompositeModeClear:
+		return zero, zero
+	case driver.CompositeModeCopy:
+		return one, zero
+	case driver.CompositeModeDestination:
+		return zero, one
+	case driver.CompositeModeDestinationOver:
+		return oneMinusDstAlpha, one
+	case driver.CompositeModeSourceIn:
+		return dstAlpha, zero
+	case driver.CompositeModeDestinationIn:
+		return zero, srcAlpha
+	case driver.CompositeModeSourceOut:
+		return oneMinusDstAlpha, zero
+	case driver.CompositeModeDestinationOut:
+		return zero, oneMinusSrcAlpha
+	case driver.CompositeModeSourceAtop:
+		return dstAlpha, oneMinusSrcAlpha
+	case driver.CompositeModeDestinationAtop:
+		return oneMinusDstAlpha, srcAlpha
+	case driver.CompositeModeXor:
+		return oneMinusDstAlpha, oneMinusSrcAlpha
+	case driver.CompositeModeLighter:
+		return one, one
+	case driver.CompositeModeMultiply:
+		return dstColor, oneMinusSrcAlpha
+	case driver.CompositeModeScreen:
+		return one, oneMinusSrcColor
+	case driver.CompositeModeMin, driver.CompositeModeMax, driver.CompositeModeSubtract:
+		return one, one
+	default:
+		panic("not reached")
+	}
+}
+
+// equations returns the blend equations for RGB and alpha of the given composite mode.
+func equations(mode driver.CompositeMode) (rgb equation, alpha equation) {
+	switch mode {
+	case driver.CompositeModeMin:
+		return blendMin, blendMin
+	case driver.CompositeModeMax:
+		return blendMax, blendMax
+	case driver.CompositeModeSubtract:
+		return funcReverseSubtract, funcAdd
+	default:
+		return funcAdd, funcAdd
+	}
+}
+
+// factor returns the blend factor of the operation for the k-th component.
+func factor(op operation, k int, src, dst vec4) float64 {
+	switch op {
+	case zero:
+		return 0
+	case one:
+		return 1
+	case srcAlpha:
+		return src[3]
+	case dstAlpha:
+		return dst[3]
+	case oneMinusSrcAlpha:
+		return 1 - src[3]
+	case oneMinusDstAlpha:
+		return 1 - dst[3]
+	case dstColor:
+		return dst[k]
+	case oneMinusSrcColor:
+		return 1 - src[k]
+	default:
+		panic("not reached")
+	}
+}
+
+// blend blends the source color src with the destination color dst by the composite mode.
+func blend(mode driver.CompositeMode, src, dst vec4) vec4 {
+	sop, dop := operations(mode)
+	rgb, alpha := equations(mode)
+	var r vec4
+	for k := 0; k < 4; k++ {
+		eq := rgb
+		if k == 3 {
+			eq = alpha
+		}
+		switch eq {
+		case funcAdd:
+			r[k] = src[k]*factor(sop, k, src, dst) + dst[k]*factor(dop, k, src, dst)
+		case funcReverseSubtract:
+			r[k] = dst[k]*factor(dop, k, src, dst) - src[k]*factor(sop, k, src, dst)
+		case blendMin:
+			r[k] = math.Min(src[k], dst[k])
+		case blendMax:
+			r[k] = math.Max(src[k], dst[k])
+		}
+	}
+	return r
+}
@@ -0,0 +1,586 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package software provides a graphics driver that renders on CPU without any graphics library.
+//
+// The driver follows the OpenGL driver's behavior including the shader programs of the graphics package,
+// and is useful to test rendering without a display.
+// The driver is slow and is not intended for games.
+package software
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/internal/driver"
+	emath "github.com/hajimehoshi/ebiten/internal/math"
+)
+
+// maxTextureSize is the maximum texture size of the software driver.
+const maxTextureSize = 4096
+
+// texture is a texture whose texels are RGBA values in [0, 1].
+//
+// The texels of an alpha-only texture are also represented as RGBA values with zero RGB values.
+type texture struct {
+	width   int
+	height  int
+	format  driver.PixelFormat
+	texels  []float32
+	deleted bool
+}
+
+// at returns the color of the texel at (x, y) as an OpenGL sampler does.
+//
+// The texels of an sRGB texture are decoded into the linear color space.
+func (t *texture) at(x, y int) vec4 {
+	i := 4 * (y*t.width + x)
+	c := vec4{float64(t.texels[i]), float64(t.texels[i+1]), float64(t.texels[i+2]), float64(t.texels[i+3])}
+	if t.format == driver.PixelFormatSRGBA8 {
+		for j := 0; j < 3; j++ {
+			c[j] = emath.SRGBToLinear(c[j])
+		}
+	}
+	return c
+}
+
+// set sets the color of the texel at (x, y).
+//
+// The color is rounded into the precision of the texture's format.
+func (t *texture) set(x, y int, c vec4) {
+	i := 4 * (y*t.width + x)
+	for j := 0; j < 4; j++ {
+		t.texels[i+j] = quantize(c[j], t.format)
+	}
+}
+
+// quantize rounds v into the precision of the format.
+func quantize(v float64, format driver.PixelFormat) float32 {
+	if format == driver.PixelFormatRGBA16F {
+		return emath.Float16frombits(emath.Float16bits(float32(v)))
+	}
+	return float32(byteValue(v)) / 0xff
+}
+
+// byteValue converts v into an 8-bit unsigned normalized value.
+func byteValue(v float64) byte {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 1 {
+		return 0xff
+	}
+	return byte(v*0xff + 0.5)
+}
+
+// replacePixels replaces the texels of the region (x, y) - (x+width, y+height) with the pixels p
+// in the given format.
+func (t *texture) replacePixels(p []byte, format driver.PixelFormat, x, y, width, height int) {
+	bpp := format.BytesPerPixel()
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			b := p[bpp*(j*width+i):]
+			var c [4]float32
+			switch format {
+			case driver.PixelFormatRGBA8, driver.PixelFormatSRGBA8:
+				for k := 0; k < 4; k++ {
+					c[k] = float32(b[k]) / 0xff
+				}
+			case driver.PixelFormatAlpha8:
+				c[3] = float32(b[0]) / 0xff
+			case driver.PixelFormatRGBA16F:
+				for k := 0; k < 4; k++ {
+					c[k] = emath.Float16frombits(binary.LittleEndian.Uint16(b[2*k:]))
+				}
+			default:
+				panic("not reached")
+			}
+			copy(t.texels[4*((y+j)*t.width+x+i):], c[:])
+		}
+	}
+}
+
+// framebuffer is a framebuffer. The screen framebuffer doesn't have a texture.
+type framebuffer struct {
+	texture *texture
+	depth   *renderbuffer
+}
+
+// renderbuffer is a depth buffer.
+type renderbuffer struct {
+	width  int
+	height int
+	depths []float32
+}
+
+// shader is a shader. The shader source is not compiled, and only the variant of the fragment shader is detected.
+type shader struct {
+	shaderType driver.ShaderType
+	variant    variant
+}
+
+// variant represents the definitions of a fragment shader of the graphics package.
+type variant struct {
+	filterNearest bool
+	filterLinear  bool
+	filterScreen  bool
+	anisotropic   bool
+	colorLUT      bool
+	colorPalette  bool
+	normalMap     bool
+	distanceField bool
+}
+
+// program is a shader program.
+type program struct {
+	variant    variant
+	attributes []string
+	uniforms   map[string][]float32
+	pointers   map[int]*attribPointer
+}
+
+// attribPointer is the location of a vertex attribute in an array buffer.
+type attribPointer struct {
+	buffer  *buffer
+	size    int
+	stride  int
+	offset  int
+	enabled bool
+}
+
+// buffer is an array buffer or an element array buffer.
+type buffer struct {
+	floats  []float32
+	indices []uint16
+}
+
+// Driver is a graphics driver that renders on CPU.
+//
+// Driver doesn't support instanced drawing and compressed textures.
+// The screen framebuffer doesn't have any storage: drawing on the screen is discarded,
+// and the pixels of the screen are always transparent.
+type Driver struct {
+	screenFramebuffer *framebuffer
+	framebuffer       *framebuffer
+	viewportWidth     int
+	viewportHeight    int
+
+	textures [4]*texture
+
+	program            *program
+	arrayBuffer        *buffer
+	elementArrayBuffer *buffer
+
+	mode      driver.CompositeMode
+	scissor   *image.Rectangle
+	colorMask [4]bool
+	depthTest bool
+}
+
+// NewDriver returns a new software driver.
+func NewDriver() *Driver {
+	s := &framebuffer{}
+	d := &Driver{
+		screenFramebuffer: s,
+		framebuffer:       s,
+	}
+	d.ResetStateCache()
+	return d
+}
+
+func (d *Driver) Reset() error {
+	d.framebuffer = d.screenFramebuffer
+	d.viewportWidth = 0
+	d.viewportHeight = 0
+	d.textures = [4]*texture{}
+	d.program = nil
+	d.arrayBuffer = nil
+	d.elementArrayBuffer = nil
+	d.ResetStateCache()
+	return nil
+}
+
+func (d *Driver) ResetStateCache() {
+	// The driver doesn't have any state cache. Set the default states as the OpenGL driver does.
+	d.mode = driver.CompositeModeSourceOver
+	d.scissor = nil
+	d.colorMask = [4]bool{true, true, true, true}
+	d.depthTest = false
+}
+
+func (d *Driver) Flush() {
+}
+
+func (d *Driver) MaxTextureSize() int {
+	return maxTextureSize
+}
+
+func (d *Driver) IsNPOTTextureAvailable() bool {
+	return true
+}
+
+func (d *Driver) IsPixelFormatAvailable(format driver.PixelFormat) bool {
+	return !format.IsCompressed()
+}
+
+func (d *Driver) NewTexture(width, height int, format driver.PixelFormat) (driver.Texture, error) {
+	if format.IsCompressed() {
+		return nil, fmt.Errorf("software: the pixel format %d is not available", format)
+	}
+	t := &texture{
+		width:  width,
+		height: height,
+		format: format,
+		texels: make([]float32, 4*width*height),
+	}
+	d.BindTexture(t)
+	return t, nil
+}
+
+func (d *Driver) NewCompressedTexture(width, height int, format driver.PixelFormat, data []byte) (driver.Texture, error) {
+	return nil, errors.New("software: compressed textures are not available")
+}
+
+func (d *Driver) BindTexture(t driver.Texture) {
+	d.BindTextureAt(0, t)
+}
+
+func (d *Driver) BindTextureAt(unit int, t driver.Texture) {
+	d.textures[unit], _ = t.(*texture)
+}
+
+func (d *Driver) DeleteTexture(t driver.Texture) {
+	tt := t.(*texture)
+	tt.deleted = true
+	tt.texels = nil
+	for i, bt := range d.textures {
+		if bt == tt {
+			d.textures[i] = nil
+		}
+	}
+}
+
+func (d *Driver) IsTexture(t driver.Texture) bool {
+	tt, ok := t.(*texture)
+	return ok && tt != nil && !tt.deleted
+}
+
+func (d *Driver) TexSubImage2D(p []byte, format driver.PixelFormat, x, y, width, height int) {
+	d.textures[0].replacePixels(p, format, x, y, width, height)
+}
+
+func (d *Driver) IsPixelBufferAvailable() bool {
+	return false
+}
+
+func (d *Driver) TexSubImages2DFromPixelBuffer(p []byte, uploads []driver.TextureUpload) {
+	for _, u := range uploads {
+		d.BindTexture(u.Texture)
+		d.TexSubImage2D(p[u.Offset:], u.Format, u.X, u.Y, u.Width, u.Height)
+	}
+}
+
+func (d *Driver) IsUploadWorkerAvailable() bool {
+	return false
+}
+
+func (d *Driver) TexSubImage2DOnWorker(t driver.Texture, texels func() []byte, format driver.PixelFormat, x, y, width, height int) {
+	panic("software: TexSubImage2DOnWorker is not available")
+}
+
+func (d *Driver) WaitUpload(t driver.Texture) {
+}
+
+func (d *Driver) CopyTexSubImage2D(x, y, sx, sy, width, height int) {
+	src := d.framebuffer.texture
+	dst := d.textures[0]
+	if src == nil {
+		// The screen is always transparent.
+		for j := 0; j < height; j++ {
+			for i := 0; i < width; i++ {
+				dst.set(x+i, y+j, vec4{})
+			}
+		}
+		return
+	}
+	for j := 0; j < height; j++ {
+		s := 4 * ((sy+j)*src.width + sx)
+		copy(dst.texels[4*((y+j)*dst.width+x):], src.texels[s:s+4*width])
+	}
+}
+
+func (d *Driver) NewFramebuffer(t driver.Texture) (driver.Framebuffer, error) {
+	f := &framebuffer{
+		texture: t.(*texture),
+	}
+	d.framebuffer = f
+	return f, nil
+}
+
+func (d *Driver) DeleteFramebuffer(f driver.Framebuffer) {
+	if d.framebuffer == f.(*framebuffer) {
+		d.framebuffer = d.screenFramebuffer
+	}
+}
+
+func (d *Driver) ScreenFramebuffer() driver.Framebuffer {
+	return d.screenFramebuffer
+}
+
+func (d *Driver) FramebufferPixels(f driver.Framebuffer, format driver.PixelFormat, width, height int) ([]byte, error) {
+	t := f.(*framebuffer).texture
+	switch format {
+	case driver.PixelFormatRGBA8, driver.PixelFormatSRGBA8:
+		// The texels of an sRGB texture are read as they are without decoding.
+		p := make([]byte, 4*width*height)
+		if t == nil {
+			return p, nil
+		}
+		for j := 0; j < height; j++ {
+			for i := 0; i < 4*width; i++ {
+				p[4*j*width+i] = byteValue(float64(t.texels[4*j*t.width+i]))
+			}
+		}
+		return p, nil
+	case driver.PixelFormatRGBA16F:
+		p := make([]byte, 8*width*height)
+		if t == nil {
+			return p, nil
+		}
+		for j := 0; j < height; j++ {
+			for i := 0; i < 4*width; i++ {
+				binary.LittleEndian.PutUint16(p[2*(4*j*width+i):], emath.Float16bits(t.texels[4*j*t.width+i]))
+			}
+		}
+		return p, nil
+	default:
+		panic("not reached")
+	}
+}
+
+func (d *Driver) SetViewport(f driver.Framebuffer, width, height int) {
+	d.framebuffer = f.(*framebuffer)
+	d.viewportWidth = width
+	d.viewportHeight = height
+}
+
+func (d *Driver) ResetViewportSize() {
+}
+
+func (d *Driver) NewDepthRenderbuffer(width, height int) (driver.Renderbuffer, error) {
+	return &renderbuffer{
+		width:  width,
+		height: height,
+		depths: make([]float32, width*height),
+	}, nil
+}
+
+func (d *Driver) DeleteRenderbuffer(r driver.Renderbuffer) {
+}
+
+func (d *Driver) AttachDepthRenderbuffer(f driver.Framebuffer, r driver.Renderbuffer) error {
+	ff := f.(*framebuffer)
+	ff.depth = r.(*renderbuffer)
+	d.framebuffer = ff
+	return nil
+}
+
+func (d *Driver) NewShader(shaderType driver.ShaderType, source string) (driver.Shader, error) {
+	s := &shader{
+		shaderType: shaderType,
+	}
+	if shaderType != driver.FragmentShader {
+		return s, nil
+	}
+	s.variant = variant{
+		filterNearest: strings.Contains(source, "#define FILTER_NEAREST"),
+		filterLinear:  strings.Contains(source, "#define FILTER_LINEAR"),
+		filterScreen:  strings.Contains(source, "#define FILTER_SCREEN"),
+		anisotropic:   strings.Contains(source, "#define FILTER_ANISOTROPIC"),
+		colorLUT:      strings.Contains(source, "#define COLOR_LUT"),
+		colorPalette:  strings.Contains(source, "#define COLOR_PALETTE"),
+		normalMap:     strings.Contains(source, "#define NORMAL_MAP"),
+		distanceField: strings.Contains(source, "#define DISTANCE_FIELD"),
+	}
+	if !s.variant.filterNearest && !s.variant.filterLinear && !s.variant.filterScreen && !s.variant.anisotropic {
+		return nil, errors.New("software: unknown fragment shader")
+	}
+	return s, nil
+}
+
+func (d *Driver) DeleteShader(s driver.Shader) {
+}
+
+func (d *Driver) NewProgram(shaders []driver.Shader, attributes []string) (driver.Program, error) {
+	p := &program{
+		attributes: attributes,
+		uniforms:   map[string][]float32{},
+		pointers:   map[int]*attribPointer{},
+	}
+	for _, s := range shaders {
+		s := s.(*shader)
+		if s.shaderType == driver.FragmentShader {
+			p.variant = s.variant
+		}
+	}
+	return p, nil
+}
+
+func (d *Driver) UseProgram(p driver.Program) {
+	d.program = p.(*program)
+}
+
+func (d *Driver) DeleteProgram(p driver.Program) {
+	if d.program == p.(*program) {
+		d.program = nil
+	}
+}
+
+func (d *Driver) UniformInt(p driver.Program, location string, v int) {
+	p.(*program).uniforms[location] = []float32{float32(v)}
+}
+
+func (d *Driver) UniformFloat(p driver.Program, location string, v float32) {
+	p.(*program).uniforms[location] = []float32{v}
+}
+
+func (d *Driver) UniformFloats(p driver.Program, location string, v []float32) {
+	vs := make([]float32, len(v))
+	copy(vs, v)
+	p.(*program).uniforms[location] = vs
+}
+
+func (d *Driver) NewArrayBuffer(size int) driver.Buffer {
+	b := &buffer{
+		floats: make([]float32, size/4),
+	}
+	d.arrayBuffer = b
+	return b
+}
+
+func (d *Driver) NewElementArrayBuffer(indices []uint16) driver.Buffer {
+	b := &buffer{
+		indices: make([]uint16, len(indices)),
+	}
+	copy(b.indices, indices)
+	d.elementArrayBuffer = b
+	return b
+}
+
+func (d *Driver) BindArrayBuffer(b driver.Buffer) {
+	d.arrayBuffer = b.(*buffer)
+}
+
+func (d *Driver) BindElementArrayBuffer(b driver.Buffer) {
+	d.elementArrayBuffer = b.(*buffer)
+}
+
+func (d *Driver) BufferSubData(bufferType driver.BufferType, data []float32) {
+	if bufferType != driver.ArrayBuffer {
+		panic("not reached")
+	}
+	copy(d.arrayBuffer.floats, data)
+}
+
+func (d *Driver) DeleteBuffer(b driver.Buffer) {
+}
+
+func (d *Driver) VertexAttribPointer(p driver.Program, index int, size int, dataType driver.DataType, stride int, offset int) {
+	if dataType != driver.Float {
+		panic("software: only float vertex attributes are supported")
+	}
+	pp := p.(*program)
+	ptr, ok := pp.pointers[index]
+	if !ok {
+		ptr = &attribPointer{}
+		pp.pointers[index] = ptr
+	}
+	ptr.buffer = d.arrayBuffer
+	ptr.size = size
+	ptr.stride = stride
+	ptr.offset = offset
+}
+
+func (d *Driver) EnableVertexAttribArray(p driver.Program, index int) {
+	d.setVertexAttribArrayEnabled(p.(*program), index, true)
+}
+
+func (d *Driver) DisableVertexAttribArray(p driver.Program, index int) {
+	d.setVertexAttribArrayEnabled(p.(*program), index, false)
+}
+
+func (d *Driver) setVertexAttribArrayEnabled(p *program, index int, enabled bool) {
+	ptr, ok := p.pointers[index]
+	if !ok {
+		ptr = &attribPointer{}
+		p.pointers[index] = ptr
+	}
+	ptr.enabled = enabled
+}
+
+func (d *Driver) VertexAttribDivisor(p driver.Program, index int, divisor int) {
+	panic("software: instancing is not supported")
+}
+
+func (d *Driver) BlendFunc(mode driver.CompositeMode) {
+	d.mode = mode
+}
+
+func (d *Driver) SetScissor(x, y, width, height int) {
+	r := image.Rect(x, y, x+width, y+height)
+	d.scissor = &r
+}
+
+func (d *Driver) DisableScissor() {
+	d.scissor = nil
+}
+
+func (d *Driver) ColorMask(r, g, b, a bool) {
+	d.colorMask = [4]bool{r, g, b, a}
+}
+
+func (d *Driver) SetDepthTest(enabled bool) {
+	d.depthTest = enabled
+}
+
+func (d *Driver) ClearDepth() {
+	r := d.framebuffer.depth
+	if r == nil {
+		return
+	}
+	b := image.Rect(0, 0, r.width, r.height)
+	if d.scissor != nil {
+		b = b.Intersect(*d.scissor)
+	}
+	for j := b.Min.Y; j < b.Max.Y; j++ {
+		for i := b.Min.X; i < b.Max.X; i++ {
+			r.depths[j*r.width+i] = 1
+		}
+	}
+}
+
+func (d *Driver) IsInstancingAvailable() bool {
+	return false
+}
+
+func (d *Driver) DrawElementsInstanced(mode driver.Mode, len int, offsetInBytes int, instanceCount int) {
+	panic("software: instancing is not supported")
+}
+
+func (d *Driver) IsStandardDerivativesAvailable() bool {
+	return true
+}
@@ -0,0 +1,429 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package software
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/internal/driver"
+	emath "github.com/hajimehoshi/ebiten/internal/math"
+)
+
+type vec2 [2]float64
+
+func (v vec2) add(w vec2) vec2 {
+	return vec2{v[0] + w[0], v[1] + w[1]}
+}
+
+func (v vec2) sub(w vec2) vec2 {
+	return vec2{v[0] - w[0], v[1] - w[1]}
+}
+
+func (v vec2) mul(w vec2) vec2 {
+	return vec2{v[0] * w[0], v[1] * w[1]}
+}
+
+func (v vec2) div(w vec2) vec2 {
+	return vec2{v[0] / w[0], v[1] / w[1]}
+}
+
+func (v vec2) scale(s float64) vec2 {
+	return vec2{v[0] * s, v[1] * s}
+}
+
+type vec4 [4]float64
+
+func (v vec4) add(w vec4) vec4 {
+	return vec4{v[0] + w[0], v[1] + w[1], v[2] + w[2], v[3] + w[3]}
+}
+
+func (v vec4) mul(w vec4) vec4 {
+	return vec4{v[0] * w[0], v[1] * w[1], v[2] * w[2], v[3] * w[3]}
+}
+
+func (v vec4) scale(s float64) vec4 {
+	return vec4{v[0] * s, v[1] * s, v[2] * s, v[3] * s}
+}
+
+func (v vec4) clamp(min, max float64) vec4 {
+	return vec4{clamp(v[0], min, max), clamp(v[1], min, max), clamp(v[2], min, max), clamp(v[3], min, max)}
+}
+
+// mat4 is a 4x4 matrix in the column-major order as a uniform matrix of OpenGL.
+type mat4 [16]float64
+
+func (m *mat4) mul(v vec4) vec4 {
+	var r vec4
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			r[i] += m[4*j+i] * v[j]
+		}
+	}
+	return r
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Min(math.Max(v, min), max)
+}
+
+// mod returns x modulo y in the same way as GLSL's mod.
+func mod(x, y float64) float64 {
+	return x - y*math.Floor(x/y)
+}
+
+func fract(x float64) float64 {
+	return x - math.Floor(x)
+}
+
+func mix(x, y vec4, a float64) vec4 {
+	return x.scale(1 - a).add(y.scale(a))
+}
+
+func decodeSRGB(c vec4) vec4 {
+	return vec4{emath.SRGBToLinear(c[0]), emath.SRGBToLinear(c[1]), emath.SRGBToLinear(c[2]), c[3]}
+}
+
+func encodeSRGB(c vec4) vec4 {
+	return vec4{emath.LinearToSRGB(c[0]), emath.LinearToSRGB(c[1]), emath.LinearToSRGB(c[2]), c[3]}
+}
+
+func (p *program) uniformFloat(name string) float64 {
+	u := p.uniforms[name]
+	if len(u) == 0 {
+		return 0
+	}
+	return float64(u[0])
+}
+
+func (p *program) uniformInt(name string) int {
+	return int(p.uniformFloat(name))
+}
+
+func (p *program) uniformVec2(name string) vec2 {
+	var v vec2
+	for i, u := range p.uniforms[name] {
+		v[i] = float64(u)
+	}
+	return v
+}
+
+func (p *program) uniformVec4(name string) vec4 {
+	var v vec4
+	for i, u := range p.uniforms[name] {
+		v[i] = float64(u)
+	}
+	return v
+}
+
+func (p *program) uniformMat4(name string) *mat4 {
+	var m mat4
+	for i, u := range p.uniforms[name] {
+		m[i] = float64(u)
+	}
+	return &m
+}
+
+// sampler returns the texture bound to the texture unit specified by the sampler uniform variable.
+func (d *Driver) sampler(p *program, name string) *texture {
+	return d.textures[p.uniformInt(name)]
+}
+
+// texture2D samples the texture at the normalized position p with the nearest filter and the clamp-to-edge wrapping
+// as the OpenGL driver does.
+func texture2D(t *texture, p vec2) vec4 {
+	if t == nil {
+		return vec4{0, 0, 0, 1}
+	}
+	x := int(clamp(math.Floor(p[0]*float64(t.width)), 0, float64(t.width-1)))
+	y := int(clamp(math.Floor(p[1]*float64(t.height)), 0, float64(t.height-1)))
+	return t.at(x, y)
+}
+
+// roundTexel adjusts p by rounding it, as p might include a very slight error.
+func roundTexel(p vec2) vec2 {
+	const factor = 1.0 / 32768.0
+	p[0] -= mod(p[0]+factor*0.5, factor) - factor*0.5
+	p[1] -= mod(p[1]+factor*0.5, factor) - factor*0.5
+	return p
+}
+
+// adjustTexelByAddress adjusts the texel position p out of the region (tmin, tmax) by the address mode.
+func adjustTexelByAddress(p, tmin, tmax, texelSize vec2, address driver.Address) vec2 {
+	for i := 0; i < 2; i++ {
+		switch address {
+		case driver.AddressClampToEdge:
+			p[i] = clamp(p[i], tmin[i], tmax[i]-texelSize[i]/256)
+		case driver.AddressRepeat:
+			p[i] = tmin[i] + mod(p[i]-tmin[i], tmax[i]-tmin[i])
+		case driver.AddressMirrorRepeat:
+			size := tmax[i] - tmin[i]
+			q := mod(p[i]-tmin[i], 2*size)
+			// Mirror every other repetition.
+			if size <= q {
+				q = 2*size - q - texelSize[i]/256
+			}
+			p[i] = tmin[i] + q
+		}
+	}
+	return p
+}
+
+// sampleLinear returns the bilinearly interpolated color at the texel position pos in the region (tmin, tmax).
+func sampleLinear(tex *texture, pos, tmin, tmax, texelSize, sourceSize vec2, address driver.Address) vec4 {
+	p0 := pos.sub(texelSize.scale(0.5))
+	p1 := pos.add(texelSize.scale(0.5))
+	q0 := adjustTexelByAddress(p0, tmin, tmax, texelSize, address)
+	q1 := adjustTexelByAddress(p1, tmin, tmax, texelSize, address)
+	c0 := texture2D(tex, q0)
+	c1 := texture2D(tex, vec2{q1[0], q0[1]})
+	c2 := texture2D(tex, vec2{q0[0], q1[1]})
+	c3 := texture2D(tex, q1)
+	if address == driver.AddressClampToZero {
+		if p0[0] < tmin[0] {
+			c0, c2 = vec4{}, vec4{}
+		}
+		if p0[1] < tmin[1] {
+			c0, c1 = vec4{}, vec4{}
+		}
+		if tmax[0]-texelSize[0]/256 <= p1[0] {
+			c1, c3 = vec4{}, vec4{}
+		}
+		if tmax[1]-texelSize[1]/256 <= p1[1] {
+			c2, c3 = vec4{}, vec4{}
+		}
+	}
+	rate := p0.mul(sourceSize)
+	return mix(mix(c0, c1, fract(rate[0])), mix(c2, c3, fract(rate[0])), fract(rate[1]))
+}
+
+// fragment processes a fragment in the same way as the fragment shader of the graphics package.
+//
+// fragment returns the premultiplied color, and false if the fragment is discarded.
+func (d *Driver) fragment(p *program, v *varyings) (vec4, bool) {
+	// pos might include a very slight error.
+	pos := roundTexel(v.texCoord)
+
+	sourceSize := p.uniformVec2("source_size")
+	texelSize := vec2{1 / sourceSize[0], 1 / sourceSize[1]}
+
+	address := driver.Address(p.uniformInt("address"))
+	tmin := v.texCoordMin
+	tmax := v.texCoordMax
+	if address.Wraps() {
+		// The whole source image is wrapped. Such an image is always at the origin of the texture.
+		tmin = vec2{0, 0}
+		tmax = p.uniformVec2("source_image_size").div(sourceSize)
+	}
+
+	tex := d.sampler(p, "texture")
+	var color vec4
+	var distance float64
+	switch {
+	case p.variant.filterNearest:
+		color = texture2D(tex, adjustTexelByAddress(pos, tmin, tmax, texelSize, address))
+		if p.variant.colorPalette {
+			color = d.lookUpPalette(p, color[3])
+		}
+		if address == driver.AddressClampToZero &&
+			(pos[0] < tmin[0] ||
+				pos[1] < tmin[1] ||
+				tmax[0]-texelSize[0]/256 <= pos[0] ||
+				tmax[1]-texelSize[1]/256 <= pos[1]) {
+			color = vec4{}
+		}
+
+	case p.variant.filterLinear:
+		color = sampleLinear(tex, pos, tmin, tmax, texelSize, sourceSize, address)
+		if p.variant.distanceField {
+			// The alpha value is the distance. The shape is filled with white, which is then tinted by the color matrix.
+			distance = color[3]
+			color = vec4{1, 1, 1, 1}
+		}
+
+	case p.variant.anisotropic:
+		// Cover the footprint of the destination pixel by linear samples along its longer axis.
+		axis := v.texCoordDx
+		if math.Hypot(axis[0]*sourceSize[0], axis[1]*sourceSize[1]) < math.Hypot(v.texCoordDy[0]*sourceSize[0], v.texCoordDy[1]*sourceSize[1]) {
+			axis = v.texCoordDy
+		}
+		n := clamp(math.Ceil(math.Hypot(axis[0]*sourceSize[0], axis[1]*sourceSize[1])), 1, p.uniformFloat("max_anisotropy"))
+		for i := 0; float64(i) < n; i++ {
+			q := pos.add(axis.scale((float64(i)+0.5)/n - 0.5))
+			color = color.add(sampleLinear(tex, q, tmin, tmax, texelSize, sourceSize, address))
+		}
+		color = color.scale(1 / n)
+
+	case p.variant.filterScreen:
+		scale := p.uniformFloat("scale")
+		p0 := pos.sub(texelSize.scale(0.5 / scale))
+		p1 := pos.add(texelSize.scale(0.5 / scale))
+		c0 := texture2D(tex, p0)
+		c1 := texture2D(tex, vec2{p1[0], p0[1]})
+		c2 := texture2D(tex, vec2{p0[0], p1[1]})
+		c3 := texture2D(tex, p1)
+		var rate vec2
+		for i := 0; i < 2; i++ {
+			center := 1 - texelSize[i]/2/scale
+			rate[i] = clamp((fract(p0[i]*sourceSize[i])-center)*scale+center, 0, 1)
+		}
+		color = mix(mix(c0, c1, rate[0]), mix(c2, c3, rate[0]), rate[1])
+	}
+
+	// Un-premultiply alpha
+	if 0 < color[3] {
+		for i := 0; i < 3; i++ {
+			color[i] /= color[3]
+		}
+	}
+	// The texels of an sRGB texture are decoded into the linear color space when sampled.
+	// Encode them so that the color matrix is always applied in the sRGB color space.
+	if p.uniformInt("source_srgb") != 0 {
+		color = encodeSRGB(color)
+	}
+	// Apply the tint and the color matrix
+	color = color.mul(v.colorScale).add(v.colorTranslate)
+	color = p.uniformMat4("color_matrix").mul(color).add(p.uniformVec4("color_matrix_translation"))
+	color = color.clamp(0, 1)
+	if p.variant.distanceField {
+		color = distanceFieldColor(p, color, distance)
+	}
+	if p.variant.normalMap {
+		// The normal map is at the same position of its texture as the source image.
+		n := texture2D(d.sampler(p, "normal_map"), pos)
+		l := lightNormal(p, [3]float64{n[0]*2 - 1, n[1]*2 - 1, n[2]*2 - 1}, v.dstPos)
+		for i := 0; i < 3; i++ {
+			color[i] = clamp(color[i]*l[i], 0, 1)
+		}
+	}
+	if p.variant.colorLUT {
+		color = d.lookUpLUT(p, color)
+	}
+	// The colors are encoded when they are written to an sRGB texture, and blending is done in the linear color space.
+	if p.uniformInt("destination_srgb") != 0 {
+		color = decodeSRGB(color)
+	}
+	// Premultiply alpha
+	for i := 0; i < 3; i++ {
+		color[i] *= color[3]
+	}
+
+	if p.uniformInt("depth_test") != 0 && color[3] == 0 {
+		// Transparent fragments must not update the depth buffer.
+		return vec4{}, false
+	}
+	return color, true
+}
+
+// lookUpPalette returns the color of the palette entry at the index, which is the alpha value of an alpha-only texel.
+func (d *Driver) lookUpPalette(p *program, index float64) vec4 {
+	i := math.Floor(index*255 + 0.5)
+	// Sample at the center of the texel.
+	return texture2D(d.sampler(p, "palette"), vec2{i + 0.5, 0.5}.div(p.uniformVec2("palette_texture_size")))
+}
+
+// lookUpLUTTile returns the bilinearly interpolated color at (r, g) in the tile for the blue entry b.
+func (d *Driver) lookUpLUTTile(p *program, b float64, rg vec2) vec4 {
+	lut := d.sampler(p, "lut")
+	size := p.uniformFloat("lut_size")
+	textureSize := p.uniformVec2("lut_texture_size")
+
+	p0 := vec2{math.Floor(rg[0]), math.Floor(rg[1])}
+	p1 := vec2{math.Min(p0[0]+1, size-1), math.Min(p0[1]+1, size-1)}
+	rate := rg.sub(p0)
+	// Sample at the centers of the texels.
+	offset := vec2{b*size + 0.5, 0.5}
+	c0 := texture2D(lut, offset.add(p0).div(textureSize))
+	c1 := texture2D(lut, offset.add(vec2{p1[0], p0[1]}).div(textureSize))
+	c2 := texture2D(lut, offset.add(vec2{p0[0], p1[1]}).div(textureSize))
+	c3 := texture2D(lut, offset.add(p1).div(textureSize))
+	return mix(mix(c0, c1, rate[0]), mix(c2, c3, rate[0]), rate[1])
+}
+
+// lookUpLUT returns the color converted by the LUT. The alpha value is kept.
+func (d *Driver) lookUpLUT(p *program, c vec4) vec4 {
+	size := p.uniformFloat("lut_size")
+	r, g, b := c[0]*(size-1), c[1]*(size-1), c[2]*(size-1)
+	b0 := math.Floor(b)
+	b1 := math.Min(b0+1, size-1)
+	l := mix(d.lookUpLUTTile(p, b0, vec2{r, g}), d.lookUpLUTTile(p, b1, vec2{r, g}), b-b0)
+	return vec4{l[0], l[1], l[2], c[3]}
+}
+
+// lightNormal returns the sum of the lights on the surface with the normal n at the destination position dst.
+func lightNormal(p *program, n [3]float64, dst vec2) [3]float64 {
+	// The normal's Y axis points up while the destination's Y axis points down.
+	n[1] = -n[1]
+	if l := math.Sqrt(n[0]*n[0] + n[1]*n[1] + n[2]*n[2]); l != 0 {
+		n = [3]float64{n[0] / l, n[1] / l, n[2] / l}
+	}
+
+	ambient := p.uniformVec4("ambient_color")
+	positions := p.uniformMat4("light_positions")
+	colors := p.uniformMat4("light_colors")
+	c := [3]float64{ambient[0], ambient[1], ambient[2]}
+	for i := 0; i < 4; i++ {
+		pos := positions[4*i : 4*i+4]
+		clr := colors[4*i : 4*i+4]
+		l := [3]float64{pos[0] - dst[0]*pos[3], pos[1] - dst[1]*pos[3], pos[2]}
+		dist := math.Sqrt(l[0]*l[0] + l[1]*l[1] + l[2]*l[2])
+		if dist == 0 {
+			continue
+		}
+		attenuation := 1.0
+		if 0 < pos[3] && 0 < clr[3] {
+			attenuation = clamp(1-dist/clr[3], 0, 1)
+		}
+		diffuse := math.Max((n[0]*l[0]+n[1]*l[1]+n[2]*l[2])/dist, 0) * attenuation
+		for j := 0; j < 3; j++ {
+			c[j] += clr[j] * diffuse
+		}
+	}
+	return c
+}
+
+// over composites the non-premultiplied color top over bottom.
+func over(top, bottom vec4) vec4 {
+	a := top[3] + bottom[3]*(1-top[3])
+	if a == 0 {
+		return vec4{}
+	}
+	var c vec4
+	for i := 0; i < 3; i++ {
+		c[i] = (top[i]*top[3] + bottom[i]*bottom[3]*(1-top[3])) / a
+	}
+	c[3] = a
+	return c
+}
+
+// distanceFieldColor returns the non-premultiplied color of the shape filled with fill, the outline and the glow
+// at the distance value a.
+func distanceFieldColor(p *program, fill vec4, a float64) vec4 {
+	df := p.uniformVec4("distance_field")
+	d := (a - 0.5) * df[0]
+	scale, outline, glow := df[1], df[2], df[3]
+	// Antialias the edges by the coverage of a destination pixel.
+	color := vec4{fill[0], fill[1], fill[2], fill[3] * clamp(d*scale+0.5, 0, 1)}
+	if 0 < outline {
+		c := p.uniformVec4("outline_color")
+		c[3] *= clamp((d+outline)*scale+0.5, 0, 1)
+		color = over(color, c)
+	}
+	if 0 < glow {
+		c := p.uniformVec4("glow_color")
+		c[3] *= clamp(1+(d+outline)/glow, 0, 1)
+		color = over(color, c)
+	}
+	return color
+}
@@ -12,11 +12,16 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// +build !ebitenheadless !linux
+
 // Package testflock provides a lock for testing.
 //
 // There is a CI service like TravisCI where multiple OpenGL processes
 // don't work well at the same time, and tests with an OpenGL main routine
 // should be protected by a file lock (#575).
+//
+// In the headless mode (the build tag ebitenheadless on Linux), the lock does nothing
+// since each process has its own surfaceless context.
 package testflock
 
 import (
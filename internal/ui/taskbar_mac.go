@@ -0,0 +1,75 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// +build darwin
+// +build !js
+// +build !ios
+// +build !ebitenexternal
+
+package ui
+
+// #cgo CFLAGS: -x objective-c
+// #cgo LDFLAGS: -framework AppKit
+//
+// #import <AppKit/AppKit.h>
+//
+// static void requestAttention() {
+//   [NSApp requestUserAttention:NSInformationalRequest];
+// }
+//
+// static NSProgressIndicator* progressIndicator;
+//
+// static void setProgress(int visible, double progress) {
+//   NSDockTile* dockTile = [NSApp dockTile];
+//   if (!visible) {
+//     if (progressIndicator) {
+//       [dockTile setContentView:nil];
+//       [dockTile display];
+//     }
+//     return;
+//   }
+//   if (![dockTile contentView]) {
+//     // The content view replaces the application icon, so show the icon behind the progress bar.
+//     NSImageView* imageView = [[NSImageView alloc] initWithFrame:NSMakeRect(0, 0, dockTile.size.width, dockTile.size.height)];
+//     [imageView setImage:[NSApp applicationIconImage]];
+//     if (!progressIndicator) {
+//       progressIndicator = [[NSProgressIndicator alloc] initWithFrame:NSMakeRect(0, 0, dockTile.size.width, 16)];
+//       [progressIndicator setStyle:NSProgressIndicatorStyleBar];
+//       [progressIndicator setIndeterminate:NO];
+//       [progressIndicator setMinValue:0];
+//       [progressIndicator setMaxValue:1];
+//     }
+//     [imageView addSubview:progressIndicator];
+//     [dockTile setContentView:imageView];
+//     [imageView release];
+//   }
+//   [progressIndicator setDoubleValue:progress];
+//   [dockTile display];
+// }
+import "C"
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+func requestWindowAttention(window *glfw.Window) {
+	C.requestAttention()
+}
+
+func setWindowProgress(window *glfw.Window, progress float64) {
+	if progress < 0 {
+		C.setProgress(0, 0)
+		return
+	}
+	C.setProgress(1, C.double(progress))
+}
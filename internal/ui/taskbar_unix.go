@@ -0,0 +1,55 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// +build freebsd linux
+// +build !js
+// +build !android
+// +build !ebitenheadless !linux
+// +build !ebitenexternal
+
+package ui
+
+// #cgo LDFLAGS: -lX11
+//
+// #include <string.h>
+// #include <X11/Xlib.h>
+//
+// static void requestAttention(Display* display, Window window) {
+//   // Add _NET_WM_STATE_DEMANDS_ATTENTION to the window state (EWMH).
+//   XEvent event;
+//   memset(&event, 0, sizeof(event));
+//   event.type = ClientMessage;
+//   event.xclient.window = window;
+//   event.xclient.format = 32;
+//   event.xclient.message_type = XInternAtom(display, "_NET_WM_STATE", False);
+//   event.xclient.data.l[0] = 1; // _NET_WM_STATE_ADD
+//   event.xclient.data.l[1] = XInternAtom(display, "_NET_WM_STATE_DEMANDS_ATTENTION", False);
+//   event.xclient.data.l[3] = 1; // A normal application
+//   XSendEvent(display, DefaultRootWindow(display), False, SubstructureNotifyMask | SubstructureRedirectMask, &event);
+//   XFlush(display);
+// }
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+func requestWindowAttention(window *glfw.Window) {
+	C.requestAttention((*C.Display)(unsafe.Pointer(glfw.GetX11Display())), C.Window(window.GetX11Window()))
+}
+
+func setWindowProgress(window *glfw.Window, progress float64) {
+	// There is no standard way to show a progress on X11.
+}
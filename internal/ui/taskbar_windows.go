@@ -0,0 +1,80 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// +build !js
+// +build !ebitenexternal
+
+package ui
+
+// #cgo LDFLAGS: -lole32 -luuid
+//
+// #define COBJMACROS
+// #include <windows.h>
+// #include <shobjidl.h>
+//
+// static void flashWindow(HWND hwnd) {
+//   FLASHWINFO info = {0};
+//   info.cbSize = sizeof(info);
+//   info.hwnd = hwnd;
+//   info.dwFlags = FLASHW_TRAY | FLASHW_TIMERNOFG;
+//   FlashWindowEx(&info);
+// }
+//
+// static ITaskbarList3* taskbarList;
+//
+// static void setProgress(HWND hwnd, int visible, ULONGLONG completed, ULONGLONG total) {
+//   if (!taskbarList) {
+//     // CoInitializeEx fails when COM is already initialized in another mode, which is fine.
+//     CoInitializeEx(NULL, COINIT_APARTMENTTHREADED);
+//     if (FAILED(CoCreateInstance(&CLSID_TaskbarList, NULL, CLSCTX_INPROC_SERVER, &IID_ITaskbarList3, (void**)&taskbarList))) {
+//       taskbarList = NULL;
+//       return;
+//     }
+//     if (FAILED(ITaskbarList3_HrInit(taskbarList))) {
+//       ITaskbarList3_Release(taskbarList);
+//       taskbarList = NULL;
+//       return;
+//     }
+//   }
+//   if (!visible) {
+//     ITaskbarList3_SetProgressState(taskbarList, hwnd, TBPF_NOPROGRESS);
+//     return;
+//   }
+//   ITaskbarList3_SetProgressState(taskbarList, hwnd, TBPF_NORMAL);
+//   ITaskbarList3_SetProgressValue(taskbarList, hwnd, completed, total);
+// }
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+const progressTotal = 10000
+
+func hwnd(window *glfw.Window) C.HWND {
+	return C.HWND(unsafe.Pointer(window.GetWin32Window()))
+}
+
+func requestWindowAttention(window *glfw.Window) {
+	C.flashWindow(hwnd(window))
+}
+
+func setWindowProgress(window *glfw.Window, progress float64) {
+	if progress < 0 {
+		C.setProgress(hwnd(window), 0, 0, 0)
+		return
+	}
+	C.setProgress(hwnd(window), 1, C.ULONGLONG(progress*progressTotal), progressTotal)
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// +build darwin
+// +build !js
+// +build !ios
+// +build !ebitenexternal
+
+package ui
+
+// #cgo CFLAGS: -x objective-c
+// #cgo LDFLAGS: -framework AppKit
+//
+// #import <AppKit/AppKit.h>
+// #include <stdint.h>
+//
+// static void setTransparent(uintptr_t window, uintptr_t context) {
+//   NSWindow* w = (NSWindow*)window;
+//   [w setOpaque:NO];
+//   [w setBackgroundColor:[NSColor clearColor]];
+//   GLint opacity = 0;
+//   [(NSOpenGLContext*)context setValues:&opacity forParameter:NSOpenGLCPSurfaceOpacity];
+// }
+import "C"
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// setWindowTransparent must be called from the main thread.
+func setWindowTransparent(window *glfw.Window) {
+	C.setTransparent(C.uintptr_t(window.GetCocoaWindow()), C.uintptr_t(window.GetNSGLContext()))
+}
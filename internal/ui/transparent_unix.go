@@ -0,0 +1,29 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// +build freebsd linux
+// +build !js
+// +build !android
+// +build !ebitenheadless !linux
+// +build !ebitenexternal
+
+package ui
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+func setWindowTransparent(window *glfw.Window) {
+	// A transparent window requires a visual with the alpha channel, which GLFW 3.2 can't choose on X11.
+	// TODO: Use GLFW_TRANSPARENT_FRAMEBUFFER when GLFW 3.3 is available.
+}
@@ -0,0 +1,47 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// +build !js
+// +build !ebitenexternal
+
+package ui
+
+// #cgo LDFLAGS: -ldwmapi -lgdi32
+//
+// #include <windows.h>
+// #include <dwmapi.h>
+//
+// static void setTransparent(HWND hwnd) {
+//   BOOL composition = FALSE;
+//   if (FAILED(DwmIsCompositionEnabled(&composition)) || !composition) {
+//     return;
+//   }
+//   // Blurring behind an empty region makes the window composed with its alpha channel without blurring.
+//   HRGN region = CreateRectRgn(0, 0, -1, -1);
+//   DWM_BLURBEHIND bb = {0};
+//   bb.dwFlags = DWM_BB_ENABLE | DWM_BB_BLURREGION;
+//   bb.fEnable = TRUE;
+//   bb.hRgnBlur = region;
+//   DwmEnableBlurBehindWindow(hwnd, &bb);
+//   DeleteObject(region);
+// }
+import "C"
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// setWindowTransparent must be called from the main thread.
+func setWindowTransparent(window *glfw.Window) {
+	C.setTransparent(hwnd(window))
+}
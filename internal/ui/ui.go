@@ -22,6 +22,29 @@ type GraphicsContext interface {
 	SetSize(width, height int, scale float64)
 	Update(afterFrameUpdate func()) error
 	Invalidate()
+
+	// NeedsPresenting reports whether the screen framebuffer is rendered at the last Update.
+	// If NeedsPresenting returns false, the UI doesn't have to present the screen framebuffer.
+	NeedsPresenting() bool
+
+	// ResetGLStateCache discards the cached OpenGL state.
+	// This is called when the OpenGL context is shared with the host application.
+	ResetGLStateCache()
+}
+
+// Window is an additional window beside the main window.
+type Window interface {
+	// Size returns the screen size in device-independent pixels and the scale from the size to the framebuffer's pixels.
+	Size() (width, height int, scale float64)
+
+	// IsClosed reports whether the window is closed by Close or by the user.
+	IsClosed() bool
+
+	Close()
+
+	// Render calls f with the window's framebuffer as the screen framebuffer, and then presents the framebuffer.
+	// The window might have its own OpenGL context, which is current only while f is called.
+	Render(f func() error) error
 }
 
 // RegularTermination represents a regular termination.
@@ -0,0 +1,445 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ebitenexternal
+// +build darwin freebsd linux windows
+// +build !js
+// +build !android
+// +build !ios
+
+package ui
+
+import (
+	"errors"
+	"image"
+	"runtime"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/internal/input"
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+)
+
+// userInterface is a user interface driven by the host application.
+//
+// In external mode, the host application owns the window and the OpenGL context, and calls Step every frame.
+// The game loop runs on its own goroutine, and the OpenGL functions are called on the host's thread in Step.
+type userInterface struct {
+	width  int
+	height int
+	scale  float64
+
+	title                string
+	running              bool
+	sizeChanged          bool
+	runnableInBackground bool
+	vsync                bool
+
+	framebuffer        uint32
+	framebufferChanged bool
+
+	// renderToTexture indicates whether the game is rendered to the texture owned by Ebiten
+	// instead of the host's framebuffer.
+	renderToTexture bool
+
+	// texture and textureFramebuffer are the texture that the game is rendered to and its framebuffer.
+	// These are 0 when the game is not rendered to a texture.
+	texture            opengl.Texture
+	textureFramebuffer opengl.Framebuffer
+
+	m sync.Mutex
+}
+
+var (
+	currentUI = &userInterface{
+		vsync:              true,
+		framebufferChanged: true,
+	}
+
+	funcs       = make(chan func())
+	renderCh    = make(chan struct{})
+	renderChEnd = make(chan struct{})
+)
+
+// Step runs one frame of the game on the current thread, where the host's OpenGL context must be current.
+//
+// chError is the channel returned by RunWithoutMainLoop.
+func Step(chError <-chan error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if chError == nil {
+		return errors.New("ui: chError must not be nil")
+	}
+	select {
+	case renderCh <- struct{}{}:
+	case err := <-chError:
+		// chError returns a value not only when an error occur but also it is closed.
+		return err
+	}
+	for {
+		select {
+		case f := <-funcs:
+			f()
+		case <-renderChEnd:
+			return nil
+		case err := <-chError:
+			return err
+		}
+	}
+}
+
+// SetFramebuffer sets the host's framebuffer object that the game renders to.
+func SetFramebuffer(framebuffer uint32) {
+	u := currentUI
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.framebuffer == framebuffer && !u.renderToTexture {
+		return
+	}
+	u.framebuffer = framebuffer
+	u.renderToTexture = false
+	u.framebufferChanged = true
+}
+
+// RenderToTexture makes the game render to a texture owned by Ebiten instead of the host's framebuffer.
+func RenderToTexture() {
+	u := currentUI
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.renderToTexture {
+		return
+	}
+	u.renderToTexture = true
+	u.framebufferChanged = true
+}
+
+// Texture returns the texture that the game is rendered to. Texture returns 0 if there is no such texture.
+func Texture() uint32 {
+	u := currentUI
+	u.m.Lock()
+	t := u.texture
+	u.m.Unlock()
+	return uint32(t)
+}
+
+func RunMainThreadLoop(ch <-chan error) error {
+	return errors.New("ui: the main loop is owned by the host application in external mode")
+}
+
+func (u *userInterface) isRunning() bool {
+	u.m.Lock()
+	v := u.running
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setRunning(running bool) {
+	u.m.Lock()
+	u.running = running
+	u.m.Unlock()
+}
+
+func (u *userInterface) runOnMainThread(f func() error) error {
+	// The OpenGL functions are called only in update, which is run in Step.
+	ch := make(chan struct{})
+	var err error
+	funcs <- func() {
+		err = f()
+		close(ch)
+	}
+	<-ch
+	return err
+}
+
+func SetScreenSize(width, height int) bool {
+	return currentUI.setScreenSize(width, height, currentUI.getScale())
+}
+
+func SetScreenScale(scale float64) bool {
+	u := currentUI
+	u.m.Lock()
+	w, h := u.width, u.height
+	u.m.Unlock()
+	return u.setScreenSize(w, h, scale)
+}
+
+func ScreenScale() float64 {
+	return currentUI.getScale()
+}
+
+func (u *userInterface) getScale() float64 {
+	u.m.Lock()
+	s := u.scale
+	u.m.Unlock()
+	return s
+}
+
+func (u *userInterface) setScreenSize(width, height int, scale float64) bool {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.width == width && u.height == height && u.scale == scale {
+		return false
+	}
+	u.width = width
+	u.height = height
+	u.scale = scale
+	u.sizeChanged = true
+	return true
+}
+
+func IsFullscreen() bool {
+	return false
+}
+
+func SetFullscreen(fullscreen bool) {
+	// Do nothing
+}
+
+func IsFocused() bool {
+	return currentUI.isRunning()
+}
+
+func SetRunnableInBackground(runnableInBackground bool) {
+	u := currentUI
+	u.m.Lock()
+	u.runnableInBackground = runnableInBackground
+	u.m.Unlock()
+}
+
+func IsRunnableInBackground() bool {
+	u := currentUI
+	u.m.Lock()
+	v := u.runnableInBackground
+	u.m.Unlock()
+	return v
+}
+
+func SetVsyncEnabled(enabled bool) {
+	// The host application decides when frames are presented.
+	u := currentUI
+	u.m.Lock()
+	u.vsync = enabled
+	u.m.Unlock()
+}
+
+func IsVsyncEnabled() bool {
+	u := currentUI
+	u.m.Lock()
+	v := u.vsync
+	u.m.Unlock()
+	return v
+}
+
+func RefreshRate() int {
+	return 0
+}
+
+func CanSkipPresenting() bool {
+	// The host application presents the framebuffer.
+	return false
+}
+
+func SetWindowIcon(iconImages []image.Image) {
+	// Do nothing
+}
+
+func SetWindowSizeLimits(minw, minh, maxw, maxh int) {
+	// Do nothing
+}
+
+func WindowSizeLimits() (minw, minh, maxw, maxh int) {
+	return -1, -1, -1, -1
+}
+
+func SetWindowAspectRatioLocked(locked bool) {
+	// Do nothing
+}
+
+func IsWindowAspectRatioLocked() bool {
+	return false
+}
+
+func RequestWindowAttention() {
+	// Do nothing
+}
+
+func SetWindowProgress(progress float64) {
+	// Do nothing
+}
+
+func SetWindowTitle(title string) {
+	u := currentUI
+	u.m.Lock()
+	u.title = title
+	u.m.Unlock()
+}
+
+func OutsideSize() (width, height int) {
+	// The host's framebuffer is regarded as the window.
+	u := currentUI
+	u.m.Lock()
+	width = int(float64(u.width) * u.scale)
+	height = int(float64(u.height) * u.scale)
+	u.m.Unlock()
+	return
+}
+
+func ScreenPadding() (x0, y0, x1, y1 float64) {
+	return 0, 0, 0, 0
+}
+
+func SafeAreaInsets() (left, top, right, bottom float64) {
+	return 0, 0, 0, 0
+}
+
+func AdjustedCursorPosition() (x, y int) {
+	return input.Get().CursorPosition()
+}
+
+func AdjustedTouches() []*input.Touch {
+	return input.Get().Touches()
+}
+
+func IsCursorVisible() bool {
+	return false
+}
+
+func SetCursorVisible(visible bool) {
+	// Do nothing
+}
+
+func IsWindowFloating() bool {
+	return false
+}
+
+func SetWindowFloating(floating bool) {
+	// Do nothing
+}
+
+func IsScreenTransparent() bool {
+	return false
+}
+
+func SetScreenTransparent(transparent bool) {
+	// Do nothing
+}
+
+func IsWindowDecorated() bool {
+	return false
+}
+
+func SetWindowDecorated(decorated bool) {
+	// Do nothing
+}
+
+func NewWindow(width, height int, scale float64, title string) (Window, error) {
+	return nil, errors.New("ui: multiple windows are not supported in external mode")
+}
+
+func Run(width, height int, scale float64, title string, g GraphicsContext, mainloop bool) error {
+	u := currentUI
+	opengl.Init(u.runOnMainThread)
+	u.setScreenSize(width, height, scale)
+	if title != "" {
+		SetWindowTitle(title)
+	}
+
+	u.setRunning(true)
+	defer func() {
+		u.setRunning(false)
+	}()
+	for {
+		if err := u.update(g); err != nil {
+			return err
+		}
+	}
+}
+
+func (u *userInterface) updateGraphicsContext(g GraphicsContext) error {
+	u.m.Lock()
+	sizeChanged := u.sizeChanged || u.framebufferChanged
+	u.sizeChanged = false
+	u.framebufferChanged = false
+	w, h, s := u.width, u.height, u.scale
+	f := u.framebuffer
+	toTexture := u.renderToTexture
+	u.m.Unlock()
+	if !sizeChanged {
+		return nil
+	}
+
+	u.deleteTexture()
+	if toTexture {
+		t, tf, err := opengl.GetContext().NewScreenTexture(int(float64(w)*s), int(float64(h)*s))
+		if err != nil {
+			return err
+		}
+		u.m.Lock()
+		u.texture = t
+		u.textureFramebuffer = tf
+		u.m.Unlock()
+		f = uint32(tf)
+	}
+	opengl.GetContext().SetScreenFramebuffer(f)
+
+	// The screen image is recreated with the new screen framebuffer.
+	g.SetSize(w, h, s)
+	return nil
+}
+
+func (u *userInterface) deleteTexture() {
+	u.m.Lock()
+	t, f := u.texture, u.textureFramebuffer
+	u.texture = 0
+	u.textureFramebuffer = 0
+	u.m.Unlock()
+	if t == 0 {
+		return
+	}
+	opengl.GetContext().DeleteFramebuffer(f)
+	opengl.GetContext().DeleteTexture(t)
+}
+
+func (u *userInterface) update(g GraphicsContext) error {
+	<-renderCh
+	defer func() {
+		renderChEnd <- struct{}{}
+	}()
+
+	// The host application might change the OpenGL state between frames.
+	g.ResetGLStateCache()
+	if err := u.updateGraphicsContext(g); err != nil {
+		return err
+	}
+	var sizeErr error
+	if err := g.Update(func() {
+		if sizeErr == nil {
+			sizeErr = u.updateGraphicsContext(g)
+		}
+	}); err != nil {
+		return err
+	}
+	if sizeErr != nil {
+		return sizeErr
+	}
+
+	u.m.Lock()
+	toTexture := u.texture != 0
+	u.m.Unlock()
+	if toTexture {
+		// Flush the commands so that the texture can be used in another context sharing objects.
+		opengl.GetContext().Flush()
+	}
+	return nil
+}
@@ -16,6 +16,8 @@
 // +build !js
 // +build !android
 // +build !ios
+// +build !ebitenheadless !linux
+// +build !ebitenexternal
 
 package ui
 
@@ -48,12 +50,27 @@ type userInterface struct {
 	origPosX             int
 	origPosY             int
 	runnableInBackground bool
+	vsync                bool
+	refreshRate          int
 
 	initFullscreen      bool
 	initCursorVisible   bool
 	initWindowDecorated bool
+	initWindowFloating  bool
+	initTransparent     bool
 	initIconImages      []image.Image
 
+	// minWindowWidth, minWindowHeight, maxWindowWidth and maxWindowHeight are the window size limits
+	// in device-independent pixels. A negative value means no limit.
+	minWindowWidth    int
+	minWindowHeight   int
+	maxWindowWidth    int
+	maxWindowHeight   int
+	aspectRatioLocked bool
+
+	// windows are the additional windows. windows must be accessed from the main thread.
+	windows []*window
+
 	funcs chan func()
 
 	m sync.Mutex
@@ -65,6 +82,11 @@ var (
 		origPosY:            -1,
 		initCursorVisible:   true,
 		initWindowDecorated: true,
+		vsync:               true,
+		minWindowWidth:      -1,
+		minWindowHeight:     -1,
+		maxWindowWidth:      -1,
+		maxWindowHeight:     -1,
 	}
 	currentUIInitialized = make(chan struct{})
 )
@@ -88,12 +110,21 @@ func initialize() error {
 	}
 	glfw.WindowHint(glfw.Decorated, decorated)
 
+	floating := glfw.False
+	if currentUI.isInitWindowFloating() {
+		floating = glfw.True
+	}
+	glfw.WindowHint(glfw.Floating, floating)
+
 	// As start, create an window with temporary size to create OpenGL context thread.
 	window, err := glfw.CreateWindow(16, 16, "", nil, nil)
 	if err != nil {
 		return err
 	}
 	hideConsoleWindowOnWindows()
+	if currentUI.isInitTransparent() {
+		setWindowTransparent(window)
+	}
 	currentUI.window = window
 	currentUI.funcs = make(chan func())
 
@@ -189,6 +220,32 @@ func (u *userInterface) setInitWindowDecorated(decorated bool) {
 	u.m.Unlock()
 }
 
+func (u *userInterface) isInitWindowFloating() bool {
+	u.m.Lock()
+	v := u.initWindowFloating
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setInitWindowFloating(floating bool) {
+	u.m.Lock()
+	u.initWindowFloating = floating
+	u.m.Unlock()
+}
+
+func (u *userInterface) isInitTransparent() bool {
+	u.m.Lock()
+	v := u.initTransparent
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setInitTransparent(transparent bool) {
+	u.m.Lock()
+	u.initTransparent = transparent
+	u.m.Unlock()
+}
+
 func (u *userInterface) isRunnableInBackground() bool {
 	u.m.Lock()
 	v := u.runnableInBackground
@@ -202,6 +259,45 @@ func (u *userInterface) setRunnableInBackground(runnableInBackground bool) {
 	u.m.Unlock()
 }
 
+func (u *userInterface) getTitle() string {
+	u.m.Lock()
+	t := u.title
+	u.m.Unlock()
+	return t
+}
+
+func (u *userInterface) setTitle(title string) {
+	u.m.Lock()
+	u.title = title
+	u.m.Unlock()
+}
+
+func (u *userInterface) isVsyncEnabled() bool {
+	u.m.Lock()
+	v := u.vsync
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setVsyncEnabled(enabled bool) {
+	u.m.Lock()
+	u.vsync = enabled
+	u.m.Unlock()
+}
+
+func (u *userInterface) getRefreshRate() int {
+	u.m.Lock()
+	r := u.refreshRate
+	u.m.Unlock()
+	return r
+}
+
+func (u *userInterface) setRefreshRate(refreshRate int) {
+	u.m.Lock()
+	u.refreshRate = refreshRate
+	u.m.Unlock()
+}
+
 func (u *userInterface) getInitIconImages() []image.Image {
 	u.m.Lock()
 	i := u.initIconImages
@@ -215,6 +311,32 @@ func (u *userInterface) setInitIconImages(iconImages []image.Image) {
 	u.m.Unlock()
 }
 
+func (u *userInterface) getWindowSizeLimits() (minw, minh, maxw, maxh int) {
+	u.m.Lock()
+	minw, minh, maxw, maxh = u.minWindowWidth, u.minWindowHeight, u.maxWindowWidth, u.maxWindowHeight
+	u.m.Unlock()
+	return
+}
+
+func (u *userInterface) setWindowSizeLimits(minw, minh, maxw, maxh int) {
+	u.m.Lock()
+	u.minWindowWidth, u.minWindowHeight, u.maxWindowWidth, u.maxWindowHeight = minw, minh, maxw, maxh
+	u.m.Unlock()
+}
+
+func (u *userInterface) isAspectRatioLocked() bool {
+	u.m.Lock()
+	l := u.aspectRatioLocked
+	u.m.Unlock()
+	return l
+}
+
+func (u *userInterface) setAspectRatioLocked(locked bool) {
+	u.m.Lock()
+	u.aspectRatioLocked = locked
+	u.m.Unlock()
+}
+
 func (u *userInterface) runOnMainThread(f func() error) error {
 	if u.funcs == nil {
 		// already closed
@@ -311,6 +433,63 @@ func IsRunnableInBackground() bool {
 	return currentUI.isRunnableInBackground()
 }
 
+func IsFocused() bool {
+	u := currentUI
+	if !u.isRunning() {
+		return false
+	}
+	v := false
+	_ = u.runOnMainThread(func() error {
+		v = u.window.GetAttrib(glfw.Focused) == glfw.True
+		return nil
+	})
+	return v
+}
+
+func SetVsyncEnabled(enabled bool) {
+	u := currentUI
+	u.setVsyncEnabled(enabled)
+	if !u.isRunning() {
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		u.updateVsync()
+		return nil
+	})
+}
+
+func IsVsyncEnabled() bool {
+	return currentUI.isVsyncEnabled()
+}
+
+func RefreshRate() int {
+	return currentUI.getRefreshRate()
+}
+
+func CanSkipPresenting() bool {
+	return true
+}
+
+// currentMonitor returns the monitor where the center of the window is.
+//
+// currentMonitor must be called from the main thread.
+func (u *userInterface) currentMonitor() *glfw.Monitor {
+	if m := u.window.GetMonitor(); m != nil {
+		return m
+	}
+	x, y := u.window.GetPos()
+	w, h := u.window.GetSize()
+	cx, cy := x+w/2, y+h/2
+	for _, m := range glfw.GetMonitors() {
+		mx, my := m.GetPos()
+		v := m.GetVideoMode()
+		if mx <= cx && cx < mx+v.Width && my <= cy && cy < my+v.Height {
+			return m
+		}
+	}
+	return glfw.GetPrimaryMonitor()
+}
+
 func SetWindowIcon(iconImages []image.Image) {
 	if !currentUI.isRunning() {
 		currentUI.setInitIconImages(iconImages)
@@ -322,6 +501,96 @@ func SetWindowIcon(iconImages []image.Image) {
 	})
 }
 
+func SetWindowSizeLimits(minw, minh, maxw, maxh int) {
+	u := currentUI
+	u.setWindowSizeLimits(minw, minh, maxw, maxh)
+	if !u.isRunning() {
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		if u.fullscreen() {
+			return nil
+		}
+		u.updateWindowConstraints()
+		u.setScreenSize(u.width, u.height, u.scale, false)
+		return nil
+	})
+}
+
+func WindowSizeLimits() (minw, minh, maxw, maxh int) {
+	return currentUI.getWindowSizeLimits()
+}
+
+func SetWindowAspectRatioLocked(locked bool) {
+	u := currentUI
+	u.setAspectRatioLocked(locked)
+	if !u.isRunning() {
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		if u.fullscreen() {
+			return nil
+		}
+		u.updateWindowConstraints()
+		return nil
+	})
+}
+
+func IsWindowAspectRatioLocked() bool {
+	return currentUI.isAspectRatioLocked()
+}
+
+func RequestWindowAttention() {
+	if !currentUI.isRunning() {
+		return
+	}
+	_ = currentUI.runOnMainThread(func() error {
+		requestWindowAttention(currentUI.window)
+		return nil
+	})
+}
+
+func SetWindowProgress(progress float64) {
+	if !currentUI.isRunning() {
+		return
+	}
+	_ = currentUI.runOnMainThread(func() error {
+		setWindowProgress(currentUI.window, progress)
+		return nil
+	})
+}
+
+func SetWindowTitle(title string) {
+	currentUI.setTitle(title)
+	if !currentUI.isRunning() {
+		return
+	}
+	_ = currentUI.runOnMainThread(func() error {
+		currentUI.window.SetTitle(title)
+		return nil
+	})
+}
+
+func OutsideSize() (width, height int) {
+	u := currentUI
+	if !u.isRunning() {
+		return 0, 0
+	}
+	_ = u.runOnMainThread(func() error {
+		if u.fullscreen() {
+			v := glfw.GetPrimaryMonitor().GetVideoMode()
+			width = int(float64(v.Width) / glfwScale())
+			height = int(float64(v.Height) / glfwScale())
+			return nil
+		}
+		w, h := u.window.GetSize()
+		width = int(float64(w) / glfwScale())
+		height = int(float64(h) / glfwScale())
+		return nil
+	})
+	return
+}
+
 func ScreenPadding() (x0, y0, x1, y1 float64) {
 	u := currentUI
 	if !u.isRunning() {
@@ -348,6 +617,10 @@ func ScreenPadding() (x0, y0, x1, y1 float64) {
 	return ox, oy, ox, oy
 }
 
+func SafeAreaInsets() (left, top, right, bottom float64) {
+	return 0, 0, 0, 0
+}
+
 func AdjustedCursorPosition() (x, y int) {
 	return adjustCursorPosition(input.Get().CursorPosition())
 }
@@ -434,6 +707,146 @@ func SetWindowDecorated(decorated bool) {
 	//     return nil
 }
 
+func IsWindowFloating() bool {
+	u := currentUI
+	if !u.isRunning() {
+		return u.isInitWindowFloating()
+	}
+	v := false
+	_ = currentUI.runOnMainThread(func() error {
+		v = currentUI.window.GetAttrib(glfw.Floating) == glfw.True
+		return nil
+	})
+	return v
+}
+
+func SetWindowFloating(floating bool) {
+	u := currentUI
+	if !u.isRunning() {
+		u.setInitWindowFloating(floating)
+		return
+	}
+	// TODO: Now SetAttrib doesn't exist on GLFW 3.2. Revisit later (#556).
+	panic("ui: SetWindowFloating can't be called after Run so far.")
+}
+
+func IsScreenTransparent() bool {
+	return currentUI.isInitTransparent()
+}
+
+func SetScreenTransparent(transparent bool) {
+	u := currentUI
+	if u.isRunning() {
+		panic("ui: SetScreenTransparent can't be called after Run.")
+	}
+	u.setInitTransparent(transparent)
+}
+
+// window is an additional window. The window has its own OpenGL context sharing the objects with the main window's context.
+type window struct {
+	window *glfw.Window
+	width  int
+	height int
+	scale  float64
+	closed bool
+
+	m sync.Mutex
+}
+
+func NewWindow(width, height int, scale float64, title string) (Window, error) {
+	u := currentUI
+	w := &window{
+		width:  width,
+		height: height,
+		scale:  scale,
+	}
+	if err := u.runOnMainThread(func() error {
+		s := scale * glfwScale()
+		gw, err := glfw.CreateWindow(int(float64(width)*s), int(float64(height)*s), title, nil, u.window)
+		if err != nil {
+			return err
+		}
+		// Swapping the buffers of the additional window must not wait for the vertical sync
+		// so that the main loop is not slowed down.
+		gw.MakeContextCurrent()
+		glfw.SwapInterval(0)
+		u.window.MakeContextCurrent()
+		gw.Show()
+
+		w.window = gw
+		u.windows = append(u.windows, w)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *window) Size() (int, int, float64) {
+	return w.width, w.height, w.scale * devicescale.DeviceScale()
+}
+
+func (w *window) IsClosed() bool {
+	w.m.Lock()
+	closed := w.closed
+	w.m.Unlock()
+	if closed {
+		return true
+	}
+
+	shouldClose := false
+	_ = currentUI.runOnMainThread(func() error {
+		shouldClose = w.window.ShouldClose()
+		return nil
+	})
+	if shouldClose {
+		// The window is closed by the user.
+		w.Close()
+	}
+	return shouldClose
+}
+
+func (w *window) Close() {
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+	u := currentUI
+	_ = u.runOnMainThread(func() error {
+		for i, ww := range u.windows {
+			if ww == w {
+				u.windows = append(u.windows[:i], u.windows[i+1:]...)
+				break
+			}
+		}
+		w.window.Destroy()
+		return nil
+	})
+}
+
+func (w *window) Render(f func() error) error {
+	if w.IsClosed() {
+		return nil
+	}
+	u := currentUI
+	_ = u.runOnMainThread(func() error {
+		w.window.MakeContextCurrent()
+		return nil
+	})
+	err := f()
+	// The bound framebuffer must be the original screen framebuffer
+	// before swapping buffers.
+	opengl.GetContext().BindScreenFramebuffer()
+	_ = u.runOnMainThread(func() error {
+		w.window.SwapBuffers()
+		u.window.MakeContextCurrent()
+		return nil
+	})
+	return err
+}
+
 func Run(width, height int, scale float64, title string, g GraphicsContext, mainloop bool) error {
 	<-currentUIInitialized
 
@@ -441,6 +854,24 @@ func Run(width, height int, scale float64, title string, g GraphicsContext, main
 	// GLContext must be created before setting the screen size, which requires
 	// swapping buffers.
 	opengl.Init(currentUI.runOnMainThread)
+	var uploadWindow *glfw.Window
+	_ = u.runOnMainThread(func() error {
+		// Create a hidden window only to have a context sharing the textures for the upload worker.
+		glfw.WindowHint(glfw.Visible, glfw.False)
+		w, err := glfw.CreateWindow(1, 1, "", nil, u.window)
+		if err != nil {
+			// The upload worker is not available, but this is not fatal.
+			return nil
+		}
+		uploadWindow = w
+		return nil
+	})
+	if uploadWindow != nil {
+		opengl.GetContext().SetUploadContext(func() error {
+			uploadWindow.MakeContextCurrent()
+			return nil
+		})
+	}
 	_ = u.runOnMainThread(func() error {
 		m := glfw.GetPrimaryMonitor()
 		v := m.GetVideoMode()
@@ -448,8 +879,10 @@ func Run(width, height int, scale float64, title string, g GraphicsContext, main
 		// The game is in window mode (not fullscreen mode) at the first state.
 		// Don't refer u.initFullscreen here to avoid some GLFW problems.
 		u.setScreenSize(width, height, scale, false)
-		u.title = title
-		u.window.SetTitle(title)
+		if title != "" {
+			u.setTitle(title)
+		}
+		u.window.SetTitle(u.getTitle())
 		u.window.Show()
 
 		w, h := u.glfwSize()
@@ -541,7 +974,7 @@ func (u *userInterface) update(g GraphicsContext) error {
 
 	_ = u.runOnMainThread(func() error {
 		u.pollEvents()
-		for !u.isRunnableInBackground() && u.window.GetAttrib(glfw.Focused) == 0 {
+		for !u.isRunnableInBackground() && !u.isAnyWindowFocused() {
 			// Wait for an arbitrary period to avoid busy loop.
 			time.Sleep(time.Second / 60)
 			u.pollEvents()
@@ -549,10 +982,13 @@ func (u *userInterface) update(g GraphicsContext) error {
 				return nil
 			}
 		}
+		// The window might be moved to another monitor.
+		u.setRefreshRate(u.currentMonitor().GetVideoMode().RefreshRate)
 		return nil
 	})
 	if err := g.Update(func() {
 		input.Get().ClearRuneBuffer()
+		input.Get().ClearDroppedFiles()
 		// The offscreens must be updated every frame (#490).
 		u.updateGraphicsContext(g)
 	}); err != nil {
@@ -572,6 +1008,16 @@ func (u *userInterface) loop(g GraphicsContext) error {
 		if err := u.update(g); err != nil {
 			return err
 		}
+		if !g.NeedsPresenting() {
+			// Nothing is rendered and the last frame is kept on the window.
+			// Wait for the next frame instead of swapping buffers, which would wait for vsync.
+			r := u.getRefreshRate()
+			if r <= 0 {
+				r = 60
+			}
+			time.Sleep(time.Second / time.Duration(r))
+			continue
+		}
 		// The bound framebuffer must be the original screen framebuffer
 		// before swapping buffers.
 		opengl.GetContext().BindScreenFramebuffer()
@@ -582,13 +1028,71 @@ func (u *userInterface) loop(g GraphicsContext) error {
 	}
 }
 
+// isAnyWindowFocused reports whether the main window or any of the additional windows is focused.
+// The game should keep running while e.g. a tool window is focused.
+//
+// isAnyWindowFocused must be called from the main thread.
+func (u *userInterface) isAnyWindowFocused() bool {
+	if u.window.GetAttrib(glfw.Focused) == glfw.True {
+		return true
+	}
+	for _, w := range u.windows {
+		if w.window.GetAttrib(glfw.Focused) == glfw.True {
+			return true
+		}
+	}
+	return false
+}
+
 // swapBuffers must be called from the main thread.
 func (u *userInterface) swapBuffers() {
 	u.window.SwapBuffers()
 }
 
+// constrainScale returns the scale adjusted so that the window of the given screen size fits with the window size limits.
+// The minimum size has priority over the maximum size.
+func (u *userInterface) constrainScale(width, height int, scale float64) float64 {
+	minw, minh, maxw, maxh := u.getWindowSizeLimits()
+	if maxw >= 0 && float64(width)*scale > float64(maxw) {
+		scale = float64(maxw) / float64(width)
+	}
+	if maxh >= 0 && float64(height)*scale > float64(maxh) {
+		scale = float64(maxh) / float64(height)
+	}
+	if minw >= 0 && float64(width)*scale < float64(minw) {
+		scale = float64(minw) / float64(width)
+	}
+	if minh >= 0 && float64(height)*scale < float64(minh) {
+		scale = float64(minh) / float64(height)
+	}
+	return scale
+}
+
+// updateWindowConstraints applies the window size limits and the aspect ratio lock to the window.
+//
+// updateWindowConstraints must be called from the main thread.
+func (u *userInterface) updateWindowConstraints() {
+	minw, minh, maxw, maxh := u.getWindowSizeLimits()
+	toGLFW := func(v int) int {
+		if v < 0 {
+			return glfw.DontCare
+		}
+		return int(float64(v) * glfwScale())
+	}
+	u.window.SetSizeLimits(toGLFW(minw), toGLFW(minh), toGLFW(maxw), toGLFW(maxh))
+
+	if !u.isAspectRatioLocked() {
+		u.window.SetAspectRatio(glfw.DontCare, glfw.DontCare)
+		return
+	}
+	u.window.SetAspectRatio(u.glfwSize())
+}
+
 // setScreenSize must be called from the main thread.
 func (u *userInterface) setScreenSize(width, height int, scale float64, fullscreen bool) bool {
+	if !fullscreen {
+		scale = u.constrainScale(width, height, scale)
+	}
 	if u.width == width && u.height == height && u.scale == scale && u.fullscreen() == fullscreen {
 		return false
 	}
@@ -628,6 +1132,9 @@ func (u *userInterface) setScreenSize(width, height int, scale float64, fullscre
 			u.origPosY = -1
 		}
 
+		// The constraints must be updated before SetSize, or the window manager might reject the new size.
+		u.updateWindowConstraints()
+
 		oldW, oldH := u.window.GetSize()
 		newW, newH := u.glfwSize()
 		if oldW != newW || oldH != newH {
@@ -648,17 +1155,25 @@ func (u *userInterface) setScreenSize(width, height int, scale float64, fullscre
 			}
 		}
 		// Window title might be lost on macOS after coming back from fullscreen.
-		u.window.SetTitle(u.title)
+		u.window.SetTitle(u.getTitle())
 	}
 	// SwapInterval is affected by the current monitor of the window.
 	// This needs to be called at least after SetMonitor.
 	// Without SwapInterval after SetMonitor, vsynch doesn't work (#375).
-	//
-	// TODO: (#405) If triple buffering is needed, SwapInterval(0) should be called,
-	// but is this correct? If glfw.SwapInterval(0) and the driver doesn't support triple
-	// buffering, what will happen?
-	glfw.SwapInterval(1)
+	u.updateVsync()
 
 	u.toChangeSize = true
 	return true
 }
+
+// updateVsync must be called from the main thread.
+func (u *userInterface) updateVsync() {
+	// TODO: (#405) If triple buffering is needed, SwapInterval(0) should be called,
+	// but is this correct? If glfw.SwapInterval(0) and the driver doesn't support triple
+	// buffering, what will happen?
+	if u.isVsyncEnabled() {
+		glfw.SwapInterval(1)
+	} else {
+		glfw.SwapInterval(0)
+	}
+}
@@ -0,0 +1,523 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ebitenheadless
+// +build linux
+// +build !android
+// +build !ebitenexternal
+
+package ui
+
+// #cgo pkg-config: egl gl
+//
+// #define GL_GLEXT_PROTOTYPES
+// #include <EGL/egl.h>
+// #include <EGL/eglext.h>
+// #include <GL/gl.h>
+// #include <GL/glext.h>
+//
+// static EGLDisplay getSurfacelessDisplay() {
+//   PFNEGLGETPLATFORMDISPLAYEXTPROC getPlatformDisplay =
+//     (PFNEGLGETPLATFORMDISPLAYEXTPROC)eglGetProcAddress("eglGetPlatformDisplayEXT");
+//   if (getPlatformDisplay) {
+//     return getPlatformDisplay(EGL_PLATFORM_SURFACELESS_MESA, EGL_DEFAULT_DISPLAY, NULL);
+//   }
+//   return eglGetDisplay(EGL_DEFAULT_DISPLAY);
+// }
+//
+// static EGLDisplay theDisplay = EGL_NO_DISPLAY;
+// static EGLContext uploadContext = EGL_NO_CONTEXT;
+//
+// static int initializeContext() {
+//   EGLDisplay display = getSurfacelessDisplay();
+//   if (display == EGL_NO_DISPLAY) {
+//     return 0;
+//   }
+//   if (!eglInitialize(display, NULL, NULL)) {
+//     return 0;
+//   }
+//   EGLint attribs[] = {
+//     EGL_SURFACE_TYPE, EGL_PBUFFER_BIT,
+//     EGL_RENDERABLE_TYPE, EGL_OPENGL_BIT,
+//     EGL_NONE,
+//   };
+//   EGLConfig config;
+//   EGLint num = 0;
+//   if (!eglChooseConfig(display, attribs, &config, 1, &num) || num == 0) {
+//     return 0;
+//   }
+//   if (!eglBindAPI(EGL_OPENGL_API)) {
+//     return 0;
+//   }
+//   EGLContext context = eglCreateContext(display, config, EGL_NO_CONTEXT, NULL);
+//   if (context == EGL_NO_CONTEXT) {
+//     return 0;
+//   }
+//   if (!eglMakeCurrent(display, EGL_NO_SURFACE, EGL_NO_SURFACE, context)) {
+//     return 0;
+//   }
+//   theDisplay = display;
+//   // The context for the upload worker shares the textures. This is optional.
+//   uploadContext = eglCreateContext(display, config, context, NULL);
+//   return 1;
+// }
+//
+// static int makeUploadContextCurrent() {
+//   if (uploadContext == EGL_NO_CONTEXT) {
+//     return 0;
+//   }
+//   return eglMakeCurrent(theDisplay, EGL_NO_SURFACE, EGL_NO_SURFACE, uploadContext);
+// }
+//
+// static GLuint newScreenFramebuffer(GLuint* renderbuffer) {
+//   GLuint f;
+//   glGenFramebuffers(1, &f);
+//   glGenRenderbuffers(1, renderbuffer);
+//   glBindRenderbuffer(GL_RENDERBUFFER, *renderbuffer);
+//   glRenderbufferStorage(GL_RENDERBUFFER, GL_RGBA8, 16, 16);
+//   glBindFramebuffer(GL_FRAMEBUFFER, f);
+//   glFramebufferRenderbuffer(GL_FRAMEBUFFER, GL_COLOR_ATTACHMENT0, GL_RENDERBUFFER, *renderbuffer);
+//   return f;
+// }
+//
+// static void resizeScreenFramebuffer(GLuint renderbuffer, int width, int height) {
+//   glBindRenderbuffer(GL_RENDERBUFFER, renderbuffer);
+//   glRenderbufferStorage(GL_RENDERBUFFER, GL_RGBA8, width, height);
+// }
+//
+// static GLuint newWindowFramebuffer(GLuint* renderbuffer, int width, int height) {
+//   // Keep the bound framebuffer as Ebiten caches it.
+//   GLint orig = 0;
+//   glGetIntegerv(GL_FRAMEBUFFER_BINDING, &orig);
+//   GLuint f = newScreenFramebuffer(renderbuffer);
+//   resizeScreenFramebuffer(*renderbuffer, width, height);
+//   glBindFramebuffer(GL_FRAMEBUFFER, orig);
+//   return f;
+// }
+//
+// static void deleteWindowFramebuffer(GLuint framebuffer, GLuint renderbuffer) {
+//   glDeleteFramebuffers(1, &framebuffer);
+//   glDeleteRenderbuffers(1, &renderbuffer);
+// }
+import "C"
+
+import (
+	"errors"
+	"image"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/internal/input"
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+)
+
+// userInterface is a user interface without any windows.
+//
+// In headless mode, the game renders to an offscreen framebuffer with an EGL surfaceless context.
+// This is useful for testing or rendering on servers where no display is available.
+type userInterface struct {
+	width  int
+	height int
+	scale  float64
+
+	title                string
+	running              bool
+	sizeChanged          bool
+	runnableInBackground bool
+	vsync                bool
+
+	framebuffer  C.GLuint
+	renderbuffer C.GLuint
+
+	funcs chan func()
+
+	m sync.Mutex
+}
+
+var (
+	currentUI = &userInterface{
+		vsync: true,
+	}
+	currentUIInitialized = make(chan struct{})
+)
+
+func init() {
+	runtime.LockOSThread()
+}
+
+func initialize() error {
+	if C.initializeContext() == 0 {
+		return errors.New("ui: initializing EGL context failed")
+	}
+
+	// Bind the offscreen framebuffer before initializing the OpenGL context
+	// so that the framebuffer is treated as the screen framebuffer.
+	currentUI.framebuffer = C.newScreenFramebuffer(&currentUI.renderbuffer)
+	currentUI.funcs = make(chan func())
+	return nil
+}
+
+func RunMainThreadLoop(ch <-chan error) error {
+	// This must be called on the main thread.
+
+	if err := initialize(); err != nil {
+		return err
+	}
+	close(currentUIInitialized)
+
+	currentUI.setRunning(true)
+	defer func() {
+		currentUI.setRunning(false)
+	}()
+	for {
+		select {
+		case f := <-currentUI.funcs:
+			f()
+		case err := <-ch:
+			// ch returns a value not only when an error occur but also it is closed.
+			return err
+		}
+	}
+}
+
+func (u *userInterface) isRunning() bool {
+	u.m.Lock()
+	v := u.running
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setRunning(running bool) {
+	u.m.Lock()
+	u.running = running
+	u.m.Unlock()
+}
+
+func (u *userInterface) runOnMainThread(f func() error) error {
+	if u.funcs == nil {
+		// already closed
+		return nil
+	}
+	ch := make(chan struct{})
+	var err error
+	u.funcs <- func() {
+		err = f()
+		close(ch)
+	}
+	<-ch
+	return err
+}
+
+func SetScreenSize(width, height int) bool {
+	return currentUI.setScreenSize(width, height, currentUI.getScale())
+}
+
+func SetScreenScale(scale float64) bool {
+	u := currentUI
+	u.m.Lock()
+	w, h := u.width, u.height
+	u.m.Unlock()
+	return u.setScreenSize(w, h, scale)
+}
+
+func ScreenScale() float64 {
+	return currentUI.getScale()
+}
+
+func (u *userInterface) getScale() float64 {
+	u.m.Lock()
+	s := u.scale
+	u.m.Unlock()
+	return s
+}
+
+func (u *userInterface) setScreenSize(width, height int, scale float64) bool {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.width == width && u.height == height && u.scale == scale {
+		return false
+	}
+	u.width = width
+	u.height = height
+	u.scale = scale
+	u.sizeChanged = true
+	return true
+}
+
+func IsFullscreen() bool {
+	return false
+}
+
+func SetFullscreen(fullscreen bool) {
+	// Do nothing
+}
+
+func IsFocused() bool {
+	return currentUI.isRunning()
+}
+
+func SetRunnableInBackground(runnableInBackground bool) {
+	u := currentUI
+	u.m.Lock()
+	u.runnableInBackground = runnableInBackground
+	u.m.Unlock()
+}
+
+func IsRunnableInBackground() bool {
+	u := currentUI
+	u.m.Lock()
+	v := u.runnableInBackground
+	u.m.Unlock()
+	return v
+}
+
+func SetVsyncEnabled(enabled bool) {
+	u := currentUI
+	u.m.Lock()
+	u.vsync = enabled
+	u.m.Unlock()
+}
+
+func IsVsyncEnabled() bool {
+	return currentUI.isVsyncEnabled()
+}
+
+func RefreshRate() int {
+	return 0
+}
+
+func CanSkipPresenting() bool {
+	return true
+}
+
+func (u *userInterface) isVsyncEnabled() bool {
+	u.m.Lock()
+	v := u.vsync
+	u.m.Unlock()
+	return v
+}
+
+func SetWindowIcon(iconImages []image.Image) {
+	// Do nothing
+}
+
+func SetWindowSizeLimits(minw, minh, maxw, maxh int) {
+	// Do nothing
+}
+
+func WindowSizeLimits() (minw, minh, maxw, maxh int) {
+	return -1, -1, -1, -1
+}
+
+func SetWindowAspectRatioLocked(locked bool) {
+	// Do nothing
+}
+
+func IsWindowAspectRatioLocked() bool {
+	return false
+}
+
+func RequestWindowAttention() {
+	// Do nothing
+}
+
+func SetWindowProgress(progress float64) {
+	// Do nothing
+}
+
+func SetWindowTitle(title string) {
+	u := currentUI
+	u.m.Lock()
+	u.title = title
+	u.m.Unlock()
+}
+
+func OutsideSize() (width, height int) {
+	// The offscreen framebuffer is regarded as the window.
+	u := currentUI
+	u.m.Lock()
+	width = int(float64(u.width) * u.scale)
+	height = int(float64(u.height) * u.scale)
+	u.m.Unlock()
+	return
+}
+
+func ScreenPadding() (x0, y0, x1, y1 float64) {
+	return 0, 0, 0, 0
+}
+
+func SafeAreaInsets() (left, top, right, bottom float64) {
+	return 0, 0, 0, 0
+}
+
+func AdjustedCursorPosition() (x, y int) {
+	return input.Get().CursorPosition()
+}
+
+func AdjustedTouches() []*input.Touch {
+	return input.Get().Touches()
+}
+
+func IsCursorVisible() bool {
+	return false
+}
+
+func SetCursorVisible(visible bool) {
+	// Do nothing
+}
+
+func IsWindowFloating() bool {
+	return false
+}
+
+func SetWindowFloating(floating bool) {
+	// Do nothing
+}
+
+func IsScreenTransparent() bool {
+	return false
+}
+
+func SetScreenTransparent(transparent bool) {
+	// Do nothing
+}
+
+func IsWindowDecorated() bool {
+	return false
+}
+
+func SetWindowDecorated(decorated bool) {
+	// Do nothing
+}
+
+// window is an additional window in headless mode, which is an offscreen framebuffer
+// in the same OpenGL context as the main screen.
+type window struct {
+	width  int
+	height int
+	scale  float64
+
+	framebuffer  C.GLuint
+	renderbuffer C.GLuint
+	closed       bool
+
+	m sync.Mutex
+}
+
+func NewWindow(width, height int, scale float64, title string) (Window, error) {
+	w := &window{
+		width:  width,
+		height: height,
+		scale:  scale,
+	}
+	_ = currentUI.runOnMainThread(func() error {
+		w.framebuffer = C.newWindowFramebuffer(&w.renderbuffer, C.int(float64(width)*scale), C.int(float64(height)*scale))
+		return nil
+	})
+	return w, nil
+}
+
+func (w *window) Size() (int, int, float64) {
+	return w.width, w.height, w.scale
+}
+
+func (w *window) IsClosed() bool {
+	w.m.Lock()
+	v := w.closed
+	w.m.Unlock()
+	return v
+}
+
+func (w *window) Close() {
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+	_ = currentUI.runOnMainThread(func() error {
+		C.deleteWindowFramebuffer(w.framebuffer, w.renderbuffer)
+		return nil
+	})
+}
+
+func (w *window) Render(f func() error) error {
+	if w.IsClosed() {
+		return nil
+	}
+	opengl.GetContext().SetScreenFramebuffer(uint32(w.framebuffer))
+	defer opengl.GetContext().SetScreenFramebuffer(uint32(currentUI.framebuffer))
+	return f()
+}
+
+func Run(width, height int, scale float64, title string, g GraphicsContext, mainloop bool) error {
+	<-currentUIInitialized
+
+	u := currentUI
+	opengl.Init(u.runOnMainThread)
+	opengl.GetContext().SetUploadContext(func() error {
+		if C.makeUploadContextCurrent() == 0 {
+			return errors.New("ui: making the upload context current failed")
+		}
+		return nil
+	})
+	u.setScreenSize(width, height, scale)
+	if title != "" {
+		SetWindowTitle(title)
+	}
+	return u.loop(g)
+}
+
+func (u *userInterface) updateGraphicsContext(g GraphicsContext) {
+	u.m.Lock()
+	sizeChanged := u.sizeChanged
+	u.sizeChanged = false
+	w, h, s := u.width, u.height, u.scale
+	u.m.Unlock()
+	if !sizeChanged {
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		C.resizeScreenFramebuffer(u.renderbuffer, C.int(float64(w)*s), C.int(float64(h)*s))
+		return nil
+	})
+	g.SetSize(w, h, s)
+}
+
+func (u *userInterface) loop(g GraphicsContext) error {
+	const frameDuration = time.Second / 60
+	next := time.Now()
+	for {
+		u.updateGraphicsContext(g)
+		if err := g.Update(func() {
+			// The offscreens must be updated every frame (#490).
+			u.updateGraphicsContext(g)
+		}); err != nil {
+			return err
+		}
+		if u.isVsyncEnabled() {
+			// There is no display to sync with. Emulate 60 FPS with a timer.
+			// Sleep until the next frame's deadline so that the time for rendering is included.
+			next = next.Add(frameDuration)
+			now := time.Now()
+			if d := next.Sub(now); d > 0 {
+				time.Sleep(d)
+			} else if -d > frameDuration {
+				// The loop is too late. Don't try to catch up with the past deadlines.
+				next = now
+			}
+		}
+	}
+}
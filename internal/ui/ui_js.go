@@ -17,17 +17,21 @@
 package ui
 
 import (
+	"errors"
 	"image"
+	"math"
+	"sort"
 	"strconv"
 
-	"github.com/gopherjs/gopherjs/js"
-
 	"github.com/hajimehoshi/ebiten/internal/devicescale"
 	"github.com/hajimehoshi/ebiten/internal/input"
+	"github.com/hajimehoshi/ebiten/internal/js"
 	"github.com/hajimehoshi/ebiten/internal/opengl"
+	"github.com/hajimehoshi/ebiten/internal/sync"
+	"github.com/hajimehoshi/ebiten/internal/web"
 )
 
-var canvas *js.Object
+var canvas js.Value
 
 type userInterface struct {
 	width                int
@@ -35,14 +39,25 @@ type userInterface struct {
 	scale                float64
 	fullscreen           bool
 	runnableInBackground bool
+	vsync                bool
+	transparent          bool
 
+	running     bool
 	sizeChanged bool
 	windowFocus bool
+
+	// refreshRate is the display's refresh rate estimated from the timestamps of the animation frames.
+	refreshRate    int
+	lastFrameTime  float64
+	frameIntervals []float64
+
+	m sync.Mutex
 }
 
 var currentUI = &userInterface{
 	sizeChanged: true,
 	windowFocus: true,
+	vsync:       true,
 }
 
 func SetScreenSize(width, height int) bool {
@@ -73,10 +88,76 @@ func IsRunnableInBackground() bool {
 	return currentUI.runnableInBackground
 }
 
+func IsFocused() bool {
+	return currentUI.running && currentUI.windowFocus
+}
+
+func SetVsyncEnabled(enabled bool) {
+	currentUI.setVsyncEnabled(enabled)
+}
+
+func IsVsyncEnabled() bool {
+	return currentUI.isVsyncEnabled()
+}
+
+func (u *userInterface) isVsyncEnabled() bool {
+	u.m.Lock()
+	v := u.vsync
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setVsyncEnabled(enabled bool) {
+	u.m.Lock()
+	u.vsync = enabled
+	u.m.Unlock()
+}
+
+func RefreshRate() int {
+	return currentUI.refreshRate
+}
+
+func CanSkipPresenting() bool {
+	// The canvas keeps the last contents unless the drawing buffer is changed.
+	return true
+}
+
+// updateRefreshRate estimates the refresh rate with the timestamp of an animation frame in milliseconds.
+//
+// The median of the frame intervals is used so that dropped frames don't affect the estimation.
+func (u *userInterface) updateRefreshRate(timestamp float64) {
+	if u.lastFrameTime > 0 {
+		u.frameIntervals = append(u.frameIntervals, timestamp-u.lastFrameTime)
+	}
+	u.lastFrameTime = timestamp
+
+	const samples = 60
+	if len(u.frameIntervals) < samples {
+		return
+	}
+	sort.Float64s(u.frameIntervals)
+	if median := u.frameIntervals[samples/2]; median > 0 {
+		u.refreshRate = int(math.Floor(1000/median + 0.5))
+	}
+	u.frameIntervals = u.frameIntervals[:0]
+}
+
+func OutsideSize() (width, height int) {
+	if !canvas.Truthy() {
+		return 0, 0
+	}
+	body := js.Global().Get("document").Get("body")
+	return body.Get("clientWidth").Int(), body.Get("clientHeight").Int()
+}
+
 func ScreenPadding() (x0, y0, x1, y1 float64) {
 	return 0, 0, 0, 0
 }
 
+func SafeAreaInsets() (left, top, right, bottom float64) {
+	return 0, 0, 0, 0
+}
+
 func adjustPosition(x, y int) (int, int) {
 	rect := canvas.Call("getBoundingClientRect")
 	x -= rect.Get("left").Int()
@@ -85,6 +166,32 @@ func adjustPosition(x, y int) (int, int) {
 	return int(float64(x) / scale), int(float64(y) / scale)
 }
 
+// updatePen updates the pen state with a pointer event.
+func updatePen(e js.Value, leave bool) {
+	if e.Get("pointerType").String() != "pen" {
+		return
+	}
+	e.Call("preventDefault")
+	if leave {
+		input.GetPen().Reset()
+		return
+	}
+
+	rect := canvas.Call("getBoundingClientRect")
+	scale := currentUI.getScale()
+	buttons := e.Get("buttons").Int()
+	input.GetPen().Set(input.PenState{
+		X:        (e.Get("clientX").Float() - rect.Get("left").Float()) / scale,
+		Y:        (e.Get("clientY").Float() - rect.Get("top").Float()) / scale,
+		Pressure: e.Get("pressure").Float(),
+		TiltX:    e.Get("tiltX").Float() * math.Pi / 180,
+		TiltY:    e.Get("tiltY").Float() * math.Pi / 180,
+		// The eraser end of a pen reports the button 5 (the bit 32).
+		Eraser:  buttons&32 != 0,
+		Pressed: buttons&(1|32) != 0,
+	})
+}
+
 func AdjustedCursorPosition() (x, y int) {
 	return adjustPosition(input.Get().CursorPosition())
 }
@@ -116,6 +223,60 @@ func SetWindowIcon(iconImages []image.Image) {
 	// Do nothing
 }
 
+func SetWindowSizeLimits(minw, minh, maxw, maxh int) {
+	// Do nothing
+}
+
+func WindowSizeLimits() (minw, minh, maxw, maxh int) {
+	return -1, -1, -1, -1
+}
+
+func SetWindowAspectRatioLocked(locked bool) {
+	// Do nothing
+}
+
+func IsWindowAspectRatioLocked() bool {
+	return false
+}
+
+func RequestWindowAttention() {
+	// Do nothing
+}
+
+func SetWindowProgress(progress float64) {
+	// Do nothing
+}
+
+func SetWindowTitle(title string) {
+	doc := js.Global().Get("document")
+	doc.Set("title", title)
+}
+
+func IsWindowFloating() bool {
+	return false
+}
+
+func SetWindowFloating(floating bool) {
+	// Do nothing
+}
+
+func IsScreenTransparent() bool {
+	return currentUI.transparent
+}
+
+// SetScreenTransparent makes the page background transparent so that the canvas, which already has the alpha
+// channel, is composed with the content behind the page, e.g., the parent page of the iframe.
+func SetScreenTransparent(transparent bool) {
+	currentUI.transparent = transparent
+	bg := "#000"
+	if transparent {
+		bg = "transparent"
+	}
+	doc := js.Global().Get("document")
+	doc.Get("documentElement").Get("style").Set("backgroundColor", bg)
+	doc.Get("body").Get("style").Set("backgroundColor", bg)
+}
+
 func IsWindowDecorated() bool {
 	return false
 }
@@ -124,11 +285,15 @@ func SetWindowDecorated(decorated bool) {
 	// Do nothing
 }
 
+func NewWindow(width, height int, scale float64, title string) (Window, error) {
+	return nil, errors.New("ui: multiple windows are not supported on browsers")
+}
+
 func (u *userInterface) getScale() float64 {
 	if !u.fullscreen {
 		return u.scale
 	}
-	doc := js.Global.Get("document")
+	doc := js.Global().Get("document")
 	body := doc.Get("body")
 	bw := body.Get("clientWidth").Float()
 	bh := body.Get("clientHeight").Float()
@@ -173,6 +338,7 @@ func (u *userInterface) update(g GraphicsContext) error {
 	u.updateGraphicsContext(g)
 	if err := g.Update(func() {
 		input.Get().ClearRuneBuffer()
+		input.Get().ClearDroppedFiles()
 		// The offscreens must be updated every frame (#490).
 		u.updateGraphicsContext(g)
 	}); err != nil {
@@ -184,6 +350,7 @@ func (u *userInterface) update(g GraphicsContext) error {
 func (u *userInterface) loop(g GraphicsContext) error {
 	ch := make(chan error)
 	var f func()
+	var jsf js.Func
 	f = func() {
 		go func() {
 			if err := u.update(g); err != nil {
@@ -191,9 +358,26 @@ func (u *userInterface) loop(g GraphicsContext) error {
 				close(ch)
 				return
 			}
-			js.Global.Get("window").Call("requestAnimationFrame", f)
+			if u.isVsyncEnabled() {
+				js.Global().Get("window").Call("requestAnimationFrame", jsf)
+				return
+			}
+			// requestAnimationFrame is synced with the display's refresh rate.
+			// Use a timer instead to render as fast as possible.
+			js.Global().Get("window").Call("setTimeout", jsf, 0)
 		}()
 	}
+	jsf = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if len(args) > 0 && args[0].Type() == js.TypeNumber {
+			// The argument is the timestamp when the callback is called by requestAnimationFrame.
+			u.updateRefreshRate(args[0].Float())
+		} else {
+			u.lastFrameTime = 0
+		}
+		f()
+		return nil
+	})
+	defer jsf.Release()
 	f()
 	return <-ch
 }
@@ -206,28 +390,32 @@ func init() {
 
 func initialize() error {
 	// Do nothing in node.js.
-	if js.Global.Get("require") != js.Undefined {
+	if web.IsNodeJS() {
 		return nil
 	}
 
-	doc := js.Global.Get("document")
-	window := js.Global.Get("window")
-	if doc.Get("body") == nil {
+	doc := js.Global().Get("document")
+	window := js.Global().Get("window")
+	if !doc.Get("body").Truthy() {
 		ch := make(chan struct{})
-		window.Call("addEventListener", "load", func() {
+		window.Call("addEventListener", "load", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 			close(ch)
-		})
+			return nil
+		}))
 		<-ch
 	}
-	window.Call("addEventListener", "focus", func() {
+	window.Call("addEventListener", "focus", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		currentUI.windowFocus = true
-	})
-	window.Call("addEventListener", "blur", func() {
+		return nil
+	}))
+	window.Call("addEventListener", "blur", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		currentUI.windowFocus = false
-	})
-	window.Call("addEventListener", "resize", func() {
+		return nil
+	}))
+	window.Call("addEventListener", "resize", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		currentUI.updateScreenSize()
-	})
+		return nil
+	}))
 
 	// Adjust the initial scale to 1.
 	// https://developer.mozilla.org/en/docs/Mozilla/Mobile/Viewport_meta_tag
@@ -254,9 +442,11 @@ func initialize() error {
 	bodyStyle.Set("padding", "0")
 	// TODO: This is OK as long as the game is in an independent iframe.
 	// What if the canvas is embedded in a HTML directly?
-	doc.Get("body").Call("addEventListener", "click", func() {
+	doc.Get("body").Call("addEventListener", "click", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		canvas.Call("focus")
-	})
+		input.RequestSensorPermission()
+		return nil
+	}))
 
 	canvasStyle := canvas.Get("style")
 	canvasStyle.Set("position", "absolute")
@@ -266,51 +456,87 @@ func initialize() error {
 	canvas.Get("style").Set("outline", "none")
 
 	// Keyboard
-	canvas.Call("addEventListener", "keydown", input.OnKeyDown)
-	canvas.Call("addEventListener", "keypress", input.OnKeyPress)
-	canvas.Call("addEventListener", "keyup", input.OnKeyUp)
+	canvas.Call("addEventListener", "keydown", eventFunc(input.OnKeyDown))
+	canvas.Call("addEventListener", "keypress", eventFunc(input.OnKeyPress))
+	canvas.Call("addEventListener", "keyup", eventFunc(input.OnKeyUp))
 
 	// Mouse
-	canvas.Call("addEventListener", "mousedown", input.OnMouseDown)
-	canvas.Call("addEventListener", "mouseup", input.OnMouseUp)
-	canvas.Call("addEventListener", "mousemove", input.OnMouseMove)
-	canvas.Call("addEventListener", "contextmenu", func(e *js.Object) {
+	canvas.Call("addEventListener", "mousedown", eventFunc(input.OnMouseDown))
+	canvas.Call("addEventListener", "mouseup", eventFunc(input.OnMouseUp))
+	canvas.Call("addEventListener", "mousemove", eventFunc(input.OnMouseMove))
+	canvas.Call("addEventListener", "contextmenu", eventFunc(func(e js.Value) {
 		e.Call("preventDefault")
-	})
+	}))
 
 	// Touch
-	canvas.Call("addEventListener", "touchstart", input.OnTouchStart)
-	canvas.Call("addEventListener", "touchend", input.OnTouchEnd)
-	canvas.Call("addEventListener", "touchmove", input.OnTouchMove)
+	canvas.Call("addEventListener", "touchstart", eventFunc(input.OnTouchStart))
+	canvas.Call("addEventListener", "touchend", eventFunc(func(e js.Value) {
+		input.RequestSensorPermission()
+		input.OnTouchEnd(e)
+	}))
+	canvas.Call("addEventListener", "touchmove", eventFunc(input.OnTouchMove))
+
+	// Pen
+	for _, name := range []string{"pointerdown", "pointermove", "pointerup"} {
+		canvas.Call("addEventListener", name, eventFunc(func(e js.Value) {
+			updatePen(e, false)
+		}))
+	}
+	for _, name := range []string{"pointerleave", "pointercancel"} {
+		canvas.Call("addEventListener", name, eventFunc(func(e js.Value) {
+			updatePen(e, true)
+		}))
+	}
+
+	// Motion sensors
+	window.Call("addEventListener", "devicemotion", eventFunc(input.OnDeviceMotion))
+	window.Call("addEventListener", "deviceorientation", eventFunc(input.OnDeviceOrientation))
+
+	// Drag and drop
+	canvas.Call("addEventListener", "dragover", eventFunc(input.OnDragOver))
+	canvas.Call("addEventListener", "drop", eventFunc(input.OnDrop))
 
 	// Gamepad
-	window.Call("addEventListener", "gamepadconnected", func(e *js.Object) {
+	window.Call("addEventListener", "gamepadconnected", eventFunc(func(e js.Value) {
 		// Do nothing.
-	})
+	}))
 
-	canvas.Call("addEventListener", "webglcontextlost", func(e *js.Object) {
+	canvas.Call("addEventListener", "webglcontextlost", eventFunc(func(e js.Value) {
 		e.Call("preventDefault")
-	})
-	canvas.Call("addEventListener", "webglcontextrestored", func(e *js.Object) {
+	}))
+	canvas.Call("addEventListener", "webglcontextrestored", eventFunc(func(e js.Value) {
 		// Do nothing.
-	})
+	}))
 
 	return nil
 }
 
+// eventFunc returns a JavaScript function to handle an event with f.
+//
+// Note that the event is handled synchronously so that preventDefault works.
+func eventFunc(f func(e js.Value)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		f(args[0])
+		return nil
+	})
+}
+
 func RunMainThreadLoop(ch <-chan error) error {
 	return <-ch
 }
 
 func Run(width, height int, scale float64, title string, g GraphicsContext, mainloop bool) error {
 	u := currentUI
-	doc := js.Global.Get("document")
-	doc.Set("title", title)
+	if title != "" {
+		doc := js.Global().Get("document")
+		doc.Set("title", title)
+	}
 	u.setScreenSize(width, height, scale, u.fullscreen)
 	canvas.Call("focus")
 	if err := opengl.Init(); err != nil {
 		return err
 	}
+	u.running = true
 	return u.loop(g)
 }
 
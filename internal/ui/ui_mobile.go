@@ -19,6 +19,7 @@ package ui
 import (
 	"errors"
 	"image"
+	"math"
 	"runtime"
 	"sync"
 	"time"
@@ -31,6 +32,7 @@ import (
 	"golang.org/x/mobile/gl"
 
 	"github.com/hajimehoshi/ebiten/internal/devicescale"
+	"github.com/hajimehoshi/ebiten/internal/hooks"
 	"github.com/hajimehoshi/ebiten/internal/input"
 	"github.com/hajimehoshi/ebiten/internal/opengl"
 )
@@ -70,6 +72,22 @@ type userInterface struct {
 	fullscreenWidthPx  int
 	fullscreenHeightPx int
 
+	// Used for gomobile-bind
+	viewWidth  float64
+	viewHeight float64
+
+	// safeAreaInsets is the left, top, right and bottom safe area insets of the view or the display
+	// in device-independent pixels.
+	safeAreaInsets [4]float64
+
+	// foreground indicates whether the app is visible.
+	// In gomobile-bind mode, this is updated only by SetForeground.
+	foreground bool
+
+	// contextLost indicates whether the OpenGL context is lost.
+	// This is used only in gomobile-bind mode. See Invalidate.
+	contextLost bool
+
 	m sync.RWMutex
 }
 
@@ -82,6 +100,7 @@ func appMain(a app.App) {
 		case lifecycle.Event:
 			switch e.Crosses(lifecycle.StageVisible) {
 			case lifecycle.CrossOn:
+				currentUI.setForeground(true)
 				glctx, _ = e.DrawContext.(gl.Context)
 				// Assume that glctx is always a same instance.
 				// Then, only once initializing should be enough.
@@ -91,6 +110,7 @@ func appMain(a app.App) {
 				}
 				a.Send(paint.Event{})
 			case lifecycle.CrossOff:
+				currentUI.setForeground(false)
 				glctx = nil
 			}
 		case size.Event:
@@ -138,6 +158,9 @@ func Run(width, height int, scale float64, title string, g GraphicsContext, main
 		ctx := <-glContextCh
 		opengl.InitWithContext(ctx)
 	} else {
+		// The app lifecycle is not available in gomobile-bind mode.
+		// Assume that the app is in foreground while Update is called.
+		u.setForeground(true)
 		opengl.Init()
 	}
 
@@ -206,6 +229,14 @@ func (u *userInterface) update(g GraphicsContext) error {
 		renderChEnd <- struct{}{}
 	}()
 
+	u.m.Lock()
+	contextLost := u.contextLost
+	u.contextLost = false
+	u.m.Unlock()
+	if contextLost {
+		g.Invalidate()
+	}
+
 	u.updateGraphicsContext(g)
 
 	if err := g.Update(func() {
@@ -292,10 +323,63 @@ func (u *userInterface) updateFullscreenScaleIfNeeded() {
 	u.fullscreenScale = scale / devicescale.DeviceScale()
 }
 
+// SetViewSize sets the size of the view where the game is rendered in device-independent pixels.
+//
+// SetViewSize is used in gomobile-bind mode.
+func SetViewSize(width, height float64) {
+	u := currentUI
+	u.m.Lock()
+	u.viewWidth = width
+	u.viewHeight = height
+	u.m.Unlock()
+}
+
+func OutsideSize() (width, height int) {
+	u := currentUI
+	u.m.RLock()
+	defer u.m.RUnlock()
+	if u.fullscreenWidthPx != 0 && u.fullscreenHeightPx != 0 {
+		s := devicescale.DeviceScale()
+		return int(float64(u.fullscreenWidthPx) / s), int(float64(u.fullscreenHeightPx) / s)
+	}
+	if u.viewWidth != 0 && u.viewHeight != 0 {
+		return int(u.viewWidth), int(u.viewHeight)
+	}
+	return int(float64(u.width) * u.scale), int(float64(u.height) * u.scale)
+}
+
 func ScreenPadding() (x0, y0, x1, y1 float64) {
 	return currentUI.screenPadding()
 }
 
+// SetSafeAreaInsets sets the safe area insets of the view or the display in device-independent pixels.
+func SetSafeAreaInsets(left, top, right, bottom float64) {
+	u := currentUI
+	u.m.Lock()
+	u.safeAreaInsets = [4]float64{left, top, right, bottom}
+	u.m.Unlock()
+}
+
+func SafeAreaInsets() (left, top, right, bottom float64) {
+	u := currentUI
+	u.m.RLock()
+	defer u.m.RUnlock()
+
+	i := u.safeAreaInsets
+	if u.fullscreenWidthPx == 0 && u.viewWidth != 0 && u.viewHeight != 0 {
+		// In gomobile-bind mode, the insets are of the view containing the game view,
+		// which is placed at the center.
+		ox := (u.viewWidth - float64(u.width)*u.scale) / 2
+		oy := (u.viewHeight - float64(u.height)*u.scale) / 2
+		i[0] -= ox
+		i[1] -= oy
+		i[2] -= ox
+		i[3] -= oy
+	}
+	d := devicescale.DeviceScale()
+	return math.Max(0, i[0]) * d, math.Max(0, i[1]) * d, math.Max(0, i[2]) * d, math.Max(0, i[3]) * d
+}
+
 func (u *userInterface) screenPadding() (x0, y0, x1, y1 float64) {
 	u.m.Lock()
 	x0, y0, x1, y1 = u.screenPaddingImpl()
@@ -360,10 +444,111 @@ func SetRunnableInBackground(runnableInBackground bool) {
 	// Do nothing
 }
 
+func IsFocused() bool {
+	u := currentUI
+	u.m.RLock()
+	v := u.foreground
+	u.m.RUnlock()
+	return v
+}
+
+func (u *userInterface) setForeground(foreground bool) {
+	u.m.Lock()
+	u.foreground = foreground
+	u.m.Unlock()
+
+	// The game is not updated here: the rendering thread is already paused, or not resumed yet.
+	if foreground {
+		hooks.Resume()
+	} else {
+		hooks.Suspend()
+	}
+}
+
+// SetForeground sets whether the app is in foreground.
+//
+// SetForeground is used in gomobile-bind mode, where the app lifecycle is not available.
+func SetForeground(foreground bool) {
+	currentUI.setForeground(foreground)
+}
+
+// Invalidate notifies that the OpenGL context is lost.
+// The graphics resources are restored at the next update.
+//
+// Invalidate is used in gomobile-bind mode.
+func Invalidate() {
+	u := currentUI
+	u.m.Lock()
+	u.contextLost = true
+	u.m.Unlock()
+}
+
+func SetVsyncEnabled(enabled bool) {
+	// Do nothing
+}
+
+func IsVsyncEnabled() bool {
+	return true
+}
+
+func RefreshRate() int {
+	// TODO: Get the refresh rate from the view (e.g. CADisplayLink or Display.getRefreshRate).
+	return 0
+}
+
+func CanSkipPresenting() bool {
+	// The view presents the framebuffer every frame, whose contents are undefined after presenting.
+	return false
+}
+
 func SetWindowIcon(iconImages []image.Image) {
 	// Do nothing
 }
 
+func SetWindowSizeLimits(minw, minh, maxw, maxh int) {
+	// Do nothing
+}
+
+func WindowSizeLimits() (minw, minh, maxw, maxh int) {
+	return -1, -1, -1, -1
+}
+
+func SetWindowAspectRatioLocked(locked bool) {
+	// Do nothing
+}
+
+func IsWindowAspectRatioLocked() bool {
+	return false
+}
+
+func RequestWindowAttention() {
+	// Do nothing
+}
+
+func SetWindowProgress(progress float64) {
+	// Do nothing
+}
+
+func SetWindowTitle(title string) {
+	// Do nothing
+}
+
+func IsWindowFloating() bool {
+	return false
+}
+
+func SetWindowFloating(floating bool) {
+	// Do nothing
+}
+
+func IsScreenTransparent() bool {
+	return false
+}
+
+func SetScreenTransparent(transparent bool) {
+	// Do nothing
+}
+
 func IsWindowDecorated() bool {
 	return false
 }
@@ -372,6 +557,10 @@ func SetWindowDecorated(decorated bool) {
 	// Do nothing
 }
 
+func NewWindow(width, height int, scale float64, title string) (Window, error) {
+	return nil, errors.New("ui: multiple windows are not supported on mobiles")
+}
+
 func UpdateTouches(touches []*input.Touch) {
 	input.Get().UpdateTouches(touches)
 }
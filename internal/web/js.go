@@ -19,11 +19,11 @@ package web
 import (
 	"strings"
 
-	"github.com/gopherjs/gopherjs/js"
+	"github.com/hajimehoshi/ebiten/internal/js"
 )
 
 func IsNodeJS() bool {
-	return js.Global.Get("require") != js.Undefined
+	return js.Global().Get("require").Type() != js.TypeUndefined
 }
 
 func IsBrowser() bool {
@@ -31,7 +31,7 @@ func IsBrowser() bool {
 }
 
 func IsIOSSafari() bool {
-	ua := js.Global.Get("navigator").Get("userAgent").String()
+	ua := js.Global().Get("navigator").Get("userAgent").String()
 	if !strings.Contains(ua, "iPhone") {
 		return false
 	}
@@ -39,7 +39,7 @@ func IsIOSSafari() bool {
 }
 
 func IsAndroidChrome() bool {
-	ua := js.Global.Get("navigator").Get("userAgent").String()
+	ua := js.Global().Get("navigator").Get("userAgent").String()
 	if !strings.Contains(ua, "Android") {
 		return false
 	}
@@ -0,0 +1,272 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// largeImageTileSize is the maximum size of a tile of a LargeImage.
+//
+// This is small enough to be a texture on any supported environment.
+const largeImageTileSize = 2048
+
+// LargeImage represents a rectangle set of pixels that can be larger than MaxImageSize.
+//
+// LargeImage holds its pixels in multiple images as tiles, and draw operations are split into
+// per-tile DrawImage calls. This is useful e.g. for huge world maps.
+//
+// As tiles are separate textures, texels across tile boundaries are not interpolated with FilterLinear.
+//
+// LargeImage implements image.Image.
+type LargeImage struct {
+	width  int
+	height int
+
+	// tiles is the tiles in row-major order.
+	tiles []*Image
+	cols  int
+}
+
+// NewLargeImage returns an empty large image.
+//
+// If width or height is less than 1, NewLargeImage panics.
+//
+// Error returned by NewLargeImage is always nil.
+func NewLargeImage(width, height int, filter Filter) (*LargeImage, error) {
+	if width < 1 || height < 1 {
+		panic(fmt.Sprintf("ebiten: width (%d) and height (%d) must be equal or more than 1", width, height))
+	}
+	l := &LargeImage{
+		width:  width,
+		height: height,
+		cols:   (width-1)/largeImageTileSize + 1,
+	}
+	rows := (height-1)/largeImageTileSize + 1
+	for j := 0; j < rows; j++ {
+		for i := 0; i < l.cols; i++ {
+			r := l.tileRect(i, j)
+			t, _ := NewImage(r.Dx(), r.Dy(), filter)
+			// The tiles are disposed with the large image.
+			theImages.remove(t)
+			l.tiles = append(l.tiles, t)
+		}
+	}
+	return l, nil
+}
+
+// tileRect returns the region of the tile at the column i and the row j.
+func (l *LargeImage) tileRect(i, j int) image.Rectangle {
+	x, y := i*largeImageTileSize, j*largeImageTileSize
+	return image.Rect(x, y, x+largeImageTileSize, y+largeImageTileSize).Intersect(l.Bounds())
+}
+
+func (l *LargeImage) isDisposed() bool {
+	return l.tiles == nil
+}
+
+// Size returns the size of the image.
+func (l *LargeImage) Size() (width, height int) {
+	return l.width, l.height
+}
+
+// Bounds returns the bounds of the image.
+func (l *LargeImage) Bounds() image.Rectangle {
+	return image.Rect(0, 0, l.width, l.height)
+}
+
+// ColorModel returns the color model of the image.
+func (l *LargeImage) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// At returns the color of the image at (x, y).
+//
+// At has the same restrictions as Image.At.
+func (l *LargeImage) At(x, y int) color.Color {
+	if l.isDisposed() {
+		return color.RGBA{}
+	}
+	if !image.Pt(x, y).In(l.Bounds()) {
+		return color.RGBA{}
+	}
+	i, j := x/largeImageTileSize, y/largeImageTileSize
+	return l.tiles[j*l.cols+i].At(x-i*largeImageTileSize, y-j*largeImageTileSize)
+}
+
+// Clear resets the pixels of the image into 0.
+//
+// When the image is disposed, Clear does nothing.
+//
+// Clear always returns nil.
+func (l *LargeImage) Clear() error {
+	for _, t := range l.tiles {
+		t.Clear()
+	}
+	return nil
+}
+
+// Fill fills the image with a solid color.
+//
+// When the image is disposed, Fill does nothing.
+//
+// Fill always returns nil.
+func (l *LargeImage) Fill(clr color.Color) error {
+	for _, t := range l.tiles {
+		t.Fill(clr)
+	}
+	return nil
+}
+
+// DrawImage draws the given image on the large image l.
+//
+// DrawImage works in the same way as Image.DrawImage, but the drawing is split into the tiles.
+// Tiles that the drawn region doesn't overlap are skipped.
+//
+// When the image l is disposed, DrawImage does nothing.
+//
+// DrawImage always returns nil.
+func (l *LargeImage) DrawImage(img *Image, options *DrawImageOptions) error {
+	if l.isDisposed() {
+		return nil
+	}
+	if options == nil {
+		options = &DrawImageOptions{}
+	}
+
+	// The bounding box of the drawn region on l. ImageParts is not considered here.
+	bounds := l.Bounds()
+	if options.ImageParts == nil && options.Parts == nil {
+		r := img.Bounds()
+		if options.SourceRect != nil {
+			r = r.Intersect(*options.SourceRect)
+		}
+		w, h := float64(r.Dx()), float64(r.Dy())
+		minX, minY := math.Inf(1), math.Inf(1)
+		maxX, maxY := math.Inf(-1), math.Inf(-1)
+		for _, p := range [][2]float64{{0, 0}, {w, 0}, {0, h}, {w, h}} {
+			x, y := options.GeoM.Apply(p[0], p[1])
+			minX, minY = math.Min(minX, x), math.Min(minY, y)
+			maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+		}
+		// Extend the box by 1 pixel so that the edge pixels are not missed.
+		bounds = image.Rect(int(math.Floor(minX))-1, int(math.Floor(minY))-1, int(math.Ceil(maxX))+1, int(math.Ceil(maxY))+1)
+	}
+
+	for idx, t := range l.tiles {
+		tr := l.tileRect(idx%l.cols, idx/l.cols)
+		if !tr.Overlaps(bounds) {
+			continue
+		}
+		op := *options
+		op.GeoM.Translate(-float64(tr.Min.X), -float64(tr.Min.Y))
+		if options.ClipRect != nil {
+			c := options.ClipRect.Sub(tr.Min)
+			op.ClipRect = &c
+		}
+		t.DrawImage(img, &op)
+	}
+	return nil
+}
+
+// DrawLargeImage draws the given large image on the image i.
+//
+// DrawLargeImage works in the same way as DrawImage, but the drawing is split into the tiles of img.
+// options.ImageParts and options.Parts are ignored.
+//
+// When the image i is disposed, DrawLargeImage does nothing.
+// When the given image img is disposed, DrawLargeImage panics.
+//
+// DrawLargeImage always returns nil.
+func (i *Image) DrawLargeImage(img *LargeImage, options *DrawImageOptions) error {
+	if img.isDisposed() {
+		panic("ebiten: the given image to DrawLargeImage must not be disposed")
+	}
+	if i.isDisposed() {
+		return nil
+	}
+	if options == nil {
+		options = &DrawImageOptions{}
+	}
+
+	r := img.Bounds()
+	var origin image.Point
+	if options.SourceRect != nil {
+		r = r.Intersect(*options.SourceRect)
+		origin = options.SourceRect.Min
+	}
+
+	for idx, t := range img.tiles {
+		tr := img.tileRect(idx%img.cols, idx/img.cols)
+		part := tr.Intersect(r)
+		if part.Empty() {
+			continue
+		}
+		op := *options
+		op.ImageParts = nil
+		op.Parts = nil
+		sr := part.Sub(tr.Min)
+		op.SourceRect = &sr
+		op.GeoM = GeoM{}
+		op.GeoM.Translate(float64(part.Min.X-origin.X), float64(part.Min.Y-origin.Y))
+		op.GeoM.Concat(options.GeoM)
+		i.DrawImage(t, &op)
+	}
+	return nil
+}
+
+// ReplacePixels replaces the pixels of the image with p.
+//
+// The given p must represent RGBA pre-multiplied alpha values. len(p) must equal to 4 * (image width) * (image height).
+//
+// When len(p) is not appropriate, ReplacePixels panics.
+//
+// When the image is disposed, ReplacePixels does nothing.
+//
+// ReplacePixels always returns nil.
+func (l *LargeImage) ReplacePixels(p []byte) error {
+	if l.isDisposed() {
+		return nil
+	}
+	if s := 4 * l.width * l.height; len(p) != s {
+		panic(fmt.Sprintf("ebiten: len(p) was %d but must be %d", len(p), s))
+	}
+	for idx, t := range l.tiles {
+		tr := l.tileRect(idx%l.cols, idx/l.cols)
+		pix := make([]byte, 4*tr.Dx()*tr.Dy())
+		for y := tr.Min.Y; y < tr.Max.Y; y++ {
+			src := p[4*(y*l.width+tr.Min.X) : 4*(y*l.width+tr.Max.X)]
+			copy(pix[4*(y-tr.Min.Y)*tr.Dx():], src)
+		}
+		t.ReplacePixels(pix)
+	}
+	return nil
+}
+
+// Dispose disposes the image data.
+//
+// When the image is disposed, Dispose does nothing.
+//
+// Dispose always returns nil.
+func (l *LargeImage) Dispose() error {
+	for _, t := range l.tiles {
+		t.Dispose()
+	}
+	l.tiles = nil
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten"
+)
+
+func TestLargeImageDrawImage(t *testing.T) {
+	// The large image consists of 2x2 tiles.
+	l, _ := NewLargeImage(2100, 2100, FilterDefault)
+	defer l.Dispose()
+
+	src, _ := NewImage(100, 100, FilterDefault)
+	src.Fill(color.RGBA{0xff, 0, 0, 0xff})
+
+	// Draw the image across the tile boundaries.
+	op := &DrawImageOptions{}
+	op.GeoM.Translate(2000, 2000)
+	l.DrawImage(src, op)
+
+	testCases := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{1999, 2050, color.RGBA{}},
+		{2050, 1999, color.RGBA{}},
+		{2000, 2000, color.RGBA{0xff, 0, 0, 0xff}},
+		{2047, 2047, color.RGBA{0xff, 0, 0, 0xff}},
+		{2048, 2047, color.RGBA{0xff, 0, 0, 0xff}},
+		{2047, 2048, color.RGBA{0xff, 0, 0, 0xff}},
+		{2099, 2099, color.RGBA{0xff, 0, 0, 0xff}},
+	}
+	for _, c := range testCases {
+		got := l.At(c.x, c.y).(color.RGBA)
+		if got != c.want {
+			t.Errorf("At(%d, %d): got: %v, want: %v", c.x, c.y, got, c.want)
+		}
+	}
+}
+
+func TestLargeImageDrawLargeImage(t *testing.T) {
+	const w, h = 2100, 2060
+	l, _ := NewLargeImage(w, h, FilterDefault)
+	defer l.Dispose()
+
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			idx := 4 * (j*w + i)
+			pix[idx] = byte(i)
+			pix[idx+1] = byte(j)
+			pix[idx+2] = byte(i + j)
+			pix[idx+3] = 0xff
+		}
+	}
+	l.ReplacePixels(pix)
+
+	dst, _ := NewImage(64, 64, FilterDefault)
+	op := &DrawImageOptions{}
+	r := image.Rect(2020, 2030, 2084, 2094)
+	op.SourceRect = &r
+	dst.DrawLargeImage(l, op)
+
+	for j := 0; j < 64; j++ {
+		for i := 0; i < 64; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			want := color.RGBA{}
+			if x, y := i+r.Min.X, j+r.Min.Y; x < w && y < h {
+				want = color.RGBA{byte(x), byte(y), byte(x + y), 0xff}
+			}
+			if got != want {
+				t.Errorf("At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ebiten
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/hajimehoshi/ebiten/internal/graphics"
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+// LayoutPolicy represents how the screen is laid out in the screen framebuffer.
+type LayoutPolicy int
+
+const (
+	// LayoutPolicyFit scales the screen to fit while keeping the aspect ratio, and letterboxes or pillarboxes
+	// the rest with black. This is the default layout policy.
+	LayoutPolicyFit LayoutPolicy = iota
+
+	// LayoutPolicyFill scales the screen to fill the whole screen framebuffer while keeping the aspect ratio.
+	// The edges of the screen out of the screen framebuffer are cropped.
+	LayoutPolicyFill
+
+	// LayoutPolicyStretch scales the screen to fill the whole screen framebuffer without keeping the aspect ratio.
+	LayoutPolicyStretch
+
+	// LayoutPolicyInteger scales the screen by the largest integer that fits, and letterboxes the rest with black.
+	// If the screen doesn't fit even at the scale 1, the screen is scaled down to fit.
+	LayoutPolicyInteger
+)
+
+var layoutPolicy = int32(LayoutPolicyFit)
+
+// CurrentLayoutPolicy returns the current layout policy.
+//
+// This function is concurrent-safe.
+func CurrentLayoutPolicy() LayoutPolicy {
+	return LayoutPolicy(atomic.LoadInt32(&layoutPolicy))
+}
+
+// SetLayoutPolicy sets how the screen is laid out in the screen framebuffer.
+//
+// The screen framebuffer is the whole display on fullscreen mode, and otherwise the window, the canvas or the view
+// sized by the screen size and the screen scale. In the latter case, the screen framebuffer usually has the same
+// aspect ratio as the screen, and the layout policy matters only with LayoutPolicyInteger at a fractional scale.
+//
+// The layout policy is applied to the windows created by NewWindow too.
+// CursorPosition, Touches and SafeAreaInsets return the positions and the sizes on the screen laid out by the policy.
+//
+// The default layout policy is LayoutPolicyFit.
+//
+// This function is concurrent-safe.
+func SetLayoutPolicy(policy LayoutPolicy) {
+	atomic.StoreInt32(&layoutPolicy, int32(policy))
+}
+
+// SafeAreaInsets returns the insets of the screen in pixels where the contents might be hidden by the display's
+// notches and rounded corners or overlapped by the system UI.
+//
+// Place important elements like HUD inside the insets. The layout policy is taken into account:
+// e.g., when a notch is in a letterbox, the inset is 0, and with LayoutPolicyFill, the cropped edges are included
+// in the insets.
+//
+// The safe area insets are available only on mobiles with the views generated by ebitenmobile.
+// SafeAreaInsets returns zeros on the other environments and before the screen is rendered first.
+//
+// This function is concurrent-safe.
+func SafeAreaInsets() (left, top, right, bottom int) {
+	m, ok := theScreenMapping.Load().(screenMapping)
+	if !ok {
+		return 0, 0, 0, 0
+	}
+	return m.insets(ui.SafeAreaInsets())
+}
+
+// There are three coordinate spaces for positions on the main screen:
+//
+// Screen space:
+//   The logical game resolution. CursorPosition, Touches and the screen image passed to the update function use
+//   this space.
+//
+// Device space:
+//   The physical pixels of the screen framebuffer, where the origin is the upper-left corner of the window's content
+//   area, the canvas, the view, or the display on fullscreen mode. Device space includes the letterboxes and the
+//   padding.
+//
+// Window space:
+//   The device-independent pixels of the screen framebuffer, which is device space divided by DeviceScaleFactor.
+//   Window space is the unit of the window size, CSS pixels on browsers, dp on Android and points on iOS.
+//
+// The conversions follow the layout policy and the screen filter at the last rendering. Before the screen is
+// rendered first, the screen is assumed to be rendered at ScreenScale without any offsets.
+
+// ScreenToDevice converts the position (x, y) in screen space into device space.
+//
+// This function is concurrent-safe.
+func ScreenToDevice(x, y float64) (float64, float64) {
+	return currentScreenMapping().toDevice(x, y)
+}
+
+// DeviceToScreen converts the position (x, y) in device space into screen space.
+//
+// The result can be out of the screen, e.g., when the position is in a letterbox.
+//
+// This function is concurrent-safe.
+func DeviceToScreen(x, y float64) (float64, float64) {
+	return currentScreenMapping().fromDevice(x, y)
+}
+
+// ScreenToWindow converts the position (x, y) in screen space into window space.
+//
+// This function is concurrent-safe.
+func ScreenToWindow(x, y float64) (float64, float64) {
+	x, y = ScreenToDevice(x, y)
+	d := DeviceScaleFactor()
+	return x / d, y / d
+}
+
+// WindowToScreen converts the position (x, y) in window space into screen space.
+//
+// The result can be out of the screen, e.g., when the position is in a letterbox.
+//
+// This function is concurrent-safe.
+func WindowToScreen(x, y float64) (float64, float64) {
+	d := DeviceScaleFactor()
+	return DeviceToScreen(x*d, y*d)
+}
+
+// currentScreenMapping returns the screenMapping of the main screen at the last rendering.
+//
+// If the main screen is not rendered yet, currentScreenMapping returns the mapping at the current screen scale.
+func currentScreenMapping() screenMapping {
+	if m, ok := theScreenMapping.Load().(screenMapping); ok {
+		return m
+	}
+	s := ScreenScale() * DeviceScaleFactor()
+	if s == 0 {
+		s = DeviceScaleFactor()
+	}
+	return screenMapping{
+		uiScale: s,
+		scaleX:  s,
+		scaleY:  s,
+	}
+}
+
+// screenMapping represents how an image is rendered on the screen framebuffer.
+//
+// The positions and the sizes are in the pixels of the screen framebuffer,
+// where the origin is the upper-left corner.
+type screenMapping struct {
+	// width and height are the size of the image.
+	width  int
+	height int
+
+	// areaWidth and areaHeight are the size of the screen framebuffer including the padding.
+	areaWidth  float64
+	areaHeight float64
+
+	// uiScale, uiOffsetX and uiOffsetY are the scale and the position of the image, which the UI assumes to
+	// calculate the input positions.
+	uiScale   float64
+	uiOffsetX float64
+	uiOffsetY float64
+
+	// scaleX, scaleY, offsetX and offsetY are the actual scale and the position of the rendered image.
+	scaleX  float64
+	scaleY  float64
+	offsetX float64
+	offsetY float64
+}
+
+// newScreenMapping returns the mapping to render an image of (sw, sh) on a screen image of (dw, dh) with the padding
+// (px0, py0) - (px1, py1) by the layout policy and the screen filter.
+func newScreenMapping(policy LayoutPolicy, filter ScreenFilter, sw, sh, dw, dh int, px0, py0, px1, py1 float64) screenMapping {
+	s := float64(dw) / float64(sw)
+	m := screenMapping{
+		width:      sw,
+		height:     sh,
+		areaWidth:  px0 + float64(dw) + px1,
+		areaHeight: py0 + float64(dh) + py1,
+		uiScale:    s,
+		uiOffsetX:  px0,
+		uiOffsetY:  py0,
+		scaleX:     s,
+		scaleY:     s,
+		offsetX:    px0,
+		offsetY:    py0,
+	}
+	if filter == ScreenFilterInteger {
+		policy = LayoutPolicyInteger
+	}
+
+	fx := m.areaWidth / float64(sw)
+	fy := m.areaHeight / float64(sh)
+	switch policy {
+	case LayoutPolicyFill:
+		s = math.Max(fx, fy)
+	case LayoutPolicyStretch:
+		m.scaleX, m.scaleY = fx, fy
+		m.offsetX, m.offsetY = 0, 0
+		return m
+	case LayoutPolicyInteger:
+		s = math.Min(fx, fy)
+		if math.Floor(s) >= 1 {
+			s = math.Floor(s)
+		}
+	default:
+		// The UI already fits the screen in the screen framebuffer.
+		return m
+	}
+	m.scaleX, m.scaleY = s, s
+	m.offsetX = math.Floor((m.areaWidth - float64(sw)*s) / 2)
+	m.offsetY = math.Floor((m.areaHeight - float64(sh)*s) / 2)
+	return m
+}
+
+// coversArea reports whether the rendered image covers the whole screen framebuffer.
+func (m screenMapping) coversArea() bool {
+	return m.offsetX <= 0 && m.offsetY <= 0 &&
+		m.areaWidth <= m.offsetX+float64(m.width)*m.scaleX &&
+		m.areaHeight <= m.offsetY+float64(m.height)*m.scaleY
+}
+
+// adjustPosition converts the position calculated by the UI into the position in the rendered image.
+func (m screenMapping) adjustPosition(x, y int) (int, int) {
+	if m.scaleX == m.uiScale && m.scaleY == m.uiScale && m.offsetX == m.uiOffsetX && m.offsetY == m.uiOffsetY {
+		return x, y
+	}
+	// Use the center of the pixel to reduce the rounding error.
+	ax := (float64(x)+0.5)*m.uiScale + m.uiOffsetX
+	ay := (float64(y)+0.5)*m.uiScale + m.uiOffsetY
+	return int(math.Floor((ax - m.offsetX) / m.scaleX)), int(math.Floor((ay - m.offsetY) / m.scaleY))
+}
+
+// toDevice converts the position in the rendered image into the position in the screen framebuffer.
+func (m screenMapping) toDevice(x, y float64) (float64, float64) {
+	return x*m.scaleX + m.offsetX, y*m.scaleY + m.offsetY
+}
+
+// fromDevice converts the position in the screen framebuffer into the position in the rendered image.
+func (m screenMapping) fromDevice(x, y float64) (float64, float64) {
+	return (x - m.offsetX) / m.scaleX, (y - m.offsetY) / m.scaleY
+}
+
+// insets converts the insets of the screen framebuffer into the insets of the rendered image.
+func (m screenMapping) insets(left, top, right, bottom float64) (int, int, int, int) {
+	inset := func(v, margin, scale float64, size int) int {
+		i := int(math.Ceil(math.Max(0, v-margin) / scale))
+		if i > size {
+			return size
+		}
+		return i
+	}
+	mr := m.areaWidth - (m.offsetX + float64(m.width)*m.scaleX)
+	mb := m.areaHeight - (m.offsetY + float64(m.height)*m.scaleY)
+	return inset(left, m.offsetX, m.scaleX, m.width),
+		inset(top, m.offsetY, m.scaleY, m.height),
+		inset(right, mr, m.scaleX, m.width),
+		inset(bottom, mb, m.scaleY, m.height)
+}
+
+// theScreenMapping is the screenMapping of the main screen at the last rendering.
+var theScreenMapping atomic.Value
+
+// adjustScreenPosition converts the position on the main screen calculated by the UI into the position on the
+// screen actually rendered, which differs by the layout policy.
+func adjustScreenPosition(x, y int) (int, int) {
+	m, ok := theScreenMapping.Load().(screenMapping)
+	if !ok {
+		return x, y
+	}
+	return m.adjustPosition(x, y)
+}
+
+// renderOnScreen renders src on the screen image dst with the mapping and the screen filter.
+//
+// If src doesn't cover the whole screen framebuffer, the screen framebuffer is cleared first.
+func renderOnScreen(dst, src *Image, m screenMapping, filter ScreenFilter) {
+	// Clear the screen framebuffer by DrawImage instad of Fill
+	// to clear the whole region including fullscreen's padding.
+	// TODO: This clear is needed only when the screen size is changed.
+	if !m.coversArea() {
+		op := &DrawImageOptions{}
+		w, h := emptyImage.Size()
+		s := float64(graphics.MaxImageSize())
+		op.GeoM.Scale(s/float64(w), s/float64(h))
+		op.CompositeMode = CompositeModeCopy
+		dst.DrawImage(emptyImage, op)
+	}
+
+	op := &DrawImageOptions{}
+	op.GeoM.Scale(m.scaleX, m.scaleY)
+	op.GeoM.Translate(m.offsetX, m.offsetY)
+	// The screen framebuffer's Y axis is down to up, and the origin point is lower left.
+	op.GeoM.Scale(1, -1)
+	op.GeoM.Translate(0, m.areaHeight)
+	op.CompositeMode = CompositeModeCopy
+	op.Filter = filter.filter()
+	_ = dst.DrawImage(src, op)
+}
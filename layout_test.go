@@ -0,0 +1,126 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ebiten
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScreenMapping(t *testing.T) {
+	cases := []struct {
+		Policy  LayoutPolicy
+		Filter  ScreenFilter
+		DW, DH  int
+		Padding float64
+		ScaleX  float64
+		ScaleY  float64
+		OffsetX float64
+		OffsetY float64
+	}{
+		{LayoutPolicyFit, ScreenFilterSharpBilinear, 800, 600, 0, 2.5, 2.5, 0, 0},
+		{LayoutPolicyFit, ScreenFilterNearest, 800, 600, 0, 2.5, 2.5, 0, 0},
+		{LayoutPolicyFit, ScreenFilterInteger, 800, 600, 0, 2, 2, 80, 60},
+		{LayoutPolicyFit, ScreenFilterInteger, 640, 480, 0, 2, 2, 0, 0},
+		{LayoutPolicyFit, ScreenFilterInteger, 160, 120, 0, 0.5, 0.5, 0, 0},
+		{LayoutPolicyFit, ScreenFilterSharpBilinear, 800, 600, 100, 2.5, 2.5, 100, 0},
+		{LayoutPolicyFill, ScreenFilterSharpBilinear, 800, 600, 100, 3.125, 3.125, 0, -75},
+		{LayoutPolicyStretch, ScreenFilterSharpBilinear, 800, 600, 100, 3.125, 2.5, 0, 0},
+		{LayoutPolicyInteger, ScreenFilterSharpBilinear, 800, 600, 100, 2, 2, 180, 60},
+		{LayoutPolicyInteger, ScreenFilterSharpBilinear, 800, 600, 0, 2, 2, 80, 60},
+	}
+	for _, c := range cases {
+		m := newScreenMapping(c.Policy, c.Filter, 320, 240, c.DW, c.DH, c.Padding, 0, c.Padding, 0)
+		if m.scaleX != c.ScaleX || m.scaleY != c.ScaleY || m.offsetX != c.OffsetX || m.offsetY != c.OffsetY {
+			t.Errorf("newScreenMapping(%d, %d, 320, 240, %d, %d, %f, 0, %f, 0): got: (%f, %f, %f, %f), want: (%f, %f, %f, %f)", c.Policy, c.Filter, c.DW, c.DH, c.Padding, c.Padding, m.scaleX, m.scaleY, m.offsetX, m.offsetY, c.ScaleX, c.ScaleY, c.OffsetX, c.OffsetY)
+		}
+	}
+
+	// The UI assumes the scale 2.5, while the screen is rendered at the scale 2 with the offset (80, 60).
+	m := newScreenMapping(LayoutPolicyFit, ScreenFilterInteger, 320, 240, 800, 600, 0, 0, 0, 0)
+	positions := []struct {
+		X, Y         int
+		WantX, WantY int
+	}{
+		{0, 0, -40, -30},
+		{32, 24, 0, 0},
+		{160, 120, 160, 120},
+		{287, 215, 319, 239},
+	}
+	for _, p := range positions {
+		x, y := m.adjustPosition(p.X, p.Y)
+		if x != p.WantX || y != p.WantY {
+			t.Errorf("adjustPosition(%d, %d): got: (%d, %d), want: (%d, %d)", p.X, p.Y, x, y, p.WantX, p.WantY)
+		}
+	}
+
+	// The UI assumes the offset (100, 0), while the screen is rendered at the offset (0, 0) with LayoutPolicyStretch.
+	m = newScreenMapping(LayoutPolicyStretch, ScreenFilterSharpBilinear, 320, 240, 800, 600, 100, 0, 100, 0)
+	if x, y := m.adjustPosition(0, 0); x != 32 || y != 0 {
+		t.Errorf("adjustPosition(0, 0): got: (%d, %d), want: (%d, %d)", x, y, 32, 0)
+	}
+}
+
+func TestScreenMappingInsets(t *testing.T) {
+	cases := []struct {
+		Policy                   LayoutPolicy
+		Left, Top, Right, Bottom float64
+		Want                     [4]int
+	}{
+		{LayoutPolicyFit, 50, 30, 200, 90, [4]int{0, 12, 40, 36}},
+		{LayoutPolicyFill, 50, 30, 0, 0, [4]int{16, 34, 0, 24}},
+		{LayoutPolicyInteger, 50, 30, 200, 90, [4]int{0, 0, 10, 15}},
+	}
+	for _, c := range cases {
+		m := newScreenMapping(c.Policy, ScreenFilterSharpBilinear, 320, 240, 800, 600, 100, 0, 100, 0)
+		l, t0, r, b := m.insets(c.Left, c.Top, c.Right, c.Bottom)
+		if got := [4]int{l, t0, r, b}; got != c.Want {
+			t.Errorf("policy: %d, insets(%f, %f, %f, %f): got: %v, want: %v", c.Policy, c.Left, c.Top, c.Right, c.Bottom, got, c.Want)
+		}
+	}
+}
+
+func TestScreenMappingDevice(t *testing.T) {
+	m := newScreenMapping(LayoutPolicyInteger, ScreenFilterSharpBilinear, 320, 240, 800, 600, 100, 0, 100, 0)
+	positions := []struct {
+		X, Y   float64
+		DX, DY float64
+	}{
+		{0, 0, 180, 60},
+		{160, 120, 500, 300},
+		{320, 240, 820, 540},
+		{-90, -30, 0, 0},
+		{10.25, 20.5, 200.5, 101},
+	}
+	for _, p := range positions {
+		dx, dy := m.toDevice(p.X, p.Y)
+		if dx != p.DX || dy != p.DY {
+			t.Errorf("toDevice(%f, %f): got: (%f, %f), want: (%f, %f)", p.X, p.Y, dx, dy, p.DX, p.DY)
+		}
+		x, y := m.fromDevice(dx, dy)
+		if x != p.X || y != p.Y {
+			t.Errorf("fromDevice(%f, %f): got: (%f, %f), want: (%f, %f)", dx, dy, x, y, p.X, p.Y)
+		}
+	}
+
+	// The pixel at the position from CursorPosition includes the device position converted by fromDevice.
+	for _, p := range []struct{ X, Y int }{{0, 0}, {32, 24}, {160, 120}, {287, 215}} {
+		m := newScreenMapping(LayoutPolicyFit, ScreenFilterInteger, 320, 240, 800, 600, 0, 0, 0, 0)
+		x, y := m.adjustPosition(p.X, p.Y)
+		fx, fy := m.fromDevice((float64(p.X)+0.5)*m.uiScale, (float64(p.Y)+0.5)*m.uiScale)
+		if x != int(math.Floor(fx)) || y != int(math.Floor(fy)) {
+			t.Errorf("fromDevice and adjustPosition for (%d, %d): got: (%f, %f), want: (%d, %d)", p.X, p.Y, fx, fy, x, y)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/hooks"
+)
+
+// LifecycleHandler is a resource that needs to be handled at the lifecycle events of the app,
+// e.g. a network connection, a timer or a GPU resource that Ebiten doesn't manage.
+//
+// The images created by Ebiten are restored automatically, and the audio players and the input states are
+// suspended and restored by Ebiten. LifecycleHandler is useful to keep the other resources consistent with them.
+type LifecycleHandler interface {
+	// Suspend is called when the app is suspended, e.g. when the app goes to background on mobiles.
+	// The game is not updated until the app is resumed.
+	//
+	// Suspend is called on the thread where the app's lifecycle events are notified, e.g. the UI thread
+	// on Android, while the game is not updated.
+	//
+	// The error returned by Suspend is returned from Run at the next frame.
+	Suspend() error
+
+	// Restore is called at the beginning of the next frame after the app is resumed, or after the images are
+	// restored when the graphics context is lost, e.g. in browsers.
+	// Restore might be called without Suspend.
+	//
+	// Restore is called before the game's update function. The images are already restored at Restore.
+	//
+	// The error returned by Restore is returned from Run.
+	Restore() error
+}
+
+// RegisterLifecycleHandler registers a lifecycle handler.
+//
+// The handlers' Suspend are called in the order of registration, and their Restore are called in the reverse order.
+//
+// h must be comparable, e.g. a pointer. If h is already registered, RegisterLifecycleHandler does nothing.
+//
+// On desktops, the app is never suspended and the graphics context is never lost, so the handlers are not called
+// except for Restore after SimulateContextLoss.
+//
+// This function is concurrent-safe.
+func RegisterLifecycleHandler(h LifecycleHandler) {
+	hooks.AddLifecycleHandler(h)
+}
+
+// UnregisterLifecycleHandler unregisters a lifecycle handler registered by RegisterLifecycleHandler.
+//
+// If h is not registered, UnregisterLifecycleHandler does nothing.
+//
+// This function is concurrent-safe.
+func UnregisterLifecycleHandler(h LifecycleHandler) {
+	hooks.RemoveLifecycleHandler(h)
+}
+
+// IsSuspended reports whether the app is suspended.
+//
+// IsSuspended is useful in a goroutine working in background, e.g. to stop downloading assets while the app is
+// suspended.
+//
+// This function is concurrent-safe.
+func IsSuspended() bool {
+	return hooks.IsSuspended()
+}
@@ -0,0 +1,312 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lighting provides 2D dynamic lights and hard shadows.
+//
+// A Scene accumulates its lights into a light buffer additively, cuts the shadows of occluders
+// out of each light, and multiplies the light buffer onto the target image.
+//
+// Note: This package is experimental and API might be changed.
+package lighting
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// Point represents a point in the target image's coordinates.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Light represents a point light or a spot light.
+type Light struct {
+	// X and Y are the position of the light.
+	X float64
+	Y float64
+
+	// Radius is the distance that the light reaches.
+	Radius float64
+
+	// Color is the color of the light. If Color is nil, the light is white.
+	Color color.Color
+
+	// Direction is the direction of a spot light in radian.
+	// Angle is the angle of the cone of a spot light in radian.
+	// If Angle is 0 or more than or equal to 2π, the light is a point light and Direction is ignored.
+	Direction float64
+	Angle     float64
+}
+
+func (l *Light) isSpot() bool {
+	return l.Angle > 0 && l.Angle < 2*math.Pi
+}
+
+// Occluder is a polygon that casts shadows.
+//
+// The inside of an occluder is in its own shadow.
+type Occluder struct {
+	// Points are the vertices of the polygon.
+	Points []Point
+
+	// Open indicates whether the polygon is open, i.e., the last point is not connected to the first point.
+	// An open occluder with two points is a wall.
+	Open bool
+}
+
+func (o *Occluder) segments(f func(p0, p1 Point)) {
+	n := len(o.Points)
+	if n < 2 {
+		return
+	}
+	for i := 0; i < n-1; i++ {
+		f(o.Points[i], o.Points[i+1])
+	}
+	if !o.Open && n > 2 {
+		f(o.Points[n-1], o.Points[0])
+	}
+}
+
+// Scene is a set of lights and occluders.
+//
+// The zero value of Scene is a scene without any lights, which is totally dark.
+type Scene struct {
+	// Ambient is the color of the light in the whole scene. If Ambient is nil, the ambient light is black.
+	Ambient color.Color
+
+	// Lights are the lights in the scene.
+	Lights []*Light
+
+	// Occluders are the occluders in the scene.
+	Occluders []*Occluder
+
+	buffer  *ebiten.Image
+	scratch *ebiten.Image
+}
+
+const (
+	falloffImageSize  = 256
+	triangleImageSize = 256
+)
+
+var (
+	falloffImage  *ebiten.Image
+	triangleImage *ebiten.Image
+)
+
+// ensureImages creates the shared images lazily.
+func ensureImages() {
+	if falloffImage != nil {
+		return
+	}
+
+	// falloffImage is a white circle fading out quadratically to its edge.
+	// The colors are premultiplied so that the image can be added as it is.
+	f := image.NewRGBA(image.Rect(0, 0, falloffImageSize, falloffImageSize))
+	const r = falloffImageSize / 2
+	for j := 0; j < falloffImageSize; j++ {
+		for i := 0; i < falloffImageSize; i++ {
+			dx := float64(i) + 0.5 - r
+			dy := float64(j) + 0.5 - r
+			d := math.Sqrt(dx*dx+dy*dy) / r
+			if d >= 1 {
+				continue
+			}
+			v := uint8((1 - d) * (1 - d) * 0xff)
+			f.Pix[4*(j*falloffImageSize+i)] = v
+			f.Pix[4*(j*falloffImageSize+i)+1] = v
+			f.Pix[4*(j*falloffImageSize+i)+2] = v
+			f.Pix[4*(j*falloffImageSize+i)+3] = v
+		}
+	}
+	falloffImage, _ = ebiten.NewImageFromImage(f, ebiten.FilterLinear)
+
+	// triangleImage is a right triangle: the upper-left half of the square including the diagonal.
+	// Any triangle can be drawn by mapping the triangle with a GeoM (See triangleGeoM).
+	t := image.NewRGBA(image.Rect(0, 0, triangleImageSize, triangleImageSize))
+	for j := 0; j < triangleImageSize; j++ {
+		for i := 0; i < triangleImageSize-j; i++ {
+			for k := 0; k < 4; k++ {
+				t.Pix[4*(j*triangleImageSize+i)+k] = 0xff
+			}
+		}
+	}
+	triangleImage, _ = ebiten.NewImageFromImage(t, ebiten.FilterNearest)
+}
+
+// triangleGeoM returns a GeoM to map triangleImage to the triangle (p0, p1, p2).
+//
+// The edges p0-p1 and p0-p2 are exactly drawn, while the edge p1-p2 is the diagonal of triangleImage
+// and might be jaggy. Put the edge that is not visible as p1-p2.
+func triangleGeoM(p0, p1, p2 Point) ebiten.GeoM {
+	const s = triangleImageSize
+	var g ebiten.GeoM
+	g.SetElement(0, 0, (p1.X-p0.X)/s)
+	g.SetElement(0, 1, (p2.X-p0.X)/s)
+	g.SetElement(0, 2, p0.X)
+	g.SetElement(1, 0, (p1.Y-p0.Y)/s)
+	g.SetElement(1, 1, (p2.Y-p0.Y)/s)
+	g.SetElement(1, 2, p0.Y)
+	return g
+}
+
+// triangle is a triangle of which edge p1-p2 might be jaggy.
+type triangle struct {
+	p0 Point
+	p1 Point
+	p2 Point
+}
+
+// shadowTriangles appends the triangles of the shadow that the segment (p0, p1) casts from the light l
+// to ts and returns the extended slice.
+//
+// The shadow is a quadrangle from the segment to far points out of the light's radius.
+// The quadrangle is split into two triangles sharing the diagonal so that the outer edges are exact.
+func shadowTriangles(ts []triangle, l *Light, p0, p1 Point) []triangle {
+	v0x, v0y := p0.X-l.X, p0.Y-l.Y
+	v1x, v1y := p1.X-l.X, p1.Y-l.Y
+	d0 := math.Hypot(v0x, v0y)
+	d1 := math.Hypot(v1x, v1y)
+	if d0 == 0 || d1 == 0 {
+		return ts
+	}
+	// A segment with the light on its line doesn't cast shadows.
+	cross := v0x*v1y - v0y*v1x
+	if math.Abs(cross) < 1e-9*d0*d1 {
+		return ts
+	}
+
+	// Split the segment when it subtends more than 90 degrees so that the far edge stays out of the radius.
+	if dot := v0x*v1x + v0y*v1y; dot < 0 {
+		m := Point{(p0.X + p1.X) / 2, (p0.Y + p1.Y) / 2}
+		ts = shadowTriangles(ts, l, p0, m)
+		return shadowTriangles(ts, l, m, p1)
+	}
+
+	far := 2 * l.Radius
+	f0 := Point{p0.X + v0x/d0*far, p0.Y + v0y/d0*far}
+	f1 := Point{p1.X + v1x/d1*far, p1.Y + v1y/d1*far}
+	return append(ts, triangle{p0, p1, f0}, triangle{f1, f0, p1})
+}
+
+// spotTriangles appends the triangles covering the outside of the spot light l's cone in the radius
+// to ts and returns the extended slice.
+func spotTriangles(ts []triangle, l *Light) []triangle {
+	c := Point{l.X, l.Y}
+	from := l.Direction + l.Angle/2
+	span := 2*math.Pi - l.Angle
+	n := int(math.Ceil(span / (math.Pi / 4)))
+	step := span / float64(n)
+	// The chords must be out of the radius.
+	r := l.Radius/math.Cos(step/2) + 1
+	for i := 0; i < n; i++ {
+		a0 := from + step*float64(i)
+		a1 := a0 + step
+		ts = append(ts, triangle{
+			c,
+			Point{l.X + r*math.Cos(a0), l.Y + r*math.Sin(a0)},
+			Point{l.X + r*math.Cos(a1), l.Y + r*math.Sin(a1)},
+		})
+	}
+	return ts
+}
+
+func drawTriangles(dst *ebiten.Image, ts []triangle, mode ebiten.CompositeMode) {
+	ops := make([]ebiten.DrawImageOptions, len(ts))
+	for i, t := range ts {
+		ops[i].GeoM = triangleGeoM(t.p0, t.p1, t.p2)
+		ops[i].CompositeMode = mode
+	}
+	_ = dst.DrawImages(triangleImage, ops)
+}
+
+func (s *Scene) ensureBuffers(width, height int) {
+	if s.buffer != nil {
+		if w, h := s.buffer.Size(); w == width && h == height {
+			return
+		}
+		_ = s.buffer.Dispose()
+		_ = s.scratch.Dispose()
+	}
+	s.buffer, _ = ebiten.NewImage(width, height, ebiten.FilterDefault)
+	s.scratch, _ = ebiten.NewImage(width, height, ebiten.FilterDefault)
+}
+
+// LightBuffer renders the lights and the shadows and returns the light buffer.
+//
+// The returned image has the given size and is valid until the next call of LightBuffer or Draw.
+func (s *Scene) LightBuffer(width, height int) *ebiten.Image {
+	ensureImages()
+	s.ensureBuffers(width, height)
+
+	ambient := s.Ambient
+	if ambient == nil {
+		ambient = color.Black
+	}
+	r, g, b, _ := ambient.RGBA()
+	_ = s.buffer.Fill(color.RGBA64{uint16(r), uint16(g), uint16(b), 0xffff})
+
+	var ts []triangle
+	for _, l := range s.Lights {
+		if l.Radius <= 0 {
+			continue
+		}
+		_ = s.scratch.Clear()
+
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(-falloffImageSize/2, -falloffImageSize/2)
+		op.GeoM.Scale(l.Radius*2/falloffImageSize, l.Radius*2/falloffImageSize)
+		op.GeoM.Translate(l.X, l.Y)
+		if l.Color != nil {
+			r, g, b, a := l.Color.RGBA()
+			if a > 0 {
+				// The color is premultiplied. Convert it to non-premultiplied one for ColorM.
+				op.ColorM.Scale(float64(r)/float64(a), float64(g)/float64(a), float64(b)/float64(a), float64(a)/0xffff)
+			} else {
+				op.ColorM.Scale(0, 0, 0, 0)
+			}
+		}
+		_ = s.scratch.DrawImage(falloffImage, op)
+
+		ts = ts[:0]
+		if l.isSpot() {
+			ts = spotTriangles(ts, l)
+		}
+		for _, o := range s.Occluders {
+			o.segments(func(p0, p1 Point) {
+				ts = shadowTriangles(ts, l, p0, p1)
+			})
+		}
+		drawTriangles(s.scratch, ts, ebiten.CompositeModeDestinationOut)
+
+		op = &ebiten.DrawImageOptions{}
+		op.CompositeMode = ebiten.CompositeModeLighter
+		_ = s.buffer.DrawImage(s.scratch, op)
+	}
+	return s.buffer
+}
+
+// Draw multiplies the light buffer onto the target image.
+//
+// Draw is usually called after the scene is drawn on target.
+func (s *Scene) Draw(target *ebiten.Image) {
+	buf := s.LightBuffer(target.Size())
+	op := &ebiten.DrawImageOptions{}
+	op.CompositeMode = ebiten.CompositeModeMultiply
+	_ = target.DrawImage(buf, op)
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lighting
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTriangleGeoM(t *testing.T) {
+	p0 := Point{10, 20}
+	p1 := Point{50, 25}
+	p2 := Point{5, 80}
+	g := triangleGeoM(p0, p1, p2)
+	cases := []struct {
+		X, Y float64
+		Want Point
+	}{
+		{0, 0, p0},
+		{triangleImageSize, 0, p1},
+		{0, triangleImageSize, p2},
+	}
+	for _, c := range cases {
+		x, y := g.Apply(c.X, c.Y)
+		if math.Abs(x-c.Want.X) > 1e-9 || math.Abs(y-c.Want.Y) > 1e-9 {
+			t.Errorf("Apply(%f, %f): got: (%f, %f), want: (%f, %f)", c.X, c.Y, x, y, c.Want.X, c.Want.Y)
+		}
+	}
+}
+
+func TestShadowTriangles(t *testing.T) {
+	l := &Light{X: 0, Y: 0, Radius: 100}
+
+	ts := shadowTriangles(nil, l, Point{10, -5}, Point{10, 5})
+	if got, want := len(ts), 2; got != want {
+		t.Fatalf("len(ts): got: %d, want: %d", got, want)
+	}
+	for _, tr := range ts {
+		for _, p := range []Point{tr.p0, tr.p1, tr.p2} {
+			if p.X < 10-1e-9 {
+				t.Errorf("shadow point %v must be behind the segment", p)
+			}
+		}
+	}
+
+	// The segment subtends more than 90 degrees and is split.
+	ts = shadowTriangles(nil, l, Point{10, -50}, Point{10, 50})
+	if got, want := len(ts), 4; got != want {
+		t.Errorf("len(ts): got: %d, want: %d", got, want)
+	}
+
+	// The light is on the line of the segment.
+	ts = shadowTriangles(nil, l, Point{10, 0}, Point{20, 0})
+	if got, want := len(ts), 0; got != want {
+		t.Errorf("len(ts): got: %d, want: %d", got, want)
+	}
+}
+
+func TestSpotTriangles(t *testing.T) {
+	l := &Light{X: 0, Y: 0, Radius: 100, Direction: 0, Angle: math.Pi / 2}
+	ts := spotTriangles(nil, l)
+	if got, want := len(ts), 6; got != want {
+		t.Fatalf("len(ts): got: %d, want: %d", got, want)
+	}
+	for _, tr := range ts {
+		for _, p := range []Point{tr.p1, tr.p2} {
+			if d := math.Hypot(p.X, p.Y); d <= l.Radius {
+				t.Errorf("spot point %v must be out of the radius", p)
+			}
+			// The points are on the edge of the cone or out of the cone.
+			if a := math.Atan2(p.Y, p.X); math.Abs(a) < math.Pi/4-1e-9 {
+				t.Errorf("spot point %v must not be in the cone", p)
+			}
+		}
+	}
+}
+
+func TestOccluderSegments(t *testing.T) {
+	ps := []Point{{0, 0}, {1, 0}, {1, 1}}
+	cases := []struct {
+		Occluder Occluder
+		Want     int
+	}{
+		{Occluder{Points: ps}, 3},
+		{Occluder{Points: ps, Open: true}, 2},
+		{Occluder{Points: ps[:2]}, 1},
+		{Occluder{Points: ps[:1]}, 0},
+	}
+	for _, c := range cases {
+		n := 0
+		c.Occluder.segments(func(p0, p1 Point) {
+			n++
+		})
+		if n != c.Want {
+			t.Errorf("number of segments of %v: got: %d, want: %d", c.Occluder, n, c.Want)
+		}
+	}
+}
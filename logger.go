@@ -0,0 +1,118 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/internal/logger"
+)
+
+// LogLevel represents the severity of a log entry.
+type LogLevel int
+
+const (
+	// LogLevelInfo is for the events that are worth knowing, e.g. the images are restored.
+	LogLevelInfo LogLevel = LogLevel(logger.LevelInfo)
+
+	// LogLevelWarning is for the events that might cause problems, e.g. the graphics context is lost
+	// or a slow path is taken.
+	LogLevelWarning LogLevel = LogLevel(logger.LevelWarning)
+)
+
+// String returns a string representing the log level.
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("LogLevel(%d)", int(l))
+}
+
+// LogField is a key-value pair to describe a log entry.
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+// LogEntry represents an event reported by Ebiten.
+type LogEntry struct {
+	Level LogLevel
+
+	// Message is the description of the event.
+	Message string
+
+	// Fields is the details of the event.
+	Fields []LogField
+}
+
+// String returns a string representing the log entry, like "warning: message key1=value1 key2=value2".
+func (e *LogEntry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s", e.Level, e.Message)
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+	}
+	return b.String()
+}
+
+// Logger is the interface to receive the events reported by Ebiten.
+type Logger interface {
+	// Log is called when an event happens.
+	//
+	// Log can be called from any goroutine, and Log must not call Ebiten's functions.
+	Log(entry *LogEntry)
+}
+
+// SetLogger sets the logger to receive the noteworthy events inside Ebiten, e.g. when the graphics context
+// is lost and the images are restored, when the draw history of an image becomes too long and the image's pixels
+// must be read from GPU, or when the image memory usage exceeds the budget (see SetImageMemoryBudget).
+//
+// By default, no logger is set and the events are discarded.
+// If logger is nil, the logger is unset.
+//
+// A logger is useful to diagnose the problems in production, like:
+//
+//     type stdLogger struct{}
+//
+//     func (stdLogger) Log(entry *ebiten.LogEntry) {
+//         log.Print(entry)
+//     }
+//
+//     ebiten.SetLogger(stdLogger{})
+//
+// This function is concurrent-safe.
+func SetLogger(l Logger) {
+	if l == nil {
+		logger.SetLogger(nil)
+		return
+	}
+	logger.SetLogger(func(e *logger.Entry) {
+		entry := &LogEntry{
+			Level:   LogLevel(e.Level),
+			Message: e.Message,
+		}
+		for _, f := range e.Fields {
+			entry.Fields = append(entry.Fields, LogField{
+				Key:   f.Key,
+				Value: f.Value,
+			})
+		}
+		l.Log(entry)
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"image/color"
+	"testing"
+)
+
+type testLogger struct {
+	entries []*LogEntry
+}
+
+func (l *testLogger) Log(entry *LogEntry) {
+	l.entries = append(l.entries, entry)
+}
+
+func TestLogEntryString(t *testing.T) {
+	e := &LogEntry{
+		Level:   LogLevelWarning,
+		Message: "message",
+		Fields:  []LogField{{"a", 1}, {"b", "c"}},
+	}
+	if got, want := e.String(), "warning: message a=1 b=c"; got != want {
+		t.Errorf("String(): got: %q, want: %q", got, want)
+	}
+}
+
+func TestImageMemoryBudgetLog(t *testing.T) {
+	l := &testLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+	defer SetImageMemoryBudget(0)
+
+	// Read a pixel to flush the commands so that the texture is created and counted.
+	img, _ := NewImage(16, 16, FilterDefault)
+	img.Fill(color.White)
+	_ = img.At(0, 0)
+
+	c := newGraphicsContext(nil)
+	SetImageMemoryBudget(1)
+	c.checkImageMemoryBudget()
+	c.checkImageMemoryBudget()
+	if got, want := len(l.entries), 1; got != want {
+		t.Fatalf("len(entries): got: %d, want: %d", got, want)
+	}
+	if got, want := l.entries[0].Level, LogLevelWarning; got != want {
+		t.Errorf("Level: got: %v, want: %v", got, want)
+	}
+
+	// The event is logged again after the usage goes under the budget.
+	SetImageMemoryBudget(0)
+	c.checkImageMemoryBudget()
+	SetImageMemoryBudget(1)
+	c.checkImageMemoryBudget()
+	if got, want := len(l.entries), 2; got != want {
+		t.Errorf("len(entries): got: %d, want: %d", got, want)
+	}
+}
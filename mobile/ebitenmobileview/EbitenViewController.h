@@ -0,0 +1,35 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ios
+
+#import <UIKit/UIKit.h>
+
+// EbitenViewController is a view controller to run the game.
+//
+// EbitenViewController starts the game when the view is laid out,
+// and suspends/resumes the game automatically when the app becomes inactive/active.
+@interface EbitenViewController : UIViewController
+
+// suspendGame suspends the game. This is called when the app resigns active.
+- (void)suspendGame;
+
+// resumeGame resumes the game. This is called when the app becomes active.
+- (void)resumeGame;
+
+// onErrorOnGameUpdate is called when an error happens when updating the game.
+// The default implementation logs the error. Override this to handle the error.
+- (void)onErrorOnGameUpdate:(NSError*)err;
+
+@end
@@ -0,0 +1,206 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ios
+
+#import <CoreMotion/CoreMotion.h>
+#import <GLKit/GLKit.h>
+#import <QuartzCore/QuartzCore.h>
+
+#import "EbitenViewController.h"
+
+#include "_cgo_export.h"
+
+@interface EbitenViewController () <GLKViewDelegate>
+@end
+
+@implementation EbitenViewController {
+  GLKView* glkView_;
+  CADisplayLink* displayLink_;
+  CMMotionManager* motionManager_;
+  bool active_;
+}
+
+- (void)viewDidLoad {
+  [super viewDidLoad];
+
+  EAGLContext* context = [[EAGLContext alloc] initWithAPI:kEAGLRenderingAPIOpenGLES2];
+  glkView_ = [[GLKView alloc] initWithFrame:self.view.bounds context:context];
+  glkView_.delegate = self;
+  glkView_.enableSetNeedsDisplay = NO;
+  [self.view addSubview:glkView_];
+  [EAGLContext setCurrentContext:context];
+  // glkView_ retains the context.
+  [context release];
+  active_ = true;
+
+  motionManager_ = [[CMMotionManager alloc] init];
+  [self startMotionUpdates];
+
+  NSNotificationCenter* center = [NSNotificationCenter defaultCenter];
+  [center addObserver:self
+             selector:@selector(suspendGame)
+                 name:UIApplicationWillResignActiveNotification
+               object:nil];
+  [center addObserver:self
+             selector:@selector(resumeGame)
+                 name:UIApplicationDidBecomeActiveNotification
+               object:nil];
+}
+
+- (void)dealloc {
+  [self stopDisplayLink];
+  [motionManager_ stopDeviceMotionUpdates];
+  [motionManager_ release];
+  [[NSNotificationCenter defaultCenter] removeObserver:self];
+  [glkView_ release];
+  [super dealloc];
+}
+
+- (void)viewDidAppear:(BOOL)animated {
+  [super viewDidAppear:animated];
+  [self startDisplayLink];
+}
+
+- (void)viewDidDisappear:(BOOL)animated {
+  // The display link retains its target, so it must be invalidated to release the view controller.
+  [self stopDisplayLink];
+  [super viewDidDisappear:animated];
+}
+
+- (void)startDisplayLink {
+  if (displayLink_) {
+    return;
+  }
+  displayLink_ = [CADisplayLink displayLinkWithTarget:self selector:@selector(drawFrame)];
+  [displayLink_ addToRunLoop:[NSRunLoop currentRunLoop] forMode:NSRunLoopCommonModes];
+}
+
+- (void)stopDisplayLink {
+  if (!displayLink_) {
+    return;
+  }
+  // The run loop releases the display link when it is invalidated.
+  [displayLink_ invalidate];
+  displayLink_ = nil;
+}
+
+- (void)startMotionUpdates {
+  if (!motionManager_.deviceMotionAvailable) {
+    return;
+  }
+  motionManager_.deviceMotionUpdateInterval = 1.0 / 60.0;
+  [motionManager_ startDeviceMotionUpdatesToQueue:[NSOperationQueue mainQueue]
+                                      withHandler:^(CMDeviceMotion* motion, NSError* error) {
+    if (!motion) {
+      return;
+    }
+    // Core Motion reports the accelerations in G toward the gravity, while Ebiten follows the W3C
+    // DeviceOrientation Event that reports the accelerations in m/s^2 against the gravity.
+    const double g = 9.80665;
+    CMAcceleration a = motion.userAcceleration;
+    CMAcceleration gravity = motion.gravity;
+    CMRotationRate r = motion.rotationRate;
+    CMAttitude* attitude = motion.attitude;
+    ebitenmobileviewUpdateMotion(-(a.x + gravity.x) * g, -(a.y + gravity.y) * g, -(a.z + gravity.z) * g,
+                                 r.x, r.y, r.z,
+                                 attitude.yaw, attitude.pitch, attitude.roll);
+  }];
+}
+
+- (void)viewDidLayoutSubviews {
+  [super viewDidLayoutSubviews];
+
+  if (@available(iOS 11.0, *)) {
+    UIEdgeInsets insets = self.view.safeAreaInsets;
+    ebitenmobileviewSetSafeAreaInsets(insets.left, insets.top, insets.right, insets.bottom);
+  }
+
+  CGRect bounds = self.view.bounds;
+  char* err = ebitenmobileviewLayout(bounds.size.width, bounds.size.height);
+  if (err) {
+    [self handleError:err];
+    return;
+  }
+
+  double scale = ebitenmobileviewScreenScale();
+  CGFloat width = ebitenmobileviewScreenWidth() * scale;
+  CGFloat height = ebitenmobileviewScreenHeight() * scale;
+  glkView_.frame = CGRectMake((bounds.size.width - width) / 2, (bounds.size.height - height) / 2, width, height);
+}
+
+- (void)drawFrame {
+  if (!active_) {
+    return;
+  }
+  [glkView_ display];
+}
+
+- (void)glkView:(GLKView*)view drawInRect:(CGRect)rect {
+  char* err = ebitenmobileviewUpdate();
+  if (err) {
+    [self handleError:err];
+  }
+}
+
+- (void)handleError:(char*)err {
+  NSString* msg = [NSString stringWithUTF8String:err];
+  free(err);
+  NSDictionary* info = @{NSLocalizedDescriptionKey: msg};
+  [self onErrorOnGameUpdate:[NSError errorWithDomain:@"ebiten" code:0 userInfo:info]];
+}
+
+- (void)onErrorOnGameUpdate:(NSError*)err {
+  NSLog(@"Error: %@", err);
+}
+
+- (void)updateTouches:(NSSet*)touches {
+  for (UITouch* touch in touches) {
+    if (touch.view != glkView_) {
+      continue;
+    }
+    CGPoint location = [touch locationInView:glkView_];
+    ebitenmobileviewUpdateTouchesOnIOS(touch.phase, (int64_t)touch, location.x, location.y);
+  }
+}
+
+- (void)touchesBegan:(NSSet*)touches withEvent:(UIEvent*)event {
+  [self updateTouches:touches];
+}
+
+- (void)touchesMoved:(NSSet*)touches withEvent:(UIEvent*)event {
+  [self updateTouches:touches];
+}
+
+- (void)touchesEnded:(NSSet*)touches withEvent:(UIEvent*)event {
+  [self updateTouches:touches];
+}
+
+- (void)touchesCancelled:(NSSet*)touches withEvent:(UIEvent*)event {
+  [self updateTouches:touches];
+}
+
+- (void)suspendGame {
+  active_ = false;
+  [motionManager_ stopDeviceMotionUpdates];
+  ebitenmobileviewPause();
+}
+
+- (void)resumeGame {
+  active_ = true;
+  [self startMotionUpdates];
+  ebitenmobileviewResume();
+}
+
+@end
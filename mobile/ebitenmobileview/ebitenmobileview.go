@@ -0,0 +1,177 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ebitenmobileview offers functions for the views generated by the ebitenmobile command.
+//
+// A game package bound by ebitenmobile must call SetUpdateFunc in its init function:
+//
+//     func init() {
+//         ebitenmobileview.SetUpdateFunc(update, 320, 240)
+//     }
+//
+// The other functions are called by the generated views (EbitenView on Android and
+// EbitenViewController on iOS), and usually you don't have to call them directly.
+package ebitenmobileview
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/mobile"
+)
+
+var (
+	theUpdateFunc func(*ebiten.Image) error
+	screenWidth   int
+	screenHeight  int
+	screenScale   float64
+	running       bool
+	m             sync.Mutex
+)
+
+// SetUpdateFunc sets the game's update function and the screen size.
+//
+// The unit of width/height is device-independent pixel (dp on Android and point on iOS).
+// The screen scale is determined by the view's size so that the screen fits with the view.
+//
+// SetUpdateFunc panics if width or height is not positive.
+//
+// SetUpdateFunc is not exported to Java or Objective-C since f's type is not supported by gomobile.
+func SetUpdateFunc(f func(*ebiten.Image) error, width, height int) {
+	if width <= 0 || height <= 0 {
+		panic("ebitenmobileview: width and height must be positive")
+	}
+	m.Lock()
+	defer m.Unlock()
+	theUpdateFunc = f
+	screenWidth = width
+	screenHeight = height
+}
+
+// Layout is called when the view's size is determined or changed.
+//
+// The unit of viewWidth/viewHeight is device-independent pixel (dp on Android and point on iOS).
+//
+// The game starts at the first call of Layout.
+func Layout(viewWidth, viewHeight float64) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if theUpdateFunc == nil {
+		return errors.New("ebitenmobileview: SetUpdateFunc must be called before Layout")
+	}
+
+	scaleX := viewWidth / float64(screenWidth)
+	scaleY := viewHeight / float64(screenHeight)
+	scale := scaleX
+	if scale > scaleY {
+		scale = scaleY
+	}
+	screenScale = scale
+	mobile.SetViewSize(viewWidth, viewHeight)
+
+	if !running {
+		if err := mobile.Start(theUpdateFunc, screenWidth, screenHeight, scale, ""); err != nil {
+			return err
+		}
+		running = true
+		return nil
+	}
+	ebiten.SetScreenScale(scale)
+	return nil
+}
+
+// SetSafeAreaInsets is called when the safe area insets of the view are determined or changed.
+//
+// The unit of the insets is device-independent pixel (dp on Android and point on iOS).
+func SetSafeAreaInsets(left, top, right, bottom float64) {
+	mobile.SetSafeAreaInsets(left, top, right, bottom)
+}
+
+// ScreenWidth returns the screen width in device-independent pixels.
+func ScreenWidth() int {
+	m.Lock()
+	defer m.Unlock()
+	return screenWidth
+}
+
+// ScreenHeight returns the screen height in device-independent pixels.
+func ScreenHeight() int {
+	m.Lock()
+	defer m.Unlock()
+	return screenHeight
+}
+
+// ScreenScale returns the screen scale determined at Layout.
+//
+// The view to render the game should be sized to (ScreenWidth() * ScreenScale(), ScreenHeight() * ScreenScale()).
+func ScreenScale() float64 {
+	m.Lock()
+	defer m.Unlock()
+	return screenScale
+}
+
+// Update updates and renders the game.
+//
+// Update does nothing before the game starts at Layout.
+func Update() error {
+	m.Lock()
+	r := running
+	m.Unlock()
+	if !r {
+		return nil
+	}
+	return mobile.Update()
+}
+
+// Pause notifies that the app is paused.
+func Pause() {
+	mobile.Pause()
+}
+
+// Resume notifies that the app is resumed.
+func Resume() {
+	mobile.Resume()
+}
+
+// OnContextLost notifies that the OpenGL context is lost.
+func OnContextLost() {
+	mobile.OnContextLost()
+}
+
+// UpdateTouchesOnAndroid updates the touch state on Android.
+func UpdateTouchesOnAndroid(action int, id int, x, y int) {
+	mobile.UpdateTouchesOnAndroid(action, id, x, y)
+}
+
+// UpdateTouchesOnIOS updates the touch state on iOS.
+func UpdateTouchesOnIOS(phase int, ptr int64, x, y int) {
+	mobile.UpdateTouchesOnIOS(phase, ptr, x, y)
+}
+
+// UpdateAcceleration updates the acceleration of the device. See mobile.UpdateAcceleration.
+func UpdateAcceleration(x, y, z float64) {
+	mobile.UpdateAcceleration(x, y, z)
+}
+
+// UpdateRotationRate updates the rotation rate of the device. See mobile.UpdateRotationRate.
+func UpdateRotationRate(x, y, z float64) {
+	mobile.UpdateRotationRate(x, y, z)
+}
+
+// UpdateOrientation updates the orientation of the device. See mobile.UpdateOrientation.
+func UpdateOrientation(alpha, beta, gamma float64) {
+	mobile.UpdateOrientation(alpha, beta, gamma)
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ios
+
+package ebitenmobileview
+
+// EbitenViewController.m is compiled together via cgo so that the framework generated by
+// ebitenmobile includes EbitenViewController.
+// The functions below are exported to C for EbitenViewController, independently from
+// the Objective-C functions generated by gomobile, whose names depend on the prefix.
+
+// #cgo CFLAGS: -x objective-c
+// #cgo LDFLAGS: -framework Foundation -framework UIKit -framework GLKit -framework OpenGLES -framework QuartzCore -framework CoreMotion
+//
+// #include <stdlib.h>
+import "C"
+
+// errorToCString returns a C string of the error message, or nil if err is nil.
+// The caller must free the returned string.
+func errorToCString(err error) *C.char {
+	if err == nil {
+		return nil
+	}
+	return C.CString(err.Error())
+}
+
+//export ebitenmobileviewLayout
+func ebitenmobileviewLayout(viewWidth, viewHeight C.double) *C.char {
+	return errorToCString(Layout(float64(viewWidth), float64(viewHeight)))
+}
+
+//export ebitenmobileviewSetSafeAreaInsets
+func ebitenmobileviewSetSafeAreaInsets(left, top, right, bottom C.double) {
+	SetSafeAreaInsets(float64(left), float64(top), float64(right), float64(bottom))
+}
+
+//export ebitenmobileviewScreenWidth
+func ebitenmobileviewScreenWidth() C.int {
+	return C.int(ScreenWidth())
+}
+
+//export ebitenmobileviewScreenHeight
+func ebitenmobileviewScreenHeight() C.int {
+	return C.int(ScreenHeight())
+}
+
+//export ebitenmobileviewScreenScale
+func ebitenmobileviewScreenScale() C.double {
+	return C.double(ScreenScale())
+}
+
+//export ebitenmobileviewUpdate
+func ebitenmobileviewUpdate() *C.char {
+	return errorToCString(Update())
+}
+
+//export ebitenmobileviewPause
+func ebitenmobileviewPause() {
+	Pause()
+}
+
+//export ebitenmobileviewResume
+func ebitenmobileviewResume() {
+	Resume()
+}
+
+//export ebitenmobileviewUpdateTouchesOnIOS
+func ebitenmobileviewUpdateTouchesOnIOS(phase C.int, ptr C.int64_t, x, y C.int) {
+	UpdateTouchesOnIOS(int(phase), int64(ptr), int(x), int(y))
+}
+
+//export ebitenmobileviewUpdateMotion
+func ebitenmobileviewUpdateMotion(ax, ay, az, rx, ry, rz, alpha, beta, gamma C.double) {
+	UpdateAcceleration(float64(ax), float64(ay), float64(az))
+	UpdateRotationRate(float64(rx), float64(ry), float64(rz))
+	UpdateOrientation(float64(alpha), float64(beta), float64(gamma))
+}
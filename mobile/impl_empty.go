@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// +build darwin freebsd linux windows
+// +build darwin freebsd linux windows js
 // +build !android
 // +build !ios
 
@@ -28,3 +28,18 @@ func update() error {
 
 func start(f func(*ebiten.Image) error, width, height int, scale float64, title string) {
 }
+
+func pause() {
+}
+
+func resume() {
+}
+
+func setViewSize(width, height float64) {
+}
+
+func setSafeAreaInsets(left, top, right, bottom float64) {
+}
+
+func onContextLost() {
+}
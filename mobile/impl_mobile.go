@@ -42,3 +42,23 @@ func start(f func(*ebiten.Image) error, width, height int, scale float64, title
 	running = true
 	chError = ebiten.RunWithoutMainLoop(f, width, height, scale, title)
 }
+
+func pause() {
+	ui.SetForeground(false)
+}
+
+func resume() {
+	ui.SetForeground(true)
+}
+
+func setViewSize(width, height float64) {
+	ui.SetViewSize(width, height)
+}
+
+func setSafeAreaInsets(left, top, right, bottom float64) {
+	ui.SetSafeAreaInsets(left, top, right, bottom)
+}
+
+func onContextLost() {
+	ui.Invalidate()
+}
@@ -17,11 +17,16 @@
 // This package is used when you use `gomobile bind`.
 // For `gomobile build`, you don't have to use this package.
 //
+// The ebitenmobile command (github.com/hajimehoshi/ebiten/cmd/ebitenmobile) wraps `gomobile bind`
+// and generates the views (EbitenView for Android and EbitenViewController for iOS) that call
+// the functions of this package. With ebitenmobile, you don't have to call them by yourself.
+//
 // For usage, see https://github.com/hajimehoshi/ebiten/wiki/Mobile, https://github.com/hajimehoshi/ebiten/wiki/Android and https://github.com/hajimehoshi/ebiten/wiki/iOS.
 package mobile
 
 import (
 	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/input"
 )
 
 // Start starts the game and returns immediately.
@@ -48,6 +53,59 @@ func Update() error {
 	return update()
 }
 
+// Pause notifies that the app is paused.
+//
+// On Android, this should be called at onPause of Activity.
+//
+// On iOS, this should be called at applicationWillResignActive: of UIApplicationDelegate.
+//
+// While the app is paused, ebiten.IsFocused returns false.
+//
+// Pause calls the lifecycle handlers' Suspend, and their Restore are called at the next Update after Resume.
+// See ebiten.RegisterLifecycleHandler.
+func Pause() {
+	pause()
+}
+
+// Resume notifies that the app is resumed.
+//
+// On Android, this should be called at onResume of Activity.
+//
+// On iOS, this should be called at applicationDidBecomeActive: of UIApplicationDelegate.
+func Resume() {
+	resume()
+}
+
+// SetViewSize notifies the size of the view where the game is rendered.
+//
+// The unit of width/height is device-independent pixel (dp on Android and point on iOS).
+//
+// The size is passed to Layout of the game run by ebiten.RunGame as the outside size.
+func SetViewSize(width, height float64) {
+	setViewSize(width, height)
+}
+
+// SetSafeAreaInsets notifies the safe area insets of the view where the game is rendered, e.g., the areas
+// overlapped by the display's notches.
+//
+// The unit of the insets is device-independent pixel (dp on Android and point on iOS).
+//
+// The insets are used to calculate ebiten.SafeAreaInsets.
+func SetSafeAreaInsets(left, top, right, bottom float64) {
+	setSafeAreaInsets(left, top, right, bottom)
+}
+
+// OnContextLost notifies that the OpenGL context is lost.
+// The images are restored at the next Update.
+//
+// On Android, this should be called at onSurfaceCreated of Renderer except for the first time,
+// since a new OpenGL context is created after the previous context is lost e.g. when the app is paused.
+//
+// On iOS, the context is not lost in usual cases and this doesn't have to be called.
+func OnContextLost() {
+	onContextLost()
+}
+
 // UpdateTouchesOnAndroid updates the touch state on Android.
 //
 // This should be called with onTouchEvent of GLSurfaceView like this:
@@ -117,3 +175,32 @@ func UpdateTouchesOnAndroid(action int, id int, x, y int) {
 func UpdateTouchesOnIOS(phase int, ptr int64, x, y int) {
 	updateTouchesOnIOSImpl(phase, ptr, x, y)
 }
+
+// UpdateAcceleration updates the acceleration of the device including the gravity in m/s².
+//
+// The axes are x toward the right of the screen, y toward the top and z out of the screen,
+// e.g. (0, 0, 9.8) when the device lies face up. This is the same as the values of
+// Android's Sensor.TYPE_ACCELEROMETER.
+//
+// The views generated by ebitenmobile call this automatically.
+func UpdateAcceleration(x, y, z float64) {
+	input.GetSensors().SetAcceleration(x, y, z)
+}
+
+// UpdateRotationRate updates the rotation rate of the device around the axes in radians per second.
+//
+// The axes are the same as UpdateAcceleration. This is the same as the values of Android's Sensor.TYPE_GYROSCOPE.
+//
+// The views generated by ebitenmobile call this automatically.
+func UpdateRotationRate(x, y, z float64) {
+	input.GetSensors().SetRotationRate(x, y, z)
+}
+
+// UpdateOrientation updates the orientation of the device in radians.
+//
+// alpha, beta and gamma are the rotations around z, x and y axes as the W3C DeviceOrientation Event.
+//
+// The views generated by ebitenmobile call this automatically.
+func UpdateOrientation(alpha, beta, gamma float64) {
+	input.GetSensors().SetOrientation(alpha, beta, gamma)
+}
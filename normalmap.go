@@ -0,0 +1,149 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/internal/graphics"
+)
+
+// MaxNormalMapLights is the maximum number of the lights of NormalMapOptions.
+const MaxNormalMapLights = graphics.MaxNormalMapLights
+
+// NormalMapLight represents a light to light an image with a normal map.
+type NormalMapLight struct {
+	// X, Y and Z are the position of a point light in the destination image's coordinates.
+	// Z is the height above the image toward the viewer.
+	//
+	// For a directional light, X, Y and Z are the direction toward the light.
+	// For example, (0, 0, 1) is a light right in front of the image.
+	X float64
+	Y float64
+	Z float64
+
+	// Directional indicates whether the light is a directional light like the sun.
+	Directional bool
+
+	// Color is the color of the light. If Color is nil, the light is white.
+	Color color.Color
+
+	// Radius is the distance that a point light reaches. The light fades out linearly to the radius.
+	// If Radius is 0, the light doesn't fade out. Radius is ignored for a directional light.
+	Radius float64
+}
+
+// NormalMapOptions represents options to light an image with a normal map at DrawImageWithNormalMap.
+type NormalMapOptions struct {
+	// NormalMap is the normal map of the image, e.g. exported from tools like SpriteIlluminator.
+	// NormalMap must have the same size as the image.
+	//
+	// The red, green and blue values represent the X (right), Y (up) and Z (toward the viewer) components
+	// of the normal vector respectively. Note that the Y axis points up unlike the image's coordinates.
+	NormalMap *Image
+
+	// Ambient is the color of the ambient light. If Ambient is nil, the ambient light is black.
+	Ambient color.Color
+
+	// Lights are the lights. The number of the lights must be MaxNormalMapLights or less.
+	Lights []NormalMapLight
+}
+
+func colorToFloats(clr color.Color) (r, g, b float32) {
+	cr, cg, cb, _ := clr.RGBA()
+	return float32(cr) / 0xffff, float32(cg) / 0xffff, float32(cb) / 0xffff
+}
+
+func (n *NormalMapOptions) lighting() *graphics.NormalMapLighting {
+	l := &graphics.NormalMapLighting{}
+	if n.Ambient != nil {
+		l.Ambient[0], l.Ambient[1], l.Ambient[2] = colorToFloats(n.Ambient)
+	}
+	for k, light := range n.Lights {
+		l.Positions[4*k] = float32(light.X)
+		l.Positions[4*k+1] = float32(light.Y)
+		l.Positions[4*k+2] = float32(light.Z)
+		if !light.Directional {
+			l.Positions[4*k+3] = 1
+		}
+		if light.Color != nil {
+			l.Colors[4*k], l.Colors[4*k+1], l.Colors[4*k+2] = colorToFloats(light.Color)
+		} else {
+			l.Colors[4*k], l.Colors[4*k+1], l.Colors[4*k+2] = 1, 1, 1
+		}
+		l.Colors[4*k+3] = float32(light.Radius)
+	}
+	return l
+}
+
+// DrawImageWithNormalMap draws the given image on the image i with options, lighting the image's colors
+// with the normal map and the lights of normalMap.
+//
+// The color of each pixel is multiplied by the sum of the ambient light and the lights, which are calculated
+// from the normal vector of the normal map (Lambertian reflection). The lighting is applied after ColorM.
+//
+// The image is always drawn with the nearest filter, and the Filter of options is ignored.
+// The successive drawings with the same normal map and the same lights are merged as DrawImage.
+//
+// When the image i is disposed, DrawImageWithNormalMap does nothing.
+// When the given image img or the normal map is disposed, DrawImageWithNormalMap panics.
+// When the normal map's size is different from img's, DrawImageWithNormalMap panics.
+// When the number of the lights is more than MaxNormalMapLights, DrawImageWithNormalMap panics.
+// When img is a paletted image, DrawImageWithNormalMap panics.
+//
+// DrawImageWithNormalMap always returns nil.
+func (i *Image) DrawImageWithNormalMap(img *Image, options *DrawImageOptions, normalMap *NormalMapOptions) error {
+	i.copyCheck()
+	if img.isDisposed() || normalMap.NormalMap.isDisposed() {
+		panic("ebiten: the given images to DrawImageWithNormalMap must not be disposed")
+	}
+	if i.isDisposed() {
+		return nil
+	}
+	if i.Format() == PixelFormatAlpha8 {
+		panic("ebiten: an image of PixelFormatAlpha8 can't be a render target")
+	}
+	if i.Format().isCompressed() {
+		panic("ebiten: an image of a compressed format can't be a render target")
+	}
+	if img.palette != nil {
+		panic("ebiten: a paletted image can't be drawn with a normal map")
+	}
+	if img == i || normalMap.NormalMap == i {
+		panic("ebiten: the given images to DrawImageWithNormalMap must be different from the receiver")
+	}
+	w, h := img.Size()
+	if nw, nh := normalMap.NormalMap.Size(); nw != w || nh != h {
+		panic(fmt.Sprintf("ebiten: the normal map size must be (%d, %d) but was (%d, %d)", w, h, nw, nh))
+	}
+	if len(normalMap.Lights) > MaxNormalMapLights {
+		panic(fmt.Sprintf("ebiten: the number of the lights must be %d or less but was %d", MaxNormalMapLights, len(normalMap.Lights)))
+	}
+
+	if options == nil {
+		options = &DrawImageOptions{}
+	}
+	q, st, ok := i.quadAndState(img, options)
+	if !ok {
+		return nil
+	}
+	if _, _, ok := st.colorm.Tint(); ok {
+		q.Tint, st.colorm = st.colorm, nil
+	}
+	i.modified = true
+	i.shareableImage.DrawImagesWithNormalMap(img.shareableImage, normalMap.NormalMap.shareableImage, normalMap.lighting(), []graphics.Quad{q}, st.colorm, st.mode, st.clip, st.disabled, st.address)
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image/color"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten"
+)
+
+// newFlatNormalMap returns a normal map of which normals all point toward the viewer.
+func newFlatNormalMap(width, height int) *Image {
+	img, _ := NewImage(width, height, FilterDefault)
+	img.Fill(color.RGBA{0x80, 0x80, 0xff, 0xff})
+	return img
+}
+
+func TestDrawImageWithNormalMap(t *testing.T) {
+	src, _ := NewImage(2, 2, FilterDefault)
+	src.Fill(color.White)
+	normal := newFlatNormalMap(2, 2)
+
+	cases := []struct {
+		Name    string
+		Options *NormalMapOptions
+		Want    color.RGBA
+	}{
+		{
+			Name:    "no lights",
+			Options: &NormalMapOptions{NormalMap: normal},
+			Want:    color.RGBA{0, 0, 0, 0xff},
+		},
+		{
+			Name:    "ambient",
+			Options: &NormalMapOptions{NormalMap: normal, Ambient: color.RGBA{0x40, 0x80, 0xc0, 0xff}},
+			Want:    color.RGBA{0x40, 0x80, 0xc0, 0xff},
+		},
+		{
+			Name: "front",
+			Options: &NormalMapOptions{
+				NormalMap: normal,
+				Lights:    []NormalMapLight{{Z: 1, Directional: true, Color: color.RGBA{0xff, 0, 0, 0xff}}},
+			},
+			Want: color.RGBA{0xff, 0, 0, 0xff},
+		},
+		{
+			Name: "side",
+			Options: &NormalMapOptions{
+				NormalMap: normal,
+				Lights:    []NormalMapLight{{X: 1, Directional: true}},
+			},
+			Want: color.RGBA{0, 0, 0, 0xff},
+		},
+	}
+	for _, c := range cases {
+		dst, _ := NewImage(2, 2, FilterDefault)
+		dst.DrawImageWithNormalMap(src, nil, c.Options)
+		got := dst.At(1, 1).(color.RGBA)
+		if !sameColors(got, c.Want, 2) {
+			t.Errorf("%s: dst.At(1, 1): got: %v, want: %v", c.Name, got, c.Want)
+		}
+	}
+}
+
+func TestDrawImageWithNormalMapSizeMismatch(t *testing.T) {
+	src, _ := NewImage(2, 2, FilterDefault)
+	dst, _ := NewImage(2, 2, FilterDefault)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("DrawImageWithNormalMap with a normal map of a different size must panic")
+		}
+	}()
+	dst.DrawImageWithNormalMap(src, nil, &NormalMapOptions{NormalMap: newFlatNormalMap(1, 1)})
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/internal/driver"
+	"github.com/hajimehoshi/ebiten/internal/shareable"
+)
+
+// MaxPaletteSize is the maximum number of the colors in a palette of a paletted image.
+const MaxPaletteSize = 256
+
+// NewPalettedImage returns an empty indexed-color image with the given palette.
+//
+// The format of a paletted image is PixelFormatAlpha8, and each byte of the pixels is an index of the palette:
+// ReplacePixels takes one index per pixel, and At returns the color of the palette entry.
+// The colors are resolved with the palette when the image is drawn, so SetPalette changes the colors of the image
+// instantly without uploading the pixels again, e.g. for palette swaps and color cycling.
+//
+// A paletted image is always drawn with the nearest filter, and can't be a render target.
+//
+// If width or height is less than 1 or more than device-dependent maximum size, NewPalettedImage panics.
+// If the palette has more than MaxPaletteSize colors, NewPalettedImage panics.
+//
+// Error returned by NewPalettedImage is always nil.
+func NewPalettedImage(width, height int, palette []color.Color) (*Image, error) {
+	i := &Image{
+		shareableImage: shareable.NewImageWithFormat(width, height, driver.PixelFormatAlpha8),
+		filter:         FilterNearest,
+	}
+	i.addr = i
+	i.palette, _ = NewImage(MaxPaletteSize, 1, FilterNearest)
+	theImages.remove(i.palette)
+	i.SetPalette(palette)
+	setFinalizer(i)
+	theImages.add(i)
+	return i, nil
+}
+
+// SetPalette replaces the palette of the paletted image. The entries out of the given palette are transparent.
+//
+// The drawings of the image after SetPalette use the new palette, while the drawings before SetPalette are not affected.
+//
+// When the image is disposed, SetPalette does nothing.
+//
+// If the image is not a paletted image created by NewPalettedImage, SetPalette panics.
+// If the palette has more than MaxPaletteSize colors, SetPalette panics.
+func (i *Image) SetPalette(palette []color.Color) {
+	i.copyCheck()
+	if i.isDisposed() {
+		return
+	}
+	if i.palette == nil {
+		panic("ebiten: SetPalette can be called only on a paletted image")
+	}
+	if len(palette) > MaxPaletteSize {
+		panic(fmt.Sprintf("ebiten: the palette size must be %d or less but was %d", MaxPaletteSize, len(palette)))
+	}
+	pix := make([]byte, 4*MaxPaletteSize)
+	for idx, c := range palette {
+		r, g, b, a := c.RGBA()
+		pix[4*idx] = byte(r >> 8)
+		pix[4*idx+1] = byte(g >> 8)
+		pix[4*idx+2] = byte(b >> 8)
+		pix[4*idx+3] = byte(a >> 8)
+	}
+	_ = i.palette.ReplacePixels(pix)
+}
+
+// paletteColorAt returns the color of the palette entry at the index.
+func (i *Image) paletteColorAt(index uint8) color.Color {
+	return i.palette.At(int(index), 0)
+}
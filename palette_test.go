@@ -0,0 +1,124 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten"
+)
+
+var (
+	paletteRed   = color.RGBA{0xff, 0, 0, 0xff}
+	paletteGreen = color.RGBA{0, 0xff, 0, 0xff}
+	paletteBlue  = color.RGBA{0, 0, 0xff, 0xff}
+)
+
+func TestPalettedImage(t *testing.T) {
+	img, _ := NewPalettedImage(4, 1, []color.Color{paletteRed, paletteGreen, paletteBlue})
+	img.ReplacePixels([]byte{0, 1, 2, 3})
+	if got, want := img.Format(), PixelFormatAlpha8; got != want {
+		t.Errorf("Format(): got: %v, want: %v", got, want)
+	}
+
+	want := []color.RGBA{paletteRed, paletteGreen, paletteBlue, {}}
+	for i, w := range want {
+		if got := img.At(i, 0); got != w {
+			t.Errorf("img.At(%d, 0): got: %v, want: %v", i, got, w)
+		}
+	}
+	if got, want := img.At(4, 0), (color.RGBA{}); got != want {
+		t.Errorf("img.At(4, 0): got: %v, want: %v", got, want)
+	}
+
+	dst, _ := NewImage(4, 1, FilterDefault)
+	// The filter is ignored for a paletted image.
+	op := &DrawImageOptions{}
+	op.Filter = FilterLinear
+	dst.DrawImage(img, op)
+	for i, w := range want {
+		if got := dst.At(i, 0); got != w {
+			t.Errorf("dst.At(%d, 0): got: %v, want: %v", i, got, w)
+		}
+	}
+}
+
+func TestPalettedImageSetPalette(t *testing.T) {
+	img, _ := NewPalettedImage(2, 1, []color.Color{paletteRed, paletteGreen})
+	img.ReplacePixels([]byte{0, 1})
+
+	dst0, _ := NewImage(2, 1, FilterDefault)
+	dst0.DrawImage(img, nil)
+
+	// Swap the palette without replacing the pixels.
+	img.SetPalette([]color.Color{paletteBlue, paletteRed})
+	dst1, _ := NewImage(2, 1, FilterDefault)
+	dst1.DrawImage(img, nil)
+
+	// The drawing before SetPalette is not affected.
+	for i, want := range []color.RGBA{paletteRed, paletteGreen} {
+		if got := dst0.At(i, 0); got != want {
+			t.Errorf("dst0.At(%d, 0): got: %v, want: %v", i, got, want)
+		}
+	}
+	for i, want := range []color.RGBA{paletteBlue, paletteRed} {
+		if got := dst1.At(i, 0); got != want {
+			t.Errorf("dst1.At(%d, 0): got: %v, want: %v", i, got, want)
+		}
+	}
+}
+
+func TestPalettedImageDrawImages(t *testing.T) {
+	img, _ := NewPalettedImage(2, 2, []color.Color{color.White, paletteRed})
+	img.ReplacePixels([]byte{0, 1, 1, 0})
+
+	dst, _ := NewImage(4, 2, FilterDefault)
+	ops := make([]DrawImageOptions, 2)
+	r := image.Rect(0, 0, 1, 2)
+	ops[0].SourceRect = &r
+	ops[0].ColorM.Scale(1, 0, 0, 1)
+	ops[1].SourceRect = &r
+	ops[1].GeoM.Translate(2, 0)
+	ops[1].ColorM.Scale(0, 1, 1, 1)
+	dst.DrawImages(img, ops)
+
+	want := map[image.Point]color.RGBA{
+		{0, 0}: {0xff, 0, 0, 0xff},
+		{0, 1}: {0xff, 0, 0, 0xff},
+		{2, 0}: {0, 0xff, 0xff, 0xff},
+		{2, 1}: {0, 0, 0, 0xff},
+	}
+	for j := 0; j < 2; j++ {
+		for i := 0; i < 4; i++ {
+			got := dst.At(i, j)
+			w := want[image.Pt(i, j)]
+			if got != w {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, w)
+			}
+		}
+	}
+}
+
+func TestSetPaletteOnNonPalettedImage(t *testing.T) {
+	img, _ := NewImage(1, 1, FilterDefault)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("SetPalette on a non-paletted image must panic")
+		}
+	}()
+	img.SetPalette([]color.Color{paletteRed})
+}
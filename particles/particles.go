@@ -0,0 +1,285 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package particles provides a simple particle system.
+//
+// An Emitter emits particles with lifetime, velocity and gravity, and the color and the size
+// of a particle can be changed over its life.
+//
+// Particles are drawn so that the draw commands are merged as much as possible:
+// all the particles of an emitter are drawn with one draw command when the color doesn't change
+// over life, and with at most ColorSteps draw commands otherwise.
+//
+// Note: This package is experimental and API might be changed.
+package particles
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// ColorSteps is the number of the discrete colors that a particle takes over its life.
+//
+// Particles with the same color can be drawn with one draw command.
+const ColorSteps = 32
+
+// A Curve returns a value at the given normalized time t of a particle's life.
+// t is in [0, 1]: 0 is the time when the particle is emitted and 1 is the time when the particle dies.
+type Curve func(t float64) float64
+
+// Linear returns a Curve that changes linearly from the value from to the value to.
+func Linear(from, to float64) Curve {
+	return func(t float64) float64 {
+		return from + (to-from)*t
+	}
+}
+
+// A ColorCurve returns a color at the given normalized time t of a particle's life.
+type ColorCurve func(t float64) color.Color
+
+// LinearColor returns a ColorCurve that changes linearly from the color from to the color to.
+func LinearColor(from, to color.Color) ColorCurve {
+	r0, g0, b0, a0 := from.RGBA()
+	r1, g1, b1, a1 := to.RGBA()
+	lerp := func(x, y uint32, t float64) uint16 {
+		return uint16(float64(x) + (float64(y)-float64(x))*t)
+	}
+	return func(t float64) color.Color {
+		return color.RGBA64{
+			R: lerp(r0, r1, t),
+			G: lerp(g0, g1, t),
+			B: lerp(b0, b1, t),
+			A: lerp(a0, a1, t),
+		}
+	}
+}
+
+type particle struct {
+	x    float64
+	y    float64
+	vx   float64
+	vy   float64
+	age  int
+	life int
+}
+
+// t returns the normalized time of the particle's life.
+func (p *particle) t() float64 {
+	if p.life <= 1 {
+		return 1
+	}
+	return float64(p.age) / float64(p.life-1)
+}
+
+// An Emitter emits and holds particles.
+//
+// The zero value of Emitter doesn't emit any particles. Set the fields to emit particles.
+type Emitter struct {
+	// Image is the image of a particle. The center of the image is the particle's position.
+	Image *ebiten.Image
+
+	// X and Y are the position where particles are emitted.
+	X float64
+	Y float64
+
+	// Rate is the number of particles emitted per tick (1/60[s]).
+	Rate float64
+
+	// Lifetime is the lifetime of a particle in ticks.
+	// LifetimeVariance is the maximum difference from Lifetime.
+	Lifetime         int
+	LifetimeVariance int
+
+	// Speed is the initial speed of a particle in pixels per tick.
+	// SpeedVariance is the maximum difference from Speed.
+	Speed         float64
+	SpeedVariance float64
+
+	// Direction is the direction in radian where particles are emitted.
+	// Spread is the range of the direction in radian.
+	// For example, when Spread is 2π, particles are emitted in all directions.
+	Direction float64
+	Spread    float64
+
+	// GravityX and GravityY are the acceleration of particles in pixels per tick^2.
+	GravityX float64
+	GravityY float64
+
+	// Color is the color of a particle over its life.
+	// The color is multiplied to the image's color.
+	// If Color is nil, the image's color is used as it is.
+	Color ColorCurve
+
+	// Scale is the scale of a particle over its life.
+	// If Scale is nil, the scale is always 1.
+	Scale Curve
+
+	// CompositeMode is the composite mode to draw particles.
+	CompositeMode ebiten.CompositeMode
+
+	// Rand is the source of random numbers.
+	// If Rand is nil, the functions of the math/rand package are used.
+	Rand *rand.Rand
+
+	particles []particle
+	remainder float64
+
+	// The buffers to sort particles by colors at Draw.
+	counts  [ColorSteps + 1]int
+	indices []int
+}
+
+func (e *Emitter) float64() float64 {
+	if e.Rand != nil {
+		return e.Rand.Float64()
+	}
+	return rand.Float64()
+}
+
+// variance returns a random value in [-v, v].
+func (e *Emitter) variance(v float64) float64 {
+	return (e.float64()*2 - 1) * v
+}
+
+// Emit emits n particles immediately.
+func (e *Emitter) Emit(n int) {
+	for i := 0; i < n; i++ {
+		life := e.Lifetime + int(math.Floor(e.variance(float64(e.LifetimeVariance))+0.5))
+		if life <= 0 {
+			continue
+		}
+		speed := e.Speed + e.variance(e.SpeedVariance)
+		dir := e.Direction + e.variance(e.Spread/2)
+		e.particles = append(e.particles, particle{
+			x:    e.X,
+			y:    e.Y,
+			vx:   speed * math.Cos(dir),
+			vy:   speed * math.Sin(dir),
+			life: life,
+		})
+	}
+}
+
+// Update advances the particles by one tick, and emits new particles based on Rate.
+//
+// Update is usually called once in a game's update function.
+func (e *Emitter) Update() {
+	ps := e.particles[:0]
+	for _, p := range e.particles {
+		p.age++
+		if p.age >= p.life {
+			continue
+		}
+		p.vx += e.GravityX
+		p.vy += e.GravityY
+		p.x += p.vx
+		p.y += p.vy
+		ps = append(ps, p)
+	}
+	e.particles = ps
+
+	e.remainder += e.Rate
+	n := int(e.remainder)
+	e.remainder -= float64(n)
+	e.Emit(n)
+}
+
+// Len returns the number of the living particles.
+func (e *Emitter) Len() int {
+	return len(e.particles)
+}
+
+// Clear removes all the particles.
+func (e *Emitter) Clear() {
+	e.particles = e.particles[:0]
+	e.remainder = 0
+}
+
+// colorStep returns the index of the discrete color of the particle.
+// If the color doesn't change, colorStep returns 0.
+func (e *Emitter) colorStep(p *particle) int {
+	if e.Color == nil {
+		return 0
+	}
+	return int(p.t() * ColorSteps)
+}
+
+// Draw draws the particles on the target image.
+//
+// The particles are drawn in the order of their colors instead of their ages,
+// so that the particles with the same color are drawn successively and the draw commands are merged.
+func (e *Emitter) Draw(target *ebiten.Image) {
+	if e.Image == nil || len(e.particles) == 0 {
+		return
+	}
+
+	// Sort the particles by the color step with counting sort.
+	for i := range e.counts {
+		e.counts[i] = 0
+	}
+	for i := range e.particles {
+		e.counts[e.colorStep(&e.particles[i])]++
+	}
+	offsets := e.counts
+	sum := 0
+	for i, c := range e.counts {
+		offsets[i] = sum
+		sum += c
+	}
+	if cap(e.indices) < len(e.particles) {
+		e.indices = make([]int, len(e.particles))
+	}
+	e.indices = e.indices[:len(e.particles)]
+	for i := range e.particles {
+		s := e.colorStep(&e.particles[i])
+		e.indices[offsets[s]] = i
+		offsets[s]++
+	}
+
+	w, h := e.Image.Size()
+	op := &ebiten.DrawImageOptions{}
+	op.CompositeMode = e.CompositeMode
+	lastStep := -1
+	for _, i := range e.indices {
+		p := &e.particles[i]
+		t := p.t()
+
+		op.GeoM.Reset()
+		op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+		if e.Scale != nil {
+			s := e.Scale(t)
+			op.GeoM.Scale(s, s)
+		}
+		op.GeoM.Translate(p.x, p.y)
+
+		if s := e.colorStep(p); s != lastStep {
+			// Use the same ColorM for the same step so that the draw commands are merged.
+			op.ColorM.Reset()
+			if e.Color != nil {
+				r, g, b, a := e.Color(float64(s) / ColorSteps).RGBA()
+				if a > 0 {
+					// The color is premultiplied. Convert it to non-premultiplied one for ColorM.
+					op.ColorM.Scale(float64(r)/float64(a), float64(g)/float64(a), float64(b)/float64(a), float64(a)/0xffff)
+				} else {
+					op.ColorM.Scale(0, 0, 0, 0)
+				}
+			}
+			lastStep = s
+		}
+		target.DrawImage(e.Image, op)
+	}
+}
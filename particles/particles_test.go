@@ -0,0 +1,70 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package particles
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestEmitterLifetime(t *testing.T) {
+	e := &Emitter{
+		Rate:     0.5,
+		Lifetime: 4,
+	}
+	want := []int{0, 1, 1, 2, 2, 2, 2, 2}
+	for i, w := range want {
+		e.Update()
+		if got := e.Len(); got != w {
+			t.Errorf("Len() at tick %d: got: %d, want: %d", i, got, w)
+		}
+	}
+
+	e.Clear()
+	if got := e.Len(); got != 0 {
+		t.Errorf("Len() after Clear(): got: %d, want: 0", got)
+	}
+}
+
+func TestEmitterGravity(t *testing.T) {
+	e := &Emitter{
+		X:        10,
+		Y:        20,
+		Lifetime: 10,
+		Speed:    2,
+		GravityY: 1,
+	}
+	e.Emit(1)
+	for i := 0; i < 3; i++ {
+		e.Update()
+	}
+	p := e.particles[0]
+	// The velocity is (2, 0) at first, and (2, 1), (2, 2), (2, 3) after each tick.
+	if got, want := p.x, 16.0; math.Abs(got-want) > 1e-9 {
+		t.Errorf("x: got: %f, want: %f", got, want)
+	}
+	if got, want := p.y, 26.0; math.Abs(got-want) > 1e-9 {
+		t.Errorf("y: got: %f, want: %f", got, want)
+	}
+}
+
+func TestLinearColor(t *testing.T) {
+	c := LinearColor(color.RGBA{0xff, 0, 0, 0xff}, color.RGBA{0, 0, 0xff, 0xff})
+	r, g, b, a := c(0.5).RGBA()
+	if r != 0x7fff || g != 0 || b != 0x7fff || a != 0xffff {
+		t.Errorf("c(0.5): got: (%x, %x, %x, %x), want: (7fff, 0, 7fff, ffff)", r, g, b, a)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/input"
+)
+
+// PenState represents the state of a pen (stylus).
+type PenState struct {
+	// X and Y are the position of the pen in the logical screen coordinates.
+	// Unlike CursorPosition, the position has sub-pixel precision.
+	X float64
+	Y float64
+
+	// Pressure is the pressure of the pen in [0, 1].
+	// Pressure is 0 when the pen hovers, and 0.5 when the pen is pressed on a device that doesn't support pressure.
+	Pressure float64
+
+	// TiltX and TiltY are the angles of the pen in radians in [-π/2, π/2].
+	// TiltX is the angle between the Y-Z plane and the plane containing the pen and the Y axis, positive toward the right.
+	// TiltY is the angle between the X-Z plane and the plane containing the pen and the X axis, positive toward the bottom.
+	TiltX float64
+	TiltY float64
+
+	// Eraser reports whether the eraser end or the eraser button of the pen is used.
+	Eraser bool
+
+	// Pressed reports whether the pen touches the screen.
+	Pressed bool
+}
+
+// Pen returns the state of the pen on or over the screen.
+//
+// ok is false when no pen is on or over the screen.
+// The pen is also reported as a cursor or a touch by CursorPosition or Touches as the platform does.
+//
+// Pen is available only on browsers supporting Pointer Events. Pen is not available on desktops and mobiles yet,
+// and ok is always false there.
+//
+// This function is concurrent-safe.
+func Pen() (state PenState, ok bool) {
+	s, ok := input.GetPen().State()
+	if !ok {
+		return PenState{}, false
+	}
+	return PenState{
+		X:        s.X,
+		Y:        s.Y,
+		Pressure: s.Pressure,
+		TiltX:    s.TiltX,
+		TiltY:    s.TiltY,
+		Eraser:   s.Eraser,
+		Pressed:  s.Pressed,
+	}, true
+}
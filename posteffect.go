@@ -0,0 +1,194 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+	"image"
+	"sync/atomic"
+)
+
+// PostEffect represents a post-processing pass applied to the screen.
+//
+// See SetPostEffects.
+type PostEffect interface {
+	// Apply renders src with the effect on dst.
+	//
+	// dst and src have the same size as the screen. dst is cleared before Apply is called.
+	// Apply must not modify src.
+	Apply(dst, src *Image) error
+}
+
+// thePostEffects is the current chain of post effects ([]PostEffect).
+var thePostEffects atomic.Value
+
+// SetPostEffects sets the chain of post effects applied to the screen.
+//
+// After the screen is rendered by the update function, the first effect is applied to the screen
+// and each following effect is applied to the result of the previous one.
+// The result of the last effect is presented instead of the screen.
+// The post effects are applied once per presented frame, not per update.
+//
+// SetPostEffects without arguments removes the post effects.
+//
+// This function is concurrent-safe.
+func SetPostEffects(effects ...PostEffect) {
+	es := make([]PostEffect, len(effects))
+	copy(es, effects)
+	thePostEffects.Store(es)
+}
+
+func currentPostEffects() []PostEffect {
+	es, _ := thePostEffects.Load().([]PostEffect)
+	return es
+}
+
+// scanlinesPattern is a 1x2 image of a white line and a black line.
+var scanlinesPattern *Image
+
+// ScanlinesEffect is a post effect to darken every other line like a CRT display.
+type ScanlinesEffect struct {
+	// Intensity is the darkness of the dark lines in [0, 1]. 0 means no effect.
+	Intensity float64
+}
+
+// Apply implements PostEffect.
+func (s *ScanlinesEffect) Apply(dst, src *Image) error {
+	op := &DrawImageOptions{}
+	op.CompositeMode = CompositeModeCopy
+	_ = dst.DrawImage(src, op)
+
+	if scanlinesPattern == nil {
+		scanlinesPattern, _ = NewImage(1, 2, FilterDefault)
+		theImages.remove(scanlinesPattern)
+		_ = scanlinesPattern.ReplacePixels([]byte{
+			0xff, 0xff, 0xff, 0xff,
+			0, 0, 0, 0xff,
+		})
+	}
+
+	// Multiply the screen by the repeated pattern. The white lines keep the colors and
+	// the black lines become 1 - Intensity.
+	w, h := dst.Size()
+	op = &DrawImageOptions{}
+	op.ColorM.Scale(s.Intensity, s.Intensity, s.Intensity, 1)
+	op.ColorM.Translate(1-s.Intensity, 1-s.Intensity, 1-s.Intensity, 0)
+	op.CompositeMode = CompositeModeMultiply
+	op.Address = AddressRepeat
+	r := image.Rect(0, 0, w, h)
+	op.SourceRect = &r
+	_ = dst.DrawImage(scanlinesPattern, op)
+	return nil
+}
+
+// bloomLevels is the number of the downsampled images for BloomEffect.
+const bloomLevels = 3
+
+// BloomEffect is a post effect to make bright parts glow.
+type BloomEffect struct {
+	// Threshold is the brightness in [0, 1] over which the parts glow.
+	Threshold float64
+
+	// Intensity is the strength of the glow.
+	Intensity float64
+
+	// images are the downsampled images. The size of the n-th image is 1/2^(n+1) of the screen.
+	images [bloomLevels]*Image
+}
+
+// Apply implements PostEffect.
+func (b *BloomEffect) Apply(dst, src *Image) error {
+	op := &DrawImageOptions{}
+	op.CompositeMode = CompositeModeCopy
+	_ = dst.DrawImage(src, op)
+
+	if b.Threshold >= 1 || b.Intensity <= 0 {
+		return nil
+	}
+
+	// Blur the bright parts in the same color space as src.
+	format := src.Format()
+	if format == PixelFormatAlpha8 || format.isCompressed() {
+		format = PixelFormatRGBA8
+	}
+	w, h := src.Size()
+	for i := range b.images {
+		w, h = (w+1)/2, (h+1)/2
+		if b.images[i] != nil {
+			if iw, ih := b.images[i].Size(); iw == w && ih == h && b.images[i].Format() == format {
+				continue
+			}
+			_ = b.images[i].Dispose()
+		}
+		b.images[i] = newVolatileImage(w, h, format)
+	}
+
+	// Extract the bright parts with downsampling.
+	k := 1 / (1 - b.Threshold)
+	op = &DrawImageOptions{}
+	op.GeoM.Scale(0.5, 0.5)
+	op.ColorM.Scale(k, k, k, 1)
+	op.ColorM.Translate(-b.Threshold*k, -b.Threshold*k, -b.Threshold*k, 0)
+	op.CompositeMode = CompositeModeCopy
+	op.Filter = FilterLinear
+	b.images[0].fill(0, 0, 0, 0)
+	_ = b.images[0].DrawImage(src, op)
+
+	// Blur the bright parts by downsampling them repeatedly.
+	for i := 1; i < len(b.images); i++ {
+		op := &DrawImageOptions{}
+		op.GeoM.Scale(0.5, 0.5)
+		op.CompositeMode = CompositeModeCopy
+		op.Filter = FilterLinear
+		b.images[i].fill(0, 0, 0, 0)
+		_ = b.images[i].DrawImage(b.images[i-1], op)
+	}
+
+	// Add the blurred bright parts by upsampling them.
+	sw, sh := src.Size()
+	for _, img := range b.images {
+		w, h := img.Size()
+		op := &DrawImageOptions{}
+		op.GeoM.Scale(float64(sw)/float64(w), float64(sh)/float64(h))
+		op.ColorM.Scale(b.Intensity, b.Intensity, b.Intensity, 1)
+		op.CompositeMode = CompositeModeLighter
+		op.Filter = FilterLinear
+		_ = dst.DrawImage(img, op)
+	}
+	return nil
+}
+
+// ColorGradingEffect is a post effect to convert the colors with a color look-up table (LUT).
+type ColorGradingEffect struct {
+	// LUT is the color look-up table image.
+	//
+	// The LUT image consists of N tiles of NxN pixels arranged horizontally, that is, the size is (N*N, N).
+	// The n-th tile is for the n-th blue level, and the X and Y axes in a tile are for the red and green levels.
+	// The colors between the levels are interpolated.
+	//
+	// The LUT image must be opaque. The LUT image is moved to its own texture when it is used.
+	LUT *Image
+}
+
+// Apply implements PostEffect.
+//
+// Apply panics if the size of the LUT image is not (N*N, N).
+func (c *ColorGradingEffect) Apply(dst, src *Image) error {
+	if w, h := c.LUT.Size(); w != h*h {
+		panic(fmt.Sprintf("ebiten: the LUT image size must be (N*N, N) but was (%d, %d)", w, h))
+	}
+	dst.drawImageWithLUT(src, c.LUT)
+	return nil
+}
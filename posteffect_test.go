@@ -0,0 +1,120 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image/color"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten"
+)
+
+func TestScanlinesEffect(t *testing.T) {
+	src, _ := NewImage(4, 4, FilterDefault)
+	src.Fill(color.RGBA{0x80, 0x80, 0x80, 0xff})
+	dst, _ := NewImage(4, 4, FilterDefault)
+
+	e := &ScanlinesEffect{Intensity: 0.5}
+	if err := e.Apply(dst, src); err != nil {
+		t.Fatal(err)
+	}
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			want := color.RGBA{0x80, 0x80, 0x80, 0xff}
+			if j%2 == 1 {
+				want = color.RGBA{0x40, 0x40, 0x40, 0xff}
+			}
+			if !sameColors(got, want, 1) {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestBloomEffect(t *testing.T) {
+	const w, h = 32, 32
+	src, _ := NewImage(w, h, FilterDefault)
+	src.Fill(color.Black)
+	white, _ := NewImage(4, 4, FilterDefault)
+	white.Fill(color.White)
+	op := &DrawImageOptions{}
+	op.GeoM.Translate(8, 8)
+	src.DrawImage(white, op)
+
+	dst, _ := NewImage(w, h, FilterDefault)
+	e := &BloomEffect{Threshold: 0.5, Intensity: 1}
+	if err := e.Apply(dst, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := dst.At(9, 9).(color.RGBA), (color.RGBA{0xff, 0xff, 0xff, 0xff}); got != want {
+		t.Errorf("dst.At(9, 9): got: %v, want: %v", got, want)
+	}
+	// The bright part glows around it.
+	if got := dst.At(13, 9).(color.RGBA); got.R == 0 {
+		t.Errorf("dst.At(13, 9): got: %v, want: a glowing color", got)
+	}
+	// The dark parts far from the bright part don't change.
+	if got, want := dst.At(30, 30).(color.RGBA), (color.RGBA{0, 0, 0, 0xff}); got != want {
+		t.Errorf("dst.At(30, 30): got: %v, want: %v", got, want)
+	}
+}
+
+func TestColorGradingEffect(t *testing.T) {
+	// The LUT inverts the colors with 2 levels for each component.
+	const n = 2
+	pix := make([]byte, 4*n*n*n)
+	for b := 0; b < n; b++ {
+		for g := 0; g < n; g++ {
+			for r := 0; r < n; r++ {
+				idx := 4 * (g*n*n + b*n + r)
+				pix[idx] = byte(0xff * (n - 1 - r) / (n - 1))
+				pix[idx+1] = byte(0xff * (n - 1 - g) / (n - 1))
+				pix[idx+2] = byte(0xff * (n - 1 - b) / (n - 1))
+				pix[idx+3] = 0xff
+			}
+		}
+	}
+	lut, _ := NewImage(n*n, n, FilterDefault)
+	lut.ReplacePixels(pix)
+
+	clrs := []color.RGBA{
+		{0, 0, 0, 0xff},
+		{0xff, 0, 0x80, 0xff},
+		{0x40, 0xc0, 0xff, 0xff},
+	}
+	src, _ := NewImage(len(clrs), 1, FilterDefault)
+	for i, c := range clrs {
+		img, _ := NewImage(1, 1, FilterDefault)
+		img.Fill(c)
+		op := &DrawImageOptions{}
+		op.GeoM.Translate(float64(i), 0)
+		src.DrawImage(img, op)
+	}
+
+	dst, _ := NewImage(len(clrs), 1, FilterDefault)
+	e := &ColorGradingEffect{LUT: lut}
+	if err := e.Apply(dst, src); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range clrs {
+		got := dst.At(i, 0).(color.RGBA)
+		want := color.RGBA{0xff - c.R, 0xff - c.G, 0xff - c.B, 0xff}
+		if !sameColors(got, want, 2) {
+			t.Errorf("dst.At(%d, 0): got: %v, want: %v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"image"
+	"sync/atomic"
+)
+
+// FrameRecorder records the frames presented on the screen, e.g. to capture a video.
+//
+// See SetFrameRecorder. The recorder package provides FrameRecorder implementations.
+type FrameRecorder interface {
+	// RecordFrame records a frame.
+	//
+	// frame is the screen after the post effects are applied, in the screen size and not scaled.
+	// The pixels are in sRGB color space when sRGB rendering is enabled.
+	// frame is valid only during the call. RecordFrame must copy frame to keep it.
+	RecordFrame(frame *image.RGBA) error
+}
+
+// frameRecorderHolder holds a FrameRecorder, as atomic.Value can't store nil.
+type frameRecorderHolder struct {
+	recorder FrameRecorder
+}
+
+var theFrameRecorder atomic.Value
+
+// SetFrameRecorder sets the recorder to record the frames.
+//
+// RecordFrame of the recorder is called once per presented frame, not per update.
+// The pixels are read from GPU synchronously, which makes a frame slower.
+// If RecordFrame returns error, the game is terminated with the error samely as the update function of Run.
+//
+// SetFrameRecorder(nil) stops recording.
+//
+// This function is concurrent-safe.
+func SetFrameRecorder(recorder FrameRecorder) {
+	theFrameRecorder.Store(frameRecorderHolder{recorder})
+}
+
+func currentFrameRecorder() FrameRecorder {
+	h, _ := theFrameRecorder.Load().(frameRecorderHolder)
+	return h.recorder
+}
+
+// recordFrame reads the pixels of img and passes them to r.
+func recordFrame(r FrameRecorder, img *Image) error {
+	pix, err := img.shareableImage.Pixels()
+	if err != nil {
+		return err
+	}
+	w, h := img.Size()
+	return r.RecordFrame(&image.RGBA{
+		Pix:    pix,
+		Stride: 4 * w,
+		Rect:   image.Rect(0, 0, w, h),
+	})
+}
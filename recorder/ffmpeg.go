@@ -0,0 +1,145 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// FFmpeg is a recorder to pipe the raw frames to the ffmpeg command to encode a video.
+//
+// The ffmpeg command must be in PATH. The video's frame rate is ebiten.FPS, which matches the recorded frames
+// when the display's refresh rate is 60 Hz.
+type FFmpeg struct {
+	output string
+	args   []string
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr bytes.Buffer
+	width  int
+	height int
+
+	// err is the error occurred while ffmpeg runs.
+	err error
+}
+
+// NewFFmpeg returns a new FFmpeg to encode the frames to the file output, e.g. "trailer.mp4" or "bug.webm".
+// The container and the codec are chosen by ffmpeg from the extension of output.
+// The output file is overwritten if it exists.
+//
+// args are the additional output options of ffmpeg, e.g. "-c:v", "libx264", "-pix_fmt", "yuv420p".
+//
+// ffmpeg starts at the first frame, as the screen size is not known until then.
+func NewFFmpeg(output string, args ...string) *FFmpeg {
+	return &FFmpeg{
+		output: output,
+		args:   append([]string{}, args...),
+	}
+}
+
+func (f *FFmpeg) start(width, height int) error {
+	args := []string{
+		"-y",
+		"-f", "rawvideo",
+		"-pixel_format", "rgba",
+		"-video_size", fmt.Sprintf("%dx%d", width, height),
+		"-framerate", fmt.Sprintf("%d", ebiten.FPS),
+		"-i", "-",
+	}
+	args = append(args, f.args...)
+	args = append(args, f.output)
+
+	cmd := exec.Command("ffmpeg", args...)
+	cmd.Stderr = &f.stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	f.cmd = cmd
+	f.stdin = stdin
+	f.width = width
+	f.height = height
+	return nil
+}
+
+// RecordFrame implements ebiten.FrameRecorder.
+//
+// RecordFrame returns error when the screen size is changed while recording.
+func (f *FFmpeg) RecordFrame(frame *image.RGBA) error {
+	if f.err != nil {
+		return f.err
+	}
+	w, h := frame.Rect.Dx(), frame.Rect.Dy()
+	if f.cmd == nil {
+		if err := f.start(w, h); err != nil {
+			return err
+		}
+	}
+	if w != f.width || h != f.height {
+		return fmt.Errorf("recorder: the screen size was changed from (%d, %d) to (%d, %d) while recording", f.width, f.height, w, h)
+	}
+	if frame.Stride != 4*w {
+		frame = copyFrame(frame)
+	}
+	if _, err := f.stdin.Write(frame.Pix[:4*w*h]); err != nil {
+		// ffmpeg might exit with an error. Wait for ffmpeg to get its output.
+		_ = f.stdin.Close()
+		_ = f.cmd.Wait()
+		f.err = f.wrapError(err)
+		return f.err
+	}
+	return nil
+}
+
+// Close finishes the video and waits for ffmpeg to exit.
+//
+// RecordFrame must not be called after Close.
+func (f *FFmpeg) Close() error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.cmd == nil {
+		return nil
+	}
+	if err := f.stdin.Close(); err != nil {
+		return err
+	}
+	if err := f.cmd.Wait(); err != nil {
+		return f.wrapError(err)
+	}
+	return nil
+}
+
+// wrapError adds the output of ffmpeg to err, which is usually more informative than err.
+//
+// wrapError must be called after ffmpeg exits.
+func (f *FFmpeg) wrapError(err error) error {
+	msg := strings.TrimSpace(f.stderr.String())
+	if msg == "" {
+		return err
+	}
+	return fmt.Errorf("recorder: %v: %s", err, msg)
+}
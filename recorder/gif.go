@@ -0,0 +1,177 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// gifFrameSkips is the number of the recorded frames per frame of a GIF.
+//
+// The delay of a GIF frame is in 1/100 seconds, and browsers don't respect a delay less than 2.
+// Thinning out the frames to 20 FPS makes the delay exactly 5.
+const gifFrameSkips = 3
+
+// GIF is a recorder to keep the recent frames in a rolling buffer and save them as an animated GIF,
+// e.g. to share the last few seconds of gameplay by pressing a key.
+//
+// The frames are thinned out to 20 FPS and downsampled when recorded. The colors are quantized only in Save.
+type GIF struct {
+	scale float64
+
+	// frames is a ring buffer of the downsampled frames. head is the index of the oldest frame.
+	frames []*image.RGBA
+	head   int
+	num    int
+
+	ticks int
+	m     sync.Mutex
+}
+
+// NewGIF returns a new GIF to keep the frames of the last duration.
+//
+// scale is the scale to downsample the frames in (0, 1], e.g. 0.5 makes a GIF of the half size of the screen.
+//
+// NewGIF panics if duration or scale is out of range.
+func NewGIF(duration time.Duration, scale float64) *GIF {
+	if duration <= 0 {
+		panic("recorder: duration must be positive")
+	}
+	if scale <= 0 || 1 < scale {
+		panic("recorder: scale must be in (0, 1]")
+	}
+	n := int(duration * ebiten.FPS / gifFrameSkips / time.Second)
+	if n < 1 {
+		n = 1
+	}
+	return &GIF{
+		scale:  scale,
+		frames: make([]*image.RGBA, n),
+	}
+}
+
+// RecordFrame implements ebiten.FrameRecorder.
+func (g *GIF) RecordFrame(frame *image.RGBA) error {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	g.ticks++
+	if (g.ticks-1)%gifFrameSkips != 0 {
+		return nil
+	}
+
+	idx := (g.head + g.num) % len(g.frames)
+	if g.num < len(g.frames) {
+		g.num++
+	} else {
+		g.head = (g.head + 1) % len(g.frames)
+	}
+	g.frames[idx] = downsample(g.frames[idx], frame, g.scale)
+	return nil
+}
+
+// downsample scales src by scale with the nearest-neighbor filter. downsample reuses dst if possible.
+func downsample(dst, src *image.RGBA, scale float64) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	w, h := int(float64(sw)*scale), int(float64(sh)*scale)
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	if dst == nil || dst.Rect.Dx() != w || dst.Rect.Dy() != h {
+		dst = image.NewRGBA(image.Rect(0, 0, w, h))
+	}
+	for j := 0; j < h; j++ {
+		sy := j * sh / h
+		for i := 0; i < w; i++ {
+			sx := i * sw / w
+			s := src.PixOffset(src.Rect.Min.X+sx, src.Rect.Min.Y+sy)
+			d := dst.PixOffset(i, j)
+			copy(dst.Pix[d:d+4], src.Pix[s:s+4])
+		}
+	}
+	return dst
+}
+
+// Save writes the frames kept in the buffer to w as an animated GIF.
+//
+// The colors are quantized with the Plan 9 palette and dithering, which takes a while for many frames.
+// The recording continues while saving.
+//
+// Save does nothing when no frames are recorded.
+func (g *GIF) Save(w io.Writer) error {
+	g.m.Lock()
+	frames := make([]*image.RGBA, g.num)
+	for i := range frames {
+		f := g.frames[(g.head+i)%len(g.frames)]
+		frames[i] = image.NewRGBA(f.Rect)
+		copy(frames[i].Pix, f.Pix)
+	}
+	g.m.Unlock()
+
+	if len(frames) == 0 {
+		return nil
+	}
+
+	out := &gif.GIF{
+		Image: make([]*image.Paletted, len(frames)),
+		Delay: make([]int, len(frames)),
+	}
+	// The frames might have different sizes when the screen size is changed.
+	// The GIF's size must cover all the frames.
+	out.Config.ColorModel = color.Palette(palette.Plan9)
+	for _, f := range frames {
+		if out.Config.Width < f.Rect.Dx() {
+			out.Config.Width = f.Rect.Dx()
+		}
+		if out.Config.Height < f.Rect.Dy() {
+			out.Config.Height = f.Rect.Dy()
+		}
+	}
+	var wg sync.WaitGroup
+	for i, f := range frames {
+		i, f := i, f
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			img := image.NewPaletted(f.Rect, palette.Plan9)
+			draw.FloydSteinberg.Draw(img, img.Rect, f, f.Rect.Min)
+			out.Image[i] = img
+			out.Delay[i] = 100 * gifFrameSkips / ebiten.FPS
+		}()
+	}
+	wg.Wait()
+	return gif.EncodeAll(w, out)
+}
+
+// Reset discards the frames kept in the buffer.
+func (g *GIF) Reset() {
+	g.m.Lock()
+	defer g.m.Unlock()
+	g.head = 0
+	g.num = 0
+	g.ticks = 0
+}
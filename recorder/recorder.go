@@ -0,0 +1,142 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package recorder provides recorders to capture the game screen, e.g. for trailers and bug reports.
+//
+// A recorder is an ebiten.FrameRecorder. Set a recorder by ebiten.SetFrameRecorder to start recording,
+// and call ebiten.SetFrameRecorder(nil) and Close to stop recording:
+//
+//     r := recorder.NewImageSequence("frames")
+//     ebiten.SetFrameRecorder(r)
+//     ...
+//     ebiten.SetFrameRecorder(nil)
+//     if err := r.Close(); err != nil {
+//         ...
+//     }
+//
+// GIF is different from the other recorders: GIF keeps only the recent frames and saves them on demand by Save.
+//
+// The recorders except for GIF don't work on browsers.
+//
+// Note: This package is experimental and API might be changed.
+package recorder
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// copyFrame returns a copy of frame, as a frame is valid only during RecordFrame.
+func copyFrame(frame *image.RGBA) *image.RGBA {
+	f := image.NewRGBA(image.Rect(0, 0, frame.Rect.Dx(), frame.Rect.Dy()))
+	for j := 0; j < f.Rect.Dy(); j++ {
+		copy(f.Pix[j*f.Stride:(j+1)*f.Stride], frame.Pix[frame.PixOffset(frame.Rect.Min.X, frame.Rect.Min.Y+j):])
+	}
+	return f
+}
+
+// ImageSequence is a recorder to write the frames as PNG files, frame00000.png, frame00001.png and so on.
+//
+// The frames are encoded on another goroutine not to block the game. If the encoding is slower than the game,
+// RecordFrame waits for the encoding.
+type ImageSequence struct {
+	dir    string
+	frames chan *image.RGBA
+	done   chan struct{}
+
+	err error
+	m   sync.Mutex
+}
+
+// NewImageSequence returns a new ImageSequence to write the frames to the directory dir.
+//
+// The directory is created if it doesn't exist.
+func NewImageSequence(dir string) *ImageSequence {
+	s := &ImageSequence{
+		dir:    dir,
+		frames: make(chan *image.RGBA, 60),
+		done:   make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+func (s *ImageSequence) loop() {
+	defer close(s.done)
+
+	e := &png.Encoder{
+		// Encoding speed matters more than the file size for a sequence of frames.
+		CompressionLevel: png.BestSpeed,
+	}
+	n := 0
+	for f := range s.frames {
+		if s.error() != nil {
+			// Drain the frames.
+			continue
+		}
+		if err := s.write(e, f, n); err != nil {
+			s.m.Lock()
+			s.err = err
+			s.m.Unlock()
+		}
+		n++
+	}
+}
+
+func (s *ImageSequence) write(e *png.Encoder, frame *image.RGBA, n int) error {
+	if n == 0 {
+		if err := os.MkdirAll(s.dir, 0755); err != nil {
+			return err
+		}
+	}
+	file, err := os.Create(filepath.Join(s.dir, fmt.Sprintf("frame%05d.png", n)))
+	if err != nil {
+		return err
+	}
+	if err := e.Encode(file, frame); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (s *ImageSequence) error() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.err
+}
+
+// RecordFrame implements ebiten.FrameRecorder.
+//
+// RecordFrame returns the error occurred in writing the previous frames if any.
+func (s *ImageSequence) RecordFrame(frame *image.RGBA) error {
+	if err := s.error(); err != nil {
+		return err
+	}
+	s.frames <- copyFrame(frame)
+	return nil
+}
+
+// Close waits for writing all the recorded frames and returns the error occurred in writing if any.
+//
+// RecordFrame must not be called after Close.
+func (s *ImageSequence) Close() error {
+	close(s.frames)
+	<-s.done
+	return s.error()
+}
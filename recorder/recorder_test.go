@@ -0,0 +1,258 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+	. "github.com/hajimehoshi/ebiten/recorder"
+)
+
+var (
+	_ ebiten.FrameRecorder = (*ImageSequence)(nil)
+	_ ebiten.FrameRecorder = (*FFmpeg)(nil)
+	_ ebiten.FrameRecorder = (*GIF)(nil)
+)
+
+func testFrame(n int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for j := 0; j < 2; j++ {
+		for i := 0; i < 4; i++ {
+			img.SetRGBA(i, j, color.RGBA{byte(n), byte(i), byte(j), 0xff})
+		}
+	}
+	return img
+}
+
+func TestImageSequence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ebiten")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "frames")
+	s := NewImageSequence(out)
+	const n = 3
+	for i := 0; i < n; i++ {
+		frame := testFrame(i)
+		if err := s.RecordFrame(frame); err != nil {
+			t.Fatal(err)
+		}
+		// The frame can be reused after RecordFrame.
+		for j := range frame.Pix {
+			frame.Pix[j] = 0
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < n; i++ {
+		name := filepath.Join(out, []string{"frame00000.png", "frame00001.png", "frame00002.png"}[i])
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := testFrame(i)
+		if got := img.Bounds(); got != want.Bounds() {
+			t.Errorf("%s: Bounds(): got: %v, want: %v", name, got, want.Bounds())
+			continue
+		}
+		for j := 0; j < 2; j++ {
+			for k := 0; k < 4; k++ {
+				if got, want := color.RGBAModel.Convert(img.At(k, j)), want.At(k, j); got != want {
+					t.Errorf("%s: At(%d, %d): got: %v, want: %v", name, k, j, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestImageSequenceError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ebiten")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A file prevents the directory from being created.
+	out := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(out, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewImageSequence(out)
+	if err := s.RecordFrame(testFrame(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err == nil {
+		t.Errorf("Close(): got: nil, want: an error")
+	}
+}
+
+func TestFFmpeg(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg is not found")
+	}
+
+	dir, err := ioutil.TempDir("", "ebiten")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "out.png")
+	f := NewFFmpeg(out, "-frames:v", "1")
+	if err := f.RecordFrame(testFrame(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFFmpegSizeChange(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg is not found")
+	}
+
+	dir, err := ioutil.TempDir("", "ebiten")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := NewFFmpeg(filepath.Join(dir, "out.webm"))
+	defer f.Close()
+	if err := f.RecordFrame(testFrame(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RecordFrame(image.NewRGBA(image.Rect(0, 0, 8, 8))); err == nil {
+		t.Errorf("RecordFrame with another size: got: nil, want: an error")
+	}
+}
+
+func filledFrame(w, h int, clr color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			img.SetRGBA(i, j, clr)
+		}
+	}
+	return img
+}
+
+func TestGIF(t *testing.T) {
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	black := color.RGBA{0, 0, 0, 0xff}
+
+	// 500ms is 30 frames, and 10 frames are kept after thinning out.
+	g := NewGIF(500*time.Millisecond, 0.5)
+	for i := 0; i < 40; i++ {
+		clr := white
+		if i >= 20 {
+			clr = black
+		}
+		if err := g.RecordFrame(filledFrame(8, 4, clr)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := g.Save(buf); err != nil {
+		t.Fatal(err)
+	}
+	out, err := gif.DecodeAll(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(out.Image), 10; got != want {
+		t.Fatalf("len(Image): got: %d, want: %d", got, want)
+	}
+	// The kept frames are the 12th, 15th, ..., 39th frames.
+	for i, img := range out.Image {
+		if got, want := img.Bounds(), image.Rect(0, 0, 4, 2); got != want {
+			t.Errorf("Image[%d].Bounds(): got: %v, want: %v", i, got, want)
+		}
+		want := white
+		if 12+3*i >= 20 {
+			want = black
+		}
+		if got := color.RGBAModel.Convert(img.At(0, 0)); got != want {
+			t.Errorf("Image[%d].At(0, 0): got: %v, want: %v", i, got, want)
+		}
+		if got, want := out.Delay[i], 5; got != want {
+			t.Errorf("Delay[%d]: got: %d, want: %d", i, got, want)
+		}
+	}
+
+	g.Reset()
+	buf.Reset()
+	if err := g.Save(buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Save after Reset: got: %d bytes, want: 0 bytes", buf.Len())
+	}
+}
+
+func TestGIFSizeChange(t *testing.T) {
+	g := NewGIF(time.Second, 1)
+	if err := g.RecordFrame(filledFrame(4, 4, color.RGBA{0xff, 0, 0, 0xff})); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := g.RecordFrame(filledFrame(8, 2, color.RGBA{0, 0xff, 0, 0xff})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := g.Save(buf); err != nil {
+		t.Fatal(err)
+	}
+	out, err := gif.DecodeAll(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(out.Image), 2; got != want {
+		t.Fatalf("len(Image): got: %d, want: %d", got, want)
+	}
+	if got, want := out.Config.Width, 8; got != want {
+		t.Errorf("Config.Width: got: %d, want: %d", got, want)
+	}
+	if got, want := out.Config.Height, 4; got != want {
+		t.Errorf("Config.Height: got: %d, want: %d", got, want)
+	}
+}
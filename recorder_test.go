@@ -0,0 +1,84 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+type testFrameRecorder struct {
+	frames []*image.RGBA
+}
+
+func (t *testFrameRecorder) RecordFrame(frame *image.RGBA) error {
+	f := image.NewRGBA(frame.Bounds())
+	copy(f.Pix, frame.Pix)
+	t.frames = append(t.frames, f)
+	return nil
+}
+
+func TestRecordFrame(t *testing.T) {
+	// The offscreen is volatile and its pixels are read from GPU.
+	img := newVolatileImage(8, 4, PixelFormatRGBA8)
+	img.fill(0, 0, 0, 0)
+	src, _ := NewImage(2, 2, FilterDefault)
+	src.Fill(color.RGBA{0x80, 0x40, 0x20, 0xff})
+	op := &DrawImageOptions{}
+	op.GeoM.Translate(3, 1)
+	img.DrawImage(src, op)
+
+	r := &testFrameRecorder{}
+	if err := recordFrame(r, img); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(r.frames), 1; got != want {
+		t.Fatalf("len(frames): got: %d, want: %d", got, want)
+	}
+	f := r.frames[0]
+	if got, want := f.Bounds(), image.Rect(0, 0, 8, 4); got != want {
+		t.Errorf("Bounds(): got: %v, want: %v", got, want)
+	}
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 8; i++ {
+			got := f.RGBAAt(i, j)
+			want := color.RGBA{}
+			if 3 <= i && i < 5 && 1 <= j && j < 3 {
+				want = color.RGBA{0x80, 0x40, 0x20, 0xff}
+			}
+			if got != want {
+				t.Errorf("frame.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestSetFrameRecorder(t *testing.T) {
+	defer SetFrameRecorder(nil)
+
+	if currentFrameRecorder() != nil {
+		t.Errorf("currentFrameRecorder(): got: non-nil, want: nil")
+	}
+	r := &testFrameRecorder{}
+	SetFrameRecorder(r)
+	if currentFrameRecorder() != r {
+		t.Errorf("currentFrameRecorder(): got: %v, want: %v", currentFrameRecorder(), r)
+	}
+	SetFrameRecorder(nil)
+	if currentFrameRecorder() != nil {
+		t.Errorf("currentFrameRecorder() after SetFrameRecorder(nil): got: non-nil, want: nil")
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replay provides functions to record input and replay it deterministically.
+//
+// A Recorder records the input state and a random seed for every tick to a stream.
+// A Player replays the stream through the same update function, so that the game sees exactly
+// the same input and the same random numbers from Rand as the recorded session.
+// Player.Play replays the stream without the main loop, which is useful for automated regression
+// tests.
+//
+// Note: This package is experimental and API might be changed.
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/hooks"
+	"github.com/hajimehoshi/ebiten/internal/input"
+	"github.com/hajimehoshi/ebiten/internal/inputstate"
+)
+
+// ErrEnded is returned by the update function wrapped by Player when the replay ends.
+var ErrEnded = errors.New("replay: the replay ended")
+
+// tick is a record for one tick in the stream.
+type tick struct {
+	Seed  int64       `json:"seed"`
+	Input input.State `json:"input"`
+}
+
+// Recorder records the input state per tick.
+type Recorder struct {
+	enc  *json.Encoder
+	seed *rand.Rand
+	rand *rand.Rand
+}
+
+// NewRecorder returns a new Recorder writing to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		enc:  json.NewEncoder(w),
+		seed: rand.New(rand.NewSource(time.Now().UnixNano())),
+		rand: rand.New(rand.NewSource(0)),
+	}
+}
+
+// Rand returns the random number generator that is seeded with the recorded seed every tick.
+//
+// The game should use Rand instead of the global functions of math/rand so that the random
+// numbers are replayed.
+func (r *Recorder) Rand() *rand.Rand {
+	return r.rand
+}
+
+// Update returns an update function that records the current input state and calls f.
+//
+// Before f is called every tick, Rand and the global random source of math/rand are seeded with
+// a recorded value.
+func (r *Recorder) Update(f func(*ebiten.Image) error) func(*ebiten.Image) error {
+	return func(screen *ebiten.Image) error {
+		t := &tick{
+			Seed:  r.seed.Int63(),
+			Input: inputstate.Capture(),
+		}
+		if err := r.enc.Encode(t); err != nil {
+			return err
+		}
+		r.rand.Seed(t.Seed)
+		rand.Seed(t.Seed)
+		return f(screen)
+	}
+}
+
+// Player replays the recorded input state per tick.
+//
+// While a Player is replaying, the input functions in the ebiten package report the recorded state
+// instead of the actual input.
+type Player struct {
+	dec  *json.Decoder
+	next *tick
+	rand *rand.Rand
+}
+
+// NewPlayer returns a new Player reading the stream recorded by a Recorder from r.
+//
+// NewPlayer starts overriding the input state with the first recorded tick.
+func NewPlayer(r io.Reader) (*Player, error) {
+	p := &Player{
+		dec:  json.NewDecoder(r),
+		rand: rand.New(rand.NewSource(0)),
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Rand returns the random number generator that is seeded with the recorded seed every tick.
+func (p *Player) Rand() *rand.Rand {
+	return p.rand
+}
+
+// advance reads the next tick and overrides the input state with it.
+//
+// The input state must be overridden before the update hooks run, so the state for the next tick
+// is set as soon as the current tick ends.
+func (p *Player) advance() error {
+	t := &tick{}
+	if err := p.dec.Decode(t); err != nil {
+		p.next = nil
+		input.SetOverridingState(nil)
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	p.next = t
+	input.SetOverridingState(&t.Input)
+	return nil
+}
+
+// Update returns an update function that replays the recorded tick and calls f.
+//
+// When all the ticks are replayed, the returned function stops overriding the input state and
+// returns ErrEnded.
+func (p *Player) Update(f func(*ebiten.Image) error) func(*ebiten.Image) error {
+	return func(screen *ebiten.Image) error {
+		if p.next == nil {
+			return ErrEnded
+		}
+		p.rand.Seed(p.next.Seed)
+		rand.Seed(p.next.Seed)
+		if err := f(screen); err != nil {
+			input.SetOverridingState(nil)
+			return err
+		}
+		return p.advance()
+	}
+}
+
+// Play replays the rest of the stream by calling f with screen for every tick, without running
+// the main loop.
+//
+// Play returns nil when all the ticks are replayed, or the first error f returns.
+func (p *Player) Play(f func(*ebiten.Image) error, screen *ebiten.Image) error {
+	defer input.SetOverridingState(nil)
+
+	update := p.Update(f)
+	for p.next != nil {
+		if err := hooks.RunBeforeUpdateHooks(); err != nil {
+			return err
+		}
+		if err := update(screen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
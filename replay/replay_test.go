@@ -0,0 +1,137 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay_test
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/input"
+	. "github.com/hajimehoshi/ebiten/replay"
+)
+
+type frame struct {
+	Rand     int
+	KeyA     bool
+	Left     bool
+	X, Y     int
+	Axis     float64
+	Button0  bool
+	TouchIDs []int
+}
+
+func currentFrame(r *rand.Rand) frame {
+	f := frame{
+		Rand:    r.Int(),
+		KeyA:    ebiten.IsKeyPressed(ebiten.KeyA),
+		Left:    ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft),
+		Axis:    ebiten.GamepadAxis(0, 1),
+		Button0: ebiten.IsGamepadButtonPressed(0, ebiten.GamepadButton0),
+	}
+	f.X, f.Y = ebiten.CursorPosition()
+	for _, t := range ebiten.Touches() {
+		f.TouchIDs = append(f.TouchIDs, t.ID())
+	}
+	return f
+}
+
+func TestRecordAndPlay(t *testing.T) {
+	states := []*input.State{
+		{
+			Keys:     []input.Key{input.KeyA},
+			CursorX:  10,
+			CursorY:  20,
+			Gamepads: []input.GamepadState{{ID: 0, Axes: []float64{0, 0.5}, Buttons: []bool{true}}},
+		},
+		{
+			MouseButtons: []input.MouseButton{input.MouseButtonLeft},
+			CursorX:      30,
+			CursorY:      40,
+			Touches:      []input.TouchState{{ID: 1, X: 5, Y: 6}, {ID: 2, X: 7, Y: 8}},
+		},
+		{},
+	}
+
+	// Record the synthetic input states.
+	buf := &bytes.Buffer{}
+	var recorded []frame
+	r := NewRecorder(buf)
+	update := r.Update(func(*ebiten.Image) error {
+		recorded = append(recorded, currentFrame(r.Rand()))
+		return nil
+	})
+	for _, s := range states {
+		input.SetOverridingState(s)
+		if err := update(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	input.SetOverridingState(nil)
+
+	p, err := NewPlayer(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var replayed []frame
+	if err := p.Play(func(*ebiten.Image) error {
+		replayed = append(replayed, currentFrame(p.Rand()))
+		return nil
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Errorf("replayed frames: got: %v, want: %v", replayed, recorded)
+	}
+	if input.OverridingState() != nil {
+		t.Errorf("the input state must not be overridden after Play")
+	}
+}
+
+func TestPlayerEnded(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := NewRecorder(buf).Update(func(*ebiten.Image) error {
+		return nil
+	})
+	for i := 0; i < 2; i++ {
+		if err := r(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := NewPlayer(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	update := p.Update(func(*ebiten.Image) error {
+		n++
+		return nil
+	})
+	for i := 0; i < 2; i++ {
+		if err := update(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := update(nil); err != ErrEnded {
+		t.Errorf("update after the end: got: %v, want: %v", err, ErrEnded)
+	}
+	if n != 2 {
+		t.Errorf("the number of updates: got: %d, want: 2", n)
+	}
+}
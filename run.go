@@ -17,7 +17,9 @@ package ebiten
 import (
 	"fmt"
 	"image"
+	"math"
 	"os"
+	"sync"
 	"sync/atomic"
 
 	"github.com/hajimehoshi/ebiten/internal/clock"
@@ -33,13 +35,63 @@ const FPS = clock.FPS
 //
 // The returned value represents how many times rendering happens in a second and
 // NOT how many times logical game updating (a passed function to Run) happens.
-// Note that logical game updating is assured to happen 60 times in a second.
+// Note that logical game updating is assured to happen 60 times in a second (see CurrentTPS).
+// With vsync enabled, CurrentFPS is close to the display's refresh rate (see RefreshRate).
 //
 // This function is concurrent-safe.
 func CurrentFPS() float64 {
 	return clock.CurrentFPS()
 }
 
+// CurrentTPS returns the current number of logical game updates (ticks) per second.
+//
+// Unlike CurrentFPS, CurrentTPS doesn't depend on the display's refresh rate: CurrentTPS is about 60
+// unless the game is too slow, even when the rendering happens e.g. 144 times in a second.
+//
+// This function is concurrent-safe.
+func CurrentTPS() float64 {
+	return clock.CurrentTPS()
+}
+
+// RefreshRate returns the refresh rate of the display in Hz.
+//
+// On desktops, RefreshRate returns the refresh rate of the monitor where the window is, which is updated every frame.
+// On browsers, the refresh rate is estimated from the animation frames after the main loop starts.
+// RefreshRate returns 0 when the refresh rate is unknown, e.g. before the main loop starts or on mobiles.
+//
+// The rendering frames are paced with the refresh rate when vsync is enabled: the jitter of the frame times is
+// absorbed so that the logical updates and FrameProgress advance steadily on e.g. 120 Hz or 144 Hz displays.
+//
+// This function is concurrent-safe.
+func RefreshRate() int {
+	return ui.RefreshRate()
+}
+
+// FrameProgress returns the elapsed time since the last logical update in ticks, in [0, 1).
+//
+// The logical updates happen 60 times a second regardless of the display's refresh rate.
+// When the display's refresh rate is different from 60 Hz, e.g. 144 Hz, a frame is rendered between two updates.
+// FrameProgress can be used at the rendering phase to interpolate the states of the last two updates
+// for smooth motion, like:
+//
+//     x := prevX + (currentX - prevX) * ebiten.FrameProgress()
+//
+// Note that this renders the states slightly (less than one tick) behind the latest update.
+//
+// FrameProgress is updated once a frame before the update function is called, so the value is same
+// among the update function calls in one frame. The value is meaningful only for the rendered frame,
+// that is, when IsRunningSlowly is false (or in Game's Draw).
+// When the game is too slow and the updates are behind the clock, FrameProgress is close to 1.
+//
+// With Run, the update function is not called at a frame without logical updates and the last screen is
+// presented as it is. To render such frames with interpolation, use RunGame: game's Draw is called
+// also at such frames.
+//
+// This function is concurrent-safe.
+func FrameProgress() float64 {
+	return clock.FrameProgress()
+}
+
 var (
 	isRunningSlowly = int32(0)
 )
@@ -74,6 +126,9 @@ func setRunningSlowly(slow bool) {
 //        return nil
 //    }
 //
+// When the game is run by RunGame, Game's Draw is not called
+// when IsRunningSlowly is true, and you don't have to call this.
+//
 // This function is concurrent-safe.
 func IsRunningSlowly() bool {
 	return atomic.LoadInt32(&isRunningSlowly) != 0
@@ -81,6 +136,37 @@ func IsRunningSlowly() bool {
 
 var theGraphicsContext atomic.Value
 
+var (
+	// delayedErr is an error that happened where the error can't be returned to the game, e.g. in At.
+	// delayedErr is returned from Run at the end of the current logical update.
+	delayedErr  error
+	delayedErrM sync.Mutex
+)
+
+// setDelayedError records err so that Run returns it at the end of the current logical update.
+// Only the first error is kept.
+//
+// setDelayedError panics with err when the main loop is not running, as there is no way to report err.
+func setDelayedError(err error) {
+	if theGraphicsContext.Load() == nil {
+		panic(err)
+	}
+	delayedErrM.Lock()
+	if delayedErr == nil {
+		delayedErr = err
+	}
+	delayedErrM.Unlock()
+}
+
+// takeDelayedError returns the recorded error and resets it.
+func takeDelayedError() error {
+	delayedErrM.Lock()
+	err := delayedErr
+	delayedErr = nil
+	delayedErrM.Unlock()
+	return err
+}
+
 func run(width, height int, scale float64, title string, g *graphicsContext, mainloop bool) error {
 	if err := ui.Run(width, height, scale, title, g, mainloop); err != nil {
 		if err == ui.RegularTermination {
@@ -174,7 +260,8 @@ func (i *imageDumper) update(screen *Image) error {
 //
 // The given scale is ignored on fullscreen mode or gomobile-build mode.
 //
-// Run returns error when 1) OpenGL error happens, 2) audio error happens or 3) f returns error.
+// Run returns error when 1) OpenGL error happens, 2) audio error happens, 3) f returns error or
+// 4) an error happens in a function that doesn't return errors, e.g. reading pixels by (*Image).At fails.
 // In the case of 3), Run returns the same error.
 //
 // The size unit is device-independent pixel.
@@ -182,12 +269,15 @@ func (i *imageDumper) update(screen *Image) error {
 // Don't call Run twice or more in one process.
 func Run(f func(*Image) error, width, height int, scale float64, title string) error {
 	f = (&imageDumper{f: f}).update
+	return runMainThreadLoop(newGraphicsContext(f), width, height, scale, title)
+}
 
+// runMainThreadLoop runs the game with the graphics context g and the main thread loop.
+func runMainThreadLoop(g *graphicsContext, width, height int, scale float64, title string) error {
 	ch := make(chan error)
 	go func() {
 		defer close(ch)
 
-		g := newGraphicsContext(f)
 		theGraphicsContext.Store(g)
 		if err := run(width, height, scale, title, g, true); err != nil {
 			ch <- err
@@ -201,11 +291,187 @@ func Run(f func(*Image) error, width, height int, scale float64, title string) e
 	return nil
 }
 
+// Game defines necessary functions for a game.
+type Game interface {
+	// Update updates a game by one tick.
+	//
+	// Update is called 60 times a second, as the function passed to Run is.
+	Update() error
+
+	// Draw draws the game screen by one frame.
+	//
+	// Draw is called after Update, but not when the rendering result would not be adopted
+	// (see IsRunningSlowly).
+	//
+	// When the display's refresh rate is higher than 60 Hz, Draw is also called at the frames
+	// without Update. Use FrameProgress to interpolate the states for smooth motion.
+	//
+	// Draw is called whenever the screen is presented, so Draw can be called before the first Update.
+	// The game must be able to draw its initial state.
+	Draw(screen *Image)
+
+	// Layout accepts a native outside size in device-independent pixels and returns the game's logical
+	// screen size.
+	//
+	// The screen is scaled to fit the outside size while keeping the aspect ratio.
+	// If the returned size is changed from the previous one, the screen size is changed accordingly.
+	Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int)
+}
+
+// defaultOutsideWidth and defaultOutsideHeight are the outside size given to Layout
+// before the game window is created.
+const (
+	defaultOutsideWidth  = 640
+	defaultOutsideHeight = 480
+)
+
+// gameRunner adapts a Game to the update function that Run takes.
+type gameRunner struct {
+	game Game
+
+	// screenWidth and screenHeight are the last logical screen size returned by Layout.
+	screenWidth  int
+	screenHeight int
+}
+
+func layoutScale(outsideWidth, outsideHeight, screenWidth, screenHeight int) float64 {
+	sw := float64(outsideWidth) / float64(screenWidth)
+	sh := float64(outsideHeight) / float64(screenHeight)
+	if sw < sh {
+		return sw
+	}
+	return sh
+}
+
+func (g *gameRunner) layout() {
+	ow, oh := ui.OutsideSize()
+	if ow <= 0 || oh <= 0 {
+		// The main loop has not started yet.
+		return
+	}
+	w, h := g.game.Layout(ow, oh)
+	if w <= 0 || h <= 0 {
+		panic("ebiten: Layout must return positive numbers")
+	}
+	if w == g.screenWidth && h == g.screenHeight {
+		return
+	}
+	g.screenWidth = w
+	g.screenHeight = h
+	SetScreenSize(w, h)
+	// On fullscreen mode, the screen is automatically enlarged to fit with the monitor.
+	// Don't change the scale for the window mode in this case.
+	if !IsFullscreen() {
+		SetScreenScale(layoutScale(ow, oh, w, h))
+	}
+}
+
+func (g *gameRunner) update(screen *Image) error {
+	// Layout is called before Update so that Update can rely on the latest outside size.
+	// Note that a changed screen size is applied to the screen image from the next frame.
+	g.layout()
+	if err := g.game.Update(); err != nil {
+		return err
+	}
+	if IsRunningSlowly() {
+		return nil
+	}
+	g.game.Draw(screen)
+	return nil
+}
+
+// draw draws the screen at a frame without updates so that the screen reflects FrameProgress.
+//
+// draw is called even before the first update, so that the first presented frame is not blank.
+func (g *gameRunner) draw(screen *Image) {
+	g.game.Draw(screen)
+}
+
+// RunGame runs the game.
+//
+// RunGame is similar to Run, but the logical update and the rendering are separated:
+// game's Update is called 60 times a second, and game's Draw is called
+// only when the rendering result is adopted.
+//
+// The initial screen size is determined by game's Layout with the default outside size (640x480),
+// and the window is scaled to fit the outside size.
+// After the main loop starts, Layout is called every tick before Update with the actual outside size:
+// the window size on desktops (the monitor size on fullscreen mode), the body element size on browsers,
+// and the view size on mobiles.
+// The window title can be specified by SetWindowTitle before calling RunGame.
+//
+// As Run, RunGame must be called from the OS main thread.
+//
+// RunGame returns error when 1) OpenGL error happens, 2) audio error happens,
+// 3) game's Update returns error or 4) an error happens in a function that doesn't return errors,
+// e.g. reading pixels by (*Image).At fails. In the case of 3), RunGame returns the same error.
+//
+// Don't call RunGame twice or more in one process.
+func RunGame(game Game) error {
+	w, h := game.Layout(defaultOutsideWidth, defaultOutsideHeight)
+	if w <= 0 || h <= 0 {
+		panic("ebiten: Layout must return positive numbers")
+	}
+	g := &gameRunner{
+		game:         game,
+		screenWidth:  w,
+		screenHeight: h,
+	}
+	c := newGraphicsContext((&imageDumper{f: g.update}).update)
+	c.draw = g.draw
+	return runMainThreadLoop(c, w, h, layoutScale(defaultOutsideWidth, defaultOutsideHeight, w, h), "")
+}
+
+// RunOptions represents options for RunGameWithOptions.
+//
+// The zero value represents the default options, which are the same as RunGame's.
+type RunOptions struct {
+	// Borderless indicates whether the window has no decorations like the title bar and the border.
+	// Borderless is the same as SetWindowDecorated(false).
+	//
+	// Borderless works only on desktops.
+	Borderless bool
+
+	// AlwaysOnTop indicates whether the window floats above the other windows.
+	//
+	// AlwaysOnTop works only on desktops.
+	AlwaysOnTop bool
+
+	// Transparent indicates whether the screen is composed with the content behind the window with the alpha values.
+	// The screen is cleared with the transparent color every frame, so the regions the game doesn't draw on are
+	// see-through. This is useful for overlay or widget-style apps together with Borderless.
+	//
+	// Transparent works on Windows with the desktop composition, on macOS and on browsers, where the page background
+	// is transparent instead of black. Transparent doesn't work on Linux and FreeBSD yet.
+	Transparent bool
+}
+
+// RunGameWithOptions runs the game with the options.
+//
+// RunGameWithOptions is the same as RunGame except for the options that must be determined before the window is
+// created. If options is nil, RunGameWithOptions is the same as RunGame.
+//
+// Don't call RunGameWithOptions twice or more in one process.
+func RunGameWithOptions(game Game, options *RunOptions) error {
+	if options != nil {
+		if options.Borderless {
+			ui.SetWindowDecorated(false)
+		}
+		if options.AlwaysOnTop {
+			ui.SetWindowFloating(true)
+		}
+		if options.Transparent {
+			ui.SetScreenTransparent(true)
+		}
+	}
+	return RunGame(game)
+}
+
 // RunWithoutMainLoop runs the game, but don't call the loop on the main (UI) thread.
 // Different from Run, this function returns immediately.
 //
 // Ebiten users should NOT call this function.
-// Instead, functions in github.com/hajimehoshi/ebiten/mobile package calls this.
+// Instead, functions in github.com/hajimehoshi/ebiten/mobile and github.com/hajimehoshi/ebiten/external packages call this.
 func RunWithoutMainLoop(f func(*Image) error, width, height int, scale float64, title string) <-chan error {
 	f = (&imageDumper{f: f}).update
 
@@ -321,6 +587,23 @@ func IsRunnableInBackground() bool {
 	return ui.IsRunnableInBackground()
 }
 
+// IsFocused returns a boolean value indicating whether
+// the game window or the app is focused (in foreground).
+//
+// The game is not updated while it is not focused unless it is runnable in background.
+// If the game runs in background by SetRunnableInBackground,
+// IsFocused is useful to reduce heavy processing or pause audio players
+// while the game is not focused.
+//
+// On mobiles, IsFocused returns true while the app is visible.
+//
+// IsFocused returns false before the main loop starts.
+//
+// This function is concurrent-safe.
+func IsFocused() bool {
+	return ui.IsFocused()
+}
+
 // SetWindowDecorated sets the state if the window is decorated.
 //
 // SetWindowDecorated works only on desktops.
@@ -351,11 +634,180 @@ func IsWindowDecorated() bool {
 //
 // SetRunnableInBackground does nothing on mobiles so far.
 //
+// See also IsFocused.
+//
 // This function is concurrent-safe.
 func SetRunnableInBackground(runnableInBackground bool) {
 	ui.SetRunnableInBackground(runnableInBackground)
 }
 
+// SetVsyncEnabled sets a boolean value indicating whether
+// the game uses the display's vsync.
+//
+// If the given value is true, the game tries to sync the display's refresh rate.
+// If false, the game ignores the display's refresh rate and renders as fast as possible,
+// which is useful e.g. for benchmarks.
+// Note that the game logic (the function passed to Run) is still updated 60 times a second
+// regardless of this setting.
+// The initial value is true.
+//
+// On browsers, a timer is used instead of requestAnimationFrame when vsync is disabled.
+//
+// SetVsyncEnabled does nothing on mobiles so far.
+//
+// This function is concurrent-safe.
+func SetVsyncEnabled(enabled bool) {
+	ui.SetVsyncEnabled(enabled)
+}
+
+// IsVsyncEnabled returns a boolean value indicating whether
+// the game uses the display's vsync.
+//
+// This function is concurrent-safe.
+func IsVsyncEnabled() bool {
+	return ui.IsVsyncEnabled()
+}
+
+var srgbRenderingEnabled = int32(0)
+
+// IsSRGBRenderingEnabled returns a boolean value indicating whether the screen is rendered in the sRGB mode.
+//
+// This function is concurrent-safe.
+func IsSRGBRenderingEnabled() bool {
+	return atomic.LoadInt32(&srgbRenderingEnabled) != 0
+}
+
+// SetSRGBRenderingEnabled sets whether the screen is rendered in the sRGB mode.
+//
+// In the sRGB mode, the screen image passed to the update function is of PixelFormatSRGBA8:
+// alpha blending and scaling on the screen are done in the linear color space.
+// This fixes e.g. dark fringes on antialiased edges. See also PixelFormatSRGBA8.
+//
+// The sRGB mode is disabled by default.
+// If the environment doesn't support sRGB textures, SetSRGBRenderingEnabled has no visible effect.
+//
+// This function is concurrent-safe.
+func SetSRGBRenderingEnabled(enabled bool) {
+	v := int32(0)
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&srgbRenderingEnabled, v)
+}
+
+var screenFilter = int32(ScreenFilterSharpBilinear)
+
+// CurrentScreenFilter returns the current screen filter.
+//
+// This function is concurrent-safe.
+func CurrentScreenFilter() ScreenFilter {
+	return ScreenFilter(atomic.LoadInt32(&screenFilter))
+}
+
+// SetScreenFilter sets the filter to render the screen on the window or the display.
+//
+// The screen filter is applied to the screen after the post effects. This also applies to the windows created by
+// NewWindow. With ScreenFilterInteger, CursorPosition and Touches return the positions on the letterboxed screen.
+//
+// The default screen filter is ScreenFilterSharpBilinear.
+//
+// This function is concurrent-safe.
+func SetScreenFilter(filter ScreenFilter) {
+	atomic.StoreInt32(&screenFilter, int32(filter))
+}
+
+var debugOverlayEnabled = int32(0)
+
+// IsDebugOverlayEnabled returns a boolean value indicating whether the debug overlay is shown.
+//
+// This function is concurrent-safe.
+func IsDebugOverlayEnabled() bool {
+	return atomic.LoadInt32(&debugOverlayEnabled) != 0
+}
+
+// SetDebugOverlayEnabled sets whether the debug overlay is shown.
+//
+// The debug overlay is shown at the upper-left corner of the window, over the screen and the post effects.
+// The overlay shows the current FPS with the display's refresh rate,
+// the current TPS (the number of the logical updates per second),
+// the number of the draw-image requests and the actual draw calls after batching at the last frame,
+// the number of the graphics state changes skipped as redundant at the last frame,
+// the GPU memory for images (see ImageMemoryUsage), and the heap size and the number of GCs.
+// The memory statistics are updated once a second.
+//
+// The debug overlay is disabled by default.
+//
+// This function is concurrent-safe.
+func SetDebugOverlayEnabled(enabled bool) {
+	v := int32(0)
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&debugOverlayEnabled, v)
+}
+
+var powerSavingEnabled = int32(0)
+
+// IsPowerSavingEnabled reports whether the power saving mode is enabled.
+//
+// This function is concurrent-safe.
+func IsPowerSavingEnabled() bool {
+	return atomic.LoadInt32(&powerSavingEnabled) != 0
+}
+
+// SetPowerSavingEnabled sets whether the power saving mode is enabled.
+//
+// In the power saving mode, the screen is not cleared at the beginning of a frame and keeps the contents of the last
+// frame. A frame where nothing is drawn on the screen is regarded as unchanged, and its rendering is skipped:
+// the post effects and the rendering to the screen framebuffer are skipped, and on desktops and browsers the frame
+// is not presented and the main loop just waits for the next frame. This reduces the power consumption of
+// mostly static games like puzzle or card games. To declare a frame unchanged, don't draw anything on the screen
+// at the frame.
+//
+// As the screen keeps the last contents, the game must clear the screen by itself when needed.
+// The screen's contents are lost when the screen size is changed or the graphics context is lost;
+// redraw the whole screen after Layout returns a different size.
+//
+// Frames are always rendered while the debug overlay is shown or the screen is recorded.
+// On mobiles, the unchanged frames are still presented from the last contents, as the views require
+// presenting every frame.
+//
+// The power saving mode is disabled by default.
+//
+// This function is concurrent-safe.
+func SetPowerSavingEnabled(enabled bool) {
+	v := int32(0)
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&powerSavingEnabled, v)
+}
+
+// IsDeterministicModeEnabled reports whether the deterministic mode is enabled.
+//
+// This function is concurrent-safe.
+func IsDeterministicModeEnabled() bool {
+	return clock.IsDeterministic()
+}
+
+// SetDeterministicModeEnabled sets whether the deterministic mode is enabled.
+//
+// In the deterministic mode, the logical updates (ticks) strictly follow the system clock at 60 TPS, which is
+// required for lockstep networking where all the peers must advance the simulation at the same pace:
+// the audio clock is not used to adjust the ticks, and the ticks are never dropped when the game is too slow or
+// is not updated for a while, e.g. in background. The delayed ticks are caught up gradually, at most 5 ticks a frame.
+//
+// Note that the timing of the ticks never affects the results of the updates in Ebiten: the update function is
+// called once a tick, and the input state doesn't change within a frame. For a deterministic simulation,
+// see also the package github.com/hajimehoshi/ebiten/deterministic.
+//
+// The deterministic mode is disabled by default.
+//
+// This function is concurrent-safe.
+func SetDeterministicModeEnabled(enabled bool) {
+	clock.SetDeterministic(enabled)
+}
+
 // SetWindowIcon sets the icon of the game window.
 //
 // If len(iconImages) is 0, SetWindowIcon reverts the icon to the default one.
@@ -380,6 +832,109 @@ func SetWindowIcon(iconImages []image.Image) {
 	ui.SetWindowIcon(iconImages)
 }
 
+// SetWindowSizeLimits sets the minimum and maximum size of the window in device-independent pixels.
+//
+// A negative value means no limit. By default, the window has no limits.
+//
+// The window size is the screen size multiplied by the screen scale. When the window would be out of the limits
+// by SetScreenSize, SetScreenScale or Run, the screen scale is adjusted so that the window fits with the limits.
+// When the minimum and the maximum conflict, the minimum has priority.
+// The limits are also given to the window system, so that the window can't be resized out of the limits.
+//
+// SetWindowSizeLimits panics when a maximum is less than the corresponding minimum.
+//
+// SetWindowSizeLimits doesn't affect fullscreen mode.
+// SetWindowSizeLimits does nothing on browsers or mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowSizeLimits(minWidth, minHeight, maxWidth, maxHeight int) {
+	if minWidth >= 0 && maxWidth >= 0 && maxWidth < minWidth {
+		panic("ebiten: maxWidth must be equal to or greater than minWidth")
+	}
+	if minHeight >= 0 && maxHeight >= 0 && maxHeight < minHeight {
+		panic("ebiten: maxHeight must be equal to or greater than minHeight")
+	}
+	ui.SetWindowSizeLimits(minWidth, minHeight, maxWidth, maxHeight)
+}
+
+// WindowSizeLimits returns the window size limits set by SetWindowSizeLimits.
+//
+// WindowSizeLimits always returns -1s on browsers or mobiles.
+//
+// This function is concurrent-safe.
+func WindowSizeLimits() (minWidth, minHeight, maxWidth, maxHeight int) {
+	return ui.WindowSizeLimits()
+}
+
+// SetWindowAspectRatioLocked sets whether the aspect ratio of the window is locked to the current one.
+//
+// When the aspect ratio is locked, the window system keeps the ratio of the window when the window is resized.
+// The ratio follows the screen when the screen size is changed by SetScreenSize.
+//
+// The aspect ratio is not locked by default.
+//
+// SetWindowAspectRatioLocked doesn't affect fullscreen mode.
+// SetWindowAspectRatioLocked does nothing on browsers or mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowAspectRatioLocked(locked bool) {
+	ui.SetWindowAspectRatioLocked(locked)
+}
+
+// IsWindowAspectRatioLocked reports whether the aspect ratio of the window is locked.
+//
+// IsWindowAspectRatioLocked always returns false on browsers or mobiles.
+//
+// This function is concurrent-safe.
+func IsWindowAspectRatioLocked() bool {
+	return ui.IsWindowAspectRatioLocked()
+}
+
+// RequestWindowAttention requests the user's attention to the window, e.g., when a match is found while the window
+// is in the background.
+//
+// On Windows, the taskbar button flashes until the window is activated.
+// On macOS, the dock icon bounces once.
+// On Linux and FreeBSD, the window is marked as demanding attention, which the window manager usually shows by
+// highlighting the taskbar entry.
+//
+// RequestWindowAttention does nothing before Run, on browsers or on mobiles.
+//
+// This function is concurrent-safe.
+func RequestWindowAttention() {
+	ui.RequestWindowAttention()
+}
+
+// SetWindowProgress sets the progress shown on the taskbar button or the dock icon, e.g., for loading assets.
+//
+// progress is in [0, 1], and a greater value is treated as 1. A negative progress or NaN hides the progress.
+//
+// The progress is shown on the taskbar button on Windows and on the dock icon on macOS.
+// SetWindowProgress is not supported on Linux and FreeBSD since X11 has no standard way to show a progress.
+// SetWindowProgress does nothing there, before Run, on browsers or on mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowProgress(progress float64) {
+	if math.IsNaN(progress) {
+		progress = -1
+	}
+	if progress > 1 {
+		progress = 1
+	}
+	ui.SetWindowProgress(progress)
+}
+
+// SetWindowTitle sets the title of the window.
+//
+// If the title is given to Run, the title overrides the one set by SetWindowTitle before Run.
+//
+// SetWindowTitle does nothing on mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowTitle(title string) {
+	ui.SetWindowTitle(title)
+}
+
 // DeviceScaleFactor returns a device scale factor value.
 //
 // DeviceScaleFactor returns a meaningful value on high-DPI display environment,
@@ -389,3 +944,20 @@ func SetWindowIcon(iconImages []image.Image) {
 func DeviceScaleFactor() float64 {
 	return devicescale.DeviceScale()
 }
+
+// SimulateContextLoss simulates a loss of the graphics context.
+//
+// At the beginning of the next frame, all the images are restored from their recorded states
+// in the same way as when the graphics context is actually lost, e.g., when an Android app goes background.
+// SimulateContextLoss is useful to test that the game is rendered correctly after restoring
+// without suspending a device.
+// The lifecycle handlers' Restore are also called after restoring (see RegisterLifecycleHandler).
+//
+// Restoring is disabled on desktops since OpenGL never loses the context there,
+// and SimulateContextLoss does nothing in this case.
+// Run the game with the environment variable EBITEN_FORCE_RESTORING=1 to enable restoring on desktops.
+//
+// This function is concurrent-safe.
+func SimulateContextLoss() {
+	atomic.StoreInt32(&contextLossRequested, 1)
+}
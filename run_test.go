@@ -0,0 +1,167 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"errors"
+	"testing"
+)
+
+type testGame struct {
+	screenWidth  int
+	screenHeight int
+
+	outsideWidth  int
+	outsideHeight int
+	updateCount   int
+	drawCount     int
+}
+
+func (g *testGame) Update() error {
+	g.updateCount++
+	return nil
+}
+
+func (g *testGame) Draw(screen *Image) {
+	g.drawCount++
+}
+
+func (g *testGame) Layout(outsideWidth, outsideHeight int) (int, int) {
+	g.outsideWidth = outsideWidth
+	g.outsideHeight = outsideHeight
+	return g.screenWidth, g.screenHeight
+}
+
+func TestLayoutScale(t *testing.T) {
+	cases := []struct {
+		OutsideWidth  int
+		OutsideHeight int
+		ScreenWidth   int
+		ScreenHeight  int
+		Scale         float64
+	}{
+		{640, 480, 320, 240, 2},
+		{640, 480, 320, 120, 2},
+		{640, 480, 160, 240, 2},
+		{320, 240, 640, 480, 0.5},
+		{300, 400, 100, 100, 3},
+	}
+	for _, c := range cases {
+		got := layoutScale(c.OutsideWidth, c.OutsideHeight, c.ScreenWidth, c.ScreenHeight)
+		want := c.Scale
+		if got != want {
+			t.Errorf("layoutScale(%d, %d, %d, %d): got: %f, want: %f", c.OutsideWidth, c.OutsideHeight, c.ScreenWidth, c.ScreenHeight, got, want)
+		}
+	}
+}
+
+func TestGameRunnerDrawingSkipped(t *testing.T) {
+	screen, _ := NewImage(16, 16, FilterDefault)
+	// Layout returns the same size as the runner's, so that the screen size is not changed.
+	g := &testGame{screenWidth: 320, screenHeight: 240}
+	r := &gameRunner{game: g, screenWidth: 320, screenHeight: 240}
+
+	setRunningSlowly(true)
+	defer setRunningSlowly(false)
+	if err := r.update(screen); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := g.updateCount, 1; got != want {
+		t.Errorf("updateCount: got: %d, want: %d", got, want)
+	}
+	if got, want := g.drawCount, 0; got != want {
+		t.Errorf("drawCount: got: %d, want: %d", got, want)
+	}
+
+	setRunningSlowly(false)
+	if err := r.update(screen); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := g.updateCount, 2; got != want {
+		t.Errorf("updateCount: got: %d, want: %d", got, want)
+	}
+	if got, want := g.drawCount, 1; got != want {
+		t.Errorf("drawCount: got: %d, want: %d", got, want)
+	}
+}
+
+func TestGameRunnerDrawWithoutUpdate(t *testing.T) {
+	screen, _ := NewImage(16, 16, FilterDefault)
+	g := &testGame{screenWidth: 320, screenHeight: 240}
+	r := &gameRunner{game: g, screenWidth: 320, screenHeight: 240}
+
+	// Draw is called even before the first Update so that the first frame is not blank.
+	r.draw(screen)
+	if got, want := g.drawCount, 1; got != want {
+		t.Errorf("drawCount: got: %d, want: %d", got, want)
+	}
+
+	if err := r.update(screen); err != nil {
+		t.Fatal(err)
+	}
+	r.draw(screen)
+	if got, want := g.updateCount, 1; got != want {
+		t.Errorf("updateCount: got: %d, want: %d", got, want)
+	}
+	if got, want := g.drawCount, 3; got != want {
+		t.Errorf("drawCount: got: %d, want: %d", got, want)
+	}
+}
+
+func TestGameRunnerLayout(t *testing.T) {
+	origScale := ScreenScale()
+	defer func() {
+		// Restore the screen for the other tests.
+		SetScreenSize(320, 240)
+		SetScreenScale(origScale)
+	}()
+
+	screen, _ := NewImage(16, 16, FilterDefault)
+	g := &testGame{screenWidth: 160, screenHeight: 120}
+	r := &gameRunner{game: g, screenWidth: 320, screenHeight: 240}
+	if err := r.update(screen); err != nil {
+		t.Fatal(err)
+	}
+	ow, oh := g.outsideWidth, g.outsideHeight
+	if ow <= 0 || oh <= 0 {
+		t.Fatalf("outside size: got: (%d, %d), want: positive numbers", ow, oh)
+	}
+	if got, want := r.screenWidth, 160; got != want {
+		t.Errorf("screen width: got: %d, want: %d", got, want)
+	}
+	if got, want := r.screenHeight, 120; got != want {
+		t.Errorf("screen height: got: %d, want: %d", got, want)
+	}
+	if !IsFullscreen() {
+		if got, want := ScreenScale(), layoutScale(ow, oh, 160, 120); got != want {
+			t.Errorf("screen scale: got: %f, want: %f", got, want)
+		}
+	}
+}
+
+func TestDelayedError(t *testing.T) {
+	err1 := errors.New("error 1")
+	err2 := errors.New("error 2")
+
+	// Only the first error is kept until it is taken.
+	setDelayedError(err1)
+	setDelayedError(err2)
+	if got, want := takeDelayedError(), err1; got != want {
+		t.Errorf("takeDelayedError(): got: %v, want: %v", got, want)
+	}
+	if got := takeDelayedError(); got != nil {
+		t.Errorf("takeDelayedError() after taking: got: %v, want: nil", got)
+	}
+}
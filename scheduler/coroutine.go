@@ -0,0 +1,121 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"runtime"
+)
+
+// Coroutine represents a function that runs across ticks, e.g. a cutscene.
+//
+// A coroutine runs on its own goroutine, but only while the scheduler waits for it to yield by Wait or to return.
+// Thus, a coroutine never runs in parallel with the game and the other coroutines.
+type Coroutine struct {
+	s      *Scheduler
+	resume chan bool
+	yield  chan struct{}
+	timer  *Timer
+	done   bool
+
+	// panicked is the value recovered from a panic in the coroutine.
+	panicked interface{}
+}
+
+// Go starts fn as a coroutine. fn runs immediately until it calls Wait or returns.
+//
+// If fn panics, the panic is propagated to the caller of Go or Update that resumed fn.
+func (s *Scheduler) Go(fn func(c *Coroutine)) *Coroutine {
+	c := &Coroutine{
+		s:      s,
+		resume: make(chan bool),
+		yield:  make(chan struct{}),
+	}
+	go func() {
+		defer func() {
+			c.panicked = recover()
+			c.done = true
+			c.yield <- struct{}{}
+		}()
+		if !<-c.resume {
+			return
+		}
+		fn(c)
+	}()
+	c.step(true)
+	return c
+}
+
+// step resumes the coroutine and waits for it to yield.
+// If cont is false, the coroutine exits instead of continuing.
+func (c *Coroutine) step(cont bool) {
+	c.resume <- cont
+	<-c.yield
+	if c.done && c.panicked != nil {
+		panic(c.panicked)
+	}
+}
+
+// Wait suspends the coroutine for n ticks. If n is less than 1, the coroutine is resumed at the next tick.
+//
+// Wait must be called on the coroutine's goroutine.
+func (c *Coroutine) Wait(n int) {
+	c.timer = c.s.After(n, func() {
+		c.timer = nil
+		c.step(true)
+	})
+	c.suspend()
+}
+
+// WaitUntil suspends the coroutine until cond returns true. cond is checked every tick from the next tick.
+//
+// WaitUntil must be called on the coroutine's goroutine.
+func (c *Coroutine) WaitUntil(cond func() bool) {
+	c.timer = c.s.Every(1, func() {
+		if !cond() {
+			return
+		}
+		c.timer.Stop()
+		c.timer = nil
+		c.step(true)
+	})
+	c.suspend()
+}
+
+func (c *Coroutine) suspend() {
+	c.yield <- struct{}{}
+	if !<-c.resume {
+		// Exit the goroutine running the deferred functions.
+		runtime.Goexit()
+	}
+}
+
+// IsDone reports whether the coroutine has returned or been stopped.
+func (c *Coroutine) IsDone() bool {
+	return c.done
+}
+
+// Stop stops the coroutine. The suspended coroutine exits at Wait or WaitUntil, running its deferred functions.
+//
+// Stop must not be called on the coroutine's goroutine. Stop does nothing if the coroutine is done.
+func (c *Coroutine) Stop() {
+	if c.done {
+		return
+	}
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.step(false)
+}
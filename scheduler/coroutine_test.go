@@ -0,0 +1,95 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCoroutine(t *testing.T) {
+	s := &Scheduler{}
+	var log []string
+	record := func(name string) {
+		log = append(log, fmt.Sprintf("%d:%s", s.Ticks(), name))
+	}
+	flag := false
+	c := s.Go(func(c *Coroutine) {
+		record("start")
+		c.Wait(2)
+		record("waited")
+		c.WaitUntil(func() bool {
+			return flag
+		})
+		record("flagged")
+	})
+	s.After(4, func() {
+		flag = true
+	})
+
+	for i := 0; i < 4; i++ {
+		if c.IsDone() {
+			t.Fatalf("IsDone() at tick %d: got: true, want: false", s.Ticks())
+		}
+		s.Update()
+	}
+	if !c.IsDone() {
+		t.Errorf("IsDone(): got: false, want: true")
+	}
+	// The flag is set at tick 4 before the condition is checked, as the timer was scheduled earlier.
+	want := []string{"0:start", "2:waited", "4:flagged"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("got: %v, want: %v", log, want)
+	}
+}
+
+func TestCoroutineStop(t *testing.T) {
+	s := &Scheduler{}
+	deferred := false
+	resumed := false
+	c := s.Go(func(c *Coroutine) {
+		defer func() {
+			deferred = true
+		}()
+		c.Wait(1)
+		resumed = true
+	})
+	c.Stop()
+	s.Update()
+	if !c.IsDone() {
+		t.Errorf("IsDone(): got: false, want: true")
+	}
+	if !deferred {
+		t.Errorf("the deferred function must run")
+	}
+	if resumed {
+		t.Errorf("the stopped coroutine must not be resumed")
+	}
+}
+
+func TestCoroutinePanic(t *testing.T) {
+	s := &Scheduler{}
+	s.Go(func(c *Coroutine) {
+		c.Wait(1)
+		panic("foo")
+	})
+	defer func() {
+		if r := recover(); r != "foo" {
+			t.Errorf("recover(): got: %v, want: foo", r)
+		}
+	}()
+	s.Update()
+}
@@ -0,0 +1,178 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scheduler provides timers and coroutines that run in game ticks.
+//
+// A Scheduler is advanced by Update, which is supposed to be called once in every game update.
+// As the time is counted in ticks instead of the wall clock, timed logic like cutscenes is deterministic.
+//
+// Note: This package is experimental and API might be changed.
+package scheduler
+
+import (
+	"sort"
+)
+
+// Scheduler runs timers and coroutines in ticks.
+//
+// The functions of a Scheduler must be called on the same goroutine as Update, e.g. in the update function.
+type Scheduler struct {
+	tick int
+
+	// seq is the serial number of the timers to run the timers of the same tick in the scheduled order.
+	seq int
+
+	// timers are the pending timers sorted by the due ticks and the serial numbers.
+	timers []*Timer
+}
+
+// Timer represents a function scheduled by a Scheduler.
+type Timer struct {
+	s        *Scheduler
+	due      int
+	seq      int
+	interval int
+	fn       func()
+	stopped  bool
+}
+
+// Ticks returns the number of the ticks advanced by Update.
+func (s *Scheduler) Ticks() int {
+	return s.tick
+}
+
+// Update advances the scheduler by one tick, and runs the timers due at the tick in the scheduled order.
+//
+// The timers scheduled while Update runs are not run until the next tick.
+func (s *Scheduler) Update() {
+	s.tick++
+	for len(s.timers) > 0 && s.timers[0].due <= s.tick {
+		t := s.timers[0]
+		s.timers = s.timers[1:]
+		if t.interval > 0 {
+			t.due += t.interval
+			s.add(t)
+		}
+		t.fn()
+	}
+}
+
+func (s *Scheduler) add(t *Timer) {
+	t.seq = s.seq
+	s.seq++
+	i := sort.Search(len(s.timers), func(i int) bool {
+		u := s.timers[i]
+		if u.due != t.due {
+			return u.due > t.due
+		}
+		return u.seq > t.seq
+	})
+	s.timers = append(s.timers, nil)
+	copy(s.timers[i+1:], s.timers[i:])
+	s.timers[i] = t
+}
+
+func (s *Scheduler) schedule(n int, interval int, fn func()) *Timer {
+	if n < 1 {
+		n = 1
+	}
+	t := &Timer{
+		s:        s,
+		due:      s.tick + n,
+		interval: interval,
+		fn:       fn,
+	}
+	s.add(t)
+	return t
+}
+
+// After runs fn once after n ticks. If n is less than 1, fn runs at the next tick.
+func (s *Scheduler) After(n int, fn func()) *Timer {
+	return s.schedule(n, 0, fn)
+}
+
+// Every runs fn every n ticks until the timer is stopped. The first run is after n ticks.
+//
+// If n is less than 1, Every panics.
+func (s *Scheduler) Every(n int, fn func()) *Timer {
+	if n < 1 {
+		panic("scheduler: n must be positive")
+	}
+	return s.schedule(n, n, fn)
+}
+
+// Tween runs fn every tick for n ticks with the value of ease at the progress in (0, 1].
+// The last value is ease(1). If ease is nil, Linear is used.
+//
+// If n is less than 1, Tween panics.
+func (s *Scheduler) Tween(n int, ease Ease, fn func(v float64)) *Timer {
+	if n < 1 {
+		panic("scheduler: n must be positive")
+	}
+	if ease == nil {
+		ease = Linear
+	}
+	i := 0
+	var t *Timer
+	t = s.Every(1, func() {
+		i++
+		if i == n {
+			t.Stop()
+		}
+		fn(ease(float64(i) / float64(n)))
+	})
+	return t
+}
+
+// Stop stops the timer. Stop does nothing if the timer has already run or been stopped.
+func (t *Timer) Stop() {
+	if t.stopped {
+		return
+	}
+	t.stopped = true
+	for i, u := range t.s.timers {
+		if u == t {
+			t.s.timers = append(t.s.timers[:i], t.s.timers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Ease is an easing function that maps the progress t in [0, 1] to a value, typically in [0, 1].
+//
+// See the package tween for more easing functions.
+type Ease func(t float64) float64
+
+// Linear is the linear easing function.
+func Linear(t float64) float64 {
+	return t
+}
+
+// EaseInQuad is the quadratic easing function that starts slowly.
+func EaseInQuad(t float64) float64 {
+	return t * t
+}
+
+// EaseOutQuad is the quadratic easing function that ends slowly.
+func EaseOutQuad(t float64) float64 {
+	return t * (2 - t)
+}
+
+// EaseInOutQuad is the quadratic easing function that starts and ends slowly.
+func EaseInOutQuad(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSchedulerTimers(t *testing.T) {
+	s := &Scheduler{}
+	var log []string
+	record := func(name string) func() {
+		return func() {
+			log = append(log, fmt.Sprintf("%d:%s", s.Ticks(), name))
+		}
+	}
+	s.After(2, record("b"))
+	s.After(0, record("a"))
+	every := s.Every(2, record("every"))
+	// The timers of the same tick run in the scheduled order.
+	s.After(2, record("c"))
+	stopped := s.After(1, record("stopped"))
+	stopped.Stop()
+	s.After(3, func() {
+		record("d")()
+		// A timer scheduled in Update runs at the next tick at the earliest.
+		s.After(0, record("e"))
+		every.Stop()
+	})
+
+	for i := 0; i < 6; i++ {
+		s.Update()
+	}
+	want := []string{"1:a", "2:b", "2:every", "2:c", "3:d", "4:e"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("got: %v, want: %v", log, want)
+	}
+}
+
+func TestSchedulerTween(t *testing.T) {
+	s := &Scheduler{}
+	var vs []float64
+	s.Tween(4, EaseInQuad, func(v float64) {
+		vs = append(vs, v)
+	})
+	for i := 0; i < 6; i++ {
+		s.Update()
+	}
+	want := []float64{1.0 / 16, 4.0 / 16, 9.0 / 16, 1}
+	if !reflect.DeepEqual(vs, want) {
+		t.Errorf("got: %v, want: %v", vs, want)
+	}
+}
+
+func TestEase(t *testing.T) {
+	for _, e := range []struct {
+		Name string
+		Ease Ease
+	}{
+		{"Linear", Linear},
+		{"EaseInQuad", EaseInQuad},
+		{"EaseOutQuad", EaseOutQuad},
+		{"EaseInOutQuad", EaseInOutQuad},
+	} {
+		if got := e.Ease(0); got != 0 {
+			t.Errorf("%s(0): got: %v, want: 0", e.Name, got)
+		}
+		if got := e.Ease(1); got != 1 {
+			t.Errorf("%s(1): got: %v, want: 1", e.Name, got)
+		}
+	}
+	if got, want := EaseInOutQuad(0.5), 0.5; got != want {
+		t.Errorf("EaseInOutQuad(0.5): got: %v, want: %v", got, want)
+	}
+}
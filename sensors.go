@@ -0,0 +1,59 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/input"
+)
+
+// Acceleration returns the acceleration of the device including the gravity in m/s².
+//
+// The axes are in the device coordinates: x toward the right of the screen, y toward the top of the screen
+// and z out of the screen. For example, Acceleration returns about (0, 0, 9.8) when the device lies face up.
+// Note that the axes don't rotate with the screen orientation.
+//
+// ok is false when the accelerometer is not available or no value has been reported yet.
+//
+// The motion sensors are available on browsers and on mobiles with the views generated by ebitenmobile.
+// Some browsers like Safari on iOS require the user's permission, which Ebiten requests at the first tap or click.
+// The motion sensors are not available on desktops.
+//
+// This function is concurrent-safe.
+func Acceleration() (x, y, z float64, ok bool) {
+	return input.GetSensors().Acceleration()
+}
+
+// RotationRate returns the rotation rate of the device around the axes in radians per second.
+//
+// The axes are the same as Acceleration.
+// ok is false when the gyroscope is not available or no value has been reported yet.
+//
+// This function is concurrent-safe.
+func RotationRate() (x, y, z float64, ok bool) {
+	return input.GetSensors().RotationRate()
+}
+
+// DeviceOrientation returns the orientation of the device in radians.
+//
+// The angles are as the W3C DeviceOrientation Event: alpha is the rotation around z axis [0, 2π),
+// beta is the rotation around x axis [-π, π) and gamma is the rotation around y axis [-π/2, π/2).
+// A device lying face up has beta and gamma of 0. For tilt controls, gamma and beta are usually enough.
+//
+// ok is false when the orientation sensor is not available or no value has been reported yet.
+//
+// This function is concurrent-safe.
+func DeviceOrientation() (alpha, beta, gamma float64, ok bool) {
+	return input.GetSensors().Orientation()
+}
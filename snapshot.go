@@ -0,0 +1,93 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+)
+
+// ImageSnapshot is a snapshot of the pixels of an image taken by (*Image).Snapshot.
+//
+// An ImageSnapshot is immutable, and can be restored any number of times.
+type ImageSnapshot struct {
+	width  int
+	height int
+	format PixelFormat
+	pixels []byte
+}
+
+// Size returns the size of the image when the snapshot was taken.
+func (s *ImageSnapshot) Size() (width, height int) {
+	return s.width, s.height
+}
+
+// Snapshot takes a snapshot of the pixels of the image so that they can be restored later by RestoreSnapshot.
+//
+// Snapshot is useful to rewind an image that is drawn persistently over frames, e.g. a canvas that a game
+// paints on, along with the game state for rollback netcode.
+//
+// Snapshot is fast when the image is not modified since the last Snapshot or RestoreSnapshot:
+// the snapshots share the pixels kept on the CPU side to restore the image when the context is lost,
+// and no pixels are read from GPU. Otherwise, Snapshot loads the pixels from GPU like At.
+//
+// Snapshot returns nil if the image is disposed.
+//
+// Snapshot panics for an image of a compressed format.
+func (i *Image) Snapshot() (*ImageSnapshot, error) {
+	if i.isDisposed() {
+		return nil, nil
+	}
+	if i.Format().isCompressed() {
+		panic("ebiten: the pixels of an image of a compressed format can't be read")
+	}
+	p, err := i.shareableImage.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	w, h := i.Size()
+	return &ImageSnapshot{
+		width:  w,
+		height: h,
+		format: i.Format(),
+		pixels: p,
+	}, nil
+}
+
+// RestoreSnapshot replaces the pixels of the image with the snapshot s.
+//
+// Restoring a snapshot doesn't copy the pixels on the CPU side, so restoring is as fast as uploading the pixels to GPU.
+//
+// RestoreSnapshot panics when the size or the format of s doesn't match with the image.
+// When the format of the image is a compressed format, RestoreSnapshot panics.
+//
+// When the image is disposed, RestoreSnapshot does nothing.
+//
+// RestoreSnapshot always returns nil.
+func (i *Image) RestoreSnapshot(s *ImageSnapshot) error {
+	i.copyCheck()
+	if i.isDisposed() {
+		return nil
+	}
+	if i.Format().isCompressed() {
+		panic("ebiten: the pixels of an image of a compressed format can't be replaced")
+	}
+	w, h := i.Size()
+	if s.width != w || s.height != h || s.format != i.Format() {
+		panic(fmt.Sprintf("ebiten: the snapshot (%d, %d) of the format %d doesn't match with the image (%d, %d) of the format %d", s.width, s.height, s.format, w, h, i.Format()))
+	}
+	i.modified = true
+	i.shareableImage.RestoreSnapshot(s.pixels)
+	return nil
+}
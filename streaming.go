@@ -0,0 +1,429 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ebiten
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hajimehoshi/ebiten/internal/graphicsutil"
+	"github.com/hajimehoshi/ebiten/internal/logger"
+)
+
+// StreamableImage represents an image whose pixels are kept on RAM or loaded on demand, and which is on GPU
+// only while it is used.
+//
+// StreamableImage is useful for games with more art than GPU memory. A streamable image is uploaded to GPU
+// when it is drawn by DrawStreamableImage, and is evicted from GPU in the least-recently-used order when the
+// streamable images exceed the budget (see SetStreamingBudget). Images of lower priorities are evicted first.
+// When a streamable image can't be on GPU at drawing, e.g. when its pixels are being loaded, the placeholder
+// is drawn instead (see SetStreamingPlaceholder).
+//
+// A streamable image can't be a render target, and its pixels can't be replaced.
+//
+// StreamableImage implements image.Image.
+type StreamableImage struct {
+	width    int
+	height   int
+	filter   Filter
+	priority int
+
+	// pixels is the pixels on RAM. pixels is nil if the pixels are loaded by load.
+	pixels []byte
+	load   func() (image.Image, error)
+
+	// image is the image on GPU. nil means the image is evicted.
+	image *Image
+
+	// lastUsed is the frame when the image is drawn last.
+	lastUsed int64
+
+	disposed bool
+
+	// loading, loaded and loadErr are the state of loading the pixels. They are protected by m.
+	loading bool
+	loaded  []byte
+	loadErr error
+	m       sync.Mutex
+}
+
+// NewStreamableImageFromImage creates a streamable image whose pixels are kept on RAM.
+//
+// Error returned by NewStreamableImageFromImage is always nil.
+func NewStreamableImageFromImage(source image.Image, filter Filter) (*StreamableImage, error) {
+	size := source.Bounds().Size()
+	return &StreamableImage{
+		width:  size.X,
+		height: size.Y,
+		filter: filter,
+		pixels: graphicsutil.CopyImage(source),
+	}, nil
+}
+
+// NewStreamableImage creates a streamable image whose pixels are loaded by load, e.g. from a file.
+//
+// load is called on another goroutine when the image needs to be on GPU, and the loaded pixels are discarded
+// when the image is evicted. The size of the image returned by load must be (width, height).
+// If load returns an error, the error is reported to the logger (see SetLogger) and the image is never loaded.
+//
+// If width or height is less than 1, NewStreamableImage panics.
+//
+// Error returned by NewStreamableImage is always nil.
+func NewStreamableImage(width, height int, load func() (image.Image, error), filter Filter) (*StreamableImage, error) {
+	if width < 1 || height < 1 {
+		panic("ebiten: width and height must be more than 0")
+	}
+	return &StreamableImage{
+		width:  width,
+		height: height,
+		filter: filter,
+		load:   load,
+	}, nil
+}
+
+// Size returns the size of the image.
+func (s *StreamableImage) Size() (width, height int) {
+	return s.width, s.height
+}
+
+// Bounds returns the bounds of the image.
+func (s *StreamableImage) Bounds() image.Rectangle {
+	return image.Rect(0, 0, s.width, s.height)
+}
+
+// ColorModel returns the color model of the image.
+func (s *StreamableImage) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// At returns the color of the image at (x, y).
+//
+// At returns a transparent color when the pixels are neither on GPU nor on RAM.
+// When the image is on GPU, At has the same restrictions as Image.At.
+func (s *StreamableImage) At(x, y int) color.Color {
+	if s.disposed || !image.Pt(x, y).In(s.Bounds()) {
+		return color.RGBA{}
+	}
+	if s.pixels != nil {
+		idx := 4 * (x + y*s.width)
+		return color.RGBA{s.pixels[idx], s.pixels[idx+1], s.pixels[idx+2], s.pixels[idx+3]}
+	}
+	if s.image != nil {
+		return s.image.At(x, y)
+	}
+	return color.RGBA{}
+}
+
+// Priority returns the priority of the image. The default priority is 0.
+func (s *StreamableImage) Priority() int {
+	return s.priority
+}
+
+// SetPriority sets the priority of the image.
+//
+// When the streamable images exceed the budget, the images of lower priorities are evicted first.
+func (s *StreamableImage) SetPriority(priority int) {
+	s.priority = priority
+}
+
+// IsResident reports whether the image is on GPU.
+func (s *StreamableImage) IsResident() bool {
+	return s.image != nil
+}
+
+// Dispose disposes the image data.
+//
+// After disposing, DrawStreamableImage with the image panics.
+//
+// Dispose always returns nil.
+func (s *StreamableImage) Dispose() error {
+	if s.disposed {
+		return nil
+	}
+	theStreamer.evict(s)
+	s.pixels = nil
+	s.disposed = true
+	return nil
+}
+
+func (s *StreamableImage) sizeInBytes() int64 {
+	return 4 * int64(s.width) * int64(s.height)
+}
+
+// takePixels returns the pixels to upload, or nil if the pixels are not available yet.
+// If the pixels are loaded by load, takePixels starts loading if needed.
+func (s *StreamableImage) takePixels() []byte {
+	if s.pixels != nil {
+		return s.pixels
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.loaded != nil {
+		p := s.loaded
+		s.loaded = nil
+		return p
+	}
+	if s.loading || s.loadErr != nil {
+		return nil
+	}
+	s.loading = true
+	go func() {
+		var p []byte
+		img, err := s.load()
+		if err == nil {
+			if size := img.Bounds().Size(); size.X != s.width || size.Y != s.height {
+				err = errors.New("ebiten: the size of the loaded image doesn't match with the streamable image")
+			} else {
+				p = graphicsutil.CopyImage(img)
+			}
+		}
+		if err != nil {
+			logger.Warn("ebiten: loading a streamable image failed", "error", err)
+		}
+		s.m.Lock()
+		s.loading = false
+		s.loaded = p
+		s.loadErr = err
+		s.m.Unlock()
+	}()
+	return nil
+}
+
+// resident returns the image on GPU, uploading the pixels if needed.
+// resident returns nil if the image can't be on GPU now.
+func (s *StreamableImage) resident() *Image {
+	s.lastUsed = theStreamer.frame
+	if s.image != nil {
+		return s.image
+	}
+	p := s.takePixels()
+	if p == nil {
+		return nil
+	}
+	if !theStreamer.reserve(s) {
+		if s.pixels == nil {
+			// Keep the loaded pixels for the next time.
+			s.m.Lock()
+			s.loaded = p
+			s.m.Unlock()
+		}
+		return nil
+	}
+	img, _ := NewImage(s.width, s.height, s.filter)
+	// The image is managed by the streamer.
+	theImages.remove(img)
+	_ = img.ReplacePixels(p)
+	s.image = img
+	return img
+}
+
+// DrawStreamableImage draws the given streamable image on the image i.
+//
+// DrawStreamableImage works in the same way as DrawImage. If img can't be on GPU, e.g. when its pixels are being
+// loaded or the budget is not enough, the placeholder is drawn instead scaled to the source region, and the
+// function set by SetStreamingBudget is called.
+// options.ImageParts and options.Parts are ignored.
+//
+// When the image i is disposed, DrawStreamableImage does nothing.
+// When the given image img is disposed, DrawStreamableImage panics.
+//
+// DrawStreamableImage always returns nil.
+func (i *Image) DrawStreamableImage(img *StreamableImage, options *DrawImageOptions) error {
+	if img.disposed {
+		panic("ebiten: the given image to DrawStreamableImage must not be disposed")
+	}
+	if i.isDisposed() {
+		return nil
+	}
+	if options == nil {
+		options = &DrawImageOptions{}
+	}
+	op := *options
+	op.ImageParts = nil
+	op.Parts = nil
+
+	if r := img.resident(); r != nil {
+		i.DrawImage(r, &op)
+		return nil
+	}
+
+	if f := currentStreamingBudget().f; f != nil {
+		f(img)
+	}
+	p := currentStreamingPlaceholder()
+	if p == nil {
+		return nil
+	}
+	r := img.Bounds()
+	if options.SourceRect != nil {
+		r = *options.SourceRect
+	}
+	pw, ph := p.Size()
+	op.SourceRect = nil
+	op.GeoM = GeoM{}
+	op.GeoM.Scale(float64(r.Dx())/float64(pw), float64(r.Dy())/float64(ph))
+	op.GeoM.Concat(options.GeoM)
+	i.DrawImage(p, &op)
+	return nil
+}
+
+type streamingBudget struct {
+	budget int64
+	f      func(img *StreamableImage)
+}
+
+// theStreamingBudget is the current streaming budget (*streamingBudget).
+var theStreamingBudget atomic.Value
+
+// SetStreamingBudget sets the budget in bytes of the GPU memory for streamable images and the function called
+// when a placeholder is drawn instead of a streamable image.
+//
+// The size of a streamable image is counted as 4 * (image width) * (image height).
+// When a streamable image is drawn and the budget is not enough, the least-recently-used images of the lowest
+// priorities that are not drawn in the current frame are evicted. If the budget is still not enough, the
+// placeholder is drawn instead. f is called on the same goroutine as DrawStreamableImage.
+// If budget is 0, the streamable images are never evicted. The default value is 0.
+//
+// This function is concurrent-safe.
+func SetStreamingBudget(budget int64, f func(img *StreamableImage)) {
+	theStreamingBudget.Store(&streamingBudget{
+		budget: budget,
+		f:      f,
+	})
+}
+
+func currentStreamingBudget() *streamingBudget {
+	if b, ok := theStreamingBudget.Load().(*streamingBudget); ok {
+		return b
+	}
+	return &streamingBudget{}
+}
+
+var theStreamingPlaceholder atomic.Value
+
+// SetStreamingPlaceholder sets the image drawn instead of a streamable image that can't be on GPU.
+//
+// The placeholder is scaled to the size of the drawn region. If img is nil, nothing is drawn instead.
+// The default value is nil.
+//
+// This function is concurrent-safe.
+func SetStreamingPlaceholder(img *Image) {
+	theStreamingPlaceholder.Store(&img)
+}
+
+func currentStreamingPlaceholder() *Image {
+	if p, ok := theStreamingPlaceholder.Load().(**Image); ok {
+		return *p
+	}
+	return nil
+}
+
+// StreamingMemoryUsage returns the total size in bytes of the streamable images on GPU.
+//
+// The size of a streamable image is counted as 4 * (image width) * (image height).
+func StreamingMemoryUsage() int64 {
+	return theStreamer.usage
+}
+
+// streamer manages the streamable images on GPU.
+type streamer struct {
+	// images is the streamable images on GPU.
+	images []*StreamableImage
+
+	// usage is the total size of images.
+	usage int64
+
+	// frame is the current frame number.
+	frame int64
+}
+
+var theStreamer = &streamer{}
+
+// reserve makes room for the image by evicting the other images if needed, and registers the image.
+// reserve returns false if the room can't be made.
+func (s *streamer) reserve(img *StreamableImage) bool {
+	size := img.sizeInBytes()
+	if budget := currentStreamingBudget().budget; budget > 0 {
+		if !s.shrink(budget-size, s.frame) {
+			return false
+		}
+	}
+	s.images = append(s.images, img)
+	s.usage += size
+	return true
+}
+
+// shrink evicts the images that are not used at frame or later until the usage is equal to or less than size.
+// shrink returns false if the usage can't be reduced enough.
+func (s *streamer) shrink(size int64, frame int64) bool {
+	if s.usage <= size {
+		return true
+	}
+	var candidates []*StreamableImage
+	freed := int64(0)
+	for _, img := range s.images {
+		if img.lastUsed >= frame {
+			continue
+		}
+		candidates = append(candidates, img)
+		freed += img.sizeInBytes()
+	}
+	if s.usage-freed > size {
+		return false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.priority != b.priority {
+			return a.priority < b.priority
+		}
+		return a.lastUsed < b.lastUsed
+	})
+	for _, img := range candidates {
+		if s.usage <= size {
+			break
+		}
+		s.evict(img)
+	}
+	return true
+}
+
+// evict removes the image from GPU.
+func (s *streamer) evict(img *StreamableImage) {
+	if img.image == nil {
+		return
+	}
+	_ = img.image.Dispose()
+	img.image = nil
+	for i, m := range s.images {
+		if m == img {
+			s.images = append(s.images[:i], s.images[i+1:]...)
+			break
+		}
+	}
+	s.usage -= img.sizeInBytes()
+}
+
+// endFrame is called at the end of every frame. endFrame evicts the images if the usage exceeds the budget,
+// e.g. when the budget is reduced.
+func (s *streamer) endFrame() {
+	if budget := currentStreamingBudget().budget; budget > 0 {
+		s.shrink(budget, s.frame+1)
+	}
+	s.frame++
+}
@@ -0,0 +1,148 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ebiten
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func newStreamableImageForTesting(clr color.RGBA) *StreamableImage {
+	src := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for i := 0; i < len(src.Pix); i += 4 {
+		src.Pix[i], src.Pix[i+1], src.Pix[i+2], src.Pix[i+3] = clr.R, clr.G, clr.B, clr.A
+	}
+	img, _ := NewStreamableImageFromImage(src, FilterNearest)
+	return img
+}
+
+func TestStreamableImageBudget(t *testing.T) {
+	var missed []*StreamableImage
+	// The budget is for two 16x16 images.
+	SetStreamingBudget(2*4*16*16, func(img *StreamableImage) {
+		missed = append(missed, img)
+	})
+	defer SetStreamingBudget(0, nil)
+
+	placeholder, _ := NewImage(1, 1, FilterNearest)
+	placeholder.Fill(color.RGBA{0, 0, 0xff, 0xff})
+	SetStreamingPlaceholder(placeholder)
+	defer SetStreamingPlaceholder(nil)
+
+	a := newStreamableImageForTesting(color.RGBA{0xff, 0, 0, 0xff})
+	b := newStreamableImageForTesting(color.RGBA{0, 0xff, 0, 0xff})
+	c := newStreamableImageForTesting(color.RGBA{0xff, 0xff, 0, 0xff})
+	defer a.Dispose()
+	defer b.Dispose()
+	defer c.Dispose()
+
+	dst, _ := NewImage(16, 16, FilterNearest)
+	defer dst.Dispose()
+
+	dst.DrawStreamableImage(a, nil)
+	dst.DrawStreamableImage(b, nil)
+	if !a.IsResident() || !b.IsResident() {
+		t.Errorf("a and b must be resident")
+	}
+
+	// The images drawn in the current frame are not evicted. The placeholder is drawn instead.
+	dst.DrawStreamableImage(c, nil)
+	if c.IsResident() {
+		t.Errorf("c must not be resident")
+	}
+	if len(missed) != 1 || missed[0] != c {
+		t.Errorf("missed: got: %v, want: [c]", missed)
+	}
+	if got, want := dst.At(8, 8), (color.RGBA{0, 0, 0xff, 0xff}); got != want {
+		t.Errorf("dst.At(8, 8): got: %v, want: %v", got, want)
+	}
+
+	// At the next frame, the least recently used image is evicted.
+	theStreamer.endFrame()
+	dst.DrawStreamableImage(b, nil)
+	theStreamer.endFrame()
+	dst.DrawStreamableImage(c, nil)
+	if a.IsResident() || !b.IsResident() || !c.IsResident() {
+		t.Errorf("a must be evicted")
+	}
+	if got, want := dst.At(8, 8), (color.RGBA{0xff, 0xff, 0, 0xff}); got != want {
+		t.Errorf("dst.At(8, 8): got: %v, want: %v", got, want)
+	}
+	if got, want := StreamingMemoryUsage(), int64(2*4*16*16); got != want {
+		t.Errorf("StreamingMemoryUsage(): got: %d, want: %d", got, want)
+	}
+
+	// An image of a higher priority is evicted later.
+	b.SetPriority(1)
+	theStreamer.endFrame()
+	dst.DrawStreamableImage(a, nil)
+	if !a.IsResident() || !b.IsResident() || c.IsResident() {
+		t.Errorf("c must be evicted")
+	}
+}
+
+func TestStreamableImageLoad(t *testing.T) {
+	loaded := make(chan struct{})
+	s, _ := NewStreamableImage(16, 16, func() (image.Image, error) {
+		defer close(loaded)
+		src := image.NewRGBA(image.Rect(0, 0, 16, 16))
+		for i := range src.Pix {
+			src.Pix[i] = 0xff
+		}
+		return src, nil
+	}, FilterNearest)
+	defer s.Dispose()
+
+	dst, _ := NewImage(16, 16, FilterNearest)
+	defer dst.Dispose()
+
+	// The pixels are not loaded yet.
+	dst.DrawStreamableImage(s, nil)
+	if s.IsResident() {
+		t.Errorf("s must not be resident before loading")
+	}
+
+	select {
+	case <-loaded:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	// Wait for the loading goroutine to store the pixels.
+	for i := 0; i < 100 && !s.IsResident(); i++ {
+		dst.DrawStreamableImage(s, nil)
+		time.Sleep(time.Millisecond)
+	}
+	if !s.IsResident() {
+		t.Fatalf("s must be resident after loading")
+	}
+	if got, want := dst.At(0, 0), (color.RGBA{0xff, 0xff, 0xff, 0xff}); got != want {
+		t.Errorf("dst.At(0, 0): got: %v, want: %v", got, want)
+	}
+
+	// A failed image is never loaded.
+	f, _ := NewStreamableImage(16, 16, func() (image.Image, error) {
+		return nil, errors.New("test error")
+	}, FilterNearest)
+	defer f.Dispose()
+	for i := 0; i < 10; i++ {
+		dst.DrawStreamableImage(f, nil)
+		time.Sleep(time.Millisecond)
+	}
+	if f.IsResident() {
+		t.Errorf("f must not be resident")
+	}
+}
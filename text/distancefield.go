@@ -0,0 +1,149 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/distancefield"
+	"github.com/hajimehoshi/ebiten/internal/shaping"
+)
+
+// DistanceFieldSpread is the range of the distances of the distance field glyphs in pixels of the face.
+//
+// The outline and the glow of DrawDistanceField must fit in DistanceFieldSpread / 2 pixels.
+const DistanceFieldSpread = 8
+
+type distanceFieldGlyphCacheEntry struct {
+	image *ebiten.Image
+	atime int64
+}
+
+var distanceFieldGlyphCache = map[font.Face]map[rune]*distanceFieldGlyphCacheEntry{}
+
+// getDistanceFieldGlyph returns the distance field image of the glyph r.
+// The upper-left corner of the glyph bounds is at (DistanceFieldSpread / 2, DistanceFieldSpread / 2) in the image.
+//
+// getDistanceFieldGlyph returns nil when the glyph is empty.
+func getDistanceFieldGlyph(face font.Face, r rune) *ebiten.Image {
+	if _, ok := emptyGlyphs[face]; !ok {
+		emptyGlyphs[face] = map[rune]struct{}{}
+	}
+	if _, ok := emptyGlyphs[face][r]; ok {
+		return nil
+	}
+	if _, ok := distanceFieldGlyphCache[face]; !ok {
+		distanceFieldGlyphCache[face] = map[rune]*distanceFieldGlyphCacheEntry{}
+	}
+	if e, ok := distanceFieldGlyphCache[face][r]; ok {
+		e.atime = now()
+		return e.image
+	}
+
+	b := getGlyphBounds(face, r)
+	w, h := (b.Max.X - b.Min.X).Ceil(), (b.Max.Y - b.Min.Y).Ceil()
+	if w == 0 || h == 0 {
+		emptyGlyphs[face][r] = struct{}{}
+		return nil
+	}
+
+	if len(distanceFieldGlyphCache[face]) > cacheLimit {
+		oldest := int64(math.MaxInt64)
+		oldestKey := rune(-1)
+		for r, e := range distanceFieldGlyphCache[face] {
+			if e.atime < oldest {
+				oldestKey = r
+				oldest = e.atime
+			}
+		}
+		_ = distanceFieldGlyphCache[face][oldestKey].image.Dispose()
+		delete(distanceFieldGlyphCache[face], oldestKey)
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	d := font.Drawer{
+		Dst:  rgba,
+		Src:  image.White,
+		Face: face,
+		Dot:  fixed.Point26_6{-b.Min.X, -b.Min.Y},
+	}
+	d.DrawString(string(r))
+
+	img, _ := ebiten.NewImageFromImage(distancefield.Generate(rgba, DistanceFieldSpread), ebiten.FilterLinear)
+	distanceFieldGlyphCache[face][r] = &distanceFieldGlyphCacheEntry{
+		image: img,
+		atime: now(),
+	}
+	return img
+}
+
+// DrawDistanceField draws a given text on a given destination image dst with distance field glyphs.
+//
+// Unlike Draw, the glyphs stay crisp when the text is scaled, and the text can have an outline and a glow.
+// This is useful for large or freely scaled texts. Use a face of a moderate size (e.g. 32 pixels) as the base of
+// the glyphs: scale is the scale of the text from the face's size.
+//
+// (x, y) represents a 'dot' (period) position after scaling.
+// clr is the color for text rendering.
+// options specifies the outline and the glow. options.Spread is ignored and DistanceFieldSpread is used instead.
+// options can be nil.
+//
+// Right-to-left texts are reordered and shaped as Draw.
+//
+// Glyphs used for rendering are cached in least-recently-used way as Draw.
+// Generating a distance field glyph at the first use is much slower than Draw.
+//
+// Be careful that the passed font face is held by this package and is never released.
+// This is a known issue (#498).
+//
+// This function is concurrent-safe.
+func DrawDistanceField(dst *ebiten.Image, text string, face font.Face, x, y, scale float64, clr color.Color, options *ebiten.DistanceFieldOptions) {
+	textM.Lock()
+	defer textM.Unlock()
+
+	df := ebiten.DistanceFieldOptions{}
+	if options != nil {
+		df = *options
+	}
+	df.Spread = DistanceFieldSpread
+
+	op := &ebiten.DrawImageOptions{}
+	op.ColorM = colorToColorM(clr)
+
+	fx := fixed.I(0)
+	prevR := rune(-1)
+	for _, r := range shaping.Visual([]rune(text)) {
+		if prevR >= 0 {
+			fx += face.Kern(prevR, r)
+		}
+		if img := getDistanceFieldGlyph(face, r); img != nil {
+			b := getGlyphBounds(face, r)
+			op.GeoM.Reset()
+			op.GeoM.Translate(fixed26_6ToFloat64(fx+b.Min.X)-DistanceFieldSpread/2, fixed26_6ToFloat64(b.Min.Y)-DistanceFieldSpread/2)
+			op.GeoM.Scale(scale, scale)
+			op.GeoM.Translate(x, y)
+			_ = dst.DrawImageWithDistanceField(img, op, &df)
+		}
+		fx += glyphAdvance(face, r)
+
+		prevR = r
+	}
+}
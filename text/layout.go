@@ -0,0 +1,438 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"image/color"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/shaping"
+)
+
+// Align represents the horizontal alignment of the lines of a Layout.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+
+	// AlignJustify stretches the spaces between words so that the lines fill the width.
+	// The last line of each paragraph is aligned to the left.
+	AlignJustify
+)
+
+// Span is a part of a text with the same face and the same color.
+type Span struct {
+	Text string
+
+	// Face is the font face of the span. If Face is nil, the face of the LayoutOptions is used.
+	Face font.Face
+
+	// Color is the color of the span. If Color is nil, the color of the LayoutOptions is used.
+	Color color.Color
+}
+
+// LayoutOptions represents options for NewLayout.
+type LayoutOptions struct {
+	// Face is the default font face. Face must not be nil.
+	Face font.Face
+
+	// Color is the default color. If Color is nil, the color is white.
+	Color color.Color
+
+	// Width is the width in pixels to wrap the lines at and to align the lines in.
+	// If Width is 0, the lines are wrapped only at line breaks, and the lines are aligned in the widest line.
+	Width int
+
+	// Align is the horizontal alignment of the lines.
+	Align Align
+
+	// LineSpacing is the additional space between the lines in pixels.
+	LineSpacing int
+}
+
+// LineMetrics represents the metrics of a laid out line in pixels.
+//
+// The positions are relative to the upper-left corner of the layout.
+type LineMetrics struct {
+	// X is the left position of the line after the alignment.
+	X int
+
+	// Baseline is the Y position of the baseline.
+	Baseline int
+
+	// Width is the width of the line.
+	Width int
+
+	// Ascent and Descent are the maximum ascent and descent of the faces in the line.
+	Ascent  int
+	Descent int
+}
+
+// layoutItem is a rune with its style.
+type layoutItem struct {
+	r     rune
+	face  font.Face
+	color color.Color
+
+	// level is the bidirectional embedding level. An odd level means right-to-left.
+	level int
+}
+
+func (i *layoutItem) isSpace() bool {
+	return unicode.IsSpace(i.r)
+}
+
+// layoutGlyph is a glyph placed in a layout.
+type layoutGlyph struct {
+	layoutItem
+	x fixed.Int26_6
+	y fixed.Int26_6
+}
+
+// Layout is a laid out text: the text is wrapped to a width and the lines are aligned.
+//
+// Layout is immutable and can be drawn many times without laying out the text again.
+type Layout struct {
+	glyphs []layoutGlyph
+	lines  []LineMetrics
+	width  int
+	height int
+}
+
+// itemsAdvance returns the advance of items following the rune prev of the face prevFace.
+// The kerning is applied between the runes of the same face.
+func itemsAdvance(items []layoutItem, prev rune, prevFace font.Face) fixed.Int26_6 {
+	var a fixed.Int26_6
+	for _, it := range items {
+		if prev >= 0 && prevFace == it.face {
+			a += it.face.Kern(prev, it.r)
+		}
+		a += glyphAdvance(it.face, it.r)
+		prev, prevFace = it.r, it.face
+	}
+	return a
+}
+
+// layoutLine is a line before positioning.
+type layoutLine struct {
+	items []layoutItem
+
+	// last indicates whether the line is the last line of a paragraph.
+	last bool
+}
+
+// wrap splits the paragraph items into lines not wider than width. width 0 means no wrapping.
+//
+// A word wider than width is put on its own line as it is.
+func wrap(items []layoutItem, width fixed.Int26_6) []layoutLine {
+	if width <= 0 {
+		return []layoutLine{{items: trimSpaces(items), last: true}}
+	}
+
+	var lines []layoutLine
+	start := 0
+	end := 0
+	var lineAdvance fixed.Int26_6
+	for i := 0; i < len(items); {
+		// Find the next word with its preceding spaces.
+		j := i
+		for j < len(items) && items[j].isSpace() {
+			j++
+		}
+		k := j
+		for k < len(items) && !items[k].isSpace() {
+			k++
+		}
+
+		prev, prevFace := rune(-1), font.Face(nil)
+		if end > start {
+			prev, prevFace = items[end-1].r, items[end-1].face
+		}
+		a := itemsAdvance(items[i:k], prev, prevFace)
+		if end > start && lineAdvance+a > width && j < k {
+			lines = append(lines, layoutLine{items: trimSpaces(items[start:end])})
+			start = j
+			end = k
+			lineAdvance = itemsAdvance(items[j:k], -1, nil)
+		} else {
+			if end == start {
+				// Spaces at the head of a paragraph are kept.
+				start = i
+			}
+			end = k
+			lineAdvance += a
+		}
+		i = k
+	}
+	lines = append(lines, layoutLine{items: trimSpaces(items[start:end]), last: true})
+	return lines
+}
+
+// trimSpaces removes the trailing spaces.
+func trimSpaces(items []layoutItem) []layoutItem {
+	for len(items) > 0 && items[len(items)-1].isSpace() {
+		items = items[:len(items)-1]
+	}
+	return items
+}
+
+// shapeItems shapes the paragraph items and sets their bidirectional levels.
+func shapeItems(items []layoutItem) []layoutItem {
+	runes := make([]rune, len(items))
+	for i, it := range items {
+		runes[i] = it.r
+	}
+	if !shaping.IsRTL(runes) {
+		return items
+	}
+
+	shaped, indices := shaping.ShapeArabic(runes)
+	levels := shaping.Levels(shaped, shaping.ParagraphRTL(shaped))
+	result := make([]layoutItem, len(shaped))
+	for i, r := range shaped {
+		result[i] = items[indices[i]]
+		result[i].r = r
+		result[i].level = levels[i]
+	}
+	return result
+}
+
+// reorderItems returns the line items in the visual order.
+func reorderItems(items []layoutItem) []layoutItem {
+	levels := make([]int, len(items))
+	rtl := false
+	for i, it := range items {
+		levels[i] = it.level
+		if it.level > 0 {
+			rtl = true
+		}
+	}
+	if !rtl {
+		return items
+	}
+
+	result := make([]layoutItem, len(items))
+	for i, idx := range shaping.VisualOrder(levels) {
+		result[i] = items[idx]
+		if result[i].level%2 == 1 {
+			result[i].r = shaping.Mirror(result[i].r)
+		}
+	}
+	return result
+}
+
+// NewLayout lays out the spans with options.
+//
+// The lines are broken at line breaks ('\n'), and wrapped at spaces to fit options.Width.
+// Kerning is applied between the adjacent runes of the same face.
+//
+// Right-to-left texts like Arabic and Hebrew are supported: the direction of each paragraph is determined by
+// its first strong character, the runs of each line are reordered visually and the brackets in right-to-left runs
+// are mirrored. Arabic letters are joined with their presentation forms, so the face must have the glyphs of
+// Arabic Presentation Forms-B. Explicit directional formatting characters are ignored. Complex scripts that
+// require the font's substitution tables, e.g. Devanagari conjuncts, and emoji sequences are not shaped.
+//
+// NewLayout panics when options.Face is nil.
+func NewLayout(spans []Span, options *LayoutOptions) *Layout {
+	if options.Face == nil {
+		panic("text: the face of LayoutOptions must not be nil")
+	}
+	defaultColor := options.Color
+	if defaultColor == nil {
+		defaultColor = color.White
+	}
+
+	textM.Lock()
+	defer textM.Unlock()
+
+	// Split the items into paragraphs.
+	var paragraphs [][]layoutItem
+	var items []layoutItem
+	for _, s := range spans {
+		face := s.Face
+		if face == nil {
+			face = options.Face
+		}
+		clr := s.Color
+		if clr == nil {
+			clr = defaultColor
+		}
+		for _, r := range s.Text {
+			if r == '\n' {
+				paragraphs = append(paragraphs, items)
+				items = nil
+				continue
+			}
+			items = append(items, layoutItem{r: r, face: face, color: clr})
+		}
+	}
+	paragraphs = append(paragraphs, items)
+
+	var lines []layoutLine
+	for _, p := range paragraphs {
+		p = shapeItems(p)
+		for _, line := range wrap(p, fixed.I(options.Width)) {
+			line.items = reorderItems(line.items)
+			lines = append(lines, line)
+		}
+	}
+
+	l := &Layout{}
+	var advances []fixed.Int26_6
+	for _, line := range lines {
+		a := itemsAdvance(line.items, -1, nil)
+		advances = append(advances, a)
+		if w := a.Ceil(); l.width < w {
+			l.width = w
+		}
+	}
+	if options.Width > 0 {
+		l.width = options.Width
+	}
+
+	y := 0
+	for i, line := range lines {
+		m := LineMetrics{
+			Width: advances[i].Ceil(),
+		}
+		faces := map[font.Face]struct{}{}
+		for _, it := range line.items {
+			faces[it.face] = struct{}{}
+		}
+		if len(faces) == 0 {
+			faces[options.Face] = struct{}{}
+		}
+		for f := range faces {
+			fm := f.Metrics()
+			if a := fm.Ascent.Ceil(); m.Ascent < a {
+				m.Ascent = a
+			}
+			if d := fm.Descent.Ceil(); m.Descent < d {
+				m.Descent = d
+			}
+		}
+		if i > 0 {
+			y += options.LineSpacing
+		}
+		m.Baseline = y + m.Ascent
+		y += m.Ascent + m.Descent
+
+		free := fixed.I(l.width) - advances[i]
+		var gap fixed.Int26_6
+		switch options.Align {
+		case AlignCenter:
+			m.X = (free / 2).Round()
+		case AlignRight:
+			m.X = free.Round()
+		case AlignJustify:
+			if line.last {
+				break
+			}
+			// Count the gaps between words.
+			n := 0
+			for j := 1; j < len(line.items); j++ {
+				if !line.items[j].isSpace() && line.items[j-1].isSpace() {
+					n++
+				}
+			}
+			if n > 0 && free > 0 {
+				gap = free / fixed.Int26_6(n)
+			}
+		}
+
+		x := fixed.I(m.X)
+		prev, prevFace := rune(-1), font.Face(nil)
+		for j, it := range line.items {
+			if prev >= 0 && prevFace == it.face {
+				x += it.face.Kern(prev, it.r)
+			}
+			if j > 0 && !it.isSpace() && line.items[j-1].isSpace() {
+				x += gap
+			}
+			if !it.isSpace() {
+				l.glyphs = append(l.glyphs, layoutGlyph{
+					layoutItem: it,
+					x:          x,
+					y:          fixed.I(m.Baseline),
+				})
+			}
+			x += glyphAdvance(it.face, it.r)
+			prev, prevFace = it.r, it.face
+		}
+		if gap > 0 {
+			m.Width = l.width
+		}
+
+		l.lines = append(l.lines, m)
+	}
+	l.height = y
+	return l
+}
+
+// Size returns the size of the layout in pixels.
+//
+// The width is LayoutOptions.Width if specified, or the width of the widest line otherwise.
+func (l *Layout) Size() (width, height int) {
+	return l.width, l.height
+}
+
+// Lines returns the metrics of the lines.
+func (l *Layout) Lines() []LineMetrics {
+	ls := make([]LineMetrics, len(l.lines))
+	copy(ls, l.lines)
+	return ls
+}
+
+// Draw draws the layout on dst. (x, y) is the upper-left corner of the layout.
+//
+// Glyphs used for rendering are cached in least-recently-used way as Draw.
+//
+// This function is concurrent-safe.
+func (l *Layout) Draw(dst *ebiten.Image, x, y int) {
+	textM.Lock()
+	defer textM.Unlock()
+
+	// Get the glyph images per face so that the missing glyphs are rendered at once.
+	indices := map[font.Face][]int{}
+	for i, g := range l.glyphs {
+		indices[g.face] = append(indices[g.face], i)
+	}
+	imgs := make([]*glyphImage, len(l.glyphs))
+	for face, is := range indices {
+		runes := make([]rune, len(is))
+		for k, i := range is {
+			runes[k] = l.glyphs[i].r
+		}
+		for k, img := range getGlyphImages(face, runes) {
+			imgs[is[k]] = img
+		}
+	}
+
+	var lastColor color.Color
+	var colorm ebiten.ColorM
+	for i, g := range l.glyphs {
+		if g.color != lastColor {
+			colorm = colorToColorM(g.color)
+			lastColor = g.color
+		}
+		drawGlyph(dst, g.face, g.r, imgs[i], fixed.I(x)+g.x, fixed.I(y)+g.y, colorm)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_test
+
+import (
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+
+	. "github.com/hajimehoshi/ebiten/text"
+)
+
+func TestLayoutWrap(t *testing.T) {
+	face := basicfont.Face7x13
+	a, _ := face.GlyphAdvance('a')
+	w := a.Ceil()
+
+	// "aaa aaa aaa" in a width of 8 characters is wrapped into "aaa aaa" and "aaa".
+	l := NewLayout([]Span{{Text: "aaa aaa aaa"}}, &LayoutOptions{
+		Face:  face,
+		Width: 8 * w,
+	})
+	lines := l.Lines()
+	if got, want := len(lines), 2; got != want {
+		t.Fatalf("len(Lines()): got: %d, want: %d", got, want)
+	}
+	if got, want := lines[0].Width, 7*w; got != want {
+		t.Errorf("lines[0].Width: got: %d, want: %d", got, want)
+	}
+	if got, want := lines[1].Width, 3*w; got != want {
+		t.Errorf("lines[1].Width: got: %d, want: %d", got, want)
+	}
+	if lines[1].Baseline <= lines[0].Baseline {
+		t.Errorf("the second baseline %d must be below the first baseline %d", lines[1].Baseline, lines[0].Baseline)
+	}
+	if gotW, gotH := l.Size(); gotW != 8*w || gotH != lines[1].Baseline+lines[1].Descent {
+		t.Errorf("Size(): got: (%d, %d), want: (%d, %d)", gotW, gotH, 8*w, lines[1].Baseline+lines[1].Descent)
+	}
+}
+
+func TestLayoutAlign(t *testing.T) {
+	face := basicfont.Face7x13
+	a, _ := face.GlyphAdvance('a')
+	w := a.Ceil()
+
+	cases := []struct {
+		Align Align
+		X     int
+	}{
+		{AlignLeft, 0},
+		{AlignCenter, 3 * w},
+		{AlignRight, 6 * w},
+	}
+	for _, c := range cases {
+		l := NewLayout([]Span{{Text: "aaaa"}}, &LayoutOptions{
+			Face:  face,
+			Width: 10 * w,
+			Align: c.Align,
+		})
+		if got := l.Lines()[0].X; got != c.X {
+			t.Errorf("Lines()[0].X with align %d: got: %d, want: %d", c.Align, got, c.X)
+		}
+	}
+
+	// The lines except for the last line of a paragraph fill the width.
+	l := NewLayout([]Span{{Text: "aa aa aa aa"}}, &LayoutOptions{
+		Face:  face,
+		Width: 9 * w,
+		Align: AlignJustify,
+	})
+	lines := l.Lines()
+	if got, want := len(lines), 2; got != want {
+		t.Fatalf("len(Lines()): got: %d, want: %d", got, want)
+	}
+	if got, want := lines[0].Width, 9*w; got != want {
+		t.Errorf("lines[0].Width: got: %d, want: %d", got, want)
+	}
+	if got, want := lines[1].Width, 2*w; got != want {
+		t.Errorf("lines[1].Width: got: %d, want: %d", got, want)
+	}
+}
+
+func TestLayoutLineBreaks(t *testing.T) {
+	l := NewLayout([]Span{{Text: "a\n\nb"}}, &LayoutOptions{
+		Face: basicfont.Face7x13,
+	})
+	if got, want := len(l.Lines()), 3; got != want {
+		t.Errorf("len(Lines()): got: %d, want: %d", got, want)
+	}
+}
+
+func TestParseMarkup(t *testing.T) {
+	bold := basicfont.Face7x13
+	spans, err := ParseMarkup("Press [color=#f00]A[/color] to [[[face=bold]jump[/face]", map[string]font.Face{"bold": bold})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Span{
+		{Text: "Press "},
+		{Text: "A", Color: color.NRGBA{0xff, 0, 0, 0xff}},
+		{Text: " to ["},
+		{Text: "jump", Face: bold},
+	}
+	if len(spans) != len(want) {
+		t.Fatalf("len(spans): got: %d, want: %d", len(spans), len(want))
+	}
+	for i := range want {
+		if spans[i] != want[i] {
+			t.Errorf("spans[%d]: got: %v, want: %v", i, spans[i], want[i])
+		}
+	}
+
+	for _, markup := range []string{
+		"[color=#ff]a[/color]",
+		"[face=unknown]a[/face]",
+		"[color=#fff]a",
+		"a[/color]",
+		"[bold]a",
+		"[color=#fff",
+	} {
+		if _, err := ParseMarkup(markup, nil); err == nil {
+			t.Errorf("ParseMarkup(%q) must return an error", markup)
+		}
+	}
+}
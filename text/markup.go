@@ -0,0 +1,136 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+)
+
+// ParseMarkup parses a text with markup tags and returns the spans.
+//
+// The tags are:
+//
+//   [color=#rrggbb]...[/color]   the color of the text. #rgb and #rrggbbaa are also available.
+//   [face=name]...[/face]        the face of the text. name is a key of faces.
+//
+// The tags can be nested. '[[' represents a literal '['.
+// The spans out of any tags have nil Face and nil Color, that are the defaults of the layout.
+//
+// For example, "Press [color=#ff0000]A[/color] to [face=bold]jump[/face]" is parsed into four spans.
+func ParseMarkup(markup string, faces map[string]font.Face) ([]Span, error) {
+	var spans []Span
+	var faceStack []font.Face
+	var colorStack []color.Color
+	var buf []byte
+
+	flush := func() {
+		if len(buf) == 0 {
+			return
+		}
+		s := Span{Text: string(buf)}
+		if len(faceStack) > 0 {
+			s.Face = faceStack[len(faceStack)-1]
+		}
+		if len(colorStack) > 0 {
+			s.Color = colorStack[len(colorStack)-1]
+		}
+		spans = append(spans, s)
+		buf = buf[:0]
+	}
+
+	for i := 0; i < len(markup); i++ {
+		c := markup[i]
+		if c != '[' {
+			buf = append(buf, c)
+			continue
+		}
+		if i+1 < len(markup) && markup[i+1] == '[' {
+			buf = append(buf, '[')
+			i++
+			continue
+		}
+		end := strings.IndexByte(markup[i:], ']')
+		if end < 0 {
+			return nil, fmt.Errorf("text: unclosed tag at %d", i)
+		}
+		tag := markup[i+1 : i+end]
+		i += end
+
+		flush()
+		switch {
+		case tag == "/color":
+			if len(colorStack) == 0 {
+				return nil, fmt.Errorf("text: unexpected [/color]")
+			}
+			colorStack = colorStack[:len(colorStack)-1]
+		case tag == "/face":
+			if len(faceStack) == 0 {
+				return nil, fmt.Errorf("text: unexpected [/face]")
+			}
+			faceStack = faceStack[:len(faceStack)-1]
+		case strings.HasPrefix(tag, "color="):
+			clr, err := parseColor(tag[len("color="):])
+			if err != nil {
+				return nil, err
+			}
+			colorStack = append(colorStack, clr)
+		case strings.HasPrefix(tag, "face="):
+			name := tag[len("face="):]
+			f, ok := faces[name]
+			if !ok {
+				return nil, fmt.Errorf("text: unknown face: %q", name)
+			}
+			faceStack = append(faceStack, f)
+		default:
+			return nil, fmt.Errorf("text: unknown tag: [%s]", tag)
+		}
+	}
+	flush()
+
+	if len(colorStack) > 0 {
+		return nil, fmt.Errorf("text: [color] is not closed")
+	}
+	if len(faceStack) > 0 {
+		return nil, fmt.Errorf("text: [face] is not closed")
+	}
+	return spans, nil
+}
+
+// parseColor parses a color in the form of #rgb, #rrggbb or #rrggbbaa.
+func parseColor(str string) (color.Color, error) {
+	if !strings.HasPrefix(str, "#") {
+		return nil, fmt.Errorf("text: invalid color: %q", str)
+	}
+	hex := str[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return nil, fmt.Errorf("text: invalid color: %q", str)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("text: invalid color: %q", str)
+	}
+	return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
@@ -26,6 +26,7 @@ import (
 	"golang.org/x/image/math/fixed"
 
 	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/shaping"
 	"github.com/hajimehoshi/ebiten/internal/sync"
 )
 
@@ -259,6 +260,8 @@ func colorToColorM(clr color.Color) ebiten.ColorM {
 // Be careful that this doesn't represent left-upper corner position.
 // clr is the color for text rendering.
 //
+// Right-to-left texts like Arabic and Hebrew are reordered and shaped. See NewLayout for the limitations.
+//
 // Glyphs used for rendering are cached in least-recently-used way.
 // It is OK to call this function with a same text and a same face at every frame in terms of performance.
 //
@@ -272,7 +275,7 @@ func Draw(dst *ebiten.Image, text string, face font.Face, x, y int, clr color.Co
 	fx := fixed.I(x)
 	prevR := rune(-1)
 
-	runes := []rune(text)
+	runes := shaping.Visual([]rune(text))
 	glyphImgs := getGlyphImages(face, runes)
 	colorm := colorToColorM(clr)
 
@@ -0,0 +1,256 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tilemap provides utility functions to render tile maps.
+//
+// A Layer draws only the tiles in the visible area of the render target.
+// A Layer can also cache its tiles into chunk images so that a static layer is drawn with
+// one DrawImage call per chunk.
+//
+// Note: This package is experimental and API might be changed.
+package tilemap
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// Empty represents a tile index that has no tile.
+const Empty = -1
+
+// Tileset represents an image that has tiles of the same size.
+//
+// Tiles are indexed from the top-left corner of the image in row-major order, starting from 0.
+type Tileset struct {
+	Image      *ebiten.Image
+	TileWidth  int
+	TileHeight int
+}
+
+// sourceRect returns the region of the tile at the index in the tileset image.
+func (t *Tileset) sourceRect(index int) image.Rectangle {
+	w, _ := t.Image.Size()
+	n := w / t.TileWidth
+	x := (index % n) * t.TileWidth
+	y := (index / n) * t.TileHeight
+	return image.Rect(x, y, x+t.TileWidth, y+t.TileHeight)
+}
+
+// Layer represents a grid of tiles.
+type Layer struct {
+	tileset *Tileset
+	width   int
+	height  int
+	tiles   []int
+
+	// chunkWidth and chunkHeight are the size of a chunk in tiles.
+	// When they are 0, the cache is disabled.
+	chunkWidth  int
+	chunkHeight int
+	chunks      []*ebiten.Image
+	dirty       []bool
+}
+
+// NewLayer returns a new layer with the given tileset and tile indices.
+// width and height are the size of the layer in tiles.
+// tiles are indices in the tileset in row-major order, and Empty represents no tile.
+//
+// NewLayer copies tiles, so modifying tiles after calling NewLayer doesn't affect the layer.
+//
+// If the length of tiles is not width * height, NewLayer panics.
+func NewLayer(tileset *Tileset, width, height int, tiles []int) *Layer {
+	if len(tiles) != width*height {
+		panic("tilemap: len(tiles) must equal to width * height")
+	}
+	l := &Layer{
+		tileset: tileset,
+		width:   width,
+		height:  height,
+		tiles:   make([]int, len(tiles)),
+	}
+	copy(l.tiles, tiles)
+	return l
+}
+
+// Size returns the size of the layer in tiles.
+func (l *Layer) Size() (width, height int) {
+	return l.width, l.height
+}
+
+// Tile returns the tile index at the position (x, y) in tiles.
+// If the position is out of the layer, Tile returns Empty.
+func (l *Layer) Tile(x, y int) int {
+	if x < 0 || y < 0 || l.width <= x || l.height <= y {
+		return Empty
+	}
+	return l.tiles[y*l.width+x]
+}
+
+// SetTile sets the tile index at the position (x, y) in tiles.
+//
+// If the position is out of the layer, SetTile panics.
+func (l *Layer) SetTile(x, y int, index int) {
+	if x < 0 || y < 0 || l.width <= x || l.height <= y {
+		panic("tilemap: the position is out of the layer")
+	}
+	i := y*l.width + x
+	if l.tiles[i] == index {
+		return
+	}
+	l.tiles[i] = index
+	if l.chunks != nil {
+		l.dirty[(y/l.chunkHeight)*l.chunkXNum()+x/l.chunkWidth] = true
+	}
+}
+
+// EnableCache enables the cache of the layer.
+// chunkWidth and chunkHeight are the size of a chunk in tiles.
+//
+// When the cache is enabled, the tiles are rendered into chunk images once,
+// and each visible chunk is drawn with one DrawImage call.
+// This is efficient for a static layer whose tiles are rarely changed.
+// A chunk is rendered again only when a tile in it is changed by SetTile.
+//
+// The chunk images are regular images, so they are restored when the graphics context is lost.
+//
+// If chunkWidth or chunkHeight is not positive, EnableCache panics.
+func (l *Layer) EnableCache(chunkWidth, chunkHeight int) {
+	if chunkWidth <= 0 || chunkHeight <= 0 {
+		panic("tilemap: chunkWidth and chunkHeight must be positive")
+	}
+	l.DisableCache()
+	l.chunkWidth = chunkWidth
+	l.chunkHeight = chunkHeight
+	n := l.chunkXNum() * l.chunkYNum()
+	l.chunks = make([]*ebiten.Image, n)
+	l.dirty = make([]bool, n)
+}
+
+// DisableCache disables the cache of the layer and disposes the chunk images.
+func (l *Layer) DisableCache() {
+	for _, c := range l.chunks {
+		if c != nil {
+			c.Dispose()
+		}
+	}
+	l.chunkWidth = 0
+	l.chunkHeight = 0
+	l.chunks = nil
+	l.dirty = nil
+}
+
+func (l *Layer) chunkXNum() int {
+	return (l.width + l.chunkWidth - 1) / l.chunkWidth
+}
+
+func (l *Layer) chunkYNum() int {
+	return (l.height + l.chunkHeight - 1) / l.chunkHeight
+}
+
+// visibleRange returns the range of the cells of the given size that are visible in the view.
+// The range is clamped by [0, xNum) and [0, yNum).
+func visibleRange(viewX, viewY float64, viewWidth, viewHeight int, cellWidth, cellHeight int, xNum, yNum int) (x0, y0, x1, y1 int) {
+	x0 = int(math.Floor(viewX / float64(cellWidth)))
+	y0 = int(math.Floor(viewY / float64(cellHeight)))
+	x1 = int(math.Ceil((viewX + float64(viewWidth)) / float64(cellWidth)))
+	y1 = int(math.Ceil((viewY + float64(viewHeight)) / float64(cellHeight)))
+	if x0 < 0 {
+		x0 = 0
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+	if x1 > xNum {
+		x1 = xNum
+	}
+	if y1 > yNum {
+		y1 = yNum
+	}
+	return
+}
+
+// Draw draws the layer on the target image.
+//
+// (viewX, viewY) is the position in pixels in the layer that is drawn at the top-left corner of the target.
+// Only the tiles (or the chunks when the cache is enabled) in the visible area are drawn.
+func (l *Layer) Draw(target *ebiten.Image, viewX, viewY float64) {
+	tw, th := l.tileset.TileWidth, l.tileset.TileHeight
+	w, h := target.Size()
+
+	if l.chunks == nil {
+		x0, y0, x1, y1 := visibleRange(viewX, viewY, w, h, tw, th, l.width, l.height)
+		l.drawTiles(target, x0, y0, x1, y1, viewX, viewY)
+		return
+	}
+
+	cw, ch := l.chunkWidth*tw, l.chunkHeight*th
+	x0, y0, x1, y1 := visibleRange(viewX, viewY, w, h, cw, ch, l.chunkXNum(), l.chunkYNum())
+	op := &ebiten.DrawImageOptions{}
+	for j := y0; j < y1; j++ {
+		for i := x0; i < x1; i++ {
+			op.GeoM.Reset()
+			op.GeoM.Translate(float64(i*cw)-viewX, float64(j*ch)-viewY)
+			target.DrawImage(l.chunk(i, j), op)
+		}
+	}
+}
+
+// drawTiles draws the tiles in the range [x0, x1) x [y0, y1) on the target image.
+func (l *Layer) drawTiles(target *ebiten.Image, x0, y0, x1, y1 int, viewX, viewY float64) {
+	tw, th := l.tileset.TileWidth, l.tileset.TileHeight
+	op := &ebiten.DrawImageOptions{}
+	for j := y0; j < y1; j++ {
+		for i := x0; i < x1; i++ {
+			t := l.tiles[j*l.width+i]
+			if t < 0 {
+				continue
+			}
+			r := l.tileset.sourceRect(t)
+			op.SourceRect = &r
+			op.GeoM.Reset()
+			op.GeoM.Translate(float64(i*tw)-viewX, float64(j*th)-viewY)
+			target.DrawImage(l.tileset.Image, op)
+		}
+	}
+}
+
+// chunk returns the chunk image at the position (x, y) in chunks.
+// The chunk image is created or rendered again if needed.
+func (l *Layer) chunk(x, y int) *ebiten.Image {
+	idx := y*l.chunkXNum() + x
+	img := l.chunks[idx]
+	if img == nil {
+		img, _ = ebiten.NewImage(l.chunkWidth*l.tileset.TileWidth, l.chunkHeight*l.tileset.TileHeight, ebiten.FilterDefault)
+		l.chunks[idx] = img
+		l.dirty[idx] = true
+	}
+	if !l.dirty[idx] {
+		return img
+	}
+
+	x0, y0 := x*l.chunkWidth, y*l.chunkHeight
+	x1, y1 := x0+l.chunkWidth, y0+l.chunkHeight
+	if x1 > l.width {
+		x1 = l.width
+	}
+	if y1 > l.height {
+		y1 = l.height
+	}
+	img.Clear()
+	l.drawTiles(img, x0, y0, x1, y1, float64(x0*l.tileset.TileWidth), float64(y0*l.tileset.TileHeight))
+	l.dirty[idx] = false
+	return img
+}
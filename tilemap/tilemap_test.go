@@ -0,0 +1,62 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tilemap
+
+import (
+	"testing"
+)
+
+func TestVisibleRange(t *testing.T) {
+	cases := []struct {
+		ViewX  float64
+		ViewY  float64
+		Width  int
+		Height int
+		X0     int
+		Y0     int
+		X1     int
+		Y1     int
+	}{
+		{0, 0, 64, 32, 0, 0, 4, 2},
+		{8, 8, 64, 32, 0, 0, 5, 3},
+		{16, 16, 64, 32, 1, 1, 5, 3},
+		{-40, -40, 64, 32, 0, 0, 2, 0},
+		{100, 100, 64, 32, 6, 6, 10, 8},
+		{1000, 0, 64, 32, 62, 0, 10, 2},
+	}
+	for _, c := range cases {
+		x0, y0, x1, y1 := visibleRange(c.ViewX, c.ViewY, c.Width, c.Height, 16, 16, 10, 8)
+		if x0 != c.X0 || y0 != c.Y0 || x1 != c.X1 || y1 != c.Y1 {
+			t.Errorf("visibleRange(%f, %f, %d, %d): got: (%d, %d, %d, %d), want: (%d, %d, %d, %d)", c.ViewX, c.ViewY, c.Width, c.Height, x0, y0, x1, y1, c.X0, c.Y0, c.X1, c.Y1)
+		}
+	}
+}
+
+func TestSetTileMarksChunkDirty(t *testing.T) {
+	l := NewLayer(&Tileset{TileWidth: 16, TileHeight: 16}, 5, 3, make([]int, 15))
+	l.EnableCache(2, 2)
+	if got, want := len(l.dirty), 3*2; got != want {
+		t.Fatalf("len(dirty): got: %d, want: %d", got, want)
+	}
+	l.SetTile(4, 2, 7)
+	for i, d := range l.dirty {
+		if want := i == 5; d != want {
+			t.Errorf("dirty[%d]: got: %v, want: %v", i, d, want)
+		}
+	}
+	if got := l.Tile(4, 2); got != 7 {
+		t.Errorf("Tile(4, 2): got: %d, want: 7", got)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// The flags of a global tile ID in TMX to represent flipping.
+const (
+	tmxFlippedHorizontally = 0x80000000
+	tmxFlippedVertically   = 0x40000000
+	tmxFlippedDiagonally   = 0x20000000
+	tmxFlags               = tmxFlippedHorizontally | tmxFlippedVertically | tmxFlippedDiagonally
+)
+
+// TMX represents a map in the TMX format of Tiled Map Editor (https://www.mapeditor.org/).
+//
+// Only orthogonal maps are supported.
+type TMX struct {
+	Width      int
+	Height     int
+	TileWidth  int
+	TileHeight int
+	Tilesets   []*TMXTileset
+	Layers     []*TMXLayer
+}
+
+// TMXTileset represents a tileset in a TMX map.
+type TMXTileset struct {
+	FirstGID   int
+	Name       string
+	TileWidth  int
+	TileHeight int
+	TileCount  int
+	Columns    int
+
+	// Source is the path of the external tileset (TSX) file.
+	// If Source is not empty, the other fields except for FirstGID are not loaded.
+	Source string
+
+	// Image is the path of the tileset image.
+	Image string
+}
+
+// TMXLayer represents a tile layer in a TMX map.
+type TMXLayer struct {
+	Name   string
+	Width  int
+	Height int
+
+	// GIDs are the global tile IDs in row-major order. 0 represents no tile.
+	// The flags of flipping are removed.
+	GIDs []int
+}
+
+// Tiles returns the tile indices of the layer for the tileset that starts with firstGID and has tileCount tiles.
+// The tiles that don't belong to the tileset are Empty.
+//
+// The result can be passed to NewLayer.
+func (l *TMXLayer) Tiles(firstGID, tileCount int) []int {
+	ts := make([]int, len(l.GIDs))
+	for i, gid := range l.GIDs {
+		if gid < firstGID || firstGID+tileCount <= gid {
+			ts[i] = Empty
+			continue
+		}
+		ts[i] = gid - firstGID
+	}
+	return ts
+}
+
+type tmxMap struct {
+	Orientation string `xml:"orientation,attr"`
+	Width       int    `xml:"width,attr"`
+	Height      int    `xml:"height,attr"`
+	TileWidth   int    `xml:"tilewidth,attr"`
+	TileHeight  int    `xml:"tileheight,attr"`
+	Tilesets    []struct {
+		FirstGID   int    `xml:"firstgid,attr"`
+		Source     string `xml:"source,attr"`
+		Name       string `xml:"name,attr"`
+		TileWidth  int    `xml:"tilewidth,attr"`
+		TileHeight int    `xml:"tileheight,attr"`
+		TileCount  int    `xml:"tilecount,attr"`
+		Columns    int    `xml:"columns,attr"`
+		Image      struct {
+			Source string `xml:"source,attr"`
+		} `xml:"image"`
+	} `xml:"tileset"`
+	Layers []struct {
+		Name   string `xml:"name,attr"`
+		Width  int    `xml:"width,attr"`
+		Height int    `xml:"height,attr"`
+		Data   struct {
+			Encoding    string `xml:"encoding,attr"`
+			Compression string `xml:"compression,attr"`
+			Content     string `xml:",chardata"`
+			Tiles       []struct {
+				GID uint32 `xml:"gid,attr"`
+			} `xml:"tile"`
+		} `xml:"data"`
+	} `xml:"layer"`
+}
+
+// ParseTMX parses a map in the TMX format.
+//
+// The layer data can be encoded in XML, CSV or Base64 with or without zlib or gzip compression.
+// Infinite maps, object groups and image layers are not supported.
+func ParseTMX(r io.Reader) (*TMX, error) {
+	var m tmxMap
+	if err := xml.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	if m.Orientation != "" && m.Orientation != "orthogonal" {
+		return nil, fmt.Errorf("tilemap: orientation %q is not supported", m.Orientation)
+	}
+
+	t := &TMX{
+		Width:      m.Width,
+		Height:     m.Height,
+		TileWidth:  m.TileWidth,
+		TileHeight: m.TileHeight,
+	}
+	for _, ts := range m.Tilesets {
+		t.Tilesets = append(t.Tilesets, &TMXTileset{
+			FirstGID:   ts.FirstGID,
+			Name:       ts.Name,
+			TileWidth:  ts.TileWidth,
+			TileHeight: ts.TileHeight,
+			TileCount:  ts.TileCount,
+			Columns:    ts.Columns,
+			Source:     ts.Source,
+			Image:      ts.Image.Source,
+		})
+	}
+	for _, l := range m.Layers {
+		var gids []uint32
+		d := l.Data
+		switch d.Encoding {
+		case "":
+			for _, t := range d.Tiles {
+				gids = append(gids, t.GID)
+			}
+		case "csv":
+			for _, s := range strings.Split(d.Content, ",") {
+				s = strings.TrimSpace(s)
+				if s == "" {
+					continue
+				}
+				v, err := strconv.ParseUint(s, 10, 32)
+				if err != nil {
+					return nil, fmt.Errorf("tilemap: invalid CSV data in layer %q: %v", l.Name, err)
+				}
+				gids = append(gids, uint32(v))
+			}
+		case "base64":
+			bs, err := decodeTMXBase64(d.Content, d.Compression)
+			if err != nil {
+				return nil, fmt.Errorf("tilemap: invalid Base64 data in layer %q: %v", l.Name, err)
+			}
+			if len(bs)%4 != 0 {
+				return nil, fmt.Errorf("tilemap: invalid Base64 data length in layer %q: %d", l.Name, len(bs))
+			}
+			for i := 0; i < len(bs); i += 4 {
+				gids = append(gids, binary.LittleEndian.Uint32(bs[i:]))
+			}
+		default:
+			return nil, fmt.Errorf("tilemap: encoding %q is not supported", d.Encoding)
+		}
+		if len(gids) != l.Width*l.Height {
+			return nil, fmt.Errorf("tilemap: the number of tiles in layer %q must be %d but %d", l.Name, l.Width*l.Height, len(gids))
+		}
+
+		layer := &TMXLayer{
+			Name:   l.Name,
+			Width:  l.Width,
+			Height: l.Height,
+			GIDs:   make([]int, len(gids)),
+		}
+		for i, g := range gids {
+			layer.GIDs[i] = int(g &^ tmxFlags)
+		}
+		t.Layers = append(t.Layers, layer)
+	}
+	return t, nil
+}
+
+func decodeTMXBase64(content string, compression string) ([]byte, error) {
+	bs, err := base64.StdEncoding.DecodeString(strings.TrimSpace(content))
+	if err != nil {
+		return nil, err
+	}
+	switch compression {
+	case "":
+		return bs, nil
+	case "zlib":
+		r, err := zlib.NewReader(bytes.NewReader(bs))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	case "gzip":
+		r, err := gzip.NewReader(bytes.NewReader(bs))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	default:
+		return nil, fmt.Errorf("compression %q is not supported", compression)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tilemap_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/tilemap"
+)
+
+const tmxTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.0" orientation="orthogonal" width="3" height="2" tilewidth="16" tileheight="16">
+ <tileset firstgid="1" name="tiles" tilewidth="16" tileheight="16" tilecount="4" columns="2">
+  <image source="tiles.png" width="32" height="32"/>
+ </tileset>
+ <layer name="ground" width="3" height="2">
+  %s
+ </layer>
+</map>
+`
+
+func TestParseTMX(t *testing.T) {
+	gids := []uint32{1, 2, 0, 4 | 0x80000000, 3, 5}
+
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	for _, g := range gids {
+		binary.Write(w, binary.LittleEndian, g)
+	}
+	w.Close()
+	zlibData := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var xmlData []string
+	for _, g := range gids {
+		xmlData = append(xmlData, fmt.Sprintf(`<tile gid="%d"/>`, g))
+	}
+
+	cases := []struct {
+		Name string
+		Data string
+	}{
+		{
+			Name: "csv",
+			Data: "<data encoding=\"csv\">\n1,2,0,\n2147483652,3,5\n</data>",
+		},
+		{
+			Name: "base64+zlib",
+			Data: `<data encoding="base64" compression="zlib">` + zlibData + `</data>`,
+		},
+		{
+			Name: "xml",
+			Data: `<data>` + strings.Join(xmlData, "") + `</data>`,
+		},
+	}
+	for _, c := range cases {
+		m, err := ParseTMX(strings.NewReader(fmt.Sprintf(tmxTemplate, c.Data)))
+		if err != nil {
+			t.Errorf("%s: %v", c.Name, err)
+			continue
+		}
+		if m.Width != 3 || m.Height != 2 || m.TileWidth != 16 || m.TileHeight != 16 {
+			t.Errorf("%s: size: got: %d, %d, %d, %d", c.Name, m.Width, m.Height, m.TileWidth, m.TileHeight)
+		}
+		if len(m.Tilesets) != 1 {
+			t.Fatalf("%s: len(Tilesets): got: %d, want: 1", c.Name, len(m.Tilesets))
+		}
+		ts := m.Tilesets[0]
+		if ts.FirstGID != 1 || ts.TileCount != 4 || ts.Image != "tiles.png" {
+			t.Errorf("%s: tileset: got: %+v", c.Name, ts)
+		}
+		if len(m.Layers) != 1 {
+			t.Fatalf("%s: len(Layers): got: %d, want: 1", c.Name, len(m.Layers))
+		}
+		l := m.Layers[0]
+		if got, want := l.GIDs, []int{1, 2, 0, 4, 3, 5}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: GIDs: got: %v, want: %v", c.Name, got, want)
+		}
+		if got, want := l.Tiles(ts.FirstGID, ts.TileCount), []int{0, 1, Empty, 3, 2, Empty}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: Tiles(): got: %v, want: %v", c.Name, got, want)
+		}
+	}
+}
+
+func TestParseTMXInvalidLength(t *testing.T) {
+	_, err := ParseTMX(strings.NewReader(fmt.Sprintf(tmxTemplate, `<data encoding="csv">1,2,3</data>`)))
+	if err == nil {
+		t.Error("ParseTMX must return an error when the number of tiles doesn't match")
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+// Transform is a node of a transform hierarchy, e.g. a scene graph.
+//
+// A Transform has the local position, rotation and scale relative to its parent.
+// The geometry matrix in the world (the screen) is calculated lazily from the ancestors and cached
+// until the Transform or one of its ancestors is modified, so that the matrices are not recalculated
+// every time the same Transform is drawn.
+//
+// The local transformation is applied in the order of scaling, rotating and translating.
+//
+//     parent := &ebiten.Transform{}
+//     parent.SetPosition(100, 100)
+//     child := &ebiten.Transform{}
+//     child.SetParent(parent)
+//     child.SetRotation(math.Pi / 2)
+//
+//     op := &ebiten.DrawImageOptions{}
+//     op.GeoM = child.GeoM()
+//     screen.DrawImage(img, op)
+//
+// The zero value is an identity Transform without a parent.
+//
+// Transform is not concurrent-safe.
+type Transform struct {
+	x        float64
+	y        float64
+	rotation float64
+
+	// scaleX1 and scaleY1 are the scales subtracted by 1, so that the zero value is identity.
+	scaleX1 float64
+	scaleY1 float64
+
+	parent   *Transform
+	children []*Transform
+
+	local GeoM
+	world GeoM
+
+	// localValid and worldValid indicate whether the cached matrices are up to date.
+	localValid bool
+	worldValid bool
+}
+
+// Position returns the local position.
+func (t *Transform) Position() (x, y float64) {
+	return t.x, t.y
+}
+
+// SetPosition sets the local position.
+func (t *Transform) SetPosition(x, y float64) {
+	if t.x == x && t.y == y {
+		return
+	}
+	t.x, t.y = x, y
+	t.invalidateLocal()
+}
+
+// Rotation returns the local rotation in radian.
+func (t *Transform) Rotation() float64 {
+	return t.rotation
+}
+
+// SetRotation sets the local rotation in radian.
+func (t *Transform) SetRotation(theta float64) {
+	if t.rotation == theta {
+		return
+	}
+	t.rotation = theta
+	t.invalidateLocal()
+}
+
+// Scale returns the local scale.
+func (t *Transform) Scale() (x, y float64) {
+	return t.scaleX1 + 1, t.scaleY1 + 1
+}
+
+// SetScale sets the local scale.
+func (t *Transform) SetScale(x, y float64) {
+	if t.scaleX1 == x-1 && t.scaleY1 == y-1 {
+		return
+	}
+	t.scaleX1, t.scaleY1 = x-1, y-1
+	t.invalidateLocal()
+}
+
+// Parent returns the parent. Parent returns nil if t is a root.
+func (t *Transform) Parent() *Transform {
+	return t.parent
+}
+
+// Children returns the children in the order of the addition.
+//
+// The returned slice must not be modified.
+func (t *Transform) Children() []*Transform {
+	return t.children
+}
+
+// SetParent sets the parent of t. t is removed from the children of the previous parent.
+//
+// If parent is nil, t becomes a root.
+//
+// SetParent panics if parent is t or a descendant of t.
+func (t *Transform) SetParent(parent *Transform) {
+	if t.parent == parent {
+		return
+	}
+	for p := parent; p != nil; p = p.parent {
+		if p == t {
+			panic("ebiten: a Transform cannot be a child of itself or its descendants")
+		}
+	}
+	if t.parent != nil {
+		cs := t.parent.children
+		for i, c := range cs {
+			if c == t {
+				copy(cs[i:], cs[i+1:])
+				cs[len(cs)-1] = nil
+				t.parent.children = cs[:len(cs)-1]
+				break
+			}
+		}
+	}
+	t.parent = parent
+	if parent != nil {
+		parent.children = append(parent.children, t)
+	}
+	t.invalidateWorld()
+}
+
+// LocalGeoM returns the geometry matrix relative to the parent.
+func (t *Transform) LocalGeoM() GeoM {
+	if !t.localValid {
+		t.local.Reset()
+		t.local.Scale(t.scaleX1+1, t.scaleY1+1)
+		t.local.Rotate(t.rotation)
+		t.local.Translate(t.x, t.y)
+		t.localValid = true
+	}
+	return t.local
+}
+
+// GeoM returns the geometry matrix in the world, which is the local matrix concatenated with the ancestors' matrices.
+//
+// The result can be used for DrawImageOptions.GeoM directly, or concatenated with other matrices.
+func (t *Transform) GeoM() GeoM {
+	if !t.worldValid {
+		t.world = t.LocalGeoM()
+		if t.parent != nil {
+			t.world.Concat(t.parent.GeoM())
+		}
+		t.worldValid = true
+	}
+	return t.world
+}
+
+func (t *Transform) invalidateLocal() {
+	t.localValid = false
+	t.invalidateWorld()
+}
+
+func (t *Transform) invalidateWorld() {
+	if !t.worldValid {
+		// The descendants are already invalidated, as a valid world matrix requires the valid ancestors.
+		return
+	}
+	t.worldValid = false
+	for _, c := range t.children {
+		c.invalidateWorld()
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten"
+)
+
+func geoMEquals(a, b GeoM) bool {
+	const delta = 1e-9
+	for i := 0; i < GeoMDim-1; i++ {
+		for j := 0; j < GeoMDim; j++ {
+			if math.Abs(a.Element(i, j)-b.Element(i, j)) > delta {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestTransformZero(t *testing.T) {
+	var tr Transform
+	if got, want := tr.GeoM(), (GeoM{}); !geoMEquals(got, want) {
+		t.Errorf("got: %v, want: identity", got)
+	}
+	if x, y := tr.Scale(); x != 1 || y != 1 {
+		t.Errorf("Scale(): got: (%f, %f), want: (1, 1)", x, y)
+	}
+}
+
+func TestTransformHierarchy(t *testing.T) {
+	parent := &Transform{}
+	parent.SetPosition(100, 50)
+	parent.SetScale(2, 2)
+	child := &Transform{}
+	child.SetParent(parent)
+	child.SetPosition(10, 0)
+	child.SetRotation(math.Pi / 2)
+	grandchild := &Transform{}
+	grandchild.SetParent(child)
+	grandchild.SetPosition(0, 5)
+
+	g := grandchild.GeoM()
+	// (0, 5) -> rotated by the child -> (-5, 0) -> translated by the child -> (5, 0)
+	// -> scaled by the parent -> (10, 0) -> translated by the parent -> (110, 50)
+	if x, y := g.Apply(0, 0); math.Abs(x-110) > 1e-9 || math.Abs(y-50) > 1e-9 {
+		t.Errorf("Apply(0, 0): got: (%f, %f), want: (110, 50)", x, y)
+	}
+
+	// Modifying the ancestors affects the descendants.
+	parent.SetPosition(0, 0)
+	g = grandchild.GeoM()
+	if x, y := g.Apply(0, 0); math.Abs(x-10) > 1e-9 || math.Abs(y) > 1e-9 {
+		t.Errorf("Apply(0, 0): got: (%f, %f), want: (10, 0)", x, y)
+	}
+
+	want := grandchild.LocalGeoM()
+	want.Concat(child.LocalGeoM())
+	want.Concat(parent.LocalGeoM())
+	if got := grandchild.GeoM(); !geoMEquals(got, want) {
+		t.Errorf("GeoM(): got: %v, want: %v", got, want)
+	}
+
+	// Reparenting.
+	grandchild.SetParent(nil)
+	if got := len(child.Children()); got != 0 {
+		t.Errorf("len(Children()): got: %d, want: 0", got)
+	}
+	if got, want := grandchild.GeoM(), grandchild.LocalGeoM(); !geoMEquals(got, want) {
+		t.Errorf("GeoM(): got: %v, want: %v", got, want)
+	}
+	grandchild.SetParent(parent)
+	if got := parent.Children(); len(got) != 2 || got[0] != child || got[1] != grandchild {
+		t.Errorf("Children(): got: %v", got)
+	}
+	want = grandchild.LocalGeoM()
+	want.Concat(parent.LocalGeoM())
+	if got := grandchild.GeoM(); !geoMEquals(got, want) {
+		t.Errorf("GeoM(): got: %v, want: %v", got, want)
+	}
+}
+
+func TestTransformGeoMCopy(t *testing.T) {
+	tr := &Transform{}
+	tr.SetPosition(1, 2)
+	g := tr.GeoM()
+	g.Translate(10, 10)
+	g = tr.GeoM()
+	if x, y := g.Apply(0, 0); x != 1 || y != 2 {
+		t.Errorf("Apply(0, 0): got: (%f, %f), want: (1, 2)", x, y)
+	}
+}
+
+func TestTransformCycle(t *testing.T) {
+	a := &Transform{}
+	b := &Transform{}
+	b.SetParent(a)
+	for _, p := range []*Transform{a, b} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SetParent must panic")
+				}
+			}()
+			a.SetParent(p)
+		}()
+	}
+}
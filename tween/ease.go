@@ -0,0 +1,144 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tween
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/scheduler"
+)
+
+// The easing functions map the progress t in [0, 1] to a value. The values at 0 and 1 are 0 and 1 respectively.
+// The easing functions are used as scheduler.Ease. Linear and the quadratic easing functions are in the package
+// scheduler, e.g. scheduler.EaseInQuad.
+//
+// "In" functions start slowly, "Out" functions end slowly, and "InOut" functions start and end slowly.
+// The values of Back and Elastic functions overshoot out of [0, 1].
+
+// inOut returns the easing function that applies in at the first half and the reversed in at the second half.
+func inOut(in scheduler.Ease, t float64) float64 {
+	if t < 0.5 {
+		return in(2*t) / 2
+	}
+	return 1 - in(2-2*t)/2
+}
+
+// out returns the reversed value of in.
+func out(in scheduler.Ease, t float64) float64 {
+	return 1 - in(1-t)
+}
+
+// EaseInCubic is the cubic easing function that starts slowly.
+func EaseInCubic(t float64) float64 {
+	return t * t * t
+}
+
+// EaseOutCubic is the cubic easing function that ends slowly.
+func EaseOutCubic(t float64) float64 {
+	return out(EaseInCubic, t)
+}
+
+// EaseInOutCubic is the cubic easing function that starts and ends slowly.
+func EaseInOutCubic(t float64) float64 {
+	return inOut(EaseInCubic, t)
+}
+
+// EaseInSine is the sinusoidal easing function that starts slowly.
+func EaseInSine(t float64) float64 {
+	return 1 - math.Cos(t*math.Pi/2)
+}
+
+// EaseOutSine is the sinusoidal easing function that ends slowly.
+func EaseOutSine(t float64) float64 {
+	return out(EaseInSine, t)
+}
+
+// EaseInOutSine is the sinusoidal easing function that starts and ends slowly.
+func EaseInOutSine(t float64) float64 {
+	return inOut(EaseInSine, t)
+}
+
+// EaseInExpo is the exponential easing function that starts slowly.
+func EaseInExpo(t float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+	return math.Pow(2, 10*(t-1))
+}
+
+// EaseOutExpo is the exponential easing function that ends slowly.
+func EaseOutExpo(t float64) float64 {
+	return out(EaseInExpo, t)
+}
+
+// EaseInOutExpo is the exponential easing function that starts and ends slowly.
+func EaseInOutExpo(t float64) float64 {
+	return inOut(EaseInExpo, t)
+}
+
+// EaseInBack is the easing function that moves backward a little at the start.
+func EaseInBack(t float64) float64 {
+	const s = 1.70158
+	return t * t * ((s+1)*t - s)
+}
+
+// EaseOutBack is the easing function that overshoots a little at the end.
+func EaseOutBack(t float64) float64 {
+	return out(EaseInBack, t)
+}
+
+// EaseInOutBack is the easing function that moves backward at the start and overshoots at the end.
+func EaseInOutBack(t float64) float64 {
+	return inOut(EaseInBack, t)
+}
+
+// EaseInElastic is the easing function that oscillates with a growing amplitude at the start.
+func EaseInElastic(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return math.Max(0, math.Min(t, 1))
+	}
+	return -math.Pow(2, 10*(t-1)) * math.Sin((t-1.075)*2*math.Pi/0.3)
+}
+
+// EaseOutElastic is the easing function that oscillates with a decaying amplitude at the end.
+func EaseOutElastic(t float64) float64 {
+	return out(EaseInElastic, t)
+}
+
+// EaseInBounce is the easing function that bounces at the start.
+func EaseInBounce(t float64) float64 {
+	return out(EaseOutBounce, t)
+}
+
+// EaseOutBounce is the easing function that bounces at the end like a falling ball.
+func EaseOutBounce(t float64) float64 {
+	const (
+		n = 7.5625
+		d = 2.75
+	)
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
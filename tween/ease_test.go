@@ -0,0 +1,64 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tween
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEaseEnds(t *testing.T) {
+	eases := map[string]func(float64) float64{
+		"EaseInCubic":    EaseInCubic,
+		"EaseOutCubic":   EaseOutCubic,
+		"EaseInOutCubic": EaseInOutCubic,
+		"EaseInSine":     EaseInSine,
+		"EaseOutSine":    EaseOutSine,
+		"EaseInOutSine":  EaseInOutSine,
+		"EaseInExpo":     EaseInExpo,
+		"EaseOutExpo":    EaseOutExpo,
+		"EaseInOutExpo":  EaseInOutExpo,
+		"EaseInBack":     EaseInBack,
+		"EaseOutBack":    EaseOutBack,
+		"EaseInOutBack":  EaseInOutBack,
+		"EaseInElastic":  EaseInElastic,
+		"EaseOutElastic": EaseOutElastic,
+		"EaseInBounce":   EaseInBounce,
+		"EaseOutBounce":  EaseOutBounce,
+	}
+	const delta = 1e-9
+	for name, f := range eases {
+		if got := f(0); math.Abs(got) > delta {
+			t.Errorf("%s(0): got: %f, want: 0", name, got)
+		}
+		if got := f(1); math.Abs(got-1) > delta {
+			t.Errorf("%s(1): got: %f, want: 1", name, got)
+		}
+		if got := f(0.5); math.IsNaN(got) {
+			t.Errorf("%s(0.5): got: NaN", name)
+		}
+	}
+}
+
+func TestEaseInOutSymmetric(t *testing.T) {
+	for _, v := range []float64{0.1, 0.25, 0.4} {
+		if got, want := EaseInOutCubic(v), 1-EaseInOutCubic(1-v); math.Abs(got-want) > 1e-9 {
+			t.Errorf("EaseInOutCubic(%f): got: %f, want: %f", v, got, want)
+		}
+	}
+	if got := EaseInOutCubic(0.5); got != 0.5 {
+		t.Errorf("EaseInOutCubic(0.5): got: %f, want: 0.5", got)
+	}
+}
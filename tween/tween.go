@@ -0,0 +1,357 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tween provides tweens that animate values in game ticks, e.g. for UI animations and cutscenes.
+//
+// A Tween is advanced by Update, which is supposed to be called once in every game update,
+// or by a scheduler.Scheduler with Start or Play.
+// Tweens are composed into sequences and parallel groups:
+//
+//     var x, alpha float64
+//     t := tween.Sequence(
+//         tween.Float64(&x, 0, 320, 60, tween.EaseOutCubic),
+//         tween.Delay(30),
+//         tween.Float64(&alpha, 1, 0, 20, nil),
+//         tween.Call(func() { ... }),
+//     )
+//     tween.Start(s, t)
+//
+// Note: This package is experimental and API might be changed.
+package tween
+
+import (
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/scheduler"
+)
+
+type kind int
+
+const (
+	kindLeaf kind = iota
+	kindSequence
+	kindParallel
+)
+
+// Tween is an animation that progresses in ticks.
+//
+// A Tween is not concurrent-safe.
+type Tween struct {
+	// OnComplete is called when the tween finishes, if not nil.
+	OnComplete func()
+
+	kind  kind
+	ticks int
+	ease  scheduler.Ease
+
+	// start is called at the first Update after the tween is created or reset.
+	start func()
+
+	// apply is called every tick with the eased value.
+	apply func(v float64)
+
+	children []*Tween
+
+	tick    int
+	index   int
+	started bool
+	done    bool
+}
+
+func newLeaf(ticks int, ease scheduler.Ease, apply func(v float64)) *Tween {
+	if ticks < 0 {
+		panic("tween: ticks must not be negative")
+	}
+	if ease == nil {
+		ease = scheduler.Linear
+	}
+	return &Tween{
+		kind:  kindLeaf,
+		ticks: ticks,
+		ease:  ease,
+		apply: apply,
+	}
+}
+
+// Func returns a tween that calls fn every tick for ticks ticks with the value of ease at the progress in (0, 1].
+// The last value is ease(1). If ease is nil, scheduler.Linear is used.
+//
+// If ticks is 0, fn is called once with ease(1) at the first Update, without taking a tick.
+// If ticks is negative, Func panics.
+func Func(ticks int, ease scheduler.Ease, fn func(v float64)) *Tween {
+	return newLeaf(ticks, ease, fn)
+}
+
+func lerp(from, to, v float64) float64 {
+	return from + (to-from)*v
+}
+
+// Float64 returns a tween that animates the value pointed by p from from to to for ticks ticks.
+//
+// See Func for ticks and ease.
+func Float64(p *float64, from, to float64, ticks int, ease scheduler.Ease) *Tween {
+	return newLeaf(ticks, ease, func(v float64) {
+		*p = lerp(from, to, v)
+	})
+}
+
+// Float64To returns a tween that animates the value pointed by p to to for ticks ticks.
+//
+// The start value is the value pointed by p at the first Update, e.g. when the previous tween in a sequence finishes.
+//
+// See Func for ticks and ease.
+func Float64To(p *float64, to float64, ticks int, ease scheduler.Ease) *Tween {
+	var from float64
+	t := newLeaf(ticks, ease, func(v float64) {
+		*p = lerp(from, to, v)
+	})
+	t.start = func() {
+		from = *p
+	}
+	return t
+}
+
+// GeoMParams represents the parameters of a geometry matrix.
+type GeoMParams struct {
+	ScaleX   float64
+	ScaleY   float64
+	Rotation float64
+	X        float64
+	Y        float64
+}
+
+// GeoM returns a geometry matrix that scales, rotates and translates in this order.
+func (p *GeoMParams) GeoM() ebiten.GeoM {
+	var g ebiten.GeoM
+	g.Scale(p.ScaleX, p.ScaleY)
+	g.Rotate(p.Rotation)
+	g.Translate(p.X, p.Y)
+	return g
+}
+
+// GeoM returns a tween that animates the geometry matrix pointed by g from the parameters from to to for ticks ticks.
+//
+// The parameters are interpolated respectively, and the matrix is replaced with the result of GeoMParams.GeoM.
+//
+// See Func for ticks and ease.
+func GeoM(g *ebiten.GeoM, from, to GeoMParams, ticks int, ease scheduler.Ease) *Tween {
+	return newLeaf(ticks, ease, func(v float64) {
+		p := GeoMParams{
+			ScaleX:   lerp(from.ScaleX, to.ScaleX, v),
+			ScaleY:   lerp(from.ScaleY, to.ScaleY, v),
+			Rotation: lerp(from.Rotation, to.Rotation, v),
+			X:        lerp(from.X, to.X, v),
+			Y:        lerp(from.Y, to.Y, v),
+		}
+		*g = p.GeoM()
+	})
+}
+
+// ColorScale represents the scales of the color components.
+type ColorScale struct {
+	R float64
+	G float64
+	B float64
+	A float64
+}
+
+// ColorM returns a color matrix that scales the color components.
+func (s *ColorScale) ColorM() ebiten.ColorM {
+	var c ebiten.ColorM
+	c.Scale(s.R, s.G, s.B, s.A)
+	return c
+}
+
+// ColorM returns a tween that animates the color matrix pointed by c from the scales from to to for ticks ticks,
+// e.g. to fade an image in or out.
+//
+// The matrix is replaced with the result of ColorScale.ColorM.
+//
+// See Func for ticks and ease.
+func ColorM(c *ebiten.ColorM, from, to ColorScale, ticks int, ease scheduler.Ease) *Tween {
+	return newLeaf(ticks, ease, func(v float64) {
+		s := ColorScale{
+			R: lerp(from.R, to.R, v),
+			G: lerp(from.G, to.G, v),
+			B: lerp(from.B, to.B, v),
+			A: lerp(from.A, to.A, v),
+		}
+		*c = s.ColorM()
+	})
+}
+
+// Delay returns a tween that does nothing for ticks ticks. Delay is useful in a sequence.
+//
+// If ticks is negative, Delay panics.
+func Delay(ticks int) *Tween {
+	return newLeaf(ticks, nil, nil)
+}
+
+// Call returns a tween that calls fn and finishes immediately without taking a tick. Call is useful in a sequence.
+func Call(fn func()) *Tween {
+	return newLeaf(0, nil, func(float64) {
+		fn()
+	})
+}
+
+// Sequence returns a tween that runs the tweens one after another.
+//
+// When a tween finishes, the following tweens that take no ticks, like Call, run at the same tick.
+func Sequence(tweens ...*Tween) *Tween {
+	return &Tween{
+		kind:     kindSequence,
+		children: tweens,
+	}
+}
+
+// Parallel returns a tween that runs the tweens at the same time. The tween finishes when all the tweens finish.
+func Parallel(tweens ...*Tween) *Tween {
+	return &Tween{
+		kind:     kindParallel,
+		children: tweens,
+	}
+}
+
+// Ticks returns the number of the ticks that the tween takes.
+func (t *Tween) Ticks() int {
+	switch t.kind {
+	case kindSequence:
+		n := 0
+		for _, c := range t.children {
+			n += c.Ticks()
+		}
+		return n
+	case kindParallel:
+		n := 0
+		for _, c := range t.children {
+			if m := c.Ticks(); n < m {
+				n = m
+			}
+		}
+		return n
+	default:
+		return t.ticks
+	}
+}
+
+// IsDone reports whether the tween has finished.
+func (t *Tween) IsDone() bool {
+	return t.done
+}
+
+// Update advances the tween by one tick, and reports whether the tween has finished.
+//
+// Update does nothing if the tween has already finished.
+func (t *Tween) Update() bool {
+	t.update()
+	return t.done
+}
+
+// update advances the tween by one tick and reports whether the tween has consumed the tick.
+func (t *Tween) update() bool {
+	if t.done {
+		return false
+	}
+	if !t.started {
+		t.started = true
+		if t.start != nil {
+			t.start()
+		}
+	}
+
+	consumed := false
+	switch t.kind {
+	case kindLeaf:
+		if t.ticks > 0 {
+			t.tick++
+			consumed = true
+		}
+		if t.apply != nil {
+			v := 1.0
+			if t.tick < t.ticks {
+				v = float64(t.tick) / float64(t.ticks)
+			}
+			t.apply(t.ease(v))
+		}
+		if t.tick < t.ticks {
+			return consumed
+		}
+	case kindSequence:
+		for t.index < len(t.children) {
+			c := t.children[t.index]
+			if consumed && c.Ticks() > 0 {
+				return consumed
+			}
+			if c.update() {
+				consumed = true
+			}
+			if !c.done {
+				return consumed
+			}
+			t.index++
+		}
+	case kindParallel:
+		done := true
+		for _, c := range t.children {
+			if c.update() {
+				consumed = true
+			}
+			if !c.done {
+				done = false
+			}
+		}
+		if !done {
+			return consumed
+		}
+	}
+
+	t.done = true
+	if t.OnComplete != nil {
+		t.OnComplete()
+	}
+	return consumed
+}
+
+// Reset rewinds the tween to the start. The tween runs again from the next Update.
+//
+// Reset doesn't restore the animated values. Float64To and the other tweens with start values captured at
+// the first Update capture them again.
+func (t *Tween) Reset() {
+	t.tick = 0
+	t.index = 0
+	t.started = false
+	t.done = false
+	for _, c := range t.children {
+		c.Reset()
+	}
+}
+
+// Start runs the tween every tick from the next tick by the scheduler s, until the tween finishes.
+//
+// The returned timer can be stopped to cancel the tween.
+func Start(s *scheduler.Scheduler, t *Tween) *scheduler.Timer {
+	var timer *scheduler.Timer
+	timer = s.Every(1, func() {
+		if t.Update() {
+			timer.Stop()
+		}
+	})
+	return timer
+}
+
+// Play runs the tween every tick from the next tick, and suspends the coroutine c until the tween finishes.
+//
+// Play must be called on the coroutine's goroutine.
+func Play(c *scheduler.Coroutine, t *Tween) {
+	c.WaitUntil(t.Update)
+}
@@ -0,0 +1,163 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tween
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/scheduler"
+)
+
+func TestFloat64(t *testing.T) {
+	var x float64
+	tw := Float64(&x, 10, 20, 4, nil)
+	var xs []float64
+	for i := 0; i < 6; i++ {
+		done := tw.Update()
+		xs = append(xs, x)
+		if got, want := done, i >= 3; got != want {
+			t.Errorf("Update() at %d: got: %v, want: %v", i, got, want)
+		}
+	}
+	if want := []float64{12.5, 15, 17.5, 20, 20, 20}; !reflect.DeepEqual(xs, want) {
+		t.Errorf("got: %v, want: %v", xs, want)
+	}
+
+	// Float64To captures the start value at the first Update.
+	tw = Float64To(&x, 0, 2, scheduler.EaseInQuad)
+	x = 8
+	tw.Update()
+	if x != 6 {
+		t.Errorf("got: %f, want: 6", x)
+	}
+	tw.Update()
+	if x != 0 {
+		t.Errorf("got: %f, want: 0", x)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	var log []string
+	record := func(name string) *Tween {
+		return Call(func() {
+			log = append(log, name)
+		})
+	}
+	var x, y float64
+	tw := Sequence(
+		record("start"),
+		Float64(&x, 0, 1, 2, nil),
+		record("x"),
+		Delay(1),
+		Parallel(
+			Float64(&y, 0, 1, 1, nil),
+			Sequence(Delay(2), record("parallel")),
+		),
+		record("end"),
+	)
+	if got, want := tw.Ticks(), 5; got != want {
+		t.Errorf("Ticks(): got: %d, want: %d", got, want)
+	}
+	completed := 0
+	tw.OnComplete = func() {
+		completed++
+	}
+
+	for i := 1; i <= 6; i++ {
+		tw.Update()
+		log = append(log, fmt.Sprintf("%d:%v,%v", i, x, y))
+	}
+	want := []string{
+		"start", "1:0.5,0",
+		"x", "2:1,0",
+		"3:1,0",
+		"4:1,1",
+		"parallel", "end", "5:1,1",
+		"6:1,1",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("got: %v, want: %v", log, want)
+	}
+	if completed != 1 {
+		t.Errorf("completed: got: %d, want: 1", completed)
+	}
+
+	tw.Reset()
+	log = nil
+	for !tw.Update() {
+	}
+	if want := []string{"start", "x", "parallel", "end"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("got: %v, want: %v", log, want)
+	}
+	if completed != 2 {
+		t.Errorf("completed: got: %d, want: 2", completed)
+	}
+}
+
+func TestGeoMAndColorM(t *testing.T) {
+	var g ebiten.GeoM
+	var c ebiten.ColorM
+	tw := Parallel(
+		GeoM(&g, GeoMParams{ScaleX: 1, ScaleY: 1}, GeoMParams{ScaleX: 3, ScaleY: 1, X: 10, Y: 20}, 2, nil),
+		ColorM(&c, ColorScale{1, 1, 1, 1}, ColorScale{1, 1, 1, 0}, 2, nil),
+	)
+	tw.Update()
+	if x, y := g.Apply(1, 1); x != 7 || y != 11 {
+		t.Errorf("Apply(1, 1): got: (%f, %f), want: (7, 11)", x, y)
+	}
+	if got := c.Element(3, 3); got != 0.5 {
+		t.Errorf("Element(3, 3): got: %f, want: 0.5", got)
+	}
+	tw.Update()
+	if x, y := g.Apply(1, 1); x != 13 || y != 21 {
+		t.Errorf("Apply(1, 1): got: (%f, %f), want: (13, 21)", x, y)
+	}
+	if got := c.Element(3, 3); got != 0 {
+		t.Errorf("Element(3, 3): got: %f, want: 0", got)
+	}
+}
+
+func TestStart(t *testing.T) {
+	s := &scheduler.Scheduler{}
+	var x float64
+	Start(s, Float64(&x, 0, 3, 3, nil))
+	var xs []float64
+	for i := 0; i < 4; i++ {
+		s.Update()
+		xs = append(xs, x)
+	}
+	if want := []float64{1, 2, 3, 3}; !reflect.DeepEqual(xs, want) {
+		t.Errorf("got: %v, want: %v", xs, want)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	s := &scheduler.Scheduler{}
+	var x float64
+	var log []string
+	s.Go(func(c *scheduler.Coroutine) {
+		Play(c, Float64(&x, 0, 2, 2, nil))
+		log = append(log, fmt.Sprintf("%d:%v", s.Ticks(), x))
+	})
+	for i := 0; i < 3; i++ {
+		s.Update()
+	}
+	if want := []string{"2:2"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("got: %v, want: %v", log, want)
+	}
+}
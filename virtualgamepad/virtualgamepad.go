@@ -0,0 +1,405 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package virtualgamepad provides an on-screen gamepad operated by touches.
+//
+// A Gamepad has the same shape of the queries as the physical gamepads in the ebiten package,
+// so that a game can handle a physical gamepad and the on-screen gamepad in one input path.
+//
+// Note: This package is experimental and API might be changed.
+package virtualgamepad
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// Button is a round button.
+type Button struct {
+	// Button is the gamepad button reported while the button is pressed.
+	Button ebiten.GamepadButton
+
+	// X and Y are the center of the button on the screen.
+	X float64
+	Y float64
+
+	// Radius is the radius of the button.
+	Radius float64
+
+	// Image is the image of the button, scaled to the diameter.
+	// If Image is nil, a translucent circle is drawn.
+	Image *ebiten.Image
+}
+
+// Stick is an analog stick.
+type Stick struct {
+	// AxisX and AxisY are the gamepad axes reported for the horizontal and vertical directions.
+	AxisX int
+	AxisY int
+
+	// X and Y are the center of the stick's base on the screen.
+	X float64
+	Y float64
+
+	// Radius is the radius of the stick's base. Moving the touch by Radius from the center results in the axis value 1.
+	Radius float64
+
+	// DeadZone is the ratio of the radius in [0, 1) where the axis values are 0.
+	DeadZone float64
+
+	// BaseImage and KnobImage are the images of the stick's base and the knob, scaled to the diameter and
+	// the half of the diameter. If they are nil, translucent circles are drawn.
+	BaseImage *ebiten.Image
+	KnobImage *ebiten.Image
+}
+
+// DPad is a directional pad that reports four buttons. Diagonal directions press two buttons.
+type DPad struct {
+	// Up, Down, Left and Right are the gamepad buttons reported for the directions.
+	Up    ebiten.GamepadButton
+	Down  ebiten.GamepadButton
+	Left  ebiten.GamepadButton
+	Right ebiten.GamepadButton
+
+	// X and Y are the center of the pad on the screen.
+	X float64
+	Y float64
+
+	// Size is the length of the sides of the pad.
+	Size float64
+
+	// Image is the image of the pad, scaled to the size.
+	// If Image is nil, translucent squares are drawn.
+	Image *ebiten.Image
+}
+
+func (d *DPad) contains(x, y float64) bool {
+	return math.Abs(x-d.X) <= d.Size/2 && math.Abs(y-d.Y) <= d.Size/2
+}
+
+// directions returns the pressed buttons at the position (x, y).
+func (d *DPad) directions(x, y float64) []ebiten.GamepadButton {
+	dx, dy := x-d.X, y-d.Y
+	l := math.Hypot(dx, dy)
+	if l < d.Size/10 {
+		return nil
+	}
+	// A direction is pressed when the angle from the direction is less than 67.5 degrees,
+	// so that the 8 directions have the same angles.
+	const sin22_5 = 0.3826834323650898
+	var bs []ebiten.GamepadButton
+	if dy < -sin22_5*l {
+		bs = append(bs, d.Up)
+	}
+	if dy > sin22_5*l {
+		bs = append(bs, d.Down)
+	}
+	if dx < -sin22_5*l {
+		bs = append(bs, d.Left)
+	}
+	if dx > sin22_5*l {
+		bs = append(bs, d.Right)
+	}
+	return bs
+}
+
+// Gamepad is an on-screen gamepad.
+//
+// The controls are placed in the screen coordinates. Update the controls' positions
+// when the screen size changes.
+type Gamepad struct {
+	Buttons []Button
+	Sticks  []Stick
+	DPads   []DPad
+
+	pressed map[ebiten.GamepadButton]bool
+	axes    map[int]float64
+
+	// stickTouches is the touch IDs operating the sticks.
+	stickTouches map[int]int
+
+	// consumed is the touches that started on a control.
+	consumed map[int]struct{}
+
+	// knobs is the positions of the knobs of the sticks.
+	knobs [][2]float64
+}
+
+// Update updates the gamepad state with the current touches of ebiten.Touches.
+//
+// Update should be called once every frame.
+func (g *Gamepad) Update() {
+	g.UpdateWithTouches(ebiten.Touches())
+}
+
+// UpdateWithTouches updates the gamepad state with the given touches.
+func (g *Gamepad) UpdateWithTouches(touches []ebiten.Touch) {
+	if g.stickTouches == nil {
+		g.stickTouches = map[int]int{}
+		g.consumed = map[int]struct{}{}
+	}
+	g.pressed = map[ebiten.GamepadButton]bool{}
+	g.axes = map[int]float64{}
+
+	current := map[int]struct{}{}
+	for _, t := range touches {
+		current[t.ID()] = struct{}{}
+	}
+	for id := range g.consumed {
+		if _, ok := current[id]; !ok {
+			delete(g.consumed, id)
+		}
+	}
+	for i, id := range g.stickTouches {
+		if _, ok := current[id]; !ok {
+			delete(g.stickTouches, i)
+		}
+	}
+
+	// The knobs are at the centers unless touched.
+	g.knobs = g.knobs[:0]
+	for _, s := range g.Sticks {
+		g.knobs = append(g.knobs, [2]float64{s.X, s.Y})
+	}
+
+	for _, t := range touches {
+		id := t.ID()
+		ix, iy := t.Position()
+		x, y := float64(ix), float64(iy)
+		_, consumed := g.consumed[id]
+
+		// A new touch on a stick's base starts operating the stick.
+		if !consumed {
+			for i, s := range g.Sticks {
+				if _, ok := g.stickTouches[i]; ok {
+					continue
+				}
+				if math.Hypot(x-s.X, y-s.Y) <= s.Radius {
+					g.stickTouches[i] = id
+					g.consumed[id] = struct{}{}
+					consumed = true
+					break
+				}
+			}
+		}
+
+		stick := false
+		for i, sid := range g.stickTouches {
+			if sid != id {
+				continue
+			}
+			g.updateStick(i, x, y)
+			stick = true
+		}
+		if stick {
+			continue
+		}
+
+		// A touch can slide between the buttons and the d-pads.
+		hit := false
+		for _, b := range g.Buttons {
+			if math.Hypot(x-b.X, y-b.Y) <= b.Radius {
+				g.pressed[b.Button] = true
+				hit = true
+			}
+		}
+		for i := range g.DPads {
+			d := &g.DPads[i]
+			if !d.contains(x, y) {
+				continue
+			}
+			for _, b := range d.directions(x, y) {
+				g.pressed[b] = true
+			}
+			hit = true
+		}
+		if hit && !consumed {
+			g.consumed[id] = struct{}{}
+		}
+	}
+}
+
+func (g *Gamepad) updateStick(i int, x, y float64) {
+	s := &g.Sticks[i]
+	dx, dy := (x-s.X)/s.Radius, (y-s.Y)/s.Radius
+	if l := math.Hypot(dx, dy); l > 1 {
+		dx, dy = dx/l, dy/l
+	}
+	g.knobs[i] = [2]float64{s.X + dx*s.Radius, s.Y + dy*s.Radius}
+
+	l := math.Hypot(dx, dy)
+	if l <= s.DeadZone {
+		return
+	}
+	// Rescale the values out of the dead zone to [0, 1].
+	r := (l - s.DeadZone) / (1 - s.DeadZone) / l
+	g.axes[s.AxisX] = dx * r
+	g.axes[s.AxisY] = dy * r
+}
+
+// IsButtonPressed returns a boolean indicating whether the button is pressed,
+// like ebiten.IsGamepadButtonPressed.
+func (g *Gamepad) IsButtonPressed(button ebiten.GamepadButton) bool {
+	return g.pressed[button]
+}
+
+// Axis returns the value [-1, 1] of the axis, like ebiten.GamepadAxis.
+func (g *Gamepad) Axis(axis int) float64 {
+	return g.axes[axis]
+}
+
+// ButtonNum returns the number of the buttons, like ebiten.GamepadButtonNum.
+// The buttons are numbered from 0 to the maximum button of the controls.
+func (g *Gamepad) ButtonNum() int {
+	n := 0
+	update := func(b ebiten.GamepadButton) {
+		if n < int(b)+1 {
+			n = int(b) + 1
+		}
+	}
+	for _, b := range g.Buttons {
+		update(b.Button)
+	}
+	for _, d := range g.DPads {
+		update(d.Up)
+		update(d.Down)
+		update(d.Left)
+		update(d.Right)
+	}
+	return n
+}
+
+// AxisNum returns the number of the axes, like ebiten.GamepadAxisNum.
+// The axes are numbered from 0 to the maximum axis of the sticks.
+func (g *Gamepad) AxisNum() int {
+	n := 0
+	for _, s := range g.Sticks {
+		if n < s.AxisX+1 {
+			n = s.AxisX + 1
+		}
+		if n < s.AxisY+1 {
+			n = s.AxisY + 1
+		}
+	}
+	return n
+}
+
+// IsTouchConsumed returns a boolean indicating whether the touch (id) operates the gamepad.
+//
+// Ignore the consumed touches in the other touch handling of the game.
+func (g *Gamepad) IsTouchConsumed(id int) bool {
+	_, ok := g.consumed[id]
+	return ok
+}
+
+const circleImageRadius = 64
+
+var (
+	circleImage *ebiten.Image
+	squareImage *ebiten.Image
+)
+
+func defaultImages() (circle, square *ebiten.Image) {
+	if circleImage != nil {
+		return circleImage, squareImage
+	}
+	const r = circleImageRadius
+	img := image.NewAlpha(image.Rect(0, 0, 2*r, 2*r))
+	for j := 0; j < 2*r; j++ {
+		for i := 0; i < 2*r; i++ {
+			// Antialias the edge by the distance from the edge.
+			d := r - math.Hypot(float64(i)+0.5-r, float64(j)+0.5-r)
+			a := math.Max(0, math.Min(1, d+0.5))
+			img.SetAlpha(i, j, color.Alpha{uint8(a * 0xff)})
+		}
+	}
+	circleImage, _ = ebiten.NewImageFromImage(img, ebiten.FilterDefault)
+	squareImage, _ = ebiten.NewImage(16, 16, ebiten.FilterDefault)
+	squareImage.Fill(color.White)
+	return circleImage, squareImage
+}
+
+// drawImage draws img scaled to the size w×h at the center (x, y) with the alpha.
+func drawImage(dst, img *ebiten.Image, x, y, w, h, alpha float64) {
+	iw, ih := img.Size()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(w/float64(iw), h/float64(ih))
+	op.GeoM.Translate(x-w/2, y-h/2)
+	op.ColorM.Scale(1, 1, 1, alpha)
+	op.Filter = ebiten.FilterLinear
+	dst.DrawImage(img, op)
+}
+
+// Draw draws the gamepad on the screen.
+//
+// The default images are translucent, and the pressed buttons are drawn brighter.
+func (g *Gamepad) Draw(screen *ebiten.Image) {
+	circle, square := defaultImages()
+
+	for i, s := range g.Sticks {
+		base, knob := s.BaseImage, s.KnobImage
+		if base == nil {
+			base = circle
+		}
+		if knob == nil {
+			knob = circle
+		}
+		drawImage(screen, base, s.X, s.Y, 2*s.Radius, 2*s.Radius, 0.25)
+		kx, ky := s.X, s.Y
+		if i < len(g.knobs) {
+			kx, ky = g.knobs[i][0], g.knobs[i][1]
+		}
+		drawImage(screen, knob, kx, ky, s.Radius, s.Radius, 0.5)
+	}
+
+	for _, b := range g.Buttons {
+		alpha := 0.25
+		if g.pressed[b.Button] {
+			alpha = 0.5
+		}
+		img := b.Image
+		if img == nil {
+			img = circle
+		}
+		drawImage(screen, img, b.X, b.Y, 2*b.Radius, 2*b.Radius, alpha)
+	}
+
+	for _, d := range g.DPads {
+		if d.Image != nil {
+			drawImage(screen, d.Image, d.X, d.Y, d.Size, d.Size, 0.5)
+			continue
+		}
+		// Draw a cross of the four direction squares.
+		u := d.Size / 3
+		for _, dir := range []struct {
+			button ebiten.GamepadButton
+			x, y   float64
+		}{
+			{d.Up, 0, -1},
+			{d.Down, 0, 1},
+			{d.Left, -1, 0},
+			{d.Right, 1, 0},
+		} {
+			alpha := 0.25
+			if g.pressed[dir.button] {
+				alpha = 0.5
+			}
+			drawImage(screen, square, d.X+dir.x*u, d.Y+dir.y*u, u, u, alpha)
+		}
+		drawImage(screen, square, d.X, d.Y, u, u, 0.25)
+	}
+}
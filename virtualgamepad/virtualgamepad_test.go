@@ -0,0 +1,137 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtualgamepad_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	. "github.com/hajimehoshi/ebiten/virtualgamepad"
+)
+
+type testTouch struct {
+	id int
+	x  int
+	y  int
+}
+
+func (t *testTouch) ID() int {
+	return t.id
+}
+
+func (t *testTouch) Position() (int, int) {
+	return t.x, t.y
+}
+
+func newGamepad() *Gamepad {
+	return &Gamepad{
+		Buttons: []Button{
+			{Button: 0, X: 300, Y: 200, Radius: 20},
+			{Button: 1, X: 250, Y: 200, Radius: 20},
+		},
+		Sticks: []Stick{
+			{AxisX: 0, AxisY: 1, X: 50, Y: 200, Radius: 40},
+		},
+		DPads: []DPad{
+			{Up: 12, Down: 13, Left: 14, Right: 15, X: 150, Y: 200, Size: 60},
+		},
+	}
+}
+
+func TestButtons(t *testing.T) {
+	g := newGamepad()
+	g.UpdateWithTouches([]ebiten.Touch{&testTouch{1, 305, 195}})
+	if !g.IsButtonPressed(0) || g.IsButtonPressed(1) {
+		t.Errorf("only button 0 must be pressed")
+	}
+	if !g.IsTouchConsumed(1) {
+		t.Errorf("the touch on a button must be consumed")
+	}
+
+	// Slide to the next button.
+	g.UpdateWithTouches([]ebiten.Touch{&testTouch{1, 250, 200}})
+	if g.IsButtonPressed(0) || !g.IsButtonPressed(1) {
+		t.Errorf("only button 1 must be pressed after sliding")
+	}
+
+	g.UpdateWithTouches(nil)
+	if g.IsButtonPressed(0) || g.IsButtonPressed(1) || g.IsTouchConsumed(1) {
+		t.Errorf("no buttons must be pressed after releasing")
+	}
+
+	g.UpdateWithTouches([]ebiten.Touch{&testTouch{2, 10, 10}})
+	if g.IsTouchConsumed(2) {
+		t.Errorf("the touch out of the controls must not be consumed")
+	}
+
+	if got, want := g.ButtonNum(), 16; got != want {
+		t.Errorf("ButtonNum(): got: %d, want: %d", got, want)
+	}
+	if got, want := g.AxisNum(), 2; got != want {
+		t.Errorf("AxisNum(): got: %d, want: %d", got, want)
+	}
+}
+
+func TestStick(t *testing.T) {
+	g := newGamepad()
+	g.UpdateWithTouches([]ebiten.Touch{&testTouch{1, 60, 200}})
+	g.UpdateWithTouches([]ebiten.Touch{&testTouch{1, 70, 180}})
+	if got, want := g.Axis(0), 0.5; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Axis(0): got: %f, want: %f", got, want)
+	}
+	if got, want := g.Axis(1), -0.5; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Axis(1): got: %f, want: %f", got, want)
+	}
+
+	// The stick keeps following the touch out of the base, and the values are clamped.
+	g.UpdateWithTouches([]ebiten.Touch{&testTouch{1, 250, 200}})
+	if got, want := g.Axis(0), 1.0; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Axis(0): got: %f, want: %f", got, want)
+	}
+	if g.IsButtonPressed(1) {
+		t.Errorf("the touch operating the stick must not press buttons")
+	}
+}
+
+func TestDPad(t *testing.T) {
+	cases := []struct {
+		X, Y    int
+		Pressed []ebiten.GamepadButton
+	}{
+		{150, 180, []ebiten.GamepadButton{12}},
+		{170, 200, []ebiten.GamepadButton{15}},
+		{170, 220, []ebiten.GamepadButton{13, 15}},
+		{150, 200, nil},
+	}
+	for _, c := range cases {
+		g := newGamepad()
+		g.UpdateWithTouches([]ebiten.Touch{&testTouch{1, c.X, c.Y}})
+		n := 0
+		for b := ebiten.GamepadButton(12); b <= 15; b++ {
+			if g.IsButtonPressed(b) {
+				n++
+			}
+		}
+		for _, b := range c.Pressed {
+			if !g.IsButtonPressed(b) {
+				t.Errorf("(%d, %d): button %d must be pressed", c.X, c.Y, b)
+			}
+		}
+		if n != len(c.Pressed) {
+			t.Errorf("(%d, %d): the number of pressed buttons: got: %d, want: %d", c.X, c.Y, n, len(c.Pressed))
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widget
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// Direction represents the direction to arrange the children of a Box.
+type Direction int
+
+const (
+	// Horizontal arranges the children from left to right.
+	Horizontal Direction = iota
+
+	// Vertical arranges the children from top to bottom.
+	Vertical
+)
+
+// Box is a layout container that arranges its children in a row or a column.
+//
+// Each child has its preferred size in the direction of the box, and is stretched to the size of the box
+// in the other direction.
+type Box struct {
+	// Direction is the direction to arrange the children.
+	Direction Direction
+
+	// Spacing is the space between the children.
+	Spacing int
+
+	// Padding is the space around the children.
+	Padding int
+
+	// Children is the child widgets.
+	Children []Widget
+}
+
+// PreferredSize implements Widget.
+func (b *Box) PreferredSize(ctx *Context) (width, height int) {
+	main, cross := 0, 0
+	for i, c := range b.Children {
+		w, h := c.PreferredSize(ctx)
+		if b.Direction == Vertical {
+			w, h = h, w
+		}
+		if i > 0 {
+			main += b.Spacing
+		}
+		main += w
+		if cross < h {
+			cross = h
+		}
+	}
+	main += 2 * b.Padding
+	cross += 2 * b.Padding
+	if b.Direction == Vertical {
+		return cross, main
+	}
+	return main, cross
+}
+
+// childBounds returns the regions of the children in bounds.
+func (b *Box) childBounds(ctx *Context, bounds image.Rectangle) []image.Rectangle {
+	rs := make([]image.Rectangle, len(b.Children))
+	x, y := bounds.Min.X+b.Padding, bounds.Min.Y+b.Padding
+	for i, c := range b.Children {
+		w, h := c.PreferredSize(ctx)
+		if b.Direction == Vertical {
+			rs[i] = image.Rect(x, y, bounds.Max.X-b.Padding, y+h)
+			y += h + b.Spacing
+		} else {
+			rs[i] = image.Rect(x, y, x+w, bounds.Max.Y-b.Padding)
+			x += w + b.Spacing
+		}
+	}
+	return rs
+}
+
+// Update implements Widget.
+func (b *Box) Update(ctx *Context, bounds image.Rectangle) {
+	for i, r := range b.childBounds(ctx, bounds) {
+		b.Children[i].Update(ctx, r)
+	}
+}
+
+// Draw implements Widget.
+func (b *Box) Draw(dst *ebiten.Image, ctx *Context, bounds image.Rectangle) {
+	for i, r := range b.childBounds(ctx, bounds) {
+		b.Children[i].Draw(dst, ctx, r)
+	}
+}
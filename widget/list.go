@@ -0,0 +1,199 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widget
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// List is a scrollable list of texts where an item can be selected.
+//
+// An item is selected by clicking it. The list is scrolled by dragging it, and the selection is moved by
+// the up and down keys while the list is focused.
+type List struct {
+	// Items is the texts of the items.
+	Items []string
+
+	// Selected is the index of the selected item. -1 means that no item is selected.
+	// Note that the zero value selects the first item.
+	Selected int
+
+	// Rows is the number of the visible rows in the preferred size. If Rows is 0, 5 is used.
+	Rows int
+
+	// Disabled indicates whether the list is disabled.
+	Disabled bool
+
+	// OnSelect is called when an item is selected by the user.
+	OnSelect func(index int)
+
+	// scroll is the vertical scroll in pixels.
+	scroll int
+
+	pressY      int
+	pressScroll int
+	dragging    bool
+}
+
+// scrollBarWidth is the width of the scroll bar in pixels.
+const scrollBarWidth = 4
+
+func (l *List) rowHeight(ctx *Context) int {
+	t := ctx.Theme()
+	return t.lineHeight() + t.padding()
+}
+
+// PreferredSize implements Widget.
+func (l *List) PreferredSize(ctx *Context) (width, height int) {
+	t := ctx.Theme()
+	w := 8 * t.lineHeight()
+	for _, item := range l.Items {
+		if iw := t.textWidth(item); w < iw {
+			w = iw
+		}
+	}
+	rows := l.Rows
+	if rows == 0 {
+		rows = 5
+	}
+	return w + 2*t.padding() + scrollBarWidth, rows * l.rowHeight(ctx)
+}
+
+func (l *List) maxScroll(ctx *Context, bounds image.Rectangle) int {
+	s := len(l.Items)*l.rowHeight(ctx) - bounds.Dy()
+	if s < 0 {
+		return 0
+	}
+	return s
+}
+
+func (l *List) selectItem(ctx *Context, bounds image.Rectangle, index int) {
+	if len(l.Items) == 0 {
+		return
+	}
+	if index < 0 {
+		index = 0
+	}
+	if index > len(l.Items)-1 {
+		index = len(l.Items) - 1
+	}
+
+	// Scroll the list to show the item.
+	rh := l.rowHeight(ctx)
+	if y := index * rh; y < l.scroll {
+		l.scroll = y
+	}
+	if y := (index + 1) * rh; y > l.scroll+bounds.Dy() {
+		l.scroll = y - bounds.Dy()
+	}
+
+	if index == l.Selected {
+		return
+	}
+	l.Selected = index
+	if l.OnSelect != nil {
+		l.OnSelect(index)
+	}
+}
+
+// Update implements Widget.
+func (l *List) Update(ctx *Context, bounds image.Rectangle) {
+	if l.Disabled {
+		return
+	}
+	in := ctx.Input()
+	if ctx.Capture(l, bounds) {
+		ctx.Focus(l)
+		l.pressY = in.CursorY
+		l.pressScroll = l.scroll
+		l.dragging = false
+	}
+	if ctx.IsActive(l) {
+		dy := in.CursorY - l.pressY
+		// A move less than the half of a line is regarded as a click.
+		if slop := ctx.Theme().lineHeight() / 2; dy > slop || dy < -slop {
+			l.dragging = true
+		}
+		if l.dragging {
+			l.scroll = l.pressScroll - dy
+		} else if ctx.IsJustReleased() && ctx.IsHovered(bounds) {
+			if idx := (in.CursorY - bounds.Min.Y + l.scroll) / l.rowHeight(ctx); idx < len(l.Items) {
+				l.selectItem(ctx, bounds, idx)
+			}
+		}
+	}
+	if ctx.IsFocused(l) {
+		for _, k := range in.Keys {
+			switch k {
+			case ebiten.KeyUp:
+				l.selectItem(ctx, bounds, l.Selected-1)
+			case ebiten.KeyDown:
+				l.selectItem(ctx, bounds, l.Selected+1)
+			case ebiten.KeyHome:
+				l.selectItem(ctx, bounds, 0)
+			case ebiten.KeyEnd:
+				l.selectItem(ctx, bounds, len(l.Items)-1)
+			}
+		}
+	}
+
+	if max := l.maxScroll(ctx, bounds); l.scroll > max {
+		l.scroll = max
+	}
+	if l.scroll < 0 {
+		l.scroll = 0
+	}
+}
+
+// Draw implements Widget.
+func (l *List) Draw(dst *ebiten.Image, ctx *Context, bounds image.Rectangle) {
+	t := ctx.Theme()
+	pad := t.padding()
+	t.drawFrame(dst, bounds)
+
+	prevClip := dst.Clip()
+	clip := bounds
+	if prevClip != nil {
+		clip = clip.Intersect(*prevClip)
+	}
+	dst.SetClip(&clip)
+	defer dst.SetClip(prevClip)
+
+	clr := t.textColor()
+	if l.Disabled {
+		clr = t.disabledTextColor()
+	}
+	rh := l.rowHeight(ctx)
+	for i := l.scroll / rh; i < len(l.Items); i++ {
+		y := bounds.Min.Y + i*rh - l.scroll
+		if y >= bounds.Max.Y {
+			break
+		}
+		if i == l.Selected {
+			fillRect(dst, image.Rect(bounds.Min.X, y, bounds.Max.X-scrollBarWidth, y+rh), t.accentColor())
+		}
+		t.drawText(dst, l.Items[i], bounds.Min.X+pad, y+pad/2, clr)
+	}
+
+	// Draw the scroll bar when the items overflow.
+	if max := l.maxScroll(ctx, bounds); max > 0 {
+		h := bounds.Dy()
+		th := h * h / (h + max)
+		ty := bounds.Min.Y + (h-th)*l.scroll/max
+		t.drawButton(dst, image.Rect(bounds.Max.X-scrollBarWidth, ty, bounds.Max.X, ty+th), l.dragging && ctx.IsActive(l))
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widget
+
+import (
+	"image"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// TextField is a single-line text input.
+//
+// The characters are input by the keyboard while the text field is focused. The text field is focused by clicking it.
+// The caret is moved by the arrow keys, the Home key and the End key, and the Enter key submits the text.
+//
+// The characters committed by IMEs are also input (see CurrentInput).
+type TextField struct {
+	// Text is the text of the text field.
+	Text string
+
+	// Placeholder is the text shown when Text is empty and the text field is not focused.
+	Placeholder string
+
+	// MaxLength is the maximum number of the characters. If MaxLength is 0, the length is not limited.
+	MaxLength int
+
+	// Disabled indicates whether the text field is disabled.
+	Disabled bool
+
+	// OnChange is called when the text is changed by the user.
+	OnChange func(text string)
+
+	// OnSubmit is called when the Enter key is pressed while the text field is focused.
+	OnSubmit func(text string)
+
+	// caret is the position of the caret in runes.
+	caret int
+
+	// scroll is the horizontal scroll of the text in pixels.
+	scroll int
+}
+
+// PreferredSize implements Widget.
+func (t *TextField) PreferredSize(ctx *Context) (width, height int) {
+	th := ctx.Theme()
+	return 12*th.lineHeight() + 2*th.padding(), th.lineHeight() + 2*th.padding()
+}
+
+// indexAt returns the index of the rune boundary nearest to x, the position from the beginning of the text.
+func indexAt(th *Theme, runes []rune, x int) int {
+	prev := 0
+	for i := 1; i <= len(runes); i++ {
+		w := th.textWidth(string(runes[:i]))
+		if x < (prev+w)/2 {
+			return i - 1
+		}
+		prev = w
+	}
+	return len(runes)
+}
+
+// Update implements Widget.
+func (t *TextField) Update(ctx *Context, bounds image.Rectangle) {
+	if t.Disabled {
+		if ctx.IsFocused(t) {
+			ctx.Focus(nil)
+		}
+		return
+	}
+
+	th := ctx.Theme()
+	pad := th.padding()
+	in := ctx.Input()
+	runes := []rune(t.Text)
+	if t.caret > len(runes) {
+		t.caret = len(runes)
+	}
+
+	if ctx.Capture(t, bounds) {
+		ctx.Focus(t)
+		t.caret = indexAt(th, runes, in.CursorX-bounds.Min.X-pad+t.scroll)
+	}
+	if !ctx.IsFocused(t) {
+		return
+	}
+
+	changed := false
+	for _, r := range in.Runes {
+		if !unicode.IsPrint(r) {
+			continue
+		}
+		if t.MaxLength > 0 && len(runes) >= t.MaxLength {
+			break
+		}
+		runes = append(runes, 0)
+		copy(runes[t.caret+1:], runes[t.caret:])
+		runes[t.caret] = r
+		t.caret++
+		changed = true
+	}
+
+	submitted := false
+	for _, k := range in.Keys {
+		switch k {
+		case ebiten.KeyBackspace:
+			if t.caret > 0 {
+				runes = append(runes[:t.caret-1], runes[t.caret:]...)
+				t.caret--
+				changed = true
+			}
+		case ebiten.KeyDelete:
+			if t.caret < len(runes) {
+				runes = append(runes[:t.caret], runes[t.caret+1:]...)
+				changed = true
+			}
+		case ebiten.KeyLeft:
+			if t.caret > 0 {
+				t.caret--
+			}
+		case ebiten.KeyRight:
+			if t.caret < len(runes) {
+				t.caret++
+			}
+		case ebiten.KeyHome:
+			t.caret = 0
+		case ebiten.KeyEnd:
+			t.caret = len(runes)
+		case ebiten.KeyEnter:
+			submitted = true
+		}
+	}
+
+	if changed {
+		t.Text = string(runes)
+		if t.OnChange != nil {
+			t.OnChange(t.Text)
+		}
+	}
+	if submitted && t.OnSubmit != nil {
+		t.OnSubmit(t.Text)
+	}
+
+	// Scroll the text to keep the caret visible.
+	cx := th.textWidth(string(runes[:t.caret]))
+	if w := bounds.Dx() - 2*pad; cx-t.scroll >= w {
+		t.scroll = cx - w + 1
+	}
+	if cx < t.scroll {
+		t.scroll = cx
+	}
+	if t.scroll < 0 {
+		t.scroll = 0
+	}
+}
+
+// Draw implements Widget.
+func (t *TextField) Draw(dst *ebiten.Image, ctx *Context, bounds image.Rectangle) {
+	th := ctx.Theme()
+	pad := th.padding()
+	th.drawFrame(dst, bounds)
+
+	inner := image.Rect(bounds.Min.X+pad, bounds.Min.Y, bounds.Max.X-pad, bounds.Max.Y)
+	prevClip := dst.Clip()
+	clip := inner
+	if prevClip != nil {
+		clip = clip.Intersect(*prevClip)
+	}
+	dst.SetClip(&clip)
+	defer dst.SetClip(prevClip)
+
+	focused := ctx.IsFocused(t)
+	y := bounds.Min.Y + (bounds.Dy()-th.lineHeight())/2
+	switch {
+	case t.Text == "" && !focused:
+		th.drawText(dst, t.Placeholder, inner.Min.X, y, th.disabledTextColor())
+	case t.Disabled:
+		th.drawText(dst, t.Text, inner.Min.X-t.scroll, y, th.disabledTextColor())
+	default:
+		th.drawText(dst, t.Text, inner.Min.X-t.scroll, y, th.textColor())
+	}
+
+	// Blink the caret every half second.
+	if focused && ctx.Ticks()/30%2 == 0 {
+		runes := []rune(t.Text)
+		caret := t.caret
+		if caret > len(runes) {
+			caret = len(runes)
+		}
+		x := inner.Min.X - t.scroll + th.textWidth(string(runes[:caret]))
+		fillRect(dst, image.Rect(x, y, x+1, y+th.lineHeight()), th.accentColor())
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widget
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+)
+
+// Theme represents the appearance of the widgets.
+//
+// The zero value fields are replaced with the default values.
+type Theme struct {
+	// Face is the font face of the texts. If Face is nil, basicfont.Face7x13 is used.
+	Face font.Face
+
+	// TextColor and DisabledTextColor are the colors of the texts.
+	// DisabledTextColor is also used for the placeholders of the text fields.
+	TextColor         color.Color
+	DisabledTextColor color.Color
+
+	// ButtonColor and ButtonPressedColor are the colors of the buttons, the check boxes and the slider knobs.
+	ButtonColor        color.Color
+	ButtonPressedColor color.Color
+
+	// FrameColor is the color of the frames of the text fields, the lists and the slider tracks.
+	FrameColor color.Color
+
+	// AccentColor is the color of the check marks, the carets and the selected items.
+	AccentColor color.Color
+
+	// ButtonImage, ButtonPressedImage and FrameImage are the nine-patch images drawn instead of
+	// ButtonColor, ButtonPressedColor and FrameColor if they are not nil.
+	ButtonImage        *NinePatch
+	ButtonPressedImage *NinePatch
+	FrameImage         *NinePatch
+
+	// Padding is the space between the borders of the widgets and their contents. If Padding is 0, 4 is used.
+	Padding int
+}
+
+var defaultTheme = &Theme{}
+
+func (t *Theme) face() font.Face {
+	if t.Face == nil {
+		return basicfont.Face7x13
+	}
+	return t.Face
+}
+
+func (t *Theme) padding() int {
+	if t.Padding == 0 {
+		return 4
+	}
+	return t.Padding
+}
+
+func orColor(c color.Color, def color.Color) color.Color {
+	if c == nil {
+		return def
+	}
+	return c
+}
+
+func (t *Theme) textColor() color.Color {
+	return orColor(t.TextColor, color.White)
+}
+
+func (t *Theme) disabledTextColor() color.Color {
+	return orColor(t.DisabledTextColor, color.Gray{0x80})
+}
+
+func (t *Theme) accentColor() color.Color {
+	return orColor(t.AccentColor, color.RGBA{0x30, 0x80, 0xe0, 0xff})
+}
+
+// lineHeight returns the height of a line of the text.
+func (t *Theme) lineHeight() int {
+	return t.face().Metrics().Height.Ceil()
+}
+
+// textWidth returns the width of the text str.
+func (t *Theme) textWidth(str string) int {
+	return font.MeasureString(t.face(), str).Ceil()
+}
+
+// drawText draws the text str at (x, y), the upper-left corner of the line.
+func (t *Theme) drawText(dst *ebiten.Image, str string, x, y int, clr color.Color) {
+	text.Draw(dst, str, t.face(), x, y+t.face().Metrics().Ascent.Ceil(), clr)
+}
+
+func (t *Theme) drawButton(dst *ebiten.Image, bounds image.Rectangle, pressed bool) {
+	if pressed {
+		drawFrame(dst, bounds, t.ButtonPressedImage, orColor(t.ButtonPressedColor, color.RGBA{0x40, 0x40, 0x40, 0xff}))
+		return
+	}
+	drawFrame(dst, bounds, t.ButtonImage, orColor(t.ButtonColor, color.RGBA{0x60, 0x60, 0x60, 0xff}))
+}
+
+func (t *Theme) drawFrame(dst *ebiten.Image, bounds image.Rectangle) {
+	drawFrame(dst, bounds, t.FrameImage, orColor(t.FrameColor, color.RGBA{0x20, 0x20, 0x20, 0xff}))
+}
+
+func drawFrame(dst *ebiten.Image, bounds image.Rectangle, n *NinePatch, clr color.Color) {
+	if n != nil {
+		n.Draw(dst, bounds)
+		return
+	}
+	fillRect(dst, bounds, clr)
+}
+
+var whiteImage *ebiten.Image
+
+// fillRect fills the region r of dst with clr.
+func fillRect(dst *ebiten.Image, r image.Rectangle, clr color.Color) {
+	if r.Empty() {
+		return
+	}
+	cr, cg, cb, ca := clr.RGBA()
+	if ca == 0 {
+		return
+	}
+	if whiteImage == nil {
+		whiteImage, _ = ebiten.NewImage(1, 1, ebiten.FilterNearest)
+		whiteImage.Fill(color.White)
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(r.Dx()), float64(r.Dy()))
+	op.GeoM.Translate(float64(r.Min.X), float64(r.Min.Y))
+	// The color is premultiplied. Convert it to non-premultiplied one for ColorM.
+	op.ColorM.Scale(float64(cr)/float64(ca), float64(cg)/float64(ca), float64(cb)/float64(ca), float64(ca)/0xffff)
+	dst.DrawImage(whiteImage, op)
+}
+
+// NinePatch is an image that is scaled without stretching its borders, e.g. a frame of a button.
+//
+// The four corners are drawn as they are, the edges are stretched in one direction,
+// and the center is stretched in both directions.
+type NinePatch struct {
+	// Image is the source image.
+	Image *ebiten.Image
+
+	// Src is the region of the image to use. If Src is empty, the whole image is used.
+	Src image.Rectangle
+
+	// Left, Top, Right and Bottom are the widths of the borders.
+	Left   int
+	Top    int
+	Right  int
+	Bottom int
+}
+
+// Draw draws the nine-patch image on dst to fill bounds.
+func (n *NinePatch) Draw(dst *ebiten.Image, bounds image.Rectangle) {
+	src := n.Src
+	if src.Empty() {
+		src = n.Image.Bounds()
+	}
+	sx := [...]int{src.Min.X, src.Min.X + n.Left, src.Max.X - n.Right, src.Max.X}
+	sy := [...]int{src.Min.Y, src.Min.Y + n.Top, src.Max.Y - n.Bottom, src.Max.Y}
+	dx := [...]int{bounds.Min.X, bounds.Min.X + n.Left, bounds.Max.X - n.Right, bounds.Max.X}
+	dy := [...]int{bounds.Min.Y, bounds.Min.Y + n.Top, bounds.Max.Y - n.Bottom, bounds.Max.Y}
+
+	rects := make([]image.Rectangle, 0, 9)
+	ops := make([]ebiten.DrawImageOptions, 0, 9)
+	for j := 0; j < 3; j++ {
+		for i := 0; i < 3; i++ {
+			sw, sh := sx[i+1]-sx[i], sy[j+1]-sy[j]
+			dw, dh := dx[i+1]-dx[i], dy[j+1]-dy[j]
+			if sw <= 0 || sh <= 0 || dw <= 0 || dh <= 0 {
+				continue
+			}
+			rects = append(rects, image.Rect(sx[i], sy[j], sx[i+1], sy[j+1]))
+			op := ebiten.DrawImageOptions{}
+			op.SourceRect = &rects[len(rects)-1]
+			op.GeoM.Scale(float64(dw)/float64(sw), float64(dh)/float64(sh))
+			op.GeoM.Translate(float64(dx[i]), float64(dy[j]))
+			ops = append(ops, op)
+		}
+	}
+	dst.DrawImages(n.Image, ops)
+}
@@ -0,0 +1,287 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package widget provides a minimal retained-mode UI toolkit for menus and settings screens:
+// labels, buttons, check boxes, sliders, text fields, scrollable lists and layout boxes.
+//
+// The widgets are drawn with the text package and nine-patch images, and are operated by the mouse,
+// touches and the keyboard.
+//
+// A UI holds a tree of widgets. Call UI's Update in the game's update function, and UI's Draw to render the widgets:
+//
+//     u := &widget.UI{
+//         Root: &widget.Box{
+//             Direction: widget.Vertical,
+//             Spacing:   4,
+//             Children: []widget.Widget{
+//                 &widget.Label{Text: "Volume"},
+//                 &widget.Slider{Max: 100, Value: 50},
+//                 &widget.Button{Text: "OK", OnClick: func() { ... }},
+//             },
+//         },
+//     }
+//
+// Note: This package is experimental and API might be changed.
+package widget
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+// Widget is an element of a UI.
+//
+// Implement Widget to make a custom widget. The region of a widget on the screen (bounds) is determined by its parent,
+// and is passed to Update and Draw.
+type Widget interface {
+	// PreferredSize returns the size that the widget wants to be.
+	PreferredSize(ctx *Context) (width, height int)
+
+	// Update updates the widget's state with the input of ctx.
+	Update(ctx *Context, bounds image.Rectangle)
+
+	// Draw draws the widget on dst.
+	Draw(dst *ebiten.Image, ctx *Context, bounds image.Rectangle)
+}
+
+// Input is a snapshot of the input state that the widgets handle.
+type Input struct {
+	// CursorX and CursorY are the position of the pointer, i.e. the mouse cursor or the first touch.
+	CursorX int
+	CursorY int
+
+	// Pressed indicates whether the pointer is pressed, i.e. the left mouse button is pressed or the screen is touched.
+	Pressed bool
+
+	// Runes is the characters input at the frame, including the texts committed by IMEs.
+	Runes []rune
+
+	// Keys is the editing keys pressed at the frame. A key held down is repeated.
+	Keys []ebiten.Key
+}
+
+// HasKey reports whether the key is pressed at the frame.
+func (in *Input) HasKey(key ebiten.Key) bool {
+	for _, k := range in.Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// editingKeys is the keys handled by the widgets.
+var editingKeys = []ebiten.Key{
+	ebiten.KeyBackspace,
+	ebiten.KeyDelete,
+	ebiten.KeyLeft,
+	ebiten.KeyRight,
+	ebiten.KeyUp,
+	ebiten.KeyDown,
+	ebiten.KeyHome,
+	ebiten.KeyEnd,
+	ebiten.KeyEnter,
+}
+
+const (
+	// keyRepeatDelay and keyRepeatInterval are the delay and the interval of the key repeat in ticks.
+	keyRepeatDelay    = 24
+	keyRepeatInterval = 4
+)
+
+// CurrentInput returns the current input state with the ebiten package and the inpututil package.
+//
+// The characters are taken by ebiten.InputChars, which includes the texts committed by IMEs on desktops.
+// Note that the texts being composed by IMEs are not available, and software keyboards are not shown on mobiles.
+func CurrentInput() Input {
+	var in Input
+	if ts := ebiten.Touches(); len(ts) > 0 {
+		in.CursorX, in.CursorY = ts[0].Position()
+		in.Pressed = true
+	} else {
+		in.CursorX, in.CursorY = ebiten.CursorPosition()
+		in.Pressed = ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	}
+	in.Runes = ebiten.InputChars()
+	for _, k := range editingKeys {
+		d := inpututil.KeyPressDuration(k)
+		if d == 1 || (d >= keyRepeatDelay && (d-keyRepeatDelay)%keyRepeatInterval == 0) {
+			in.Keys = append(in.Keys, k)
+		}
+	}
+	return in
+}
+
+// Context is the state shared by the widgets of a UI.
+type Context struct {
+	theme       *Theme
+	input       Input
+	prevPressed bool
+
+	// active is the widget capturing the pointer.
+	active Widget
+
+	// focus is the widget receiving the keyboard input.
+	focus Widget
+
+	ticks int
+}
+
+// Theme returns the theme of the UI.
+func (c *Context) Theme() *Theme {
+	return c.theme
+}
+
+// Input returns the input state at the current frame.
+func (c *Context) Input() *Input {
+	return &c.input
+}
+
+// Ticks returns the number of the updates of the UI, which is useful e.g. for animations.
+func (c *Context) Ticks() int {
+	return c.ticks
+}
+
+// IsJustPressed reports whether the pointer is just pressed at the current frame.
+func (c *Context) IsJustPressed() bool {
+	return c.input.Pressed && !c.prevPressed
+}
+
+// IsJustReleased reports whether the pointer is just released at the current frame.
+func (c *Context) IsJustReleased() bool {
+	return !c.input.Pressed && c.prevPressed
+}
+
+// IsHovered reports whether the pointer is in bounds.
+func (c *Context) IsHovered(bounds image.Rectangle) bool {
+	return image.Pt(c.input.CursorX, c.input.CursorY).In(bounds)
+}
+
+// Capture makes the widget w capture the pointer if the pointer is just pressed in bounds,
+// and reports whether w captures the pointer.
+//
+// The widget capturing the pointer is active until the pointer is released. See IsActive.
+func (c *Context) Capture(w Widget, bounds image.Rectangle) bool {
+	if c.active != nil || !c.IsJustPressed() || !c.IsHovered(bounds) {
+		return false
+	}
+	c.active = w
+	return true
+}
+
+// IsActive reports whether the widget w captures the pointer.
+// A widget is active from the frame when the pointer is pressed until the frame when the pointer is released.
+func (c *Context) IsActive(w Widget) bool {
+	return c.active != nil && c.active == w
+}
+
+// Focus makes the widget w receive the keyboard input. If w is nil, no widget is focused.
+//
+// The focus is cleared when the pointer is pressed, so a widget should call Focus when it captures the pointer.
+func (c *Context) Focus(w Widget) {
+	c.focus = w
+}
+
+// IsFocused reports whether the widget w receives the keyboard input.
+func (c *Context) IsFocused(w Widget) bool {
+	return c.focus != nil && c.focus == w
+}
+
+// UI is a tree of widgets.
+//
+// The zero value is an empty UI. Set Root to show widgets.
+type UI struct {
+	// Root is the root widget.
+	Root Widget
+
+	// Theme is the theme of the widgets. If Theme is nil, the default theme is used.
+	Theme *Theme
+
+	// Bounds is the region of the root widget on the screen.
+	// If Bounds is empty, the root widget is placed at (0, 0) in its preferred size.
+	Bounds image.Rectangle
+
+	ctx      Context
+	touching bool
+}
+
+func (u *UI) context() *Context {
+	u.ctx.theme = u.Theme
+	if u.ctx.theme == nil {
+		u.ctx.theme = defaultTheme
+	}
+	return &u.ctx
+}
+
+func (u *UI) bounds(ctx *Context) image.Rectangle {
+	if !u.Bounds.Empty() {
+		return u.Bounds
+	}
+	w, h := u.Root.PreferredSize(ctx)
+	return image.Rect(0, 0, w, h)
+}
+
+// Update updates the widgets with the current input state (see CurrentInput).
+//
+// Update should be called once every frame.
+func (u *UI) Update() {
+	in := CurrentInput()
+	touching := len(ebiten.Touches()) > 0
+	if u.touching && !touching {
+		// The touch is just released. Keep the last position of the touch instead of the mouse cursor's.
+		in.CursorX, in.CursorY = u.ctx.input.CursorX, u.ctx.input.CursorY
+	}
+	u.touching = touching
+	u.UpdateWithInput(in)
+}
+
+// UpdateWithInput updates the widgets with the given input state.
+func (u *UI) UpdateWithInput(in Input) {
+	ctx := u.context()
+	ctx.input = in
+	if !ctx.prevPressed {
+		ctx.active = nil
+	}
+	if ctx.IsJustPressed() {
+		ctx.focus = nil
+	}
+	if u.Root != nil {
+		u.Root.Update(ctx, u.bounds(ctx))
+	}
+	ctx.prevPressed = in.Pressed
+	ctx.ticks++
+}
+
+// Draw draws the widgets on dst.
+func (u *UI) Draw(dst *ebiten.Image) {
+	if u.Root == nil {
+		return
+	}
+	ctx := u.context()
+	u.Root.Draw(dst, ctx, u.bounds(ctx))
+}
+
+// Focused returns the widget receiving the keyboard input. If no widget is focused, Focused returns nil.
+func (u *UI) Focused() Widget {
+	return u.ctx.focus
+}
+
+// IsPointerConsumed returns a boolean indicating whether the pointer operates a widget.
+//
+// Ignore the pointer in the other input handling of the game while the pointer is consumed.
+func (u *UI) IsPointerConsumed() bool {
+	return u.ctx.active != nil
+}
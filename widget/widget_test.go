@@ -0,0 +1,221 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widget_test
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitentest"
+	. "github.com/hajimehoshi/ebiten/widget"
+)
+
+func TestMain(m *testing.M) {
+	if err := ebitentest.Init(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// click updates the UI with a press and a release at (x, y).
+func click(u *UI, x, y int) {
+	u.UpdateWithInput(Input{CursorX: x, CursorY: y, Pressed: true})
+	u.UpdateWithInput(Input{CursorX: x, CursorY: y})
+}
+
+func TestButton(t *testing.T) {
+	clicked := 0
+	b := &Button{Text: "OK", OnClick: func() { clicked++ }}
+	u := &UI{Root: b, Bounds: image.Rect(10, 10, 50, 30)}
+
+	click(u, 20, 20)
+	if got, want := clicked, 1; got != want {
+		t.Errorf("clicked: got: %d, want: %d", got, want)
+	}
+
+	// Pressing out of the button doesn't click.
+	click(u, 0, 0)
+	u.UpdateWithInput(Input{CursorX: 0, CursorY: 0, Pressed: true})
+	u.UpdateWithInput(Input{CursorX: 20, CursorY: 20})
+	// Releasing out of the button doesn't click.
+	u.UpdateWithInput(Input{CursorX: 20, CursorY: 20, Pressed: true})
+	if !u.IsPointerConsumed() {
+		t.Errorf("IsPointerConsumed(): got: false, want: true")
+	}
+	u.UpdateWithInput(Input{CursorX: 0, CursorY: 0})
+	if got, want := clicked, 1; got != want {
+		t.Errorf("clicked: got: %d, want: %d", got, want)
+	}
+
+	b.Disabled = true
+	click(u, 20, 20)
+	if got, want := clicked, 1; got != want {
+		t.Errorf("clicked with Disabled: got: %d, want: %d", got, want)
+	}
+}
+
+func TestCheckBox(t *testing.T) {
+	var changed []bool
+	c := &CheckBox{Text: "Fullscreen", OnChange: func(checked bool) { changed = append(changed, checked) }}
+	u := &UI{Root: c}
+
+	click(u, 1, 1)
+	click(u, 1, 1)
+	if len(changed) != 2 || !changed[0] || changed[1] {
+		t.Errorf("changed: got: %v, want: [true false]", changed)
+	}
+	if c.Checked {
+		t.Errorf("Checked: got: true, want: false")
+	}
+}
+
+func TestSlider(t *testing.T) {
+	s := &Slider{Min: 0, Max: 10, Step: 1}
+	u := &UI{Root: s, Bounds: image.Rect(0, 0, 106, 12)}
+
+	// The knob's width is 6, so the value is 5 at the center.
+	click(u, 53, 6)
+	if got, want := s.Value, 5.0; got != want {
+		t.Errorf("Value: got: %v, want: %v", got, want)
+	}
+
+	// Dragging out of the slider clamps the value.
+	u.UpdateWithInput(Input{CursorX: 53, CursorY: 6, Pressed: true})
+	u.UpdateWithInput(Input{CursorX: 500, CursorY: 100, Pressed: true})
+	if got, want := s.Value, 10.0; got != want {
+		t.Errorf("Value: got: %v, want: %v", got, want)
+	}
+	u.UpdateWithInput(Input{CursorX: 500, CursorY: 100})
+
+	// The slider is focused and operated by the keys.
+	u.UpdateWithInput(Input{Keys: []ebiten.Key{ebiten.KeyLeft, ebiten.KeyLeft}})
+	if got, want := s.Value, 8.0; got != want {
+		t.Errorf("Value: got: %v, want: %v", got, want)
+	}
+}
+
+func TestTextField(t *testing.T) {
+	var submitted string
+	f := &TextField{MaxLength: 5, OnSubmit: func(text string) { submitted = text }}
+	u := &UI{Root: f}
+
+	// Characters are not input until the text field is focused.
+	u.UpdateWithInput(Input{Runes: []rune("x")})
+	if f.Text != "" {
+		t.Errorf("Text: got: %q, want: %q", f.Text, "")
+	}
+
+	click(u, 1, 1)
+	if u.Focused() != f {
+		t.Errorf("the text field must be focused")
+	}
+	u.UpdateWithInput(Input{Runes: []rune("abc\n")})
+	u.UpdateWithInput(Input{Keys: []ebiten.Key{ebiten.KeyBackspace, ebiten.KeyLeft}})
+	u.UpdateWithInput(Input{Runes: []rune("XYZW")})
+	if got, want := f.Text, "aXYZb"; got != want {
+		t.Errorf("Text: got: %q, want: %q", got, want)
+	}
+	u.UpdateWithInput(Input{Keys: []ebiten.Key{ebiten.KeyHome, ebiten.KeyDelete, ebiten.KeyEnter}})
+	if got, want := submitted, "XYZb"; got != want {
+		t.Errorf("submitted: got: %q, want: %q", got, want)
+	}
+
+	// Clicking out of the text field unfocuses it.
+	click(u, 1000, 1000)
+	if u.Focused() != nil {
+		t.Errorf("no widget must be focused")
+	}
+}
+
+func TestListAndBox(t *testing.T) {
+	selected := -1
+	l := &List{
+		Items:    []string{"a", "b", "c", "d", "e", "f", "g", "h"},
+		Selected: -1,
+		Rows:     3,
+		OnSelect: func(index int) { selected = index },
+	}
+	label := &Label{Text: "Items"}
+	u := &UI{
+		Root: &Box{
+			Direction: Vertical,
+			Spacing:   3,
+			Children:  []Widget{label, l},
+		},
+	}
+
+	// The label's height is 13 and the row's height is 17. The list starts at 16.
+	click(u, 5, 16+17*2+1)
+	if got, want := selected, 2; got != want {
+		t.Errorf("selected: got: %d, want: %d", got, want)
+	}
+
+	u.UpdateWithInput(Input{Keys: []ebiten.Key{ebiten.KeyDown, ebiten.KeyDown}})
+	if got, want := l.Selected, 4; got != want {
+		t.Errorf("Selected: got: %d, want: %d", got, want)
+	}
+
+	// The list is scrolled to show the selected item, so the first visible row is 2.
+	click(u, 5, 16+1)
+	if got, want := l.Selected, 2; got != want {
+		t.Errorf("Selected: got: %d, want: %d", got, want)
+	}
+
+	// Dragging scrolls the list without selecting.
+	u.UpdateWithInput(Input{CursorX: 5, CursorY: 16 + 40, Pressed: true})
+	u.UpdateWithInput(Input{CursorX: 5, CursorY: 16, Pressed: true})
+	u.UpdateWithInput(Input{CursorX: 5, CursorY: 16})
+	if got, want := l.Selected, 2; got != want {
+		t.Errorf("Selected after dragging: got: %d, want: %d", got, want)
+	}
+	// The scroll is 34 + 40 = 74 pixels, so the first visible row is 4.
+	click(u, 5, 16+1)
+	if got, want := l.Selected, 4; got != want {
+		t.Errorf("Selected: got: %d, want: %d", got, want)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	accent := color.RGBA{0xff, 0, 0, 0xff}
+	u := &UI{
+		Root: &Box{
+			Children: []Widget{
+				&CheckBox{Checked: true},
+				&TextField{Text: "This text is longer than the text field"},
+			},
+		},
+		Theme: &Theme{
+			AccentColor: accent,
+			FrameColor:  color.RGBA{0, 0, 0xff, 0xff},
+		},
+	}
+	dst, _ := ebiten.NewImage(320, 32, ebiten.FilterDefault)
+	u.Draw(dst)
+
+	// The check box is centered vertically in the box of the height 21.
+	if got := dst.At(6, 10); got != accent {
+		t.Errorf("At(6, 10): got: %v, want: %v", got, accent)
+	}
+	// The text is clipped in the text field.
+	if got, want := dst.At(300, 6), (color.RGBA{}); got != want {
+		t.Errorf("At(300, 6): got: %v, want: %v", got, want)
+	}
+	if got := dst.Clip(); got != nil {
+		t.Errorf("Clip(): got: %v, want: nil", got)
+	}
+}
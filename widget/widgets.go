@@ -0,0 +1,250 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widget
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// Label is a line of a text.
+type Label struct {
+	// Text is the text of the label.
+	Text string
+
+	// Color is the color of the text. If Color is nil, the theme's text color is used.
+	Color color.Color
+}
+
+// PreferredSize implements Widget.
+func (l *Label) PreferredSize(ctx *Context) (width, height int) {
+	t := ctx.Theme()
+	return t.textWidth(l.Text), t.lineHeight()
+}
+
+// Update implements Widget.
+func (l *Label) Update(ctx *Context, bounds image.Rectangle) {
+}
+
+// Draw implements Widget.
+func (l *Label) Draw(dst *ebiten.Image, ctx *Context, bounds image.Rectangle) {
+	t := ctx.Theme()
+	t.drawText(dst, l.Text, bounds.Min.X, bounds.Min.Y+(bounds.Dy()-t.lineHeight())/2, orColor(l.Color, t.textColor()))
+}
+
+// Button is a push button with a text.
+type Button struct {
+	// Text is the text of the button.
+	Text string
+
+	// Disabled indicates whether the button is disabled.
+	Disabled bool
+
+	// OnClick is called when the button is clicked, i.e. when the pointer is pressed and released on the button.
+	OnClick func()
+}
+
+// PreferredSize implements Widget.
+func (b *Button) PreferredSize(ctx *Context) (width, height int) {
+	t := ctx.Theme()
+	return t.textWidth(b.Text) + 4*t.padding(), t.lineHeight() + 2*t.padding()
+}
+
+// Update implements Widget.
+func (b *Button) Update(ctx *Context, bounds image.Rectangle) {
+	if b.Disabled {
+		return
+	}
+	ctx.Capture(b, bounds)
+	if ctx.IsActive(b) && ctx.IsJustReleased() && ctx.IsHovered(bounds) && b.OnClick != nil {
+		b.OnClick()
+	}
+}
+
+// Draw implements Widget.
+func (b *Button) Draw(dst *ebiten.Image, ctx *Context, bounds image.Rectangle) {
+	t := ctx.Theme()
+	t.drawButton(dst, bounds, ctx.IsActive(b) && ctx.Input().Pressed && ctx.IsHovered(bounds))
+	clr := t.textColor()
+	if b.Disabled {
+		clr = t.disabledTextColor()
+	}
+	x := bounds.Min.X + (bounds.Dx()-t.textWidth(b.Text))/2
+	y := bounds.Min.Y + (bounds.Dy()-t.lineHeight())/2
+	t.drawText(dst, b.Text, x, y, clr)
+}
+
+// CheckBox is a box with a text that can be checked and unchecked.
+type CheckBox struct {
+	// Text is the text of the check box.
+	Text string
+
+	// Checked indicates whether the check box is checked.
+	Checked bool
+
+	// Disabled indicates whether the check box is disabled.
+	Disabled bool
+
+	// OnChange is called when the check box is checked or unchecked by the user.
+	OnChange func(checked bool)
+}
+
+// PreferredSize implements Widget.
+func (c *CheckBox) PreferredSize(ctx *Context) (width, height int) {
+	t := ctx.Theme()
+	h := t.lineHeight()
+	return h + t.padding() + t.textWidth(c.Text), h
+}
+
+// Update implements Widget.
+func (c *CheckBox) Update(ctx *Context, bounds image.Rectangle) {
+	if c.Disabled {
+		return
+	}
+	ctx.Capture(c, bounds)
+	if ctx.IsActive(c) && ctx.IsJustReleased() && ctx.IsHovered(bounds) {
+		c.Checked = !c.Checked
+		if c.OnChange != nil {
+			c.OnChange(c.Checked)
+		}
+	}
+}
+
+// Draw implements Widget.
+func (c *CheckBox) Draw(dst *ebiten.Image, ctx *Context, bounds image.Rectangle) {
+	t := ctx.Theme()
+	h := t.lineHeight()
+	y := bounds.Min.Y + (bounds.Dy()-h)/2
+	box := image.Rect(bounds.Min.X, y, bounds.Min.X+h, y+h)
+	t.drawButton(dst, box, ctx.IsActive(c) && ctx.Input().Pressed && ctx.IsHovered(bounds))
+	if c.Checked {
+		m := h / 4
+		fillRect(dst, image.Rect(box.Min.X+m, box.Min.Y+m, box.Max.X-m, box.Max.Y-m), t.accentColor())
+	}
+	clr := t.textColor()
+	if c.Disabled {
+		clr = t.disabledTextColor()
+	}
+	t.drawText(dst, c.Text, box.Max.X+t.padding(), y, clr)
+}
+
+// Slider is a horizontal slider to choose a value in a range.
+type Slider struct {
+	// Min and Max are the range of the value.
+	Min float64
+	Max float64
+
+	// Value is the current value.
+	Value float64
+
+	// Step is the unit of the value. If Step is 0, the value is continuous.
+	Step float64
+
+	// Disabled indicates whether the slider is disabled.
+	Disabled bool
+
+	// OnChange is called when the value is changed by the user.
+	OnChange func(value float64)
+}
+
+func (s *Slider) knobWidth(ctx *Context) int {
+	return ctx.Theme().lineHeight() / 2
+}
+
+// PreferredSize implements Widget.
+func (s *Slider) PreferredSize(ctx *Context) (width, height int) {
+	h := ctx.Theme().lineHeight()
+	return 8 * h, h
+}
+
+func (s *Slider) setValue(v float64) {
+	if s.Step > 0 {
+		v = s.Min + math.Round((v-s.Min)/s.Step)*s.Step
+	}
+	if v > s.Max {
+		v = s.Max
+	}
+	if v < s.Min {
+		v = s.Min
+	}
+	if v == s.Value {
+		return
+	}
+	s.Value = v
+	if s.OnChange != nil {
+		s.OnChange(v)
+	}
+}
+
+// Update implements Widget.
+//
+// The value can also be changed by the arrow keys while the slider is focused.
+func (s *Slider) Update(ctx *Context, bounds image.Rectangle) {
+	if s.Disabled || s.Max <= s.Min {
+		return
+	}
+	if ctx.Capture(s, bounds) {
+		ctx.Focus(s)
+	}
+	in := ctx.Input()
+	if ctx.IsActive(s) && in.Pressed {
+		kw := s.knobWidth(ctx)
+		if w := bounds.Dx() - kw; w > 0 {
+			r := float64(in.CursorX-bounds.Min.X-kw/2) / float64(w)
+			s.setValue(s.Min + r*(s.Max-s.Min))
+		}
+	}
+	if !ctx.IsFocused(s) {
+		return
+	}
+	step := s.Step
+	if step == 0 {
+		step = (s.Max - s.Min) / 20
+	}
+	for _, k := range in.Keys {
+		switch k {
+		case ebiten.KeyLeft, ebiten.KeyDown:
+			s.setValue(s.Value - step)
+		case ebiten.KeyRight, ebiten.KeyUp:
+			s.setValue(s.Value + step)
+		case ebiten.KeyHome:
+			s.setValue(s.Min)
+		case ebiten.KeyEnd:
+			s.setValue(s.Max)
+		}
+	}
+}
+
+// Draw implements Widget.
+func (s *Slider) Draw(dst *ebiten.Image, ctx *Context, bounds image.Rectangle) {
+	t := ctx.Theme()
+	kw := s.knobWidth(ctx)
+	r := 0.0
+	if s.Max > s.Min {
+		r = (s.Value - s.Min) / (s.Max - s.Min)
+	}
+	kx := bounds.Min.X + int(r*float64(bounds.Dx()-kw))
+
+	th := bounds.Dy() / 4
+	ty := bounds.Min.Y + (bounds.Dy()-th)/2
+	t.drawFrame(dst, image.Rect(bounds.Min.X, ty, bounds.Max.X, ty+th))
+	if !s.Disabled {
+		fillRect(dst, image.Rect(bounds.Min.X, ty, kx, ty+th), t.accentColor())
+	}
+	t.drawButton(dst, image.Rect(kx, bounds.Min.Y, kx+kw, bounds.Max.Y), ctx.IsActive(s))
+}
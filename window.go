@@ -0,0 +1,121 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"errors"
+
+	"github.com/hajimehoshi/ebiten/internal/shareable"
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+// Window is an additional window beside the main game window, e.g. for a debug view or a level editor.
+//
+// A Window has its own screen and its own function to draw the screen.
+// Input devices are handled only with the main window: e.g. CursorPosition returns the position in the main window.
+type Window struct {
+	f         func(screen *Image) error
+	ui        ui.Window
+	offscreen *Image
+	screen    *Image
+}
+
+// NewWindow creates a new window beside the main window.
+//
+// width and height are the screen size of the window, and scale is the scale of the window
+// samely as Run.
+//
+// f is called once every frame after the game's update function to draw the window's screen.
+// The screen is cleared before f is called.
+// If f returns error, the game is terminated with the error samely as the update function of Run.
+//
+// NewWindow must be called after the game starts, e.g. in the update function.
+// NewWindow returns error on browsers, on mobiles and in the external mode, where multiple windows are not available.
+//
+// If width or height is not positive, NewWindow panics.
+func NewWindow(f func(screen *Image) error, width, height int, scale float64, title string) (*Window, error) {
+	if width <= 0 || height <= 0 {
+		panic("ebiten: width and height must be positive")
+	}
+	g, ok := theGraphicsContext.Load().(*graphicsContext)
+	if !ok {
+		return nil, errors.New("ebiten: NewWindow must be called after the game starts")
+	}
+	u, err := ui.NewWindow(width, height, scale, title)
+	if err != nil {
+		return nil, err
+	}
+	w := &Window{
+		f:         f,
+		ui:        u,
+		offscreen: newVolatileImage(width, height, offscreenFormat()),
+	}
+	g.addWindow(w)
+	return w, nil
+}
+
+// Close closes the window. After Close, the window's function is no longer called.
+//
+// Close does nothing if the window is already closed.
+func (w *Window) Close() {
+	w.ui.Close()
+}
+
+// IsClosed reports whether the window is closed by Close or by the user.
+func (w *Window) IsClosed() bool {
+	return w.ui.IsClosed()
+}
+
+func (w *Window) update() error {
+	w.offscreen.fill(0, 0, 0, 0)
+	if err := w.f(w.offscreen); err != nil {
+		return err
+	}
+
+	// Flush the commands before the window's framebuffer becomes the screen,
+	// as the window might have its own OpenGL context.
+	if err := shareable.ResolveStaleImages(); err != nil {
+		return err
+	}
+	err := w.ui.Render(func() error {
+		shareable.ResetGLStateCache()
+
+		width, height, scale := w.ui.Size()
+		dw, dh := int(float64(width)*scale), int(float64(height)*scale)
+		if w.screen != nil {
+			if sw, sh := w.screen.Size(); sw != dw || sh != dh {
+				_ = w.screen.Dispose()
+				w.screen = nil
+			}
+		}
+		if w.screen == nil {
+			w.screen = newImageWithScreenFramebuffer(dw, dh)
+		}
+
+		m := newScreenMapping(CurrentLayoutPolicy(), CurrentScreenFilter(), width, height, dw, dh, 0, 0, 0, 0)
+		renderOnScreen(w.screen, w.offscreen, m, CurrentScreenFilter())
+		return shareable.ResolveStaleImages()
+	})
+	// The OpenGL context might be switched back from the window's.
+	shareable.ResetGLStateCache()
+	return err
+}
+
+func (w *Window) dispose() {
+	_ = w.offscreen.Dispose()
+	if w.screen != nil {
+		_ = w.screen.Dispose()
+	}
+}
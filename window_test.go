@@ -0,0 +1,86 @@
+// Copyright 2018 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+)
+
+func TestWindow(t *testing.T) {
+	g := theGraphicsContext.Load().(*graphicsContext)
+
+	n := 0
+	var sw, sh int
+	w, err := NewWindow(func(screen *Image) error {
+		n++
+		sw, sh = screen.Size()
+		screen.Fill(color.White)
+		return nil
+	}, 16, 8, 2, "Window")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.updateWindows(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("the number of the calls: got: %d, want: %d", n, 1)
+	}
+	if sw != 16 || sh != 8 {
+		t.Errorf("screen size: got: (%d, %d), want: (%d, %d)", sw, sh, 16, 8)
+	}
+
+	// Rendering the window must not break the rendering of the other images.
+	img, _ := NewImage(4, 4, FilterDefault)
+	img.Fill(color.RGBA{0x80, 0x40, 0x20, 0xff})
+	if got, want := img.At(1, 1), (color.RGBA{0x80, 0x40, 0x20, 0xff}); got != want {
+		t.Errorf("img.At(1, 1): got: %v, want: %v", got, want)
+	}
+
+	w.Close()
+	if !w.IsClosed() {
+		t.Errorf("IsClosed(): got: false, want: true")
+	}
+	if err := g.updateWindows(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("the number of the calls after Close: got: %d, want: %d", n, 1)
+	}
+	if got := len(g.windows); got != 0 {
+		t.Errorf("len(windows): got: %d, want: %d", got, 0)
+	}
+}
+
+func TestWindowError(t *testing.T) {
+	g := theGraphicsContext.Load().(*graphicsContext)
+
+	want := errors.New("window error")
+	w, err := NewWindow(func(screen *Image) error {
+		return want
+	}, 16, 16, 1, "Window")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		w.Close()
+		_ = g.updateWindows()
+	}()
+	if got := g.updateWindows(); got != want {
+		t.Errorf("updateWindows(): got: %v, want: %v", got, want)
+	}
+}